@@ -0,0 +1,31 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Domains",type=integer,JSONPath=`.status.domainCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterNextDNSAllowlist is the Schema for the clusternextdnsallowlists API. It is
+// the cluster-scoped variant of NextDNSAllowlist, referenced from allowlistRefs
+// of profiles in any namespace with kind ClusterNextDNSAllowlist.
+type ClusterNextDNSAllowlist struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSAllowlistSpec   `json:"spec,omitempty"`
+	Status NextDNSAllowlistStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterNextDNSAllowlistList contains a list of ClusterNextDNSAllowlist
+type ClusterNextDNSAllowlistList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNextDNSAllowlist `json:"items"`
+}
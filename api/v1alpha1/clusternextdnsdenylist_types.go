@@ -0,0 +1,31 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Domains",type=integer,JSONPath=`.status.domainCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterNextDNSDenylist is the Schema for the clusternextdnsdenylists API. It is
+// the cluster-scoped variant of NextDNSDenylist, referenced from denylistRefs
+// of profiles in any namespace with kind ClusterNextDNSDenylist.
+type ClusterNextDNSDenylist struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSDenylistSpec   `json:"spec,omitempty"`
+	Status NextDNSDenylistStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterNextDNSDenylistList contains a list of ClusterNextDNSDenylist
+type ClusterNextDNSDenylistList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNextDNSDenylist `json:"items"`
+}
@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// crdDir holds the CRDs generated by controller-gen; chartCRDDir is the copy
+// shipped with the Helm chart
+const (
+	crdDir      = "../../config/crd/bases"
+	chartCRDDir = "../../chart/crds"
+)
+
+// loadCRDs returns the CRDs in dir by file name
+func loadCRDs(t *testing.T, dir string) map[string]*apiextensionsv1.CustomResourceDefinition {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	crds := make(map[string]*apiextensionsv1.CustomResourceDefinition, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, yaml.UnmarshalStrict(data, crd), file)
+		crds[filepath.Base(file)] = crd
+	}
+	return crds
+}
+
+// structuralSchema returns the structural schema of the version of crd, as
+// the API server builds it
+func structuralSchema(t *testing.T, crd *apiextensionsv1.CustomResourceDefinition, version string) *structuralschema.Structural {
+	t.Helper()
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		require.NotNil(t, v.Schema, "%s/%s has no schema", crd.Name, version)
+		internal := &apiextensions.JSONSchemaProps{}
+		require.NoError(t, apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, internal, nil))
+		s, err := structuralschema.NewStructural(internal)
+		require.NoError(t, err, "%s/%s", crd.Name, version)
+		return s
+	}
+	t.Fatalf("%s has no version %s", crd.Name, version)
+	return nil
+}
+
+// TestCRDs_Structural rejects CRDs the API server would refuse to serve, such
+// as hand-edited schemas missing a type
+func TestCRDs_Structural(t *testing.T) {
+	for _, dir := range []string{crdDir, chartCRDDir} {
+		for file, crd := range loadCRDs(t, dir) {
+			for _, v := range crd.Spec.Versions {
+				s := structuralSchema(t, crd, v.Name)
+				errs := structuralschema.ValidateStructural(field.NewPath("spec", "versions").Key(v.Name).Child("schema", "openAPIV3Schema"), s)
+				assert.Empty(t, errs.ToAggregate(), "%s/%s is not structural", dir, file)
+			}
+		}
+	}
+}
+
+// TestCRDs_ChartInSync checks that the chart ships the generated CRDs
+// unchanged
+func TestCRDs_ChartInSync(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(crdDir, "*.yaml"))
+	require.NoError(t, err)
+	for _, file := range files {
+		want, err := os.ReadFile(file)
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(chartCRDDir, filepath.Base(file)))
+		require.NoError(t, err, "chart/crds is missing %s", filepath.Base(file))
+		assert.Equal(t, string(want), string(got), "chart/crds/%s differs from config/crd/bases; run task manifests", filepath.Base(file))
+	}
+}
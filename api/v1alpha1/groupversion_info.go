@@ -26,8 +26,17 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&NextDNSProfile{}, &NextDNSProfileList{},
 		&NextDNSAllowlist{}, &NextDNSAllowlistList{},
 		&NextDNSDenylist{}, &NextDNSDenylistList{},
+		&NextDNSDenylistSource{}, &NextDNSDenylistSourceList{},
+		&ClusterNextDNSAllowlist{}, &ClusterNextDNSAllowlistList{},
+		&ClusterNextDNSDenylist{}, &ClusterNextDNSDenylistList{},
 		&NextDNSCoreDNS{}, &NextDNSCoreDNSList{},
 		&NextDNSTLDList{}, &NextDNSTLDListList{},
+		&NextDNSRewrite{}, &NextDNSRewriteList{},
+		&NextDNSDevice{}, &NextDNSDeviceList{},
+		&NextDNSProfileTemplate{}, &NextDNSProfileTemplateList{},
+		&NextDNSProfileGenerator{}, &NextDNSProfileGeneratorList{},
+		&NextDNSReport{}, &NextDNSReportList{},
+		&NextDNSAccount{}, &NextDNSAccountList{},
 	)
 	metav1.AddToGroupVersion(scheme, GroupVersion)
 	return nil
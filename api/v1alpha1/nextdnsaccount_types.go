@@ -0,0 +1,164 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OrphanGCPolicy defines what happens to orphaned profiles of an account
+// +kubebuilder:validation:Enum=Report;Delete
+type OrphanGCPolicy string
+
+const (
+	// OrphanGCPolicyReport lists orphaned profiles in status without
+	// changing them
+	OrphanGCPolicyReport OrphanGCPolicy = "Report"
+
+	// OrphanGCPolicyDelete deletes orphaned profiles from the account
+	OrphanGCPolicyDelete OrphanGCPolicy = "Delete"
+)
+
+// NextDNSAccountSpec defines the desired state of NextDNSAccount
+type NextDNSAccountSpec struct {
+	// CredentialsRef references a Secret containing the API key of the
+	// account. The Secret is read in the account's namespace unless a
+	// namespace is set.
+	// +kubebuilder:validation:Required
+	CredentialsRef SecretKeySelector `json:"credentialsRef"`
+
+	// ProfileLimit is the number of profiles the account's plan allows.
+	// NextDNS does not report it through the API, so status.remainingProfiles
+	// is only computed when it is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProfileLimit *int32 `json:"profileLimit,omitempty"`
+
+	// RateLimit overrides the operator's NextDNS API rate limit for this
+	// account. Every profile using the account's API key shares it.
+	// +optional
+	RateLimit *AccountRateLimit `json:"rateLimit,omitempty"`
+
+	// SyncInterval is the period between reads of the account's profile
+	// list, e.g. "15m" or "6h". Defaults to the operator's sync period.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	// +optional
+	SyncInterval string `json:"syncInterval,omitempty"`
+
+	// GCPolicy controls what happens to orphaned profiles: profiles whose
+	// NextDNSProfile finalizer could not delete them although its deletion
+	// policy was Delete, for example because NextDNS returned an error.
+	// Report lists them in status; Delete deletes them.
+	// +kubebuilder:default=Report
+	// +optional
+	GCPolicy OrphanGCPolicy `json:"gcPolicy,omitempty"`
+}
+
+// AccountRateLimit is the token bucket of the NextDNS API requests of an
+// account
+type AccountRateLimit struct {
+	// RequestsPerSecond is the sustained request rate
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+
+	// Burst is the number of requests allowed in a burst above the rate.
+	// Defaults to the operator's --api-burst.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// AccountProfile is a profile discovered in a NextDNS account
+type AccountProfile struct {
+	// ID is the NextDNS profile identifier
+	ID string `json:"id"`
+
+	// Name is the profile name in NextDNS
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// ManagedBy is the namespace/name of the NextDNSProfile syncing the
+	// profile through the account; empty for profiles the operator does not
+	// manage
+	// +optional
+	ManagedBy string `json:"managedBy,omitempty"`
+
+	// DeletionPolicy is the effective deletion policy of the NextDNSProfile
+	// in ManagedBy when it was last seen
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Orphaned reports that the finalizer of the NextDNSProfile in
+	// ManagedBy removed it without deleting the profile
+	// +optional
+	Orphaned bool `json:"orphaned,omitempty"`
+}
+
+// NextDNSAccountStatus defines the observed state of NextDNSAccount
+type NextDNSAccountStatus struct {
+	// AccountFingerprint identifies the NextDNS account of the API key, like
+	// status.accountFingerprint of the profiles using it
+	// +optional
+	AccountFingerprint string `json:"accountFingerprint,omitempty"`
+
+	// CredentialsSecretVersion is the resourceVersion of the credentials
+	// Secret whose API key NextDNS last accepted
+	// +optional
+	CredentialsSecretVersion string `json:"credentialsSecretVersion,omitempty"`
+
+	// ProfileCount is the number of profiles in the account
+	// +optional
+	ProfileCount int32 `json:"profileCount,omitempty"`
+
+	// RemainingProfiles is the number of profiles that can still be created
+	// under spec.profileLimit; unset when no limit is set
+	// +optional
+	RemainingProfiles *int32 `json:"remainingProfiles,omitempty"`
+
+	// OrphanedProfiles is the number of orphaned profiles in the account
+	// +optional
+	OrphanedProfiles int32 `json:"orphanedProfiles,omitempty"`
+
+	// Profiles lists the profiles discovered in the account
+	// +optional
+	Profiles []AccountProfile `json:"profiles,omitempty"`
+
+	// LastSyncTime is when the account's profiles were last listed
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Account",type=string,JSONPath=`.status.accountFingerprint`
+// +kubebuilder:printcolumn:name="Profiles",type=integer,JSONPath=`.status.profileCount`
+// +kubebuilder:printcolumn:name="Remaining",type=integer,JSONPath=`.status.remainingProfiles`
+// +kubebuilder:printcolumn:name="Orphans",type=integer,JSONPath=`.status.orphanedProfiles`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSAccount is the Schema for the nextdnsaccounts API. It holds the
+// API key of a NextDNS account for profiles that reference it by name.
+type NextDNSAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSAccountSpec   `json:"spec,omitempty"`
+	Status NextDNSAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSAccountList contains a list of NextDNSAccount
+type NextDNSAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSAccount `json:"items"`
+}
@@ -11,8 +11,13 @@ type NextDNSAllowlistSpec struct {
 	Description string `json:"description,omitempty"`
 
 	// Domains is the list of domains to allow
-	// +kubebuilder:validation:MinItems=1
-	Domains []DomainEntry `json:"domains"`
+	// +optional
+	Domains []DomainEntry `json:"domains,omitempty"`
+
+	// Sources fetches additional entries from HTTP(S) URLs, OCI artifacts,
+	// or Git repositories. Fetched entries are active.
+	// +optional
+	Sources []ListSource `json:"sources,omitempty"`
 }
 
 // NextDNSAllowlistStatus defines the observed state of NextDNSAllowlist
@@ -21,6 +26,10 @@ type NextDNSAllowlistStatus struct {
 	// +optional
 	DomainCount int `json:"domainCount,omitempty"`
 
+	// Sources reports the last fetch of each entry in spec.sources
+	// +optional
+	Sources []ListSourceStatus `json:"sources,omitempty"`
+
 	// ProfileRefs lists profiles using this allowlist
 	// +optional
 	ProfileRefs []ResourceReference `json:"profileRefs,omitempty"`
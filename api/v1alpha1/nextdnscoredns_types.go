@@ -2,12 +2,13 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DNSProtocol specifies the DNS protocol to use for upstream queries
-// +kubebuilder:validation:Enum=DoT;DoH;DNS
+// +kubebuilder:validation:Enum=DoT;DoH;DNS;DoQ
 type DNSProtocol string
 
 const (
@@ -22,6 +23,11 @@ const (
 	// and DNS queries may be visible to network observers. Use DoT or DoH
 	// for privacy in untrusted networks.
 	DNSProtocolDNS DNSProtocol = "DNS"
+	// DNSProtocolDoQ uses DNS over QUIC (port 853)
+	// Accepted for forward compatibility: the CoreDNS forward plugin cannot
+	// reach QUIC upstreams yet, so the operator reports it as unsupported
+	// instead of deploying CoreDNS.
+	DNSProtocolDoQ DNSProtocol = "DoQ"
 )
 
 // DeploymentMode specifies how CoreDNS instances are deployed
@@ -118,6 +124,22 @@ type UpstreamConfig struct {
 	// CoreDNS defaults are used when omitted.
 	// +optional
 	Forward *ForwardTuningConfig `json:"forward,omitempty"`
+
+	// IPv6 also forwards DoT and plain DNS queries to the profile's IPv6
+	// endpoints reported by the NextDNS setup endpoint. Requires IPv6
+	// connectivity from the CoreDNS pods.
+	// +optional
+	// +kubebuilder:default=false
+	IPv6 bool `json:"ipv6,omitempty"`
+
+	// BootstrapServers pins dns.nextdns.io to these IP addresses in the
+	// CoreDNS pods when the primary protocol is DoH, so reaching NextDNS does
+	// not depend on another resolver. The NextDNS anycast addresses
+	// 45.90.28.0 and 45.90.30.0 serve DoH. Ignored for DoT and plain DNS,
+	// which forward to IP addresses.
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	BootstrapServers []string `json:"bootstrapServers,omitempty"`
 }
 
 // CoreDNSDeploymentConfig configures the CoreDNS deployment
@@ -127,7 +149,8 @@ type CoreDNSDeploymentConfig struct {
 	// +optional
 	Mode DeploymentMode `json:"mode,omitempty"`
 
-	// Replicas specifies the number of CoreDNS replicas (only used when Mode is Deployment)
+	// Replicas specifies the number of CoreDNS replicas (only used when Mode is Deployment).
+	// Ignored while Autoscaling is enabled.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=2
 	// +optional
@@ -150,6 +173,19 @@ type CoreDNSDeploymentConfig struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// TopologySpreadConstraints spread the CoreDNS pods across zones or
+	// nodes. A constraint without a labelSelector selects the pods of this
+	// instance.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the priority class of the CoreDNS pods, so DNS
+	// outranks batch workloads during preemption. Overrides the
+	// system-node-critical class of the critical addon preset and the
+	// node-local cache.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
 	// Resources specifies compute resource requirements
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
@@ -159,13 +195,200 @@ type CoreDNSDeploymentConfig struct {
 	// +optional
 	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
 
+	// ServiceAccount configures the ServiceAccount of the CoreDNS pods.
+	// Without it the pods run as the namespace default ServiceAccount.
+	// +optional
+	ServiceAccount *CoreDNSServiceAccountConfig `json:"serviceAccount,omitempty"`
+
+	// PodSecurityContext overrides pod-level security settings of the
+	// CoreDNS pods. The pods always run as non-root.
+	// +optional
+	PodSecurityContext *CoreDNSPodSecurityConfig `json:"podSecurityContext,omitempty"`
+
+	// ExtraContainers are added to the CoreDNS pods after the coredns
+	// container, e.g. a dnstap collector sidecar. The names coredns and
+	// setup-interface are reserved.
+	// +optional
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+
+	// InitContainers run before CoreDNS starts, e.g. to pre-warm
+	// certificates. They run after the init container of the node-local
+	// cache.
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// Strategy configures the rolling update of the CoreDNS pods. Unset
+	// fields keep the Kubernetes defaults.
+	// +optional
+	Strategy *CoreDNSUpdateStrategyConfig `json:"strategy,omitempty"`
+
+	// MinReadySeconds is how long a new pod must be ready before it counts
+	// as available, so a rollout only moves on once it serves queries
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
 	// PodDisruptionBudget configures disruption budget for HA deployments
 	// +optional
 	PodDisruptionBudget *CoreDNSPDBConfig `json:"podDisruptionBudget,omitempty"`
+
+	// Autoscaling manages a HorizontalPodAutoscaler for the CoreDNS Deployment
+	// instead of a static replica count (only used when Mode is Deployment)
+	// +optional
+	Autoscaling *CoreDNSAutoscalingConfig `json:"autoscaling,omitempty"`
+
+	// HostPort exposes DNS on port 53 of every node running a CoreDNS pod
+	// (only used when Mode is DaemonSet)
+	// +optional
+	HostPort *CoreDNSHostPortConfig `json:"hostPort,omitempty"`
+
+	// CriticalAddon applies the system-node-critical priority class and
+	// tolerations for CriticalAddonsOnly and the node.kubernetes.io/* taints,
+	// so DNS keeps running on cordoned, not-ready or pressured nodes during
+	// recovery. Tolerations are merged with Tolerations.
+	// (only used when Mode is DaemonSet)
+	// +kubebuilder:default=false
+	// +optional
+	CriticalAddon bool `json:"criticalAddon,omitempty"`
+
+	// NodeLocal runs CoreDNS as a per-node cache on a link-local address,
+	// like node-local-dns, so pods on every node can use it as their
+	// resolver. (only used when Mode is DaemonSet)
+	// +optional
+	NodeLocal *CoreDNSNodeLocalConfig `json:"nodeLocal,omitempty"`
+}
+
+// CoreDNSNodeLocalConfig configures the node-local cache mode. Pods run with
+// host networking, an init container adds LocalIP to a dummy interface on
+// the node, and CoreDNS binds to LocalIP and the node IP only. The critical
+// addon priority class and tolerations are applied.
+type CoreDNSNodeLocalConfig struct {
+	// Enabled runs CoreDNS as a node-local cache
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// LocalIP is the address bound on every node. Point the kubelet
+	// clusterDNS setting at it; status.nodeLocal shows the configuration.
+	// +kubebuilder:default="169.254.20.10"
+	// +optional
+	LocalIP string `json:"localIP,omitempty"`
+
+	// Interface is the name of the dummy interface holding LocalIP
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	// +kubebuilder:default=nodelocaldns
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// SetupImage is the image of the init container that creates the
+	// interface. It must provide sh and the ip command.
+	// +kubebuilder:default="mirror.gcr.io/library/busybox:1.37"
+	// +optional
+	SetupImage string `json:"setupImage,omitempty"`
+}
+
+// NodeAddressType selects which node address is published for hostPort endpoints
+// +kubebuilder:validation:Enum=InternalIP;ExternalIP
+type NodeAddressType string
+
+const (
+	// NodeAddressInternalIP publishes the node's InternalIP address
+	NodeAddressInternalIP NodeAddressType = "InternalIP"
+	// NodeAddressExternalIP publishes the node's ExternalIP address
+	NodeAddressExternalIP NodeAddressType = "ExternalIP"
+)
+
+// CoreDNSHostPortConfig configures hostPort exposure for DaemonSet mode
+type CoreDNSHostPortConfig struct {
+	// Enabled binds the DNS container ports to port 53 on each node
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// AddressType selects which node address is reported in status.endpoints
+	// +kubebuilder:default=InternalIP
+	// +optional
+	AddressType NodeAddressType `json:"addressType,omitempty"`
+
+	// ExternalDNSHostname, when set, annotates the Service for external-dns
+	// so the hostname resolves to the current set of node IPs
+	// +optional
+	ExternalDNSHostname string `json:"externalDNSHostname,omitempty"`
+}
+
+// CoreDNSServiceAccountConfig configures the ServiceAccount the CoreDNS pods
+// run as
+type CoreDNSServiceAccountConfig struct {
+	// Create makes the operator create and own the ServiceAccount. When
+	// false, Name must refer to an existing ServiceAccount.
+	// +kubebuilder:default=true
+	// +optional
+	Create *bool `json:"create,omitempty"`
+
+	// Name is the ServiceAccount name. Defaults to the name of the CoreDNS
+	// Deployment or DaemonSet when created by the operator.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Annotations are added to the created ServiceAccount, such as
+	// eks.amazonaws.com/role-arn for IAM roles for service accounts
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CoreDNSPodSecurityConfig overrides pod-level security settings of the
+// CoreDNS pods
+type CoreDNSPodSecurityConfig struct {
+	// RunAsUser is the user ID the containers run as. Defaults to 65534
+	// (nobody).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// RunAsGroup is the group ID the containers run as. Defaults to the
+	// image's group.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// FSGroup is the supplemental group that owns the mounted volumes
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// SeccompProfile is the seccomp profile of the containers, such as
+	// RuntimeDefault for clusters enforcing the restricted Pod Security
+	// Standard
+	// +optional
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+}
+
+// CoreDNSUpdateStrategyConfig configures the rolling update of the CoreDNS
+// Deployment or DaemonSet
+type CoreDNSUpdateStrategyConfig struct {
+	// MaxUnavailable is the maximum number of pods that can be unavailable
+	// during an update, as a number or a percentage. Defaults to 25% for
+	// Deployments and 1 for DaemonSets.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MaxSurge is the maximum number of pods created above the desired
+	// count during an update, as a number or a percentage (Deployment mode
+	// only). Defaults to 25%.
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 }
 
 // CoreDNSPDBConfig configures PodDisruptionBudget for CoreDNS HA deployments
 type CoreDNSPDBConfig struct {
+	// Enabled controls whether the PodDisruptionBudget is created. Setting it
+	// to false deletes an existing budget while keeping the configuration.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
 	// MinAvailable is the minimum number of pods that must be available.
 	// Mutually exclusive with MaxUnavailable.
 	// +optional
@@ -177,6 +400,89 @@ type CoreDNSPDBConfig struct {
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
+// CoreDNSNetworkPolicyConfig configures the NetworkPolicy for CoreDNS pods.
+// Ingress is limited to the DNS and metrics ports and egress to the NextDNS
+// upstreams and domain override upstreams.
+type CoreDNSNetworkPolicyConfig struct {
+	// Enabled controls whether the NetworkPolicy is created. Setting it to
+	// false deletes an existing policy while keeping the configuration.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AllowedNamespaces lists the namespaces whose pods may query CoreDNS and
+	// scrape its metrics. When neither this nor AllowedCIDRs is set, all
+	// sources are allowed on those ports.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// AllowedCIDRs lists the IP ranges that may query CoreDNS and scrape its
+	// metrics, such as LAN clients reaching a LoadBalancer Service
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+}
+
+// CoreDNSAccessControlConfig restricts which clients may query CoreDNS. It
+// is enforced by CoreDNS itself with the acl plugin, against the client
+// address it sees, and refused clients get REFUSED.
+type CoreDNSAccessControlConfig struct {
+	// AllowCIDRs lists the client networks that may query CoreDNS. When
+	// set, every other client is refused. Empty allows every client not in
+	// DenyCIDRs.
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	AllowCIDRs []string `json:"allowCIDRs,omitempty"`
+
+	// DenyCIDRs lists the client networks refused, even when they are also
+	// in AllowCIDRs
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	DenyCIDRs []string `json:"denyCIDRs,omitempty"`
+}
+
+// CoreDNSAutoscalingConfig configures a HorizontalPodAutoscaler for CoreDNS
+type CoreDNSAutoscalingConfig struct {
+	// Enabled controls whether the HorizontalPodAutoscaler is created. While
+	// enabled, the operator leaves the Deployment replica count to the autoscaler.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MinReplicas is the lower limit for the number of replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=2
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the average CPU utilization across pods,
+	// relative to the CPU request. Defaults to 80 if no target is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the average memory utilization
+	// across pods, relative to the memory request
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// TargetQueriesPerSecond is the average DNS queries per second per pod.
+	// Requires a custom metrics adapter (e.g. prometheus-adapter) exposing
+	// QueriesPerSecondMetric as a pods metric.
+	// +optional
+	TargetQueriesPerSecond *resource.Quantity `json:"targetQueriesPerSecond,omitempty"`
+
+	// QueriesPerSecondMetric is the pods metric used with TargetQueriesPerSecond
+	// +kubebuilder:default=coredns_dns_requests_per_second
+	// +optional
+	QueriesPerSecondMetric string `json:"queriesPerSecondMetric,omitempty"`
+}
+
 // CoreDNSServiceConfig configures the CoreDNS Kubernetes Service
 type CoreDNSServiceConfig struct {
 	// Type specifies the type of Service
@@ -199,6 +505,35 @@ type CoreDNSServiceConfig struct {
 	// NameOverride overrides the generated service name
 	// +optional
 	NameOverride string `json:"nameOverride,omitempty"`
+
+	// IPFamilies lists the IP families of the Service, primary first, such
+	// as [IPv6] for IPv6-only or [IPv4, IPv6] for dual-stack. Defaults to
+	// the cluster's primary family. The primary family cannot be changed
+	// once the Service exists.
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum=IPv4;IPv6
+	// +optional
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
+	// IPFamilyPolicy is SingleStack, PreferDualStack or RequireDualStack.
+	// Defaults to SingleStack, or RequireDualStack when IPFamilies lists
+	// two families.
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+
+	// InternalTrafficPolicy is Cluster or Local. Local keeps in-cluster
+	// queries on the client's node and drops them on nodes without a
+	// CoreDNS pod, so it suits the DaemonSet mode.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	InternalTrafficPolicy *corev1.ServiceInternalTrafficPolicy `json:"internalTrafficPolicy,omitempty"`
+
+	// TopologyAwareRouting sets the service.kubernetes.io/topology-mode
+	// annotation to Auto, so clients prefer CoreDNS pods in their own zone
+	// when the pods are spread evenly enough across zones
+	// +optional
+	TopologyAwareRouting bool `json:"topologyAwareRouting,omitempty"`
 }
 
 // CoreDNSMetricsConfig configures metrics and monitoring
@@ -214,6 +549,56 @@ type CoreDNSMetricsConfig struct {
 	// +kubebuilder:default=9153
 	// +optional
 	Port *int32 `json:"port,omitempty"`
+
+	// Address is the IP address the prometheus plugin binds to.
+	// Empty binds all interfaces. Binding to a loopback address keeps
+	// metrics off the Service and pod network.
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// DomainOverrides also loads the prometheus plugin in every domain
+	// override server block, so queries answered there are recorded with
+	// their own server label. By default only the catch-all block records
+	// metrics.
+	// +kubebuilder:default=false
+	// +optional
+	DomainOverrides bool `json:"domainOverrides,omitempty"`
+
+	// ExcludeDomains lists domain override blocks that never record
+	// metrics when DomainOverrides is enabled. Each entry must match
+	// a spec.corefile.domainOverrides[].domain.
+	// +optional
+	ExcludeDomains []string `json:"excludeDomains,omitempty"`
+
+	// ServiceMonitor creates a Prometheus Operator ServiceMonitor scraping
+	// the metrics port of the CoreDNS Service. Ignored when the
+	// monitoring.coreos.com/v1 ServiceMonitor CRD is not installed.
+	// +optional
+	ServiceMonitor *CoreDNSServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+}
+
+// CoreDNSServiceMonitorConfig configures the ServiceMonitor for CoreDNS metrics
+type CoreDNSServiceMonitorConfig struct {
+	// Enabled enables the ServiceMonitor
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Interval is the scrape interval
+	// +kubebuilder:validation:Pattern=`^[0-9]+(ms|s|m|h)$`
+	// +kubebuilder:default="30s"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels are added to the ServiceMonitor, e.g. to match the
+	// serviceMonitorSelector of a Prometheus instance
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Namespace of the ServiceMonitor. Defaults to the namespace of the
+	// NextDNSCoreDNS resource.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // CoreDNSHealthConfig configures the CoreDNS health plugin used for
@@ -302,6 +687,59 @@ type CoreDNSCacheConfig struct {
 	// +kubebuilder:default=3600
 	// +optional
 	SuccessTTL *int32 `json:"successTTL,omitempty"`
+
+	// DenialTTL specifies the maximum TTL for NXDOMAIN and NODATA responses
+	// (in seconds). CoreDNS caps them at 1800 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DenialTTL *int32 `json:"denialTTL,omitempty"`
+
+	// Prefetch refreshes popular entries shortly before they expire
+	// +optional
+	Prefetch *CoreDNSCachePrefetchConfig `json:"prefetch,omitempty"`
+
+	// ServeStale answers from expired entries while NextDNS cannot be
+	// reached, so short upstream outages go unnoticed by clients
+	// +optional
+	ServeStale *CoreDNSCacheServeStaleConfig `json:"serveStale,omitempty"`
+}
+
+// CoreDNSCachePrefetchConfig configures cache prefetching
+type CoreDNSCachePrefetchConfig struct {
+	// Amount is the number of queries within Duration that makes an entry
+	// eligible for prefetching
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	Amount int32 `json:"amount"`
+
+	// Duration is the window in which Amount queries must arrive
+	// (e.g., "1m"). CoreDNS default is 1m.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(ns|us|µs|ms|s|m|h)$`
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// Percentage is the share of the TTL that must remain when an entry is
+	// prefetched. CoreDNS default is 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percentage *int32 `json:"percentage,omitempty"`
+}
+
+// CoreDNSCacheServeStaleConfig configures serving expired cache entries
+type CoreDNSCacheServeStaleConfig struct {
+	// Duration is how long past expiry an entry may still be served
+	// (e.g., "1h"). CoreDNS default is 1h.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(ns|us|µs|ms|s|m|h)$`
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// RefreshMode is immediate to answer stale entries right away and
+	// refresh them in the background, or verify to query upstream first and
+	// only serve the stale entry if that fails. CoreDNS default is immediate.
+	// +kubebuilder:validation:Enum=immediate;verify
+	// +optional
+	RefreshMode string `json:"refreshMode,omitempty"`
 }
 
 // CoreDNSLoggingConfig configures DNS query logging
@@ -310,6 +748,12 @@ type CoreDNSLoggingConfig struct {
 	// +kubebuilder:default=false
 	// +optional
 	Enabled *bool `json:"enabled,omitempty"`
+
+	// RedactClientAddress leaves the client address and port out of the
+	// query log, so log pipelines never receive them
+	// +kubebuilder:default=false
+	// +optional
+	RedactClientAddress bool `json:"redactClientAddress,omitempty"`
 }
 
 // DomainOverride specifies a domain-specific DNS upstream configuration
@@ -503,6 +947,107 @@ type HostsConfig struct {
 	TTL *int32 `json:"ttl,omitempty"`
 }
 
+// LocalRecord is a static DNS record answered by CoreDNS itself instead of
+// being forwarded to NextDNS
+type LocalRecord struct {
+	// Name is the fully qualified name the record answers, such as
+	// "nas.home.lan"
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Type is the record type
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME
+	// +kubebuilder:default=A
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Value is an IPv4 address for A records, an IPv6 address for AAAA
+	// records, or the target name for CNAME records
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Value string `json:"value"`
+
+	// TTL is the TTL (in seconds) returned with the record
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3600
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+}
+
+// LocalZoneTransferConfig publishes a zone built from the hosts entries and
+// local records below it
+type LocalZoneTransferConfig struct {
+	// Enabled serves the zone and allows transferring it
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Zone is the zone name, such as "home.arpa". CoreDNS answers it
+	// authoritatively, so names in the zone without a record return
+	// NXDOMAIN instead of being forwarded to NextDNS.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Zone string `json:"zone"`
+
+	// AllowedCIDRs are the networks allowed to transfer the zone with AXFR
+	// or IXFR. Transfers from other addresses are refused.
+	// +kubebuilder:validation:MinItems=1
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+}
+
+// SearchPathConfig answers names in the search domains of the clients
+// locally, so the expansions a resolver with ndots:5 tries before the name
+// itself are not forwarded to NextDNS
+type SearchPathConfig struct {
+	// Enabled answers the search domains locally
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Domains are the search domains of the clients, such as the cluster
+	// domain or "us-west-2.compute.internal". Names in them are answered
+	// NXDOMAIN unless a hosts entry, local record, domain override or the
+	// local zone serves them.
+	// +kubebuilder:default={"cluster.local"}
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	Domains []string `json:"domains,omitempty"`
+}
+
+// CoreDNSRateLimitConfig limits the queries clients may send to the
+// catch-all zone, so a misbehaving workload cannot flood NextDNS and use
+// up the account's query quota
+type CoreDNSRateLimitConfig struct {
+	// QueriesPerSecond is the number of queries per second each client IP
+	// not matched by a rule may send. Excess queries are dropped. Unset
+	// leaves those clients unlimited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	QueriesPerSecond *int32 `json:"queriesPerSecond,omitempty"`
+
+	// Rules set the limit of the clients in source CIDRs. The first rule
+	// matching a client applies.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Rules []RateLimitRule `json:"rules,omitempty"`
+}
+
+// RateLimitRule sets the query rate limit of the clients in a set of CIDRs
+type RateLimitRule struct {
+	// Sources are the CIDRs of the clients the rule applies to, such as
+	// "10.42.0.0/16"
+	// +kubebuilder:validation:MinItems=1
+	Sources []string `json:"sources"`
+
+	// QueriesPerSecond is the number of queries per second each client IP
+	// in Sources may send. 0 drops every query from Sources. Unset exempts
+	// them from rate limiting.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	QueriesPerSecond *int32 `json:"queriesPerSecond,omitempty"`
+}
+
 // CorefileSpec groups CoreDNS plugin-level configuration.
 // This is the configuration that ends up in the generated Corefile,
 // separate from Kubernetes-level deployment concerns (Deployment, Service,
@@ -542,6 +1087,38 @@ type CorefileSpec struct {
 	// +optional
 	Hosts *HostsConfig `json:"hosts,omitempty"`
 
+	// LocalRecords are static A, AAAA and CNAME records answered without
+	// forwarding to NextDNS, so LAN hostnames resolve from the same endpoint.
+	// Other names, and other query types for a name with only A or AAAA
+	// records, are still forwarded.
+	// +optional
+	LocalRecords []LocalRecord `json:"localRecords,omitempty"`
+
+	// ReverseRecords answers PTR queries for the private addresses
+	// (RFC 1918 and IPv6 ULA) of A and AAAA local records with their names,
+	// so reverse lookups of LAN hosts return the friendly name. Hosts
+	// entries answer reverse lookups without this setting.
+	// +optional
+	ReverseRecords bool `json:"reverseRecords,omitempty"`
+
+	// LocalZoneTransfer serves the hosts entries and local records of a zone
+	// as an authoritative zone that downstream resolvers, such as a router
+	// acting as secondary, can copy with AXFR
+	// +optional
+	LocalZoneTransfer *LocalZoneTransferConfig `json:"localZoneTransfer,omitempty"`
+
+	// SearchPath answers lookups in the clients' search domains without
+	// forwarding them, mitigating the ndots:5 lookup storm of pods that use
+	// this instance as their resolver
+	// +optional
+	SearchPath *SearchPathConfig `json:"searchPath,omitempty"`
+
+	// RateLimit limits the queries each client may send per source CIDR.
+	// Drops use the acl plugin; other limits need an image built with the
+	// ratelimit plugin, set in spec.deployment.image.
+	// +optional
+	RateLimit *CoreDNSRateLimitConfig `json:"rateLimit,omitempty"`
+
 	// Health configures the CoreDNS health plugin (liveness endpoint).
 	// +optional
 	Health *CoreDNSHealthConfig `json:"health,omitempty"`
@@ -555,12 +1132,168 @@ type CorefileSpec struct {
 	Errors *CoreDNSErrorsConfig `json:"errors,omitempty"`
 }
 
+// CoreDNSListenersConfig configures additional listeners CoreDNS serves
+// next to plain DNS on port 53
+type CoreDNSListenersConfig struct {
+	// DoH serves DNS-over-HTTPS so clients can use encrypted DNS to the relay
+	// +optional
+	DoH *CoreDNSDoHListenerConfig `json:"doh,omitempty"`
+
+	// DoT serves DNS-over-TLS so routers and other downstream resolvers can
+	// forward to the relay over an encrypted transport
+	// +optional
+	DoT *CoreDNSDoTListenerConfig `json:"dot,omitempty"`
+}
+
+// CoreDNSDoHListenerConfig configures the DNS-over-HTTPS listener. The
+// certificate comes from exactly one of TLSSecretName or CertificateName.
+type CoreDNSDoHListenerConfig struct {
+	// Enabled serves DNS-over-HTTPS
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Port is the port DNS-over-HTTPS is served on, by the pods and the Service
+	// +kubebuilder:default=443
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// TLSSecretName is the name of a kubernetes.io/tls Secret in the
+	// NextDNSCoreDNS namespace holding the certificate (tls.crt and tls.key)
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// CertificateName is the name of a cert-manager Certificate in the
+	// NextDNSCoreDNS namespace; the Secret it issues to is used
+	// +optional
+	CertificateName string `json:"certificateName,omitempty"`
+}
+
+// CoreDNSDoTListenerConfig configures the DNS-over-TLS listener. The
+// certificate comes from exactly one of TLSSecretName or CertificateName.
+type CoreDNSDoTListenerConfig struct {
+	// Enabled serves DNS-over-TLS
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Port is the port DNS-over-TLS is served on, by the pods and the Service
+	// +kubebuilder:default=853
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// TLSSecretName is the name of a kubernetes.io/tls Secret in the
+	// NextDNSCoreDNS namespace holding the certificate (tls.crt and tls.key)
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// CertificateName is the name of a cert-manager Certificate in the
+	// NextDNSCoreDNS namespace; the Secret it issues to is used
+	// +optional
+	CertificateName string `json:"certificateName,omitempty"`
+}
+
+// ClusterDNSIntegrationMode selects how a NextDNSCoreDNS replaces the
+// default cluster DNS
+// +kubebuilder:validation:Enum=None;KubeDNSService;Kubelet
+type ClusterDNSIntegrationMode string
+
+const (
+	// ClusterDNSIntegrationNone leaves the cluster DNS untouched
+	ClusterDNSIntegrationNone ClusterDNSIntegrationMode = "None"
+	// ClusterDNSIntegrationKubeDNSService points the selector of the
+	// cluster DNS Service at the CoreDNS pods, so pods keep their resolver
+	// address and reach NextDNS
+	ClusterDNSIntegrationKubeDNSService ClusterDNSIntegrationMode = "KubeDNSService"
+	// ClusterDNSIntegrationKubelet publishes the CoreDNS Service ClusterIP
+	// as the kubelet clusterDNS setting in status; nodes are reconfigured
+	// by the cluster administrator
+	ClusterDNSIntegrationKubelet ClusterDNSIntegrationMode = "Kubelet"
+)
+
+// ClusterDNSIntegrationConfig configures taking over the default cluster DNS.
+// The KubeDNSService mode is only applied when the operator runs with
+// --allow-cluster-dns-integration.
+type ClusterDNSIntegrationConfig struct {
+	// Mode selects how cluster DNS is taken over
+	// +kubebuilder:default=None
+	// +optional
+	Mode ClusterDNSIntegrationMode `json:"mode,omitempty"`
+
+	// ServiceName is the cluster DNS Service whose selector is patched in
+	// KubeDNSService mode. It must be in the NextDNSCoreDNS namespace,
+	// because a Service only selects pods of its own namespace.
+	// +kubebuilder:default=kube-dns
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// CoreDNSReadinessConfig configures when a NextDNSCoreDNS reports Ready
+type CoreDNSReadinessConfig struct {
+	// MinReadyReplicas is the number of available CoreDNS pods at which the
+	// instance reports Ready, so a rolling update or a lost node does not
+	// flip it while the remaining pods serve queries. A pod is available
+	// once it stayed ready for spec.deployment.minReadySeconds. When unset,
+	// every desired pod must be ready; a value above the desired count
+	// requires all of them.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReadyReplicas *int32 `json:"minReadyReplicas,omitempty"`
+}
+
+// SoakTestConfig compares this instance with a legacy resolver before
+// clients are moved over to it
+type SoakTestConfig struct {
+	// Enabled turns the soak test on or off without removing its settings
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// LegacyResolver is the address of the resolver being replaced, as an
+	// IP or IP:port. The port defaults to 53.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	LegacyResolver string `json:"legacyResolver"`
+
+	// Domains are the names looked up through both resolvers
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Domains []string `json:"domains"`
+
+	// SamplePercent is the percentage of Domains looked up in each round,
+	// chosen at random
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=100
+	// +optional
+	SamplePercent *int32 `json:"samplePercent,omitempty"`
+
+	// Interval is the time between rounds, e.g. "5m". Intervals shorter
+	// than 1m are raised to 1m.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
 // NextDNSCoreDNSSpec defines the desired state of NextDNSCoreDNS
 type NextDNSCoreDNSSpec struct {
 	// ProfileRef references the NextDNSProfile to use for DNS resolution
 	// +kubebuilder:validation:Required
 	ProfileRef ResourceReference `json:"profileRef"`
 
+	// FallbackProfileRef references a second NextDNSProfile, such as a
+	// permissive break-glass profile, that CoreDNS forwards to only while
+	// every upstream of the primary profile fails its health check. It is
+	// reached over the same protocol and device name, and requires the
+	// sequential forward policy, which is then set.
+	// +optional
+	FallbackProfileRef *ResourceReference `json:"fallbackProfileRef,omitempty"`
+
 	// Deployment configures the CoreDNS deployment
 	// +optional
 	Deployment *CoreDNSDeploymentConfig `json:"deployment,omitempty"`
@@ -585,6 +1318,53 @@ type NextDNSCoreDNSSpec struct {
 	// metrics, logging, domain overrides).
 	// +optional
 	Corefile *CorefileSpec `json:"corefile,omitempty"`
+
+	// NetworkPolicy configures a NetworkPolicy restricting traffic to and
+	// from the CoreDNS pods
+	// +optional
+	NetworkPolicy *CoreDNSNetworkPolicyConfig `json:"networkPolicy,omitempty"`
+
+	// AccessControl restricts which clients may query CoreDNS, on every
+	// listener. A LoadBalancer Service then uses the Local external traffic
+	// policy, so CoreDNS sees the client address.
+	// +optional
+	AccessControl *CoreDNSAccessControlConfig `json:"accessControl,omitempty"`
+
+	// Listeners configures encrypted DNS listeners served in addition to
+	// plain DNS on port 53
+	// +optional
+	Listeners *CoreDNSListenersConfig `json:"listeners,omitempty"`
+
+	// ClusterDNSIntegration lets this instance replace the default cluster
+	// DNS, by taking over the kube-dns Service or by publishing its
+	// ClusterIP for the kubelet --cluster-dns setting
+	// +optional
+	ClusterDNSIntegration *ClusterDNSIntegrationConfig `json:"clusterDNSIntegration,omitempty"`
+
+	// SoakTest periodically looks up a sample of domains through this
+	// instance and through the resolver it replaces, and reports where
+	// their answers diverge
+	// +optional
+	SoakTest *SoakTestConfig `json:"soakTest,omitempty"`
+
+	// Readiness configures when the instance reports Ready
+	// +optional
+	Readiness *CoreDNSReadinessConfig `json:"readiness,omitempty"`
+
+	// SuspendWorkload freezes the CoreDNS Deployment or DaemonSet: it is not
+	// created, updated or replaced, so no image or Corefile change rolls the
+	// pods. The ConfigMap, Service and other resources are still reconciled,
+	// so changes are staged and roll out once this is cleared.
+	// +optional
+	SuspendWorkload bool `json:"suspendWorkload,omitempty"`
+
+	// SyncInterval overrides the operator's sync period for this resource,
+	// e.g. "15m" or "6h". "0s" disables periodic syncing. Intervals shorter
+	// than 5m are raised to 5m. Takes precedence over the
+	// nextdns.io/sync-period annotation.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	// +optional
+	SyncInterval string `json:"syncInterval,omitempty"`
 }
 
 // DNSEndpoint represents a DNS endpoint exposed by the service
@@ -595,14 +1375,170 @@ type DNSEndpoint struct {
 	// Port is the port number of the DNS endpoint
 	Port int32 `json:"port"`
 
-	// Protocol is the protocol (TCP or UDP)
+	// Protocol is the protocol: UDP or TCP for plain DNS, DoH or DoT for
+	// the encrypted listeners
 	Protocol string `json:"protocol"`
+
+	// Stamp is the DNS stamp (sdns://) of the endpoint, for dnscrypt-proxy
+	// and routers that accept stamps. Empty when the endpoint cannot be
+	// expressed as a stamp.
+	// +optional
+	Stamp string `json:"stamp,omitempty"`
 }
 
+// UpstreamPath is the upstream path serving the queries of a NextDNSCoreDNS
+type UpstreamPath string
+
+const (
+	// UpstreamPathPrimary means the primary profile's upstreams answer
+	UpstreamPathPrimary UpstreamPath = "Primary"
+	// UpstreamPathFallback means every primary upstream is down and queries
+	// are forwarded to the fallback profile
+	UpstreamPathFallback UpstreamPath = "Fallback"
+)
+
 // UpstreamStatus represents the status of upstream DNS configuration
 type UpstreamStatus struct {
 	// URL is the NextDNS upstream URL being used
 	URL string `json:"url"`
+
+	// IPv4 lists the IPv4 addresses CoreDNS forwards to. Empty for DoH.
+	// +optional
+	IPv4 []string `json:"ipv4,omitempty"`
+
+	// IPv6 lists the IPv6 addresses CoreDNS forwards to. Empty for DoH or
+	// when spec.corefile.upstream.ipv6 is not enabled.
+	// +optional
+	IPv6 []string `json:"ipv6,omitempty"`
+
+	// BootstrapServers lists the addresses dns.nextdns.io is pinned to for
+	// DoH. Empty when the pods resolve it through their DNS policy.
+	// +optional
+	BootstrapServers []string `json:"bootstrapServers,omitempty"`
+}
+
+// NodeLocalStatus reports how to point the kubelet at the node-local cache
+type NodeLocalStatus struct {
+	// LocalIP is the address CoreDNS serves on every node
+	LocalIP string `json:"localIP"`
+
+	// Interface is the dummy interface holding LocalIP
+	Interface string `json:"interface"`
+
+	// KubeletConfig is the KubeletConfiguration fragment that makes pods
+	// resolve through the cache
+	KubeletConfig string `json:"kubeletConfig"`
+
+	// KubeletFlag is the equivalent kubelet command-line flag
+	KubeletFlag string `json:"kubeletFlag"`
+}
+
+// LocalZoneStatus reports the zone published for transfers
+type LocalZoneStatus struct {
+	// Zone is the zone name
+	Zone string `json:"zone"`
+
+	// Serial is the SOA serial, increased whenever the records change
+	Serial int64 `json:"serial"`
+
+	// Records is the number of records in the zone besides SOA and NS
+	Records int32 `json:"records"`
+}
+
+// ClusterDNSStatus reports how the instance serves as cluster DNS
+type ClusterDNSStatus struct {
+	// Mode is the integration mode in effect
+	Mode ClusterDNSIntegrationMode `json:"mode"`
+
+	// Service is the namespace/name of the cluster DNS Service whose
+	// selector points at the CoreDNS pods (KubeDNSService mode)
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// ClusterIP is the address pods resolve through
+	// +optional
+	ClusterIP string `json:"clusterIP,omitempty"`
+
+	// KubeletConfig is the KubeletConfiguration fragment that makes pods
+	// resolve through ClusterIP (Kubelet mode)
+	// +optional
+	KubeletConfig string `json:"kubeletConfig,omitempty"`
+
+	// KubeletFlag is the equivalent kubelet command-line flag (Kubelet mode)
+	// +optional
+	KubeletFlag string `json:"kubeletFlag,omitempty"`
+}
+
+// TestQueryStatus is the outcome of a lookup requested with the
+// nextdns.io/test-query annotation
+type TestQueryStatus struct {
+	// Name is the domain that was looked up
+	Name string `json:"name"`
+
+	// Server is the address the lookup was sent to
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// Result classifies the answer. Blocked means every address returned was
+	// 0.0.0.0 or ::, which is how NextDNS answers blocked domains by default.
+	// +kubebuilder:validation:Enum=Resolved;Blocked;NXDOMAIN;Failed
+	Result string `json:"result"`
+
+	// Answers lists the addresses returned
+	// +optional
+	Answers []string `json:"answers,omitempty"`
+
+	// RTT is the time the lookup took, e.g. "12.4ms"
+	// +optional
+	RTT string `json:"rtt,omitempty"`
+
+	// Error describes why the lookup failed
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Time is when the lookup was made
+	Time metav1.Time `json:"time"`
+}
+
+// SoakTestStatus summarizes the soak test against the legacy resolver
+type SoakTestStatus struct {
+	// Queries is the number of domains looked up through both resolvers
+	// since the soak test was enabled
+	Queries int64 `json:"queries"`
+
+	// Divergences is the number of those lookups whose results differed
+	Divergences int64 `json:"divergences"`
+
+	// LastRound is when the latest round was run
+	// +optional
+	LastRound *metav1.Time `json:"lastRound,omitempty"`
+
+	// NextDNSLatency is the mean lookup time through this instance in the
+	// latest round, e.g. "12.4ms"
+	// +optional
+	NextDNSLatency string `json:"nextDNSLatency,omitempty"`
+
+	// LegacyLatency is the mean lookup time through the legacy resolver in
+	// the latest round
+	// +optional
+	LegacyLatency string `json:"legacyLatency,omitempty"`
+
+	// LastDivergence is the latest lookup whose results differed
+	// +optional
+	LastDivergence *SoakTestDivergence `json:"lastDivergence,omitempty"`
+
+	// Error describes why the latest round could not run
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SoakTestDivergence is a domain that two resolvers answered differently
+type SoakTestDivergence struct {
+	// NextDNS is the lookup through this instance
+	NextDNS TestQueryStatus `json:"nextDNS"`
+
+	// Legacy is the lookup through the legacy resolver
+	Legacy TestQueryStatus `json:"legacy"`
 }
 
 // ReplicaStatus represents the status of deployment replicas
@@ -617,6 +1553,19 @@ type ReplicaStatus struct {
 	Available int32 `json:"available"`
 }
 
+// PodPlacement reports the ready CoreDNS pods running on one node
+type PodPlacement struct {
+	// Node is the name of the node running the pods
+	Node string `json:"node"`
+
+	// Zone is the node's topology.kubernetes.io/zone label, if set
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// ReadyPods is the number of ready CoreDNS pods on the node
+	ReadyPods int32 `json:"readyPods"`
+}
+
 // NextDNSCoreDNSStatus defines the observed state of NextDNSCoreDNS
 type NextDNSCoreDNSStatus struct {
 	// ProfileID is the NextDNS profile ID from the referenced profile
@@ -639,14 +1588,77 @@ type NextDNSCoreDNSStatus struct {
 	// +optional
 	MultusIPs []string `json:"multusIPs,omitempty"`
 
+	// NodeIPs lists the addresses of nodes serving DNS via hostPort
+	// +optional
+	NodeIPs []string `json:"nodeIPs,omitempty"`
+
+	// NodeLocal reports the node-local cache address and the kubelet
+	// configuration that uses it
+	// +optional
+	NodeLocal *NodeLocalStatus `json:"nodeLocal,omitempty"`
+
+	// ClusterDNS reports the cluster DNS integration in effect
+	// +optional
+	ClusterDNS *ClusterDNSStatus `json:"clusterDNS,omitempty"`
+
+	// LocalZone reports the zone published by spec.corefile.localZoneTransfer
+	// +optional
+	LocalZone *LocalZoneStatus `json:"localZone,omitempty"`
+
 	// Upstream is the status of the NextDNS upstream connection
 	// +optional
 	Upstream *UpstreamStatus `json:"upstream,omitempty"`
 
+	// FallbackUpstream is the status of the upstream connection to the
+	// fallback profile set in spec.fallbackProfileRef
+	// +optional
+	FallbackUpstream *UpstreamStatus `json:"fallbackUpstream,omitempty"`
+
+	// ActiveUpstream is the upstream path serving queries when a fallback
+	// profile is set: Primary, or Fallback while no primary upstream
+	// answers the operator's health check
+	// +kubebuilder:validation:Enum=Primary;Fallback
+	// +optional
+	ActiveUpstream UpstreamPath `json:"activeUpstream,omitempty"`
+
 	// Replicas is the status of the deployment replicas
 	// +optional
 	Replicas *ReplicaStatus `json:"replicas,omitempty"`
 
+	// Placement lists the nodes and zones currently running ready CoreDNS pods
+	// +optional
+	Placement []PodPlacement `json:"placement,omitempty"`
+
+	// PlacementUpdated is the time placement was last refreshed from the pod list
+	// +optional
+	PlacementUpdated *metav1.Time `json:"placementUpdated,omitempty"`
+
+	// CorefileHash is the hash of the Corefile every pod was last rolled out
+	// with
+	// +optional
+	CorefileHash string `json:"corefileHash,omitempty"`
+
+	// CorefileAppliedAt is when the pods finished rolling out a changed
+	// Corefile
+	// +optional
+	CorefileAppliedAt *metav1.Time `json:"corefileAppliedAt,omitempty"`
+
+	// CorefileGeneration is the generation that produced the Corefile the
+	// pods run. Spec changes that leave the Corefile unchanged do not bump
+	// it.
+	// +optional
+	CorefileGeneration int64 `json:"corefileGeneration,omitempty"`
+
+	// LastTestQuery is the outcome of the latest lookup requested with the
+	// nextdns.io/test-query annotation
+	// +optional
+	LastTestQuery *TestQueryStatus `json:"lastTestQuery,omitempty"`
+
+	// SoakTest summarizes the comparison with the legacy resolver set in
+	// spec.soakTest
+	// +optional
+	SoakTest *SoakTestStatus `json:"soakTest,omitempty"`
+
 	// Ready indicates if the CoreDNS deployment is fully ready
 	// +optional
 	Ready bool `json:"ready,omitempty"`
@@ -11,8 +11,13 @@ type NextDNSDenylistSpec struct {
 	Description string `json:"description,omitempty"`
 
 	// Domains is the list of domains to block
-	// +kubebuilder:validation:MinItems=1
-	Domains []DomainEntry `json:"domains"`
+	// +optional
+	Domains []DomainEntry `json:"domains,omitempty"`
+
+	// Sources fetches additional entries from HTTP(S) URLs, OCI artifacts,
+	// or Git repositories. Fetched entries are active.
+	// +optional
+	Sources []ListSource `json:"sources,omitempty"`
 }
 
 // NextDNSDenylistStatus defines the observed state of NextDNSDenylist
@@ -21,6 +26,10 @@ type NextDNSDenylistStatus struct {
 	// +optional
 	DomainCount int `json:"domainCount,omitempty"`
 
+	// Sources reports the last fetch of each entry in spec.sources
+	// +optional
+	Sources []ListSourceStatus `json:"sources,omitempty"`
+
 	// ProfileRefs lists profiles using this denylist
 	// +optional
 	ProfileRefs []ResourceReference `json:"profileRefs,omitempty"`
@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DenylistSourceFormat defines how a remote blocklist is parsed
+// +kubebuilder:validation:Enum=Hosts;Adblock
+type DenylistSourceFormat string
+
+const (
+	// DenylistSourceFormatHosts reads hosts files ("0.0.0.0 ads.example.com")
+	// and plain lists with one domain per line
+	DenylistSourceFormatHosts DenylistSourceFormat = "Hosts"
+
+	// DenylistSourceFormatAdblock reads the domain rules ("||ads.example.com^")
+	// of Adblock-style filter lists and skips all other rules
+	DenylistSourceFormatAdblock DenylistSourceFormat = "Adblock"
+)
+
+// NextDNSDenylistSourceSpec defines the desired state of NextDNSDenylistSource
+type NextDNSDenylistSourceSpec struct {
+	// Description provides context for this blocklist
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// URL of the blocklist, e.g. a StevenBlack hosts file or an OISD list
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Interval between fetches of the blocklist
+	// +optional
+	// +kubebuilder:default="24h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	Interval string `json:"interval,omitempty"`
+
+	// Format of the blocklist
+	// +optional
+	// +kubebuilder:default=Hosts
+	Format DenylistSourceFormat `json:"format,omitempty"`
+
+	// Exclude lists domains to drop from the blocklist, e.g. false positives.
+	// Excluding a domain also excludes its subdomains.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Cosign requires a valid cosign signature of the blocklist. Content that
+	// fails verification is rejected.
+	// +optional
+	Cosign *CosignVerification `json:"cosign,omitempty"`
+}
+
+// NextDNSDenylistSourceStatus defines the observed state of NextDNSDenylistSource
+type NextDNSDenylistSourceStatus struct {
+	// DomainCount is the number of domains in the parsed blocklist
+	// +optional
+	DomainCount int `json:"domainCount,omitempty"`
+
+	// ConfigMapName is the ConfigMap holding the parsed domains
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Source reports the last fetch of the blocklist
+	// +optional
+	Source *ListSourceStatus `json:"source,omitempty"`
+
+	// ProfileRefs lists profiles using this blocklist
+	// +optional
+	ProfileRefs []ResourceReference `json:"profileRefs,omitempty"`
+
+	// ObservedGeneration is the last generation reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Domains",type=integer,JSONPath=`.status.domainCount`
+// +kubebuilder:printcolumn:name="Last Fetch",type=date,JSONPath=`.status.source.lastFetchTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSDenylistSource is the Schema for the nextdnsdenylistsources API. It
+// pulls a remote blocklist that profiles reference from denylistRefs.
+type NextDNSDenylistSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSDenylistSourceSpec   `json:"spec,omitempty"`
+	Status NextDNSDenylistSourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSDenylistSourceList contains a list of NextDNSDenylistSource
+type NextDNSDenylistSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSDenylistSource `json:"items"`
+}
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NextDNSDeviceSpec defines the desired state of NextDNSDevice
+type NextDNSDeviceSpec struct {
+	// ProfileRef references the NextDNSProfile the device uses
+	// +kubebuilder:validation:Required
+	ProfileRef ResourceReference `json:"profileRef"`
+
+	// DeviceName identifies the device in NextDNS Analytics and Logs.
+	// Defaults to the resource name. Only alphanumeric characters, hyphens,
+	// and spaces are allowed. Spaces are converted to -- for DoT and
+	// URL-encoded for DoH.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9 ]+$`
+	DeviceName string `json:"deviceName,omitempty"`
+}
+
+// NextDNSDeviceStatus defines the observed state of NextDNSDevice
+type NextDNSDeviceStatus struct {
+	// ProfileID is the NextDNS profile identifier of the referenced profile
+	// +optional
+	ProfileID string `json:"profileID,omitempty"`
+
+	// DeviceName is the device name embedded in the endpoints
+	// +optional
+	DeviceName string `json:"deviceName,omitempty"`
+
+	// DoTHostname is the device-specific DNS-over-TLS hostname
+	// (e.g., "laptop-abc123.dns.nextdns.io")
+	// +optional
+	DoTHostname string `json:"dotHostname,omitempty"`
+
+	// DoHURL is the device-specific DNS-over-HTTPS URL
+	// (e.g., "https://dns.nextdns.io/abc123/laptop")
+	// +optional
+	DoHURL string `json:"dohURL,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Profile ID",type=string,JSONPath=`.status.profileID`
+// +kubebuilder:printcolumn:name="DoT Hostname",type=string,JSONPath=`.status.dotHostname`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSDevice is the Schema for the nextdnsdevices API
+type NextDNSDevice struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSDeviceSpec   `json:"spec,omitempty"`
+	Status NextDNSDeviceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSDeviceList contains a list of NextDNSDevice
+type NextDNSDeviceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSDevice `json:"items"`
+}
@@ -139,7 +139,7 @@ type ObservedSettings struct {
 type ObservedLogs struct {
 	Enabled bool `json:"enabled"`
 	// Retention is the log retention period in seconds as returned by the NextDNS API
-	// (e.g., 604800 for 7 days). Use formatRetentionString() to convert to spec enum values.
+	// (e.g., 604800 for 7 days). Use FormatRetention() to convert to spec enum values.
 	Retention int `json:"retention,omitempty"`
 	// Location is the log storage location (e.g., "eu", "us", "ch")
 	Location string `json:"location,omitempty"`
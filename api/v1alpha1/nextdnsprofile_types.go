@@ -16,6 +16,87 @@ const (
 	ProfileModeManaged ProfileMode = "managed"
 )
 
+// DriftPolicy defines how a managed profile reacts to remote changes
+// +kubebuilder:validation:Enum=Correct;ReportOnly
+type DriftPolicy string
+
+const (
+	// DriftPolicyCorrect re-applies the desired state when the remote profile drifts
+	DriftPolicyCorrect DriftPolicy = "Correct"
+
+	// DriftPolicyReportOnly reports drift without modifying the remote profile
+	DriftPolicyReportOnly DriftPolicy = "ReportOnly"
+)
+
+// CredentialsSource identifies where a profile's API key was read from
+// +kubebuilder:validation:Enum=CredentialsRef;Account;Default
+type CredentialsSource string
+
+const (
+	// CredentialsSourceCredentialsRef is the Secret in spec.credentialsRef
+	CredentialsSourceCredentialsRef CredentialsSource = "CredentialsRef"
+
+	// CredentialsSourceAccount is the Secret of the NextDNSAccount in
+	// spec.accountRef
+	CredentialsSourceAccount CredentialsSource = "Account"
+
+	// CredentialsSourceDefault is the operator's default credentials Secret,
+	// used when spec.credentialsRef is not set
+	CredentialsSourceDefault CredentialsSource = "Default"
+)
+
+// AdoptionPolicy defines how the first sync treats an existing remote profile
+// +kubebuilder:validation:Enum=Overwrite;MergeOnce;ObserveFirst
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyOverwrite applies the spec to the remote profile, replacing what is there
+	AdoptionPolicyOverwrite AdoptionPolicy = "Overwrite"
+
+	// AdoptionPolicyMergeOnce makes the first sync additive: remote list entries,
+	// TLDs, rewrites and privacy blocklists are kept alongside the desired ones
+	AdoptionPolicyMergeOnce AdoptionPolicy = "MergeOnce"
+
+	// AdoptionPolicyObserveFirst reads the remote profile into status and makes
+	// no changes until the policy is switched to Overwrite or MergeOnce
+	AdoptionPolicyObserveFirst AdoptionPolicy = "ObserveFirst"
+)
+
+// ImportPolicy defines whether the configuration of an adopted profile is
+// imported into the spec
+// +kubebuilder:validation:Enum=None;MergeOnAdopt;Overwrite
+type ImportPolicy string
+
+const (
+	// ImportPolicyNone leaves the spec as written
+	ImportPolicyNone ImportPolicy = "None"
+
+	// ImportPolicyMergeOnAdopt fills in settings and list entries the spec
+	// does not set from the remote profile
+	ImportPolicyMergeOnAdopt ImportPolicy = "MergeOnAdopt"
+
+	// ImportPolicyOverwrite replaces the spec's settings and inline lists with
+	// those of the remote profile
+	ImportPolicyOverwrite ImportPolicy = "Overwrite"
+)
+
+// DeletionPolicy defines what happens to the remote profile when its
+// NextDNSProfile is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the remote profile
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan leaves the remote profile unchanged
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+
+	// DeletionPolicyRetain keeps the remote profile but removes the allowlist,
+	// denylist, TLD and rewrite entries applied by the operator
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // ConfigMapRef configures the optional ConfigMap containing connection details
 type ConfigMapRef struct {
 	// Enabled enables creation of the ConfigMap
@@ -29,6 +110,63 @@ type ConfigMapRef struct {
 	Name string `json:"name,omitempty"`
 }
 
+// EffectiveConfigExport configures the optional ConfigMap containing the
+// effective profile configuration
+type EffectiveConfigExport struct {
+	// Enabled enables export of the effective configuration
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Name is the name of the ConfigMap to create
+	// If not specified, defaults to "<profile-name>-effective"
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Format of the exported configuration: "yaml" (default) writes the
+	// profile.yaml key, "json" writes profile.json
+	// +kubebuilder:validation:Enum=yaml;json
+	// +kubebuilder:default=yaml
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+// DeployCoreDNSSpec configures the optional NextDNSCoreDNS deployed for the
+// profile
+type DeployCoreDNSSpec struct {
+	// Enabled deploys a NextDNSCoreDNS bound to this profile
+	// +kubebuilder:default=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Name is the name of the NextDNSCoreDNS to create
+	// If not specified, defaults to the profile name
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Template holds the NextDNSCoreDNS settings most deployments need.
+	// Create a standalone NextDNSCoreDNS for the settings it leaves out.
+	// +optional
+	Template *DeployCoreDNSTemplate `json:"template,omitempty"`
+}
+
+// DeployCoreDNSTemplate is the part of a NextDNSCoreDNS spec that can be set
+// for the instance a profile deploys
+type DeployCoreDNSTemplate struct {
+	// Deployment configures the CoreDNS deployment
+	// +optional
+	Deployment *CoreDNSDeploymentConfig `json:"deployment,omitempty"`
+
+	// Service configures the Kubernetes Service
+	// +optional
+	Service *CoreDNSServiceConfig `json:"service,omitempty"`
+
+	// Corefile groups CoreDNS plugin-level configuration (upstream, cache,
+	// metrics, logging, domain overrides).
+	// +optional
+	Corefile *CorefileSpec `json:"corefile,omitempty"`
+}
+
 // NextDNSProfileSpec defines the desired state of NextDNSProfile
 type NextDNSProfileSpec struct {
 	// Name is the human-readable name shown in NextDNS dashboard
@@ -43,15 +181,67 @@ type NextDNSProfileSpec struct {
 	// +optional
 	Mode ProfileMode `json:"mode,omitempty"`
 
-	// CredentialsRef references a Secret containing the NextDNS API key
-	// +kubebuilder:validation:Required
-	CredentialsRef SecretKeySelector `json:"credentialsRef"`
+	// DriftPolicy controls what happens when the remote profile is changed
+	// outside the operator (e.g. in the NextDNS dashboard). "Correct" (default)
+	// overwrites the remote changes; "ReportOnly" only reports them in
+	// status.driftSummary and the Drifted condition. Changes to the desired
+	// state are always applied. Only used in managed mode.
+	// +kubebuilder:default=Correct
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// SyncInterval overrides the operator's sync period for this profile,
+	// e.g. "15m" or "6h". "0s" disables periodic syncing. Intervals shorter
+	// than 5m are raised to 5m to protect the NextDNS API. Takes precedence
+	// over the nextdns.io/sync-period annotation.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	// +optional
+	SyncInterval string `json:"syncInterval,omitempty"`
+
+	// CredentialsRef references a Secret containing the NextDNS API key.
+	// When neither it nor accountRef is set, the operator's default
+	// credentials Secret is used.
+	// +optional
+	CredentialsRef SecretKeySelector `json:"credentialsRef,omitempty"`
+
+	// AccountRef references the NextDNSAccount whose API key the profile
+	// uses, instead of a Secret in credentialsRef. Only one of them may be
+	// set.
+	// +optional
+	AccountRef *ResourceReference `json:"accountRef,omitempty"`
 
 	// ProfileID optionally specifies an existing NextDNS profile to manage
 	// If not set, a new profile will be created
 	// +optional
 	ProfileID string `json:"profileID,omitempty"`
 
+	// AdoptionPolicy controls the first sync of an existing profile set in
+	// ProfileID and is required to adopt one in managed mode. "Overwrite"
+	// replaces the remote configuration, "MergeOnce" keeps remote entries on
+	// the first sync and "ObserveFirst" only reads the remote profile into
+	// status until the policy is changed. Ignored after the first sync.
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// ImportPolicy imports the configuration of an existing profile set in
+	// ProfileID into this spec before the first sync. "MergeOnAdopt" fills in
+	// what the spec leaves unset, "Overwrite" replaces the security, privacy,
+	// parental control and settings sections and the inline allowlist,
+	// denylist and rewrites. Blocked TLDs are not imported. The spec is
+	// written back once; an adoptionPolicy is not required when importing.
+	// +kubebuilder:default=None
+	// +optional
+	ImportPolicy ImportPolicy `json:"importPolicy,omitempty"`
+
+	// DeletionPolicy controls what happens to the NextDNS profile when this
+	// resource is deleted. "Delete" removes the profile, "Orphan" leaves it
+	// unchanged and "Retain" keeps it but removes the allowlist, denylist, TLD
+	// and rewrite entries the operator applied. Defaults to "Delete" for
+	// profiles the operator created and "Orphan" for profiles adopted through
+	// profileID. Observe-mode profiles are never modified.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// ===========================================
 	// List References (Multi-CRD Architecture)
 	// ===========================================
@@ -71,6 +261,11 @@ type NextDNSProfileSpec struct {
 	// +optional
 	TLDListRefs []ListReference `json:"tldListRefs,omitempty"`
 
+	// RewriteRefs references NextDNSRewrite resources
+	// Rewrites from all referenced lists are merged with inline Rewrites
+	// +optional
+	RewriteRefs []ListReference `json:"rewriteRefs,omitempty"`
+
 	// ===========================================
 	// Inline Lists (for simple cases)
 	// ===========================================
@@ -83,6 +278,13 @@ type NextDNSProfileSpec struct {
 	// +optional
 	Allowlist []DomainEntry `json:"allowlist,omitempty"`
 
+	// PreserveUnmanagedEntries keeps allowlist and denylist entries that were
+	// added outside the operator (e.g. in the NextDNS dashboard). Only entries
+	// previously applied by the operator are removed when they leave the spec.
+	// +kubebuilder:default=false
+	// +optional
+	PreserveUnmanagedEntries bool `json:"preserveUnmanagedEntries,omitempty"`
+
 	// ===========================================
 	// Other Settings
 	// ===========================================
@@ -102,8 +304,8 @@ type NextDNSProfileSpec struct {
 	// +optional
 	ParentalControl *ParentalControlSpec `json:"parentalControl,omitempty"`
 
-	// Rewrites specifies DNS rewrites.
-	// Omitting this field leaves remote rewrites unchanged.
+	// Rewrites specifies DNS rewrites (merged with RewriteRefs).
+	// Omitting this field and RewriteRefs leaves remote rewrites unchanged.
 	// Setting an empty list explicitly clears all remote rewrites.
 	// +optional
 	Rewrites []RewriteEntry `json:"rewrites,omitempty"`
@@ -116,6 +318,75 @@ type NextDNSProfileSpec struct {
 	// ConfigMapRef configures optional ConfigMap creation with connection details
 	// +optional
 	ConfigMapRef *ConfigMapRef `json:"configMapRef,omitempty"`
+
+	// EffectiveConfigExport configures an optional ConfigMap holding the
+	// effective configuration applied by the last successful sync
+	// +optional
+	EffectiveConfigExport *EffectiveConfigExport `json:"effectiveConfigExport,omitempty"`
+
+	// DeployCoreDNS creates a NextDNSCoreDNS bound to this profile, so a
+	// single resource manages both the profile and its DNS forwarder
+	// +optional
+	DeployCoreDNS *DeployCoreDNSSpec `json:"deployCoreDNS,omitempty"`
+
+	// ===========================================
+	// Overlays
+	// ===========================================
+
+	// Overlays defines named sets of list references and settings that can be
+	// layered on top of the base spec (e.g. "strict" and "relaxed")
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Overlays []ProfileOverlay `json:"overlays,omitempty"`
+
+	// ActiveOverlay selects the overlay from Overlays to apply. The merged
+	// result is synced in a single reconcile. Empty applies the base spec only.
+	// +optional
+	ActiveOverlay string `json:"activeOverlay,omitempty"`
+}
+
+// ProfileOverlay is a named set of list references and settings applied on
+// top of the base spec when selected by spec.activeOverlay. List references
+// are added to the base references; settings sections replace the base section.
+type ProfileOverlay struct {
+	// Name identifies the overlay
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// AllowlistRefs references additional NextDNSAllowlist resources
+	// +optional
+	AllowlistRefs []ListReference `json:"allowlistRefs,omitempty"`
+
+	// DenylistRefs references additional NextDNSDenylist resources
+	// +optional
+	DenylistRefs []ListReference `json:"denylistRefs,omitempty"`
+
+	// TLDListRefs references additional NextDNSTLDList resources
+	// +optional
+	TLDListRefs []ListReference `json:"tldListRefs,omitempty"`
+
+	// RewriteRefs references additional NextDNSRewrite resources
+	// +optional
+	RewriteRefs []ListReference `json:"rewriteRefs,omitempty"`
+
+	// Security replaces the base security section when set
+	// +optional
+	Security *SecuritySpec `json:"security,omitempty"`
+
+	// Privacy replaces the base privacy section when set
+	// +optional
+	Privacy *PrivacySpec `json:"privacy,omitempty"`
+
+	// ParentalControl replaces the base parental control section when set
+	// +optional
+	ParentalControl *ParentalControlSpec `json:"parentalControl,omitempty"`
+
+	// Settings replaces the base settings section when set
+	// +optional
+	Settings *SettingsSpec `json:"settings,omitempty"`
 }
 
 // SecuritySpec defines security/threat protection settings
@@ -372,6 +643,9 @@ type AggregatedCounts struct {
 
 	// BlockedTLDs is the total count of blocked TLDs
 	BlockedTLDs int `json:"blockedTLDs,omitempty"`
+
+	// Rewrites is the total count of active DNS rewrites
+	Rewrites int `json:"rewrites,omitempty"`
 }
 
 // ReferencedResources tracks the status of all referenced resources
@@ -387,6 +661,10 @@ type ReferencedResources struct {
 	// TLDLists lists the status of referenced TLD lists
 	// +optional
 	TLDLists []ReferencedResourceStatus `json:"tldLists,omitempty"`
+
+	// Rewrites lists the status of referenced rewrite lists
+	// +optional
+	Rewrites []ReferencedResourceStatus `json:"rewrites,omitempty"`
 }
 
 // SetupLinkedIP contains linked IP DNS configuration from the NextDNS API
@@ -435,6 +713,29 @@ type NextDNSProfileStatus struct {
 	// +optional
 	Fingerprint string `json:"fingerprint,omitempty"`
 
+	// AccountFingerprint identifies the NextDNS account of the profile's API
+	// key. It is derived from a digest of the key, so profiles under the
+	// same account share it without revealing the key.
+	// +optional
+	AccountFingerprint string `json:"accountFingerprint,omitempty"`
+
+	// CredentialsSource is where the API key was read from: the Secret in
+	// spec.credentialsRef, the NextDNSAccount in spec.accountRef or the
+	// operator's default credentials Secret
+	// +optional
+	CredentialsSource CredentialsSource `json:"credentialsSource,omitempty"`
+
+	// CredentialsSecret is the namespace/name of the Secret the API key was
+	// read from
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// CredentialsSecretVersion is the resourceVersion of the credentials
+	// Secret whose API key NextDNS last accepted; a new version is
+	// validated again before the next sync
+	// +optional
+	CredentialsSecretVersion string `json:"credentialsSecretVersion,omitempty"`
+
 	// AggregatedCounts tracks totals from all sources
 	// +optional
 	AggregatedCounts *AggregatedCounts `json:"aggregatedCounts,omitempty"`
@@ -466,10 +767,190 @@ type NextDNSProfileStatus struct {
 	// +optional
 	SuggestedSpec *SuggestedSpec `json:"suggestedSpec,omitempty"`
 
+	// ImportedAt is when the remote configuration was imported into the spec
+	// according to spec.importPolicy
+	// +optional
+	ImportedAt *metav1.Time `json:"importedAt,omitempty"`
+
 	// Setup contains the profile's DNS endpoint configuration
 	// Always populated after successful reconciliation in any mode
 	// +optional
 	Setup *ProfileSetup `json:"setup,omitempty"`
+
+	// AppliedConfigHash is a hash of the desired state last applied to NextDNS.
+	// Remote differences are only reported as drift while it matches the
+	// current desired state.
+	// +optional
+	AppliedConfigHash string `json:"appliedConfigHash,omitempty"`
+
+	// DriftSummary describes how the remote profile differs from the desired
+	// state. Cleared when no drift is detected.
+	// +optional
+	DriftSummary *DriftSummary `json:"driftSummary,omitempty"`
+
+	// Sections reports the latest write of each profile section, so a
+	// failing sync names the section that failed. Sections the spec leaves
+	// unmanaged have no entry. Cleared in observe mode.
+	// +optional
+	Sections *ProfileSectionStatus `json:"sections,omitempty"`
+
+	// BreakGlass describes the window requested by the nextdns.io/break-glass
+	// annotation during which blocking is suspended. Cleared once the
+	// annotation is removed.
+	// +optional
+	BreakGlass *BreakGlassStatus `json:"breakGlass,omitempty"`
+
+	// ManagedEntries records the allowlist and denylist domains applied by the
+	// operator. Only populated when spec.preserveUnmanagedEntries is enabled.
+	// +optional
+	ManagedEntries *ManagedListEntries `json:"managedEntries,omitempty"`
+
+	// Rewrites reports the state of each rewrite applied by the last sync.
+	// Only populated when the profile manages rewrites.
+	// +optional
+	Rewrites []RewriteStatus `json:"rewrites,omitempty"`
+
+	// ActiveOverlay is the overlay applied by the last successful sync
+	// +optional
+	ActiveOverlay string `json:"activeOverlay,omitempty"`
+
+	// CompatibilityLevel is the behavior version applied by the last
+	// successful sync, from the nextdns.io/compatibility-level annotation or
+	// the operator default
+	// +optional
+	CompatibilityLevel int `json:"compatibilityLevel,omitempty"`
+
+	// EffectiveConfigMap is the name of the ConfigMap holding the effective
+	// configuration. Only set when spec.effectiveConfigExport is enabled.
+	// +optional
+	EffectiveConfigMap string `json:"effectiveConfigMap,omitempty"`
+
+	// DeployedCoreDNS is the name of the NextDNSCoreDNS deployed for the
+	// profile. Only set when spec.deployCoreDNS is enabled.
+	// +optional
+	DeployedCoreDNS string `json:"deployedCoreDNS,omitempty"`
+}
+
+// RewriteState is the outcome of applying a rewrite to NextDNS
+// +kubebuilder:validation:Enum=Applied;Rejected
+type RewriteState string
+
+const (
+	// RewriteStateApplied means the rewrite exists in the remote profile
+	RewriteStateApplied RewriteState = "Applied"
+
+	// RewriteStateRejected means NextDNS refused to create the rewrite
+	RewriteStateRejected RewriteState = "Rejected"
+)
+
+// RewriteStatus reports how a desired rewrite was applied
+type RewriteStatus struct {
+	// Name is the domain being rewritten
+	Name string `json:"name"`
+
+	// Content is the rewrite target
+	Content string `json:"content"`
+
+	// State is Applied or Rejected
+	State RewriteState `json:"state"`
+
+	// Type is the record type NextDNS resolved the target to: A or AAAA for
+	// an IP address, CNAME for a hostname
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Message is the reason NextDNS rejected the rewrite
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ManagedListEntries tracks the list domains owned by the operator
+type ManagedListEntries struct {
+	// Denylist contains the denylist domains applied by the operator
+	// +optional
+	Denylist []string `json:"denylist,omitempty"`
+
+	// Allowlist contains the allowlist domains applied by the operator
+	// +optional
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// SectionSyncStatus reports the latest write of one profile section to
+// NextDNS
+type SectionSyncStatus struct {
+	// LastSynced is when the section was last applied with changed content,
+	// or applied again after a failed write
+	// +optional
+	LastSynced *metav1.Time `json:"lastSynced,omitempty"`
+
+	// Hash is a hash of the section content last applied successfully
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// Error is why the latest write of the section failed. Cleared once a
+	// write succeeds.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// BreakGlassStatus describes a break-glass window of a profile
+type BreakGlassStatus struct {
+	// Duration is the nextdns.io/break-glass annotation value the window
+	// was started or last adjusted for
+	Duration string `json:"duration"`
+
+	// StartedAt is when blocking was suspended
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// ExpiresAt is when blocking is restored
+	ExpiresAt metav1.Time `json:"expiresAt"`
+}
+
+// ProfileSectionStatus reports the sync of each section of a profile
+type ProfileSectionStatus struct {
+	// Security is the security settings
+	// +optional
+	Security *SectionSyncStatus `json:"security,omitempty"`
+
+	// Privacy is the privacy settings, blocklists and native tracking
+	// protection
+	// +optional
+	Privacy *SectionSyncStatus `json:"privacy,omitempty"`
+
+	// ParentalControl is the parental control settings
+	// +optional
+	ParentalControl *SectionSyncStatus `json:"parentalControl,omitempty"`
+
+	// Denylist is the denylist entries
+	// +optional
+	Denylist *SectionSyncStatus `json:"denylist,omitempty"`
+
+	// Allowlist is the allowlist entries
+	// +optional
+	Allowlist *SectionSyncStatus `json:"allowlist,omitempty"`
+
+	// TLDs is the blocked top-level domains
+	// +optional
+	TLDs *SectionSyncStatus `json:"tlds,omitempty"`
+
+	// Settings is the logs, block page, performance and web3 settings
+	// +optional
+	Settings *SectionSyncStatus `json:"settings,omitempty"`
+}
+
+// DriftSummary describes differences between the remote profile and the desired state
+type DriftSummary struct {
+	// Sections lists the profile sections that differ (e.g. security, denylist)
+	// +optional
+	Sections []string `json:"sections,omitempty"`
+
+	// Differences describes individual differences, truncated to keep status small
+	// +optional
+	Differences []string `json:"differences,omitempty"`
+
+	// Corrected is true when the desired state was re-applied
+	// +optional
+	Corrected bool `json:"corrected,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -477,6 +958,8 @@ type NextDNSProfileStatus struct {
 // +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
 // +kubebuilder:printcolumn:name="Profile ID",type=string,JSONPath=`.status.profileID`
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Overlay",type=string,JSONPath=`.status.activeOverlay`,priority=1
+// +kubebuilder:printcolumn:name="Drifted",type=string,JSONPath=`.status.conditions[?(@.type=="Drifted")].status`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // NextDNSProfile is the Schema for the nextdnsprofiles API
@@ -0,0 +1,92 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProfileInstance holds the parameters of one generated NextDNSProfile
+type ProfileInstance struct {
+	// Name is the name of the generated NextDNSProfile resource
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// DisplayName is the profile name shown in the NextDNS dashboard.
+	// Defaults to the template's name followed by the instance name, or
+	// the instance name if the template has no name.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// AllowlistRefs are appended to the template's allowlistRefs
+	// +optional
+	AllowlistRefs []ListReference `json:"allowlistRefs,omitempty"`
+
+	// DenylistRefs are appended to the template's denylistRefs
+	// +optional
+	DenylistRefs []ListReference `json:"denylistRefs,omitempty"`
+
+	// LogRetention overrides the template's settings.logs.retention
+	// +kubebuilder:validation:Enum="1h";"6h";"1d";"7d";"30d";"90d";"1y";"2y"
+	// +optional
+	LogRetention string `json:"logRetention,omitempty"`
+}
+
+// NextDNSProfileGeneratorSpec defines the desired state of NextDNSProfileGenerator
+type NextDNSProfileGeneratorSpec struct {
+	// TemplateRef references the NextDNSProfileTemplate the profiles are
+	// generated from
+	// +kubebuilder:validation:Required
+	TemplateRef ResourceReference `json:"templateRef"`
+
+	// Instances lists the NextDNSProfiles to generate in the generator's
+	// namespace. Profiles of instances removed from the list are deleted.
+	// +kubebuilder:validation:MinItems=1
+	Instances []ProfileInstance `json:"instances"`
+}
+
+// NextDNSProfileGeneratorStatus defines the observed state of NextDNSProfileGenerator
+type NextDNSProfileGeneratorStatus struct {
+	// Profiles lists the names of the generated NextDNSProfiles
+	// +optional
+	Profiles []string `json:"profiles,omitempty"`
+
+	// ProfileCount is the number of generated NextDNSProfiles
+	// +optional
+	ProfileCount int `json:"profileCount,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Template",type=string,JSONPath=`.spec.templateRef.name`
+// +kubebuilder:printcolumn:name="Profiles",type=integer,JSONPath=`.status.profileCount`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSProfileGenerator is the Schema for the nextdnsprofilegenerators API.
+// It stamps out one NextDNSProfile per instance from a NextDNSProfileTemplate
+// and keeps them in sync with the template.
+type NextDNSProfileGenerator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSProfileGeneratorSpec   `json:"spec,omitempty"`
+	Status NextDNSProfileGeneratorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSProfileGeneratorList contains a list of NextDNSProfileGenerator
+type NextDNSProfileGeneratorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSProfileGenerator `json:"items"`
+}
@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NextDNSProfileTemplateSpec defines the desired state of NextDNSProfileTemplate
+type NextDNSProfileTemplateSpec struct {
+	// Description is a human-readable description of the template
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Template is the profile spec every NextDNSProfile generated from this
+	// template starts from. NextDNSProfileGenerator instances override the
+	// name, extend the list references and may override the log retention.
+	// +kubebuilder:validation:Required
+	Template NextDNSProfileSpec `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=`.spec.description`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSProfileTemplate is the Schema for the nextdnsprofiletemplates API.
+// It holds a NextDNSProfile spec shared by the profiles a
+// NextDNSProfileGenerator stamps out.
+type NextDNSProfileTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NextDNSProfileTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSProfileTemplateList contains a list of NextDNSProfileTemplate
+type NextDNSProfileTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSProfileTemplate `json:"items"`
+}
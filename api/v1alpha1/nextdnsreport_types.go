@@ -0,0 +1,89 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NextDNSReportSpec defines the desired state of NextDNSReport
+type NextDNSReportSpec struct {
+	// ProfileRefs references the NextDNSProfiles summarized in the report
+	// +kubebuilder:validation:MinItems=1
+	ProfileRefs []ResourceReference `json:"profileRefs"`
+
+	// Interval is the period between reports, e.g. "168h" for a weekly
+	// digest. Each report covers the interval before it was sent.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(m|h)$`
+	// +kubebuilder:default="168h"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// TopBlocked is the number of most blocked domains listed per profile
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=10
+	// +optional
+	TopBlocked int `json:"topBlocked,omitempty"`
+
+	// Webhook is where the report is delivered
+	// +kubebuilder:validation:Required
+	Webhook ReportWebhook `json:"webhook"`
+}
+
+// ReportWebhook is an HTTP endpoint a report is posted to as JSON. Exactly
+// one of url or urlSecretRef must be set.
+type ReportWebhook struct {
+	// URL is the endpoint the report is posted to
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef references a key of a Secret in the report's namespace
+	// holding the endpoint, for webhook URLs that embed a token
+	// +optional
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+}
+
+// NextDNSReportStatus defines the observed state of NextDNSReport
+type NextDNSReportStatus struct {
+	// LastReportTime is when the last report was delivered
+	// +optional
+	LastReportTime *metav1.Time `json:"lastReportTime,omitempty"`
+
+	// NextReportTime is when the next report is due
+	// +optional
+	NextReportTime *metav1.Time `json:"nextReportTime,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Interval",type=string,JSONPath=`.spec.interval`
+// +kubebuilder:printcolumn:name="Last Report",type=date,JSONPath=`.status.lastReportTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSReport is the Schema for the nextdnsreports API
+type NextDNSReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSReportSpec   `json:"spec,omitempty"`
+	Status NextDNSReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSReportList contains a list of NextDNSReport
+type NextDNSReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSReport `json:"items"`
+}
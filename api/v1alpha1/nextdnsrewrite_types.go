@@ -0,0 +1,54 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NextDNSRewriteSpec defines the desired state of NextDNSRewrite
+type NextDNSRewriteSpec struct {
+	// Description provides context for this rewrite list
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Rewrites is the list of DNS rewrites to apply
+	// +kubebuilder:validation:MinItems=1
+	Rewrites []RewriteEntry `json:"rewrites"`
+}
+
+// NextDNSRewriteStatus defines the observed state of NextDNSRewrite
+type NextDNSRewriteStatus struct {
+	// RewriteCount is the number of active rewrites
+	// +optional
+	RewriteCount int `json:"rewriteCount,omitempty"`
+
+	// ProfileRefs lists profiles using this rewrite list
+	// +optional
+	ProfileRefs []ResourceReference `json:"profileRefs,omitempty"`
+
+	// Conditions represent the latest available observations
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rewrites",type=integer,JSONPath=`.status.rewriteCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NextDNSRewrite is the Schema for the nextdnsrewrites API
+type NextDNSRewrite struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NextDNSRewriteSpec   `json:"spec,omitempty"`
+	Status NextDNSRewriteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NextDNSRewriteList contains a list of NextDNSRewrite
+type NextDNSRewriteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NextDNSRewrite `json:"items"`
+}
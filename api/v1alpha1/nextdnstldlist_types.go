@@ -31,8 +31,13 @@ type NextDNSTLDListSpec struct {
 	Description string `json:"description,omitempty"`
 
 	// TLDs is the list of top-level domains to block
-	// +kubebuilder:validation:MinItems=1
-	TLDs []TLDEntry `json:"tlds"`
+	// +optional
+	TLDs []TLDEntry `json:"tlds,omitempty"`
+
+	// Sources fetches additional entries from HTTP(S) URLs, OCI artifacts,
+	// or Git repositories. Fetched entries are active.
+	// +optional
+	Sources []ListSource `json:"sources,omitempty"`
 }
 
 // NextDNSTLDListStatus defines the observed state of NextDNSTLDList
@@ -41,6 +46,10 @@ type NextDNSTLDListStatus struct {
 	// +optional
 	TLDCount int `json:"tldCount,omitempty"`
 
+	// Sources reports the last fetch of each entry in spec.sources
+	// +optional
+	Sources []ListSourceStatus `json:"sources,omitempty"`
+
 	// ProfileRefs lists profiles using this TLD list
 	// +optional
 	ProfileRefs []ResourceReference `json:"profileRefs,omitempty"`
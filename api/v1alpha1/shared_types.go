@@ -1,5 +1,9 @@
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // ResourceReference identifies a Kubernetes resource
 type ResourceReference struct {
 	// Name of the resource
@@ -10,15 +14,41 @@ type ResourceReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// Kinds of list resources in list references
+const (
+	// KindAllowlist is the kind of NextDNSAllowlist resources
+	KindAllowlist = "NextDNSAllowlist"
+
+	// KindClusterAllowlist is the kind of ClusterNextDNSAllowlist resources
+	KindClusterAllowlist = "ClusterNextDNSAllowlist"
+
+	// KindDenylist is the kind of NextDNSDenylist resources
+	KindDenylist = "NextDNSDenylist"
+
+	// KindClusterDenylist is the kind of ClusterNextDNSDenylist resources
+	KindClusterDenylist = "ClusterNextDNSDenylist"
+
+	// KindDenylistSource is the kind of NextDNSDenylistSource resources
+	KindDenylistSource = "NextDNSDenylistSource"
+)
+
 // ListReference references a list CRD (allowlist, denylist, or TLD list)
 type ListReference struct {
 	// Name of the list resource
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
 
-	// Namespace of the list resource (defaults to profile's namespace)
+	// Namespace of the list resource (defaults to profile's namespace).
+	// Ignored for cluster-scoped kinds.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+
+	// Kind of the list resource. Defaults to the kind the field references.
+	// allowlistRefs also accept ClusterNextDNSAllowlist; denylistRefs also
+	// accept ClusterNextDNSDenylist and NextDNSDenylistSource.
+	// +optional
+	// +kubebuilder:validation:Enum=NextDNSAllowlist;ClusterNextDNSAllowlist;NextDNSDenylist;ClusterNextDNSDenylist;NextDNSDenylistSource
+	Kind string `json:"kind,omitempty"`
 }
 
 // SecretKeySelector references a key in a Secret
@@ -78,13 +108,140 @@ type ReferencedResourceStatus struct {
 	// Name of the resource
 	Name string `json:"name"`
 
-	// Namespace of the resource
+	// Namespace of the resource; empty for cluster-scoped lists
 	Namespace string `json:"namespace"`
 
+	// Kind of the resource when the reference sets one
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
 	// Ready indicates if the resource is ready
 	Ready bool `json:"ready"`
 
 	// Count of items (domains or TLDs)
 	// +optional
 	Count int `json:"count,omitempty"`
+
+	// ContentHash identifies the resolved entries of the resource, so profiles
+	// applying the same list content report the same hash
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// ListSource fetches list entries from outside the cluster. Exactly one of
+// HTTP, OCI, or Git must be set. The fetched file holds one entry per line;
+// blank lines, comments starting with # or !, and hosts-file addresses are
+// ignored.
+type ListSource struct {
+	// HTTP fetches the list from an HTTP(S) URL
+	// +optional
+	HTTP *HTTPListSource `json:"http,omitempty"`
+
+	// OCI fetches the list from an OCI artifact in a container registry
+	// +optional
+	OCI *OCIListSource `json:"oci,omitempty"`
+
+	// Git fetches the list from a file in a Git repository
+	// +optional
+	Git *GitListSource `json:"git,omitempty"`
+
+	// Checksum pins the fetched content to a SHA-256 digest. Content with a
+	// different digest is rejected.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Checksum string `json:"checksum,omitempty"`
+
+	// Cosign requires a valid cosign signature of the fetched content. Content
+	// that fails verification is rejected.
+	// +optional
+	Cosign *CosignVerification `json:"cosign,omitempty"`
+
+	// Interval between fetches of the source
+	// +optional
+	// +kubebuilder:default="1h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	Interval string `json:"interval,omitempty"`
+}
+
+// CosignVerification verifies a cosign signature made with a key pair
+type CosignVerification struct {
+	// PublicKey is the PEM-encoded public key the content is signed with
+	// (cosign.pub)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	PublicKey string `json:"publicKey"`
+
+	// Signature locates the base64 signature created by "cosign sign-blob":
+	// a URL for HTTP sources (default "<url>.sig") or a path in the same
+	// commit for Git sources (default "<path>.sig"). OCI sources use the
+	// signature pushed to the registry by "cosign sign".
+	// +optional
+	Signature string `json:"signature,omitempty"`
+}
+
+// HTTPListSource fetches a list from an HTTP(S) URL
+type HTTPListSource struct {
+	// URL of the list file
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+}
+
+// OCIListSource fetches a list from an OCI artifact
+type OCIListSource struct {
+	// Reference is the artifact reference, e.g. ghcr.io/org/lists:v1 or
+	// ghcr.io/org/lists@sha256:<digest>
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Reference string `json:"reference"`
+
+	// File selects the layer by its org.opencontainers.image.title
+	// annotation. Required when the artifact has more than one layer.
+	// +optional
+	File string `json:"file,omitempty"`
+}
+
+// GitListSource fetches a list from a file in a Git repository
+type GitListSource struct {
+	// URL of the repository, cloned over HTTP(S)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Ref is the branch, tag, or full reference name to read. Defaults to
+	// the repository's default branch.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Path of the list file within the repository
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+// ListSourceStatus reports the last fetch of a list source
+type ListSourceStatus struct {
+	// Source identifies the fetched URL or reference
+	Source string `json:"source"`
+
+	// Revision is the source's version of the content: the OCI manifest
+	// digest, the Git commit, or the HTTP ETag
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Digest is the SHA-256 digest of the fetched content
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Count is the number of entries read from the source
+	// +optional
+	Count int `json:"count,omitempty"`
+
+	// LastFetchTime is when the source was last fetched successfully
+	// +optional
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+
+	// Error is the last fetch error, if any
+	// +optional
+	Error string `json:"error,omitempty"`
 }
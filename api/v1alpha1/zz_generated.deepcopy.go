@@ -23,10 +23,40 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountProfile) DeepCopyInto(out *AccountProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountProfile.
+func (in *AccountProfile) DeepCopy() *AccountProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountRateLimit) DeepCopyInto(out *AccountRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountRateLimit.
+func (in *AccountRateLimit) DeepCopy() *AccountRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AggregatedCounts) DeepCopyInto(out *AggregatedCounts) {
 	*out = *in
@@ -82,6 +112,23 @@ func (in *BlocklistEntry) DeepCopy() *BlocklistEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BreakGlassStatus) DeepCopyInto(out *BreakGlassStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakGlassStatus.
+func (in *BreakGlassStatus) DeepCopy() *BreakGlassStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BreakGlassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CategoryEntry) DeepCopyInto(out *CategoryEntry) {
 	*out = *in
@@ -108,773 +155,2717 @@ func (in *CategoryEntry) DeepCopy() *CategoryEntry {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
+func (in *ClusterDNSIntegrationConfig) DeepCopyInto(out *ClusterDNSIntegrationConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
-func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDNSIntegrationConfig.
+func (in *ClusterDNSIntegrationConfig) DeepCopy() *ClusterDNSIntegrationConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ConfigMapRef)
+	out := new(ClusterDNSIntegrationConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConsolidateRule) DeepCopyInto(out *ConsolidateRule) {
+func (in *ClusterDNSStatus) DeepCopyInto(out *ClusterDNSStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidateRule.
-func (in *ConsolidateRule) DeepCopy() *ConsolidateRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDNSStatus.
+func (in *ClusterDNSStatus) DeepCopy() *ClusterDNSStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ConsolidateRule)
+	out := new(ClusterDNSStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSCacheConfig) DeepCopyInto(out *CoreDNSCacheConfig) {
+func (in *ClusterNextDNSAllowlist) DeepCopyInto(out *ClusterNextDNSAllowlist) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.SuccessTTL != nil {
-		in, out := &in.SuccessTTL, &out.SuccessTTL
-		*out = new(int32)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSCacheConfig.
-func (in *CoreDNSCacheConfig) DeepCopy() *CoreDNSCacheConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNextDNSAllowlist.
+func (in *ClusterNextDNSAllowlist) DeepCopy() *ClusterNextDNSAllowlist {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSCacheConfig)
+	out := new(ClusterNextDNSAllowlist)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNextDNSAllowlist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSDeploymentConfig) DeepCopyInto(out *CoreDNSDeploymentConfig) {
+func (in *ClusterNextDNSAllowlistList) DeepCopyInto(out *ClusterNextDNSAllowlistList) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterNextDNSAllowlist, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PodAnnotations != nil {
-		in, out := &in.PodAnnotations, &out.PodAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNextDNSAllowlistList.
+func (in *ClusterNextDNSAllowlistList) DeepCopy() *ClusterNextDNSAllowlistList {
+	if in == nil {
+		return nil
 	}
-	if in.PodDisruptionBudget != nil {
-		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
-		*out = new(CoreDNSPDBConfig)
-		(*in).DeepCopyInto(*out)
+	out := new(ClusterNextDNSAllowlistList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNextDNSAllowlistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
+	return nil
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSDeploymentConfig.
-func (in *CoreDNSDeploymentConfig) DeepCopy() *CoreDNSDeploymentConfig {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNextDNSDenylist) DeepCopyInto(out *ClusterNextDNSDenylist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNextDNSDenylist.
+func (in *ClusterNextDNSDenylist) DeepCopy() *ClusterNextDNSDenylist {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSDeploymentConfig)
+	out := new(ClusterNextDNSDenylist)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNextDNSDenylist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSErrorsConfig) DeepCopyInto(out *CoreDNSErrorsConfig) {
+func (in *ClusterNextDNSDenylistList) DeepCopyInto(out *ClusterNextDNSDenylistList) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Consolidate != nil {
-		in, out := &in.Consolidate, &out.Consolidate
-		*out = make([]ConsolidateRule, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterNextDNSDenylist, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSErrorsConfig.
-func (in *CoreDNSErrorsConfig) DeepCopy() *CoreDNSErrorsConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNextDNSDenylistList.
+func (in *ClusterNextDNSDenylistList) DeepCopy() *ClusterNextDNSDenylistList {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSErrorsConfig)
+	out := new(ClusterNextDNSDenylistList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNextDNSDenylistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSHealthConfig) DeepCopyInto(out *CoreDNSHealthConfig) {
+func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int32)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSHealthConfig.
-func (in *CoreDNSHealthConfig) DeepCopy() *CoreDNSHealthConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
+func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSHealthConfig)
+	out := new(ConfigMapRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSLoggingConfig) DeepCopyInto(out *CoreDNSLoggingConfig) {
+func (in *ConsolidateRule) DeepCopyInto(out *ConsolidateRule) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSLoggingConfig.
-func (in *CoreDNSLoggingConfig) DeepCopy() *CoreDNSLoggingConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidateRule.
+func (in *ConsolidateRule) DeepCopy() *ConsolidateRule {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSLoggingConfig)
+	out := new(ConsolidateRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSMetricsConfig) DeepCopyInto(out *CoreDNSMetricsConfig) {
+func (in *CoreDNSAccessControlConfig) DeepCopyInto(out *CoreDNSAccessControlConfig) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
+	if in.AllowCIDRs != nil {
+		in, out := &in.AllowCIDRs, &out.AllowCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int32)
-		**out = **in
+	if in.DenyCIDRs != nil {
+		in, out := &in.DenyCIDRs, &out.DenyCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSMetricsConfig.
-func (in *CoreDNSMetricsConfig) DeepCopy() *CoreDNSMetricsConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSAccessControlConfig.
+func (in *CoreDNSAccessControlConfig) DeepCopy() *CoreDNSAccessControlConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSMetricsConfig)
+	out := new(CoreDNSAccessControlConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSPDBConfig) DeepCopyInto(out *CoreDNSPDBConfig) {
+func (in *CoreDNSAutoscalingConfig) DeepCopyInto(out *CoreDNSAutoscalingConfig) {
 	*out = *in
-	if in.MinAvailable != nil {
-		in, out := &in.MinAvailable, &out.MinAvailable
-		*out = new(intstr.IntOrString)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
-	if in.MaxUnavailable != nil {
-		in, out := &in.MaxUnavailable, &out.MaxUnavailable
-		*out = new(intstr.IntOrString)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
 		**out = **in
 	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetQueriesPerSecond != nil {
+		in, out := &in.TargetQueriesPerSecond, &out.TargetQueriesPerSecond
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSPDBConfig.
-func (in *CoreDNSPDBConfig) DeepCopy() *CoreDNSPDBConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSAutoscalingConfig.
+func (in *CoreDNSAutoscalingConfig) DeepCopy() *CoreDNSAutoscalingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSPDBConfig)
+	out := new(CoreDNSAutoscalingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSReadyConfig) DeepCopyInto(out *CoreDNSReadyConfig) {
+func (in *CoreDNSCacheConfig) DeepCopyInto(out *CoreDNSCacheConfig) {
 	*out = *in
 	if in.Enabled != nil {
 		in, out := &in.Enabled, &out.Enabled
 		*out = new(bool)
 		**out = **in
 	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
+	if in.SuccessTTL != nil {
+		in, out := &in.SuccessTTL, &out.SuccessTTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DenialTTL != nil {
+		in, out := &in.DenialTTL, &out.DenialTTL
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Prefetch != nil {
+		in, out := &in.Prefetch, &out.Prefetch
+		*out = new(CoreDNSCachePrefetchConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServeStale != nil {
+		in, out := &in.ServeStale, &out.ServeStale
+		*out = new(CoreDNSCacheServeStaleConfig)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSReadyConfig.
-func (in *CoreDNSReadyConfig) DeepCopy() *CoreDNSReadyConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSCacheConfig.
+func (in *CoreDNSCacheConfig) DeepCopy() *CoreDNSCacheConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSReadyConfig)
+	out := new(CoreDNSCacheConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CoreDNSServiceConfig) DeepCopyInto(out *CoreDNSServiceConfig) {
+func (in *CoreDNSCachePrefetchConfig) DeepCopyInto(out *CoreDNSCachePrefetchConfig) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSServiceConfig.
-func (in *CoreDNSServiceConfig) DeepCopy() *CoreDNSServiceConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSCachePrefetchConfig.
+func (in *CoreDNSCachePrefetchConfig) DeepCopy() *CoreDNSCachePrefetchConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CoreDNSServiceConfig)
+	out := new(CoreDNSCachePrefetchConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CorefileSpec) DeepCopyInto(out *CorefileSpec) {
+func (in *CoreDNSCacheServeStaleConfig) DeepCopyInto(out *CoreDNSCacheServeStaleConfig) {
 	*out = *in
-	if in.Upstream != nil {
-		in, out := &in.Upstream, &out.Upstream
-		*out = new(UpstreamConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSCacheServeStaleConfig.
+func (in *CoreDNSCacheServeStaleConfig) DeepCopy() *CoreDNSCacheServeStaleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSCacheServeStaleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSDeploymentConfig) DeepCopyInto(out *CoreDNSDeploymentConfig) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Cache != nil {
-		in, out := &in.Cache, &out.Cache
-		*out = new(CoreDNSCacheConfig)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Metrics != nil {
-		in, out := &in.Metrics, &out.Metrics
-		*out = new(CoreDNSMetricsConfig)
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(CoreDNSServiceAccountConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Logging != nil {
-		in, out := &in.Logging, &out.Logging
-		*out = new(CoreDNSLoggingConfig)
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(CoreDNSPodSecurityConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.DomainOverrides != nil {
-		in, out := &in.DomainOverrides, &out.DomainOverrides
-		*out = make([]DomainOverride, len(*in))
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]corev1.Container, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Rewrite != nil {
-		in, out := &in.Rewrite, &out.Rewrite
-		*out = make([]RewriteRule, len(*in))
-		copy(*out, *in)
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = new(HostsConfig)
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(CoreDNSUpdateStrategyConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Health != nil {
-		in, out := &in.Health, &out.Health
-		*out = new(CoreDNSHealthConfig)
-		(*in).DeepCopyInto(*out)
+	if in.MinReadySeconds != nil {
+		in, out := &in.MinReadySeconds, &out.MinReadySeconds
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Ready != nil {
-		in, out := &in.Ready, &out.Ready
-		*out = new(CoreDNSReadyConfig)
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(CoreDNSPDBConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Errors != nil {
-		in, out := &in.Errors, &out.Errors
-		*out = new(CoreDNSErrorsConfig)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(CoreDNSAutoscalingConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HostPort != nil {
+		in, out := &in.HostPort, &out.HostPort
+		*out = new(CoreDNSHostPortConfig)
+		**out = **in
+	}
+	if in.NodeLocal != nil {
+		in, out := &in.NodeLocal, &out.NodeLocal
+		*out = new(CoreDNSNodeLocalConfig)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CorefileSpec.
-func (in *CorefileSpec) DeepCopy() *CorefileSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSDeploymentConfig.
+func (in *CoreDNSDeploymentConfig) DeepCopy() *CoreDNSDeploymentConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CorefileSpec)
+	out := new(CoreDNSDeploymentConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DNSEndpoint) DeepCopyInto(out *DNSEndpoint) {
+func (in *CoreDNSDoHListenerConfig) DeepCopyInto(out *CoreDNSDoHListenerConfig) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSEndpoint.
-func (in *DNSEndpoint) DeepCopy() *DNSEndpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSDoHListenerConfig.
+func (in *CoreDNSDoHListenerConfig) DeepCopy() *CoreDNSDoHListenerConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DNSEndpoint)
+	out := new(CoreDNSDoHListenerConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DomainEntry) DeepCopyInto(out *DomainEntry) {
+func (in *CoreDNSDoTListenerConfig) DeepCopyInto(out *CoreDNSDoTListenerConfig) {
 	*out = *in
-	if in.Active != nil {
-		in, out := &in.Active, &out.Active
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainEntry.
-func (in *DomainEntry) DeepCopy() *DomainEntry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSDoTListenerConfig.
+func (in *CoreDNSDoTListenerConfig) DeepCopy() *CoreDNSDoTListenerConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DomainEntry)
+	out := new(CoreDNSDoTListenerConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DomainOverride) DeepCopyInto(out *DomainOverride) {
+func (in *CoreDNSErrorsConfig) DeepCopyInto(out *CoreDNSErrorsConfig) {
 	*out = *in
-	if in.Upstreams != nil {
-		in, out := &in.Upstreams, &out.Upstreams
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.CacheTTL != nil {
-		in, out := &in.CacheTTL, &out.CacheTTL
-		*out = new(int32)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
+	if in.Consolidate != nil {
+		in, out := &in.Consolidate, &out.Consolidate
+		*out = make([]ConsolidateRule, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainOverride.
-func (in *DomainOverride) DeepCopy() *DomainOverride {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSErrorsConfig.
+func (in *CoreDNSErrorsConfig) DeepCopy() *CoreDNSErrorsConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DomainOverride)
+	out := new(CoreDNSErrorsConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ForwardTuningConfig) DeepCopyInto(out *ForwardTuningConfig) {
+func (in *CoreDNSHealthConfig) DeepCopyInto(out *CoreDNSHealthConfig) {
 	*out = *in
-	if in.MaxConcurrent != nil {
-		in, out := &in.MaxConcurrent, &out.MaxConcurrent
-		*out = new(int32)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
-	if in.MaxFails != nil {
-		in, out := &in.MaxFails, &out.MaxFails
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardTuningConfig.
-func (in *ForwardTuningConfig) DeepCopy() *ForwardTuningConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSHealthConfig.
+func (in *CoreDNSHealthConfig) DeepCopy() *CoreDNSHealthConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ForwardTuningConfig)
+	out := new(CoreDNSHealthConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewayAddress) DeepCopyInto(out *GatewayAddress) {
+func (in *CoreDNSHostPortConfig) DeepCopyInto(out *CoreDNSHostPortConfig) {
 	*out = *in
-	if in.Type != nil {
-		in, out := &in.Type, &out.Type
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAddress.
-func (in *GatewayAddress) DeepCopy() *GatewayAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSHostPortConfig.
+func (in *CoreDNSHostPortConfig) DeepCopy() *CoreDNSHostPortConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewayAddress)
+	out := new(CoreDNSHostPortConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
+func (in *CoreDNSListenersConfig) DeepCopyInto(out *CoreDNSListenersConfig) {
 	*out = *in
-	if in.GatewayClassName != nil {
-		in, out := &in.GatewayClassName, &out.GatewayClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]GatewayAddress, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.DoH != nil {
+		in, out := &in.DoH, &out.DoH
+		*out = new(CoreDNSDoHListenerConfig)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Infrastructure != nil {
-		in, out := &in.Infrastructure, &out.Infrastructure
-		*out = new(GatewayInfrastructure)
+	if in.DoT != nil {
+		in, out := &in.DoT, &out.DoT
+		*out = new(CoreDNSDoTListenerConfig)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayConfig.
-func (in *GatewayConfig) DeepCopy() *GatewayConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSListenersConfig.
+func (in *CoreDNSListenersConfig) DeepCopy() *CoreDNSListenersConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewayConfig)
+	out := new(CoreDNSListenersConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewayInfrastructure) DeepCopyInto(out *GatewayInfrastructure) {
+func (in *CoreDNSLoggingConfig) DeepCopyInto(out *CoreDNSLoggingConfig) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.ParametersRef != nil {
-		in, out := &in.ParametersRef, &out.ParametersRef
-		*out = new(GatewayParametersReference)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayInfrastructure.
-func (in *GatewayInfrastructure) DeepCopy() *GatewayInfrastructure {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSLoggingConfig.
+func (in *CoreDNSLoggingConfig) DeepCopy() *CoreDNSLoggingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewayInfrastructure)
+	out := new(CoreDNSLoggingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewayParametersReference) DeepCopyInto(out *GatewayParametersReference) {
+func (in *CoreDNSMetricsConfig) DeepCopyInto(out *CoreDNSMetricsConfig) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParametersReference.
-func (in *GatewayParametersReference) DeepCopy() *GatewayParametersReference {
-	if in == nil {
-		return nil
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
-	out := new(GatewayParametersReference)
-	in.DeepCopyInto(out)
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExcludeDomains != nil {
+		in, out := &in.ExcludeDomains, &out.ExcludeDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(CoreDNSServiceMonitorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSMetricsConfig.
+func (in *CoreDNSMetricsConfig) DeepCopy() *CoreDNSMetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSMetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSNetworkPolicyConfig) DeepCopyInto(out *CoreDNSNetworkPolicyConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSNetworkPolicyConfig.
+func (in *CoreDNSNetworkPolicyConfig) DeepCopy() *CoreDNSNetworkPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSNetworkPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSNodeLocalConfig) DeepCopyInto(out *CoreDNSNodeLocalConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSNodeLocalConfig.
+func (in *CoreDNSNodeLocalConfig) DeepCopy() *CoreDNSNodeLocalConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSNodeLocalConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSPDBConfig) DeepCopyInto(out *CoreDNSPDBConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSPDBConfig.
+func (in *CoreDNSPDBConfig) DeepCopy() *CoreDNSPDBConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSPDBConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSPodSecurityConfig) DeepCopyInto(out *CoreDNSPodSecurityConfig) {
+	*out = *in
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(corev1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSPodSecurityConfig.
+func (in *CoreDNSPodSecurityConfig) DeepCopy() *CoreDNSPodSecurityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSPodSecurityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSRateLimitConfig) DeepCopyInto(out *CoreDNSRateLimitConfig) {
+	*out = *in
+	if in.QueriesPerSecond != nil {
+		in, out := &in.QueriesPerSecond, &out.QueriesPerSecond
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]RateLimitRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSRateLimitConfig.
+func (in *CoreDNSRateLimitConfig) DeepCopy() *CoreDNSRateLimitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSRateLimitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSReadinessConfig) DeepCopyInto(out *CoreDNSReadinessConfig) {
+	*out = *in
+	if in.MinReadyReplicas != nil {
+		in, out := &in.MinReadyReplicas, &out.MinReadyReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSReadinessConfig.
+func (in *CoreDNSReadinessConfig) DeepCopy() *CoreDNSReadinessConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSReadinessConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSReadyConfig) DeepCopyInto(out *CoreDNSReadyConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSReadyConfig.
+func (in *CoreDNSReadyConfig) DeepCopy() *CoreDNSReadyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSReadyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSServiceAccountConfig) DeepCopyInto(out *CoreDNSServiceAccountConfig) {
+	*out = *in
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSServiceAccountConfig.
+func (in *CoreDNSServiceAccountConfig) DeepCopy() *CoreDNSServiceAccountConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSServiceAccountConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSServiceConfig) DeepCopyInto(out *CoreDNSServiceConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(corev1.IPFamilyPolicy)
+		**out = **in
+	}
+	if in.InternalTrafficPolicy != nil {
+		in, out := &in.InternalTrafficPolicy, &out.InternalTrafficPolicy
+		*out = new(corev1.ServiceInternalTrafficPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSServiceConfig.
+func (in *CoreDNSServiceConfig) DeepCopy() *CoreDNSServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSServiceMonitorConfig) DeepCopyInto(out *CoreDNSServiceMonitorConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSServiceMonitorConfig.
+func (in *CoreDNSServiceMonitorConfig) DeepCopy() *CoreDNSServiceMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSServiceMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSUpdateStrategyConfig) DeepCopyInto(out *CoreDNSUpdateStrategyConfig) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSUpdateStrategyConfig.
+func (in *CoreDNSUpdateStrategyConfig) DeepCopy() *CoreDNSUpdateStrategyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSUpdateStrategyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CorefileSpec) DeepCopyInto(out *CorefileSpec) {
+	*out = *in
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(CoreDNSCacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(CoreDNSMetricsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(CoreDNSLoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DomainOverrides != nil {
+		in, out := &in.DomainOverrides, &out.DomainOverrides
+		*out = make([]DomainOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rewrite != nil {
+		in, out := &in.Rewrite, &out.Rewrite
+		*out = make([]RewriteRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = new(HostsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LocalRecords != nil {
+		in, out := &in.LocalRecords, &out.LocalRecords
+		*out = make([]LocalRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LocalZoneTransfer != nil {
+		in, out := &in.LocalZoneTransfer, &out.LocalZoneTransfer
+		*out = new(LocalZoneTransferConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SearchPath != nil {
+		in, out := &in.SearchPath, &out.SearchPath
+		*out = new(SearchPathConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(CoreDNSRateLimitConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(CoreDNSHealthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ready != nil {
+		in, out := &in.Ready, &out.Ready
+		*out = new(CoreDNSReadyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = new(CoreDNSErrorsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CorefileSpec.
+func (in *CorefileSpec) DeepCopy() *CorefileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CorefileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosignVerification) DeepCopyInto(out *CosignVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosignVerification.
+func (in *CosignVerification) DeepCopy() *CosignVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CosignVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSEndpoint) DeepCopyInto(out *DNSEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSEndpoint.
+func (in *DNSEndpoint) DeepCopy() *DNSEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployCoreDNSSpec) DeepCopyInto(out *DeployCoreDNSSpec) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(DeployCoreDNSTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployCoreDNSSpec.
+func (in *DeployCoreDNSSpec) DeepCopy() *DeployCoreDNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployCoreDNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployCoreDNSTemplate) DeepCopyInto(out *DeployCoreDNSTemplate) {
+	*out = *in
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(CoreDNSDeploymentConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(CoreDNSServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Corefile != nil {
+		in, out := &in.Corefile, &out.Corefile
+		*out = new(CorefileSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployCoreDNSTemplate.
+func (in *DeployCoreDNSTemplate) DeepCopy() *DeployCoreDNSTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployCoreDNSTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainEntry) DeepCopyInto(out *DomainEntry) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainEntry.
+func (in *DomainEntry) DeepCopy() *DomainEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainOverride) DeepCopyInto(out *DomainOverride) {
+	*out = *in
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CacheTTL != nil {
+		in, out := &in.CacheTTL, &out.CacheTTL
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainOverride.
+func (in *DomainOverride) DeepCopy() *DomainOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftSummary) DeepCopyInto(out *DriftSummary) {
+	*out = *in
+	if in.Sections != nil {
+		in, out := &in.Sections, &out.Sections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Differences != nil {
+		in, out := &in.Differences, &out.Differences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftSummary.
+func (in *DriftSummary) DeepCopy() *DriftSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectiveConfigExport) DeepCopyInto(out *EffectiveConfigExport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectiveConfigExport.
+func (in *EffectiveConfigExport) DeepCopy() *EffectiveConfigExport {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectiveConfigExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForwardTuningConfig) DeepCopyInto(out *ForwardTuningConfig) {
+	*out = *in
+	if in.MaxConcurrent != nil {
+		in, out := &in.MaxConcurrent, &out.MaxConcurrent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxFails != nil {
+		in, out := &in.MaxFails, &out.MaxFails
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardTuningConfig.
+func (in *ForwardTuningConfig) DeepCopy() *ForwardTuningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ForwardTuningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAddress) DeepCopyInto(out *GatewayAddress) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAddress.
+func (in *GatewayAddress) DeepCopy() *GatewayAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
+	*out = *in
+	if in.GatewayClassName != nil {
+		in, out := &in.GatewayClassName, &out.GatewayClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]GatewayAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Infrastructure != nil {
+		in, out := &in.Infrastructure, &out.Infrastructure
+		*out = new(GatewayInfrastructure)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayConfig.
+func (in *GatewayConfig) DeepCopy() *GatewayConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayInfrastructure) DeepCopyInto(out *GatewayInfrastructure) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParametersRef != nil {
+		in, out := &in.ParametersRef, &out.ParametersRef
+		*out = new(GatewayParametersReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayInfrastructure.
+func (in *GatewayInfrastructure) DeepCopy() *GatewayInfrastructure {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayInfrastructure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayParametersReference) DeepCopyInto(out *GatewayParametersReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParametersReference.
+func (in *GatewayParametersReference) DeepCopy() *GatewayParametersReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayParametersReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitListSource) DeepCopyInto(out *GitListSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitListSource.
+func (in *GitListSource) DeepCopy() *GitListSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitListSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPListSource) DeepCopyInto(out *HTTPListSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPListSource.
+func (in *HTTPListSource) DeepCopy() *HTTPListSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPListSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostsConfig) DeepCopyInto(out *HostsConfig) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]HostsEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fallthrough != nil {
+		in, out := &in.Fallthrough, &out.Fallthrough
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostsConfig.
+func (in *HostsConfig) DeepCopy() *HostsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HostsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostsEntry) DeepCopyInto(out *HostsEntry) {
+	*out = *in
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostsEntry.
+func (in *HostsEntry) DeepCopy() *HostsEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(HostsEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListReference) DeepCopyInto(out *ListReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListReference.
+func (in *ListReference) DeepCopy() *ListReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ListReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListSource) DeepCopyInto(out *ListSource) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPListSource)
+		**out = **in
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCIListSource)
+		**out = **in
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitListSource)
+		**out = **in
+	}
+	if in.Cosign != nil {
+		in, out := &in.Cosign, &out.Cosign
+		*out = new(CosignVerification)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListSource.
+func (in *ListSource) DeepCopy() *ListSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ListSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListSourceStatus) DeepCopyInto(out *ListSourceStatus) {
+	*out = *in
+	if in.LastFetchTime != nil {
+		in, out := &in.LastFetchTime, &out.LastFetchTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListSourceStatus.
+func (in *ListSourceStatus) DeepCopy() *ListSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalRecord) DeepCopyInto(out *LocalRecord) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalRecord.
+func (in *LocalRecord) DeepCopy() *LocalRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalZoneStatus) DeepCopyInto(out *LocalZoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalZoneStatus.
+func (in *LocalZoneStatus) DeepCopy() *LocalZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalZoneTransferConfig) DeepCopyInto(out *LocalZoneTransferConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalZoneTransferConfig.
+func (in *LocalZoneTransferConfig) DeepCopy() *LocalZoneTransferConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalZoneTransferConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogsSpec) DeepCopyInto(out *LogsSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogClientsIPs != nil {
+		in, out := &in.LogClientsIPs, &out.LogClientsIPs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogDomains != nil {
+		in, out := &in.LogDomains, &out.LogDomains
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogsSpec.
+func (in *LogsSpec) DeepCopy() *LogsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedListEntries) DeepCopyInto(out *ManagedListEntries) {
+	*out = *in
+	if in.Denylist != nil {
+		in, out := &in.Denylist, &out.Denylist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Allowlist != nil {
+		in, out := &in.Allowlist, &out.Allowlist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedListEntries.
+func (in *ManagedListEntries) DeepCopy() *ManagedListEntries {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedListEntries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultusConfig) DeepCopyInto(out *MultusConfig) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultusConfig.
+func (in *MultusConfig) DeepCopy() *MultusConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MultusConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NativeEntry) DeepCopyInto(out *NativeEntry) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NativeEntry.
+func (in *NativeEntry) DeepCopy() *NativeEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(NativeEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAccount) DeepCopyInto(out *NextDNSAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAccount.
+func (in *NextDNSAccount) DeepCopy() *NextDNSAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAccountList) DeepCopyInto(out *NextDNSAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAccountList.
+func (in *NextDNSAccountList) DeepCopy() *NextDNSAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAccountSpec) DeepCopyInto(out *NextDNSAccountSpec) {
+	*out = *in
+	out.CredentialsRef = in.CredentialsRef
+	if in.ProfileLimit != nil {
+		in, out := &in.ProfileLimit, &out.ProfileLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(AccountRateLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAccountSpec.
+func (in *NextDNSAccountSpec) DeepCopy() *NextDNSAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAccountStatus) DeepCopyInto(out *NextDNSAccountStatus) {
+	*out = *in
+	if in.RemainingProfiles != nil {
+		in, out := &in.RemainingProfiles, &out.RemainingProfiles
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]AccountProfile, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAccountStatus.
+func (in *NextDNSAccountStatus) DeepCopy() *NextDNSAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAllowlist) DeepCopyInto(out *NextDNSAllowlist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlist.
+func (in *NextDNSAllowlist) DeepCopy() *NextDNSAllowlist {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAllowlist)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSAllowlist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAllowlistList) DeepCopyInto(out *NextDNSAllowlistList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSAllowlist, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistList.
+func (in *NextDNSAllowlistList) DeepCopy() *NextDNSAllowlistList {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAllowlistList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSAllowlistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAllowlistSpec) DeepCopyInto(out *NextDNSAllowlistSpec) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]DomainEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistSpec.
+func (in *NextDNSAllowlistSpec) DeepCopy() *NextDNSAllowlistSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAllowlistSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSAllowlistStatus) DeepCopyInto(out *NextDNSAllowlistStatus) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistStatus.
+func (in *NextDNSAllowlistStatus) DeepCopy() *NextDNSAllowlistStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSAllowlistStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSCoreDNS) DeepCopyInto(out *NextDNSCoreDNS) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNS.
+func (in *NextDNSCoreDNS) DeepCopy() *NextDNSCoreDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSCoreDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSCoreDNS) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSCoreDNSList) DeepCopyInto(out *NextDNSCoreDNSList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSCoreDNS, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSList.
+func (in *NextDNSCoreDNSList) DeepCopy() *NextDNSCoreDNSList {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSCoreDNSList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSCoreDNSList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSCoreDNSSpec) DeepCopyInto(out *NextDNSCoreDNSSpec) {
+	*out = *in
+	out.ProfileRef = in.ProfileRef
+	if in.FallbackProfileRef != nil {
+		in, out := &in.FallbackProfileRef, &out.FallbackProfileRef
+		*out = new(ResourceReference)
+		**out = **in
+	}
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(CoreDNSDeploymentConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(CoreDNSServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Multus != nil {
+		in, out := &in.Multus, &out.Multus
+		*out = new(MultusConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Corefile != nil {
+		in, out := &in.Corefile, &out.Corefile
+		*out = new(CorefileSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(CoreDNSNetworkPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessControl != nil {
+		in, out := &in.AccessControl, &out.AccessControl
+		*out = new(CoreDNSAccessControlConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = new(CoreDNSListenersConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterDNSIntegration != nil {
+		in, out := &in.ClusterDNSIntegration, &out.ClusterDNSIntegration
+		*out = new(ClusterDNSIntegrationConfig)
+		**out = **in
+	}
+	if in.SoakTest != nil {
+		in, out := &in.SoakTest, &out.SoakTest
+		*out = new(SoakTestConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(CoreDNSReadinessConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSSpec.
+func (in *NextDNSCoreDNSSpec) DeepCopy() *NextDNSCoreDNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSCoreDNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSCoreDNSStatus) DeepCopyInto(out *NextDNSCoreDNSStatus) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]DNSEndpoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.MultusIPs != nil {
+		in, out := &in.MultusIPs, &out.MultusIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeIPs != nil {
+		in, out := &in.NodeIPs, &out.NodeIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeLocal != nil {
+		in, out := &in.NodeLocal, &out.NodeLocal
+		*out = new(NodeLocalStatus)
+		**out = **in
+	}
+	if in.ClusterDNS != nil {
+		in, out := &in.ClusterDNS, &out.ClusterDNS
+		*out = new(ClusterDNSStatus)
+		**out = **in
+	}
+	if in.LocalZone != nil {
+		in, out := &in.LocalZone, &out.LocalZone
+		*out = new(LocalZoneStatus)
+		**out = **in
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackUpstream != nil {
+		in, out := &in.FallbackUpstream, &out.FallbackUpstream
+		*out = new(UpstreamStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(ReplicaStatus)
+		**out = **in
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = make([]PodPlacement, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlacementUpdated != nil {
+		in, out := &in.PlacementUpdated, &out.PlacementUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.CorefileAppliedAt != nil {
+		in, out := &in.CorefileAppliedAt, &out.CorefileAppliedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTestQuery != nil {
+		in, out := &in.LastTestQuery, &out.LastTestQuery
+		*out = new(TestQueryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoakTest != nil {
+		in, out := &in.SoakTest, &out.SoakTest
+		*out = new(SoakTestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSStatus.
+func (in *NextDNSCoreDNSStatus) DeepCopy() *NextDNSCoreDNSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSCoreDNSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylist) DeepCopyInto(out *NextDNSDenylist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylist.
+func (in *NextDNSDenylist) DeepCopy() *NextDNSDenylist {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylist)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDenylist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistList) DeepCopyInto(out *NextDNSDenylistList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSDenylist, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistList.
+func (in *NextDNSDenylistList) DeepCopy() *NextDNSDenylistList {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDenylistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistSource) DeepCopyInto(out *NextDNSDenylistSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSource.
+func (in *NextDNSDenylistSource) DeepCopy() *NextDNSDenylistSource {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDenylistSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistSourceList) DeepCopyInto(out *NextDNSDenylistSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSDenylistSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSourceList.
+func (in *NextDNSDenylistSourceList) DeepCopy() *NextDNSDenylistSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDenylistSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistSourceSpec) DeepCopyInto(out *NextDNSDenylistSourceSpec) {
+	*out = *in
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cosign != nil {
+		in, out := &in.Cosign, &out.Cosign
+		*out = new(CosignVerification)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSourceSpec.
+func (in *NextDNSDenylistSourceSpec) DeepCopy() *NextDNSDenylistSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistSourceStatus) DeepCopyInto(out *NextDNSDenylistSourceStatus) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(ListSourceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSourceStatus.
+func (in *NextDNSDenylistSourceStatus) DeepCopy() *NextDNSDenylistSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistSpec) DeepCopyInto(out *NextDNSDenylistSpec) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]DomainEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSpec.
+func (in *NextDNSDenylistSpec) DeepCopy() *NextDNSDenylistSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDenylistStatus) DeepCopyInto(out *NextDNSDenylistStatus) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistStatus.
+func (in *NextDNSDenylistStatus) DeepCopy() *NextDNSDenylistStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDenylistStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDevice) DeepCopyInto(out *NextDNSDevice) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDevice.
+func (in *NextDNSDevice) DeepCopy() *NextDNSDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(NextDNSDevice)
+	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDevice) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HostsConfig) DeepCopyInto(out *HostsConfig) {
+func (in *NextDNSDeviceList) DeepCopyInto(out *NextDNSDeviceList) {
 	*out = *in
-	if in.Entries != nil {
-		in, out := &in.Entries, &out.Entries
-		*out = make([]HostsEntry, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSDevice, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Fallthrough != nil {
-		in, out := &in.Fallthrough, &out.Fallthrough
-		*out = new(bool)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDeviceList.
+func (in *NextDNSDeviceList) DeepCopy() *NextDNSDeviceList {
+	if in == nil {
+		return nil
 	}
-	if in.TTL != nil {
-		in, out := &in.TTL, &out.TTL
-		*out = new(int32)
-		**out = **in
+	out := new(NextDNSDeviceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSDeviceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
+	return nil
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostsConfig.
-func (in *HostsConfig) DeepCopy() *HostsConfig {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSDeviceSpec) DeepCopyInto(out *NextDNSDeviceSpec) {
+	*out = *in
+	out.ProfileRef = in.ProfileRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDeviceSpec.
+func (in *NextDNSDeviceSpec) DeepCopy() *NextDNSDeviceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HostsConfig)
+	out := new(NextDNSDeviceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HostsEntry) DeepCopyInto(out *HostsEntry) {
+func (in *NextDNSDeviceStatus) DeepCopyInto(out *NextDNSDeviceStatus) {
 	*out = *in
-	if in.Hostnames != nil {
-		in, out := &in.Hostnames, &out.Hostnames
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostsEntry.
-func (in *HostsEntry) DeepCopy() *HostsEntry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDeviceStatus.
+func (in *NextDNSDeviceStatus) DeepCopy() *NextDNSDeviceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HostsEntry)
+	out := new(NextDNSDeviceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListReference) DeepCopyInto(out *ListReference) {
+func (in *NextDNSProfile) DeepCopyInto(out *NextDNSProfile) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListReference.
-func (in *ListReference) DeepCopy() *ListReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfile.
+func (in *NextDNSProfile) DeepCopy() *NextDNSProfile {
 	if in == nil {
 		return nil
 	}
-	out := new(ListReference)
+	out := new(NextDNSProfile)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogsSpec) DeepCopyInto(out *LogsSpec) {
+func (in *NextDNSProfileGenerator) DeepCopyInto(out *NextDNSProfileGenerator) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.LogClientsIPs != nil {
-		in, out := &in.LogClientsIPs, &out.LogClientsIPs
-		*out = new(bool)
-		**out = **in
-	}
-	if in.LogDomains != nil {
-		in, out := &in.LogDomains, &out.LogDomains
-		*out = new(bool)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogsSpec.
-func (in *LogsSpec) DeepCopy() *LogsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileGenerator.
+func (in *NextDNSProfileGenerator) DeepCopy() *NextDNSProfileGenerator {
 	if in == nil {
 		return nil
 	}
-	out := new(LogsSpec)
+	out := new(NextDNSProfileGenerator)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSProfileGenerator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultusConfig) DeepCopyInto(out *MultusConfig) {
+func (in *NextDNSProfileGeneratorList) DeepCopyInto(out *NextDNSProfileGeneratorList) {
 	*out = *in
-	if in.IPs != nil {
-		in, out := &in.IPs, &out.IPs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NextDNSProfileGenerator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultusConfig.
-func (in *MultusConfig) DeepCopy() *MultusConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileGeneratorList.
+func (in *NextDNSProfileGeneratorList) DeepCopy() *NextDNSProfileGeneratorList {
 	if in == nil {
 		return nil
 	}
-	out := new(MultusConfig)
+	out := new(NextDNSProfileGeneratorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSProfileGeneratorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NativeEntry) DeepCopyInto(out *NativeEntry) {
+func (in *NextDNSProfileGeneratorSpec) DeepCopyInto(out *NextDNSProfileGeneratorSpec) {
 	*out = *in
-	if in.Active != nil {
-		in, out := &in.Active, &out.Active
-		*out = new(bool)
-		**out = **in
+	out.TemplateRef = in.TemplateRef
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]ProfileInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NativeEntry.
-func (in *NativeEntry) DeepCopy() *NativeEntry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileGeneratorSpec.
+func (in *NextDNSProfileGeneratorSpec) DeepCopy() *NextDNSProfileGeneratorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NativeEntry)
+	out := new(NextDNSProfileGeneratorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSAllowlist) DeepCopyInto(out *NextDNSAllowlist) {
+func (in *NextDNSProfileGeneratorStatus) DeepCopyInto(out *NextDNSProfileGeneratorStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlist.
-func (in *NextDNSAllowlist) DeepCopy() *NextDNSAllowlist {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileGeneratorStatus.
+func (in *NextDNSProfileGeneratorStatus) DeepCopy() *NextDNSProfileGeneratorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSAllowlist)
+	out := new(NextDNSProfileGeneratorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSAllowlist) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSAllowlistList) DeepCopyInto(out *NextDNSAllowlistList) {
+func (in *NextDNSProfileList) DeepCopyInto(out *NextDNSProfileList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NextDNSAllowlist, len(*in))
+		*out = make([]NextDNSProfile, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistList.
-func (in *NextDNSAllowlistList) DeepCopy() *NextDNSAllowlistList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileList.
+func (in *NextDNSProfileList) DeepCopy() *NextDNSProfileList {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSAllowlistList)
+	out := new(NextDNSProfileList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSAllowlistList) DeepCopyObject() runtime.Object {
+func (in *NextDNSProfileList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSAllowlistSpec) DeepCopyInto(out *NextDNSAllowlistSpec) {
-	*out = *in
-	if in.Domains != nil {
-		in, out := &in.Domains, &out.Domains
-		*out = make([]DomainEntry, len(*in))
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSProfileSpec) DeepCopyInto(out *NextDNSProfileSpec) {
+	*out = *in
+	out.CredentialsRef = in.CredentialsRef
+	if in.AccountRef != nil {
+		in, out := &in.AccountRef, &out.AccountRef
+		*out = new(ResourceReference)
+		**out = **in
+	}
+	if in.AllowlistRefs != nil {
+		in, out := &in.AllowlistRefs, &out.AllowlistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DenylistRefs != nil {
+		in, out := &in.DenylistRefs, &out.DenylistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLDListRefs != nil {
+		in, out := &in.TLDListRefs, &out.TLDListRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.RewriteRefs != nil {
+		in, out := &in.RewriteRefs, &out.RewriteRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Denylist != nil {
+		in, out := &in.Denylist, &out.Denylist
+		*out = make([]DomainEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Allowlist != nil {
+		in, out := &in.Allowlist, &out.Allowlist
+		*out = make([]DomainEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(SecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Privacy != nil {
+		in, out := &in.Privacy, &out.Privacy
+		*out = new(PrivacySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ParentalControl != nil {
+		in, out := &in.ParentalControl, &out.ParentalControl
+		*out = new(ParentalControlSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rewrites != nil {
+		in, out := &in.Rewrites, &out.Rewrites
+		*out = make([]RewriteEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(SettingsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapRef)
+		**out = **in
+	}
+	if in.EffectiveConfigExport != nil {
+		in, out := &in.EffectiveConfigExport, &out.EffectiveConfigExport
+		*out = new(EffectiveConfigExport)
+		**out = **in
+	}
+	if in.DeployCoreDNS != nil {
+		in, out := &in.DeployCoreDNS, &out.DeployCoreDNS
+		*out = new(DeployCoreDNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]ProfileOverlay, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistSpec.
-func (in *NextDNSAllowlistSpec) DeepCopy() *NextDNSAllowlistSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileSpec.
+func (in *NextDNSProfileSpec) DeepCopy() *NextDNSProfileSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSAllowlistSpec)
+	out := new(NextDNSProfileSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSAllowlistStatus) DeepCopyInto(out *NextDNSAllowlistStatus) {
+func (in *NextDNSProfileStatus) DeepCopyInto(out *NextDNSProfileStatus) {
 	*out = *in
-	if in.ProfileRefs != nil {
-		in, out := &in.ProfileRefs, &out.ProfileRefs
-		*out = make([]ResourceReference, len(*in))
-		copy(*out, *in)
+	if in.AggregatedCounts != nil {
+		in, out := &in.AggregatedCounts, &out.AggregatedCounts
+		*out = new(AggregatedCounts)
+		**out = **in
+	}
+	if in.ReferencedResources != nil {
+		in, out := &in.ReferencedResources, &out.ReferencedResources
+		*out = new(ReferencedResources)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -883,39 +2874,86 @@ func (in *NextDNSAllowlistStatus) DeepCopyInto(out *NextDNSAllowlistStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ObservedConfig != nil {
+		in, out := &in.ObservedConfig, &out.ObservedConfig
+		*out = new(ObservedConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SuggestedSpec != nil {
+		in, out := &in.SuggestedSpec, &out.SuggestedSpec
+		*out = new(SuggestedSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImportedAt != nil {
+		in, out := &in.ImportedAt, &out.ImportedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Setup != nil {
+		in, out := &in.Setup, &out.Setup
+		*out = new(ProfileSetup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriftSummary != nil {
+		in, out := &in.DriftSummary, &out.DriftSummary
+		*out = new(DriftSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sections != nil {
+		in, out := &in.Sections, &out.Sections
+		*out = new(ProfileSectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BreakGlass != nil {
+		in, out := &in.BreakGlass, &out.BreakGlass
+		*out = new(BreakGlassStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManagedEntries != nil {
+		in, out := &in.ManagedEntries, &out.ManagedEntries
+		*out = new(ManagedListEntries)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rewrites != nil {
+		in, out := &in.Rewrites, &out.Rewrites
+		*out = make([]RewriteStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSAllowlistStatus.
-func (in *NextDNSAllowlistStatus) DeepCopy() *NextDNSAllowlistStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileStatus.
+func (in *NextDNSProfileStatus) DeepCopy() *NextDNSProfileStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSAllowlistStatus)
+	out := new(NextDNSProfileStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSCoreDNS) DeepCopyInto(out *NextDNSCoreDNS) {
+func (in *NextDNSProfileTemplate) DeepCopyInto(out *NextDNSProfileTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNS.
-func (in *NextDNSCoreDNS) DeepCopy() *NextDNSCoreDNS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileTemplate.
+func (in *NextDNSProfileTemplate) DeepCopy() *NextDNSProfileTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSCoreDNS)
+	out := new(NextDNSProfileTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSCoreDNS) DeepCopyObject() runtime.Object {
+func (in *NextDNSProfileTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -923,31 +2961,31 @@ func (in *NextDNSCoreDNS) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSCoreDNSList) DeepCopyInto(out *NextDNSCoreDNSList) {
+func (in *NextDNSProfileTemplateList) DeepCopyInto(out *NextDNSProfileTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NextDNSCoreDNS, len(*in))
+		*out = make([]NextDNSProfileTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSList.
-func (in *NextDNSCoreDNSList) DeepCopy() *NextDNSCoreDNSList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileTemplateList.
+func (in *NextDNSProfileTemplateList) DeepCopy() *NextDNSProfileTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSCoreDNSList)
+	out := new(NextDNSProfileTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSCoreDNSList) DeepCopyObject() runtime.Object {
+func (in *NextDNSProfileTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -955,94 +2993,23 @@ func (in *NextDNSCoreDNSList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSCoreDNSSpec) DeepCopyInto(out *NextDNSCoreDNSSpec) {
-	*out = *in
-	out.ProfileRef = in.ProfileRef
-	if in.Deployment != nil {
-		in, out := &in.Deployment, &out.Deployment
-		*out = new(CoreDNSDeploymentConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(CoreDNSServiceConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Multus != nil {
-		in, out := &in.Multus, &out.Multus
-		*out = new(MultusConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Gateway != nil {
-		in, out := &in.Gateway, &out.Gateway
-		*out = new(GatewayConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Corefile != nil {
-		in, out := &in.Corefile, &out.Corefile
-		*out = new(CorefileSpec)
-		(*in).DeepCopyInto(*out)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSSpec.
-func (in *NextDNSCoreDNSSpec) DeepCopy() *NextDNSCoreDNSSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(NextDNSCoreDNSSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSCoreDNSStatus) DeepCopyInto(out *NextDNSCoreDNSStatus) {
+func (in *NextDNSProfileTemplateSpec) DeepCopyInto(out *NextDNSProfileTemplateSpec) {
 	*out = *in
-	if in.Endpoints != nil {
-		in, out := &in.Endpoints, &out.Endpoints
-		*out = make([]DNSEndpoint, len(*in))
-		copy(*out, *in)
-	}
-	if in.MultusIPs != nil {
-		in, out := &in.MultusIPs, &out.MultusIPs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Upstream != nil {
-		in, out := &in.Upstream, &out.Upstream
-		*out = new(UpstreamStatus)
-		**out = **in
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(ReplicaStatus)
-		**out = **in
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.LastUpdated != nil {
-		in, out := &in.LastUpdated, &out.LastUpdated
-		*out = (*in).DeepCopy()
-	}
+	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSCoreDNSStatus.
-func (in *NextDNSCoreDNSStatus) DeepCopy() *NextDNSCoreDNSStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileTemplateSpec.
+func (in *NextDNSProfileTemplateSpec) DeepCopy() *NextDNSProfileTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSCoreDNSStatus)
+	out := new(NextDNSProfileTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSDenylist) DeepCopyInto(out *NextDNSDenylist) {
+func (in *NextDNSReport) DeepCopyInto(out *NextDNSReport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1050,18 +3017,18 @@ func (in *NextDNSDenylist) DeepCopyInto(out *NextDNSDenylist) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylist.
-func (in *NextDNSDenylist) DeepCopy() *NextDNSDenylist {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSReport.
+func (in *NextDNSReport) DeepCopy() *NextDNSReport {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSDenylist)
+	out := new(NextDNSReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSDenylist) DeepCopyObject() runtime.Object {
+func (in *NextDNSReport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1069,31 +3036,31 @@ func (in *NextDNSDenylist) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSDenylistList) DeepCopyInto(out *NextDNSDenylistList) {
+func (in *NextDNSReportList) DeepCopyInto(out *NextDNSReportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NextDNSDenylist, len(*in))
+		*out = make([]NextDNSReport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistList.
-func (in *NextDNSDenylistList) DeepCopy() *NextDNSDenylistList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSReportList.
+func (in *NextDNSReportList) DeepCopy() *NextDNSReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSDenylistList)
+	out := new(NextDNSReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSDenylistList) DeepCopyObject() runtime.Object {
+func (in *NextDNSReportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1101,34 +3068,36 @@ func (in *NextDNSDenylistList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSDenylistSpec) DeepCopyInto(out *NextDNSDenylistSpec) {
+func (in *NextDNSReportSpec) DeepCopyInto(out *NextDNSReportSpec) {
 	*out = *in
-	if in.Domains != nil {
-		in, out := &in.Domains, &out.Domains
-		*out = make([]DomainEntry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
 	}
+	in.Webhook.DeepCopyInto(&out.Webhook)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistSpec.
-func (in *NextDNSDenylistSpec) DeepCopy() *NextDNSDenylistSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSReportSpec.
+func (in *NextDNSReportSpec) DeepCopy() *NextDNSReportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSDenylistSpec)
+	out := new(NextDNSReportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSDenylistStatus) DeepCopyInto(out *NextDNSDenylistStatus) {
+func (in *NextDNSReportStatus) DeepCopyInto(out *NextDNSReportStatus) {
 	*out = *in
-	if in.ProfileRefs != nil {
-		in, out := &in.ProfileRefs, &out.ProfileRefs
-		*out = make([]ResourceReference, len(*in))
-		copy(*out, *in)
+	if in.LastReportTime != nil {
+		in, out := &in.LastReportTime, &out.LastReportTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextReportTime != nil {
+		in, out := &in.NextReportTime, &out.NextReportTime
+		*out = (*in).DeepCopy()
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1139,18 +3108,18 @@ func (in *NextDNSDenylistStatus) DeepCopyInto(out *NextDNSDenylistStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSDenylistStatus.
-func (in *NextDNSDenylistStatus) DeepCopy() *NextDNSDenylistStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSReportStatus.
+func (in *NextDNSReportStatus) DeepCopy() *NextDNSReportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSDenylistStatus)
+	out := new(NextDNSReportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSProfile) DeepCopyInto(out *NextDNSProfile) {
+func (in *NextDNSRewrite) DeepCopyInto(out *NextDNSRewrite) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1158,18 +3127,18 @@ func (in *NextDNSProfile) DeepCopyInto(out *NextDNSProfile) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfile.
-func (in *NextDNSProfile) DeepCopy() *NextDNSProfile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSRewrite.
+func (in *NextDNSRewrite) DeepCopy() *NextDNSRewrite {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSProfile)
+	out := new(NextDNSRewrite)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSProfile) DeepCopyObject() runtime.Object {
+func (in *NextDNSRewrite) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1177,85 +3146,40 @@ func (in *NextDNSProfile) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSProfileList) DeepCopyInto(out *NextDNSProfileList) {
+func (in *NextDNSRewriteList) DeepCopyInto(out *NextDNSRewriteList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NextDNSProfile, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileList.
-func (in *NextDNSProfileList) DeepCopy() *NextDNSProfileList {
-	if in == nil {
-		return nil
-	}
-	out := new(NextDNSProfileList)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NextDNSProfileList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSProfileSpec) DeepCopyInto(out *NextDNSProfileSpec) {
-	*out = *in
-	out.CredentialsRef = in.CredentialsRef
-	if in.AllowlistRefs != nil {
-		in, out := &in.AllowlistRefs, &out.AllowlistRefs
-		*out = make([]ListReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.DenylistRefs != nil {
-		in, out := &in.DenylistRefs, &out.DenylistRefs
-		*out = make([]ListReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.TLDListRefs != nil {
-		in, out := &in.TLDListRefs, &out.TLDListRefs
-		*out = make([]ListReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.Denylist != nil {
-		in, out := &in.Denylist, &out.Denylist
-		*out = make([]DomainEntry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Allowlist != nil {
-		in, out := &in.Allowlist, &out.Allowlist
-		*out = make([]DomainEntry, len(*in))
+		*out = make([]NextDNSRewrite, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Security != nil {
-		in, out := &in.Security, &out.Security
-		*out = new(SecuritySpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Privacy != nil {
-		in, out := &in.Privacy, &out.Privacy
-		*out = new(PrivacySpec)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSRewriteList.
+func (in *NextDNSRewriteList) DeepCopy() *NextDNSRewriteList {
+	if in == nil {
+		return nil
 	}
-	if in.ParentalControl != nil {
-		in, out := &in.ParentalControl, &out.ParentalControl
-		*out = new(ParentalControlSpec)
-		(*in).DeepCopyInto(*out)
+	out := new(NextDNSRewriteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NextDNSRewriteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextDNSRewriteSpec) DeepCopyInto(out *NextDNSRewriteSpec) {
+	*out = *in
 	if in.Rewrites != nil {
 		in, out := &in.Rewrites, &out.Rewrites
 		*out = make([]RewriteEntry, len(*in))
@@ -1263,40 +3187,25 @@ func (in *NextDNSProfileSpec) DeepCopyInto(out *NextDNSProfileSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Settings != nil {
-		in, out := &in.Settings, &out.Settings
-		*out = new(SettingsSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ConfigMapRef != nil {
-		in, out := &in.ConfigMapRef, &out.ConfigMapRef
-		*out = new(ConfigMapRef)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileSpec.
-func (in *NextDNSProfileSpec) DeepCopy() *NextDNSProfileSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSRewriteSpec.
+func (in *NextDNSRewriteSpec) DeepCopy() *NextDNSRewriteSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSProfileSpec)
+	out := new(NextDNSRewriteSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextDNSProfileStatus) DeepCopyInto(out *NextDNSProfileStatus) {
+func (in *NextDNSRewriteStatus) DeepCopyInto(out *NextDNSRewriteStatus) {
 	*out = *in
-	if in.AggregatedCounts != nil {
-		in, out := &in.AggregatedCounts, &out.AggregatedCounts
-		*out = new(AggregatedCounts)
-		**out = **in
-	}
-	if in.ReferencedResources != nil {
-		in, out := &in.ReferencedResources, &out.ReferencedResources
-		*out = new(ReferencedResources)
-		(*in).DeepCopyInto(*out)
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1305,33 +3214,14 @@ func (in *NextDNSProfileStatus) DeepCopyInto(out *NextDNSProfileStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LastSyncTime != nil {
-		in, out := &in.LastSyncTime, &out.LastSyncTime
-		*out = (*in).DeepCopy()
-	}
-	if in.ObservedConfig != nil {
-		in, out := &in.ObservedConfig, &out.ObservedConfig
-		*out = new(ObservedConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.SuggestedSpec != nil {
-		in, out := &in.SuggestedSpec, &out.SuggestedSpec
-		*out = new(SuggestedSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Setup != nil {
-		in, out := &in.Setup, &out.Setup
-		*out = new(ProfileSetup)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSProfileStatus.
-func (in *NextDNSProfileStatus) DeepCopy() *NextDNSProfileStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSRewriteStatus.
+func (in *NextDNSRewriteStatus) DeepCopy() *NextDNSRewriteStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NextDNSProfileStatus)
+	out := new(NextDNSRewriteStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1405,6 +3295,13 @@ func (in *NextDNSTLDListSpec) DeepCopyInto(out *NextDNSTLDListSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextDNSTLDListSpec.
@@ -1420,6 +3317,13 @@ func (in *NextDNSTLDListSpec) DeepCopy() *NextDNSTLDListSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NextDNSTLDListStatus) DeepCopyInto(out *NextDNSTLDListStatus) {
 	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ListSourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ProfileRefs != nil {
 		in, out := &in.ProfileRefs, &out.ProfileRefs
 		*out = make([]ResourceReference, len(*in))
@@ -1444,6 +3348,36 @@ func (in *NextDNSTLDListStatus) DeepCopy() *NextDNSTLDListStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocalStatus) DeepCopyInto(out *NodeLocalStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocalStatus.
+func (in *NodeLocalStatus) DeepCopy() *NodeLocalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIListSource) DeepCopyInto(out *OCIListSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIListSource.
+func (in *OCIListSource) DeepCopy() *OCIListSource {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIListSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservedBlockPage) DeepCopyInto(out *ObservedBlockPage) {
 	*out = *in
@@ -1841,93 +3775,238 @@ func (in *ParentalControlSpec) DeepCopyInto(out *ParentalControlSpec) {
 		*out = new(bool)
 		**out = **in
 	}
-	if in.YouTubeRestrictedMode != nil {
-		in, out := &in.YouTubeRestrictedMode, &out.YouTubeRestrictedMode
-		*out = new(bool)
-		**out = **in
+	if in.YouTubeRestrictedMode != nil {
+		in, out := &in.YouTubeRestrictedMode, &out.YouTubeRestrictedMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BlockBypass != nil {
+		in, out := &in.BlockBypass, &out.BlockBypass
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParentalControlSpec.
+func (in *ParentalControlSpec) DeepCopy() *ParentalControlSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ParentalControlSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceSpec) DeepCopyInto(out *PerformanceSpec) {
+	*out = *in
+	if in.ECS != nil {
+		in, out := &in.ECS, &out.ECS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CacheBoost != nil {
+		in, out := &in.CacheBoost, &out.CacheBoost
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CNAMEFlattening != nil {
+		in, out := &in.CNAMEFlattening, &out.CNAMEFlattening
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceSpec.
+func (in *PerformanceSpec) DeepCopy() *PerformanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodPlacement) DeepCopyInto(out *PodPlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodPlacement.
+func (in *PodPlacement) DeepCopy() *PodPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(PodPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivacySpec) DeepCopyInto(out *PrivacySpec) {
+	*out = *in
+	if in.Blocklists != nil {
+		in, out := &in.Blocklists, &out.Blocklists
+		*out = make([]BlocklistEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Natives != nil {
+		in, out := &in.Natives, &out.Natives
+		*out = make([]NativeEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisguisedTrackers != nil {
+		in, out := &in.DisguisedTrackers, &out.DisguisedTrackers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowAffiliate != nil {
+		in, out := &in.AllowAffiliate, &out.AllowAffiliate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivacySpec.
+func (in *PrivacySpec) DeepCopy() *PrivacySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivacySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileInstance) DeepCopyInto(out *ProfileInstance) {
+	*out = *in
+	if in.AllowlistRefs != nil {
+		in, out := &in.AllowlistRefs, &out.AllowlistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DenylistRefs != nil {
+		in, out := &in.DenylistRefs, &out.DenylistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileInstance.
+func (in *ProfileInstance) DeepCopy() *ProfileInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileOverlay) DeepCopyInto(out *ProfileOverlay) {
+	*out = *in
+	if in.AllowlistRefs != nil {
+		in, out := &in.AllowlistRefs, &out.AllowlistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DenylistRefs != nil {
+		in, out := &in.DenylistRefs, &out.DenylistRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLDListRefs != nil {
+		in, out := &in.TLDListRefs, &out.TLDListRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.RewriteRefs != nil {
+		in, out := &in.RewriteRefs, &out.RewriteRefs
+		*out = make([]ListReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(SecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Privacy != nil {
+		in, out := &in.Privacy, &out.Privacy
+		*out = new(PrivacySpec)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.BlockBypass != nil {
-		in, out := &in.BlockBypass, &out.BlockBypass
-		*out = new(bool)
-		**out = **in
+	if in.ParentalControl != nil {
+		in, out := &in.ParentalControl, &out.ParentalControl
+		*out = new(ParentalControlSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(SettingsSpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParentalControlSpec.
-func (in *ParentalControlSpec) DeepCopy() *ParentalControlSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileOverlay.
+func (in *ProfileOverlay) DeepCopy() *ProfileOverlay {
 	if in == nil {
 		return nil
 	}
-	out := new(ParentalControlSpec)
+	out := new(ProfileOverlay)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PerformanceSpec) DeepCopyInto(out *PerformanceSpec) {
+func (in *ProfileSectionStatus) DeepCopyInto(out *ProfileSectionStatus) {
 	*out = *in
-	if in.ECS != nil {
-		in, out := &in.ECS, &out.ECS
-		*out = new(bool)
-		**out = **in
-	}
-	if in.CacheBoost != nil {
-		in, out := &in.CacheBoost, &out.CacheBoost
-		*out = new(bool)
-		**out = **in
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.CNAMEFlattening != nil {
-		in, out := &in.CNAMEFlattening, &out.CNAMEFlattening
-		*out = new(bool)
-		**out = **in
+	if in.Privacy != nil {
+		in, out := &in.Privacy, &out.Privacy
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceSpec.
-func (in *PerformanceSpec) DeepCopy() *PerformanceSpec {
-	if in == nil {
-		return nil
+	if in.ParentalControl != nil {
+		in, out := &in.ParentalControl, &out.ParentalControl
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(PerformanceSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrivacySpec) DeepCopyInto(out *PrivacySpec) {
-	*out = *in
-	if in.Blocklists != nil {
-		in, out := &in.Blocklists, &out.Blocklists
-		*out = make([]BlocklistEntry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Denylist != nil {
+		in, out := &in.Denylist, &out.Denylist
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Natives != nil {
-		in, out := &in.Natives, &out.Natives
-		*out = make([]NativeEntry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Allowlist != nil {
+		in, out := &in.Allowlist, &out.Allowlist
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.DisguisedTrackers != nil {
-		in, out := &in.DisguisedTrackers, &out.DisguisedTrackers
-		*out = new(bool)
-		**out = **in
+	if in.TLDs != nil {
+		in, out := &in.TLDs, &out.TLDs
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.AllowAffiliate != nil {
-		in, out := &in.AllowAffiliate, &out.AllowAffiliate
-		*out = new(bool)
-		**out = **in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(SectionSyncStatus)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivacySpec.
-func (in *PrivacySpec) DeepCopy() *PrivacySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileSectionStatus.
+func (in *ProfileSectionStatus) DeepCopy() *ProfileSectionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PrivacySpec)
+	out := new(ProfileSectionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1962,6 +4041,31 @@ func (in *ProfileSetup) DeepCopy() *ProfileSetup {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitRule) DeepCopyInto(out *RateLimitRule) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QueriesPerSecond != nil {
+		in, out := &in.QueriesPerSecond, &out.QueriesPerSecond
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitRule.
+func (in *RateLimitRule) DeepCopy() *RateLimitRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReferencedResourceStatus) DeepCopyInto(out *ReferencedResourceStatus) {
 	*out = *in
@@ -1995,6 +4099,11 @@ func (in *ReferencedResources) DeepCopyInto(out *ReferencedResources) {
 		*out = make([]ReferencedResourceStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.Rewrites != nil {
+		in, out := &in.Rewrites, &out.Rewrites
+		*out = make([]ReferencedResourceStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferencedResources.
@@ -2022,6 +4131,26 @@ func (in *ReplicaStatus) DeepCopy() *ReplicaStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportWebhook) DeepCopyInto(out *ReportWebhook) {
+	*out = *in
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportWebhook.
+func (in *ReportWebhook) DeepCopy() *ReportWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
 	*out = *in
@@ -2072,6 +4201,46 @@ func (in *RewriteRule) DeepCopy() *RewriteRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RewriteStatus) DeepCopyInto(out *RewriteStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RewriteStatus.
+func (in *RewriteStatus) DeepCopy() *RewriteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RewriteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SearchPathConfig) DeepCopyInto(out *SearchPathConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchPathConfig.
+func (in *SearchPathConfig) DeepCopy() *SearchPathConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SearchPathConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -2087,6 +4256,25 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SectionSyncStatus) DeepCopyInto(out *SectionSyncStatus) {
+	*out = *in
+	if in.LastSynced != nil {
+		in, out := &in.LastSynced, &out.LastSynced
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SectionSyncStatus.
+func (in *SectionSyncStatus) DeepCopy() *SectionSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SectionSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecuritySpec) DeepCopyInto(out *SecuritySpec) {
 	*out = *in
@@ -2242,6 +4430,77 @@ func (in *SetupLinkedIP) DeepCopy() *SetupLinkedIP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakTestConfig) DeepCopyInto(out *SoakTestConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SamplePercent != nil {
+		in, out := &in.SamplePercent, &out.SamplePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakTestConfig.
+func (in *SoakTestConfig) DeepCopy() *SoakTestConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakTestConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakTestDivergence) DeepCopyInto(out *SoakTestDivergence) {
+	*out = *in
+	in.NextDNS.DeepCopyInto(&out.NextDNS)
+	in.Legacy.DeepCopyInto(&out.Legacy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakTestDivergence.
+func (in *SoakTestDivergence) DeepCopy() *SoakTestDivergence {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakTestDivergence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoakTestStatus) DeepCopyInto(out *SoakTestStatus) {
+	*out = *in
+	if in.LastRound != nil {
+		in, out := &in.LastRound, &out.LastRound
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDivergence != nil {
+		in, out := &in.LastDivergence, &out.LastDivergence
+		*out = new(SoakTestDivergence)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoakTestStatus.
+func (in *SoakTestStatus) DeepCopy() *SoakTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SoakTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SuggestedSpec) DeepCopyInto(out *SuggestedSpec) {
 	*out = *in
@@ -2323,6 +4582,27 @@ func (in *TLDEntry) DeepCopy() *TLDEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestQueryStatus) DeepCopyInto(out *TestQueryStatus) {
+	*out = *in
+	if in.Answers != nil {
+		in, out := &in.Answers, &out.Answers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestQueryStatus.
+func (in *TestQueryStatus) DeepCopy() *TestQueryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TestQueryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamConfig) DeepCopyInto(out *UpstreamConfig) {
 	*out = *in
@@ -2331,6 +4611,11 @@ func (in *UpstreamConfig) DeepCopyInto(out *UpstreamConfig) {
 		*out = new(ForwardTuningConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BootstrapServers != nil {
+		in, out := &in.BootstrapServers, &out.BootstrapServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamConfig.
@@ -2346,6 +4631,21 @@ func (in *UpstreamConfig) DeepCopy() *UpstreamConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamStatus) DeepCopyInto(out *UpstreamStatus) {
 	*out = *in
+	if in.IPv4 != nil {
+		in, out := &in.IPv4, &out.IPv4
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6 != nil {
+		in, out := &in.IPv6, &out.IPv6
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BootstrapServers != nil {
+		in, out := &in.BootstrapServers, &out.BootstrapServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamStatus.
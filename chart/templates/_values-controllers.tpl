@@ -21,7 +21,114 @@ controllers:
           {{- end }}
           - --health-probe-bind-address=:8081
           - --metrics-bind-address=:8080
+          {{- with .Values.pprofBindAddress }}
+          - --pprof-bind-address={{ . }}
+          {{- end }}
           - --gateway-class-name={{ .Values.gatewayAPI.gatewayClassName }}
+          {{- with .Values.sync.period }}
+          - --sync-period={{ . }}
+          {{- end }}
+          {{- with .Values.sync.jitter }}
+          - --sync-jitter={{ . }}
+          {{- end }}
+          {{- with .Values.sync.minInterval }}
+          - --sync-min-interval={{ . }}
+          {{- end }}
+          {{- with .Values.sync.maxInterval }}
+          - --sync-max-interval={{ . }}
+          {{- end }}
+          {{- with .Values.api.rateLimit }}
+          - --api-rate-limit={{ . }}
+          {{- end }}
+          {{- with .Values.api.burst }}
+          - --api-burst={{ . }}
+          {{- end }}
+          {{- with .Values.api.maxRetries }}
+          - --api-max-retries={{ . }}
+          {{- end }}
+          {{- with .Values.api.accountRateLimits }}
+          - --api-account-rate-limits={{ . }}
+          {{- end }}
+          {{- with .Values.defaultCredentialsSecret }}
+          - --default-credentials-secret={{ . }}
+          {{- end }}
+          {{- with .Values.crossNamespaceCredentials }}
+          - --cross-namespace-credentials={{ . }}
+          {{- end }}
+          {{- with .Values.watch.namespaces }}
+          - --watch-namespaces={{ . }}
+          {{- end }}
+          {{- with .Values.watch.labelSelector }}
+          - --watch-label-selector={{ . }}
+          {{- end }}
+          {{- with .Values.kubeAPI.qps }}
+          - --kube-api-qps={{ . }}
+          {{- end }}
+          {{- with .Values.kubeAPI.burst }}
+          - --kube-api-burst={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.baseDelay }}
+          - --workqueue-base-delay={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.maxDelay }}
+          - --workqueue-max-delay={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.qps }}
+          - --workqueue-qps={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.burst }}
+          - --workqueue-burst={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.maxConcurrentReconciles }}
+          - --max-concurrent-reconciles={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.controllerRateLimits }}
+          - --controller-rate-limits={{ . }}
+          {{- end }}
+          {{- with .Values.workqueue.controllerConcurrency }}
+          - --controller-concurrency={{ . }}
+          {{- end }}
+          {{- if .Values.catalog.enabled }}
+          - --catalog-namespace={{ .Release.Namespace }}
+          {{- with .Values.catalog.interval }}
+          - --catalog-interval={{ . }}
+          {{- end }}
+          {{- end }}
+          {{- if .Values.endpointDirectory.enabled }}
+          - --endpoint-directory-namespace={{ .Release.Namespace }}
+          {{- with .Values.endpointDirectory.interval }}
+          - --endpoint-directory-interval={{ . }}
+          {{- end }}
+          {{- end }}
+          {{- with .Values.telemetry.mode }}
+          - --telemetry={{ . }}
+          {{- end }}
+          {{- with .Values.telemetry.endpoint }}
+          - --telemetry-endpoint={{ . }}
+          {{- end }}
+          {{- with .Values.telemetry.interval }}
+          - --telemetry-interval={{ . }}
+          {{- end }}
+          {{- if .Values.clusterDNSIntegration.enabled }}
+          - --allow-cluster-dns-integration
+          {{- end }}
+          {{- with .Values.compatibilityLevel }}
+          - --compatibility-level={{ . }}
+          {{- end }}
+          {{- with .Values.statusBudget }}
+          - --status-budget={{ . }}
+          {{- end }}
+          {{- with .Values.resourceLabels }}
+          {{- $labels := list }}
+          {{- range $key, $value := . }}
+          {{- $labels = append $labels (printf "%s=%s" $key $value) }}
+          {{- end }}
+          - --resource-labels={{ join "," $labels }}
+          {{- end }}
+          {{- if .Values.webhook.enabled }}
+          - --enable-webhooks
+          - --webhook-port={{ .Values.webhook.port }}
+          {{- end }}
         env:
           TZ: {{ .Values.timezone }}
         resources:
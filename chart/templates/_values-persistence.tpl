@@ -0,0 +1,14 @@
+{{/*
+Build persistence structure from flat values
+*/}}
+{{- define "nextdns-operator.values.persistence" -}}
+{{- if .Values.webhook.enabled }}
+persistence:
+  webhook-certs:
+    type: secret
+    name: {{ include "nextdns-operator.fullname" . }}-webhook-cert
+    globalMounts:
+      - path: /tmp/k8s-webhook-server/serving-certs
+        readOnly: true
+{{- end }}
+{{- end -}}
@@ -15,6 +15,8 @@ rbac:
             - ""
           resources:
             - configmaps
+            - secrets
+            - serviceaccounts
             - services
           verbs:
             - create
@@ -34,8 +36,9 @@ rbac:
         - apiGroups:
             - ""
           resources:
+            - namespaces
+            - nodes
             - pods
-            - secrets
           verbs:
             - get
             - list
@@ -53,6 +56,24 @@ rbac:
             - patch
             - update
             - watch
+        - apiGroups:
+            - autoscaling
+          resources:
+            - horizontalpodautoscalers
+          verbs:
+            - create
+            - delete
+            - get
+            - list
+            - patch
+            - update
+            - watch
+        - apiGroups:
+            - cert-manager.io
+          resources:
+            - certificates
+          verbs:
+            - get
         - apiGroups:
             - coordination.k8s.io
           resources:
@@ -105,13 +126,45 @@ rbac:
             - gateways/status
           verbs:
             - get
+        - apiGroups:
+            - monitoring.coreos.com
+          resources:
+            - servicemonitors
+          verbs:
+            - create
+            - delete
+            - get
+            - list
+            - patch
+            - update
+            - watch
+        - apiGroups:
+            - networking.k8s.io
+          resources:
+            - networkpolicies
+          verbs:
+            - create
+            - delete
+            - get
+            - list
+            - patch
+            - update
+            - watch
         - apiGroups:
             - nextdns.io
           resources:
+            - clusternextdnsallowlists
+            - clusternextdnsdenylists
+            - nextdnsaccounts
             - nextdnsallowlists
             - nextdnscorednses
             - nextdnsdenylists
+            - nextdnsdenylistsources
+            - nextdnsdevices
+            - nextdnsprofilegenerators
             - nextdnsprofiles
+            - nextdnsreports
+            - nextdnsrewrites
             - nextdnstldlists
           verbs:
             - create
@@ -124,25 +177,46 @@ rbac:
         - apiGroups:
             - nextdns.io
           resources:
+            - clusternextdnsallowlists/finalizers
+            - clusternextdnsdenylists/finalizers
             - nextdnsallowlists/finalizers
             - nextdnscorednses/finalizers
             - nextdnsdenylists/finalizers
+            - nextdnsdenylistsources/finalizers
+            - nextdnsprofilegenerators/finalizers
             - nextdnsprofiles/finalizers
+            - nextdnsrewrites/finalizers
             - nextdnstldlists/finalizers
           verbs:
             - update
         - apiGroups:
             - nextdns.io
           resources:
+            - clusternextdnsallowlists/status
+            - clusternextdnsdenylists/status
+            - nextdnsaccounts/status
             - nextdnsallowlists/status
             - nextdnscorednses/status
             - nextdnsdenylists/status
+            - nextdnsdenylistsources/status
+            - nextdnsdevices/status
+            - nextdnsprofilegenerators/status
             - nextdnsprofiles/status
+            - nextdnsreports/status
+            - nextdnsrewrites/status
             - nextdnstldlists/status
           verbs:
             - get
             - patch
             - update
+        - apiGroups:
+            - nextdns.io
+          resources:
+            - nextdnsprofiletemplates
+          verbs:
+            - get
+            - list
+            - watch
         - apiGroups:
             - policy
           resources:
@@ -9,4 +9,10 @@ service:
       metrics:
         port: 8080
         protocol: TCP
+      {{- if .Values.webhook.enabled }}
+      webhook:
+        port: 443
+        targetPort: {{ .Values.webhook.port }}
+        protocol: TCP
+      {{- end }}
 {{- end -}}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,19 +16,31 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
 	"github.com/jacaudi/nextdns-operator/internal/controller"
+	"github.com/jacaudi/nextdns-operator/internal/describe"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"github.com/jacaudi/nextdns-operator/internal/migrate"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+	"github.com/jacaudi/nextdns-operator/internal/scan"
+	webhookv1alpha1 "github.com/jacaudi/nextdns-operator/internal/webhook/v1alpha1"
 )
 
 var (
@@ -63,13 +78,33 @@ func lookupEnvOrString(key, defaultVal string) string {
 }
 
 func main() {
+	// The migrate subcommand translates Pi-hole/AdGuard Home lists into list
+	// resources and exits without starting the manager.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	// The scan subcommand reports remote profiles without a NextDNSProfile
+	// and exits without starting the manager.
+	if len(os.Args) > 1 && os.Args[1] == "scan" {
+		os.Exit(runScan(os.Args[2:]))
+	}
+	// The describe subcommand prints the effective policy of a NextDNSProfile
+	// and exits without starting the manager.
+	if len(os.Args) > 1 && os.Args[1] == "describe" {
+		os.Exit(runDescribe(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var pprofAddr string
 	var syncPeriod string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", lookupEnvOrString("PPROF_BIND_ADDRESS", ""),
+		"The address the pprof endpoint binds to, for CPU and memory profiling. Empty disables it. "+
+			"Can also be set via PPROF_BIND_ADDRESS environment variable.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -77,12 +112,177 @@ func main() {
 		"The period at which resources are resynced for drift detection. "+
 			"Set to 0 to disable periodic syncing. Can also be set via SYNC_PERIOD environment variable.")
 
+	var syncJitter string
+	var syncMinInterval string
+	var syncMaxInterval string
+	flag.StringVar(&syncJitter, "sync-jitter", lookupEnvOrString("SYNC_JITTER",
+		strconv.FormatFloat(controller.DefaultSyncIntervalConfig.Jitter, 'f', -1, 64)),
+		"Maximum random deviation from the sync period, as a fraction of it (0 to below 1). "+
+			"Can also be set via SYNC_JITTER environment variable.")
+	flag.StringVar(&syncMinInterval, "sync-min-interval", lookupEnvOrString("SYNC_MIN_INTERVAL", "0"),
+		"Lower bound for the jittered sync interval. Set to 0 for no bound. "+
+			"Can also be set via SYNC_MIN_INTERVAL environment variable.")
+	flag.StringVar(&syncMaxInterval, "sync-max-interval", lookupEnvOrString("SYNC_MAX_INTERVAL", "0"),
+		"Upper bound for the jittered sync interval. Set to 0 for no bound. "+
+			"Can also be set via SYNC_MAX_INTERVAL environment variable.")
+
+	var apiRateLimit string
+	var apiBurst string
+	var apiMaxRetries string
+	var apiAccountRateLimits string
+	apiRateLimitDefault := lookupEnvOrString("API_RATE_LIMIT", lookupEnvOrString("NEXTDNS_API_RPS",
+		strconv.FormatFloat(nextdns.DefaultClientConfig.RequestsPerSecond, 'f', -1, 64)))
+	flag.StringVar(&apiRateLimit, "api-rate-limit", apiRateLimitDefault,
+		"Sustained NextDNS API requests per second allowed per API key. "+
+			"Can also be set via API_RATE_LIMIT environment variable.")
+	flag.StringVar(&apiRateLimit, "nextdns-api-rps", apiRateLimitDefault,
+		"Alias of --api-rate-limit. Can also be set via NEXTDNS_API_RPS environment variable.")
+	flag.StringVar(&apiBurst, "api-burst", lookupEnvOrString("API_BURST", strconv.Itoa(nextdns.DefaultClientConfig.Burst)),
+		"NextDNS API requests per API key allowed in a burst above the rate limit. "+
+			"Can also be set via API_BURST environment variable.")
+	flag.StringVar(&apiMaxRetries, "api-max-retries", lookupEnvOrString("API_MAX_RETRIES",
+		strconv.Itoa(nextdns.DefaultClientConfig.MaxRetries)),
+		"Retries of NextDNS API requests that were rate limited or failed with a server error. "+
+			"Can also be set via API_MAX_RETRIES environment variable.")
+	flag.StringVar(&apiAccountRateLimits, "api-account-rate-limits", lookupEnvOrString("API_ACCOUNT_RATE_LIMITS", ""),
+		"Comma-separated per-account overrides of the API rate limit as FINGERPRINT=RATE[/BURST], "+
+			"keyed by the account fingerprint in NextDNSProfile status. "+
+			"Can also be set via API_ACCOUNT_RATE_LIMITS environment variable.")
+
+	var fanOutWindow string
+	flag.StringVar(&fanOutWindow, "fanout-window", lookupEnvOrString("FANOUT_WINDOW", controller.DefaultFanOutWindow.String()),
+		"Period over which the profile reconciles triggered by one shared list change are spread. "+
+			"Set to 0 to reconcile all referencing profiles immediately. Can also be set via FANOUT_WINDOW environment variable.")
+
+	var sectionConcurrency string
+	flag.StringVar(&sectionConcurrency, "section-concurrency", lookupEnvOrString("SECTION_CONCURRENCY",
+		strconv.Itoa(controller.DefaultSectionConcurrency)),
+		"Number of profile sections (security, privacy, parental control, settings, lists) written to the NextDNS API "+
+			"at once during a sync. Set to 1 to write them one after another. "+
+			"Can also be set via SECTION_CONCURRENCY environment variable.")
+
 	var gatewayClassName string
 	flag.StringVar(&gatewayClassName, "gateway-class-name", lookupEnvOrString("GATEWAY_CLASS_NAME", ""),
 		"Default GatewayClass name to reference for Gateway API resources. "+
 			"Can be overridden per-CR via spec.gateway.gatewayClassName. "+
 			"Can also be set via GATEWAY_CLASS_NAME environment variable.")
 
+	var resourceLabels string
+	flag.StringVar(&resourceLabels, "resource-labels", lookupEnvOrString("RESOURCE_LABELS", ""),
+		"Comma-separated key=value labels added to every object the operator creates, "+
+			"e.g. team=platform,cost-center=dns. Can also be set via RESOURCE_LABELS environment variable.")
+
+	var watchNamespaces string
+	var watchLabelSelector string
+	flag.StringVar(&watchNamespaces, "watch-namespaces", lookupEnvOrString("WATCH_NAMESPACES", ""),
+		"Comma-separated namespaces whose resources are reconciled. Empty watches all namespaces. "+
+			"Can also be set via WATCH_NAMESPACES environment variable.")
+	flag.StringVar(&watchLabelSelector, "watch-label-selector", lookupEnvOrString("WATCH_LABEL_SELECTOR", ""),
+		"Label selector restricting the nextdns.io resources that are reconciled, e.g. nextdns.io/instance=blue. "+
+			"Can also be set via WATCH_LABEL_SELECTOR environment variable.")
+
+	var defaultCredentialsSecret string
+	flag.StringVar(&defaultCredentialsSecret, "default-credentials-secret", lookupEnvOrString("DEFAULT_CREDENTIALS_SECRET", ""),
+		"Secret (namespace/name) holding the NextDNS API key under api-key, used by NextDNSProfiles without "+
+			"spec.credentialsRef. Can also be set via DEFAULT_CREDENTIALS_SECRET environment variable.")
+
+	var crossNamespaceCredentials string
+	flag.StringVar(&crossNamespaceCredentials, "cross-namespace-credentials",
+		lookupEnvOrString("CROSS_NAMESPACE_CREDENTIALS", controller.CrossNamespaceCredentialsAllow),
+		"Policy for NextDNSProfile credentialsRef Secrets in another namespace: allow, grant (the Secret must list the "+
+			"profile's namespace in its nextdns.io/allowed-namespaces annotation) or deny. "+
+			"Can also be set via CROSS_NAMESPACE_CREDENTIALS environment variable.")
+
+	var catalogNamespace string
+	var catalogInterval string
+	flag.StringVar(&catalogNamespace, "catalog-namespace", lookupEnvOrString("CATALOG_NAMESPACE", ""),
+		"Namespace to publish the nextdns-catalog ConfigMap to, listing the blocklists, natives and categories "+
+			"enabled on managed profiles. Empty disables the catalog. Can also be set via CATALOG_NAMESPACE environment variable.")
+	flag.StringVar(&catalogInterval, "catalog-interval", lookupEnvOrString("CATALOG_INTERVAL", controller.DefaultCatalogInterval.String()),
+		"Period between catalog updates. Can also be set via CATALOG_INTERVAL environment variable.")
+
+	var endpointDirectoryNamespace string
+	var endpointDirectoryInterval string
+	flag.StringVar(&endpointDirectoryNamespace, "endpoint-directory-namespace", lookupEnvOrString("ENDPOINT_DIRECTORY_NAMESPACE", ""),
+		"Namespace to publish the nextdns-endpoints ConfigMap to, listing the DNS endpoints of every ready NextDNSCoreDNS "+
+			"ordered across failure domains. Empty disables the directory. Can also be set via ENDPOINT_DIRECTORY_NAMESPACE environment variable.")
+	flag.StringVar(&endpointDirectoryInterval, "endpoint-directory-interval",
+		lookupEnvOrString("ENDPOINT_DIRECTORY_INTERVAL", controller.DefaultEndpointDirectoryInterval.String()),
+		"Period between endpoint directory updates. Can also be set via ENDPOINT_DIRECTORY_INTERVAL environment variable.")
+
+	var telemetryMode string
+	var telemetryEndpoint string
+	var telemetryInterval string
+	flag.StringVar(&telemetryMode, "telemetry", lookupEnvOrString("TELEMETRY", controller.TelemetryOff),
+		"Anonymous usage telemetry: off, print (log the report that would be sent) or send (post it to --telemetry-endpoint). "+
+			"Reports hold the operator version, enabled features and custom resource counts. "+
+			"Can also be set via TELEMETRY environment variable.")
+	flag.StringVar(&telemetryEndpoint, "telemetry-endpoint", lookupEnvOrString("TELEMETRY_ENDPOINT", ""),
+		"URL telemetry reports are posted to in send mode. Can also be set via TELEMETRY_ENDPOINT environment variable.")
+	flag.StringVar(&telemetryInterval, "telemetry-interval", lookupEnvOrString("TELEMETRY_INTERVAL", controller.DefaultTelemetryInterval.String()),
+		"Period between telemetry reports. Can also be set via TELEMETRY_INTERVAL environment variable.")
+
+	var allowClusterDNSIntegration bool
+	flag.BoolVar(&allowClusterDNSIntegration, "allow-cluster-dns-integration",
+		lookupEnvOrString("ALLOW_CLUSTER_DNS_INTEGRATION", "false") == "true",
+		"Allow NextDNSCoreDNS resources with spec.clusterDNSIntegration.mode=KubeDNSService to point "+
+			"the cluster DNS Service at their pods. Can also be set via ALLOW_CLUSTER_DNS_INTEGRATION environment variable.")
+
+	var compatibilityLevel string
+	flag.StringVar(&compatibilityLevel, "compatibility-level", lookupEnvOrString("COMPATIBILITY_LEVEL",
+		strconv.Itoa(controller.DefaultCompatibilityLevel)),
+		"Behavior version applied to resources without the nextdns.io/compatibility-level annotation. "+
+			"Raise it once all resources were tested with the new behavior. "+
+			"Can also be set via COMPATIBILITY_LEVEL environment variable.")
+
+	var statusBudget string
+	flag.StringVar(&statusBudget, "status-budget", lookupEnvOrString("STATUS_BUDGET", strconv.Itoa(controller.DefaultStatusBudget)),
+		"Maximum size in bytes of the status the controllers write to a resource. Larger statuses have their longest "+
+			"lists and messages truncated. Can also be set via STATUS_BUDGET environment variable.")
+
+	var kubeAPIQPS string
+	var kubeAPIBurst string
+	flag.StringVar(&kubeAPIQPS, "kube-api-qps", lookupEnvOrString("KUBE_API_QPS", "20"),
+		"Sustained Kubernetes API requests per second allowed to the operator. "+
+			"Can also be set via KUBE_API_QPS environment variable.")
+	flag.StringVar(&kubeAPIBurst, "kube-api-burst", lookupEnvOrString("KUBE_API_BURST", "30"),
+		"Kubernetes API requests allowed in a burst above the QPS. "+
+			"Can also be set via KUBE_API_BURST environment variable.")
+
+	var workqueueBaseDelay string
+	var workqueueMaxDelay string
+	var workqueueQPS string
+	var workqueueBurst string
+	var maxConcurrentReconciles string
+	var controllerRateLimits string
+	var controllerConcurrency string
+	flag.StringVar(&workqueueBaseDelay, "workqueue-base-delay", lookupEnvOrString("WORKQUEUE_BASE_DELAY",
+		controller.DefaultWorkqueueConfig.BaseDelay.String()),
+		"Requeue delay after the first failed reconcile of a resource, doubled on every further failure. "+
+			"Can also be set via WORKQUEUE_BASE_DELAY environment variable.")
+	flag.StringVar(&workqueueMaxDelay, "workqueue-max-delay", lookupEnvOrString("WORKQUEUE_MAX_DELAY",
+		controller.DefaultWorkqueueConfig.MaxDelay.String()),
+		"Longest requeue delay of a resource that keeps failing. "+
+			"Can also be set via WORKQUEUE_MAX_DELAY environment variable.")
+	flag.StringVar(&workqueueQPS, "workqueue-qps", lookupEnvOrString("WORKQUEUE_QPS",
+		strconv.FormatFloat(controller.DefaultWorkqueueConfig.QPS, 'f', -1, 64)),
+		"Sustained reconciles per second each controller starts. "+
+			"Can also be set via WORKQUEUE_QPS environment variable.")
+	flag.StringVar(&workqueueBurst, "workqueue-burst", lookupEnvOrString("WORKQUEUE_BURST",
+		strconv.Itoa(controller.DefaultWorkqueueConfig.Burst)),
+		"Reconciles each controller starts in a burst above the workqueue QPS. "+
+			"Can also be set via WORKQUEUE_BURST environment variable.")
+	flag.StringVar(&maxConcurrentReconciles, "max-concurrent-reconciles", lookupEnvOrString("MAX_CONCURRENT_RECONCILES",
+		strconv.Itoa(controller.DefaultWorkqueueConfig.MaxConcurrentReconciles)),
+		"Resources each controller reconciles at once. "+
+			"Can also be set via MAX_CONCURRENT_RECONCILES environment variable.")
+	flag.StringVar(&controllerRateLimits, "controller-rate-limits", lookupEnvOrString("CONTROLLER_RATE_LIMITS", ""),
+		"Comma-separated per-controller overrides of the workqueue rate limit as NAME=QPS[/BURST], "+
+			"e.g. nextdnsprofile=2/20. Can also be set via CONTROLLER_RATE_LIMITS environment variable.")
+	flag.StringVar(&controllerConcurrency, "controller-concurrency", lookupEnvOrString("CONTROLLER_CONCURRENCY", ""),
+		"Comma-separated per-controller overrides of --max-concurrent-reconciles as NAME=N, "+
+			"e.g. nextdnsprofile=8. Can also be set via CONTROLLER_CONCURRENCY environment variable.")
+
 	var logLevel string
 	var logFormat string
 	flag.StringVar(&logLevel, "log-level", lookupEnvOrString("LOG_LEVEL", "info"),
@@ -90,6 +290,14 @@ func main() {
 	flag.StringVar(&logFormat, "log-format", lookupEnvOrString("LOG_FORMAT", "json"),
 		"Log format (json, text). Can also be set via LOG_FORMAT environment variable.")
 
+	var enableWebhooks bool
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", lookupEnvOrString("ENABLE_WEBHOOKS", "false") == "true",
+		"Enable the defaulting webhook that writes effective defaults into NextDNSCoreDNS resources. "+
+			"Requires a serving certificate in /tmp/k8s-webhook-server/serving-certs. "+
+			"Can also be set via ENABLE_WEBHOOKS environment variable.")
+	var webhookPort int
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on.")
+
 	var showVersion bool
 	flag.BoolVar(&showVersion, "version", false, "Print build version and exit.")
 
@@ -114,24 +322,217 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("drift detection configuration", "syncPeriod", syncDuration)
+	var syncInterval controller.SyncIntervalConfig
+	if syncInterval.Jitter, err = strconv.ParseFloat(syncJitter, 64); err != nil {
+		setupLog.Error(err, "invalid sync jitter", "syncJitter", syncJitter)
+		os.Exit(1)
+	}
+	if syncInterval.MinInterval, err = time.ParseDuration(syncMinInterval); err != nil {
+		setupLog.Error(err, "invalid minimum sync interval", "syncMinInterval", syncMinInterval)
+		os.Exit(1)
+	}
+	if syncInterval.MaxInterval, err = time.ParseDuration(syncMaxInterval); err != nil {
+		setupLog.Error(err, "invalid maximum sync interval", "syncMaxInterval", syncMaxInterval)
+		os.Exit(1)
+	}
+	if err := controller.SetSyncIntervalConfig(syncInterval); err != nil {
+		setupLog.Error(err, "invalid sync interval configuration")
+		os.Exit(1)
+	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	apiClient := nextdns.DefaultClientConfig
+	if apiClient.RequestsPerSecond, err = strconv.ParseFloat(apiRateLimit, 64); err != nil {
+		setupLog.Error(err, "invalid API rate limit", "apiRateLimit", apiRateLimit)
+		os.Exit(1)
+	}
+	if apiClient.Burst, err = strconv.Atoi(apiBurst); err != nil {
+		setupLog.Error(err, "invalid API burst", "apiBurst", apiBurst)
+		os.Exit(1)
+	}
+	if apiClient.MaxRetries, err = strconv.Atoi(apiMaxRetries); err != nil {
+		setupLog.Error(err, "invalid API max retries", "apiMaxRetries", apiMaxRetries)
+		os.Exit(1)
+	}
+	if apiClient.AccountLimits, err = nextdns.ParseAccountLimits(apiAccountRateLimits); err != nil {
+		setupLog.Error(err, "invalid API account rate limits", "apiAccountRateLimits", apiAccountRateLimits)
+		os.Exit(1)
+	}
+	if err := nextdns.SetClientConfig(apiClient); err != nil {
+		setupLog.Error(err, "invalid API client configuration")
+		os.Exit(1)
+	}
+	setupLog.Info("API client configuration", "apiRateLimit", apiClient.RequestsPerSecond,
+		"apiBurst", apiClient.Burst, "apiMaxRetries", apiClient.MaxRetries,
+		"apiAccountRateLimits", len(apiClient.AccountLimits))
+
+	// Operator metrics are served by the manager's metrics endpoint
+	operatorMetrics, err := metrics.New(ctrlmetrics.Registry)
+	if err != nil {
+		setupLog.Error(err, "unable to register metrics")
+		os.Exit(1)
+	}
+	nextdns.SetMetrics(operatorMetrics)
+
+	defaultCompatibilityLevel, err := strconv.Atoi(compatibilityLevel)
+	if err == nil {
+		err = controller.ValidateCompatibilityLevel(defaultCompatibilityLevel)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid compatibility level", "compatibilityLevel", compatibilityLevel)
+		os.Exit(1)
+	}
+
+	sectionConcurrencyValue, err := strconv.Atoi(sectionConcurrency)
+	if err == nil && sectionConcurrencyValue < 1 {
+		err = errors.New("section concurrency must be at least 1")
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid section concurrency", "sectionConcurrency", sectionConcurrency)
+		os.Exit(1)
+	}
+
+	statusBudgetBytes, err := strconv.Atoi(statusBudget)
+	if err == nil {
+		err = controller.SetStatusBudget(statusBudgetBytes)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid status budget", "statusBudget", statusBudget)
+		os.Exit(1)
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	kubeAPIQPSValue, err := strconv.ParseFloat(kubeAPIQPS, 32)
+	if err == nil && kubeAPIQPSValue <= 0 {
+		err = errors.New("Kubernetes API QPS must be positive")
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid Kubernetes API QPS", "kubeAPIQPS", kubeAPIQPS)
+		os.Exit(1)
+	}
+	restConfig.QPS = float32(kubeAPIQPSValue)
+	if restConfig.Burst, err = strconv.Atoi(kubeAPIBurst); err == nil && restConfig.Burst <= 0 {
+		err = errors.New("Kubernetes API burst must be positive")
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid Kubernetes API burst", "kubeAPIBurst", kubeAPIBurst)
+		os.Exit(1)
+	}
+
+	queue := controller.DefaultWorkqueueConfig
+	if queue.BaseDelay, err = time.ParseDuration(workqueueBaseDelay); err != nil {
+		setupLog.Error(err, "invalid workqueue base delay", "workqueueBaseDelay", workqueueBaseDelay)
+		os.Exit(1)
+	}
+	if queue.MaxDelay, err = time.ParseDuration(workqueueMaxDelay); err != nil {
+		setupLog.Error(err, "invalid workqueue max delay", "workqueueMaxDelay", workqueueMaxDelay)
+		os.Exit(1)
+	}
+	if queue.QPS, err = strconv.ParseFloat(workqueueQPS, 64); err != nil {
+		setupLog.Error(err, "invalid workqueue QPS", "workqueueQPS", workqueueQPS)
+		os.Exit(1)
+	}
+	if queue.Burst, err = strconv.Atoi(workqueueBurst); err != nil {
+		setupLog.Error(err, "invalid workqueue burst", "workqueueBurst", workqueueBurst)
+		os.Exit(1)
+	}
+	if queue.MaxConcurrentReconciles, err = strconv.Atoi(maxConcurrentReconciles); err != nil {
+		setupLog.Error(err, "invalid max concurrent reconciles", "maxConcurrentReconciles", maxConcurrentReconciles)
+		os.Exit(1)
+	}
+	if queue.ControllerRateLimits, err = controller.ParseControllerRateLimits(controllerRateLimits); err != nil {
+		setupLog.Error(err, "invalid controller rate limits", "controllerRateLimits", controllerRateLimits)
+		os.Exit(1)
+	}
+	if queue.ControllerConcurrency, err = controller.ParseControllerConcurrency(controllerConcurrency); err != nil {
+		setupLog.Error(err, "invalid controller concurrency", "controllerConcurrency", controllerConcurrency)
+		os.Exit(1)
+	}
+	if err := queue.Validate(); err != nil {
+		setupLog.Error(err, "invalid workqueue configuration")
+		os.Exit(1)
+	}
+	setupLog.Info("Kubernetes API and workqueue configuration", "kubeAPIQPS", restConfig.QPS,
+		"kubeAPIBurst", restConfig.Burst, "workqueueBaseDelay", queue.BaseDelay,
+		"workqueueMaxDelay", queue.MaxDelay, "workqueueQPS", queue.QPS, "workqueueBurst", queue.Burst,
+		"maxConcurrentReconciles", queue.MaxConcurrentReconciles, "controllerRateLimits", controllerRateLimits,
+		"controllerConcurrency", controllerConcurrency)
+
+	fanOutDuration, err := time.ParseDuration(fanOutWindow)
+	if err != nil {
+		setupLog.Error(err, "invalid fan-out window", "fanOutWindow", fanOutWindow)
+		os.Exit(1)
+	}
+
+	setupLog.Info("drift detection configuration", "syncPeriod", syncDuration,
+		"syncJitter", syncInterval.Jitter, "syncMinInterval", syncInterval.MinInterval,
+		"syncMaxInterval", syncInterval.MaxInterval, "fanOutWindow", fanOutDuration)
+
+	labels, err := controller.ParseResourceLabels(resourceLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid resource labels", "resourceLabels", resourceLabels)
+		os.Exit(1)
+	}
+	if len(labels) > 0 {
+		setupLog.Info("adding labels to managed objects", "resourceLabels", labels.String())
+	}
+
+	var scope controller.WatchScope
+	if scope.Namespaces, err = controller.ParseWatchNamespaces(watchNamespaces); err != nil {
+		setupLog.Error(err, "invalid watch namespaces", "watchNamespaces", watchNamespaces)
+		os.Exit(1)
+	}
+	if scope.LabelSelector, err = controller.ParseWatchLabelSelector(watchLabelSelector); err != nil {
+		setupLog.Error(err, "invalid watch label selector", "watchLabelSelector", watchLabelSelector)
+		os.Exit(1)
+	}
+	defaultCredentials, err := controller.ParseDefaultCredentialsSecret(defaultCredentialsSecret)
+	if err != nil {
+		setupLog.Error(err, "invalid default credentials Secret", "defaultCredentialsSecret", defaultCredentialsSecret)
+		os.Exit(1)
+	}
+	controller.SetDefaultCredentialsSecret(defaultCredentials)
+	if err := controller.SetCrossNamespaceCredentials(crossNamespaceCredentials); err != nil {
+		setupLog.Error(err, "invalid cross-namespace credentials mode", "crossNamespaceCredentials", crossNamespaceCredentials)
+		os.Exit(1)
+	}
+	// The catalog and endpoint directory ConfigMaps and the default
+	// credentials Secret are read through the cache, so their namespaces are
+	// watched too
+	if len(scope.Namespaces) > 0 {
+		for _, namespace := range []string{catalogNamespace, endpointDirectoryNamespace, defaultCredentials.Namespace} {
+			if namespace != "" && !slices.Contains(scope.Namespaces, namespace) {
+				scope.Namespaces = append(scope.Namespaces, namespace)
+			}
+		}
+	}
+	setupLog.Info("watch scope", "scope", scope.String())
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
+		Cache:  scope.CacheOptions(),
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: webhookPort}),
 		HealthProbeBindAddress: probeAddr,
+		PprofBindAddress:       pprofAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "nextdns-operator.nextdns.io",
+		// Every status the controllers write is pruned to the status budget
+		NewClient: func(config *rest.Config, options client.Options) (client.Client, error) {
+			c, err := client.New(config, options)
+			if err != nil {
+				return nil, err
+			}
+			return controller.WithStatusBudget(c), nil
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	// Detect Gateway API CRDs
-	gatewayAPIAvailable := false
+	// Detect optional CRDs
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
 	if err != nil {
 		setupLog.Error(err, "unable to create discovery client")
@@ -142,73 +543,293 @@ func main() {
 	if err != nil {
 		setupLog.Info("Warning: could not fully discover API resources", "error", err)
 	}
-	for _, resourceList := range apiResourceList {
-		if resourceList.GroupVersion == "gateway.networking.k8s.io/v1" {
-			for _, resource := range resourceList.APIResources {
-				if resource.Kind == "GatewayClass" {
-					gatewayAPIAvailable = true
-					break
-				}
-			}
-		}
-		if gatewayAPIAvailable {
-			break
-		}
-	}
 
+	// Detect Gateway API CRDs
+	gatewayAPIAvailable := apiResourceAvailable(apiResourceList, "gateway.networking.k8s.io/v1", "GatewayClass")
 	if gatewayAPIAvailable {
 		setupLog.Info("Gateway API CRDs detected, enabling gateway support")
 	} else {
 		setupLog.Info("Gateway API CRDs not detected, gateway support disabled")
 	}
 
+	// Detect Prometheus Operator CRDs
+	serviceMonitorAvailable := apiResourceAvailable(apiResourceList,
+		controller.ServiceMonitorGVK.GroupVersion().String(), controller.ServiceMonitorGVK.Kind)
+	if serviceMonitorAvailable {
+		setupLog.Info("ServiceMonitor CRD detected, enabling ServiceMonitor support")
+	} else {
+		setupLog.Info("ServiceMonitor CRD not detected, ServiceMonitor support disabled")
+	}
+
+	// List sources are fetched once and shared by the list and profile controllers
+	listSources := listsource.NewCache()
+
+	// Every controller emits Kubernetes events through one recorder
+	recorder := mgr.GetEventRecorderFor("nextdns-operator") //nolint:staticcheck // the reconcilers use record.EventRecorder
+
 	if err = (&controller.NextDNSProfileReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		SyncPeriod: syncDuration,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           recorder,
+		SyncPeriod:         syncDuration,
+		FanOutWindow:       fanOutDuration,
+		ListSources:        listSources,
+		ResourceLabels:     labels,
+		CompatibilityLevel: defaultCompatibilityLevel,
+		SectionConcurrency: sectionConcurrencyValue,
+		Metrics:            operatorMetrics,
+		Workqueue:          &queue,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NextDNSProfile")
 		os.Exit(1)
 	}
 
 	if err = (&controller.NextDNSAllowlistReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		SyncPeriod: syncDuration,
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    recorder,
+		SyncPeriod:  syncDuration,
+		ListSources: listSources,
+		Workqueue:   &queue,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NextDNSAllowlist")
 		os.Exit(1)
 	}
 
 	if err = (&controller.NextDNSDenylistReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    recorder,
+		SyncPeriod:  syncDuration,
+		ListSources: listSources,
+		Workqueue:   &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSDenylist")
+		os.Exit(1)
+	}
+
+	if err = (&controller.ClusterNextDNSAllowlistReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    recorder,
+		SyncPeriod:  syncDuration,
+		ListSources: listSources,
+		Workqueue:   &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterNextDNSAllowlist")
+		os.Exit(1)
+	}
+
+	if err = (&controller.ClusterNextDNSDenylistReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    recorder,
+		SyncPeriod:  syncDuration,
+		ListSources: listSources,
+		Workqueue:   &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterNextDNSDenylist")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSDenylistSourceReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       recorder,
+		SyncPeriod:     syncDuration,
+		ListSources:    listSources,
+		ResourceLabels: labels,
+		Workqueue:      &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSDenylistSource")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSTLDListReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    recorder,
+		SyncPeriod:  syncDuration,
+		ListSources: listSources,
+		Workqueue:   &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSTLDList")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSRewriteReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
+		Recorder:   recorder,
 		SyncPeriod: syncDuration,
+		Workqueue:  &queue,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "NextDNSDenylist")
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSRewrite")
 		os.Exit(1)
 	}
 
-	if err = (&controller.NextDNSTLDListReconciler{
+	if err = (&controller.NextDNSDeviceReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  recorder,
+		Workqueue: &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSDevice")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSReportReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  recorder,
+		Workqueue: &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSReport")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSAccountReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
 		SyncPeriod: syncDuration,
+		Recorder:   recorder,
+		Metrics:    operatorMetrics,
+		APIReader:  mgr.GetAPIReader(),
+		Workqueue:  &queue,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "NextDNSTLDList")
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSAccount")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NextDNSProfileGeneratorReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       recorder,
+		ResourceLabels: labels,
+		Workqueue:      &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NextDNSProfileGenerator")
+		os.Exit(1)
+	}
+
+	if err = (&controller.SecretReplicationReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       recorder,
+		ResourceLabels: labels,
+		Workqueue:      &queue,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SecretReplication")
 		os.Exit(1)
 	}
 
 	if err = (&controller.NextDNSCoreDNSReconciler{
-		Client:              mgr.GetClient(),
-		Scheme:              mgr.GetScheme(),
-		SyncPeriod:          syncDuration,
-		GatewayAPIAvailable: gatewayAPIAvailable,
-		GatewayClassName:    gatewayClassName,
+		Client:                     mgr.GetClient(),
+		Scheme:                     mgr.GetScheme(),
+		Recorder:                   recorder,
+		SyncPeriod:                 syncDuration,
+		GatewayAPIAvailable:        gatewayAPIAvailable,
+		GatewayClassName:           gatewayClassName,
+		ServiceMonitorAvailable:    serviceMonitorAvailable,
+		ResourceLabels:             labels,
+		AllowClusterDNSIntegration: allowClusterDNSIntegration,
+		Metrics:                    operatorMetrics,
+		Workqueue:                  &queue,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NextDNSCoreDNS")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = webhookv1alpha1.SetupNextDNSCoreDNSWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NextDNSCoreDNS")
+			os.Exit(1)
+		}
+		setupLog.Info("defaulting webhook enabled")
+	}
+
+	if catalogNamespace != "" {
+		catalogDuration, err := time.ParseDuration(catalogInterval)
+		if err == nil && catalogDuration <= 0 {
+			err = fmt.Errorf("catalog interval must be positive")
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid catalog interval", "catalogInterval", catalogInterval)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.CatalogPublisher{
+			Client:         mgr.GetClient(),
+			Namespace:      catalogNamespace,
+			Interval:       catalogDuration,
+			ResourceLabels: labels,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up catalog publisher")
+			os.Exit(1)
+		}
+		setupLog.Info("catalog publishing enabled", "namespace", catalogNamespace, "interval", catalogDuration)
+	}
+
+	if endpointDirectoryNamespace != "" {
+		endpointDirectoryDuration, err := time.ParseDuration(endpointDirectoryInterval)
+		if err == nil && endpointDirectoryDuration <= 0 {
+			err = fmt.Errorf("endpoint directory interval must be positive")
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid endpoint directory interval", "endpointDirectoryInterval", endpointDirectoryInterval)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.EndpointDirectoryPublisher{
+			Client:         mgr.GetClient(),
+			Namespace:      endpointDirectoryNamespace,
+			Interval:       endpointDirectoryDuration,
+			ResourceLabels: labels,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up endpoint directory publisher")
+			os.Exit(1)
+		}
+		setupLog.Info("endpoint directory publishing enabled", "namespace", endpointDirectoryNamespace, "interval", endpointDirectoryDuration)
+	}
+
+	if telemetryMode != controller.TelemetryOff {
+		telemetryDuration, err := time.ParseDuration(telemetryInterval)
+		if err == nil && telemetryDuration <= 0 {
+			err = fmt.Errorf("telemetry interval must be positive")
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid telemetry interval", "telemetryInterval", telemetryInterval)
+			os.Exit(1)
+		}
+		if err := controller.ValidateTelemetryMode(telemetryMode); err != nil {
+			setupLog.Error(err, "invalid telemetry mode")
+			os.Exit(1)
+		}
+		if telemetryMode == controller.TelemetrySend && telemetryEndpoint == "" {
+			setupLog.Error(fmt.Errorf("--telemetry-endpoint is required"), "invalid telemetry configuration")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.TelemetryReporter{
+			Client:          mgr.GetClient(),
+			Mode:            telemetryMode,
+			Endpoint:        telemetryEndpoint,
+			Interval:        telemetryDuration,
+			OperatorVersion: version,
+			Features: enabledFeatures(map[string]bool{
+				"webhooks":              enableWebhooks,
+				"clusterDNSIntegration": allowClusterDNSIntegration,
+				"catalog":               catalogNamespace != "",
+				"endpointDirectory":     endpointDirectoryNamespace != "",
+				"gatewayAPI":            gatewayAPIAvailable,
+				"serviceMonitor":        serviceMonitorAvailable,
+				"resourceLabels":        resourceLabels != "",
+				"defaultCredentials":    defaultCredentials.Name != "",
+			}),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up telemetry reporter")
+			os.Exit(1)
+		}
+		setupLog.Info("telemetry enabled", "mode", telemetryMode, "endpoint", telemetryEndpoint, "interval", telemetryDuration)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -225,6 +846,95 @@ func main() {
 	}
 }
 
+// enabledFeatures returns the names of the features set to true
+func enabledFeatures(features map[string]bool) []string {
+	var enabled []string
+	for name, on := range features {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+// runMigrate runs the migrate subcommand and returns the process exit code.
+// apiResourceAvailable reports whether the discovered resources include kind in groupVersion
+func apiResourceAvailable(resourceLists []*metav1.APIResourceList, groupVersion, kind string) bool {
+	for _, resourceList := range resourceLists {
+		if resourceList.GroupVersion != groupVersion {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if resource.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func runMigrate(args []string) int {
+	newClient := func() (client.Client, error) {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		return client.New(cfg, client.Options{Scheme: scheme})
+	}
+
+	err := migrate.Run(ctrl.SetupSignalHandler(), args, os.Stdin, os.Stdout, os.Stderr, newClient)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runScan runs the scan subcommand and returns the process exit code.
+func runScan(args []string) int {
+	newClient := func() (client.Client, error) {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		return client.New(cfg, client.Options{Scheme: scheme})
+	}
+
+	err := scan.Run(ctrl.SetupSignalHandler(), args, os.Stdout, os.Stderr, newClient, controller.DefaultClientFactory)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runDescribe runs the describe subcommand and returns the process exit code.
+func runDescribe(args []string) int {
+	newClient := func() (client.Client, error) {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		return client.New(cfg, client.Options{Scheme: scheme})
+	}
+
+	err := describe.Run(ctrl.SetupSignalHandler(), args, os.Stdout, os.Stderr, newClient)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "describe: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 // setupLogger creates a slog.Logger with the specified level and format.
 func setupLogger(level, format string) *slog.Logger {
 	var slogLevel slog.Level
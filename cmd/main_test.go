@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestSetupLogger_DefaultLevel(t *testing.T) {
@@ -81,3 +82,21 @@ func TestSetupLogger_CaseInsensitiveFormat(t *testing.T) {
 	_, ok := handler.(*slog.TextHandler)
 	assert.True(t, ok, "expected TextHandler for format=TEXT (uppercase)")
 }
+
+func TestAPIResourceAvailable(t *testing.T) {
+	lists := []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Kind: "Service"}}},
+		{GroupVersion: "monitoring.coreos.com/v1", APIResources: []metav1.APIResource{{Kind: "PodMonitor"}, {Kind: "ServiceMonitor"}}},
+	}
+
+	assert.True(t, apiResourceAvailable(lists, "monitoring.coreos.com/v1", "ServiceMonitor"))
+	assert.False(t, apiResourceAvailable(lists, "monitoring.coreos.com/v1", "Probe"))
+	assert.False(t, apiResourceAvailable(lists, "gateway.networking.k8s.io/v1", "GatewayClass"))
+	assert.False(t, apiResourceAvailable(nil, "v1", "Service"))
+}
+
+func TestEnabledFeatures(t *testing.T) {
+	features := enabledFeatures(map[string]bool{"webhooks": true, "catalog": false, "gatewayAPI": true})
+	assert.ElementsMatch(t, []string{"webhooks", "gatewayAPI"}, features)
+	assert.Empty(t, enabledFeatures(map[string]bool{"catalog": false}))
+}
@@ -0,0 +1,79 @@
+#!/usr/bin/env bash
+# E2E CLUSTER SETUP
+# Creates a kind cluster with MetalLB, builds the operator image, loads it
+# into the cluster and installs the Helm chart, ready for the e2e suite.
+#
+# Usage: ./hack/e2e-kind.sh
+#        KIND_CLUSTER=nextdns-e2e IMG=nextdns-operator:e2e ./hack/e2e-kind.sh
+#
+# Then run: go test -tags e2e ./test/e2e/... -v -timeout 30m
+
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+REPO_ROOT="$(cd "${SCRIPT_DIR}/.." && pwd)"
+
+KIND_CLUSTER="${KIND_CLUSTER:-nextdns-e2e}"
+IMG="${IMG:-nextdns-operator:e2e}"
+METALLB_VERSION="${METALLB_VERSION:-v0.14.9}"
+NAMESPACE="${NAMESPACE:-nextdns-system}"
+
+for tool in kind kubectl helm docker; do
+    if ! command -v "${tool}" &> /dev/null; then
+        echo "Error: ${tool} is required but not installed."
+        exit 1
+    fi
+done
+
+# Two workers so DaemonSets run more than one pod
+if ! kind get clusters | grep -qx "${KIND_CLUSTER}"; then
+    kind create cluster --name "${KIND_CLUSTER}" --wait 120s --config - <<KIND
+kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+  - role: worker
+  - role: worker
+KIND
+fi
+kubectl config use-context "kind-${KIND_CLUSTER}"
+
+echo "Installing MetalLB ${METALLB_VERSION}..."
+kubectl apply -f "https://raw.githubusercontent.com/metallb/metallb/${METALLB_VERSION}/config/manifests/metallb-native.yaml"
+kubectl -n metallb-system wait --for=condition=Available deployment/controller --timeout=180s
+kubectl -n metallb-system rollout status daemonset/speaker --timeout=180s
+
+# Hand out the top of the kind Docker network's IPv4 range
+subnet="$(docker network inspect kind -f '{{range .IPAM.Config}}{{.Subnet}} {{end}}' | tr ' ' '\n' | grep -m1 '\.')"
+prefix="$(echo "${subnet}" | cut -d. -f1-2)"
+kubectl apply -f - <<METALLB
+apiVersion: metallb.io/v1beta1
+kind: IPAddressPool
+metadata:
+  name: e2e
+  namespace: metallb-system
+spec:
+  addresses:
+    - ${prefix}.255.200-${prefix}.255.250
+---
+apiVersion: metallb.io/v1beta1
+kind: L2Advertisement
+metadata:
+  name: e2e
+  namespace: metallb-system
+METALLB
+
+echo "Building ${IMG}..."
+docker build -t "${IMG}" "${REPO_ROOT}"
+kind load docker-image "${IMG}" --name "${KIND_CLUSTER}"
+
+echo "Installing the operator..."
+helm dependency build "${REPO_ROOT}/chart"
+helm upgrade --install nextdns-operator "${REPO_ROOT}/chart" \
+    --namespace "${NAMESPACE}" --create-namespace \
+    --set image.repository="${IMG%:*}" \
+    --set image.tag="${IMG##*:}" \
+    --set image.pullPolicy=Never \
+    --wait --timeout 180s
+
+echo "Cluster ${KIND_CLUSTER} is ready for the e2e suite"
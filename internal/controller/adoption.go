@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// adoptionPolicyMissing reports whether an existing remote profile would be
+// adopted without an explicit spec.adoptionPolicy. Importing the remote
+// configuration into the spec keeps it as well, so no policy is needed then.
+func adoptionPolicyMissing(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return profile.Spec.ProfileID != "" &&
+		profile.Status.ProfileID == "" &&
+		profile.Spec.AdoptionPolicy == "" &&
+		(profile.Spec.ImportPolicy == "" || profile.Spec.ImportPolicy == nextdnsv1alpha1.ImportPolicyNone)
+}
+
+// firstManagedSync reports whether the operator has not yet applied a desired
+// state to the remote profile. The adoption policy only matters until then.
+func firstManagedSync(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return profile.Status.AppliedConfigHash == ""
+}
+
+// observeBeforeAdoption reports whether ObserveFirst holds back writes
+func observeBeforeAdoption(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return profile.Spec.ProfileID != "" &&
+		profile.Spec.AdoptionPolicy == nextdnsv1alpha1.AdoptionPolicyObserveFirst &&
+		firstManagedSync(profile)
+}
+
+// mergeOnce reports whether this sync must keep remote entries alongside the
+// desired ones
+func mergeOnce(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return profile.Spec.AdoptionPolicy == nextdnsv1alpha1.AdoptionPolicyMergeOnce &&
+		firstManagedSync(profile)
+}
+
+// mergeRemoteLists returns a copy of lists with the remote TLDs and rewrites
+// added. Collections the spec leaves unmanaged stay unmanaged.
+func mergeRemoteLists(ctx context.Context, client nextdns.ClientInterface, profileID string, lists *ResolvedLists) (*ResolvedLists, error) {
+	merged := *lists
+
+	if len(lists.TLDs) > 0 {
+		remote, err := client.GetSecurityTLDs(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get security TLDs: %w", err)
+		}
+		ids := make([]string, 0, len(remote))
+		for _, tld := range remote {
+			ids = append(ids, tld.ID)
+		}
+		merged.TLDs = unionIDs(lists.TLDs, ids)
+	}
+
+	if lists.Rewrites != nil {
+		remote, err := client.GetRewrites(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rewrites: %w", err)
+		}
+		merged.Rewrites = append([]nextdns.RewriteEntry(nil), lists.Rewrites...)
+		seen := make(map[string]bool, len(lists.Rewrites))
+		for _, e := range lists.Rewrites {
+			seen[e.Name] = true
+		}
+		for _, r := range remote {
+			if !seen[r.Name] {
+				seen[r.Name] = true
+				merged.Rewrites = append(merged.Rewrites, nextdns.RewriteEntry{Name: r.Name, Content: r.Content})
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+// unionIDs returns desired followed by the remote IDs it does not contain
+func unionIDs(desired, remote []string) []string {
+	result := append([]string(nil), desired...)
+	seen := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		seen[id] = true
+	}
+	for _, id := range remote {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
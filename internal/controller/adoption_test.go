@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// newAdoptionTest returns a reconciler adopting remote profile abc123, which
+// already carries a dashboard-managed denylist entry, TLD and rewrite
+func newAdoptionTest(t *testing.T, policy nextdnsv1alpha1.AdoptionPolicy) (*NextDNSProfileReconciler, *nextdns.MockClient, ctrl.Request) {
+	t.Helper()
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "adopted",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Adopted",
+			ProfileID:      "abc123",
+			AdoptionPolicy: policy,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Denylist: []nextdnsv1alpha1.DomainEntry{
+				{Domain: "ads.example.com", Active: ptr.To(true)},
+			},
+			TLDListRefs: []nextdnsv1alpha1.ListReference{{Name: "tlds"}},
+			Rewrites: []nextdnsv1alpha1.RewriteEntry{
+				{From: "nas.home", To: "192.168.1.10"},
+			},
+		},
+	}
+	tldList := &nextdnsv1alpha1.NextDNSTLDList{
+		ObjectMeta: metav1.ObjectMeta{Name: "tlds", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSTLDListSpec{
+			TLDs: []nextdnsv1alpha1.TLDEntry{{TLD: "zip"}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Adopted", "abc123.dns.nextdns.io")
+	require.NoError(t, mockNDS.AddDenylistEntry(ctx, "abc123", "manual.example.com", true))
+	require.NoError(t, mockNDS.SyncSecurityTLDs(ctx, "abc123", []string{"xyz"}))
+	_, err := mockNDS.SyncRewrites(ctx, "abc123", []nextdns.RewriteEntry{{Name: "printer.home", Content: "192.168.1.20"}})
+	require.NoError(t, err)
+	mockNDS.Calls = nil
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, tldList, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "adopted", Namespace: "default"}}
+	return reconciler, mockNDS, req
+}
+
+func remoteDenylist(t *testing.T, mockNDS *nextdns.MockClient) []string {
+	t.Helper()
+	entries, err := mockNDS.GetDenylist(context.Background(), "abc123")
+	require.NoError(t, err)
+	var domains []string
+	for _, e := range entries {
+		domains = append(domains, e.ID)
+	}
+	return domains
+}
+
+func TestReconcile_AdoptionPolicyRequired(t *testing.T) {
+	ctx := context.Background()
+	reconciler, mockNDS, req := newAdoptionTest(t, "")
+
+	result, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "AdoptionPolicyRequired", cond.Reason)
+	assert.Empty(t, updated.Status.ProfileID)
+	assert.Empty(t, mockNDS.Calls, "no NextDNS calls before a policy is chosen")
+}
+
+func TestReconcile_AdoptionObserveFirst(t *testing.T) {
+	ctx := context.Background()
+	reconciler, mockNDS, req := newAdoptionTest(t, nextdnsv1alpha1.AdoptionPolicyObserveFirst)
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.ObservedConfig, "remote config should be imported for review")
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeObserveOnly)
+	require.NotNil(t, cond)
+	assert.Equal(t, "AdoptionPending", cond.Reason)
+	assert.False(t, mockNDS.WasMethodCalled("SyncDenylist"))
+	assert.False(t, mockNDS.WasMethodCalled("UpdateProfile"))
+	assert.ElementsMatch(t, []string{"manual.example.com"}, remoteDenylist(t, mockNDS))
+
+	// Flipping the policy starts syncing
+	updated.Spec.AdoptionPolicy = nextdnsv1alpha1.AdoptionPolicyOverwrite
+	require.NoError(t, reconciler.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	assert.Nil(t, updated.Status.ObservedConfig)
+	assert.NotEmpty(t, updated.Status.AppliedConfigHash)
+	cond = meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeObserveOnly)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.ElementsMatch(t, []string{"ads.example.com"}, remoteDenylist(t, mockNDS))
+}
+
+func TestReconcile_AdoptionMergeOnce(t *testing.T) {
+	ctx := context.Background()
+	reconciler, mockNDS, req := newAdoptionTest(t, nextdnsv1alpha1.AdoptionPolicyMergeOnce)
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"manual.example.com", "ads.example.com"}, remoteDenylist(t, mockNDS))
+	tlds, err := mockNDS.GetSecurityTLDs(ctx, "abc123")
+	require.NoError(t, err)
+	var tldIDs []string
+	for _, tld := range tlds {
+		tldIDs = append(tldIDs, tld.ID)
+	}
+	assert.ElementsMatch(t, []string{"zip", "xyz"}, tldIDs)
+	require.Len(t, mockNDS.Rewrites["abc123"], 2)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, "abc123", updated.Status.ProfileID)
+	assert.NotEmpty(t, updated.Status.AppliedConfigHash)
+}
+
+func TestReconcile_ImportMergeOnAdopt(t *testing.T) {
+	ctx := context.Background()
+	reconciler, mockNDS, req := newAdoptionTest(t, "")
+
+	var profile nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &profile))
+	profile.Spec.ImportPolicy = nextdnsv1alpha1.ImportPolicyMergeOnAdopt
+	require.NoError(t, reconciler.Update(ctx, &profile))
+
+	// The first reconcile imports the remote configuration into the spec
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, mockNDS.WasMethodCalled("SyncDenylist"), "nothing is written while importing")
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.ImportedAt)
+	var domains []string
+	for _, e := range updated.Spec.Denylist {
+		domains = append(domains, e.Domain)
+	}
+	assert.Equal(t, []string{"ads.example.com", "manual.example.com"}, domains)
+	assert.Len(t, updated.Spec.Rewrites, 2)
+	assert.NotNil(t, updated.Spec.Security)
+
+	// The next reconcile syncs the imported spec without an adoption policy
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, reconciler.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, "abc123", updated.Status.ProfileID)
+	assert.NotEmpty(t, updated.Status.AppliedConfigHash)
+	assert.ElementsMatch(t, []string{"manual.example.com", "ads.example.com"}, remoteDenylist(t, mockNDS))
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// defaultAutoscalingCPUTarget is used when autoscaling sets no target
+	defaultAutoscalingCPUTarget int32 = 80
+
+	// defaultQueriesPerSecondMetric is the pods metric used for QPS targets
+	defaultQueriesPerSecondMetric = "coredns_dns_requests_per_second"
+)
+
+// autoscalingConfig returns the autoscaling config when a HorizontalPodAutoscaler
+// should manage the Deployment, or nil otherwise
+func autoscalingConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.CoreDNSAutoscalingConfig {
+	d := coreDNS.Spec.Deployment
+	if d == nil || d.Autoscaling == nil || d.Mode == nextdnsv1alpha1.DeploymentModeDaemonSet {
+		return nil
+	}
+	if !boolValue(d.Autoscaling.Enabled, true) {
+		return nil
+	}
+	return d.Autoscaling
+}
+
+// minReplicas returns the autoscaler's lower replica limit
+func minReplicas(cfg *nextdnsv1alpha1.CoreDNSAutoscalingConfig) int32 {
+	if cfg.MinReplicas != nil {
+		return *cfg.MinReplicas
+	}
+	return defaultReplicas
+}
+
+// buildHPAMetrics translates the autoscaling targets into HPA metric specs.
+// CPU utilization is targeted when no other target is set.
+func buildHPAMetrics(cfg *nextdnsv1alpha1.CoreDNSAutoscalingConfig) []autoscalingv2.MetricSpec {
+	var metrics []autoscalingv2.MetricSpec
+
+	resourceMetric := func(name corev1.ResourceName, target int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: name,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &target,
+				},
+			},
+		}
+	}
+
+	if cfg.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *cfg.TargetCPUUtilizationPercentage))
+	}
+	if cfg.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *cfg.TargetMemoryUtilizationPercentage))
+	}
+	if cfg.TargetQueriesPerSecond != nil {
+		metricName := cfg.QueriesPerSecondMetric
+		if metricName == "" {
+			metricName = defaultQueriesPerSecondMetric
+		}
+		target := cfg.TargetQueriesPerSecond.DeepCopy()
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: metricName},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: &target,
+				},
+			},
+		})
+	}
+
+	if len(metrics) == 0 {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, defaultAutoscalingCPUTarget))
+	}
+	return metrics
+}
+
+// reconcileHPA creates, updates, or cleans up the HorizontalPodAutoscaler for the CoreDNS Deployment
+func (r *NextDNSCoreDNSReconciler) reconcileHPA(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+	resourceName := r.getResourceName(coreDNS, profile)
+	hpaName := resourceName + "-hpa"
+
+	cfg := autoscalingConfig(coreDNS)
+	if cfg == nil {
+		// Clean up any existing HPA
+		existing := &autoscalingv2.HorizontalPodAutoscaler{}
+		err := r.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: coreDNS.Namespace}, existing)
+		if err == nil {
+			logger.Info("Cleaning up stale HorizontalPodAutoscaler", "name", hpaName)
+			return r.Delete(ctx, existing)
+		}
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	minReplicas := minReplicas(cfg)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      hpaName,
+			Namespace: coreDNS.Namespace,
+		},
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, hpa, func() error {
+		hpa.Labels = r.ResourceLabels.merge(r.buildLabels(coreDNS, profile))
+		hpa.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       resourceName,
+		}
+		hpa.Spec.MinReplicas = &minReplicas
+		hpa.Spec.MaxReplicas = cfg.MaxReplicas
+		hpa.Spec.Metrics = buildHPAMetrics(cfg)
+
+		return controllerutil.SetControllerReference(coreDNS, hpa, r.Scheme)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to reconcile HorizontalPodAutoscaler: %w", err)
+	}
+
+	if op != controllerutil.OperationResultNone {
+		logger.Info("HorizontalPodAutoscaler reconciled", "operation", op, "name", hpaName)
+	}
+
+	return nil
+}
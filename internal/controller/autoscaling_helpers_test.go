@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestAutoscalingConfig(t *testing.T) {
+	cfg := &nextdnsv1alpha1.CoreDNSAutoscalingConfig{MaxReplicas: 5}
+
+	tests := []struct {
+		name       string
+		deployment *nextdnsv1alpha1.CoreDNSDeploymentConfig
+		want       bool
+	}{
+		{name: "no deployment config", deployment: nil, want: false},
+		{name: "no autoscaling", deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{}, want: false},
+		{name: "enabled by default", deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{Autoscaling: cfg}, want: true},
+		{
+			name: "explicitly disabled",
+			deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Autoscaling: &nextdnsv1alpha1.CoreDNSAutoscalingConfig{Enabled: ptr.To(false), MaxReplicas: 5},
+			},
+			want: false,
+		},
+		{
+			name: "DaemonSet mode",
+			deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode:        nextdnsv1alpha1.DeploymentModeDaemonSet,
+				Autoscaling: cfg,
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{Deployment: tt.deployment},
+			}
+			assert.Equal(t, tt.want, autoscalingConfig(coreDNS) != nil)
+		})
+	}
+}
+
+func TestBuildHPAMetrics(t *testing.T) {
+	t.Run("defaults to CPU", func(t *testing.T) {
+		metrics := buildHPAMetrics(&nextdnsv1alpha1.CoreDNSAutoscalingConfig{MaxReplicas: 5})
+		require.Len(t, metrics, 1)
+		assert.Equal(t, corev1.ResourceCPU, metrics[0].Resource.Name)
+		assert.Equal(t, defaultAutoscalingCPUTarget, *metrics[0].Resource.Target.AverageUtilization)
+	})
+
+	t.Run("all targets", func(t *testing.T) {
+		qps := resource.MustParse("500")
+		metrics := buildHPAMetrics(&nextdnsv1alpha1.CoreDNSAutoscalingConfig{
+			MaxReplicas:                       5,
+			TargetCPUUtilizationPercentage:    ptr.To[int32](70),
+			TargetMemoryUtilizationPercentage: ptr.To[int32](90),
+			TargetQueriesPerSecond:            &qps,
+		})
+		require.Len(t, metrics, 3)
+		assert.Equal(t, corev1.ResourceCPU, metrics[0].Resource.Name)
+		assert.Equal(t, int32(70), *metrics[0].Resource.Target.AverageUtilization)
+		assert.Equal(t, corev1.ResourceMemory, metrics[1].Resource.Name)
+		assert.Equal(t, int32(90), *metrics[1].Resource.Target.AverageUtilization)
+		assert.Equal(t, autoscalingv2.PodsMetricSourceType, metrics[2].Type)
+		assert.Equal(t, defaultQueriesPerSecondMetric, metrics[2].Pods.Metric.Name)
+		assert.Equal(t, "500", metrics[2].Pods.Target.AverageValue.String())
+	})
+
+	t.Run("QPS only with custom metric", func(t *testing.T) {
+		qps := resource.MustParse("250")
+		metrics := buildHPAMetrics(&nextdnsv1alpha1.CoreDNSAutoscalingConfig{
+			MaxReplicas:            5,
+			TargetQueriesPerSecond: &qps,
+			QueriesPerSecondMetric: "dns_qps",
+		})
+		require.Len(t, metrics, 1)
+		assert.Equal(t, "dns_qps", metrics[0].Pods.Metric.Name)
+	})
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_Autoscaling(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "hpa-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Replicas: ptr.To[int32](4),
+				Autoscaling: &nextdnsv1alpha1.CoreDNSAutoscalingConfig{
+					MinReplicas: ptr.To[int32](3),
+					MaxReplicas: 10,
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hpa-dns", Namespace: "default"}}
+	deploymentKey := types.NamespacedName{Name: "hpa-dns-abc123-coredns", Namespace: "default"}
+	hpaKey := types.NamespacedName{Name: "hpa-dns-abc123-coredns-hpa", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	require.NoError(t, fakeClient.Get(ctx, hpaKey, hpa), "HPA should be created")
+	assert.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, deploymentKey.Name, hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(3), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	require.Len(t, hpa.OwnerReferences, 1)
+	assert.Equal(t, "hpa-dns", hpa.OwnerReferences[0].Name)
+
+	// New Deployments start at minReplicas
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, deploymentKey, deployment))
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+
+	// The autoscaler scales up; reconciling must not reset the replica count
+	deployment.Spec.Replicas = ptr.To[int32](7)
+	require.NoError(t, fakeClient.Update(ctx, deployment))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, deploymentKey, deployment))
+	assert.Equal(t, int32(7), *deployment.Spec.Replicas)
+
+	// Disabling autoscaling removes the HPA and restores the static count
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.Deployment.Autoscaling.Enabled = ptr.To(false)
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, hpaKey, hpa)
+	assert.True(t, apierrors.IsNotFound(err), "HPA should be deleted when autoscaling is disabled")
+	require.NoError(t, fakeClient.Get(ctx, deploymentKey, deployment))
+	assert.Equal(t, int32(4), *deployment.Spec.Replicas)
+}
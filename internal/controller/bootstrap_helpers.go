@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+// ConditionTypeDoHBootstrapped reports how the CoreDNS pods resolve the DoH
+// endpoint hostname. Only set when the primary protocol is DoH.
+const ConditionTypeDoHBootstrapped = "DoHBootstrapped"
+
+// dohBootstrapServers returns the addresses the DoH endpoint hostname is
+// pinned to, or nil unless the primary protocol is DoH
+func dohBootstrapServers(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) []string {
+	if upstreamProtocol(coreDNS) != coredns.ProtocolDoH {
+		return nil
+	}
+	return coreDNS.Spec.Corefile.Upstream.BootstrapServers
+}
+
+// applyDoHBootstrap pins the DoH endpoint hostname to the bootstrap servers
+// in the pods' /etc/hosts, so CoreDNS reaches NextDNS without asking another
+// resolver for its address
+func applyDoHBootstrap(podSpec *corev1.PodSpec, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	for _, ip := range dohBootstrapServers(coreDNS) {
+		podSpec.HostAliases = append(podSpec.HostAliases, corev1.HostAlias{
+			IP:        ip,
+			Hostnames: []string{coredns.DoHHost},
+		})
+	}
+}
+
+// setDoHBootstrapCondition reports whether the DoH endpoint hostname is
+// pinned or resolved through the pod DNS policy, which fails when that
+// resolver depends on this instance
+func (r *NextDNSCoreDNSReconciler) setDoHBootstrapCondition(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	if upstreamProtocol(coreDNS) != coredns.ProtocolDoH {
+		meta.RemoveStatusCondition(&coreDNS.Status.Conditions, ConditionTypeDoHBootstrapped)
+		return
+	}
+
+	if servers := dohBootstrapServers(coreDNS); len(servers) > 0 {
+		r.setCondition(coreDNS, ConditionTypeDoHBootstrapped, metav1.ConditionTrue, "Pinned",
+			fmt.Sprintf("%s is pinned to %s", coredns.DoHHost, strings.Join(servers, ", ")))
+		return
+	}
+
+	message := fmt.Sprintf("%s is resolved through the pod DNS policy; set spec.corefile.upstream.bootstrapServers to pin it", coredns.DoHHost)
+	if clusterDNSMode(coreDNS) != nextdnsv1alpha1.ClusterDNSIntegrationNone || nodeLocalEnabled(coreDNS) {
+		message = fmt.Sprintf("%s is resolved through the pod DNS policy, which may depend on this instance; set spec.corefile.upstream.bootstrapServers to pin it", coredns.DoHHost)
+	}
+	r.setCondition(coreDNS, ConditionTypeDoHBootstrapped, metav1.ConditionFalse, "ResolvedByPodDNS", message)
+}
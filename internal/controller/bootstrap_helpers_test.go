@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+func newBootstrapCoreDNS(protocol nextdnsv1alpha1.DNSProtocol, servers ...string) *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{
+					Primary:          protocol,
+					BootstrapServers: servers,
+				},
+			},
+		},
+	}
+}
+
+func TestBuildPodSpec_DoHBootstrap(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	coreDNS := newBootstrapCoreDNS(nextdnsv1alpha1.DNSProtocolDoH, "45.90.28.0", "2a07:a8c0::")
+	podSpec := r.buildPodSpec(coreDNS, "test-cm")
+	assert.Equal(t, []corev1.HostAlias{
+		{IP: "45.90.28.0", Hostnames: []string{coredns.DoHHost}},
+		{IP: "2a07:a8c0::", Hostnames: []string{coredns.DoHHost}},
+	}, podSpec.HostAliases)
+
+	// Ignored unless the primary protocol is DoH
+	coreDNS = newBootstrapCoreDNS(nextdnsv1alpha1.DNSProtocolDoT, "45.90.28.0")
+	podSpec = r.buildPodSpec(coreDNS, "test-cm")
+	assert.Empty(t, podSpec.HostAliases)
+}
+
+func TestSetDoHBootstrapCondition(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	coreDNS := newBootstrapCoreDNS(nextdnsv1alpha1.DNSProtocolDoH, "45.90.28.0")
+	r.setDoHBootstrapCondition(coreDNS)
+	condition := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeDoHBootstrapped)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Pinned", condition.Reason)
+
+	coreDNS.Spec.Corefile.Upstream.BootstrapServers = nil
+	coreDNS.Spec.ClusterDNSIntegration = &nextdnsv1alpha1.ClusterDNSIntegrationConfig{
+		Mode: nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService,
+	}
+	r.setDoHBootstrapCondition(coreDNS)
+	condition = meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeDoHBootstrapped)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "ResolvedByPodDNS", condition.Reason)
+	assert.Contains(t, condition.Message, "may depend on this instance")
+
+	coreDNS.Spec.Corefile.Upstream.Primary = nextdnsv1alpha1.DNSProtocolDoT
+	r.setDoHBootstrapCondition(coreDNS)
+	assert.Nil(t, meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeDoHBootstrapped))
+}
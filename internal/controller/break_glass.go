@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// AnnotationBreakGlass suspends blocking on a managed profile for the
+// duration it holds, e.g. "30m". Privacy blocklists, parental control
+// categories and services and denylist entries are deactivated until the
+// window expires or the annotation is removed; the spec is left untouched
+// and is applied again on restoration. The annotation is removed once the
+// window expired.
+const AnnotationBreakGlass = "nextdns.io/break-glass"
+
+// ConditionTypeBreakGlass indicates blocking is suspended by the break-glass
+// annotation
+const ConditionTypeBreakGlass = "BreakGlass"
+
+// MaxBreakGlassDuration is the longest break-glass window a profile can
+// request, so a forgotten annotation cannot disable blocking indefinitely
+const MaxBreakGlassDuration = 24 * time.Hour
+
+// parseBreakGlassDuration parses the value of the break-glass annotation
+func parseBreakGlassDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s duration %q", AnnotationBreakGlass, value)
+	}
+	if duration > MaxBreakGlassDuration {
+		return 0, fmt.Errorf("%s duration %s exceeds the maximum of %s", AnnotationBreakGlass, duration, MaxBreakGlassDuration)
+	}
+	return duration, nil
+}
+
+// reconcileBreakGlass updates status.breakGlass and the BreakGlass condition
+// of profile from the break-glass annotation at now, emitting an event when
+// blocking is suspended or restored. It returns how long blocking stays
+// suspended, 0 if it is not, and whether the window expired so the
+// annotation must be removed.
+func (r *NextDNSProfileReconciler) reconcileBreakGlass(profile *nextdnsv1alpha1.NextDNSProfile, now time.Time) (time.Duration, bool) {
+	wasActive := meta.IsStatusConditionTrue(profile.Status.Conditions, ConditionTypeBreakGlass)
+
+	value, requested := profile.GetAnnotations()[AnnotationBreakGlass]
+	if !requested {
+		profile.Status.BreakGlass = nil
+		meta.RemoveStatusCondition(&profile.Status.Conditions, ConditionTypeBreakGlass)
+		if wasActive {
+			recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonBreakGlassEnded,
+				"Blocking restored: the %s annotation was removed", AnnotationBreakGlass)
+		}
+		return 0, false
+	}
+
+	duration, err := parseBreakGlassDuration(value)
+	if err != nil {
+		profile.Status.BreakGlass = nil
+		if previous := meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeBreakGlass); previous == nil || previous.Reason != "InvalidDuration" {
+			recordEvent(r.Recorder, profile, corev1.EventTypeWarning, EventReasonBreakGlassRejected,
+				"%s; blocking is not suspended", err.Error())
+		}
+		r.setCondition(profile, ConditionTypeBreakGlass, metav1.ConditionFalse, "InvalidDuration", err.Error())
+		return 0, false
+	}
+
+	// A changed duration moves the end of a running window; once the window
+	// expired, only a new value starts another one
+	window := profile.Status.BreakGlass
+	switch {
+	case window == nil || (window.Duration != value && !now.Before(window.ExpiresAt.Time)):
+		window = &nextdnsv1alpha1.BreakGlassStatus{
+			Duration:  value,
+			StartedAt: metav1.NewTime(now),
+			ExpiresAt: metav1.NewTime(now.Add(duration)),
+		}
+	case window.Duration != value:
+		window = &nextdnsv1alpha1.BreakGlassStatus{
+			Duration:  value,
+			StartedAt: window.StartedAt,
+			ExpiresAt: metav1.NewTime(window.StartedAt.Add(duration)),
+		}
+	}
+	profile.Status.BreakGlass = window
+
+	remaining := window.ExpiresAt.Sub(now)
+	if remaining <= 0 {
+		if wasActive {
+			recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonBreakGlassEnded,
+				"Blocking restored: the break-glass window expired")
+		}
+		r.setCondition(profile, ConditionTypeBreakGlass, metav1.ConditionFalse, "Expired",
+			fmt.Sprintf("Break-glass window expired at %s", window.ExpiresAt.UTC().Format(time.RFC3339)))
+		return 0, true
+	}
+
+	message := fmt.Sprintf("Blocking is suspended until %s by the %s annotation",
+		window.ExpiresAt.UTC().Format(time.RFC3339), AnnotationBreakGlass)
+	if !wasActive {
+		recordEvent(r.Recorder, profile, corev1.EventTypeWarning, EventReasonBreakGlassActivated, "%s", message)
+	}
+	r.setCondition(profile, ConditionTypeBreakGlass, metav1.ConditionTrue, "Active", message)
+	return remaining, false
+}
+
+// suspendBlocking deactivates the privacy blocklists, parental control
+// categories and services of spec and the denylist entries of lists.
+// Security protections, blocked TLDs and allowlists are kept. The denylist
+// is copied, since lists may share it with the list cache.
+func suspendBlocking(spec *nextdnsv1alpha1.NextDNSProfileSpec, lists *ResolvedLists) {
+	if spec.Privacy != nil {
+		for i := range spec.Privacy.Blocklists {
+			spec.Privacy.Blocklists[i].Active = ptr.To(false)
+		}
+	}
+	if spec.ParentalControl != nil {
+		for i := range spec.ParentalControl.Categories {
+			spec.ParentalControl.Categories[i].Active = ptr.To(false)
+		}
+		for i := range spec.ParentalControl.Services {
+			spec.ParentalControl.Services[i].Active = ptr.To(false)
+		}
+	}
+
+	if len(lists.Denylist) > 0 {
+		denylist := make([]nextdns.DomainEntry, len(lists.Denylist))
+		for i, entry := range lists.Denylist {
+			entry.Active = false
+			denylist[i] = entry
+		}
+		lists.Denylist = denylist
+	}
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestParseBreakGlassDuration(t *testing.T) {
+	duration, err := parseBreakGlassDuration("30m")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, duration)
+
+	for _, value := range []string{"", "soon", "0s", "-5m", "25h"} {
+		_, err := parseBreakGlassDuration(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestReconcileBreakGlass(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &NextDNSProfileReconciler{Recorder: recorder}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationBreakGlass: "30m"}},
+	}
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	remaining, expired := r.reconcileBreakGlass(profile, start)
+	assert.Equal(t, 30*time.Minute, remaining)
+	assert.False(t, expired)
+	require.NotNil(t, profile.Status.BreakGlass)
+	assert.Equal(t, start, profile.Status.BreakGlass.StartedAt.Time)
+	assert.True(t, meta.IsStatusConditionTrue(profile.Status.Conditions, ConditionTypeBreakGlass))
+	events := drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], EventReasonBreakGlassActivated)
+
+	// Later reconciles keep the window and emit nothing
+	remaining, _ = r.reconcileBreakGlass(profile, start.Add(10*time.Minute))
+	assert.Equal(t, 20*time.Minute, remaining)
+	assert.Empty(t, drainEvents(recorder))
+
+	// A new duration extends the running window from its start
+	profile.Annotations[AnnotationBreakGlass] = "1h"
+	remaining, _ = r.reconcileBreakGlass(profile, start.Add(10*time.Minute))
+	assert.Equal(t, 50*time.Minute, remaining)
+	assert.Equal(t, start, profile.Status.BreakGlass.StartedAt.Time)
+
+	remaining, expired = r.reconcileBreakGlass(profile, start.Add(time.Hour))
+	assert.Zero(t, remaining)
+	assert.True(t, expired)
+	condition := meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeBreakGlass)
+	require.NotNil(t, condition)
+	assert.Equal(t, "Expired", condition.Reason)
+	events = drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], EventReasonBreakGlassEnded)
+
+	// An expired window is not restarted by the same value
+	remaining, expired = r.reconcileBreakGlass(profile, start.Add(2*time.Hour))
+	assert.Zero(t, remaining)
+	assert.True(t, expired)
+	assert.Empty(t, drainEvents(recorder))
+
+	delete(profile.Annotations, AnnotationBreakGlass)
+	remaining, expired = r.reconcileBreakGlass(profile, start.Add(2*time.Hour))
+	assert.Zero(t, remaining)
+	assert.False(t, expired)
+	assert.Nil(t, profile.Status.BreakGlass)
+	assert.Nil(t, meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeBreakGlass))
+
+	profile.Annotations[AnnotationBreakGlass] = "forever"
+	remaining, _ = r.reconcileBreakGlass(profile, start)
+	assert.Zero(t, remaining)
+	assert.False(t, meta.IsStatusConditionTrue(profile.Status.Conditions, ConditionTypeBreakGlass))
+	events = drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], EventReasonBreakGlassRejected)
+	r.reconcileBreakGlass(profile, start)
+	assert.Empty(t, drainEvents(recorder), "an invalid duration is reported once")
+}
+
+func TestSuspendBlocking(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Security: &nextdnsv1alpha1.SecuritySpec{Cryptojacking: ptr.To(true)},
+		Privacy: &nextdnsv1alpha1.PrivacySpec{
+			Blocklists: []nextdnsv1alpha1.BlocklistEntry{{ID: "nextdns-recommended"}},
+		},
+		ParentalControl: &nextdnsv1alpha1.ParentalControlSpec{
+			Categories: []nextdnsv1alpha1.CategoryEntry{{ID: "gambling"}},
+			Services:   []nextdnsv1alpha1.ServiceEntry{{ID: "tiktok", Active: ptr.To(true)}},
+		},
+	}
+	cached := []nextdns.DomainEntry{{Domain: "ads.example.com", Active: true}}
+	lists := &ResolvedLists{
+		Denylist:  cached,
+		Allowlist: []nextdns.DomainEntry{{Domain: "good.example.com", Active: true}},
+		TLDs:      []string{"zip"},
+	}
+
+	suspendBlocking(spec, lists)
+
+	assert.Empty(t, desiredPrivacyBlocklists(spec.Privacy))
+	parentalControl := desiredParentalControlConfig(spec.ParentalControl)
+	assert.Empty(t, parentalControl.Categories)
+	assert.Empty(t, parentalControl.Services)
+	assert.False(t, lists.Denylist[0].Active)
+	assert.True(t, cached[0].Active, "the cached denylist is not modified")
+	assert.True(t, lists.Allowlist[0].Active)
+	assert.Equal(t, []string{"zip"}, lists.TLDs)
+	assert.True(t, *spec.Security.Cryptojacking)
+}
+
+func TestReconcile_BreakGlass(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "break-glass",
+			Namespace:   "default",
+			Finalizers:  []string{FinalizerName},
+			Annotations: map[string]string{AnnotationBreakGlass: "30m"},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Break Glass",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Denylist:       []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Break Glass", "abc123.dns.nextdns.io")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: time.Hour,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "break-glass", Namespace: "default"}}
+
+	denylistActive := func() bool {
+		denylist, err := mockNDS.GetDenylist(ctx, "abc123")
+		require.NoError(t, err)
+		require.Len(t, denylist, 1)
+		return denylist[0].Active
+	}
+
+	result, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, denylistActive(), "blocking is suspended")
+	assert.LessOrEqual(t, result.RequeueAfter, 30*time.Minute, "the sync is scheduled for the end of the window")
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.BreakGlass)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeBreakGlass))
+
+	// Once the window expired the spec is applied again and the annotation removed
+	updated.Status.BreakGlass.StartedAt = metav1.NewTime(time.Now().Add(-time.Hour))
+	updated.Status.BreakGlass.ExpiresAt = metav1.NewTime(time.Now().Add(-30 * time.Minute))
+	require.NoError(t, fakeClient.Status().Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, denylistActive(), "blocking is restored")
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.NotContains(t, updated.Annotations, AnnotationBreakGlass)
+	assert.False(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeBreakGlass))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Nil(t, updated.Status.BreakGlass)
+	assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeBreakGlass))
+	assert.True(t, denylistActive())
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// CatalogConfigMapName is the ConfigMap the catalog is published to
+	CatalogConfigMapName = "nextdns-catalog"
+
+	// CatalogBlocklistsKey holds the privacy blocklists of the catalog
+	CatalogBlocklistsKey = "blocklists.json"
+
+	// CatalogNativesKey holds the native tracking protection lists of the catalog
+	CatalogNativesKey = "natives.json"
+
+	// CatalogCategoriesKey holds the parental control categories of the catalog
+	CatalogCategoriesKey = "categories.json"
+
+	// AnnotationCatalogUpdated records when the catalog was last published
+	AnnotationCatalogUpdated = "nextdns.io/catalog-updated"
+
+	// DefaultCatalogInterval is the default period between catalog updates
+	DefaultCatalogInterval = 6 * time.Hour
+)
+
+// CatalogEntry describes a blocklist, native tracking protection list or
+// parental control category in the published catalog
+type CatalogEntry struct {
+	// ID is the identifier to use in a NextDNSProfile spec
+	ID string `json:"id"`
+
+	// Name is the display name; only known for blocklists
+	Name string `json:"name,omitempty"`
+
+	// Entries is the number of domains; only known for blocklists
+	Entries int `json:"entries,omitempty"`
+
+	// Profiles is the number of NextDNS profiles the entry is enabled on
+	Profiles int `json:"profiles"`
+}
+
+// CatalogPublisher periodically publishes the blocklists, native tracking
+// protection lists and parental control categories enabled on the profiles
+// the operator manages to a ConfigMap, so tooling can offer them without API
+// credentials. The NextDNS API has no catalog endpoint, so entries no profile
+// uses are not listed.
+type CatalogPublisher struct {
+	Client         client.Client
+	ClientFactory  ClientFactory
+	Namespace      string
+	Interval       time.Duration
+	ResourceLabels ResourceLabels
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (p *CatalogPublisher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start publishes the catalog every Interval until ctx is done
+func (p *CatalogPublisher) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("catalog")
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(ctx); err != nil {
+			logger.Error(err, "Failed to publish NextDNS catalog")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish reads the catalog from the remote profiles and writes it to the
+// catalog ConfigMap. Profiles that cannot be read are skipped.
+func (p *CatalogPublisher) Publish(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("catalog")
+
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := p.Client.List(ctx, &profiles); err != nil {
+		return fmt.Errorf("failed to list NextDNSProfiles: %w", err)
+	}
+
+	factory := p.ClientFactory
+	if factory == nil {
+		factory = DefaultClientFactory
+	}
+
+	blocklists := map[string]*CatalogEntry{}
+	natives := map[string]*CatalogEntry{}
+	categories := map[string]*CatalogEntry{}
+	read := map[string]bool{}
+
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		profileID := profile.Status.ProfileID
+		if profileID == "" || read[profileID] {
+			continue
+		}
+
+		apiKey, err := profileAPIKey(ctx, p.Client, profile)
+		if err != nil {
+			logger.Info("Skipping profile for catalog", "profile", client.ObjectKeyFromObject(profile), "error", err.Error())
+			continue
+		}
+		nextdnsClient, err := factory(apiKey)
+		if err != nil {
+			logger.Info("Skipping profile for catalog", "profile", client.ObjectKeyFromObject(profile), "error", err.Error())
+			continue
+		}
+
+		remoteBlocklists, err := nextdnsClient.GetPrivacyBlocklists(ctx, profileID)
+		if err != nil {
+			logger.Info("Skipping profile for catalog", "profileID", profileID, "error", err.Error())
+			continue
+		}
+		remoteNatives, err := nextdnsClient.GetPrivacyNatives(ctx, profileID)
+		if err != nil {
+			logger.Info("Skipping profile for catalog", "profileID", profileID, "error", err.Error())
+			continue
+		}
+		remoteCategories, err := nextdnsClient.GetParentalControlCategories(ctx, profileID)
+		if err != nil {
+			logger.Info("Skipping profile for catalog", "profileID", profileID, "error", err.Error())
+			continue
+		}
+		read[profileID] = true
+
+		for _, b := range remoteBlocklists {
+			entry := catalogEntry(blocklists, b.ID)
+			if b.Name != "" {
+				entry.Name = b.Name
+			}
+			if b.Entries > 0 {
+				entry.Entries = b.Entries
+			}
+		}
+		for _, n := range remoteNatives {
+			catalogEntry(natives, n.ID)
+		}
+		for _, c := range remoteCategories {
+			if c.Active {
+				catalogEntry(categories, c.ID)
+			}
+		}
+	}
+
+	data := map[string]string{}
+	for key, entries := range map[string]map[string]*CatalogEntry{
+		CatalogBlocklistsKey: blocklists,
+		CatalogNativesKey:    natives,
+		CatalogCategoriesKey: categories,
+	} {
+		encoded, err := json.MarshalIndent(sortedCatalog(entries), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal catalog: %w", err)
+		}
+		data[key] = string(encoded) + "\n"
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: CatalogConfigMapName, Namespace: p.Namespace},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, p.Client, configMap, func() error {
+		p.ResourceLabels.set(configMap)
+		if configMap.Annotations == nil {
+			configMap.Annotations = map[string]string{}
+		}
+		configMap.Annotations[AnnotationCatalogUpdated] = time.Now().UTC().Format(time.RFC3339)
+		configMap.Data = data
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write catalog ConfigMap: %w", err)
+	}
+
+	logger.V(1).Info("Published NextDNS catalog", "profiles", len(read),
+		"blocklists", len(blocklists), "natives", len(natives), "categories", len(categories))
+	return nil
+}
+
+// catalogEntry returns the entry for id, adding it if needed, and counts
+// one more profile using it
+func catalogEntry(entries map[string]*CatalogEntry, id string) *CatalogEntry {
+	entry, ok := entries[id]
+	if !ok {
+		entry = &CatalogEntry{ID: id}
+		entries[id] = entry
+	}
+	entry.Profiles++
+	return entry
+}
+
+// sortedCatalog returns the entries ordered by ID
+func sortedCatalog(entries map[string]*CatalogEntry) []CatalogEntry {
+	result := make([]CatalogEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestCatalogPublisher_Publish(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	newProfile := func(name, profileID string) *nextdnsv1alpha1.NextDNSProfile {
+		return &nextdnsv1alpha1.NextDNSProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			},
+			Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: profileID},
+		}
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.PrivacyBlocklists["home1"] = []*sdknextdns.PrivacyBlocklists{
+		{ID: "oisd", Name: "OISD", Entries: 200000},
+		{ID: "nextdns-recommended", Name: "NextDNS Ads & Trackers Blocklist", Entries: 100000},
+	}
+	mockNDS.PrivacyBlocklists["kids2"] = []*sdknextdns.PrivacyBlocklists{{ID: "oisd"}}
+	mockNDS.PrivacyNatives["home1"] = []*sdknextdns.PrivacyNatives{{ID: "apple"}}
+	mockNDS.ParentalControlCategories["kids2"] = []*sdknextdns.ParentalControlCategories{
+		{ID: "gambling", Active: true},
+		{ID: "dating", Active: false},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newProfile("home", "home1"), newProfile("kids", "kids2"), newProfile("pending", ""), secret).
+		Build()
+
+	publisher := &CatalogPublisher{
+		Client:         fakeClient,
+		Namespace:      "nextdns-system",
+		ResourceLabels: ResourceLabels{"team": "dns"},
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	require.NoError(t, publisher.Publish(ctx))
+
+	var cm corev1.ConfigMap
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: CatalogConfigMapName, Namespace: "nextdns-system"}, &cm))
+	assert.Equal(t, "dns", cm.Labels["team"])
+	assert.NotEmpty(t, cm.Annotations[AnnotationCatalogUpdated])
+
+	var blocklists []CatalogEntry
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[CatalogBlocklistsKey]), &blocklists))
+	assert.Equal(t, []CatalogEntry{
+		{ID: "nextdns-recommended", Name: "NextDNS Ads & Trackers Blocklist", Entries: 100000, Profiles: 1},
+		{ID: "oisd", Name: "OISD", Entries: 200000, Profiles: 2},
+	}, blocklists)
+
+	var natives, categories []CatalogEntry
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[CatalogNativesKey]), &natives))
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[CatalogCategoriesKey]), &categories))
+	assert.Equal(t, []CatalogEntry{{ID: "apple", Profiles: 1}}, natives)
+	assert.Equal(t, []CatalogEntry{{ID: "gambling", Profiles: 1}}, categories, "inactive categories are not listed")
+
+	// Republishing updates the existing ConfigMap
+	mockNDS.PrivacyBlocklists["kids2"] = nil
+	require.NoError(t, publisher.Publish(ctx))
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: CatalogConfigMapName, Namespace: "nextdns-system"}, &cm))
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[CatalogBlocklistsKey]), &blocklists))
+	assert.Equal(t, 1, blocklists[1].Profiles)
+}
@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// ConditionTypeClusterDNSIntegrated indicates the instance serves as
+	// cluster DNS as requested by spec.clusterDNSIntegration
+	ConditionTypeClusterDNSIntegrated = "ClusterDNSIntegrated"
+
+	// defaultClusterDNSServiceName is the Service kubeadm and most
+	// distributions create for cluster DNS
+	defaultClusterDNSServiceName = "kube-dns"
+
+	// AnnotationClusterDNSOwner records the NextDNSCoreDNS whose pods a
+	// cluster DNS Service currently selects
+	AnnotationClusterDNSOwner = "nextdns.io/cluster-dns-owner"
+
+	// AnnotationOriginalSelector holds the JSON selector of a cluster DNS
+	// Service before it was taken over, restored on release
+	AnnotationOriginalSelector = "nextdns.io/original-selector"
+
+	// reasonWaitingForReadyPods is the ClusterDNSIntegrated reason while the
+	// cluster DNS Service is left alone because no CoreDNS pod is ready
+	reasonWaitingForReadyPods = "WaitingForReadyPods"
+
+	// clusterDNSWaitInterval is how often a cluster DNS takeover waiting
+	// for ready pods is retried, besides the workload's own events
+	clusterDNSWaitInterval = 10 * time.Second
+)
+
+// clusterDNSMode returns the cluster DNS integration mode, None unless set
+func clusterDNSMode(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) nextdnsv1alpha1.ClusterDNSIntegrationMode {
+	if cfg := coreDNS.Spec.ClusterDNSIntegration; cfg != nil && cfg.Mode != "" {
+		return cfg.Mode
+	}
+	return nextdnsv1alpha1.ClusterDNSIntegrationNone
+}
+
+// clusterDNSServiceKey returns the cluster DNS Service taken over in
+// KubeDNSService mode. It is always in the NextDNSCoreDNS namespace, since a
+// Service selector cannot match pods of another namespace.
+func clusterDNSServiceKey(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) types.NamespacedName {
+	name := defaultClusterDNSServiceName
+	if cfg := coreDNS.Spec.ClusterDNSIntegration; cfg != nil && cfg.ServiceName != "" {
+		name = cfg.ServiceName
+	}
+	return types.NamespacedName{Name: name, Namespace: coreDNS.Namespace}
+}
+
+// clusterDNSOwner returns the owner annotation value for coreDNS
+func clusterDNSOwner(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	return coreDNS.Namespace + "/" + coreDNS.Name
+}
+
+// kubeletClusterDNSStatus returns the status publishing ip as the kubelet
+// clusterDNS setting
+func kubeletClusterDNSStatus(ip string) *nextdnsv1alpha1.ClusterDNSStatus {
+	status := &nextdnsv1alpha1.ClusterDNSStatus{
+		Mode:      nextdnsv1alpha1.ClusterDNSIntegrationKubelet,
+		ClusterIP: ip,
+	}
+	if ip != "" {
+		status.KubeletConfig = fmt.Sprintf("clusterDNS:\n- %s\n", ip)
+		status.KubeletFlag = "--cluster-dns=" + ip
+	}
+	return status
+}
+
+// reconcileClusterDNS applies spec.clusterDNSIntegration. In KubeDNSService
+// mode the cluster DNS Service selector is pointed at the CoreDNS pods, its
+// original selector kept in an annotation; in Kubelet mode the CoreDNS
+// Service ClusterIP is published for the kubelet --cluster-dns setting.
+// A Service taken over earlier is released when it is no longer wanted.
+func (r *NextDNSCoreDNSReconciler) reconcileClusterDNS(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+	mode := clusterDNSMode(coreDNS)
+
+	var keep types.NamespacedName
+	if mode == nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService && r.AllowClusterDNSIntegration {
+		keep = clusterDNSServiceKey(coreDNS)
+	}
+	if err := r.releaseClusterDNSService(ctx, coreDNS, keep); err != nil {
+		return err
+	}
+
+	switch mode {
+	case nextdnsv1alpha1.ClusterDNSIntegrationKubelet:
+		service := &corev1.Service{}
+		key := types.NamespacedName{Name: r.getServiceName(coreDNS, profile), Namespace: coreDNS.Namespace}
+		if err := r.Get(ctx, key, service); err != nil {
+			return fmt.Errorf("failed to get Service %s: %w", key, err)
+		}
+		coreDNS.Status.ClusterDNS = kubeletClusterDNSStatus(service.Spec.ClusterIP)
+		r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionTrue, "KubeletConfigPublished",
+			"Point the kubelet clusterDNS setting at the address in status.clusterDNS")
+		return nil
+
+	case nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService:
+		if !r.AllowClusterDNSIntegration {
+			coreDNS.Status.ClusterDNS = nil
+			r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionFalse, "NotAllowed",
+				"The operator was not started with --allow-cluster-dns-integration")
+			return nil
+		}
+		return r.takeOverClusterDNSService(ctx, coreDNS, profile)
+
+	default:
+		coreDNS.Status.ClusterDNS = nil
+		meta.RemoveStatusCondition(&coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+		if coreDNS.Spec.ClusterDNSIntegration != nil {
+			logger.V(1).Info("Cluster DNS integration disabled")
+		}
+		return nil
+	}
+}
+
+// clusterDNSRefreshAfter returns how long to wait before retrying a cluster
+// DNS takeover waiting for ready pods, or 0 when none is waiting
+func clusterDNSRefreshAfter(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) time.Duration {
+	cond := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+	if cond == nil || cond.Reason != reasonWaitingForReadyPods {
+		return 0
+	}
+	return clusterDNSWaitInterval
+}
+
+// readyCoreDNSPods returns the number of ready pods of the CoreDNS workload,
+// 0 when it does not exist yet
+func (r *NextDNSCoreDNSReconciler) readyCoreDNSPods(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) (int32, error) {
+	key := types.NamespacedName{Name: r.getResourceName(coreDNS, profile), Namespace: coreDNS.Namespace}
+	if d := coreDNS.Spec.Deployment; d != nil && d.Mode == nextdnsv1alpha1.DeploymentModeDaemonSet {
+		daemonSet := &appsv1.DaemonSet{}
+		if err := r.Get(ctx, key, daemonSet); err != nil {
+			return 0, client.IgnoreNotFound(err)
+		}
+		return daemonSet.Status.NumberReady, nil
+	}
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, deployment); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	return deployment.Status.ReadyReplicas, nil
+}
+
+// takeOverClusterDNSService points the cluster DNS Service selector at the
+// CoreDNS pods, recording the selector it replaces. Until a CoreDNS pod is
+// ready the Service keeps, or gets back, its original selector, so cluster
+// DNS survives a rollout or crashlooping pods.
+func (r *NextDNSCoreDNSReconciler) takeOverClusterDNSService(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+	key := clusterDNSServiceKey(coreDNS)
+
+	service := &corev1.Service{}
+	if err := r.Get(ctx, key, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			coreDNS.Status.ClusterDNS = nil
+			r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionFalse, "ServiceNotFound",
+				fmt.Sprintf("Cluster DNS Service %s not found; it must be in the NextDNSCoreDNS namespace", key))
+			return nil
+		}
+		return fmt.Errorf("failed to get cluster DNS Service %s: %w", key, err)
+	}
+
+	owner := service.Annotations[AnnotationClusterDNSOwner]
+	if owner != "" && owner != clusterDNSOwner(coreDNS) {
+		coreDNS.Status.ClusterDNS = nil
+		r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionFalse, "ServiceOwnedByOther",
+			fmt.Sprintf("Cluster DNS Service %s is already taken over by NextDNSCoreDNS %s", key, owner))
+		return nil
+	}
+
+	ready, err := r.readyCoreDNSPods(ctx, coreDNS, profile)
+	if err != nil {
+		return fmt.Errorf("failed to get the ready CoreDNS pods: %w", err)
+	}
+	if ready < 1 {
+		if err := r.releaseClusterDNSService(ctx, coreDNS, types.NamespacedName{}); err != nil {
+			return err
+		}
+		r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionFalse, reasonWaitingForReadyPods,
+			fmt.Sprintf("Cluster DNS Service %s is taken over once a CoreDNS pod is ready", key))
+		return nil
+	}
+
+	selector := r.buildLabels(coreDNS, profile)
+	if owner == "" || !maps.Equal(service.Spec.Selector, selector) {
+		if owner == "" {
+			original, err := json.Marshal(service.Spec.Selector)
+			if err != nil {
+				return fmt.Errorf("failed to record selector of Service %s: %w", key, err)
+			}
+			if service.Annotations == nil {
+				service.Annotations = make(map[string]string)
+			}
+			service.Annotations[AnnotationClusterDNSOwner] = clusterDNSOwner(coreDNS)
+			service.Annotations[AnnotationOriginalSelector] = string(original)
+		}
+		service.Spec.Selector = selector
+		if err := r.Update(ctx, service); err != nil {
+			return fmt.Errorf("failed to take over cluster DNS Service %s: %w", key, err)
+		}
+		logger.Info("Cluster DNS Service now selects the CoreDNS pods", "service", key.String())
+	}
+
+	coreDNS.Status.ClusterDNS = &nextdnsv1alpha1.ClusterDNSStatus{
+		Mode:      nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService,
+		Service:   key.String(),
+		ClusterIP: service.Spec.ClusterIP,
+	}
+	r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionTrue, "KubeDNSServiceTakenOver",
+		fmt.Sprintf("Cluster DNS Service %s selects the CoreDNS pods", key))
+	return nil
+}
+
+// releaseClusterDNSService restores the original selector of every cluster
+// DNS Service coreDNS took over, unless it is keep. The Services are found
+// by their owner annotation as well as status, so a Service is released
+// even when the status recording it was lost.
+func (r *NextDNSCoreDNSReconciler) releaseClusterDNSService(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, keep types.NamespacedName) error {
+	keys, err := r.clusterDNSServicesOwned(ctx, coreDNS)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == keep {
+			continue
+		}
+		if err := r.restoreClusterDNSService(ctx, coreDNS, key); err != nil {
+			return err
+		}
+	}
+
+	if status := coreDNS.Status.ClusterDNS; status != nil && status.Service != "" && status.Service != keep.String() {
+		coreDNS.Status.ClusterDNS = nil
+	}
+	return nil
+}
+
+// clusterDNSServicesOwned returns the cluster DNS Service recorded in the
+// status of coreDNS and the Services in its namespace annotated as taken
+// over by it
+func (r *NextDNSCoreDNSReconciler) clusterDNSServicesOwned(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]types.NamespacedName, error) {
+	var keys []types.NamespacedName
+	if status := coreDNS.Status.ClusterDNS; status != nil {
+		if namespace, name, ok := strings.Cut(status.Service, "/"); ok {
+			keys = append(keys, types.NamespacedName{Name: name, Namespace: namespace})
+		}
+	}
+
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(coreDNS.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Services in namespace %s: %w", coreDNS.Namespace, err)
+	}
+	for _, service := range services.Items {
+		key := client.ObjectKeyFromObject(&service)
+		if service.Annotations[AnnotationClusterDNSOwner] == clusterDNSOwner(coreDNS) && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// restoreClusterDNSService restores the original selector of the Service
+// key when coreDNS took it over
+func (r *NextDNSCoreDNSReconciler) restoreClusterDNSService(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, key types.NamespacedName) error {
+	service := &corev1.Service{}
+	if err := r.Get(ctx, key, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get cluster DNS Service %s: %w", key, err)
+	}
+	if service.Annotations[AnnotationClusterDNSOwner] != clusterDNSOwner(coreDNS) {
+		return nil
+	}
+
+	var selector map[string]string
+	if err := json.Unmarshal([]byte(service.Annotations[AnnotationOriginalSelector]), &selector); err != nil {
+		return fmt.Errorf("failed to read original selector of Service %s: %w", key, err)
+	}
+	service.Spec.Selector = selector
+	delete(service.Annotations, AnnotationClusterDNSOwner)
+	delete(service.Annotations, AnnotationOriginalSelector)
+	if err := r.Update(ctx, service); err != nil {
+		return fmt.Errorf("failed to release cluster DNS Service %s: %w", key, err)
+	}
+	log.FromContext(ctx).Info("Restored the original selector of the cluster DNS Service", "service", key.String())
+	return nil
+}
@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newClusterDNSTestObjects(mode nextdnsv1alpha1.ClusterDNSIntegrationMode) (*nextdnsv1alpha1.NextDNSProfile, *nextdnsv1alpha1.NextDNSCoreDNS, *corev1.Service) {
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "kube-system"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cluster-dns",
+			Namespace:  "kube-system",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef:            nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			ClusterDNSIntegration: &nextdnsv1alpha1.ClusterDNSIntegrationConfig{Mode: mode},
+		},
+	}
+	kubeDNS := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.96.0.10",
+			Selector:  map[string]string{"k8s-app": "kube-dns"},
+		},
+	}
+	return profile, coreDNS, kubeDNS
+}
+
+func TestReconcile_ClusterDNSIntegration_KubeDNSService(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+	profile, coreDNS, kubeDNS := newClusterDNSTestObjects(nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS, kubeDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme, AllowClusterDNSIntegration: true}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster-dns", Namespace: "kube-system"}}
+	serviceKey := types.NamespacedName{Name: "kube-dns", Namespace: "kube-system"}
+	deploymentKey := types.NamespacedName{Name: "cluster-dns-abc123-coredns", Namespace: "kube-system"}
+
+	// The Service keeps its pods until a CoreDNS pod is ready
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, clusterDNSWaitInterval, result.RequeueAfter)
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, serviceKey, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+	assert.Empty(t, service.Annotations)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	condition := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonWaitingForReadyPods, condition.Reason)
+	assert.Nil(t, coreDNS.Status.ClusterDNS)
+
+	setReadyReplicas(ctx, t, fakeClient, deploymentKey, 1)
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, serviceKey, service))
+	assert.Equal(t, "cluster-dns", service.Spec.Selector["app.kubernetes.io/instance"])
+	assert.Equal(t, "kube-system/cluster-dns", service.Annotations[AnnotationClusterDNSOwner])
+	assert.JSONEq(t, `{"k8s-app":"kube-dns"}`, service.Annotations[AnnotationOriginalSelector])
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	assert.Equal(t, &nextdnsv1alpha1.ClusterDNSStatus{
+		Mode:      nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService,
+		Service:   "kube-system/kube-dns",
+		ClusterIP: "10.96.0.10",
+	}, coreDNS.Status.ClusterDNS)
+	condition = meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// Losing every ready pod, e.g. to a crashloop, hands the Service back
+	setReadyReplicas(ctx, t, fakeClient, deploymentKey, 0)
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, serviceKey, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+	assert.Empty(t, service.Annotations)
+
+	setReadyReplicas(ctx, t, fakeClient, deploymentKey, 1)
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, serviceKey, service))
+	assert.Equal(t, "cluster-dns", service.Spec.Selector["app.kubernetes.io/instance"])
+
+	// Turning the integration off restores the original selector
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	coreDNS.Spec.ClusterDNSIntegration = nil
+	require.NoError(t, fakeClient.Update(ctx, coreDNS))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, serviceKey, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+	assert.NotContains(t, service.Annotations, AnnotationClusterDNSOwner)
+	assert.NotContains(t, service.Annotations, AnnotationOriginalSelector)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	assert.Nil(t, coreDNS.Status.ClusterDNS)
+	assert.Nil(t, meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated))
+}
+
+func TestReconcile_ClusterDNSIntegration_NotAllowed(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+	profile, coreDNS, kubeDNS := newClusterDNSTestObjects(nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS, kubeDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster-dns", Namespace: "kube-system"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "kube-dns", Namespace: "kube-system"}, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	condition := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "NotAllowed", condition.Reason)
+}
+
+func TestReconcile_ClusterDNSIntegration_OwnedByOther(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+	profile, coreDNS, kubeDNS := newClusterDNSTestObjects(nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService)
+	kubeDNS.Annotations = map[string]string{
+		AnnotationClusterDNSOwner:  "kube-system/other-dns",
+		AnnotationOriginalSelector: `{"k8s-app":"kube-dns"}`,
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS, kubeDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme, AllowClusterDNSIntegration: true}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster-dns", Namespace: "kube-system"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	condition := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeClusterDNSIntegrated)
+	require.NotNil(t, condition)
+	assert.Equal(t, "ServiceOwnedByOther", condition.Reason)
+	assert.Nil(t, coreDNS.Status.ClusterDNS)
+}
+
+func TestHandleDeletion_ReleasesClusterDNSService(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+	_, coreDNS, kubeDNS := newClusterDNSTestObjects(nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService)
+	kubeDNS.Spec.Selector = map[string]string{"app.kubernetes.io/instance": "cluster-dns"}
+	kubeDNS.Annotations = map[string]string{
+		AnnotationClusterDNSOwner:  "kube-system/cluster-dns",
+		AnnotationOriginalSelector: `{"k8s-app":"kube-dns"}`,
+	}
+	coreDNS.Status.ClusterDNS = &nextdnsv1alpha1.ClusterDNSStatus{
+		Mode:    nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService,
+		Service: "kube-system/kube-dns",
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(kubeDNS).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	require.NoError(t, r.releaseClusterDNSService(ctx, coreDNS, types.NamespacedName{}))
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "kube-dns", Namespace: "kube-system"}, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+	assert.Empty(t, service.Annotations)
+	assert.Nil(t, coreDNS.Status.ClusterDNS)
+}
+
+func TestHandleDeletion_ReleasesClusterDNSService_WithoutStatus(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+	_, coreDNS, kubeDNS := newClusterDNSTestObjects(nextdnsv1alpha1.ClusterDNSIntegrationKubeDNSService)
+	kubeDNS.Spec.Selector = map[string]string{"app.kubernetes.io/instance": "cluster-dns"}
+	kubeDNS.Annotations = map[string]string{
+		AnnotationClusterDNSOwner:  "kube-system/cluster-dns",
+		AnnotationOriginalSelector: `{"k8s-app":"kube-dns"}`,
+	}
+	// Taken over by another instance; left alone
+	other := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-dns",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				AnnotationClusterDNSOwner:  "kube-system/other-dns",
+				AnnotationOriginalSelector: `{"k8s-app":"other"}`,
+			},
+		},
+		Spec: corev1.ServiceSpec{Selector: map[string]string{"app.kubernetes.io/instance": "other-dns"}},
+	}
+	// The status recording the takeover was never written
+	now := metav1.Now()
+	coreDNS.DeletionTimestamp = &now
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(coreDNS, kubeDNS, other).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	_, err := r.handleDeletion(ctx, coreDNS)
+	require.NoError(t, err)
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "kube-dns", Namespace: "kube-system"}, service))
+	assert.Equal(t, map[string]string{"k8s-app": "kube-dns"}, service.Spec.Selector)
+	assert.Empty(t, service.Annotations)
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "other-dns", Namespace: "kube-system"}, service))
+	assert.Equal(t, map[string]string{"app.kubernetes.io/instance": "other-dns"}, service.Spec.Selector)
+	assert.Equal(t, "kube-system/other-dns", service.Annotations[AnnotationClusterDNSOwner])
+}
+
+// setReadyReplicas sets the ready replica count of the Deployment key
+func setReadyReplicas(ctx context.Context, t *testing.T, c client.Client, key types.NamespacedName, ready int32) {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, c.Get(ctx, key, deployment))
+	deployment.Status.ReadyReplicas = ready
+	require.NoError(t, c.Status().Update(ctx, deployment))
+}
+
+func TestKubeletClusterDNSStatus(t *testing.T) {
+	assert.Equal(t, &nextdnsv1alpha1.ClusterDNSStatus{
+		Mode:          nextdnsv1alpha1.ClusterDNSIntegrationKubelet,
+		ClusterIP:     "10.96.120.15",
+		KubeletConfig: "clusterDNS:\n- 10.96.120.15\n",
+		KubeletFlag:   "--cluster-dns=10.96.120.15",
+	}, kubeletClusterDNSStatus("10.96.120.15"))
+
+	// Nothing to publish before the ClusterIP is allocated
+	assert.Equal(t, &nextdnsv1alpha1.ClusterDNSStatus{Mode: nextdnsv1alpha1.ClusterDNSIntegrationKubelet},
+		kubeletClusterDNSStatus(""))
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+)
+
+const (
+	// ClusterAllowlistFinalizerName is the finalizer added to ClusterNextDNSAllowlist resources
+	ClusterAllowlistFinalizerName = "nextdns.io/clusterallowlist-finalizer"
+)
+
+// ClusterNextDNSAllowlistReconciler reconciles a ClusterNextDNSAllowlist object
+type ClusterNextDNSAllowlistReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	SyncPeriod time.Duration
+
+	// ListSources caches fetched spec.sources; shared with the profile reconciler
+	ListSources *listsource.Cache
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=clusternextdnsallowlists,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=clusternextdnsallowlists/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=clusternextdnsallowlists/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *ClusterNextDNSAllowlistReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the cluster allowlist
+	var list nextdnsv1alpha1.ClusterNextDNSAllowlist
+	if err := r.Get(ctx, req.NamespacedName, &list); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, &list, &list.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Handle deletion
+	if !list.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, &list)
+	}
+
+	// Add finalizer if not present
+	if !controllerutil.ContainsFinalizer(&list, ClusterAllowlistFinalizerName) {
+		logger.Info("Adding finalizer to ClusterNextDNSAllowlist")
+		controllerutil.AddFinalizer(&list, ClusterAllowlistFinalizerName)
+		if err := r.Update(ctx, &list); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	// Count active domains, including those fetched from sources
+	fetched := fetchListSources(ctx, r.ListSources, list.Spec.Sources, resyncRequested(&list))
+	if fetched.err != nil {
+		logger.Error(fetched.err, "Failed to fetch list sources")
+	}
+	count := appendSourcedDomains(resolveDomainList(list.Spec.Domains), fetched.entries).count
+
+	// Find profile references
+	profileRefs, err := r.findProfileReferences(ctx, &list)
+	if err != nil {
+		logger.Error(err, "Failed to find profile references")
+		return ctrl.Result{}, err
+	}
+
+	// Update status
+	list.Status.DomainCount = count
+	list.Status.Sources = fetched.status
+	list.Status.ProfileRefs = profileRefs
+
+	// Set conditions
+	setListConditions(&list.Status.Conditions, count, len(profileRefs), "domains")
+	setSourcesCondition(r.Recorder, &list, &list.Status.Conditions, fetched, len(list.Spec.Sources))
+
+	// Update status subresource
+	if err := r.Status().Update(ctx, &list); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &list); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	// Schedule next sync with jitter for drift detection
+	syncInterval := CalculateSyncInterval(r.SyncPeriod)
+	return ctrl.Result{RequeueAfter: fetched.requeueAfter(syncInterval)}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterNextDNSAllowlistReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.ClusterNextDNSAllowlist{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.findClusterAllowlistsForProfile),
+		).
+		WithOptions(controllerOptions("clusternextdnsallowlist", r.Workqueue)).
+		Complete(r)
+}
+
+// findClusterAllowlistsForProfile returns reconcile requests for all cluster allowlists referenced by a profile
+func (r *ClusterNextDNSAllowlistReconciler) findClusterAllowlistsForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ref := range allListRefs(&profile.Spec, clusterAllowlistRefs) {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ref.Name},
+		})
+	}
+
+	return requests
+}
+
+// findProfileReferences finds all profiles in any namespace that reference this cluster allowlist
+func (r *ClusterNextDNSAllowlistReconciler) findProfileReferences(ctx context.Context, list *nextdnsv1alpha1.ClusterNextDNSAllowlist) ([]nextdnsv1alpha1.ResourceReference, error) {
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &profiles); err != nil {
+		return nil, err
+	}
+
+	return findRefsForList(profiles.Items, list.Name, "", clusterAllowlistRefs), nil
+}
+
+// handleDeletion handles the deletion of a cluster allowlist
+func (r *ClusterNextDNSAllowlistReconciler) handleDeletion(ctx context.Context, list *nextdnsv1alpha1.ClusterNextDNSAllowlist) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Check if any profiles reference this list
+	if len(list.Status.ProfileRefs) > 0 {
+		logger.Info("Deletion blocked - list is in use", "profileRefs", list.Status.ProfileRefs)
+
+		setDeletionBlockedCondition(r.Recorder, list, &list.Status.Conditions, list.Status.ProfileRefs)
+
+		// Update status and requeue
+		if err := r.Status().Update(ctx, list); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// No references - safe to delete
+	logger.Info("Removing finalizer from ClusterNextDNSAllowlist")
+	controllerutil.RemoveFinalizer(list, ClusterAllowlistFinalizerName)
+	if err := r.Update(ctx, list); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
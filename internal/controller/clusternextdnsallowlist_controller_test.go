@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestClusterNextDNSAllowlistReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	list := &nextdnsv1alpha1.ClusterNextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "corporate",
+			Finalizers: []string{ClusterAllowlistFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSAllowlistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{
+				{Domain: "example.com"},
+				{Domain: "disabled.example.com", Active: ptr.To(false)},
+			},
+		},
+	}
+	profiles := []*nextdnsv1alpha1.NextDNSProfile{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				AllowlistRefs: []nextdnsv1alpha1.ListReference{
+					{Name: "corporate", Kind: nextdnsv1alpha1.KindClusterAllowlist},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-b", Namespace: "team-b"},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				// The namespace is ignored for cluster-scoped lists
+				AllowlistRefs: []nextdnsv1alpha1.ListReference{
+					{Name: "corporate", Namespace: "other", Kind: nextdnsv1alpha1.KindClusterAllowlist},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "namespaced", Namespace: "team-c"},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "corporate"}},
+			},
+		},
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(list).WithStatusSubresource(list)
+	for _, profile := range profiles {
+		builder = builder.WithObjects(profile)
+	}
+	fakeClient := builder.Build()
+	r := &ClusterNextDNSAllowlistReconciler{Client: fakeClient, Scheme: scheme, SyncPeriod: time.Hour}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "corporate"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.ClusterNextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, 1, updated.Status.DomainCount)
+	assert.ElementsMatch(t, []nextdnsv1alpha1.ResourceReference{
+		{Name: "team-a", Namespace: "team-a"},
+		{Name: "team-b", Namespace: "team-b"},
+	}, updated.Status.ProfileRefs, "references without the cluster kind name a namespaced allowlist")
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, "InUse"))
+
+	requests := r.findClusterAllowlistsForProfile(ctx, profiles[1])
+	require.Len(t, requests, 1)
+	assert.Equal(t, types.NamespacedName{Name: "corporate"}, requests[0].NamespacedName)
+	assert.Empty(t, r.findClusterAllowlistsForProfile(ctx, profiles[2]))
+}
+
+func TestClusterNextDNSAllowlistReconciler_HandleDeletion(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	list := &nextdnsv1alpha1.ClusterNextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "corporate",
+			Finalizers:        []string{ClusterAllowlistFinalizerName},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Status: nextdnsv1alpha1.NextDNSAllowlistStatus{
+			ProfileRefs: []nextdnsv1alpha1.ResourceReference{{Name: "team-a", Namespace: "team-a"}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(list).WithStatusSubresource(list).Build()
+	r := &ClusterNextDNSAllowlistReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "corporate"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.ClusterNextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Contains(t, updated.Finalizers, ClusterAllowlistFinalizerName, "deletion is blocked while in use")
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, "DeletionBlocked"))
+}
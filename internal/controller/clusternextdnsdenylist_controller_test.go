@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestClusterNextDNSDenylistReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	list := &nextdnsv1alpha1.ClusterNextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "malware",
+			Finalizers: []string{ClusterDenylistFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "malware.example.com"}},
+		},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "malware", Kind: nextdnsv1alpha1.KindClusterDenylist},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list, profile).
+		WithStatusSubresource(list).
+		Build()
+	r := &ClusterNextDNSDenylistReconciler{Client: fakeClient, Scheme: scheme, SyncPeriod: time.Hour}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "malware"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.ClusterNextDNSDenylist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, 1, updated.Status.DomainCount)
+	assert.Equal(t, []nextdnsv1alpha1.ResourceReference{{Name: "team-a", Namespace: "team-a"}}, updated.Status.ProfileRefs)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, "InUse"))
+}
+
+func TestResolveListReferences_ClusterLists(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	allowlist := &nextdnsv1alpha1.ClusterNextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate"},
+		Spec: nextdnsv1alpha1.NextDNSAllowlistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "intranet.example.com"}},
+		},
+	}
+	denylist := &nextdnsv1alpha1.ClusterNextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "malware"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "malware.example.com"}},
+		},
+	}
+	// A namespaced denylist of the same name is not confused with the cluster one
+	namespaced := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "malware", Namespace: "team-a"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "team.example.com"}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(allowlist, denylist, namespaced).Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, listCache: newListCache()}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "team-a"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name: "profile",
+			AllowlistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "corporate", Kind: nextdnsv1alpha1.KindClusterAllowlist},
+			},
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "malware", Kind: nextdnsv1alpha1.KindClusterDenylist},
+				{Name: "malware"},
+			},
+		},
+	}
+
+	resolved, err := reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []nextdns.DomainEntry{{Domain: "intranet.example.com", Active: true}}, resolved.Allowlist)
+	assert.Equal(t, []nextdns.DomainEntry{
+		{Domain: "malware.example.com", Active: true},
+		{Domain: "team.example.com", Active: true},
+	}, resolved.Denylist)
+
+	require.Len(t, resolved.ResourceStatus.Denylists, 2)
+	assert.Equal(t, "", resolved.ResourceStatus.Denylists[0].Namespace)
+	assert.Equal(t, nextdnsv1alpha1.KindClusterDenylist, resolved.ResourceStatus.Denylists[0].Kind)
+	assert.Equal(t, "team-a", resolved.ResourceStatus.Denylists[1].Namespace)
+
+	// A missing cluster list is reported without a namespace
+	profile.Spec.DenylistRefs = []nextdnsv1alpha1.ListReference{{Name: "missing", Kind: nextdnsv1alpha1.KindClusterDenylist}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get cluster denylist missing:")
+	assert.Equal(t, ReasonReferenceNotFound, listReferenceErrorReason(err))
+
+	// allowlistRefs do not accept denylist kinds
+	profile.Spec.DenylistRefs = nil
+	profile.Spec.AllowlistRefs = []nextdnsv1alpha1.ListReference{{Name: "malware", Kind: nextdnsv1alpha1.KindClusterDenylist}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	assert.ErrorContains(t, err, "allowlistRefs cannot reference kind ClusterNextDNSDenylist")
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// AnnotationCompatibilityLevel pins the behavior version a controller applies
+// to a resource. It is an annotation rather than a spec field so older
+// operators and CRD schemas keep it across a rollback.
+const AnnotationCompatibilityLevel = "nextdns.io/compatibility-level"
+
+// Compatibility levels. Each level keeps the behavior of the previous ones
+// except for the changes listed here, so releases that change behavior can be
+// rolled out per resource.
+const (
+	// CompatibilityLevel1 is the behavior of releases before compatibility
+	// levels were introduced
+	CompatibilityLevel1 = 1
+
+	// CompatibilityLevel2 syncs allowlists and denylists by merging: entries
+	// added outside the operator are kept as if preserveUnmanagedEntries
+	// was set
+	CompatibilityLevel2 = 2
+
+	// LatestCompatibilityLevel is the highest level this release implements
+	LatestCompatibilityLevel = CompatibilityLevel2
+
+	// DefaultCompatibilityLevel applies to resources without the annotation
+	// unless the operator is configured otherwise
+	DefaultCompatibilityLevel = CompatibilityLevel1
+)
+
+// ValidateCompatibilityLevel checks that level is implemented by this release
+func ValidateCompatibilityLevel(level int) error {
+	if level < CompatibilityLevel1 || level > LatestCompatibilityLevel {
+		return fmt.Errorf("compatibility level %d is not between %d and %d", level, CompatibilityLevel1, LatestCompatibilityLevel)
+	}
+	return nil
+}
+
+// resourceCompatibilityLevel returns the compatibility level of obj: the
+// nextdns.io/compatibility-level annotation if set, otherwise defaultLevel
+// (DefaultCompatibilityLevel if 0). The returned level is always usable; the
+// error reports an annotation that was invalid (defaultLevel is used).
+func resourceCompatibilityLevel(obj client.Object, defaultLevel int) (int, error) {
+	if defaultLevel == 0 {
+		defaultLevel = DefaultCompatibilityLevel
+	}
+
+	value, ok := obj.GetAnnotations()[AnnotationCompatibilityLevel]
+	if !ok {
+		return defaultLevel, nil
+	}
+
+	level, err := strconv.Atoi(value)
+	if err == nil {
+		err = ValidateCompatibilityLevel(level)
+	}
+	if err != nil {
+		return defaultLevel, fmt.Errorf("invalid %s %q, using compatibility level %d", AnnotationCompatibilityLevel, value, defaultLevel)
+	}
+	return level, nil
+}
+
+// applyCompatibilityLevel adjusts the in-memory effective spec of a profile
+// to the behavior of level
+func applyCompatibilityLevel(spec *nextdnsv1alpha1.NextDNSProfileSpec, level int) {
+	if level >= CompatibilityLevel2 {
+		spec.PreserveUnmanagedEntries = true
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestResourceCompatibilityLevel(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotation   *string
+		defaultLevel int
+		want         int
+		wantErr      bool
+	}{
+		{name: "no annotation uses the built-in default", want: DefaultCompatibilityLevel},
+		{name: "no annotation uses the operator default", defaultLevel: 2, want: 2},
+		{name: "annotation wins", annotation: ptr.To("2"), want: 2},
+		{name: "annotation pins an older level", annotation: ptr.To("1"), defaultLevel: 2, want: 1},
+		{name: "unknown level", annotation: ptr.To("99"), defaultLevel: 1, want: 1, wantErr: true},
+		{name: "not a number", annotation: ptr.To("latest"), want: DefaultCompatibilityLevel, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &nextdnsv1alpha1.NextDNSProfile{}
+			if tt.annotation != nil {
+				profile.Annotations = map[string]string{AnnotationCompatibilityLevel: *tt.annotation}
+			}
+			level, err := resourceCompatibilityLevel(profile, tt.defaultLevel)
+			assert.Equal(t, tt.want, level)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateCompatibilityLevel(t *testing.T) {
+	assert.NoError(t, ValidateCompatibilityLevel(CompatibilityLevel1))
+	assert.NoError(t, ValidateCompatibilityLevel(LatestCompatibilityLevel))
+	assert.Error(t, ValidateCompatibilityLevel(0))
+	assert.Error(t, ValidateCompatibilityLevel(LatestCompatibilityLevel+1))
+}
+
+func TestReconcile_CompatibilityLevel2MergesLists(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "merge-profile",
+			Namespace:   "default",
+			Finalizers:  []string{FinalizerName},
+			Annotations: map[string]string{AnnotationCompatibilityLevel: "2"},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Merge Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Denylist:       []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com", Active: ptr.To(true)}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Merge Profile", "abc123.dns.nextdns.io")
+	require.NoError(t, mockNDS.AddDenylistEntry(ctx, "abc123", "manual.example.com", true))
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "merge-profile", Namespace: "default"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	denylist, err := mockNDS.GetDenylist(ctx, "abc123")
+	require.NoError(t, err)
+	var domains []string
+	for _, e := range denylist {
+		domains = append(domains, e.ID)
+	}
+	assert.ElementsMatch(t, []string{"manual.example.com", "ads.example.com"}, domains,
+		"level 2 keeps entries added outside the operator")
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, 2, updated.Status.CompatibilityLevel)
+	assert.False(t, updated.Spec.PreserveUnmanagedEntries, "the level is not written back to the spec")
+	require.NotNil(t, updated.Status.ManagedEntries)
+	assert.Equal(t, []string{"ads.example.com"}, updated.Status.ManagedEntries.Denylist)
+}
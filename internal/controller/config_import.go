@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// importPending reports whether the remote configuration must be imported
+// into the spec before the first sync
+func importPending(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	policy := profile.Spec.ImportPolicy
+	return profile.Spec.ProfileID != "" &&
+		(policy == nextdnsv1alpha1.ImportPolicyMergeOnAdopt || policy == nextdnsv1alpha1.ImportPolicyOverwrite) &&
+		profile.Status.ImportedAt == nil &&
+		firstManagedSync(profile)
+}
+
+// mergeIntoSpec materializes an imported configuration in spec. With
+// overwrite the imported sections and inline lists replace those of the
+// spec; otherwise only fields and list entries the spec does not set are
+// taken from the import.
+func mergeIntoSpec(spec *nextdnsv1alpha1.NextDNSProfileSpec, imported *nextdnsv1alpha1.SuggestedSpec, overwrite bool) error {
+	if imported == nil {
+		return nil
+	}
+
+	if overwrite {
+		spec.Security = imported.Security
+		spec.Privacy = imported.Privacy
+		spec.ParentalControl = imported.ParentalControl
+		spec.Settings = imported.Settings
+		spec.Denylist = imported.Denylist
+		spec.Allowlist = imported.Allowlist
+		spec.Rewrites = imported.Rewrites
+		return nil
+	}
+
+	if err := fillUnset(&spec.Security, imported.Security); err != nil {
+		return fmt.Errorf("failed to merge security: %w", err)
+	}
+	if err := fillUnset(&spec.Privacy, imported.Privacy); err != nil {
+		return fmt.Errorf("failed to merge privacy: %w", err)
+	}
+	if err := fillUnset(&spec.ParentalControl, imported.ParentalControl); err != nil {
+		return fmt.Errorf("failed to merge parental control: %w", err)
+	}
+	if err := fillUnset(&spec.Settings, imported.Settings); err != nil {
+		return fmt.Errorf("failed to merge settings: %w", err)
+	}
+
+	spec.Denylist = mergeDomainEntries(spec.Denylist, imported.Denylist)
+	spec.Allowlist = mergeDomainEntries(spec.Allowlist, imported.Allowlist)
+
+	seen := make(map[string]bool, len(spec.Rewrites))
+	for _, rw := range spec.Rewrites {
+		seen[rw.From] = true
+	}
+	for _, rw := range imported.Rewrites {
+		if !seen[rw.From] {
+			seen[rw.From] = true
+			spec.Rewrites = append(spec.Rewrites, rw)
+		}
+	}
+
+	return nil
+}
+
+// mergeDomainEntries returns entries followed by the imported entries for
+// domains it does not contain
+func mergeDomainEntries(entries, imported []nextdnsv1alpha1.DomainEntry) []nextdnsv1alpha1.DomainEntry {
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.Domain] = true
+	}
+	for _, e := range imported {
+		if !seen[e.Domain] {
+			seen[e.Domain] = true
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// fillUnset sets the fields of *dst that are not set from src, recursing
+// into nested objects. Lists set in *dst are kept as they are.
+func fillUnset[T any](dst **T, src *T) error {
+	if src == nil {
+		return nil
+	}
+	if *dst == nil {
+		*dst = src
+		return nil
+	}
+
+	dstFields, err := toFieldMap(*dst)
+	if err != nil {
+		return err
+	}
+	srcFields, err := toFieldMap(src)
+	if err != nil {
+		return err
+	}
+	fillFields(dstFields, srcFields)
+
+	data, err := json.Marshal(dstFields)
+	if err != nil {
+		return err
+	}
+	merged := new(T)
+	if err := json.Unmarshal(data, merged); err != nil {
+		return err
+	}
+	*dst = merged
+	return nil
+}
+
+// toFieldMap returns the JSON fields of v
+func toFieldMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// fillFields copies the keys of src missing from dst, recursing into
+// objects present in both
+func fillFields(dst, src map[string]any) {
+	for key, srcValue := range src {
+		dstValue, ok := dst[key]
+		if !ok {
+			dst[key] = srcValue
+			continue
+		}
+		dstObject, dstIsObject := dstValue.(map[string]any)
+		srcObject, srcIsObject := srcValue.(map[string]any)
+		if dstIsObject && srcIsObject {
+			fillFields(dstObject, srcObject)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func importedConfig() *nextdnsv1alpha1.SuggestedSpec {
+	return &nextdnsv1alpha1.SuggestedSpec{
+		Security: &nextdnsv1alpha1.SecuritySpec{
+			AIThreatDetection: ptr.To(true),
+			Cryptojacking:     ptr.To(true),
+		},
+		Settings: &nextdnsv1alpha1.SettingsSpec{
+			Web3: ptr.To(true),
+		},
+		Denylist: []nextdnsv1alpha1.DomainEntry{
+			{Domain: "ads.example.com", Active: ptr.To(true)},
+			{Domain: "manual.example.com", Active: ptr.To(true)},
+		},
+		Rewrites: []nextdnsv1alpha1.RewriteEntry{
+			{From: "printer.home", To: "192.168.1.20"},
+		},
+	}
+}
+
+func TestMergeIntoSpec_Merge(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Security: &nextdnsv1alpha1.SecuritySpec{Cryptojacking: ptr.To(false)},
+		Denylist: []nextdnsv1alpha1.DomainEntry{
+			{Domain: "ads.example.com", Active: ptr.To(false)},
+		},
+		Rewrites: []nextdnsv1alpha1.RewriteEntry{
+			{From: "nas.home", To: "192.168.1.10"},
+		},
+	}
+
+	require.NoError(t, mergeIntoSpec(spec, importedConfig(), false))
+
+	require.NotNil(t, spec.Security)
+	assert.Equal(t, ptr.To(false), spec.Security.Cryptojacking, "fields set in the spec are kept")
+	assert.Equal(t, ptr.To(true), spec.Security.AIThreatDetection, "unset fields are imported")
+	require.NotNil(t, spec.Settings)
+	assert.Equal(t, ptr.To(true), spec.Settings.Web3)
+	assert.Equal(t, []nextdnsv1alpha1.DomainEntry{
+		{Domain: "ads.example.com", Active: ptr.To(false)},
+		{Domain: "manual.example.com", Active: ptr.To(true)},
+	}, spec.Denylist)
+	assert.Equal(t, []nextdnsv1alpha1.RewriteEntry{
+		{From: "nas.home", To: "192.168.1.10"},
+		{From: "printer.home", To: "192.168.1.20"},
+	}, spec.Rewrites)
+}
+
+func TestMergeIntoSpec_Overwrite(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Name:      "Adopted",
+		Security:  &nextdnsv1alpha1.SecuritySpec{Cryptojacking: ptr.To(false)},
+		Privacy:   &nextdnsv1alpha1.PrivacySpec{DisguisedTrackers: ptr.To(true)},
+		Allowlist: []nextdnsv1alpha1.DomainEntry{{Domain: "good.example.com"}},
+	}
+
+	imported := importedConfig()
+	require.NoError(t, mergeIntoSpec(spec, imported, true))
+
+	assert.Equal(t, "Adopted", spec.Name)
+	assert.Equal(t, imported.Security, spec.Security)
+	assert.Nil(t, spec.Privacy, "sections missing from the import are cleared")
+	assert.Nil(t, spec.Allowlist)
+	assert.Equal(t, imported.Denylist, spec.Denylist)
+	assert.Equal(t, imported.Rewrites, spec.Rewrites)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// corednsContainerName is the name of the CoreDNS container
+	corednsContainerName = "coredns"
+
+	// setupInterfaceContainerName is the name of the node-local cache init
+	// container
+	setupInterfaceContainerName = "setup-interface"
+)
+
+// validateExtraContainers returns an error when the extra or init containers
+// reuse a name of the operator's containers or of each other, which the API
+// server would reject when the pod template is applied
+func validateExtraContainers(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) error {
+	if coreDNS.Spec.Deployment == nil {
+		return nil
+	}
+	seen := map[string]string{
+		corednsContainerName:        "reserved",
+		setupInterfaceContainerName: "reserved",
+	}
+	check := func(field string, containers []corev1.Container) error {
+		for i, container := range containers {
+			path := fmt.Sprintf("spec.deployment.%s[%d]", field, i)
+			if container.Name == "" {
+				return fmt.Errorf("%s: name is required", path)
+			}
+			if container.Image == "" {
+				return fmt.Errorf("%s: image is required", path)
+			}
+			if prev, ok := seen[container.Name]; ok {
+				if prev == "reserved" {
+					return fmt.Errorf("%s: container name %q is reserved", path, container.Name)
+				}
+				return fmt.Errorf("%s: container name %q is already used by %s", path, container.Name, prev)
+			}
+			seen[container.Name] = path
+		}
+		return nil
+	}
+	if err := check("initContainers", coreDNS.Spec.Deployment.InitContainers); err != nil {
+		return err
+	}
+	return check("extraContainers", coreDNS.Spec.Deployment.ExtraContainers)
+}
+
+// applyExtraContainers adds the extra containers after the CoreDNS container
+// and the init containers after the operator's init containers, so the
+// CoreDNS container stays first
+func applyExtraContainers(podSpec *corev1.PodSpec, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	if coreDNS.Spec.Deployment == nil {
+		return
+	}
+	for _, container := range coreDNS.Spec.Deployment.InitContainers {
+		podSpec.InitContainers = append(podSpec.InitContainers, *container.DeepCopy())
+	}
+	for _, container := range coreDNS.Spec.Deployment.ExtraContainers {
+		podSpec.Containers = append(podSpec.Containers, *container.DeepCopy())
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestValidateExtraContainers(t *testing.T) {
+	sidecar := corev1.Container{Name: "dnstap", Image: "dnstap:latest"}
+	warmer := corev1.Container{Name: "warm-certs", Image: "warmer:latest"}
+
+	tests := []struct {
+		name    string
+		extra   []corev1.Container
+		init    []corev1.Container
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", extra: []corev1.Container{sidecar}, init: []corev1.Container{warmer}},
+		{name: "reserved coredns", extra: []corev1.Container{{Name: "coredns", Image: "x"}},
+			wantErr: `spec.deployment.extraContainers[0]: container name "coredns" is reserved`},
+		{name: "reserved setup-interface", init: []corev1.Container{{Name: "setup-interface", Image: "x"}},
+			wantErr: `spec.deployment.initContainers[0]: container name "setup-interface" is reserved`},
+		{name: "duplicate across lists", extra: []corev1.Container{warmer}, init: []corev1.Container{warmer},
+			wantErr: `spec.deployment.extraContainers[0]: container name "warm-certs" is already used by spec.deployment.initContainers[0]`},
+		{name: "missing image", extra: []corev1.Container{sidecar, {Name: "other"}},
+			wantErr: "spec.deployment.extraContainers[1]: image is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+					Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{ExtraContainers: tt.extra, InitContainers: tt.init},
+				},
+			}
+			err := validateExtraContainers(coreDNS)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBuildPodSpec_ExtraContainers(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode: nextdnsv1alpha1.DeploymentModeDaemonSet,
+				ExtraContainers: []corev1.Container{
+					{Name: "dnstap", Image: "dnstap:latest", Args: []string{"-u", "/var/run/dnstap.sock"}},
+				},
+				InitContainers: []corev1.Container{
+					{Name: "warm-certs", Image: "warmer:latest"},
+				},
+				NodeLocal: &nextdnsv1alpha1.CoreDNSNodeLocalConfig{Enabled: true},
+			},
+		},
+	}
+
+	podSpec := r.buildPodSpec(coreDNS, "dns-abc123-coredns")
+	require.Len(t, podSpec.Containers, 2)
+	assert.Equal(t, "coredns", podSpec.Containers[0].Name, "the CoreDNS container stays first")
+	assert.Equal(t, "dnstap", podSpec.Containers[1].Name)
+	assert.Equal(t, []string{"-u", "/var/run/dnstap.sock"}, podSpec.Containers[1].Args)
+
+	require.Len(t, podSpec.InitContainers, 2)
+	assert.Equal(t, "setup-interface", podSpec.InitContainers[0].Name, "the node-local init container runs first")
+	assert.Equal(t, "warm-certs", podSpec.InitContainers[1].Name)
+
+	// The spec is copied, not shared with the pod template
+	podSpec.Containers[1].Args[0] = "-changed"
+	assert.Equal(t, "-u", coreDNS.Spec.Deployment.ExtraContainers[0].Args[0])
+}
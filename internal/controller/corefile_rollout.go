@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+// AnnotationCorefileHash records the hash of the Corefile on the pod
+// template, so pods restart to load a changed Corefile
+const AnnotationCorefileHash = "nextdns.io/corefile-hash"
+
+// generateCorefile returns the Corefile for coreDNS
+func (r *NextDNSCoreDNSReconciler) generateCorefile(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) (string, error) {
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	if err != nil {
+		return "", err
+	}
+	return coredns.GenerateCorefile(cfg), nil
+}
+
+// corefileHash returns the hash of a Corefile
+func corefileHash(corefile string) string {
+	sum := sha256.Sum256([]byte(corefile))
+	return hex.EncodeToString(sum[:])
+}
+
+// applyCorefileHash annotates a CoreDNS pod template with the Corefile hash
+func applyCorefileHash(template *corev1.PodTemplateSpec, hash string) {
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[AnnotationCorefileHash] = hash
+}
+
+// deploymentRolledOut reports whether every replica of deployment runs its
+// current pod template and is available
+func deploymentRolledOut(deployment *appsv1.Deployment, desired int32) bool {
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == desired &&
+		status.Replicas == desired &&
+		status.AvailableReplicas >= desired
+}
+
+// daemonSetRolledOut reports whether every pod of daemonSet runs its
+// current pod template and is available
+func daemonSetRolledOut(daemonSet *appsv1.DaemonSet) bool {
+	status := daemonSet.Status
+	return status.ObservedGeneration >= daemonSet.Generation &&
+		status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberAvailable == status.DesiredNumberScheduled
+}
+
+// recordCorefileApplied records a Corefile in status once a workload has
+// rolled out a pod template carrying it. currentHash is the hash of the
+// Corefile for the current spec; a template with another hash is still
+// waiting for this reconcile's update to be observed. Only a changed
+// Corefile updates the timestamp and generation.
+func recordCorefileApplied(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, template *corev1.PodTemplateSpec, currentHash string, rolledOut bool, now metav1.Time) {
+	hash := template.Annotations[AnnotationCorefileHash]
+	if !rolledOut || hash == "" || hash != currentHash || hash == coreDNS.Status.CorefileHash {
+		return
+	}
+	coreDNS.Status.CorefileHash = hash
+	coreDNS.Status.CorefileAppliedAt = &now
+	coreDNS.Status.CorefileGeneration = coreDNS.Generation
+}
+
+// recordWorkloadEvent emits an event when a CoreDNS workload was created or
+// an update changed its pod template, rolling out new pods. previous is the
+// pod template read before the update; nil for a new workload.
+func (r *NextDNSCoreDNSReconciler) recordWorkloadEvent(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, kind, name string, op controllerutil.OperationResult, previous, current *corev1.PodTemplateSpec) {
+	switch {
+	case op == controllerutil.OperationResultCreated:
+		recordEvent(r.Recorder, coreDNS, corev1.EventTypeNormal, EventReasonWorkloadCreated, "Created %s %s", kind, name)
+	case op == controllerutil.OperationResultUpdated && previous != nil && !apiequality.Semantic.DeepEqual(previous, current):
+		recordEvent(r.Recorder, coreDNS, corev1.EventTypeNormal, EventReasonRolloutStarted,
+			"Rolling out a new pod template to %s %s", kind, name)
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestDeploymentRolledOut(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	assert.True(t, deploymentRolledOut(deployment, 2))
+
+	deployment.Status.ObservedGeneration = 2
+	assert.False(t, deploymentRolledOut(deployment, 2), "the new template has not been observed")
+
+	deployment.Status.ObservedGeneration = 3
+	deployment.Status.Replicas = 3
+	assert.False(t, deploymentRolledOut(deployment, 2), "an old pod is still running")
+
+	deployment.Status.Replicas = 2
+	deployment.Status.AvailableReplicas = 1
+	assert.False(t, deploymentRolledOut(deployment, 2))
+}
+
+func TestDaemonSetRolledOut(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberAvailable:        3,
+		},
+	}
+	assert.True(t, daemonSetRolledOut(daemonSet))
+
+	daemonSet.Status.UpdatedNumberScheduled = 2
+	assert.False(t, daemonSetRolledOut(daemonSet))
+}
+
+func TestRecordCorefileApplied(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{ObjectMeta: metav1.ObjectMeta{Generation: 4}}
+	template := &corev1.PodTemplateSpec{}
+	applyCorefileHash(template, "hash-a")
+	applied := metav1.Now()
+
+	recordCorefileApplied(coreDNS, template, "hash-a", false, applied)
+	assert.Empty(t, coreDNS.Status.CorefileHash, "nothing is recorded during a rollout")
+
+	recordCorefileApplied(coreDNS, template, "hash-b", true, applied)
+	assert.Empty(t, coreDNS.Status.CorefileHash, "a stale template is not recorded")
+
+	recordCorefileApplied(coreDNS, template, "hash-a", true, applied)
+	assert.Equal(t, "hash-a", coreDNS.Status.CorefileHash)
+	assert.Equal(t, &applied, coreDNS.Status.CorefileAppliedAt)
+	assert.Equal(t, int64(4), coreDNS.Status.CorefileGeneration)
+
+	// A spec change that leaves the Corefile alone keeps the recorded apply
+	coreDNS.Generation = 5
+	recordCorefileApplied(coreDNS, template, "hash-a", true, metav1.Now())
+	assert.Equal(t, &applied, coreDNS.Status.CorefileAppliedAt)
+	assert.Equal(t, int64(4), coreDNS.Status.CorefileGeneration)
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_CorefileApplied(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-dns",
+			Namespace:  "default",
+			Generation: 1,
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-dns", Namespace: "default"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-dns-abc123-coredns", Namespace: "default"}, configMap))
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-dns-abc123-coredns", Namespace: "default"}, deployment))
+	assert.Equal(t, corefileHash(configMap.Data[CorefileKey]), deployment.Spec.Template.Annotations[AnnotationCorefileHash])
+
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Empty(t, updated.Status.CorefileHash, "pods have not rolled out yet")
+	assert.Nil(t, updated.Status.CorefileAppliedAt)
+
+	deployment.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: deployment.Generation,
+		Replicas:           2,
+		UpdatedReplicas:    2,
+		ReadyReplicas:      2,
+		AvailableReplicas:  2,
+	}
+	require.NoError(t, fakeClient.Status().Update(ctx, deployment))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, corefileHash(configMap.Data[CorefileKey]), updated.Status.CorefileHash)
+	assert.NotNil(t, updated.Status.CorefileAppliedAt)
+	assert.Equal(t, int64(1), updated.Status.CorefileGeneration)
+}
@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+const (
+	// CrossNamespaceCredentialsAllow lets a profile reference a credentials
+	// Secret in any namespace
+	CrossNamespaceCredentialsAllow = "allow"
+
+	// CrossNamespaceCredentialsGrant lets a profile reference a credentials
+	// Secret in another namespace only when the Secret lists the profile's
+	// namespace in its nextdns.io/allowed-namespaces annotation
+	CrossNamespaceCredentialsGrant = "grant"
+
+	// CrossNamespaceCredentialsDeny restricts profiles to credentials
+	// Secrets in their own namespace
+	CrossNamespaceCredentialsDeny = "deny"
+
+	// AnnotationAllowedNamespaces lists, comma-separated, the namespaces
+	// whose profiles may reference a credentials Secret in grant mode; "*"
+	// allows all namespaces
+	AnnotationAllowedNamespaces = "nextdns.io/allowed-namespaces"
+
+	// ReasonInvalidCredentials is set when NextDNS rejects the API key
+	ReasonInvalidCredentials = "InvalidCredentials"
+
+	// defaultCredentialsKey is the Secret key holding the API key when a
+	// reference sets none
+	defaultCredentialsKey = "api-key"
+)
+
+// crossNamespaceCredentials is the policy for credentialsRef.namespace
+var crossNamespaceCredentials = CrossNamespaceCredentialsAllow
+
+// errCredentialsAccessDenied is wrapped by the errors of credentials
+// Secrets in another namespace that a profile may not read
+var errCredentialsAccessDenied = errors.New("cross-namespace credentials access denied")
+
+// defaultCredentials is the Secret used by profiles without a
+// credentialsRef; its Name is empty when none is configured
+var defaultCredentials types.NamespacedName
+
+// errNoCredentials is returned for a profile without a credentialsRef when
+// no default credentials Secret is configured
+var errNoCredentials = errors.New("spec.credentialsRef is not set and no default credentials Secret is configured")
+
+// errConflictingCredentials is returned for a profile setting both
+// credentialsRef and accountRef
+var errConflictingCredentials = errors.New("spec.credentialsRef and spec.accountRef are mutually exclusive")
+
+// ParseDefaultCredentialsSecret parses a "namespace/name" Secret reference,
+// returning an empty name for an empty value
+func ParseDefaultCredentialsSecret(value string) (types.NamespacedName, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return types.NamespacedName{}, nil
+	}
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok {
+		return types.NamespacedName{}, fmt.Errorf("invalid default credentials Secret %q: must be namespace/name", value)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid default credentials Secret namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid default credentials Secret name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// SetDefaultCredentialsSecret sets the Secret used by profiles without a
+// credentialsRef; an empty name turns the default off. It must be called
+// before the controllers start.
+func SetDefaultCredentialsSecret(secret types.NamespacedName) {
+	defaultCredentials = secret
+}
+
+// SetCrossNamespaceCredentials sets the policy for credentials Secrets in
+// another namespace than the profile: allow, grant or deny. It must be
+// called before the controllers start.
+func SetCrossNamespaceCredentials(mode string) error {
+	switch mode {
+	case CrossNamespaceCredentialsAllow, CrossNamespaceCredentialsGrant, CrossNamespaceCredentialsDeny:
+	default:
+		return fmt.Errorf("invalid cross-namespace credentials mode %q: must be %s, %s or %s",
+			mode, CrossNamespaceCredentialsAllow, CrossNamespaceCredentialsGrant, CrossNamespaceCredentialsDeny)
+	}
+	crossNamespaceCredentials = mode
+	return nil
+}
+
+// namespaceGranted reports whether the AnnotationAllowedNamespaces
+// annotation in annotations lists namespace
+func namespaceGranted(annotations map[string]string, namespace string) bool {
+	for _, allowed := range strings.Split(annotations[AnnotationAllowedNamespaces], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsGranted reports whether the cross-namespace credentials policy
+// lets namespace use credentials from another namespace whose Secret or
+// NextDNSAccount carries annotations
+func credentialsGranted(annotations map[string]string, namespace string) bool {
+	switch crossNamespaceCredentials {
+	case CrossNamespaceCredentialsAllow:
+		return true
+	case CrossNamespaceCredentialsGrant:
+		return namespaceGranted(annotations, namespace)
+	default:
+		return false
+	}
+}
+
+// profileCredentials returns the Secret reference holding the API key of
+// profile, with its namespace and key filled in, and where it came from.
+// spec.credentialsRef takes precedence over the default credentials Secret.
+// It does not follow spec.accountRef; resolveCredentials does.
+func profileCredentials(profile *nextdnsv1alpha1.NextDNSProfile) (nextdnsv1alpha1.SecretKeySelector, nextdnsv1alpha1.CredentialsSource, error) {
+	ref := profile.Spec.CredentialsRef
+	source := nextdnsv1alpha1.CredentialsSourceCredentialsRef
+	if ref.Name == "" {
+		if defaultCredentials.Name == "" {
+			return nextdnsv1alpha1.SecretKeySelector{}, "", errNoCredentials
+		}
+		ref = nextdnsv1alpha1.SecretKeySelector{Name: defaultCredentials.Name, Namespace: defaultCredentials.Namespace}
+		source = nextdnsv1alpha1.CredentialsSourceDefault
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = profile.Namespace
+	}
+	if ref.Key == "" {
+		ref.Key = defaultCredentialsKey
+	}
+	return ref, source, nil
+}
+
+// accountCredentials returns the Secret reference holding the API key of
+// account, with its namespace and key filled in
+func accountCredentials(account *nextdnsv1alpha1.NextDNSAccount) nextdnsv1alpha1.SecretKeySelector {
+	ref := account.Spec.CredentialsRef
+	if ref.Namespace == "" {
+		ref.Namespace = account.Namespace
+	}
+	if ref.Key == "" {
+		ref.Key = defaultCredentialsKey
+	}
+	return ref
+}
+
+// profileAccount returns the NextDNSAccount in spec.accountRef of profile.
+// An account in another namespace is subject to the cross-namespace
+// credentials policy, with the grant read from the account's
+// nextdns.io/allowed-namespaces annotation.
+func profileAccount(ctx context.Context, c client.Reader, profile *nextdnsv1alpha1.NextDNSProfile) (*nextdnsv1alpha1.NextDNSAccount, error) {
+	ref := profile.Spec.AccountRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = profile.Namespace
+	}
+
+	crossNamespace := namespace != profile.Namespace
+	if crossNamespace && crossNamespaceCredentials == CrossNamespaceCredentialsDeny {
+		return nil, fmt.Errorf("%w: NextDNSAccount %s/%s is outside the profile's namespace", errCredentialsAccessDenied, namespace, ref.Name)
+	}
+
+	account := &nextdnsv1alpha1.NextDNSAccount{}
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, account); err != nil {
+		return nil, fmt.Errorf("failed to get NextDNSAccount %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	if crossNamespace && !credentialsGranted(account.Annotations, profile.Namespace) {
+		return nil, fmt.Errorf("%w: NextDNSAccount %s/%s does not list namespace %s in its %s annotation",
+			errCredentialsAccessDenied, namespace, ref.Name, profile.Namespace, AnnotationAllowedNamespaces)
+	}
+	return account, nil
+}
+
+// resolveCredentials returns the Secret reference holding the API key of
+// profile like profileCredentials, following spec.accountRef to the
+// account's Secret. It also returns the namespace the reference was made
+// from, which the cross-namespace credentials policy applies to; it is
+// empty for the default credentials Secret.
+func resolveCredentials(ctx context.Context, c client.Reader, profile *nextdnsv1alpha1.NextDNSProfile) (nextdnsv1alpha1.SecretKeySelector, nextdnsv1alpha1.CredentialsSource, string, error) {
+	if profile.Spec.AccountRef != nil {
+		if profile.Spec.CredentialsRef.Name != "" {
+			return nextdnsv1alpha1.SecretKeySelector{}, "", "", errConflictingCredentials
+		}
+		account, err := profileAccount(ctx, c, profile)
+		if err != nil {
+			return nextdnsv1alpha1.SecretKeySelector{}, "", "", err
+		}
+		return accountCredentials(account), nextdnsv1alpha1.CredentialsSourceAccount, account.Namespace, nil
+	}
+
+	ref, source, err := profileCredentials(profile)
+	if err != nil {
+		return nextdnsv1alpha1.SecretKeySelector{}, "", "", err
+	}
+	if source == nextdnsv1alpha1.CredentialsSourceDefault {
+		return ref, source, "", nil
+	}
+	return ref, source, profile.Namespace, nil
+}
+
+// setCredentialsStatus records in status which Secret the API key of
+// profile is read from
+func setCredentialsStatus(ctx context.Context, c client.Reader, profile *nextdnsv1alpha1.NextDNSProfile) {
+	ref, source, _, err := resolveCredentials(ctx, c, profile)
+	if err != nil {
+		profile.Status.CredentialsSource = ""
+		profile.Status.CredentialsSecret = ""
+		return
+	}
+	profile.Status.CredentialsSource = source
+	profile.Status.CredentialsSecret = ref.Namespace + "/" + ref.Name
+}
+
+// validateCredentials checks the API key of profile with NextDNS unless it
+// was accepted before for the same version of the credentials Secret, so a
+// rotated or revoked key is reported before the sync. The outcome is
+// recorded in the CredentialsValid condition and written to status at once.
+// It reports whether NextDNS rejected the key, in which case Ready is set to
+// False; a check that fails otherwise leaves the sync to report the error.
+func (r *NextDNSProfileReconciler) validateCredentials(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, apiKey, secretVersion string) bool {
+	if secretVersion == profile.Status.CredentialsSecretVersion &&
+		meta.IsStatusConditionTrue(profile.Status.Conditions, ConditionTypeCredentialsValid) {
+		return false
+	}
+	logger := log.FromContext(ctx)
+
+	factory := r.ClientFactory
+	if factory == nil {
+		factory = DefaultClientFactory
+	}
+	client, err := factory(apiKey)
+	if err == nil {
+		err = client.ValidateKey(ctx)
+	}
+
+	rejected := false
+	switch {
+	case err == nil:
+		profile.Status.CredentialsSecretVersion = secretVersion
+		r.setCondition(profile, ConditionTypeCredentialsValid, metav1.ConditionTrue, "Validated",
+			fmt.Sprintf("NextDNS accepted the API key in secret %s", profile.Status.CredentialsSecret))
+	case nextdns.IsAuthError(err):
+		logger.Error(err, "NextDNS rejected the API key")
+		rejected = true
+		r.recordRejectedCredentials(profile, err)
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, ReasonInvalidCredentials)
+		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, ReasonInvalidCredentials,
+			fmt.Sprintf("NextDNS rejected the API key in secret %s", profile.Status.CredentialsSecret))
+	default:
+		r.setCondition(profile, ConditionTypeCredentialsValid, metav1.ConditionUnknown, "ValidationFailed", err.Error())
+	}
+
+	if err := r.Status().Update(ctx, profile); err != nil {
+		logger.Error(err, "Failed to update status")
+	}
+	return rejected
+}
+
+// recordRejectedCredentials sets the CredentialsValid condition to False
+// when err is an authentication failure, so the key is validated again on
+// the next reconcile
+func (r *NextDNSProfileReconciler) recordRejectedCredentials(profile *nextdnsv1alpha1.NextDNSProfile, err error) {
+	if !nextdns.IsAuthError(err) {
+		return
+	}
+	profile.Status.CredentialsSecretVersion = ""
+	r.setCondition(profile, ConditionTypeCredentialsValid, metav1.ConditionFalse, ReasonInvalidCredentials,
+		fmt.Sprintf("NextDNS rejected the API key in secret %s: %v", profile.Status.CredentialsSecret, err))
+}
@@ -0,0 +1,354 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestParseDefaultCredentialsSecret(t *testing.T) {
+	secret, err := ParseDefaultCredentialsSecret(" nextdns-system/nextdns-credentials ")
+	require.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "nextdns-system", Name: "nextdns-credentials"}, secret)
+
+	secret, err = ParseDefaultCredentialsSecret("")
+	require.NoError(t, err)
+	assert.Empty(t, secret.Name)
+
+	for _, value := range []string{"nextdns-credentials", "Bad/secret", "nextdns-system/", "nextdns-system/a/b"} {
+		_, err := ParseDefaultCredentialsSecret(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestProfileCredentials(t *testing.T) {
+	defer SetDefaultCredentialsSecret(types.NamespacedName{})
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "family"},
+	}
+	_, _, err := profileCredentials(profile)
+	assert.ErrorIs(t, err, errNoCredentials)
+
+	SetDefaultCredentialsSecret(types.NamespacedName{Namespace: "nextdns-system", Name: "nextdns-credentials"})
+	ref, source, err := profileCredentials(profile)
+	require.NoError(t, err)
+	assert.Equal(t, nextdnsv1alpha1.CredentialsSourceDefault, source)
+	assert.Equal(t, nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials", Namespace: "nextdns-system", Key: "api-key"}, ref)
+	assert.Equal(t, []string{"nextdns-system/nextdns-credentials"}, credentialsRefIndexFunc(profile))
+
+	// spec.credentialsRef takes precedence over the default
+	profile.Spec.CredentialsRef = nextdnsv1alpha1.SecretKeySelector{Name: "own", Key: "token"}
+	ref, source, err = profileCredentials(profile)
+	require.NoError(t, err)
+	assert.Equal(t, nextdnsv1alpha1.CredentialsSourceCredentialsRef, source)
+	assert.Equal(t, nextdnsv1alpha1.SecretKeySelector{Name: "own", Namespace: "family", Key: "token"}, ref)
+}
+
+func TestReconcile_DefaultCredentials(t *testing.T) {
+	defer SetDefaultCredentialsSecret(types.NamespacedName{})
+	SetDefaultCredentialsSecret(types.NamespacedName{Namespace: "nextdns-system", Name: "nextdns-credentials"})
+
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "nextdns-system"},
+		Data:       map[string][]byte{"api-key": []byte("default-api-key")},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{Name: "Test Profile"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	var usedKey string
+	reconciler := &NextDNSProfileReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			usedKey = apiKey
+			return newMockNextDNSClient(), nil
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-profile", Namespace: "default"}}
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "default-api-key", usedKey)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, nextdnsv1alpha1.CredentialsSourceDefault, updated.Status.CredentialsSource)
+	assert.Equal(t, "nextdns-system/nextdns-credentials", updated.Status.CredentialsSecret)
+}
+
+func TestSetCrossNamespaceCredentials(t *testing.T) {
+	defer func() { crossNamespaceCredentials = CrossNamespaceCredentialsAllow }()
+
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsGrant))
+	assert.Equal(t, CrossNamespaceCredentialsGrant, crossNamespaceCredentials)
+	assert.ErrorContains(t, SetCrossNamespaceCredentials("open"), `invalid cross-namespace credentials mode "open"`)
+}
+
+func TestProfileAPIKey_CrossNamespacePolicy(t *testing.T) {
+	defer func() { crossNamespaceCredentials = CrossNamespaceCredentialsAllow }()
+	defer SetDefaultCredentialsSecret(types.NamespacedName{})
+	ctx := context.Background()
+
+	central := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nextdns-credentials",
+			Namespace:   "nextdns-system",
+			Annotations: map[string]string{AnnotationAllowedNamespaces: "team-a, team-b"},
+		},
+		Data: map[string][]byte{"api-key": []byte("central-api-key")},
+	}
+	local := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "team-c"},
+		Data:       map[string][]byte{"api-key": []byte("local-api-key")},
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(central, local).Build()
+
+	profileIn := func(namespace, secretNamespace string) *nextdnsv1alpha1.NextDNSProfile {
+		return &nextdnsv1alpha1.NextDNSProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: namespace},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials", Namespace: secretNamespace},
+			},
+		}
+	}
+
+	// allow reads Secrets in any namespace
+	apiKey, err := profileAPIKey(ctx, c, profileIn("team-c", "nextdns-system"))
+	require.NoError(t, err)
+	assert.Equal(t, "central-api-key", apiKey)
+
+	// grant requires the Secret to list the profile's namespace
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsGrant))
+	apiKey, err = profileAPIKey(ctx, c, profileIn("team-b", "nextdns-system"))
+	require.NoError(t, err)
+	assert.Equal(t, "central-api-key", apiKey)
+	_, err = profileAPIKey(ctx, c, profileIn("team-c", "nextdns-system"))
+	assert.ErrorIs(t, err, errCredentialsAccessDenied)
+	assert.ErrorContains(t, err, "does not list namespace team-c")
+
+	// deny only allows the profile's own namespace
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsDeny))
+	_, err = profileAPIKey(ctx, c, profileIn("team-a", "nextdns-system"))
+	assert.ErrorIs(t, err, errCredentialsAccessDenied)
+	apiKey, err = profileAPIKey(ctx, c, profileIn("team-c", "team-c"))
+	require.NoError(t, err)
+	assert.Equal(t, "local-api-key", apiKey)
+
+	// The operator's default credentials Secret is exempt
+	SetDefaultCredentialsSecret(types.NamespacedName{Namespace: "nextdns-system", Name: "nextdns-credentials"})
+	apiKey, err = profileAPIKey(ctx, c, &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "team-c"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "central-api-key", apiKey)
+}
+
+func TestReconcile_CrossNamespaceCredentialsDenied(t *testing.T) {
+	defer func() { crossNamespaceCredentials = CrossNamespaceCredentialsAllow }()
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsDeny))
+
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Test Profile",
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials", Namespace: "nextdns-system"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile).
+		WithStatusSubresource(profile).
+		Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-profile", Namespace: "default"}}
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, ReasonCrossNamespaceAccessDenied, cond.Reason)
+}
+
+func TestReconcile_CredentialsValidation(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("old-api-key")},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Test Profile",
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	mockClient := nextdns.NewMockClient()
+	reconciler := &NextDNSProfileReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockClient, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-profile", Namespace: "default"}}
+	validations := func() int {
+		n := 0
+		for _, call := range mockClient.Calls {
+			if call.Method == "ValidateKey" {
+				n++
+			}
+		}
+		return n
+	}
+	reconcile := func() *nextdnsv1alpha1.NextDNSProfile {
+		t.Helper()
+		_, err := reconciler.Reconcile(ctx, req)
+		require.NoError(t, err)
+		var updated nextdnsv1alpha1.NextDNSProfile
+		require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+		return &updated
+	}
+
+	updated := reconcile()
+	assert.Equal(t, 1, validations())
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeCredentialsValid))
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "nextdns-credentials", Namespace: "default"}, secret))
+	assert.Equal(t, secret.ResourceVersion, updated.Status.CredentialsSecretVersion)
+
+	// An unchanged Secret is not validated again
+	reconcile()
+	assert.Equal(t, 1, validations())
+
+	// A rotated key that NextDNS rejects is reported before any sync
+	secret.Data["api-key"] = []byte("revoked-api-key")
+	require.NoError(t, fakeClient.Update(ctx, secret))
+	mockClient.ValidateKeyError = &sdknextdns.Error{Type: sdknextdns.ErrorTypeAuthentication}
+	mockClient.Calls = nil
+
+	updated = reconcile()
+	assert.Equal(t, []nextdns.MockCall{{Method: "ValidateKey"}}, mockClient.Calls)
+	assert.Empty(t, updated.Status.CredentialsSecretVersion)
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeCredentialsValid)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, ReasonInvalidCredentials, cond.Reason)
+	ready := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, ReasonInvalidCredentials, ready.Reason)
+
+	// A rejected key is validated again until NextDNS accepts it
+	mockClient.ValidateKeyError = nil
+	updated = reconcile()
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeCredentialsValid))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeReady))
+}
+
+func TestProfileAPIKey_AccountRef(t *testing.T) {
+	defer func() { crossNamespaceCredentials = CrossNamespaceCredentialsAllow }()
+	ctx := context.Background()
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "main",
+			Namespace:   "nextdns-system",
+			Annotations: map[string]string{AnnotationAllowedNamespaces: "team-a"},
+		},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "nextdns-system"},
+		Data:       map[string][]byte{"api-key": []byte("account-api-key")},
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(account, secret).Build()
+
+	profileIn := func(namespace string) *nextdnsv1alpha1.NextDNSProfile {
+		return &nextdnsv1alpha1.NextDNSProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: namespace},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				AccountRef: &nextdnsv1alpha1.ResourceReference{Name: "main", Namespace: "nextdns-system"},
+			},
+		}
+	}
+
+	apiKey, err := profileAPIKey(ctx, c, profileIn("team-b"))
+	require.NoError(t, err)
+	assert.Equal(t, "account-api-key", apiKey)
+
+	profile := profileIn("team-b")
+	setCredentialsStatus(ctx, c, profile)
+	assert.Equal(t, nextdnsv1alpha1.CredentialsSourceAccount, profile.Status.CredentialsSource)
+	assert.Equal(t, "nextdns-system/nextdns-credentials", profile.Status.CredentialsSecret)
+
+	// The account's Secret is read from the account's own namespace, so
+	// only the account reference is subject to the policy
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsGrant))
+	apiKey, err = profileAPIKey(ctx, c, profileIn("team-a"))
+	require.NoError(t, err)
+	assert.Equal(t, "account-api-key", apiKey)
+	_, err = profileAPIKey(ctx, c, profileIn("team-b"))
+	assert.ErrorIs(t, err, errCredentialsAccessDenied)
+	assert.ErrorContains(t, err, "NextDNSAccount nextdns-system/main does not list namespace team-b")
+
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsDeny))
+	_, err = profileAPIKey(ctx, c, profileIn("team-a"))
+	assert.ErrorIs(t, err, errCredentialsAccessDenied)
+
+	// A profile cannot name both an account and a Secret
+	profile = profileIn("nextdns-system")
+	profile.Spec.CredentialsRef = nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"}
+	_, err = profileAPIKey(ctx, c, profile)
+	assert.ErrorIs(t, err, errConflictingCredentials)
+}
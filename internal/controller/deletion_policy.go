@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// deletionPolicy returns the effective deletion policy of profile. Without
+// an explicit policy, profiles the operator created are deleted and profiles
+// adopted through spec.profileID are orphaned.
+func deletionPolicy(profile *nextdnsv1alpha1.NextDNSProfile) nextdnsv1alpha1.DeletionPolicy {
+	if profile.Spec.DeletionPolicy != "" {
+		return profile.Spec.DeletionPolicy
+	}
+	if profile.Spec.ProfileID != "" {
+		return nextdnsv1alpha1.DeletionPolicyOrphan
+	}
+	return nextdnsv1alpha1.DeletionPolicyDelete
+}
+
+// stripManagedLists removes the list entries the operator applied to the
+// remote profile: the allowlist and denylist domains of the spec and those
+// recorded in status.managedEntries, and the TLDs and rewrites if the spec
+// manages them. Entries added outside the operator are kept.
+func (r *NextDNSProfileReconciler) stripManagedLists(ctx context.Context, client nextdns.ClientInterface, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	profileID := profile.Status.ProfileID
+
+	// Resolve what the last sync applied, including the active overlay
+	desired := profile.DeepCopy()
+	if merged, err := effectiveSpec(&profile.Spec); err == nil {
+		desired.Spec = *merged
+	}
+	lists, err := r.resolveListReferences(ctx, desired)
+	if err != nil {
+		return fmt.Errorf("failed to resolve list references: %w", err)
+	}
+
+	var managed nextdnsv1alpha1.ManagedListEntries
+	if profile.Status.ManagedEntries != nil {
+		managed = *profile.Status.ManagedEntries
+	}
+
+	if domains := unionIDs(entryDomains(lists.Denylist), managed.Denylist); len(domains) > 0 {
+		opts := nextdns.ListSyncOptions{PreserveUnmanaged: true, Managed: domains}
+		if err := client.SyncDenylist(ctx, profileID, nil, opts); err != nil {
+			return fmt.Errorf("failed to remove denylist entries: %w", err)
+		}
+	}
+
+	if domains := unionIDs(entryDomains(lists.Allowlist), managed.Allowlist); len(domains) > 0 {
+		opts := nextdns.ListSyncOptions{PreserveUnmanaged: true, Managed: domains}
+		if err := client.SyncAllowlist(ctx, profileID, nil, opts); err != nil {
+			return fmt.Errorf("failed to remove allowlist entries: %w", err)
+		}
+	}
+
+	if len(lists.TLDs) > 0 {
+		if err := client.SyncSecurityTLDs(ctx, profileID, nil); err != nil {
+			return fmt.Errorf("failed to remove TLDs: %w", err)
+		}
+	}
+
+	if lists.Rewrites != nil {
+		if _, err := client.SyncRewrites(ctx, profileID, []nextdns.RewriteEntry{}); err != nil {
+			return fmt.Errorf("failed to remove rewrites: %w", err)
+		}
+	}
+
+	return nil
+}
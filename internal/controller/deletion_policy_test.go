@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		profileID string
+		policy    nextdnsv1alpha1.DeletionPolicy
+		want      nextdnsv1alpha1.DeletionPolicy
+	}{
+		{name: "created profile defaults to Delete", want: nextdnsv1alpha1.DeletionPolicyDelete},
+		{name: "adopted profile defaults to Orphan", profileID: "abc123", want: nextdnsv1alpha1.DeletionPolicyOrphan},
+		{name: "explicit policy wins", profileID: "abc123", policy: nextdnsv1alpha1.DeletionPolicyDelete, want: nextdnsv1alpha1.DeletionPolicyDelete},
+		{name: "Retain", policy: nextdnsv1alpha1.DeletionPolicyRetain, want: nextdnsv1alpha1.DeletionPolicyRetain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &nextdnsv1alpha1.NextDNSProfile{
+				Spec: nextdnsv1alpha1.NextDNSProfileSpec{ProfileID: tt.profileID, DeletionPolicy: tt.policy},
+			}
+			assert.Equal(t, tt.want, deletionPolicy(profile))
+		})
+	}
+}
+
+// newDeletionTest returns a reconciler for profile whose NextDNS profile
+// abc123 holds the given denylist and allowlist domains
+func newDeletionTest(t *testing.T, profile *nextdnsv1alpha1.NextDNSProfile, denylist, allowlist []string) (*NextDNSProfileReconciler, *nextdns.MockClient) {
+	t.Helper()
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Home", "abc123.dns.nextdns.io")
+	for _, domain := range denylist {
+		require.NoError(t, mockNDS.AddDenylistEntry(ctx, "abc123", domain, true))
+	}
+	for _, domain := range allowlist {
+		require.NoError(t, mockNDS.AddAllowlistEntry(ctx, "abc123", domain, true))
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, secret).Build()
+	return &NextDNSProfileReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}, mockNDS
+}
+
+func TestHandleDeletion_RetainStripsManagedLists(t *testing.T) {
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "home",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:                     "Home",
+			DeletionPolicy:           nextdnsv1alpha1.DeletionPolicyRetain,
+			CredentialsRef:           nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			PreserveUnmanagedEntries: true,
+			Denylist:                 []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+			Rewrites:                 []nextdnsv1alpha1.RewriteEntry{{From: "nas.home", To: "192.168.1.10"}},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			ManagedEntries: &nextdnsv1alpha1.ManagedListEntries{
+				Denylist:  []string{"ads.example.com"},
+				Allowlist: []string{"cdn.example.com"},
+			},
+		},
+	}
+	reconciler, mockNDS := newDeletionTest(t, profile,
+		[]string{"ads.example.com", "manual.example.com"},
+		[]string{"cdn.example.com", "dashboard.example.com"})
+	_, err := mockNDS.SyncRewrites(ctx, "abc123", []nextdns.RewriteEntry{{Name: "nas.home", Content: "192.168.1.10"}})
+	require.NoError(t, err)
+
+	_, err = reconciler.handleDeletion(ctx, profile)
+	require.NoError(t, err)
+
+	assert.False(t, mockNDS.WasMethodCalled("DeleteProfile"), "the profile is retained")
+	assert.NotContains(t, profile.Finalizers, FinalizerName)
+
+	denylist, err := mockNDS.GetDenylist(ctx, "abc123")
+	require.NoError(t, err)
+	require.Len(t, denylist, 1)
+	assert.Equal(t, "manual.example.com", denylist[0].ID, "entries added outside the operator are kept")
+
+	allowlist, err := mockNDS.GetAllowlist(ctx, "abc123")
+	require.NoError(t, err)
+	require.Len(t, allowlist, 1)
+	assert.Equal(t, "dashboard.example.com", allowlist[0].ID)
+
+	rewrites, err := mockNDS.GetRewrites(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Empty(t, rewrites)
+}
+
+func TestHandleDeletion_DeletePolicyOnAdoptedProfile(t *testing.T) {
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "home",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Home",
+			ProfileID:      "abc123",
+			DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	reconciler, mockNDS := newDeletionTest(t, profile, nil, nil)
+	recorder := record.NewFakeRecorder(10)
+	reconciler.Recorder = recorder
+
+	_, err := reconciler.handleDeletion(ctx, profile)
+	require.NoError(t, err)
+
+	assert.True(t, mockNDS.WasMethodCalled("DeleteProfile"))
+	assert.NotContains(t, profile.Finalizers, FinalizerName)
+	events := drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "Normal "+EventReasonProfileDeleted)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// ConditionTypeCoreDNSDeployed indicates the NextDNSCoreDNS requested by
+// spec.deployCoreDNS is in place
+const ConditionTypeCoreDNSDeployed = "CoreDNSDeployed"
+
+// errCoreDNSNotDeployed is returned when a NextDNSCoreDNS with the requested
+// name exists but is not controlled by the profile
+var errCoreDNSNotDeployed = errors.New("NextDNSCoreDNS exists and is not managed by this profile")
+
+// deployedCoreDNSName returns the name of the NextDNSCoreDNS deployed for
+// profile, or "" if spec.deployCoreDNS is not enabled
+func deployedCoreDNSName(profile *nextdnsv1alpha1.NextDNSProfile) string {
+	cfg := profile.Spec.DeployCoreDNS
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return profile.Name
+}
+
+// deployedCoreDNSSpec returns the spec of the NextDNSCoreDNS deployed for
+// profile: the template bound to the profile
+func deployedCoreDNSSpec(profile *nextdnsv1alpha1.NextDNSProfile) nextdnsv1alpha1.NextDNSCoreDNSSpec {
+	spec := nextdnsv1alpha1.NextDNSCoreDNSSpec{
+		ProfileRef: nextdnsv1alpha1.ResourceReference{Name: profile.Name},
+	}
+	if template := profile.Spec.DeployCoreDNS.Template.DeepCopy(); template != nil {
+		spec.Deployment = template.Deployment
+		spec.Service = template.Service
+		spec.Corefile = template.Corefile
+	}
+	return spec
+}
+
+// reconcileDeployedCoreDNS creates or updates the NextDNSCoreDNS requested by
+// spec.deployCoreDNS and deletes one deployed earlier that is no longer
+// wanted. The instance is owned by the profile, so edits to it are reverted
+// and it is garbage collected with the profile.
+func (r *NextDNSProfileReconciler) reconcileDeployedCoreDNS(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+	name := deployedCoreDNSName(profile)
+
+	if previous := profile.Status.DeployedCoreDNS; previous != "" && previous != name {
+		if err := r.deleteDeployedCoreDNS(ctx, profile, previous); err != nil {
+			return err
+		}
+		profile.Status.DeployedCoreDNS = ""
+	}
+
+	if name == "" {
+		meta.RemoveStatusCondition(&profile.Status.Conditions, ConditionTypeCoreDNSDeployed)
+		return nil
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: profile.Namespace},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, coreDNS, func() error {
+		if coreDNS.ResourceVersion != "" && !metav1.IsControlledBy(coreDNS, profile) {
+			return errCoreDNSNotDeployed
+		}
+		r.ResourceLabels.set(coreDNS)
+		coreDNS.Spec = deployedCoreDNSSpec(profile)
+		return controllerutil.SetControllerReference(profile, coreDNS, r.Scheme)
+	})
+	if errors.Is(err, errCoreDNSNotDeployed) {
+		r.setCondition(profile, ConditionTypeCoreDNSDeployed, metav1.ConditionFalse, "NameConflict",
+			fmt.Sprintf("NextDNSCoreDNS %s exists and is not managed by this profile; set spec.deployCoreDNS.name", name))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply NextDNSCoreDNS %s: %w", name, err)
+	}
+	if result == controllerutil.OperationResultCreated {
+		logger.Info("Deployed NextDNSCoreDNS for profile", "coreDNS", name)
+	}
+
+	profile.Status.DeployedCoreDNS = name
+	r.setCondition(profile, ConditionTypeCoreDNSDeployed, metav1.ConditionTrue, "Deployed",
+		fmt.Sprintf("NextDNSCoreDNS %s is bound to this profile", name))
+	return nil
+}
+
+// deleteDeployedCoreDNS deletes the NextDNSCoreDNS name if the profile
+// controls it
+func (r *NextDNSProfileReconciler) deleteDeployedCoreDNS(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, name string) error {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: profile.Namespace}, coreDNS); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(coreDNS, profile) {
+		return nil
+	}
+	if err := r.Delete(ctx, coreDNS); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete NextDNSCoreDNS %s: %w", name, err)
+	}
+	log.FromContext(ctx).Info("Deleted NextDNSCoreDNS no longer deployed for profile", "coreDNS", name)
+	return nil
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newDeployCoreDNSTestProfile() *nextdnsv1alpha1.NextDNSProfile {
+	return &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", UID: "profile-uid"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			DeployCoreDNS: &nextdnsv1alpha1.DeployCoreDNSSpec{
+				Enabled: true,
+				Template: &nextdnsv1alpha1.DeployCoreDNSTemplate{
+					Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{Replicas: ptr.To[int32](3)},
+				},
+			},
+		},
+	}
+}
+
+func TestReconcileDeployedCoreDNS(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	profile := newDeployCoreDNSTestProfile()
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+	key := types.NamespacedName{Name: "home", Namespace: "default"}
+
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	require.NoError(t, fakeClient.Get(ctx, key, coreDNS))
+	assert.True(t, metav1.IsControlledBy(coreDNS, profile))
+	assert.Equal(t, "home", coreDNS.Spec.ProfileRef.Name)
+	assert.Equal(t, ptr.To[int32](3), coreDNS.Spec.Deployment.Replicas)
+	assert.Equal(t, "home", profile.Status.DeployedCoreDNS)
+	condition := meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeCoreDNSDeployed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// Edits to the deployed instance are reverted
+	coreDNS.Spec.Deployment.Replicas = ptr.To[int32](1)
+	require.NoError(t, fakeClient.Update(ctx, coreDNS))
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+	require.NoError(t, fakeClient.Get(ctx, key, coreDNS))
+	assert.Equal(t, ptr.To[int32](3), coreDNS.Spec.Deployment.Replicas)
+
+	// Disabling deletes the instance
+	profile.Spec.DeployCoreDNS.Enabled = false
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(ctx, key, coreDNS)))
+	assert.Empty(t, profile.Status.DeployedCoreDNS)
+	assert.Nil(t, meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeCoreDNSDeployed))
+}
+
+// pruneWithCRD drops the fields of obj the generated CRD in crdFile does not
+// declare, as the API server does on write, and returns the pruned paths
+func pruneWithCRD(t *testing.T, obj client.Object, crdFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile("../../config/crd/bases/" + crdFile)
+	require.NoError(t, err)
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal(data, crd))
+	internal := &apiextensions.JSONSchemaProps{}
+	require.NoError(t, apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(crd.Spec.Versions[0].Schema.OpenAPIV3Schema, internal, nil))
+	schema, err := structuralschema.NewStructural(internal)
+	require.NoError(t, err)
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	pruned := pruning.PruneWithOptions(u, schema, true, structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true})
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u, obj))
+	return pruned
+}
+
+// TestReconcileDeployedCoreDNS_ServiceRoundTrip follows the Service settings
+// of a deployCoreDNS template through the profile CRD, the deployed
+// NextDNSCoreDNS and its CRD, to the Service
+func TestReconcileDeployedCoreDNS_ServiceRoundTrip(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	profile := newDeployCoreDNSTestProfile()
+	profile.Status.ProfileID = "abc123"
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	profile.Spec.DeployCoreDNS.Template.Service = &nextdnsv1alpha1.CoreDNSServiceConfig{
+		InternalTrafficPolicy: &local,
+		TopologyAwareRouting:  true,
+	}
+	assert.Empty(t, pruneWithCRD(t, profile, "nextdns.io_nextdnsprofiles.yaml"))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()
+	r := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home", Namespace: "default"}, coreDNS))
+	assert.Empty(t, pruneWithCRD(t, coreDNS, "nextdns.io_nextdnscorednses.yaml"))
+
+	coreDNSReconciler := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, coreDNSReconciler.reconcileService(ctx, coreDNS, profile))
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home-abc123-coredns", Namespace: "default"}, service))
+	assert.Equal(t, &local, service.Spec.InternalTrafficPolicy)
+	assert.Equal(t, "Auto", service.Annotations[corev1.AnnotationTopologyMode])
+}
+
+func TestReconcileDeployedCoreDNS_Rename(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	profile := newDeployCoreDNSTestProfile()
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+
+	profile.Spec.DeployCoreDNS.Name = "home-dns"
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home-dns", Namespace: "default"}, coreDNS))
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(ctx, types.NamespacedName{Name: "home", Namespace: "default"}, coreDNS)))
+	assert.Equal(t, "home-dns", profile.Status.DeployedCoreDNS)
+}
+
+func TestReconcileDeployedCoreDNS_NameConflict(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	profile := newDeployCoreDNSTestProfile()
+	existing := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "other"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+	r := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+
+	require.NoError(t, r.reconcileDeployedCoreDNS(ctx, profile))
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home", Namespace: "default"}, coreDNS))
+	assert.Equal(t, "other", coreDNS.Spec.ProfileRef.Name, "a standalone instance is left alone")
+	assert.Empty(t, profile.Status.DeployedCoreDNS)
+	condition := meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeCoreDNSDeployed)
+	require.NotNil(t, condition)
+	assert.Equal(t, "NameConflict", condition.Reason)
+}
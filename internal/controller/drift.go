@@ -0,0 +1,434 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+const (
+	// ConditionTypeDrifted indicates the remote profile differs from the desired state
+	ConditionTypeDrifted = "Drifted"
+
+	// maxDriftDifferences caps status.driftSummary.differences
+	maxDriftDifferences = 20
+)
+
+// desiredSecurityConfig builds the security settings applied for spec
+func desiredSecurityConfig(spec *nextdnsv1alpha1.SecuritySpec) *nextdns.SecurityConfig {
+	return &nextdns.SecurityConfig{
+		ThreatIntelligenceFeeds: boolValue(spec.ThreatIntelligenceFeeds, true),
+		AIThreatDetection:       boolValue(spec.AIThreatDetection, true),
+		GoogleSafeBrowsing:      boolValue(spec.GoogleSafeBrowsing, true),
+		Cryptojacking:           boolValue(spec.Cryptojacking, true),
+		DNSRebinding:            boolValue(spec.DNSRebinding, true),
+		IDNHomographs:           boolValue(spec.IDNHomographs, true),
+		Typosquatting:           boolValue(spec.Typosquatting, true),
+		DGA:                     boolValue(spec.DGA, true),
+		NRD:                     boolValue(spec.NRD, false),
+		DDNS:                    boolValue(spec.DDNS, false),
+		Parking:                 boolValue(spec.Parking, true),
+		CSAM:                    boolValue(spec.CSAM, true),
+	}
+}
+
+// desiredPrivacyConfig builds the privacy settings applied for spec
+func desiredPrivacyConfig(spec *nextdnsv1alpha1.PrivacySpec) *nextdns.PrivacyConfig {
+	return &nextdns.PrivacyConfig{
+		DisguisedTrackers: boolValue(spec.DisguisedTrackers, true),
+		AllowAffiliate:    boolValue(spec.AllowAffiliate, false),
+	}
+}
+
+// desiredPrivacyBlocklists returns the IDs of the active privacy blocklists
+func desiredPrivacyBlocklists(spec *nextdnsv1alpha1.PrivacySpec) []string {
+	blocklists := make([]string, 0, len(spec.Blocklists))
+	for _, bl := range spec.Blocklists {
+		if bl.Active == nil || *bl.Active {
+			blocklists = append(blocklists, bl.ID)
+		}
+	}
+	return blocklists
+}
+
+// desiredPrivacyNatives returns the IDs of the active native tracking protections
+func desiredPrivacyNatives(spec *nextdnsv1alpha1.PrivacySpec) []string {
+	natives := make([]string, 0, len(spec.Natives))
+	for _, n := range spec.Natives {
+		if n.Active == nil || *n.Active {
+			natives = append(natives, n.ID)
+		}
+	}
+	return natives
+}
+
+// desiredParentalControlConfig builds the parental control settings applied for spec
+func desiredParentalControlConfig(spec *nextdnsv1alpha1.ParentalControlSpec) *nextdns.ParentalControlConfig {
+	categories := make([]string, 0)
+	for _, c := range spec.Categories {
+		if c.Active == nil || *c.Active {
+			categories = append(categories, c.ID)
+		}
+	}
+	services := make([]string, 0)
+	for _, s := range spec.Services {
+		if s.Active == nil || *s.Active {
+			services = append(services, s.ID)
+		}
+	}
+
+	return &nextdns.ParentalControlConfig{
+		Categories:            categories,
+		Services:              services,
+		SafeSearch:            boolValue(spec.SafeSearch, false),
+		YouTubeRestrictedMode: boolValue(spec.YouTubeRestrictedMode, false),
+		BlockBypass:           boolValue(spec.BlockBypass, false),
+	}
+}
+
+// desiredSettingsConfig builds the general settings applied for spec
+func desiredSettingsConfig(spec *nextdnsv1alpha1.SettingsSpec) *nextdns.SettingsConfig {
+	settingsConfig := &nextdns.SettingsConfig{
+		// Log defaults
+		LogsEnabled:   true,
+		LogClientsIPs: false,
+		LogDomains:    true,
+		// Block page default
+		BlockPageEnable: true,
+		// Performance defaults
+		Ecs:             true,
+		CacheBoost:      true,
+		CnameFlattening: true,
+	}
+	if spec.Logs != nil {
+		settingsConfig.LogsEnabled = boolValue(spec.Logs.Enabled, true)
+		settingsConfig.LogClientsIPs = boolValue(spec.Logs.LogClientsIPs, false)
+		settingsConfig.LogDomains = boolValue(spec.Logs.LogDomains, true)
+		settingsConfig.LogRetention = parseRetentionSeconds(spec.Logs.Retention)
+		settingsConfig.Location = spec.Logs.Location
+	}
+	if spec.BlockPage != nil {
+		settingsConfig.BlockPageEnable = boolValue(spec.BlockPage.Enabled, true)
+	}
+	if spec.Performance != nil {
+		settingsConfig.Ecs = boolValue(spec.Performance.ECS, true)
+		settingsConfig.CacheBoost = boolValue(spec.Performance.CacheBoost, true)
+		settingsConfig.CnameFlattening = boolValue(spec.Performance.CNAMEFlattening, true)
+	}
+	settingsConfig.Web3 = boolValue(spec.Web3, false)
+	settingsConfig.BAV = boolValue(spec.BAV, false)
+	return settingsConfig
+}
+
+// desiredConfigHash hashes everything syncWithNextDNS applies, so a change to
+// the spec or to any referenced list can be told apart from remote drift
+func desiredConfigHash(spec *nextdnsv1alpha1.NextDNSProfileSpec, lists *ResolvedLists) (string, error) {
+	data, err := json.Marshal(struct {
+		Name            string
+		Security        *nextdnsv1alpha1.SecuritySpec
+		Privacy         *nextdnsv1alpha1.PrivacySpec
+		ParentalControl *nextdnsv1alpha1.ParentalControlSpec
+		Settings        *nextdnsv1alpha1.SettingsSpec
+		Allowlist       []nextdns.DomainEntry
+		Denylist        []nextdns.DomainEntry
+		TLDs            []string
+		Rewrites        []nextdns.RewriteEntry
+	}{
+		Name:            spec.Name,
+		Security:        spec.Security,
+		Privacy:         spec.Privacy,
+		ParentalControl: spec.ParentalControl,
+		Settings:        spec.Settings,
+		Allowlist:       lists.Allowlist,
+		Denylist:        lists.Denylist,
+		TLDs:            lists.TLDs,
+		Rewrites:        lists.Rewrites,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash desired config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// driftRecorder collects differences grouped by profile section
+type driftRecorder struct {
+	sections    []string
+	differences []string
+}
+
+func (d *driftRecorder) add(section, format string, args ...any) {
+	if len(d.sections) == 0 || d.sections[len(d.sections)-1] != section {
+		d.sections = append(d.sections, section)
+	}
+	d.differences = append(d.differences, section+": "+fmt.Sprintf(format, args...))
+}
+
+func (d *driftRecorder) flag(section, field string, desired, remote bool) {
+	if desired != remote {
+		d.add(section, "%s is %t, want %t", field, remote, desired)
+	}
+}
+
+// ids compares two ID sets, reporting missing and unexpected entries in order
+func (d *driftRecorder) ids(section string, desired, remote []string) {
+	want := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		want[id] = true
+	}
+	have := make(map[string]bool, len(remote))
+	for _, id := range remote {
+		have[id] = true
+	}
+	for _, id := range sortedUnique(desired) {
+		if !have[id] {
+			d.add(section, "%s is missing", id)
+		}
+	}
+	for _, id := range sortedUnique(remote) {
+		if !want[id] {
+			d.add(section, "%s is not in the desired state", id)
+		}
+	}
+}
+
+// domains compares domain lists including each entry's active flag. Extra
+// remote domains are ignored when unmanaged entries are preserved.
+func (d *driftRecorder) domains(section string, desired []nextdns.DomainEntry, remote map[string]bool, preserveUnmanaged bool) {
+	want := make(map[string]bool, len(desired))
+	for _, e := range desired {
+		want[e.Domain] = e.Active
+	}
+	for _, domain := range sortedKeys(want) {
+		active, ok := remote[domain]
+		switch {
+		case !ok:
+			d.add(section, "%s is missing", domain)
+		case active != want[domain]:
+			d.add(section, "%s active is %t, want %t", domain, active, want[domain])
+		}
+	}
+	if preserveUnmanaged {
+		return
+	}
+	for _, domain := range sortedKeys(remote) {
+		if _, ok := want[domain]; !ok {
+			d.add(section, "%s is not in the desired state", domain)
+		}
+	}
+}
+
+// summary returns the collected drift, or nil when there is none
+func (d *driftRecorder) summary() *nextdnsv1alpha1.DriftSummary {
+	if len(d.differences) == 0 {
+		return nil
+	}
+	differences := d.differences
+	if len(differences) > maxDriftDifferences {
+		more := len(differences) - maxDriftDifferences
+		differences = append(differences[:maxDriftDifferences:maxDriftDifferences],
+			fmt.Sprintf("... and %d more", more))
+	}
+	return &nextdnsv1alpha1.DriftSummary{
+		Sections:    d.sections,
+		Differences: differences,
+	}
+}
+
+// detectDrift reads back every section syncWithNextDNS manages and compares it
+// with the desired state. Sections the spec leaves unset are not compared,
+// matching the sync which leaves them untouched. Returns nil when the remote
+// profile matches.
+func detectDrift(ctx context.Context, client nextdns.ClientInterface, profileID string, spec *nextdnsv1alpha1.NextDNSProfileSpec, lists *ResolvedLists) (*nextdnsv1alpha1.DriftSummary, error) {
+	d := &driftRecorder{}
+
+	if spec.Security != nil {
+		remote, err := client.GetSecurity(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get security: %w", err)
+		}
+		want := desiredSecurityConfig(spec.Security)
+		d.flag("security", "threatIntelligenceFeeds", want.ThreatIntelligenceFeeds, remote.ThreatIntelligenceFeeds)
+		d.flag("security", "aiThreatDetection", want.AIThreatDetection, remote.AiThreatDetection)
+		d.flag("security", "googleSafeBrowsing", want.GoogleSafeBrowsing, remote.GoogleSafeBrowsing)
+		d.flag("security", "cryptojacking", want.Cryptojacking, remote.Cryptojacking)
+		d.flag("security", "dnsRebinding", want.DNSRebinding, remote.DNSRebinding)
+		d.flag("security", "idnHomographs", want.IDNHomographs, remote.IdnHomographs)
+		d.flag("security", "typosquatting", want.Typosquatting, remote.Typosquatting)
+		d.flag("security", "dga", want.DGA, remote.Dga)
+		d.flag("security", "nrd", want.NRD, remote.Nrd)
+		d.flag("security", "ddns", want.DDNS, remote.DDNS)
+		d.flag("security", "parking", want.Parking, remote.Parking)
+		d.flag("security", "csam", want.CSAM, remote.Csam)
+	}
+
+	if spec.Privacy != nil {
+		remote, err := client.GetPrivacy(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get privacy: %w", err)
+		}
+		want := desiredPrivacyConfig(spec.Privacy)
+		d.flag("privacy", "disguisedTrackers", want.DisguisedTrackers, remote.DisguisedTrackers)
+		d.flag("privacy", "allowAffiliate", want.AllowAffiliate, remote.AllowAffiliate)
+
+		if len(spec.Privacy.Blocklists) > 0 {
+			blocklists, err := client.GetPrivacyBlocklists(ctx, profileID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get privacy blocklists: %w", err)
+			}
+			remoteIDs := make([]string, 0, len(blocklists))
+			for _, bl := range blocklists {
+				remoteIDs = append(remoteIDs, bl.ID)
+			}
+			d.ids("privacy.blocklists", desiredPrivacyBlocklists(spec.Privacy), remoteIDs)
+		}
+
+		if len(spec.Privacy.Natives) > 0 {
+			natives, err := client.GetPrivacyNatives(ctx, profileID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get privacy natives: %w", err)
+			}
+			remoteIDs := make([]string, 0, len(natives))
+			for _, n := range natives {
+				remoteIDs = append(remoteIDs, n.ID)
+			}
+			d.ids("privacy.natives", desiredPrivacyNatives(spec.Privacy), remoteIDs)
+		}
+	}
+
+	if spec.ParentalControl != nil {
+		remote, err := client.GetParentalControl(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parental control: %w", err)
+		}
+		want := desiredParentalControlConfig(spec.ParentalControl)
+		d.flag("parentalControl", "safeSearch", want.SafeSearch, remote.SafeSearch)
+		d.flag("parentalControl", "youtubeRestrictedMode", want.YouTubeRestrictedMode, remote.YoutubeRestrictedMode)
+		d.flag("parentalControl", "blockBypass", want.BlockBypass, remote.BlockBypass)
+
+		// Categories and services are only pushed when some are desired
+		if len(want.Categories) > 0 {
+			categories, err := client.GetParentalControlCategories(ctx, profileID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parental control categories: %w", err)
+			}
+			var remoteIDs []string
+			for _, c := range categories {
+				if c.Active {
+					remoteIDs = append(remoteIDs, c.ID)
+				}
+			}
+			d.ids("parentalControl.categories", want.Categories, remoteIDs)
+		}
+		if len(want.Services) > 0 {
+			services, err := client.GetParentalControlServices(ctx, profileID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parental control services: %w", err)
+			}
+			var remoteIDs []string
+			for _, s := range services {
+				if s.Active {
+					remoteIDs = append(remoteIDs, s.ID)
+				}
+			}
+			d.ids("parentalControl.services", want.Services, remoteIDs)
+		}
+	}
+
+	if len(lists.Denylist) > 0 {
+		denylist, err := client.GetDenylist(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get denylist: %w", err)
+		}
+		remote := make(map[string]bool, len(denylist))
+		for _, e := range denylist {
+			remote[e.ID] = e.Active
+		}
+		d.domains("denylist", lists.Denylist, remote, spec.PreserveUnmanagedEntries)
+	}
+
+	if len(lists.Allowlist) > 0 {
+		allowlist, err := client.GetAllowlist(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allowlist: %w", err)
+		}
+		remote := make(map[string]bool, len(allowlist))
+		for _, e := range allowlist {
+			remote[e.ID] = e.Active
+		}
+		d.domains("allowlist", lists.Allowlist, remote, spec.PreserveUnmanagedEntries)
+	}
+
+	if len(lists.TLDs) > 0 {
+		tlds, err := client.GetSecurityTLDs(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get security TLDs: %w", err)
+		}
+		remoteIDs := make([]string, 0, len(tlds))
+		for _, t := range tlds {
+			remoteIDs = append(remoteIDs, t.ID)
+		}
+		d.ids("blockedTLDs", lists.TLDs, remoteIDs)
+	}
+
+	if lists.Rewrites != nil {
+		rewrites, err := client.GetRewrites(ctx, profileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rewrites: %w", err)
+		}
+		desired := make([]string, 0, len(lists.Rewrites))
+		for _, e := range lists.Rewrites {
+			desired = append(desired, e.Name+" -> "+e.Content)
+		}
+		remoteIDs := make([]string, 0, len(rewrites))
+		for _, rw := range rewrites {
+			remoteIDs = append(remoteIDs, rw.Name+" -> "+rw.Content)
+		}
+		d.ids("rewrites", desired, remoteIDs)
+	}
+
+	return d.summary(), nil
+}
+
+// formatDriftMessage summarizes drift for the Drifted condition message
+func formatDriftMessage(summary *nextdnsv1alpha1.DriftSummary) string {
+	return fmt.Sprintf("Remote profile differs in %s", strings.Join(summary.Sections, ", "))
+}
+
+func sortedUnique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// entryDomains returns the sorted, de-duplicated domains of the given entries
+func entryDomains(entries []nextdns.DomainEntry) []string {
+	domains := make([]string, 0, len(entries))
+	for _, e := range entries {
+		domains = append(domains, e.Domain)
+	}
+	return sortedUnique(domains)
+}
@@ -0,0 +1,369 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+	"github.com/jacaudi/nextdns-operator/internal/perfbudget"
+)
+
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+	mockNDS := nextdns.NewMockClient()
+
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Security: &nextdnsv1alpha1.SecuritySpec{},
+		Privacy: &nextdnsv1alpha1.PrivacySpec{
+			Blocklists: []nextdnsv1alpha1.BlocklistEntry{{ID: "nextdns-recommended"}},
+		},
+	}
+	lists := &ResolvedLists{
+		Denylist: []nextdns.DomainEntry{
+			{Domain: "ads.example.com", Active: true},
+			{Domain: "paused.example.com", Active: false},
+		},
+		Rewrites: []nextdns.RewriteEntry{{Name: "nas.home", Content: "192.168.1.10"}},
+	}
+
+	// Apply the desired state, then verify nothing is reported
+	require.NoError(t, mockNDS.UpdateSecurity(ctx, "abc123", desiredSecurityConfig(spec.Security)))
+	require.NoError(t, mockNDS.UpdatePrivacy(ctx, "abc123", desiredPrivacyConfig(spec.Privacy)))
+	require.NoError(t, mockNDS.SyncPrivacyBlocklists(ctx, "abc123", desiredPrivacyBlocklists(spec.Privacy)))
+	require.NoError(t, mockNDS.SyncDenylist(ctx, "abc123", lists.Denylist, nextdns.ListSyncOptions{}))
+	_, err := mockNDS.SyncRewrites(ctx, "abc123", lists.Rewrites)
+	require.NoError(t, err)
+
+	drift, err := detectDrift(ctx, mockNDS, "abc123", spec, lists)
+	require.NoError(t, err)
+	assert.Nil(t, drift)
+
+	// Simulate edits in the NextDNS dashboard
+	mockNDS.Security["abc123"].Nrd = true
+	require.NoError(t, mockNDS.SyncDenylist(ctx, "abc123", []nextdns.DomainEntry{
+		{Domain: "paused.example.com", Active: true},
+		{Domain: "manual.example.com", Active: true},
+	}, nextdns.ListSyncOptions{}))
+	_, err = mockNDS.SyncRewrites(ctx, "abc123", nil)
+	require.NoError(t, err)
+
+	drift, err = detectDrift(ctx, mockNDS, "abc123", spec, lists)
+	require.NoError(t, err)
+	require.NotNil(t, drift)
+
+	assert.Equal(t, []string{"security", "denylist", "rewrites"}, drift.Sections)
+	assert.Equal(t, []string{
+		"security: nrd is true, want false",
+		"denylist: ads.example.com is missing",
+		"denylist: paused.example.com active is true, want false",
+		"denylist: manual.example.com is not in the desired state",
+		"rewrites: nas.home -> 192.168.1.10 is missing",
+	}, drift.Differences)
+	assert.False(t, drift.Corrected)
+}
+
+func TestDetectDrift_SkipsUnmanagedSections(t *testing.T) {
+	ctx := context.Background()
+	mockNDS := nextdns.NewMockClient()
+	require.NoError(t, mockNDS.SyncAllowlist(ctx, "abc123", []nextdns.DomainEntry{{Domain: "manual.example.com", Active: true}}, nextdns.ListSyncOptions{}))
+
+	drift, err := detectDrift(ctx, mockNDS, "abc123", &nextdnsv1alpha1.NextDNSProfileSpec{}, &ResolvedLists{})
+	require.NoError(t, err)
+	assert.Nil(t, drift)
+	assert.False(t, mockNDS.WasMethodCalled("GetSecurity"))
+	assert.False(t, mockNDS.WasMethodCalled("GetAllowlist"))
+	assert.False(t, mockNDS.WasMethodCalled("GetRewrites"))
+}
+
+func TestDriftRecorder_Truncates(t *testing.T) {
+	d := &driftRecorder{}
+	for i := 0; i < maxDriftDifferences+5; i++ {
+		d.add("denylist", "domain%d.example.com is missing", i)
+	}
+
+	summary := d.summary()
+	require.NotNil(t, summary)
+	assert.Equal(t, []string{"denylist"}, summary.Sections)
+	assert.Len(t, summary.Differences, maxDriftDifferences+1)
+	assert.Equal(t, "... and 5 more", summary.Differences[maxDriftDifferences])
+}
+
+func TestDesiredConfigHash(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{Name: "Home"}
+	lists := &ResolvedLists{Denylist: []nextdns.DomainEntry{{Domain: "ads.example.com", Active: true}}}
+
+	h1, err := desiredConfigHash(spec, lists)
+	require.NoError(t, err)
+
+	// Fields that are not synced do not change the hash
+	spec.DriftPolicy = nextdnsv1alpha1.DriftPolicyReportOnly
+	spec.ConfigMapRef = &nextdnsv1alpha1.ConfigMapRef{Enabled: true}
+	h2, err := desiredConfigHash(spec, lists)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	// A change in a referenced list does
+	lists.Denylist = append(lists.Denylist, nextdns.DomainEntry{Domain: "more.example.com", Active: true})
+	h3, err := desiredConfigHash(spec, lists)
+	require.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+}
+
+func TestReconcile_DriftPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     nextdnsv1alpha1.DriftPolicy
+		wantReason string
+		wantNRD    bool
+	}{
+		{name: "correct", policy: nextdnsv1alpha1.DriftPolicyCorrect, wantReason: "DriftCorrected", wantNRD: false},
+		{name: "report only", policy: nextdnsv1alpha1.DriftPolicyReportOnly, wantReason: "DriftDetected", wantNRD: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := newTestScheme()
+			ctx := context.Background()
+
+			profile := &nextdnsv1alpha1.NextDNSProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "drift-profile",
+					Namespace:  "default",
+					Finalizers: []string{FinalizerName},
+				},
+				Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+					Name:           "Drift Profile",
+					ProfileID:      "abc123",
+					AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+					DriftPolicy:    tt.policy,
+					CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+					Security:       &nextdnsv1alpha1.SecuritySpec{NRD: ptr.To(false)},
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+				Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+			}
+
+			mockNDS := nextdns.NewMockClient()
+			mockNDS.SetProfile("abc123", "Drift Profile", "abc123.dns.nextdns.io")
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(profile, secret).
+				WithStatusSubresource(profile).
+				Build()
+
+			reconciler := &NextDNSProfileReconciler{
+				Client:     fakeClient,
+				Scheme:     scheme,
+				SyncPeriod: 5 * time.Minute,
+				ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+					return mockNDS, nil
+				},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "drift-profile", Namespace: "default"}}
+
+			// First sync applies the desired state
+			_, err := reconciler.Reconcile(ctx, req)
+			require.NoError(t, err)
+
+			var updated nextdnsv1alpha1.NextDNSProfile
+			require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+			assert.NotEmpty(t, updated.Status.AppliedConfigHash)
+			cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDrifted)
+			require.NotNil(t, cond)
+			assert.Equal(t, "InSync", cond.Reason)
+
+			// Someone enables NRD in the dashboard
+			mockNDS.Security["abc123"].Nrd = true
+			updatesBefore := mockNDS.GetCallCount("UpdateSecurity")
+
+			_, err = reconciler.Reconcile(ctx, req)
+			require.NoError(t, err)
+
+			require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+			cond = meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDrifted)
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionTrue, cond.Status)
+			assert.Equal(t, tt.wantReason, cond.Reason)
+			assert.Contains(t, cond.Message, "security")
+
+			require.NotNil(t, updated.Status.DriftSummary)
+			assert.Equal(t, []string{"security: nrd is true, want false"}, updated.Status.DriftSummary.Differences)
+			assert.Equal(t, tt.policy == nextdnsv1alpha1.DriftPolicyCorrect, updated.Status.DriftSummary.Corrected)
+
+			assert.Equal(t, tt.wantNRD, mockNDS.Security["abc123"].Nrd, fmt.Sprintf("remote NRD after %s", tt.policy))
+			assert.Equal(t, tt.policy == nextdnsv1alpha1.DriftPolicyCorrect, mockNDS.GetCallCount("UpdateSecurity") > updatesBefore)
+		})
+	}
+}
+
+func TestDetectDrift_PreserveUnmanagedEntries(t *testing.T) {
+	ctx := context.Background()
+	mockNDS := nextdns.NewMockClient()
+	require.NoError(t, mockNDS.SyncDenylist(ctx, "abc123", []nextdns.DomainEntry{
+		{Domain: "ads.example.com", Active: true},
+		{Domain: "manual.example.com", Active: true},
+	}, nextdns.ListSyncOptions{}))
+
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{PreserveUnmanagedEntries: true}
+	lists := &ResolvedLists{Denylist: []nextdns.DomainEntry{{Domain: "ads.example.com", Active: true}}}
+
+	drift, err := detectDrift(ctx, mockNDS, "abc123", spec, lists)
+	require.NoError(t, err)
+	assert.Nil(t, drift)
+}
+
+func TestReconcile_PreserveUnmanagedEntries(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "preserve-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:                     "Preserve Profile",
+			ProfileID:                "abc123",
+			AdoptionPolicy:           nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef:           nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			PreserveUnmanagedEntries: true,
+			Denylist: []nextdnsv1alpha1.DomainEntry{
+				{Domain: "ads.example.com", Active: ptr.To(true)},
+				{Domain: "old.example.com", Active: ptr.To(true)},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Preserve Profile", "abc123.dns.nextdns.io")
+	require.NoError(t, mockNDS.AddDenylistEntry(ctx, "abc123", "manual.example.com", true))
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "preserve-profile", Namespace: "default"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.ManagedEntries)
+	assert.Equal(t, []string{"ads.example.com", "old.example.com"}, updated.Status.ManagedEntries.Denylist)
+
+	// Drop old.example.com from the spec; the dashboard entry must survive
+	updated.Spec.Denylist = updated.Spec.Denylist[:1]
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	denylist, err := mockNDS.GetDenylist(ctx, "abc123")
+	require.NoError(t, err)
+	var domains []string
+	for _, e := range denylist {
+		domains = append(domains, e.ID)
+	}
+	assert.ElementsMatch(t, []string{"manual.example.com", "ads.example.com"}, domains)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, []string{"ads.example.com"}, updated.Status.ManagedEntries.Denylist)
+}
+
+// largeDrift returns n desired denylist entries and a remote denylist that
+// lacks the first tenth of them and holds n/10 entries the spec dropped
+func largeDrift(n int) ([]nextdns.DomainEntry, map[string]bool) {
+	desired := resolveDomainList(largeDomainEntries(n)).Domains
+	remote := make(map[string]bool, n)
+	for _, e := range desired[n/10:] {
+		remote[e.Domain] = e.Active
+	}
+	for i := range n / 10 {
+		remote[fmt.Sprintf("dropped-%d.example.com", i)] = true
+	}
+	return desired, remote
+}
+
+func BenchmarkDriftRecorder_Domains(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		desired, remote := largeDrift(n)
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				d := &driftRecorder{}
+				d.domains("denylist", desired, remote, false)
+			}
+		})
+	}
+}
+
+func BenchmarkDesiredConfigHash(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		desired, _ := largeDrift(n)
+		spec := &nextdnsv1alpha1.NextDNSProfileSpec{Name: "home"}
+		lists := &ResolvedLists{Denylist: desired, Allowlist: desired[:n/10]}
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := desiredConfigHash(spec, lists); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDriftRecorder_PerformanceBudget(t *testing.T) {
+	desired, remote := largeDrift(100_000)
+
+	d := &driftRecorder{}
+	d.domains("denylist", desired, remote, false)
+	require.Len(t, d.differences, 20_000)
+
+	perfbudget.Enforce(t, perfbudget.Budget{Time: 400 * time.Millisecond, Allocs: 120_000}, func() {
+		d := &driftRecorder{}
+		d.domains("denylist", desired, remote, false)
+	})
+}
+
+func TestDesiredConfigHash_PerformanceBudget(t *testing.T) {
+	desired, _ := largeDrift(100_000)
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{Name: "home"}
+	lists := &ResolvedLists{Denylist: desired, Allowlist: desired[:10_000]}
+
+	perfbudget.Enforce(t, perfbudget.Budget{Time: 150 * time.Millisecond, Allocs: 50}, func() {
+		_, _ = desiredConfigHash(spec, lists)
+	})
+}
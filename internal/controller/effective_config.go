@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// EffectiveConfigKey is the ConfigMap key holding the effective configuration
+	EffectiveConfigKey = "profile.yaml"
+
+	// EffectiveConfigJSONKey holds the effective configuration with format json
+	EffectiveConfigJSONKey = "profile.json"
+
+	// AnnotationConfigHash records the applied config hash on the exported ConfigMap
+	AnnotationConfigHash = "nextdns.io/config-hash"
+
+	// AnnotationActiveOverlay records the overlay applied by the exported configuration
+	AnnotationActiveOverlay = "nextdns.io/active-overlay"
+)
+
+// buildEffectiveConfig renders what syncWithNextDNS applies for spec, with the
+// active overlay merged, list references resolved and defaults filled in. The
+// result has the same shape as status.observedConfig so the two can be diffed.
+// Sections the spec leaves unset are omitted because the sync does not touch them.
+func buildEffectiveConfig(spec *nextdnsv1alpha1.NextDNSProfileSpec, lists *ResolvedLists) *nextdnsv1alpha1.ObservedConfig {
+	cfg := &nextdnsv1alpha1.ObservedConfig{
+		Name:        spec.Name,
+		BlockedTLDs: lists.TLDs,
+	}
+
+	if spec.Security != nil {
+		s := desiredSecurityConfig(spec.Security)
+		cfg.Security = &nextdnsv1alpha1.ObservedSecurity{
+			AIThreatDetection:       s.AIThreatDetection,
+			ThreatIntelligenceFeeds: s.ThreatIntelligenceFeeds,
+			GoogleSafeBrowsing:      s.GoogleSafeBrowsing,
+			Cryptojacking:           s.Cryptojacking,
+			DNSRebinding:            s.DNSRebinding,
+			IDNHomographs:           s.IDNHomographs,
+			Typosquatting:           s.Typosquatting,
+			DGA:                     s.DGA,
+			NRD:                     s.NRD,
+			DDNS:                    s.DDNS,
+			Parking:                 s.Parking,
+			CSAM:                    s.CSAM,
+		}
+	}
+
+	if spec.Privacy != nil {
+		p := desiredPrivacyConfig(spec.Privacy)
+		cfg.Privacy = &nextdnsv1alpha1.ObservedPrivacy{
+			DisguisedTrackers: p.DisguisedTrackers,
+			AllowAffiliate:    p.AllowAffiliate,
+		}
+		for _, id := range desiredPrivacyBlocklists(spec.Privacy) {
+			cfg.Privacy.Blocklists = append(cfg.Privacy.Blocklists, nextdnsv1alpha1.ObservedBlocklistEntry{ID: id})
+		}
+		for _, id := range desiredPrivacyNatives(spec.Privacy) {
+			cfg.Privacy.Natives = append(cfg.Privacy.Natives, nextdnsv1alpha1.ObservedNativeEntry{ID: id})
+		}
+	}
+
+	if spec.ParentalControl != nil {
+		pc := desiredParentalControlConfig(spec.ParentalControl)
+		cfg.ParentalControl = &nextdnsv1alpha1.ObservedParentalControl{
+			SafeSearch:            pc.SafeSearch,
+			YouTubeRestrictedMode: pc.YouTubeRestrictedMode,
+			BlockBypass:           pc.BlockBypass,
+		}
+		for _, id := range pc.Categories {
+			cfg.ParentalControl.Categories = append(cfg.ParentalControl.Categories, nextdnsv1alpha1.ObservedCategoryEntry{ID: id, Active: true})
+		}
+		for _, id := range pc.Services {
+			cfg.ParentalControl.Services = append(cfg.ParentalControl.Services, nextdnsv1alpha1.ObservedServiceEntry{ID: id, Active: true})
+		}
+	}
+
+	if spec.Settings != nil {
+		s := desiredSettingsConfig(spec.Settings)
+		cfg.Settings = &nextdnsv1alpha1.ObservedSettings{
+			Logs: &nextdnsv1alpha1.ObservedLogs{
+				Enabled:       s.LogsEnabled,
+				Retention:     s.LogRetention,
+				Location:      s.Location,
+				LogClientsIPs: s.LogClientsIPs,
+				LogDomains:    s.LogDomains,
+			},
+			BlockPage: &nextdnsv1alpha1.ObservedBlockPage{Enabled: s.BlockPageEnable},
+			Performance: &nextdnsv1alpha1.ObservedPerformance{
+				ECS:             s.Ecs,
+				CacheBoost:      s.CacheBoost,
+				CNAMEFlattening: s.CnameFlattening,
+			},
+			Web3: s.Web3,
+			BAV:  s.BAV,
+		}
+	}
+
+	for _, e := range lists.Allowlist {
+		cfg.Allowlist = append(cfg.Allowlist, nextdnsv1alpha1.ObservedDomainEntry{Domain: e.Domain, Active: e.Active})
+	}
+	for _, e := range lists.Denylist {
+		cfg.Denylist = append(cfg.Denylist, nextdnsv1alpha1.ObservedDomainEntry{Domain: e.Domain, Active: e.Active})
+	}
+	for _, e := range lists.Rewrites {
+		cfg.Rewrites = append(cfg.Rewrites, nextdnsv1alpha1.ObservedRewriteEntry{Name: e.Name, Content: e.Content})
+	}
+
+	return cfg
+}
+
+// EffectivePolicy returns the settings a sync applies for spec, with the
+// active overlay merged and defaults filled in. List references are not
+// resolved, so the allowlist, denylist, rewrites and blocked TLDs are left
+// empty; status.aggregatedCounts holds their totals.
+func EffectivePolicy(spec *nextdnsv1alpha1.NextDNSProfileSpec) (*nextdnsv1alpha1.ObservedConfig, error) {
+	merged, err := effectiveSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return buildEffectiveConfig(merged, &ResolvedLists{}), nil
+}
+
+// marshalEffectiveConfig renders cfg as the ConfigMap data of format, which
+// is "json" or "yaml" (the default)
+func marshalEffectiveConfig(cfg *nextdnsv1alpha1.ObservedConfig, format string) (map[string]string, error) {
+	if format == "json" {
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{EffectiveConfigJSONKey: string(data) + "\n"}, nil
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{EffectiveConfigKey: string(data)}, nil
+}
+
+// reconcileEffectiveConfigMap writes the effective configuration to a
+// ConfigMap after a successful sync and records its name in status
+func (r *NextDNSProfileReconciler) reconcileEffectiveConfigMap(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, lists *ResolvedLists) error {
+	export := profile.Spec.EffectiveConfigExport
+	if export == nil || !export.Enabled {
+		profile.Status.EffectiveConfigMap = ""
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	configMapName := export.Name
+	if configMapName == "" {
+		configMapName = profile.Name + "-effective"
+	}
+
+	data, err := marshalEffectiveConfig(buildEffectiveConfig(&profile.Spec, lists), export.Format)
+	if err != nil {
+		return fmt.Errorf("failed to marshal effective config: %w", err)
+	}
+
+	annotations := map[string]string{
+		AnnotationConfigHash: profile.Status.AppliedConfigHash,
+	}
+	if profile.Spec.ActiveOverlay != "" {
+		annotations[AnnotationActiveOverlay] = profile.Spec.ActiveOverlay
+	}
+
+	existing := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: profile.Namespace}, existing)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get effective config ConfigMap: %w", err)
+		}
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        configMapName,
+				Namespace:   profile.Namespace,
+				Labels:      r.ResourceLabels.merge(nil),
+				Annotations: annotations,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(profile, nextdnsv1alpha1.GroupVersion.WithKind("NextDNSProfile")),
+				},
+			},
+			Data: data,
+		}
+		if err := r.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("failed to create effective config ConfigMap: %w", err)
+		}
+		logger.Info("Created ConfigMap with effective configuration", "configMap", configMapName)
+		profile.Status.EffectiveConfigMap = configMapName
+		return nil
+	}
+
+	existing.Data = data
+	r.ResourceLabels.set(existing)
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	delete(existing.Annotations, AnnotationActiveOverlay)
+	for k, v := range annotations {
+		existing.Annotations[k] = v
+	}
+	if len(existing.OwnerReferences) == 0 {
+		existing.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(profile, nextdnsv1alpha1.GroupVersion.WithKind("NextDNSProfile")),
+		}
+	}
+
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update effective config ConfigMap: %w", err)
+	}
+	logger.V(1).Info("Updated ConfigMap with effective configuration", "configMap", configMapName)
+	profile.Status.EffectiveConfigMap = configMapName
+	return nil
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestBuildEffectiveConfig(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Name:     "Effective",
+		Security: &nextdnsv1alpha1.SecuritySpec{NRD: ptr.To(true)},
+		Privacy: &nextdnsv1alpha1.PrivacySpec{
+			Blocklists: []nextdnsv1alpha1.BlocklistEntry{
+				{ID: "nextdns-recommended"},
+				{ID: "oisd", Active: ptr.To(false)},
+			},
+		},
+		ParentalControl: &nextdnsv1alpha1.ParentalControlSpec{
+			Categories: []nextdnsv1alpha1.CategoryEntry{{ID: "gambling"}},
+		},
+		Settings: &nextdnsv1alpha1.SettingsSpec{
+			Logs: &nextdnsv1alpha1.LogsSpec{Retention: "7d"},
+		},
+	}
+	lists := &ResolvedLists{
+		Denylist: []nextdns.DomainEntry{{Domain: "ads.example.com", Active: true}},
+		TLDs:     []string{"zip"},
+		Rewrites: []nextdns.RewriteEntry{{Name: "nas.home", Content: "10.0.0.5"}},
+	}
+
+	cfg := buildEffectiveConfig(spec, lists)
+
+	assert.Equal(t, "Effective", cfg.Name)
+	// Unset security fields resolve to the operator defaults
+	require.NotNil(t, cfg.Security)
+	assert.True(t, cfg.Security.NRD)
+	assert.True(t, cfg.Security.ThreatIntelligenceFeeds)
+	assert.False(t, cfg.Security.DDNS)
+	// Inactive blocklists are not applied, so they are not exported
+	require.NotNil(t, cfg.Privacy)
+	assert.True(t, cfg.Privacy.DisguisedTrackers)
+	assert.Equal(t, []nextdnsv1alpha1.ObservedBlocklistEntry{{ID: "nextdns-recommended"}}, cfg.Privacy.Blocklists)
+	require.NotNil(t, cfg.ParentalControl)
+	assert.Equal(t, []nextdnsv1alpha1.ObservedCategoryEntry{{ID: "gambling", Active: true}}, cfg.ParentalControl.Categories)
+	require.NotNil(t, cfg.Settings)
+	assert.Equal(t, 604800, cfg.Settings.Logs.Retention)
+	assert.True(t, cfg.Settings.Performance.CacheBoost)
+	assert.Equal(t, []nextdnsv1alpha1.ObservedDomainEntry{{Domain: "ads.example.com", Active: true}}, cfg.Denylist)
+	assert.Empty(t, cfg.Allowlist)
+	assert.Equal(t, []string{"zip"}, cfg.BlockedTLDs)
+	assert.Equal(t, []nextdnsv1alpha1.ObservedRewriteEntry{{Name: "nas.home", Content: "10.0.0.5"}}, cfg.Rewrites)
+}
+
+func TestBuildEffectiveConfig_OmitsUnsetSections(t *testing.T) {
+	cfg := buildEffectiveConfig(&nextdnsv1alpha1.NextDNSProfileSpec{Name: "Bare"}, &ResolvedLists{})
+
+	assert.Equal(t, "Bare", cfg.Name)
+	assert.Nil(t, cfg.Security)
+	assert.Nil(t, cfg.Privacy)
+	assert.Nil(t, cfg.ParentalControl)
+	assert.Nil(t, cfg.Settings)
+}
+
+func TestReconcile_EffectiveConfigExport(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "export-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:                  "Export Profile",
+			ProfileID:             "abc123",
+			AdoptionPolicy:        nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef:        nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Denylist:              []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+			EffectiveConfigExport: &nextdnsv1alpha1.EffectiveConfigExport{Enabled: true},
+			Overlays: []nextdnsv1alpha1.ProfileOverlay{
+				{
+					Name:     "strict",
+					Security: &nextdnsv1alpha1.SecuritySpec{NRD: ptr.To(true)},
+				},
+			},
+			ActiveOverlay: "strict",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Export Profile", "abc123.dns.nextdns.io")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "export-profile", Namespace: "default"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, "export-profile-effective", updated.Status.EffectiveConfigMap)
+
+	var cm corev1.ConfigMap
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "export-profile-effective", Namespace: "default"}, &cm))
+	assert.Equal(t, updated.Status.AppliedConfigHash, cm.Annotations[AnnotationConfigHash])
+	assert.Equal(t, "strict", cm.Annotations[AnnotationActiveOverlay])
+	require.Len(t, cm.OwnerReferences, 1)
+	assert.Equal(t, "export-profile", cm.OwnerReferences[0].Name)
+
+	var exported nextdnsv1alpha1.ObservedConfig
+	require.NoError(t, yaml.Unmarshal([]byte(cm.Data[EffectiveConfigKey]), &exported))
+	assert.Equal(t, "Export Profile", exported.Name)
+	require.NotNil(t, exported.Security)
+	assert.True(t, exported.Security.NRD, "overlay security section should be exported")
+	assert.Equal(t, []nextdnsv1alpha1.ObservedDomainEntry{{Domain: "ads.example.com", Active: true}}, exported.Denylist)
+
+	// Switching back to the base spec drops the overlay from the export
+	updated.Spec.ActiveOverlay = ""
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "export-profile-effective", Namespace: "default"}, &cm))
+	assert.NotContains(t, cm.Annotations, AnnotationActiveOverlay)
+	exported = nextdnsv1alpha1.ObservedConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte(cm.Data[EffectiveConfigKey]), &exported))
+	assert.Nil(t, exported.Security)
+
+	// The json format replaces the YAML key
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.EffectiveConfigExport.Format = "json"
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "export-profile-effective", Namespace: "default"}, &cm))
+	assert.NotContains(t, cm.Data, EffectiveConfigKey)
+	exported = nextdnsv1alpha1.ObservedConfig{}
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[EffectiveConfigJSONKey]), &exported))
+	assert.Equal(t, "Export Profile", exported.Name)
+
+	// Disabling the export clears the status reference
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.EffectiveConfigExport.Enabled = false
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Empty(t, updated.Status.EffectiveConfigMap)
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// EndpointDirectoryConfigMapName is the ConfigMap the endpoint directory
+	// is published to
+	EndpointDirectoryConfigMapName = "nextdns-endpoints"
+
+	// EndpointDirectoryEndpointsKey holds the ordered endpoints of every
+	// ready NextDNSCoreDNS
+	EndpointDirectoryEndpointsKey = "endpoints.json"
+
+	// EndpointDirectoryResolversKey holds the ordered plain DNS resolver
+	// addresses, one per line
+	EndpointDirectoryResolversKey = "resolvers"
+
+	// LabelFailureDomain sets the failure domain of a NextDNSCoreDNS in the
+	// endpoint directory, e.g. a site name. Defaults to the zones its ready
+	// pods run in.
+	LabelFailureDomain = "nextdns.io/failure-domain"
+
+	// AnnotationEndpointsUpdated records when the endpoint directory was last
+	// published
+	AnnotationEndpointsUpdated = "nextdns.io/endpoints-updated"
+
+	// DefaultEndpointDirectoryInterval is the default period between endpoint
+	// directory updates
+	DefaultEndpointDirectoryInterval = time.Minute
+)
+
+// DirectoryEndpoint is a DNS endpoint of a ready NextDNSCoreDNS in the
+// published endpoint directory
+type DirectoryEndpoint struct {
+	// Instance is the namespace/name of the NextDNSCoreDNS
+	Instance string `json:"instance"`
+
+	// ProfileID is the NextDNS profile the endpoint resolves with
+	ProfileID string `json:"profileID,omitempty"`
+
+	// FailureDomain is the failure domain of the instance; empty when unknown
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	nextdnsv1alpha1.DNSEndpoint `json:",inline"`
+}
+
+// EndpointDirectoryPublisher periodically publishes the DNS endpoints of
+// every ready NextDNSCoreDNS to a ConfigMap, ordered so that consecutive
+// instances are in different failure domains. Clients programmed with the
+// first few resolvers of the list keep resolving when a zone or site fails.
+type EndpointDirectoryPublisher struct {
+	Client         client.Client
+	Namespace      string
+	Interval       time.Duration
+	ResourceLabels ResourceLabels
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (p *EndpointDirectoryPublisher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start publishes the endpoint directory every Interval until ctx is done
+func (p *EndpointDirectoryPublisher) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("endpoint-directory")
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(ctx); err != nil {
+			logger.Error(err, "Failed to publish endpoint directory")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish lists the ready NextDNSCoreDNS resources and writes their
+// endpoints to the endpoint directory ConfigMap
+func (p *EndpointDirectoryPublisher) Publish(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("endpoint-directory")
+
+	var list nextdnsv1alpha1.NextDNSCoreDNSList
+	if err := p.Client.List(ctx, &list); err != nil {
+		return fmt.Errorf("failed to list NextDNSCoreDNS resources: %w", err)
+	}
+
+	endpoints := directoryEndpoints(list.Items)
+	encoded, err := json.MarshalIndent(endpoints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal endpoint directory: %w", err)
+	}
+
+	var resolvers strings.Builder
+	seen := map[string]bool{}
+	for _, e := range endpoints {
+		if e.Protocol != string(corev1.ProtocolUDP) || seen[e.IP] {
+			continue
+		}
+		seen[e.IP] = true
+		resolvers.WriteString(e.IP + "\n")
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: EndpointDirectoryConfigMapName, Namespace: p.Namespace},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, p.Client, configMap, func() error {
+		p.ResourceLabels.set(configMap)
+		if configMap.Annotations == nil {
+			configMap.Annotations = map[string]string{}
+		}
+		configMap.Annotations[AnnotationEndpointsUpdated] = time.Now().UTC().Format(time.RFC3339)
+		configMap.Data = map[string]string{
+			EndpointDirectoryEndpointsKey: string(encoded) + "\n",
+			EndpointDirectoryResolversKey: resolvers.String(),
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write endpoint directory ConfigMap: %w", err)
+	}
+
+	logger.V(1).Info("Published endpoint directory", "endpoints", len(endpoints), "resolvers", len(seen))
+	return nil
+}
+
+// failureDomain returns the failure domain of coreDNS: its failure domain
+// label, or else the sorted zones of its ready pods joined by commas
+func failureDomain(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	if domain := coreDNS.Labels[LabelFailureDomain]; domain != "" {
+		return domain
+	}
+	zones := map[string]bool{}
+	for _, p := range coreDNS.Status.Placement {
+		if p.Zone != "" && p.ReadyPods > 0 {
+			zones[p.Zone] = true
+		}
+	}
+	sorted := make([]string, 0, len(zones))
+	for zone := range zones {
+		sorted = append(sorted, zone)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// directoryEndpoints returns the endpoints of the ready instances. Instances
+// are grouped by failure domain and taken from each domain in turn, so the
+// first instances of the list span as many domains as possible; within a
+// domain they are ordered by namespace and name, and each instance keeps
+// the order of its status endpoints.
+func directoryEndpoints(items []nextdnsv1alpha1.NextDNSCoreDNS) []DirectoryEndpoint {
+	domains := map[string][]*nextdnsv1alpha1.NextDNSCoreDNS{}
+	for i := range items {
+		coreDNS := &items[i]
+		if !coreDNS.Status.Ready || len(coreDNS.Status.Endpoints) == 0 {
+			continue
+		}
+		domain := failureDomain(coreDNS)
+		domains[domain] = append(domains[domain], coreDNS)
+	}
+
+	names := make([]string, 0, len(domains))
+	for domain, instances := range domains {
+		names = append(names, domain)
+		sort.Slice(instances, func(i, j int) bool {
+			return client.ObjectKeyFromObject(instances[i]).String() < client.ObjectKeyFromObject(instances[j]).String()
+		})
+	}
+	sort.Strings(names)
+
+	result := []DirectoryEndpoint{}
+	for round := 0; ; round++ {
+		added := false
+		for _, domain := range names {
+			if round >= len(domains[domain]) {
+				continue
+			}
+			added = true
+			coreDNS := domains[domain][round]
+			for _, e := range coreDNS.Status.Endpoints {
+				result = append(result, DirectoryEndpoint{
+					Instance:      client.ObjectKeyFromObject(coreDNS).String(),
+					ProfileID:     coreDNS.Status.ProfileID,
+					FailureDomain: domain,
+					DNSEndpoint:   e,
+				})
+			}
+		}
+		if !added {
+			return result
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestEndpointDirectoryPublisher_Publish(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	newCoreDNS := func(name, ip string, ready bool, zones ...string) *nextdnsv1alpha1.NextDNSCoreDNS {
+		coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: nextdnsv1alpha1.NextDNSCoreDNSStatus{
+				ProfileID: "abc123",
+				Ready:     ready,
+				Endpoints: []nextdnsv1alpha1.DNSEndpoint{
+					{IP: ip, Port: 53, Protocol: "UDP"},
+					{IP: ip, Port: 53, Protocol: "TCP"},
+				},
+			},
+		}
+		for _, zone := range zones {
+			coreDNS.Status.Placement = append(coreDNS.Status.Placement,
+				nextdnsv1alpha1.PodPlacement{Node: "node-" + zone, Zone: zone, ReadyPods: 1})
+		}
+		return coreDNS
+	}
+	site := newCoreDNS("site", "192.168.1.53", true)
+	site.Labels = map[string]string{LabelFailureDomain: "branch"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newCoreDNS("dns-a1", "10.0.0.1", true, "a"),
+			newCoreDNS("dns-a2", "10.0.0.2", true, "a"),
+			newCoreDNS("dns-b", "10.0.0.3", true, "b"),
+			newCoreDNS("dns-down", "10.0.0.4", false, "b"),
+			site,
+		).
+		Build()
+
+	publisher := &EndpointDirectoryPublisher{
+		Client:         fakeClient,
+		Namespace:      "nextdns-system",
+		ResourceLabels: ResourceLabels{"team": "dns"},
+	}
+	require.NoError(t, publisher.Publish(ctx))
+
+	var cm corev1.ConfigMap
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: EndpointDirectoryConfigMapName, Namespace: "nextdns-system"}, &cm))
+	assert.Equal(t, "dns", cm.Labels["team"])
+	assert.NotEmpty(t, cm.Annotations[AnnotationEndpointsUpdated])
+
+	// One instance per failure domain before the second of any domain
+	assert.Equal(t, "10.0.0.1\n10.0.0.3\n192.168.1.53\n10.0.0.2\n", cm.Data[EndpointDirectoryResolversKey])
+
+	var endpoints []DirectoryEndpoint
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[EndpointDirectoryEndpointsKey]), &endpoints))
+	require.Len(t, endpoints, 8)
+	assert.Equal(t, DirectoryEndpoint{
+		Instance:      "default/dns-a1",
+		ProfileID:     "abc123",
+		FailureDomain: "a",
+		DNSEndpoint:   nextdnsv1alpha1.DNSEndpoint{IP: "10.0.0.1", Port: 53, Protocol: "UDP"},
+	}, endpoints[0])
+	assert.Equal(t, "branch", endpoints[4].FailureDomain)
+	assert.Contains(t, cm.Data[EndpointDirectoryEndpointsKey], `"ip": "10.0.0.1"`)
+}
+
+func TestFailureDomain(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Status: nextdnsv1alpha1.NextDNSCoreDNSStatus{
+			Placement: []nextdnsv1alpha1.PodPlacement{
+				{Node: "n1", Zone: "b", ReadyPods: 1},
+				{Node: "n2", Zone: "a", ReadyPods: 2},
+				{Node: "n3", Zone: "a", ReadyPods: 1},
+				{Node: "n4", ReadyPods: 1},
+			},
+		},
+	}
+	assert.Equal(t, "a,b", failureDomain(coreDNS))
+
+	coreDNS.Labels = map[string]string{LabelFailureDomain: "site-1"}
+	assert.Equal(t, "site-1", failureDomain(coreDNS))
+
+	assert.Equal(t, "", failureDomain(&nextdnsv1alpha1.NextDNSCoreDNS{}))
+}
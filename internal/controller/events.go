@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the events the controllers emit besides condition changes
+const (
+	// EventReasonProfileCreated is emitted when a NextDNS profile is created
+	EventReasonProfileCreated = "ProfileCreated"
+
+	// EventReasonProfileAdopted is emitted when an existing NextDNS profile is
+	// adopted through spec.profileID
+	EventReasonProfileAdopted = "ProfileAdopted"
+
+	// EventReasonProfileDeleted is emitted when the NextDNS profile of a
+	// deleted NextDNSProfile is deleted
+	EventReasonProfileDeleted = "ProfileDeleted"
+
+	// EventReasonProfileRetained is emitted when the NextDNS profile of a
+	// deleted NextDNSProfile is kept by its deletion policy
+	EventReasonProfileRetained = "ProfileRetained"
+
+	// EventReasonProfileDeleteFailed is emitted when cleaning up the NextDNS
+	// profile of a deleted NextDNSProfile fails
+	EventReasonProfileDeleteFailed = "ProfileDeleteFailed"
+
+	// EventReasonDriftDetected is emitted when the remote profile was changed
+	// outside the operator
+	EventReasonDriftDetected = "DriftDetected"
+
+	// EventReasonWorkloadCreated is emitted when a CoreDNS Deployment or
+	// DaemonSet is created
+	EventReasonWorkloadCreated = "WorkloadCreated"
+
+	// EventReasonRolloutStarted is emitted when the pod template of a CoreDNS
+	// workload changes, rolling out new pods
+	EventReasonRolloutStarted = "RolloutStarted"
+
+	// EventReasonFallbackActivated is emitted when no primary upstream of a
+	// CoreDNS instance answers and its fallback profile takes over
+	EventReasonFallbackActivated = "FallbackActivated"
+
+	// EventReasonPrimaryRestored is emitted when the primary upstreams of a
+	// CoreDNS instance answer again after a fallback
+	EventReasonPrimaryRestored = "PrimaryRestored"
+
+	// EventReasonBreakGlassActivated is emitted when the break-glass
+	// annotation suspends blocking on a profile
+	EventReasonBreakGlassActivated = "BreakGlassActivated"
+
+	// EventReasonBreakGlassEnded is emitted when blocking is restored after a
+	// break-glass window expired or its annotation was removed
+	EventReasonBreakGlassEnded = "BreakGlassEnded"
+
+	// EventReasonBreakGlassRejected is emitted when the break-glass
+	// annotation holds an invalid duration
+	EventReasonBreakGlassRejected = "BreakGlassRejected"
+
+	// EventReasonOrphanDetected is emitted when a NextDNSAccount finds a
+	// profile whose NextDNSProfile was removed without deleting it
+	EventReasonOrphanDetected = "OrphanDetected"
+
+	// EventReasonOrphanDeleted is emitted when a NextDNSAccount deletes an
+	// orphaned profile
+	EventReasonOrphanDeleted = "OrphanDeleted"
+)
+
+// recordEvent emits an event for obj. A nil recorder, as in tests, emits
+// nothing.
+func recordEvent(recorder record.EventRecorder, obj runtime.Object, eventType, reason, messageFmt string, args ...any) {
+	if recorder == nil {
+		return
+	}
+	recorder.Event(obj, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// recordConditionEvent emits an event for a condition about to be set on
+// obj when it changes: a Warning carrying the reason and message when it
+// turns False or fails for another reason, and a Normal event when it turns
+// True. A failure retried on every reconcile is reported once.
+func recordConditionEvent(recorder record.EventRecorder, obj runtime.Object, conditions []metav1.Condition, condition metav1.Condition) {
+	previous := meta.FindStatusCondition(conditions, condition.Type)
+	switch condition.Status {
+	case metav1.ConditionFalse:
+		if previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != condition.Reason {
+			recordEvent(recorder, obj, corev1.EventTypeWarning, condition.Reason, "%s", condition.Message)
+		}
+	case metav1.ConditionTrue:
+		if previous == nil || previous.Status != metav1.ConditionTrue {
+			recordEvent(recorder, obj, corev1.EventTypeNormal, condition.Reason, "%s", condition.Message)
+		}
+	}
+}
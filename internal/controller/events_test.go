@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestRecordConditionEvent(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &NextDNSProfileReconciler{Recorder: recorder}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+	}
+
+	reconciler.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "SyncFailed", "API unreachable")
+	assert.Equal(t, []string{"Warning SyncFailed API unreachable"}, drainEvents(recorder))
+
+	reconciler.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "SyncFailed", "API unreachable")
+	assert.Empty(t, drainEvents(recorder), "a repeated failure is recorded once")
+
+	reconciler.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "InvalidCredentials", "bad API key")
+	assert.Equal(t, []string{"Warning InvalidCredentials bad API key"}, drainEvents(recorder))
+
+	reconciler.setCondition(profile, ConditionTypeReady, metav1.ConditionTrue, "Synced", "Profile synced")
+	assert.Equal(t, []string{"Normal Synced Profile synced"}, drainEvents(recorder))
+
+	reconciler.setCondition(profile, ConditionTypeReady, metav1.ConditionTrue, "Synced", "Profile synced")
+	assert.Empty(t, drainEvents(recorder))
+
+	reconciler.setCondition(profile, ConditionTypeSynced, metav1.ConditionFalse, "SyncFailed", "API unreachable")
+	assert.Empty(t, drainEvents(recorder), "only Ready changes are recorded for profiles")
+}
+
+func TestRecordEvent_NilRecorder(t *testing.T) {
+	assert.NotPanics(t, func() {
+		recordEvent(nil, &corev1.Secret{}, corev1.EventTypeNormal, "Reason", "message")
+	})
+}
+
+func TestSetDeletionBlockedCondition_RecordsOnce(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	list := &nextdnsv1alpha1.NextDNSAllowlist{}
+	refs := []nextdnsv1alpha1.ResourceReference{{Name: "home", Namespace: "default"}}
+
+	setDeletionBlockedCondition(recorder, list, &list.Status.Conditions, refs)
+	setDeletionBlockedCondition(recorder, list, &list.Status.Conditions, refs)
+
+	events := drainEvents(recorder)
+	assert.Len(t, events, 1)
+	assert.Contains(t, events[0], "Warning DeletionBlocked")
+}
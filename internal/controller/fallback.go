@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+const (
+	// fallbackProbeInterval is how often the primary upstreams are health
+	// checked while a fallback profile is set
+	fallbackProbeInterval = 2 * time.Minute
+
+	// upstreamProbeTimeout bounds the health check of one upstream
+	upstreamProbeTimeout = 3 * time.Second
+)
+
+// healthCheckQuery is a ". IN NS" query, the one the CoreDNS forward plugin
+// health check sends
+var healthCheckQuery = []byte{
+	0x4e, 0x44, // ID
+	0x00, 0x00, // flags
+	0x00, 0x01, // one question
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00,       // root name
+	0x00, 0x02, // type NS
+	0x00, 0x01, // class IN
+}
+
+// UpstreamProbeFunc health checks one upstream. upstream is host:port for
+// DoT and plain DNS and the URL for DoH; serverName is the TLS server name
+// for DoT.
+type UpstreamProbeFunc func(ctx context.Context, protocol, upstream, serverName string) error
+
+// DefaultUpstreamProbe sends the forward plugin's health check query to
+// upstream over protocol and succeeds on any DNS response
+func DefaultUpstreamProbe(ctx context.Context, protocol, upstream, serverName string) error {
+	switch protocol {
+	case coredns.ProtocolDoT:
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}}
+		conn, err := dialer.DialContext(ctx, "tcp", upstream)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = conn.Close() }()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		// DNS over a stream is prefixed with the message length
+		message := binary.BigEndian.AppendUint16(nil, uint16(len(healthCheckQuery)))
+		if _, err := conn.Write(append(message, healthCheckQuery...)); err != nil {
+			return err
+		}
+		length := make([]byte, 2)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		response := make([]byte, binary.BigEndian.Uint16(length))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return err
+		}
+		return checkHealthCheckResponse(response)
+
+	case coredns.ProtocolDoH:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstream, bytes.NewReader(healthCheckQuery))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/dns-message")
+		req.Header.Set("Accept", "application/dns-message")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		response, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+		if err != nil {
+			return err
+		}
+		return checkHealthCheckResponse(response)
+
+	default:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "udp", upstream)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = conn.Close() }()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		if _, err := conn.Write(healthCheckQuery); err != nil {
+			return err
+		}
+		response := make([]byte, 512)
+		n, err := conn.Read(response)
+		if err != nil {
+			return err
+		}
+		return checkHealthCheckResponse(response[:n])
+	}
+}
+
+// checkHealthCheckResponse checks that response answers healthCheckQuery.
+// Like the forward plugin, any response code counts as healthy.
+func checkHealthCheckResponse(response []byte) error {
+	if len(response) < 12 || !bytes.Equal(response[:2], healthCheckQuery[:2]) || response[2]&0x80 == 0 {
+		return fmt.Errorf("invalid DNS response")
+	}
+	return nil
+}
+
+// resolveFallbackProfile returns the fallback profile of coreDNS once it is
+// ready, and nil when none is set or it cannot take over yet. A fallback
+// that is not ready leaves the primary serving alone rather than failing
+// the instance.
+func (r *NextDNSCoreDNSReconciler) resolveFallbackProfile(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.NextDNSProfile {
+	ref := coreDNS.Spec.FallbackProfileRef
+	if ref == nil {
+		meta.RemoveStatusCondition(&coreDNS.Status.Conditions, ConditionTypeFallbackResolved)
+		return nil
+	}
+
+	fallback, err := profileresolver.Resolve(ctx, r.Client, *ref, coreDNS.Namespace)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to resolve fallback NextDNSProfile reference")
+		r.setCondition(coreDNS, ConditionTypeFallbackResolved, metav1.ConditionFalse, "ProfileNotFound", err.Error())
+		return nil
+	}
+	if !profileresolver.IsReady(fallback) || fallback.Status.ProfileID == "" {
+		r.setCondition(coreDNS, ConditionTypeFallbackResolved, metav1.ConditionFalse, "ProfileNotReady",
+			"Fallback profile is not ready; queries have no fallback until it is")
+		return nil
+	}
+
+	r.setCondition(coreDNS, ConditionTypeFallbackResolved, metav1.ConditionTrue, "ProfileResolved", "Fallback profile found and ready")
+	return fallback
+}
+
+// fallbackUpstreamConfig returns the Corefile configuration of the fallback
+// profile, or nil without one
+func fallbackUpstreamConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, fallback *nextdnsv1alpha1.NextDNSProfile) *coredns.FallbackUpstreamConfig {
+	if fallback == nil {
+		return nil
+	}
+	ipv4, ipv6 := profileUpstreamIPs(coreDNS, fallback)
+	return &coredns.FallbackUpstreamConfig{
+		ProfileID:    fallback.Status.ProfileID,
+		UpstreamIPv4: ipv4,
+		UpstreamIPv6: ipv6,
+	}
+}
+
+// updateActiveUpstream health checks the primary upstreams when a fallback
+// profile is in use and records which path serves queries. The check runs
+// from the operator pod, so it reflects the primary being down rather than
+// the network of a single CoreDNS pod.
+func (r *NextDNSCoreDNSReconciler) updateActiveUpstream(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile, fallback *nextdnsv1alpha1.NextDNSProfile) {
+	if fallback == nil {
+		coreDNS.Status.FallbackUpstream = nil
+		coreDNS.Status.ActiveUpstream = ""
+		return
+	}
+	coreDNS.Status.FallbackUpstream = upstreamStatus(coreDNS, fallback)
+
+	active := nextdnsv1alpha1.UpstreamPathFallback
+	if r.primaryUpstreamHealthy(ctx, coreDNS, profile) {
+		active = nextdnsv1alpha1.UpstreamPathPrimary
+	}
+	switch {
+	case active == nextdnsv1alpha1.UpstreamPathFallback && coreDNS.Status.ActiveUpstream != active:
+		recordEvent(r.Recorder, coreDNS, corev1.EventTypeWarning, EventReasonFallbackActivated,
+			"No upstream of profile %s answers; queries are forwarded to fallback profile %s", profile.Status.ProfileID, fallback.Status.ProfileID)
+	case active == nextdnsv1alpha1.UpstreamPathPrimary && coreDNS.Status.ActiveUpstream == nextdnsv1alpha1.UpstreamPathFallback:
+		recordEvent(r.Recorder, coreDNS, corev1.EventTypeNormal, EventReasonPrimaryRestored,
+			"Upstreams of profile %s answer again", profile.Status.ProfileID)
+	}
+	coreDNS.Status.ActiveUpstream = active
+}
+
+// primaryUpstreamHealthy reports whether any upstream of the primary profile
+// answers the health check. The forward plugin keeps using the primary
+// while one of its upstreams is up.
+func (r *NextDNSCoreDNSReconciler) primaryUpstreamHealthy(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	probe := r.UpstreamProbe
+	if probe == nil {
+		probe = DefaultUpstreamProbe
+	}
+	protocol := upstreamProtocol(coreDNS)
+	targets, serverName := upstreamProbeTargets(coreDNS, profile, protocol)
+	for _, target := range targets {
+		probeCtx, cancel := context.WithTimeout(ctx, upstreamProbeTimeout)
+		err := probe(probeCtx, protocol, target, serverName)
+		cancel()
+		if err == nil {
+			return true
+		}
+		log.FromContext(ctx).V(1).Info("Upstream health check failed", "upstream", target, "error", err.Error())
+	}
+	return false
+}
+
+// upstreamProbeTargets returns the upstreams of profile the forward plugin
+// uses over protocol, with the TLS server name for DoT
+func upstreamProbeTargets(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile, protocol string) ([]string, string) {
+	deviceName := ""
+	if cf := coreDNS.Spec.Corefile; cf != nil && cf.Upstream != nil {
+		deviceName = cf.Upstream.DeviceName
+	}
+	if protocol == coredns.ProtocolDoH {
+		return []string{coredns.DeviceDoHURL(profile.Status.ProfileID, deviceName)}, ""
+	}
+
+	port, serverName := "53", ""
+	if protocol == coredns.ProtocolDoT {
+		port, serverName = "853", coredns.DeviceDoTHostname(profile.Status.ProfileID, deviceName)
+	}
+	addrs := coredns.UpstreamAddresses(profileUpstreamIPs(coreDNS, profile))
+	targets := make([]string, len(addrs))
+	for i, addr := range addrs {
+		targets[i] = net.JoinHostPort(addr, port)
+	}
+	return targets, serverName
+}
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+// newFallbackTestProfile returns a ready profile with the given NextDNS ID
+func newFallbackTestProfile(name, profileID string) *nextdnsv1alpha1.NextDNSProfile {
+	return &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:  profileID,
+			Conditions: []metav1.Condition{{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: "Synced"}},
+		},
+	}
+}
+
+func TestResolveFallbackProfile(t *testing.T) {
+	ctx := context.Background()
+	breakGlass := newFallbackTestProfile("break-glass", "def456")
+	pending := &nextdnsv1alpha1.NextDNSProfile{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}}
+	r := &NextDNSCoreDNSReconciler{
+		Client: fake.NewClientBuilder().WithScheme(newCoreDNSTestScheme()).WithObjects(breakGlass, pending).Build(),
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"}}
+
+	assert.Nil(t, r.resolveFallbackProfile(ctx, coreDNS))
+	assert.Nil(t, meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeFallbackResolved))
+
+	coreDNS.Spec.FallbackProfileRef = &nextdnsv1alpha1.ResourceReference{Name: "missing"}
+	assert.Nil(t, r.resolveFallbackProfile(ctx, coreDNS))
+	assert.Equal(t, "ProfileNotFound", meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeFallbackResolved).Reason)
+
+	coreDNS.Spec.FallbackProfileRef = &nextdnsv1alpha1.ResourceReference{Name: "pending"}
+	assert.Nil(t, r.resolveFallbackProfile(ctx, coreDNS))
+	assert.Equal(t, "ProfileNotReady", meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeFallbackResolved).Reason)
+
+	coreDNS.Spec.FallbackProfileRef = &nextdnsv1alpha1.ResourceReference{Name: "break-glass"}
+	fallback := r.resolveFallbackProfile(ctx, coreDNS)
+	require.NotNil(t, fallback)
+	assert.Equal(t, "def456", fallback.Status.ProfileID)
+	assert.True(t, meta.IsStatusConditionTrue(coreDNS.Status.Conditions, ConditionTypeFallbackResolved))
+
+	coreDNS.Spec.FallbackProfileRef = nil
+	assert.Nil(t, r.resolveFallbackProfile(ctx, coreDNS))
+	assert.Nil(t, meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeFallbackResolved), "the condition is removed with the reference")
+}
+
+func TestUpdateActiveUpstream(t *testing.T) {
+	ctx := context.Background()
+	profile := newFallbackTestProfile("home", "abc123")
+	fallback := newFallbackTestProfile("break-glass", "def456")
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			FallbackProfileRef: &nextdnsv1alpha1.ResourceReference{Name: "break-glass"},
+		},
+	}
+
+	var probed []string
+	healthy := true
+	recorder := record.NewFakeRecorder(10)
+	r := &NextDNSCoreDNSReconciler{
+		Recorder: recorder,
+		UpstreamProbe: func(ctx context.Context, protocol, upstream, serverName string) error {
+			probed = append(probed, protocol+" "+upstream+" "+serverName)
+			if !healthy {
+				return errors.New("timeout")
+			}
+			return nil
+		},
+	}
+
+	r.updateActiveUpstream(ctx, coreDNS, profile, fallback)
+	assert.Equal(t, nextdnsv1alpha1.UpstreamPathPrimary, coreDNS.Status.ActiveUpstream)
+	assert.Equal(t, []string{"DoT 45.90.28.0:853 abc123.dns.nextdns.io"}, probed, "one healthy upstream is enough")
+	require.NotNil(t, coreDNS.Status.FallbackUpstream)
+	assert.Contains(t, coreDNS.Status.FallbackUpstream.URL, "def456.dns.nextdns.io")
+	assert.Empty(t, drainEvents(recorder))
+
+	healthy, probed = false, nil
+	r.updateActiveUpstream(ctx, coreDNS, profile, fallback)
+	assert.Equal(t, nextdnsv1alpha1.UpstreamPathFallback, coreDNS.Status.ActiveUpstream)
+	assert.Len(t, probed, 2)
+	events := drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "Warning "+EventReasonFallbackActivated)
+
+	r.updateActiveUpstream(ctx, coreDNS, profile, fallback)
+	assert.Empty(t, drainEvents(recorder), "a continuing fallback is reported once")
+
+	healthy = true
+	r.updateActiveUpstream(ctx, coreDNS, profile, fallback)
+	assert.Equal(t, nextdnsv1alpha1.UpstreamPathPrimary, coreDNS.Status.ActiveUpstream)
+	events = drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "Normal "+EventReasonPrimaryRestored)
+
+	r.updateActiveUpstream(ctx, coreDNS, profile, nil)
+	assert.Empty(t, coreDNS.Status.ActiveUpstream)
+	assert.Nil(t, coreDNS.Status.FallbackUpstream)
+}
+
+func TestUpstreamProbeTargets(t *testing.T) {
+	profile := newFallbackTestProfile("home", "abc123")
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{DeviceName: "home"},
+			},
+		},
+	}
+
+	targets, serverName := upstreamProbeTargets(coreDNS, profile, coredns.ProtocolDoT)
+	assert.Equal(t, []string{"45.90.28.0:853", "45.90.30.0:853"}, targets)
+	assert.Equal(t, "home-abc123.dns.nextdns.io", serverName)
+
+	targets, serverName = upstreamProbeTargets(coreDNS, profile, coredns.ProtocolDNS)
+	assert.Equal(t, []string{"45.90.28.0:53", "45.90.30.0:53"}, targets)
+	assert.Empty(t, serverName)
+
+	targets, _ = upstreamProbeTargets(coreDNS, profile, coredns.ProtocolDoH)
+	assert.Equal(t, []string{"https://dns.nextdns.io/abc123/home"}, targets)
+}
+
+func TestDefaultUpstreamProbe_DNS(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	go func() {
+		buf := make([]byte, 512)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		buf[2] |= 0x80 // mark the query as a response
+		_, _ = conn.WriteTo(buf[:n], addr)
+	}()
+
+	assert.NoError(t, DefaultUpstreamProbe(context.Background(), coredns.ProtocolDNS, conn.LocalAddr().String(), ""))
+}
+
+func TestCheckHealthCheckResponse(t *testing.T) {
+	response := append([]byte(nil), healthCheckQuery...)
+	assert.Error(t, checkHealthCheckResponse(response), "a query is not a response")
+	response[2] |= 0x80
+	assert.NoError(t, checkHealthCheckResponse(response))
+	assert.Error(t, checkHealthCheckResponse(response[:4]))
+}
+
+func TestReconcileConfigMap_Fallback(t *testing.T) {
+	ctx := context.Background()
+	profile := newFallbackTestProfile("home", "abc123")
+	fallback := newFallbackTestProfile("break-glass", "def456")
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default", UID: "uid"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			FallbackProfileRef: &nextdnsv1alpha1.ResourceReference{Name: "break-glass"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{
+					Primary: nextdnsv1alpha1.DNSProtocolDoT,
+					Forward: &nextdnsv1alpha1.ForwardTuningConfig{Policy: nextdnsv1alpha1.ForwardPolicyRoundRobin},
+				},
+			},
+		},
+	}
+	scheme := newCoreDNSTestScheme()
+	r := &NextDNSCoreDNSReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(coreDNS).Build(),
+		Scheme: scheme,
+	}
+
+	err := r.reconcileConfigMap(ctx, coreDNS, profile, fallback)
+	assert.ErrorContains(t, err, "round_robin", "a policy spreading queries would use the fallback")
+
+	coreDNS.Spec.Corefile.Upstream.Forward = nil
+	require.NoError(t, r.reconcileConfigMap(ctx, coreDNS, profile, fallback))
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultFanOutWindow is the period over which the profile reconciles
+// triggered by one shared list change are spread
+const DefaultFanOutWindow = 30 * time.Second
+
+// scheduledRequest is a reconcile request and the delay before it is enqueued
+type scheduledRequest struct {
+	request reconcile.Request
+	delay   time.Duration
+}
+
+// fanOutCoalescer spreads the reconciles fanned out from a shared list change
+// over a window, so editing a list referenced by many profiles does not send
+// every one of them to the NextDNS API at once. Each profile is reconciled at
+// most once per window; changes arriving while a reconcile is pending are
+// coalesced into it.
+type fanOutCoalescer struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	scheduled map[reconcile.Request]time.Time
+}
+
+// newFanOutCoalescer returns a coalescer spreading reconciles over window
+func newFanOutCoalescer(window time.Duration) *fanOutCoalescer {
+	return &fanOutCoalescer{
+		window:    window,
+		now:       time.Now,
+		scheduled: make(map[reconcile.Request]time.Time),
+	}
+}
+
+// schedule returns the requests to enqueue and their delays. The first
+// request runs immediately and the rest are spread evenly over the window.
+func (c *fanOutCoalescer) schedule(requests []reconcile.Request) []scheduledRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for req, at := range c.scheduled {
+		if now.Sub(at) >= c.window {
+			delete(c.scheduled, req)
+		}
+	}
+
+	unique := make([]reconcile.Request, 0, len(requests))
+	seen := make(map[reconcile.Request]bool, len(requests))
+	for _, req := range requests {
+		if !seen[req] {
+			seen[req] = true
+			unique = append(unique, req)
+		}
+	}
+
+	var result []scheduledRequest
+	for i, req := range unique {
+		at := now.Add(c.window * time.Duration(i) / time.Duration(len(unique)))
+		if last, ok := c.scheduled[req]; ok {
+			if last.After(now) {
+				// Already pending; it will read the latest list contents
+				continue
+			}
+			if next := last.Add(c.window); at.Before(next) {
+				at = next
+			}
+		}
+		c.scheduled[req] = at
+		result = append(result, scheduledRequest{request: req, delay: at.Sub(now)})
+	}
+	return result
+}
+
+// handler returns an event handler enqueuing the requests returned by mapFn
+// through the coalescer
+func (c *fanOutCoalescer) handler(mapFn handler.MapFunc) handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objs ...client.Object) {
+		var requests []reconcile.Request
+		for _, obj := range objs {
+			requests = append(requests, mapFn(ctx, obj)...)
+		}
+		for _, s := range c.schedule(requests) {
+			if s.delay > 0 {
+				q.AddAfter(s.request, s.delay)
+			} else {
+				q.Add(s.request)
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
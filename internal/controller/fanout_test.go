@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func profileRequests(n int) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, n)
+	for i := range n {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: fmt.Sprintf("profile-%d", i), Namespace: "default"},
+		})
+	}
+	return requests
+}
+
+func TestFanOutCoalescer_Schedule(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newFanOutCoalescer(time.Minute)
+	c.now = func() time.Time { return now }
+
+	requests := profileRequests(4)
+
+	// Requests are spread evenly over the window; duplicates are dropped
+	scheduled := c.schedule(append(requests, requests[0]))
+	require.Len(t, scheduled, 4)
+	for i, s := range scheduled {
+		assert.Equal(t, requests[i], s.request)
+		assert.Equal(t, time.Duration(i)*15*time.Second, s.delay)
+	}
+
+	// A second edit while reconciles are pending is coalesced into them
+	now = now.Add(10 * time.Second)
+	scheduled = c.schedule(requests)
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, requests[0], scheduled[0].request)
+	assert.Equal(t, 50*time.Second, scheduled[0].delay, "at most one reconcile per key per window")
+
+	// Once the window has passed, keys run immediately again
+	now = now.Add(5 * time.Minute)
+	scheduled = c.schedule(requests[:1])
+	require.Len(t, scheduled, 1)
+	assert.Zero(t, scheduled[0].delay)
+}
+
+func TestFanOutCoalescer_ZeroWindow(t *testing.T) {
+	c := newFanOutCoalescer(0)
+
+	for range 2 {
+		scheduled := c.schedule(profileRequests(3))
+		require.Len(t, scheduled, 3)
+		for _, s := range scheduled {
+			assert.Zero(t, s.delay)
+		}
+	}
+}
+
+func TestFanOutCoalescer_Handler(t *testing.T) {
+	c := newFanOutCoalescer(time.Hour)
+	requests := profileRequests(3)
+	mapFn := func(context.Context, client.Object) []reconcile.Request { return requests }
+
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	allowlist := &nextdnsv1alpha1.NextDNSAllowlist{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"}}
+	c.handler(mapFn).Update(context.Background(), event.UpdateEvent{ObjectOld: allowlist, ObjectNew: allowlist}, q)
+
+	// Only the first profile is enqueued right away; the rest are delayed
+	assert.Equal(t, 1, q.Len())
+	item, _ := q.Get()
+	assert.Equal(t, requests[0], item)
+	q.Done(item)
+}
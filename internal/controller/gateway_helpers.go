@@ -40,6 +40,8 @@ func (r *NextDNSCoreDNSReconciler) reconcileGateway(ctx context.Context, coreDNS
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, gw, func() error {
+		r.ResourceLabels.set(gw)
+
 		// Reset annotations to match spec (removes stale annotations from prior reconciles)
 		gw.Annotations = make(map[string]string)
 		if coreDNS.Spec.Gateway != nil {
@@ -170,6 +172,7 @@ func (r *NextDNSCoreDNSReconciler) reconcileTCPRoute(ctx context.Context, coreDN
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+		r.ResourceLabels.set(route)
 		route.Spec = gatewayv1alpha2.TCPRouteSpec{
 			CommonRouteSpec: gatewayv1.CommonRouteSpec{
 				ParentRefs: []gatewayv1.ParentReference{
@@ -224,6 +227,7 @@ func (r *NextDNSCoreDNSReconciler) reconcileUDPRoute(ctx context.Context, coreDN
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+		r.ResourceLabels.set(route)
 		route.Spec = gatewayv1alpha2.UDPRouteSpec{
 			CommonRouteSpec: gatewayv1.CommonRouteSpec{
 				ParentRefs: []gatewayv1.ParentReference{
@@ -19,8 +19,9 @@ import (
 // configuration for a particular gateway controller.
 type gatewayProxyStrategy interface {
 	// ReconcileProxyReplicas creates or updates the implementation-specific
-	// CR and returns a GatewayParametersReference pointing at it.
-	ReconcileProxyReplicas(ctx context.Context, c client.Client, scheme *runtime.Scheme, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, replicas int32) (*nextdnsv1alpha1.GatewayParametersReference, error)
+	// CR with the given labels and returns a GatewayParametersReference
+	// pointing at it.
+	ReconcileProxyReplicas(ctx context.Context, c client.Client, scheme *runtime.Scheme, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, replicas int32, labels ResourceLabels) (*nextdnsv1alpha1.GatewayParametersReference, error)
 
 	// CleanupProxyReplicas deletes the implementation-specific CR.
 	CleanupProxyReplicas(ctx context.Context, c client.Client, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) error
@@ -57,7 +58,7 @@ func envoyProxyGVK() schema.GroupVersionKind {
 	}
 }
 
-func (s *envoyGatewayStrategy) ReconcileProxyReplicas(ctx context.Context, c client.Client, scheme *runtime.Scheme, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, replicas int32) (*nextdnsv1alpha1.GatewayParametersReference, error) {
+func (s *envoyGatewayStrategy) ReconcileProxyReplicas(ctx context.Context, c client.Client, scheme *runtime.Scheme, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, replicas int32, labels ResourceLabels) (*nextdnsv1alpha1.GatewayParametersReference, error) {
 	name := envoyProxyName(coreDNS)
 
 	desired := &unstructured.Unstructured{
@@ -80,6 +81,8 @@ func (s *envoyGatewayStrategy) ReconcileProxyReplicas(ctx context.Context, c cli
 		},
 	}
 
+	labels.set(desired)
+
 	if err := ctrl.SetControllerReference(coreDNS, desired, scheme); err != nil {
 		return nil, fmt.Errorf("failed to set owner reference on EnvoyProxy: %w", err)
 	}
@@ -106,6 +109,7 @@ func (s *envoyGatewayStrategy) ReconcileProxyReplicas(ctx context.Context, c cli
 
 	// Update existing spec
 	existing.Object["spec"] = desired.Object["spec"]
+	labels.set(existing)
 	if updateErr := c.Update(ctx, existing); updateErr != nil {
 		return nil, fmt.Errorf("failed to update EnvoyProxy: %w", updateErr)
 	}
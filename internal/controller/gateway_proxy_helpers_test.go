@@ -41,7 +41,7 @@ func TestEnvoyGatewayStrategy_ReconcileProxyReplicas(t *testing.T) {
 	strategy := &envoyGatewayStrategy{}
 	replicas := int32(3)
 
-	ref, err := strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, replicas)
+	ref, err := strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, replicas, nil)
 	require.NoError(t, err)
 	require.NotNil(t, ref)
 
@@ -130,11 +130,11 @@ func TestEnvoyGatewayStrategy_ReconcileProxyReplicas_Update(t *testing.T) {
 	strategy := &envoyGatewayStrategy{}
 
 	// First reconcile: 2 replicas
-	_, err := strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, 2)
+	_, err := strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, 2, nil)
 	require.NoError(t, err)
 
 	// Second reconcile: 5 replicas
-	_, err = strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, 5)
+	_, err = strategy.ReconcileProxyReplicas(context.Background(), fakeClient, scheme, coreDNS, 5, nil)
 	require.NoError(t, err)
 
 	// Verify updated
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// externalDNSHostnameAnnotation tells external-dns which record to manage
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+
+	// externalDNSTargetAnnotation overrides the record targets published by external-dns
+	externalDNSTargetAnnotation = "external-dns.alpha.kubernetes.io/target"
+)
+
+// hostPortEnabled reports whether DNS is exposed via hostPort.
+// hostPort is only honored in DaemonSet mode, where each node runs one pod.
+func hostPortEnabled(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	d := coreDNS.Spec.Deployment
+	return d != nil && d.Mode == nextdnsv1alpha1.DeploymentModeDaemonSet &&
+		d.HostPort != nil && d.HostPort.Enabled
+}
+
+// hostPortAddressType returns the node address type to publish, defaulting to InternalIP
+func hostPortAddressType(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) corev1.NodeAddressType {
+	if coreDNS.Spec.Deployment.HostPort.AddressType == nextdnsv1alpha1.NodeAddressExternalIP {
+		return corev1.NodeExternalIP
+	}
+	return corev1.NodeInternalIP
+}
+
+// listHostPortNodeIPs returns the sorted addresses of nodes running a ready CoreDNS pod
+func (r *NextDNSCoreDNSReconciler) listHostPortNodeIPs(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]string, error) {
+	podList := &corev1.PodList{}
+	labels := map[string]string{
+		"app.kubernetes.io/name":     "coredns",
+		"app.kubernetes.io/instance": coreDNS.Name,
+	}
+	if err := r.List(ctx, podList, client.InNamespace(coreDNS.Namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("failed to list CoreDNS pods: %w", err)
+	}
+
+	addressType := hostPortAddressType(coreDNS)
+	seen := make(map[string]bool)
+	var ips []string
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || !isPodReady(&pod) {
+			continue
+		}
+
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			// The node may have just left the cluster; its pod will follow
+			log.FromContext(ctx).V(1).Info("Skipping pod on unavailable node", "pod", pod.Name, "node", pod.Spec.NodeName, "error", err)
+			continue
+		}
+
+		ip := nodeAddress(node, addressType)
+		if ip != "" && !seen[ip] {
+			seen[ip] = true
+			ips = append(ips, ip)
+		}
+	}
+
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// isPodReady reports whether the pod's Ready condition is true
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeAddress returns the first address of the given type, or "" if none
+func nodeAddress(node *corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == addressType {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+// applyExternalDNSAnnotations points external-dns at the current node IPs.
+// With no ready nodes the target annotation is removed rather than left stale.
+func applyExternalDNSAnnotations(service *corev1.Service, hostname string, nodeIPs []string) {
+	if service.Annotations == nil {
+		service.Annotations = make(map[string]string)
+	}
+	service.Annotations[externalDNSHostnameAnnotation] = hostname
+	if len(nodeIPs) == 0 {
+		delete(service.Annotations, externalDNSTargetAnnotation)
+		return
+	}
+	service.Annotations[externalDNSTargetAnnotation] = strings.Join(nodeIPs, ",")
+}
+
+// findCoreDNSForNode returns reconcile requests for every NextDNSCoreDNS using hostPort
+func (r *NextDNSCoreDNSReconciler) findCoreDNSForNode(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*corev1.Node); !ok {
+		return nil
+	}
+
+	var coreDNSList nextdnsv1alpha1.NextDNSCoreDNSList
+	if err := r.List(ctx, &coreDNSList); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, coreDNS := range coreDNSList.Items {
+		if hostPortEnabled(&coreDNS) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      coreDNS.Name,
+					Namespace: coreDNS.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
+
+// nodeTopologyChangedPredicate filters Node events down to joins, leaves and
+// changes that affect the published endpoints. Nodes are updated constantly
+// by the kubelet, so heartbeat-only updates are ignored.
+func nodeTopologyChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return isNodeReady(oldNode) != isNodeReady(newNode) ||
+				!equalNodeAddresses(oldNode.Status.Addresses, newNode.Status.Addresses)
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// isNodeReady reports whether the node's Ready condition is true
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// equalNodeAddresses compares two node address lists in order
+func equalNodeAddresses(a, b []corev1.NodeAddress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newHostPortCoreDNS(hostPort *nextdnsv1alpha1.CoreDNSHostPortConfig) *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode:     nextdnsv1alpha1.DeploymentModeDaemonSet,
+				HostPort: hostPort,
+			},
+		},
+	}
+}
+
+func newCoreDNSPod(name, nodeName string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "coredns",
+				"app.kubernetes.io/instance": "home-dns",
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func newNode(name, internalIP, externalIP string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if internalIP != "" {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: internalIP})
+	}
+	if externalIP != "" {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: externalIP})
+	}
+	return node
+}
+
+func TestHostPortEnabled(t *testing.T) {
+	assert.True(t, hostPortEnabled(newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true})))
+	assert.False(t, hostPortEnabled(newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: false})))
+	assert.False(t, hostPortEnabled(newHostPortCoreDNS(nil)))
+
+	deployment := newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true})
+	deployment.Spec.Deployment.Mode = nextdnsv1alpha1.DeploymentModeDeployment
+	assert.False(t, hostPortEnabled(deployment), "hostPort is ignored outside DaemonSet mode")
+
+	assert.False(t, hostPortEnabled(&nextdnsv1alpha1.NextDNSCoreDNS{}))
+}
+
+func TestListHostPortNodeIPs(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	coreDNS := newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newNode("node-b", "192.168.1.12", "203.0.113.12"),
+			newNode("node-a", "192.168.1.11", "203.0.113.11"),
+			newNode("node-c", "192.168.1.13", ""),
+			newCoreDNSPod("dns-b", "node-b", true),
+			newCoreDNSPod("dns-a", "node-a", true),
+			newCoreDNSPod("dns-c", "node-c", false),
+			newCoreDNSPod("dns-gone", "node-gone", true),
+			newCoreDNSPod("dns-pending", "", true),
+		).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	ips, err := r.listHostPortNodeIPs(context.Background(), coreDNS)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.11", "192.168.1.12"}, ips)
+
+	coreDNS.Spec.Deployment.HostPort.AddressType = nextdnsv1alpha1.NodeAddressExternalIP
+	ips, err = r.listHostPortNodeIPs(context.Background(), coreDNS)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"203.0.113.11", "203.0.113.12"}, ips)
+}
+
+func TestBuildPodSpec_HostPort(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	podSpec := r.buildPodSpec(newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true}), "test-cm")
+	for _, port := range podSpec.Containers[0].Ports {
+		if port.ContainerPort == 53 {
+			assert.Equal(t, int32(53), port.HostPort, "port %s should bind the host", port.Name)
+		} else {
+			assert.Zero(t, port.HostPort, "port %s should not bind the host", port.Name)
+		}
+	}
+
+	podSpec = r.buildPodSpec(newHostPortCoreDNS(nil), "test-cm")
+	for _, port := range podSpec.Containers[0].Ports {
+		assert.Zero(t, port.HostPort)
+	}
+}
+
+func TestUpdateStatus_HostPortNodeIPs(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true})
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns-abc123-coredns", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.10", Type: corev1.ServiceTypeClusterIP},
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns-abc123-coredns", Namespace: "default"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(coreDNS, profile, service, daemonSet,
+			newNode("node-a", "192.168.1.11", ""),
+			newCoreDNSPod("dns-a", "node-a", true),
+		).
+		WithStatusSubresource(coreDNS).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, r.updateStatus(context.Background(), coreDNS, profile))
+
+	assert.Equal(t, []string{"192.168.1.11"}, coreDNS.Status.NodeIPs)
+	assert.Contains(t, coreDNS.Status.Endpoints, nextdnsv1alpha1.DNSEndpoint{IP: "192.168.1.11", Port: 53, Protocol: "UDP", Stamp: "sdns://AAAAAAAAAAAADDE5Mi4xNjguMS4xMQ"})
+	assert.Contains(t, coreDNS.Status.Endpoints, nextdnsv1alpha1.DNSEndpoint{IP: "192.168.1.11", Port: 53, Protocol: "TCP", Stamp: "sdns://AAAAAAAAAAAADDE5Mi4xNjguMS4xMQ"})
+
+	// Node leaves: its pod goes away and the endpoint is dropped
+	require.NoError(t, fakeClient.Delete(context.Background(), newCoreDNSPod("dns-a", "node-a", true)))
+	require.NoError(t, r.updateStatus(context.Background(), coreDNS, profile))
+
+	assert.Empty(t, coreDNS.Status.NodeIPs)
+	for _, ep := range coreDNS.Status.Endpoints {
+		assert.NotEqual(t, "192.168.1.11", ep.IP)
+	}
+}
+
+func TestReconcileService_ExternalDNSAnnotations(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{
+		Enabled:             true,
+		ExternalDNSHostname: "dns.home.example.com",
+	})
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(coreDNS, profile,
+			newNode("node-a", "192.168.1.11", ""),
+			newNode("node-b", "192.168.1.12", ""),
+			newCoreDNSPod("dns-a", "node-a", true),
+			newCoreDNSPod("dns-b", "node-b", true),
+		).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, r.reconcileService(ctx, coreDNS, profile))
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home-dns-abc123-coredns", Namespace: "default"}, service))
+	assert.Equal(t, "dns.home.example.com", service.Annotations[externalDNSHostnameAnnotation])
+	assert.Equal(t, "192.168.1.11,192.168.1.12", service.Annotations[externalDNSTargetAnnotation])
+
+	// All nodes gone: the stale target is removed
+	require.NoError(t, fakeClient.Delete(ctx, newCoreDNSPod("dns-a", "node-a", true)))
+	require.NoError(t, fakeClient.Delete(ctx, newCoreDNSPod("dns-b", "node-b", true)))
+	require.NoError(t, r.reconcileService(ctx, coreDNS, profile))
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "home-dns-abc123-coredns", Namespace: "default"}, service))
+	assert.NotContains(t, service.Annotations, externalDNSTargetAnnotation)
+}
+
+func TestFindCoreDNSForNode(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	withHostPort := newHostPortCoreDNS(&nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true})
+	withoutHostPort := newHostPortCoreDNS(nil)
+	withoutHostPort.Name = "other-dns"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(withHostPort, withoutHostPort).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	requests := r.findCoreDNSForNode(context.Background(), newNode("node-a", "192.168.1.11", ""))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "home-dns", requests[0].Name)
+
+	assert.Nil(t, r.findCoreDNSForNode(context.Background(), &corev1.Pod{}))
+}
+
+func TestNodeTopologyChangedPredicate(t *testing.T) {
+	p := nodeTopologyChangedPredicate()
+
+	ready := newNode("node-a", "192.168.1.11", "")
+	ready.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+
+	heartbeat := ready.DeepCopy()
+	heartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: heartbeat}))
+
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: notReady}))
+
+	readdressed := ready.DeepCopy()
+	readdressed.Status.Addresses[0].Address = "192.168.1.99"
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: readdressed}))
+
+	assert.True(t, p.Create(event.CreateEvent{Object: ready}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: ready}))
+	assert.False(t, p.Generic(event.GenericEvent{Object: ready}))
+}
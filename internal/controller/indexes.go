@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+const (
+	// credentialsRefIndexField is the field index key for looking up profiles by their secret reference
+	credentialsRefIndexField = ".spec.credentialsRef"
+
+	// accountRefIndexField is the field index key for looking up profiles by their account reference
+	accountRefIndexField = ".spec.accountRef"
+
+	// accountCredentialsIndexField is the field index key for looking up accounts by their secret reference
+	accountCredentialsIndexField = ".spec.credentialsRef"
+
+	// listRefsIndexField is the field index key for looking up profiles by the lists they reference
+	listRefsIndexField = ".spec.listRefs"
+
+	// templateRefIndexField is the field index key for looking up generators by their template reference
+	templateRefIndexField = ".spec.templateRef"
+)
+
+// credentialsRefIndexFunc extracts the secret reference key (namespace/name) from a NextDNSProfile,
+// falling back to the default credentials Secret, for use with controller-runtime's field indexer.
+// This enables efficient lookups when a Secret changes.
+func credentialsRefIndexFunc(obj client.Object) []string {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok || profile.Spec.AccountRef != nil {
+		return nil
+	}
+	ref, _, err := profileCredentials(profile)
+	if err != nil {
+		return nil
+	}
+	return []string{ref.Namespace + "/" + ref.Name}
+}
+
+// accountRefIndexFunc extracts the account reference key (namespace/name)
+// from a NextDNSProfile
+func accountRefIndexFunc(obj client.Object) []string {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok || profile.Spec.AccountRef == nil {
+		return nil
+	}
+	namespace := profile.Spec.AccountRef.Namespace
+	if namespace == "" {
+		namespace = profile.Namespace
+	}
+	return []string{namespace + "/" + profile.Spec.AccountRef.Name}
+}
+
+// accountCredentialsIndexFunc extracts the secret reference key
+// (namespace/name) from a NextDNSAccount
+func accountCredentialsIndexFunc(obj client.Object) []string {
+	account, ok := obj.(*nextdnsv1alpha1.NextDNSAccount)
+	if !ok {
+		return nil
+	}
+	ref := accountCredentials(account)
+	return []string{ref.Namespace + "/" + ref.Name}
+}
+
+// listRefKinds maps each list kind a profile can reference to the function
+// extracting those references from a spec
+var listRefKinds = []struct {
+	kind          string
+	clusterScoped bool
+	extractRefs   func(*nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference
+}{
+	{kind: nextdnsv1alpha1.KindAllowlist, extractRefs: allowlistRefs},
+	{kind: nextdnsv1alpha1.KindClusterAllowlist, clusterScoped: true, extractRefs: clusterAllowlistRefs},
+	{kind: nextdnsv1alpha1.KindDenylist, extractRefs: denylistRefs},
+	{kind: nextdnsv1alpha1.KindClusterDenylist, clusterScoped: true, extractRefs: clusterDenylistRefs},
+	{kind: nextdnsv1alpha1.KindDenylistSource, extractRefs: denylistSourceRefs},
+	{kind: "NextDNSTLDList", extractRefs: tldListRefs},
+	{kind: "NextDNSRewrite", extractRefs: rewriteRefs},
+}
+
+// listRefIndexKey returns the index key of a list. Cluster-scoped lists have
+// an empty namespace.
+func listRefIndexKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// listRefsIndexFunc extracts a key for every list a NextDNSProfile references,
+// including references in overlays, so a list change only looks up the
+// profiles using it
+func listRefsIndexFunc(obj client.Object) []string {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, k := range listRefKinds {
+		for _, ref := range allListRefs(&profile.Spec, k.extractRefs) {
+			ns := ""
+			if !k.clusterScoped {
+				ns = ref.Namespace
+				if ns == "" {
+					ns = profile.Namespace
+				}
+			}
+			key := listRefIndexKey(k.kind, ns, ref.Name)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// coreDNSProfileRefIndexFunc extracts the profile reference keys from a
+// NextDNSCoreDNS, including its fallback profile
+func coreDNSProfileRefIndexFunc(obj client.Object) []string {
+	coreDNS, ok := obj.(*nextdnsv1alpha1.NextDNSCoreDNS)
+	if !ok {
+		return nil
+	}
+	keys := []string{profileresolver.Key(coreDNS.Spec.ProfileRef, coreDNS.Namespace)}
+	if ref := coreDNS.Spec.FallbackProfileRef; ref != nil {
+		keys = append(keys, profileresolver.Key(*ref, coreDNS.Namespace))
+	}
+	return keys
+}
+
+// deviceProfileRefIndexFunc extracts the profile reference key from a NextDNSDevice
+func deviceProfileRefIndexFunc(obj client.Object) []string {
+	device, ok := obj.(*nextdnsv1alpha1.NextDNSDevice)
+	if !ok {
+		return nil
+	}
+	return []string{profileresolver.Key(device.Spec.ProfileRef, device.Namespace)}
+}
+
+// generatorTemplateRefIndexFunc extracts the template reference key from a NextDNSProfileGenerator
+func generatorTemplateRefIndexFunc(obj client.Object) []string {
+	generator, ok := obj.(*nextdnsv1alpha1.NextDNSProfileGenerator)
+	if !ok {
+		return nil
+	}
+	return []string{profileresolver.Key(generator.Spec.TemplateRef, generator.Namespace)}
+}
+
+// indexField registers a field index, wrapping the error with the field name
+func indexField(mgr ctrl.Manager, obj client.Object, field string, extract client.IndexerFunc) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, field, extract); err != nil {
+		return fmt.Errorf("failed to create field index for %s: %w", field, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+func TestListRefsIndexFunc(t *testing.T) {
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			AllowlistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "trusted"},
+				{Name: "corp", Namespace: "ignored", Kind: nextdnsv1alpha1.KindClusterAllowlist},
+			},
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "ads", Namespace: "lists"},
+				{Name: "feed", Kind: nextdnsv1alpha1.KindDenylistSource},
+				{Name: "malware", Kind: nextdnsv1alpha1.KindClusterDenylist},
+			},
+			TLDListRefs: []nextdnsv1alpha1.ListReference{{Name: "tlds"}},
+			RewriteRefs: []nextdnsv1alpha1.ListReference{{Name: "lan"}},
+			Overlays: []nextdnsv1alpha1.ProfileOverlay{{
+				Name:          "school",
+				AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "trusted"}},
+				DenylistRefs:  []nextdnsv1alpha1.ListReference{{Name: "games"}},
+			}},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{
+		"NextDNSAllowlist/default/trusted",
+		"ClusterNextDNSAllowlist//corp",
+		"NextDNSDenylist/lists/ads",
+		"NextDNSDenylist/default/games",
+		"NextDNSDenylistSource/default/feed",
+		"ClusterNextDNSDenylist//malware",
+		"NextDNSTLDList/default/tlds",
+		"NextDNSRewrite/default/lan",
+	}, listRefsIndexFunc(profile), "overlay references are indexed once")
+
+	assert.Nil(t, listRefsIndexFunc(&nextdnsv1alpha1.NextDNSCoreDNS{}))
+}
+
+func TestAccountRefIndexFunc(t *testing.T) {
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			AccountRef: &nextdnsv1alpha1.ResourceReference{Name: "main"},
+		},
+	}
+	assert.Equal(t, []string{"default/main"}, accountRefIndexFunc(profile))
+	assert.Nil(t, credentialsRefIndexFunc(profile), "the account's Secret is watched by the account")
+
+	profile.Spec.AccountRef = nil
+	assert.Nil(t, accountRefIndexFunc(profile))
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials", Namespace: "nextdns-system"},
+		},
+	}
+	assert.Equal(t, []string{"nextdns-system/nextdns-credentials"}, accountCredentialsIndexFunc(account))
+}
+
+func TestFindProfilesForList_ClusterAndSource(t *testing.T) {
+	scheme := newTestScheme()
+
+	referencing := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "family"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "corp", Kind: nextdnsv1alpha1.KindClusterAllowlist}},
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "malware", Kind: nextdnsv1alpha1.KindClusterDenylist},
+				{Name: "feed", Namespace: "lists", Kind: nextdnsv1alpha1.KindDenylistSource},
+			},
+		},
+	}
+	namespacedOnly := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "corp"}},
+			DenylistRefs:  []nextdnsv1alpha1.ListReference{{Name: "feed"}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(referencing, namespacedOnly).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
+		Build()
+	r := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "home", Namespace: "family"}}}
+
+	assert.Equal(t, want, r.findProfilesForClusterAllowlist(ctx, &nextdnsv1alpha1.ClusterNextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "corp"},
+	}))
+	assert.Equal(t, want, r.findProfilesForClusterDenylist(ctx, &nextdnsv1alpha1.ClusterNextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "malware"},
+	}))
+	assert.Equal(t, want, r.findProfilesForDenylistSource(ctx, &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: "lists"},
+	}))
+	assert.Empty(t, r.findProfilesForDenylistSource(ctx, &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: "default"},
+	}), "a plain denylist reference does not match a source of the same name")
+	assert.Nil(t, r.findProfilesForClusterAllowlist(ctx, &nextdnsv1alpha1.NextDNSAllowlist{}))
+}
+
+func TestReferencingCoreDNSForProfile(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	sameNamespace := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec:       nextdnsv1alpha1.NextDNSCoreDNSSpec{ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"}},
+	}
+	crossNamespace := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-dns", Namespace: "edge"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home", Namespace: "default"},
+		},
+	}
+	otherProfile := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "edge"},
+		Spec:       nextdnsv1alpha1.NextDNSCoreDNSSpec{ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"}},
+	}
+	fallback := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "office-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef:         nextdnsv1alpha1.ResourceReference{Name: "office"},
+			FallbackProfileRef: &nextdnsv1alpha1.ResourceReference{Name: "home"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(sameNamespace, crossNamespace, otherProfile, fallback).
+		WithIndex(&nextdnsv1alpha1.NextDNSCoreDNS{}, profileresolver.IndexField, coreDNSProfileRefIndexFunc).
+		Build()
+	profile := &nextdnsv1alpha1.NextDNSProfile{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"}}
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "home-dns", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "edge-dns", Namespace: "edge"}},
+		{NamespacedName: types.NamespacedName{Name: "office-dns", Namespace: "default"}},
+	}, profileresolver.Referencing(context.Background(), fakeClient, &nextdnsv1alpha1.NextDNSCoreDNSList{}, profile))
+	assert.Nil(t, profileresolver.Referencing(context.Background(), fakeClient, &nextdnsv1alpha1.NextDNSCoreDNSList{}, &nextdnsv1alpha1.NextDNSCoreDNS{}))
+}
+
+func TestFindGeneratorsForTemplate(t *testing.T) {
+	scheme := newTestScheme()
+
+	sameNamespace := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "schools", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "school"},
+		},
+	}
+	crossNamespace := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "schools", Namespace: "district-2"},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "school", Namespace: "default"},
+		},
+	}
+	otherTemplate := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "offices", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "office"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(sameNamespace, crossNamespace, otherTemplate).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfileGenerator{}, templateRefIndexField, generatorTemplateRefIndexFunc).
+		Build()
+	r := &NextDNSProfileGeneratorReconciler{Client: fakeClient, Scheme: scheme}
+
+	template := &nextdnsv1alpha1.NextDNSProfileTemplate{ObjectMeta: metav1.ObjectMeta{Name: "school", Namespace: "default"}}
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "schools", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "schools", Namespace: "district-2"}},
+	}, r.findGeneratorsForTemplate(context.Background(), template))
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// resolvedList is a referenced list reduced to the entries a profile sync applies
+type resolvedList struct {
+	// Domains holds allowlist or denylist entries, including inactive ones
+	Domains []nextdns.DomainEntry `json:"domains,omitempty"`
+	// TLDs holds the active TLDs of a TLD list
+	TLDs []string `json:"tlds,omitempty"`
+	// Rewrites holds the active rewrites of a rewrite list
+	Rewrites []nextdns.RewriteEntry `json:"rewrites,omitempty"`
+
+	// count is the number of active entries
+	count int
+	// hash identifies the resolved content
+	hash string
+}
+
+// listCacheKey identifies a referenced list resource
+type listCacheKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// cachedList is a list resolved at a given generation and source revision
+type cachedList struct {
+	uid        types.UID
+	generation int64
+	revision   string
+	list       *resolvedList
+}
+
+// listCache shares resolved lists between profile reconciles, so a list
+// referenced by many profiles is walked and hashed once per generation.
+// Cached entries are shared and must not be modified.
+type listCache struct {
+	mu      sync.Mutex
+	entries map[listCacheKey]cachedList
+}
+
+// newListCache returns an empty list cache
+func newListCache() *listCache {
+	return &listCache{entries: make(map[listCacheKey]cachedList)}
+}
+
+// resolve returns the resolved content of obj, calling resolveFn only when
+// the list or the revision of its fetched sources changed since it was last
+// resolved. A nil cache always resolves.
+func (c *listCache) resolve(kind string, obj client.Object, revision string, resolveFn func() *resolvedList) *resolvedList {
+	if c == nil {
+		return hashResolvedList(resolveFn())
+	}
+
+	key := listCacheKey{kind: kind, namespace: obj.GetNamespace(), name: obj.GetName()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.entries[key]; ok && cached.uid == obj.GetUID() && cached.generation == obj.GetGeneration() && cached.revision == revision {
+		return cached.list
+	}
+
+	list := hashResolvedList(resolveFn())
+	c.entries[key] = cachedList{uid: obj.GetUID(), generation: obj.GetGeneration(), revision: revision, list: list}
+	return list
+}
+
+// hashResolvedList sets the content hash of list
+func hashResolvedList(list *resolvedList) *resolvedList {
+	// Marshaling plain slices of strings and bools cannot fail
+	data, _ := json.Marshal(list)
+	sum := sha256.Sum256(data)
+	list.hash = hex.EncodeToString(sum[:])
+	return list
+}
+
+// resolveDomainList resolves allowlist or denylist entries
+func resolveDomainList(domains []nextdnsv1alpha1.DomainEntry) *resolvedList {
+	list := &resolvedList{Domains: make([]nextdns.DomainEntry, 0, len(domains))}
+	for _, entry := range domains {
+		active := entry.Active == nil || *entry.Active
+		list.Domains = append(list.Domains, nextdns.DomainEntry{
+			Domain: entry.Domain,
+			Active: active,
+		})
+		if active {
+			list.count++
+		}
+	}
+	return list
+}
+
+// resolveTLDList resolves the active TLDs of a TLD list
+func resolveTLDList(tlds []nextdnsv1alpha1.TLDEntry) *resolvedList {
+	list := &resolvedList{TLDs: make([]string, 0, len(tlds))}
+	for _, entry := range tlds {
+		if entry.Active == nil || *entry.Active {
+			list.TLDs = append(list.TLDs, entry.TLD)
+		}
+	}
+	list.count = len(list.TLDs)
+	return list
+}
+
+// resolveRewriteList resolves the active rewrites of a rewrite list
+func resolveRewriteList(rewrites []nextdnsv1alpha1.RewriteEntry) *resolvedList {
+	list := &resolvedList{Rewrites: activeRewrites(rewrites)}
+	list.count = len(list.Rewrites)
+	return list
+}
+
+// activeRewrites converts rewrites to API entries, skipping inactive ones
+func activeRewrites(rewrites []nextdnsv1alpha1.RewriteEntry) []nextdns.RewriteEntry {
+	entries := make([]nextdns.RewriteEntry, 0, len(rewrites))
+	for _, rw := range rewrites {
+		if rw.Active != nil && !*rw.Active {
+			continue
+		}
+		entries = append(entries, nextdns.RewriteEntry{Name: rw.From, Content: rw.To})
+	}
+	return entries
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+	"github.com/jacaudi/nextdns-operator/internal/perfbudget"
+)
+
+func TestListCache_Resolve(t *testing.T) {
+	cache := newListCache()
+	allowlist := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "uid-1", Generation: 1},
+		Spec: nextdnsv1alpha1.NextDNSAllowlistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{
+				{Domain: "good.example.com"},
+				{Domain: "paused.example.com", Active: ptr.To(false)},
+			},
+		},
+	}
+
+	calls := 0
+	resolve := func() *resolvedList {
+		calls++
+		return resolveDomainList(allowlist.Spec.Domains)
+	}
+
+	first := cache.resolve("NextDNSAllowlist", allowlist, "", resolve)
+	assert.Equal(t, 1, first.count)
+	assert.NotEmpty(t, first.hash)
+	assert.Equal(t, []nextdns.DomainEntry{
+		{Domain: "good.example.com", Active: true},
+		{Domain: "paused.example.com", Active: false},
+	}, first.Domains)
+
+	// Same generation is served from the cache
+	assert.Same(t, first, cache.resolve("NextDNSAllowlist", allowlist, "", resolve))
+	assert.Equal(t, 1, calls)
+
+	// A spec change bumps the generation
+	allowlist.Generation = 2
+	allowlist.Spec.Domains = append(allowlist.Spec.Domains, nextdnsv1alpha1.DomainEntry{Domain: "new.example.com"})
+	second := cache.resolve("NextDNSAllowlist", allowlist, "", resolve)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, second.count)
+	assert.NotEqual(t, first.hash, second.hash)
+
+	// A recreated list starts over at generation 1
+	allowlist.UID = "uid-2"
+	allowlist.Generation = 1
+	cache.resolve("NextDNSAllowlist", allowlist, "", resolve)
+	assert.Equal(t, 3, calls)
+
+	// A new source revision is resolved again
+	cache.resolve("NextDNSAllowlist", allowlist, "sha256:abc", resolve)
+	assert.Equal(t, 4, calls)
+
+	// A nil cache always resolves
+	var none *listCache
+	none.resolve("NextDNSAllowlist", allowlist, "", resolve)
+	assert.Equal(t, 5, calls)
+}
+
+func TestResolveListReferences_SharedCache(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	denylist := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+		},
+	}
+	copyList := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "copy", Namespace: "default"},
+		Spec:       denylist.Spec,
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(denylist, copyList).Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, listCache: newListCache()}
+
+	profile := func(name, list string) *nextdnsv1alpha1.NextDNSProfile {
+		return &nextdnsv1alpha1.NextDNSProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				Name:         name,
+				DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: list}},
+				Denylist:     []nextdnsv1alpha1.DomainEntry{{Domain: name + ".example.com"}},
+			},
+		}
+	}
+
+	first, err := reconciler.resolveListReferences(ctx, profile("one", "shared"))
+	require.NoError(t, err)
+	second, err := reconciler.resolveListReferences(ctx, profile("two", "shared"))
+	require.NoError(t, err)
+	third, err := reconciler.resolveListReferences(ctx, profile("three", "copy"))
+	require.NoError(t, err)
+
+	assert.Len(t, reconciler.listCache.entries, 2)
+
+	// Inline entries stay per profile
+	assert.Equal(t, []nextdns.DomainEntry{
+		{Domain: "ads.example.com", Active: true},
+		{Domain: "one.example.com", Active: true},
+	}, first.Denylist)
+	assert.Equal(t, []nextdns.DomainEntry{
+		{Domain: "ads.example.com", Active: true},
+		{Domain: "two.example.com", Active: true},
+	}, second.Denylist)
+
+	// Lists with the same content report the same hash
+	hash := first.ResourceStatus.Denylists[0].ContentHash
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, hash, second.ResourceStatus.Denylists[0].ContentHash)
+	assert.Equal(t, hash, third.ResourceStatus.Denylists[0].ContentHash)
+}
+
+// largeListProfile returns a reconciler serving a denylist of n domains and
+// a profile referencing it next to n/10 inline allowlist entries
+func largeListProfile(n int, cache *listCache) (*NextDNSProfileReconciler, *nextdnsv1alpha1.NextDNSProfile) {
+	scheme := newTestScheme()
+	denylist := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "blocklist", Namespace: "default", UID: "uid-1", Generation: 1},
+		Spec:       nextdnsv1alpha1.NextDNSDenylistSpec{Domains: largeDomainEntries(n)},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(denylist).Build()
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:         "home",
+			DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "blocklist"}},
+			Allowlist:    largeDomainEntries(n / 10),
+		},
+	}
+	return &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, listCache: cache}, profile
+}
+
+func BenchmarkResolveListReferences(b *testing.B) {
+	ctx := context.Background()
+	for _, n := range []int{10_000, 100_000} {
+		for _, warm := range []bool{false, true} {
+			// A nil cache resolves and hashes the list on every call, like a
+			// changed list; a warm cache only reads it
+			var cache *listCache
+			if warm {
+				cache = newListCache()
+			}
+			reconciler, profile := largeListProfile(n, cache)
+			b.Run(fmt.Sprintf("entries=%d/warm=%t", n, warm), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := reconciler.resolveListReferences(ctx, profile); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestResolveListReferences_PerformanceBudget(t *testing.T) {
+	ctx := context.Background()
+	reconciler, profile := largeListProfile(100_000, nil)
+
+	resolved, err := reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	require.Len(t, resolved.Denylist, 100_000)
+	require.Len(t, resolved.Allowlist, 10_000)
+
+	perfbudget.Enforce(t, perfbudget.Budget{Time: 600 * time.Millisecond, Allocs: 250_000}, func() {
+		_, _ = reconciler.resolveListReferences(ctx, profile)
+	})
+}
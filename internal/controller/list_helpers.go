@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
 )
@@ -80,19 +83,37 @@ func countActiveTLDs(tlds []nextdnsv1alpha1.TLDEntry) int {
 	return count
 }
 
-// setDeletionBlockedCondition sets the DeletionBlocked condition on a list resource.
-func setDeletionBlockedCondition(conditions *[]metav1.Condition, profileRefs []nextdnsv1alpha1.ResourceReference) {
+// countActiveRewrites counts the number of RewriteEntry items where Active is nil or true.
+func countActiveRewrites(rewrites []nextdnsv1alpha1.RewriteEntry) int {
+	count := 0
+	for _, rw := range rewrites {
+		if rw.Active == nil || *rw.Active {
+			count++
+		}
+	}
+	return count
+}
+
+// setDeletionBlockedCondition sets the DeletionBlocked condition on a list
+// resource, emitting a Warning event for obj when deletion becomes blocked
+func setDeletionBlockedCondition(recorder record.EventRecorder, obj runtime.Object, conditions *[]metav1.Condition, profileRefs []nextdnsv1alpha1.ResourceReference) {
+	message := fmt.Sprintf("Cannot delete: used by profiles %s. Remove references first.", formatProfileRefs(profileRefs))
+	if !meta.IsStatusConditionTrue(*conditions, "DeletionBlocked") {
+		recordEvent(recorder, obj, corev1.EventTypeWarning, "DeletionBlocked", "%s", message)
+	}
 	meta.SetStatusCondition(conditions, metav1.Condition{
 		Type:    "DeletionBlocked",
 		Status:  metav1.ConditionTrue,
 		Reason:  "InUseByProfiles",
-		Message: fmt.Sprintf("Cannot delete: used by profiles %s. Remove references first.", formatProfileRefs(profileRefs)),
+		Message: message,
 	})
 }
 
 // findRefsForList iterates over all profiles and returns those that reference a given
 // list resource. The extractRefs function should return the relevant ListReference
-// slice from a profile's spec (e.g. AllowlistRefs, DenylistRefs, or TLDListRefs).
+// slice from a profile's spec (e.g. AllowlistRefs, DenylistRefs, TLDListRefs, or RewriteRefs);
+// references in overlays are included. An empty listNamespace denotes a
+// cluster-scoped list, which is matched by name only.
 func findRefsForList(
 	profiles []nextdnsv1alpha1.NextDNSProfile,
 	listName, listNamespace string,
@@ -101,13 +122,13 @@ func findRefsForList(
 	var refs []nextdnsv1alpha1.ResourceReference
 
 	for _, profile := range profiles {
-		for _, ref := range extractRefs(&profile.Spec) {
+		for _, ref := range allListRefs(&profile.Spec, extractRefs) {
 			namespace := ref.Namespace
 			if namespace == "" {
 				namespace = profile.Namespace
 			}
 
-			if ref.Name == listName && namespace == listNamespace {
+			if ref.Name == listName && (listNamespace == "" || namespace == listNamespace) {
 				refs = append(refs, nextdnsv1alpha1.ResourceReference{
 					Name:      profile.Name,
 					Namespace: profile.Namespace,
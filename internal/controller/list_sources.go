@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// ConditionTypeSourcesReady indicates all list sources were fetched
+const ConditionTypeSourcesReady = "SourcesReady"
+
+// fetchedSources is the result of fetching a list's spec.sources
+type fetchedSources struct {
+	// entries holds the entries of every source with content
+	entries []string
+	// status reports each source in spec order
+	status []nextdnsv1alpha1.ListSourceStatus
+	// revision identifies the fetched content of all sources
+	revision string
+	// interval is the shortest fetch interval of the sources
+	interval time.Duration
+	// err is the first fetch error; sources that failed after an earlier
+	// successful fetch keep contributing their previous content
+	err error
+	// missing is set when a source has no content at all
+	missing bool
+}
+
+// fetchListSources fetches sources through cache. A nil cache or refresh
+// fetches every source regardless of its interval.
+func fetchListSources(ctx context.Context, cache *listsource.Cache, sources []nextdnsv1alpha1.ListSource, refresh bool) *fetchedSources {
+	if cache == nil {
+		cache = listsource.NewCache()
+	}
+
+	result := &fetchedSources{}
+	var digests []string
+	// Verification failures take precedence so they are not masked by
+	// transient fetch errors of other sources
+	fail := func(err error) {
+		if result.err == nil ||
+			(errors.Is(err, listsource.ErrVerificationFailed) && !errors.Is(result.err, listsource.ErrVerificationFailed)) {
+			result.err = err
+		}
+	}
+
+	for _, spec := range sources {
+		interval, err := listsource.Interval(spec)
+		if err != nil {
+			interval = listsource.DefaultInterval
+		}
+		if result.interval == 0 || interval < result.interval {
+			result.interval = interval
+		}
+
+		src, srcErr := listsource.New(spec)
+		if srcErr != nil {
+			err = srcErr
+		}
+		if err != nil {
+			fail(err)
+			result.missing = true
+			result.status = append(result.status, nextdnsv1alpha1.ListSourceStatus{Error: err.Error()})
+			digests = append(digests, "")
+			continue
+		}
+
+		status := nextdnsv1alpha1.ListSourceStatus{Source: src.Key()}
+		maxAge := interval
+		if refresh {
+			maxAge = 0
+		}
+		fetched, err := cache.Get(ctx, src, spec.Checksum, maxAge)
+		if err != nil {
+			fail(err)
+			status.Error = err.Error()
+		}
+		if fetched == nil {
+			result.missing = true
+			result.status = append(result.status, status)
+			digests = append(digests, "")
+			continue
+		}
+
+		entries := listsource.Parse(fetched.Data)
+		result.entries = append(result.entries, entries...)
+		status.Revision = fetched.Revision
+		status.Digest = fetched.Digest
+		status.Count = len(entries)
+		status.LastFetchTime = &metav1.Time{Time: fetched.FetchedAt}
+		result.status = append(result.status, status)
+		digests = append(digests, fetched.Digest)
+	}
+
+	result.revision = strings.Join(digests, ",")
+	return result
+}
+
+// requeueAfter returns the earlier of syncInterval and the next source fetch
+func (f *fetchedSources) requeueAfter(syncInterval time.Duration) time.Duration {
+	if f.interval > 0 && f.interval < syncInterval {
+		return f.interval
+	}
+	return syncInterval
+}
+
+// setSourcesCondition sets the SourcesReady condition of a list, or removes
+// it when the list has no sources, emitting an event for obj when it changes
+func setSourcesCondition(recorder record.EventRecorder, obj runtime.Object, conditions *[]metav1.Condition, fetched *fetchedSources, sourceCount int) {
+	if sourceCount == 0 {
+		meta.RemoveStatusCondition(conditions, ConditionTypeSourcesReady)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:    ConditionTypeSourcesReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Fetched",
+		Message: "All sources fetched",
+	}
+	if fetched.err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "FetchFailed"
+		if errors.Is(fetched.err, listsource.ErrVerificationFailed) {
+			condition.Reason = "SourceVerificationFailed"
+		}
+		condition.Message = fetched.err.Error()
+	}
+	recordConditionEvent(recorder, obj, *conditions, condition)
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// appendSourcedDomains adds active entries for the sourced domains not
+// already in list
+func appendSourcedDomains(list *resolvedList, domains []string) *resolvedList {
+	seen := make(map[string]bool, len(list.Domains))
+	for _, entry := range list.Domains {
+		seen[entry.Domain] = true
+	}
+	for _, domain := range domains {
+		if !seen[domain] {
+			seen[domain] = true
+			list.Domains = append(list.Domains, nextdns.DomainEntry{Domain: domain, Active: true})
+			list.count++
+		}
+	}
+	return list
+}
+
+// appendSourcedTLDs adds the sourced TLDs not already in list
+func appendSourcedTLDs(list *resolvedList, tlds []string) *resolvedList {
+	seen := make(map[string]bool, len(list.TLDs))
+	for _, tld := range list.TLDs {
+		seen[tld] = true
+	}
+	for _, tld := range tlds {
+		if !seen[tld] {
+			seen[tld] = true
+			list.TLDs = append(list.TLDs, tld)
+		}
+	}
+	list.count = len(list.TLDs)
+	return list
+}
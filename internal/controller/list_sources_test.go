@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// newListSourceServer serves list files by path
+func newListSourceServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(data))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNextDNSDenylistReconciler_Reconcile_Sources(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	server := newListSourceServer(t, map[string]string{
+		"/ads.txt": "# ads\nads.example.com\n0.0.0.0 tracker.example.com\nexample.com\n",
+	})
+
+	list := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sourced",
+			Namespace:  "default",
+			Finalizers: []string{DenylistFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "example.com"}},
+			Sources: []nextdnsv1alpha1.ListSource{
+				{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"}, Interval: "10m"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list).
+		WithStatusSubresource(list).
+		Build()
+	r := &NextDNSDenylistReconciler{Client: fakeClient, Scheme: scheme, SyncPeriod: time.Hour, ListSources: listsource.NewCache()}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sourced", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, result.RequeueAfter, "requeued for the next source fetch")
+
+	var updated nextdnsv1alpha1.NextDNSDenylist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, 3, updated.Status.DomainCount, "sourced duplicates of inline domains are counted once")
+	require.Len(t, updated.Status.Sources, 1)
+	assert.Equal(t, server.URL+"/ads.txt", updated.Status.Sources[0].Source)
+	assert.Equal(t, 3, updated.Status.Sources[0].Count)
+	assert.NotEmpty(t, updated.Status.Sources[0].Digest)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeSourcesReady))
+
+	// A checksum mismatch fails verification
+	updated.Spec.Sources[0].Checksum = listsource.Digest([]byte("other"))
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeSourcesReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "SourceVerificationFailed", cond.Reason)
+	assert.Equal(t, 1, updated.Status.DomainCount)
+}
+
+func TestResolveListReferences_Sources(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	server := newListSourceServer(t, map[string]string{
+		"/ads.txt":  "ads.example.com\n",
+		"/tlds.txt": ".zip\nmov\n",
+	})
+
+	denylist := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "ads", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com", Active: ptr.To(false)}},
+			Sources: []nextdnsv1alpha1.ListSource{
+				{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"}},
+			},
+		},
+	}
+	tldList := &nextdnsv1alpha1.NextDNSTLDList{
+		ObjectMeta: metav1.ObjectMeta{Name: "tlds", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSTLDListSpec{
+			Sources: []nextdnsv1alpha1.ListSource{
+				{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/tlds.txt"}},
+			},
+		},
+	}
+	missing := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Sources: []nextdnsv1alpha1.ListSource{
+				{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/missing.txt"}},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(denylist, tldList, missing).Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, listCache: newListCache(), ListSources: listsource.NewCache()}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:         "profile",
+			DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "ads"}},
+			TLDListRefs:  []nextdnsv1alpha1.ListReference{{Name: "tlds"}},
+		},
+	}
+
+	resolved, err := reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []nextdns.DomainEntry{{Domain: "ads.example.com", Active: false}}, resolved.Denylist,
+		"inline entries take precedence over sourced ones")
+	assert.Equal(t, []string{"zip", "mov"}, resolved.TLDs)
+
+	// A source that was never fetched fails the resolution
+	profile.Spec.DenylistRefs = []nextdnsv1alpha1.ListReference{{Name: "missing"}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	assert.ErrorContains(t, err, "failed to fetch list sources")
+}
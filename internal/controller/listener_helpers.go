@@ -0,0 +1,366 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+const (
+	// listenerDoH names the DNS-over-HTTPS listener's ports and volume
+	listenerDoH = "doh"
+
+	// listenerDoT names the DNS-over-TLS listener's ports and volume
+	listenerDoT = "dot"
+
+	// AnnotationDoHTLSHash records the hash of the DoH listener certificate
+	// on the pod template, so pods restart to load a renewed certificate
+	AnnotationDoHTLSHash = "nextdns.io/doh-tls-hash"
+
+	// AnnotationDoTTLSHash records the hash of the DoT listener certificate
+	// on the pod template, so pods restart to load a renewed certificate
+	AnnotationDoTTLSHash = "nextdns.io/dot-tls-hash"
+
+	// certManagerCertificateNameAnnotation is set by cert-manager on the
+	// Secrets it issues certificates to
+	certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+)
+
+// CertificateGVK identifies the cert-manager Certificate kind
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// errInvalidListener reports a spec.listeners entry the controller cannot serve
+var errInvalidListener = errors.New("invalid spec.listeners")
+
+// tlsListener is an enabled encrypted listener of a NextDNSCoreDNS
+type tlsListener struct {
+	// name is "doh" or "dot"
+	name            string
+	port            int32
+	tlsSecretName   string
+	certificateName string
+
+	// secret holds the certificate once resolved
+	secret *corev1.Secret
+}
+
+// protocol returns the endpoint protocol reported in status
+func (l tlsListener) protocol() string {
+	if l.name == listenerDoT {
+		return "DoT"
+	}
+	return "DoH"
+}
+
+// hashAnnotation returns the pod template annotation holding the
+// certificate hash
+func (l tlsListener) hashAnnotation() string {
+	if l.name == listenerDoT {
+		return AnnotationDoTTLSHash
+	}
+	return AnnotationDoHTLSHash
+}
+
+// mountPath returns the directory the certificate is mounted at
+func (l tlsListener) mountPath() string {
+	return coredns.TLSMountPath + "/" + l.name
+}
+
+// corefileConfig returns the Corefile server block config of the listener
+func (l tlsListener) corefileConfig() *coredns.TLSListenerConfig {
+	return &coredns.TLSListenerConfig{
+		Port:     l.port,
+		CertFile: l.mountPath() + "/" + corev1.TLSCertKey,
+		KeyFile:  l.mountPath() + "/" + corev1.TLSPrivateKeyKey,
+	}
+}
+
+// newCertificate returns an empty cert-manager Certificate object
+func newCertificate() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	return certificate
+}
+
+// tlsListeners returns the enabled encrypted listeners of coreDNS, DoH first
+func tlsListeners(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) []tlsListener {
+	listeners := coreDNS.Spec.Listeners
+	if listeners == nil {
+		return nil
+	}
+
+	var result []tlsListener
+	if doh := listeners.DoH; doh != nil && boolValue(doh.Enabled, true) {
+		port := coredns.DefaultDoHListenerPort
+		if doh.Port != nil {
+			port = *doh.Port
+		}
+		result = append(result, tlsListener{
+			name:            listenerDoH,
+			port:            port,
+			tlsSecretName:   doh.TLSSecretName,
+			certificateName: doh.CertificateName,
+		})
+	}
+	if dot := listeners.DoT; dot != nil && boolValue(dot.Enabled, true) {
+		port := coredns.DefaultDoTListenerPort
+		if dot.Port != nil {
+			port = *dot.Port
+		}
+		result = append(result, tlsListener{
+			name:            listenerDoT,
+			port:            port,
+			tlsSecretName:   dot.TLSSecretName,
+			certificateName: dot.CertificateName,
+		})
+	}
+	return result
+}
+
+// validateTLSListeners checks that each encrypted listener names exactly one
+// certificate source and does not share a port with another listener
+func validateTLSListeners(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) error {
+	inUse := map[int32]string{dnsPort: "DNS", metricsPort(coreDNS): "metrics"}
+	if healthPluginEnabled(coreDNS) {
+		inUse[livenessProbePort(coreDNS)] = "health"
+	}
+	if readyPluginEnabled(coreDNS) {
+		inUse[readinessProbePort(coreDNS)] = "ready"
+	}
+
+	for _, listener := range tlsListeners(coreDNS) {
+		if (listener.tlsSecretName == "") == (listener.certificateName == "") {
+			return fmt.Errorf("%w: %s: exactly one of tlsSecretName or certificateName must be set", errInvalidListener, listener.name)
+		}
+		if name, ok := inUse[listener.port]; ok {
+			return fmt.Errorf("%w: %s: port %d is already used by the %s listener", errInvalidListener, listener.name, listener.port, name)
+		}
+		inUse[listener.port] = listener.protocol()
+	}
+	return nil
+}
+
+// resolveTLSListeners returns the enabled encrypted listeners with their
+// certificate Secrets. A cert-manager Certificate is resolved to the Secret
+// it issues to.
+func (r *NextDNSCoreDNSReconciler) resolveTLSListeners(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]tlsListener, error) {
+	listeners := tlsListeners(coreDNS)
+	for i := range listeners {
+		secret, err := r.listenerTLSSecret(ctx, coreDNS.Namespace, listeners[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s listener: %w", listeners[i].name, err)
+		}
+		listeners[i].secret = secret
+	}
+	return listeners, nil
+}
+
+// listenerTLSSecret returns the Secret holding the certificate of listener
+func (r *NextDNSCoreDNSReconciler) listenerTLSSecret(ctx context.Context, namespace string, listener tlsListener) (*corev1.Secret, error) {
+	secretName := listener.tlsSecretName
+	if listener.certificateName != "" {
+		certificate := newCertificate()
+		if err := r.Get(ctx, types.NamespacedName{Name: listener.certificateName, Namespace: namespace}, certificate); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("certificate %s not found", listener.certificateName)
+			}
+			return nil, fmt.Errorf("failed to get Certificate %s: %w", listener.certificateName, err)
+		}
+		name, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		if name == "" {
+			return nil, fmt.Errorf("certificate %s has no spec.secretName", listener.certificateName)
+		}
+		secretName = name
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("TLS secret %s not found", secretName)
+		}
+		return nil, fmt.Errorf("failed to get TLS secret %s: %w", secretName, err)
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, fmt.Errorf("TLS secret %s must contain %s and %s", secretName, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	return secret, nil
+}
+
+// applyTLSListeners adds the port and certificate volume of each encrypted
+// listener to a CoreDNS pod template, and annotates it with the certificate
+// hashes
+func applyTLSListeners(template *corev1.PodTemplateSpec, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, listeners []tlsListener) {
+	for _, listener := range listeners {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[listener.hashAnnotation()] = secretDataHash(listener.secret.Data)
+
+		port := corev1.ContainerPort{
+			Name:          listener.name,
+			ContainerPort: listener.port,
+			Protocol:      corev1.ProtocolTCP,
+		}
+		if hostPortEnabled(coreDNS) {
+			port.HostPort = port.ContainerPort
+		}
+
+		volumeName := listener.name + "-tls"
+		container := &template.Spec.Containers[0]
+		container.Ports = append(container.Ports, port)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: listener.mountPath(),
+			ReadOnly:  true,
+		})
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: listener.secret.Name,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+						{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+					},
+				},
+			},
+		})
+	}
+}
+
+// tlsListenerServicePorts returns the Service ports exposing the encrypted
+// listeners
+func tlsListenerServicePorts(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	for _, listener := range tlsListeners(coreDNS) {
+		ports = append(ports, corev1.ServicePort{
+			Name:       listener.name,
+			Port:       listener.port,
+			TargetPort: intstr.FromInt32(listener.port),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+	return ports
+}
+
+// dnsEndpoints returns the status endpoints served on ip: plain DNS over UDP
+// and TCP, then the encrypted listeners
+func dnsEndpoints(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, ip string) []nextdnsv1alpha1.DNSEndpoint {
+	endpoints := []nextdnsv1alpha1.DNSEndpoint{
+		{IP: ip, Port: dnsPort, Protocol: "UDP"},
+		{IP: ip, Port: dnsPort, Protocol: "TCP"},
+	}
+	for _, listener := range tlsListeners(coreDNS) {
+		endpoints = append(endpoints, nextdnsv1alpha1.DNSEndpoint{IP: ip, Port: listener.port, Protocol: listener.protocol()})
+	}
+	return endpoints
+}
+
+// listenerServerName returns the first DNS name of the certificate in
+// secret, or "" if it has none
+func listenerServerName(secret *corev1.Secret) string {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || len(cert.DNSNames) == 0 {
+		return ""
+	}
+	return cert.DNSNames[0]
+}
+
+// endpointStamp returns the DNS stamp of endpoint, or "" if it cannot be
+// expressed as one. serverName is the TLS server name of an encrypted
+// listener; the endpoint address is used when it is empty.
+func endpointStamp(endpoint nextdnsv1alpha1.DNSEndpoint, serverName string) string {
+	isIP := net.ParseIP(endpoint.IP) != nil
+
+	var stamp coredns.Stamp
+	var defaultPort int32
+	switch endpoint.Protocol {
+	case "UDP", "TCP":
+		if !isIP {
+			return ""
+		}
+		stamp.Protocol, defaultPort = coredns.StampProtocolPlain, dnsPort
+	case "DoH":
+		stamp.Protocol, defaultPort = coredns.StampProtocolDoH, coredns.DefaultDoHListenerPort
+		stamp.Path = coredns.DoHPath
+	case "DoT":
+		stamp.Protocol, defaultPort = coredns.StampProtocolDoT, coredns.DefaultDoTListenerPort
+	default:
+		return ""
+	}
+
+	if isIP {
+		stamp.Addr = coredns.StampAddr(endpoint.IP, endpoint.Port, defaultPort)
+	}
+	if stamp.Protocol != coredns.StampProtocolPlain {
+		stamp.Hostname = serverName
+		if stamp.Hostname == "" {
+			stamp.Hostname = endpoint.IP
+		}
+		if !isIP && endpoint.Port != defaultPort {
+			stamp.Hostname = net.JoinHostPort(stamp.Hostname, fmt.Sprint(endpoint.Port))
+		}
+	}
+	return stamp.String()
+}
+
+// setEndpointStamps fills in the DNS stamp of each status endpoint, naming
+// the encrypted listeners by their certificate
+func (r *NextDNSCoreDNSReconciler) setEndpointStamps(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	serverNames := map[string]string{}
+	if listeners, err := r.resolveTLSListeners(ctx, coreDNS); err == nil {
+		for _, listener := range listeners {
+			serverNames[listener.protocol()] = listenerServerName(listener.secret)
+		}
+	}
+	for i := range coreDNS.Status.Endpoints {
+		endpoint := &coreDNS.Status.Endpoints[i]
+		endpoint.Stamp = endpointStamp(*endpoint, serverNames[endpoint.Protocol])
+	}
+}
+
+// findCoreDNSForSecret maps a Secret to the NextDNSCoreDNS resources serving
+// an encrypted listener with its certificate, directly or through a
+// cert-manager Certificate
+func (r *NextDNSCoreDNSReconciler) findCoreDNSForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list nextdnsv1alpha1.NextDNSCoreDNSList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NextDNSCoreDNS resources for Secret", "secret", obj.GetName())
+		return nil
+	}
+
+	certificateName := obj.GetAnnotations()[certManagerCertificateNameAnnotation]
+	var requests []reconcile.Request
+	for _, coreDNS := range list.Items {
+		for _, listener := range tlsListeners(&coreDNS) {
+			if listener.tlsSecretName == obj.GetName() ||
+				(listener.certificateName != "" && listener.certificateName == certificateName) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: coreDNS.Name, Namespace: coreDNS.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
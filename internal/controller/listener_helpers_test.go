@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// newCertificateTestScheme registers the cert-manager Certificate as an
+// unstructured kind
+func newCertificateTestScheme() *runtime.Scheme {
+	scheme := newCoreDNSTestScheme()
+	scheme.AddKnownTypeWithName(CertificateGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(CertificateGVK.GroupVersion().WithKind(CertificateGVK.Kind+"List"), &unstructured.UnstructuredList{})
+	return scheme
+}
+
+// newListenersCoreDNS returns a NextDNSCoreDNS serving the given encrypted listeners
+func newListenersCoreDNS(listeners *nextdnsv1alpha1.CoreDNSListenersConfig) *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "tls-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Listeners:  listeners,
+		},
+	}
+}
+
+// newTLSSecret returns a kubernetes.io/tls Secret holding a placeholder certificate
+func newTLSSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("certificate"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+}
+
+func TestValidateTLSListeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners *nextdnsv1alpha1.CoreDNSListenersConfig
+		wantErr   string
+	}{
+		{name: "not configured"},
+		{
+			name: "disabled",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{Enabled: ptr.To(false)},
+			},
+		},
+		{
+			name: "secret and certificate",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{TLSSecretName: "dns-tls"},
+				DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{CertificateName: "dns"},
+			},
+		},
+		{
+			name: "no certificate source",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{},
+			},
+			wantErr: "dot: exactly one of tlsSecretName or certificateName",
+		},
+		{
+			name: "both certificate sources",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{TLSSecretName: "dns-tls", CertificateName: "dns"},
+			},
+			wantErr: "doh: exactly one of tlsSecretName or certificateName",
+		},
+		{
+			name: "port used by DNS",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{TLSSecretName: "dns-tls", Port: ptr.To[int32](53)},
+			},
+			wantErr: "port 53 is already used by the DNS listener",
+		},
+		{
+			name: "port used by metrics",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{TLSSecretName: "dns-tls", Port: ptr.To[int32](9153)},
+			},
+			wantErr: "port 9153 is already used by the metrics listener",
+		},
+		{
+			name: "port used by the other listener",
+			listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{TLSSecretName: "dns-tls", Port: ptr.To[int32](853)},
+				DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{TLSSecretName: "dns-tls"},
+			},
+			wantErr: "dot: port 853 is already used by the DoH listener",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTLSListeners(newListenersCoreDNS(tt.listeners))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errInvalidListener)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_TLSListeners(t *testing.T) {
+	scheme := newCertificateTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := newListenersCoreDNS(&nextdnsv1alpha1.CoreDNSListenersConfig{
+		DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{CertificateName: "dns", Port: ptr.To[int32](8443)},
+		DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{TLSSecretName: "dot-tls"},
+	})
+
+	certificate := newCertificate()
+	certificate.SetName("dns")
+	certificate.SetNamespace("default")
+	require.NoError(t, unstructured.SetNestedField(certificate.Object, "dns-tls", "spec", "secretName"))
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS, certificate, newTLSSecret("dot-tls")).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tls-dns", Namespace: "default"}}
+	resourceKey := types.NamespacedName{Name: "tls-dns-abc123-coredns", Namespace: "default"}
+
+	// The Certificate has not been issued yet
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	ready := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, "ListenerCertificateUnavailable", ready.Reason)
+	assert.Contains(t, ready.Message, "doh listener: TLS secret dns-tls not found")
+
+	// Once issued, the certificates are mounted and both listeners are served and exposed
+	require.NoError(t, fakeClient.Create(ctx, newTLSSecret("dns-tls")))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, configMap))
+	assert.Contains(t, configMap.Data[CorefileKey], "https://.:8443 {\n    tls /etc/coredns-tls/doh/tls.crt /etc/coredns-tls/doh/tls.key\n")
+	assert.Contains(t, configMap.Data[CorefileKey], "tls://.:853 {\n    tls /etc/coredns-tls/dot/tls.crt /etc/coredns-tls/dot/tls.key\n")
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, deployment))
+	podSpec := deployment.Spec.Template.Spec
+	assert.Contains(t, podSpec.Containers[0].Ports, corev1.ContainerPort{Name: "doh", ContainerPort: 8443, Protocol: corev1.ProtocolTCP})
+	assert.Contains(t, podSpec.Containers[0].Ports, corev1.ContainerPort{Name: "dot", ContainerPort: 853, Protocol: corev1.ProtocolTCP})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "doh-tls", MountPath: "/etc/coredns-tls/doh", ReadOnly: true})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "dot-tls", MountPath: "/etc/coredns-tls/dot", ReadOnly: true})
+	require.Len(t, podSpec.Volumes, 3)
+	assert.Equal(t, "dns-tls", podSpec.Volumes[1].Secret.SecretName)
+	assert.Equal(t, "dot-tls", podSpec.Volumes[2].Secret.SecretName)
+	hash := deployment.Spec.Template.Annotations[AnnotationDoHTLSHash]
+	assert.NotEmpty(t, hash)
+	assert.NotEmpty(t, deployment.Spec.Template.Annotations[AnnotationDoTTLSHash])
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, service))
+	assert.Equal(t, tlsListenerServicePorts(coreDNS), service.Spec.Ports[3:])
+
+	// The encrypted endpoints are reported next to plain DNS. The placeholder
+	// certificates have no DNS names, so the stamps name the address.
+	service.Spec.ClusterIP = "10.96.0.53"
+	require.NoError(t, fakeClient.Update(ctx, service))
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	require.NoError(t, r.updateStatus(ctx, &updated, profile))
+	assert.Equal(t, []nextdnsv1alpha1.DNSEndpoint{
+		{IP: "10.96.0.53", Port: 53, Protocol: "UDP", Stamp: "sdns://AAAAAAAAAAAACjEwLjk2LjAuNTM"},
+		{IP: "10.96.0.53", Port: 53, Protocol: "TCP", Stamp: "sdns://AAAAAAAAAAAACjEwLjk2LjAuNTM"},
+		{IP: "10.96.0.53", Port: 8443, Protocol: "DoH", Stamp: "sdns://AgAAAAAAAAAADzEwLjk2LjAuNTM6ODQ0MwAKMTAuOTYuMC41MwovZG5zLXF1ZXJ5"},
+		{IP: "10.96.0.53", Port: 853, Protocol: "DoT", Stamp: "sdns://AwAAAAAAAAAACjEwLjk2LjAuNTMACjEwLjk2LjAuNTM"},
+	}, updated.Status.Endpoints)
+
+	// A renewed certificate rolls the pods
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "dns-tls", Namespace: "default"}, secret))
+	secret.Data[corev1.TLSCertKey] = []byte("renewed")
+	require.NoError(t, fakeClient.Update(ctx, secret))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, deployment))
+	assert.NotEqual(t, hash, deployment.Spec.Template.Annotations[AnnotationDoHTLSHash])
+}
+
+func TestFindCoreDNSForSecret(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	bySecret := newListenersCoreDNS(&nextdnsv1alpha1.CoreDNSListenersConfig{
+		DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{TLSSecretName: "dns-tls"},
+	})
+	byCertificate := newListenersCoreDNS(&nextdnsv1alpha1.CoreDNSListenersConfig{
+		DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{CertificateName: "dns"},
+		DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{CertificateName: "dns"},
+	})
+	byCertificate.Name = "cert-dns"
+	plain := newListenersCoreDNS(nil)
+	plain.Name = "plain-dns"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(bySecret, byCertificate, plain).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	requests := r.findCoreDNSForSecret(context.Background(), newTLSSecret("dns-tls"))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "tls-dns", requests[0].Name)
+
+	issued := newTLSSecret("issued-tls")
+	issued.Annotations = map[string]string{certManagerCertificateNameAnnotation: "dns"}
+	requests = r.findCoreDNSForSecret(context.Background(), issued)
+	require.Len(t, requests, 1, "a resource is enqueued once even if both listeners use the Secret")
+	assert.Equal(t, "cert-dns", requests[0].Name)
+
+	assert.Empty(t, r.findCoreDNSForSecret(context.Background(), newTLSSecret("other")))
+}
+
+func TestEndpointStamp(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   nextdnsv1alpha1.DNSEndpoint
+		serverName string
+		want       string
+	}{
+		{
+			name:     "plain DNS",
+			endpoint: nextdnsv1alpha1.DNSEndpoint{IP: "10.96.0.53", Port: 53, Protocol: "UDP"},
+			want:     "sdns://AAAAAAAAAAAACjEwLjk2LjAuNTM",
+		},
+		{
+			name:     "plain DNS on a hostname",
+			endpoint: nextdnsv1alpha1.DNSEndpoint{IP: "lb.example.com", Port: 53, Protocol: "TCP"},
+		},
+		{
+			name:       "DoH named by its certificate",
+			endpoint:   nextdnsv1alpha1.DNSEndpoint{IP: "192.168.1.53", Port: 443, Protocol: "DoH"},
+			serverName: "dns.home.example",
+			want:       "sdns://AgAAAAAAAAAADDE5Mi4xNjguMS41MwAQZG5zLmhvbWUuZXhhbXBsZQovZG5zLXF1ZXJ5",
+		},
+		{
+			name:     "DoT on a hostname and custom port",
+			endpoint: nextdnsv1alpha1.DNSEndpoint{IP: "lb.example.com", Port: 8853, Protocol: "DoT"},
+			want:     "sdns://AwAAAAAAAAAAAAATbGIuZXhhbXBsZS5jb206ODg1Mw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, endpointStamp(tt.endpoint, tt.serverName))
+		})
+	}
+}
+
+func TestListenerServerName(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"dns.home.example", "dns"},
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	secret := newTLSSecret("dns-tls")
+	secret.Data[corev1.TLSCertKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	assert.Equal(t, "dns.home.example", listenerServerName(secret))
+
+	// Not a certificate
+	assert.Empty(t, listenerServerName(newTLSSecret("dns-tls")))
+}
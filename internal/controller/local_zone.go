@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+const (
+	// LocalZoneKey is the key in the ConfigMap for the local zone file
+	LocalZoneKey = "local.zone"
+
+	// AnnotationLocalZoneHash records the hash of the local zone records on
+	// the ConfigMap, so the serial only changes with them
+	AnnotationLocalZoneHash = "nextdns.io/local-zone-hash"
+
+	// AnnotationLocalZoneSerial records the SOA serial of the local zone on
+	// the ConfigMap
+	AnnotationLocalZoneSerial = "nextdns.io/local-zone-serial"
+)
+
+// localZoneTransfer returns the local zone configuration, or nil unless it
+// is enabled
+func localZoneTransfer(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.LocalZoneTransferConfig {
+	cf := coreDNS.Spec.Corefile
+	if cf == nil || cf.LocalZoneTransfer == nil || !boolWithDefault(cf.LocalZoneTransfer.Enabled, true) {
+		return nil
+	}
+	return cf.LocalZoneTransfer
+}
+
+// applyLocalZone writes the zone file of cfg.LocalZone to configMap and
+// returns its status, or removes the zone bookkeeping and returns nil when
+// the local zone is disabled. The SOA serial is kept while the records are
+// unchanged; otherwise it becomes the current Unix time, or one past the
+// previous serial if that is later, so secondaries see it increase.
+func applyLocalZone(configMap *corev1.ConfigMap, cfg *coredns.CorefileConfig, now time.Time) *nextdnsv1alpha1.LocalZoneStatus {
+	if cfg.LocalZone == nil {
+		delete(configMap.Annotations, AnnotationLocalZoneHash)
+		delete(configMap.Annotations, AnnotationLocalZoneSerial)
+		return nil
+	}
+
+	zone := cfg.LocalZone.Zone
+	records := coredns.LocalZoneRecords(zone, cfg.Hosts, cfg.LocalRecords)
+	hash := corefileHash(coredns.GenerateZoneFile(zone, 0, records))
+
+	previous, _ := strconv.ParseUint(configMap.Annotations[AnnotationLocalZoneSerial], 10, 32)
+	serial := uint32(previous)
+	if serial == 0 || hash != configMap.Annotations[AnnotationLocalZoneHash] {
+		serial = max(uint32(now.Unix()), serial+1)
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[AnnotationLocalZoneHash] = hash
+	configMap.Annotations[AnnotationLocalZoneSerial] = strconv.FormatUint(uint64(serial), 10)
+	configMap.Data[LocalZoneKey] = coredns.GenerateZoneFile(zone, serial, records)
+
+	return &nextdnsv1alpha1.LocalZoneStatus{
+		Zone:    zone,
+		Serial:  int64(serial),
+		Records: int32(len(records)),
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+func newLocalZoneCoreDNS() *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				LocalRecords: []nextdnsv1alpha1.LocalRecord{
+					{Name: "nas.home.arpa", Value: "192.168.1.10"},
+				},
+				LocalZoneTransfer: &nextdnsv1alpha1.LocalZoneTransferConfig{
+					Zone:         "home.arpa",
+					AllowedCIDRs: []string{"192.168.1.1/32"},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyLocalZone(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	coreDNS := newLocalZoneCoreDNS()
+	profile := &nextdnsv1alpha1.NextDNSProfile{Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"}}
+	now := time.Unix(1760601600, 0)
+
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	configMap := &corev1.ConfigMap{Data: map[string]string{}}
+
+	status := applyLocalZone(configMap, cfg, now)
+	assert.Equal(t, &nextdnsv1alpha1.LocalZoneStatus{Zone: "home.arpa", Serial: 1760601600, Records: 1}, status)
+	assert.Contains(t, configMap.Data[LocalZoneKey], "hostmaster.home.arpa. 1760601600 ")
+	assert.Contains(t, configMap.Data[LocalZoneKey], "nas.home.arpa. 3600 IN A 192.168.1.10\n")
+
+	// Unchanged records keep the serial
+	status = applyLocalZone(configMap, cfg, now.Add(time.Hour))
+	assert.Equal(t, int64(1760601600), status.Serial)
+
+	// Changed records increase it, even if the clock has not moved on
+	coreDNS.Spec.Corefile.LocalRecords[0].Value = "192.168.1.11"
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	status = applyLocalZone(configMap, cfg, now.Add(-time.Hour))
+	assert.Equal(t, int64(1760601601), status.Serial)
+
+	// Disabling removes the bookkeeping
+	coreDNS.Spec.Corefile.LocalZoneTransfer.Enabled = ptr.To(false)
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.LocalZone)
+	assert.Nil(t, applyLocalZone(configMap, cfg, now))
+	assert.NotContains(t, configMap.Annotations, AnnotationLocalZoneSerial)
+}
+
+func TestBuildCorefileConfig_InvalidLocalZone(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	coreDNS := newLocalZoneCoreDNS()
+	coreDNS.Spec.Corefile.LocalZoneTransfer.AllowedCIDRs = []string{"192.168.1.1"}
+
+	_, err := r.buildCorefileConfig(coreDNS, &nextdnsv1alpha1.NextDNSProfile{})
+	assert.ErrorContains(t, err, `invalid CIDR "192.168.1.1"`)
+}
+
+func TestBuildPodSpec_LocalZone(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	podSpec := r.buildPodSpec(newLocalZoneCoreDNS(), "test-cm")
+	assert.Equal(t, []corev1.KeyToPath{
+		{Key: CorefileKey, Path: "Corefile"},
+		{Key: LocalZoneKey, Path: "local.zone"},
+	}, podSpec.Volumes[0].ConfigMap.Items)
+	assert.Equal(t, "/etc/coredns/"+LocalZoneKey, coredns.LocalZonePath)
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+const (
+	// dnsPort is the port CoreDNS serves and forwards plain DNS on
+	dnsPort int32 = 53
+
+	// dotPort is the NextDNS DNS-over-TLS port
+	dotPort int32 = 853
+
+	// dohPort is the NextDNS DNS-over-HTTPS port
+	dohPort int32 = 443
+)
+
+// networkPolicyConfig returns the NetworkPolicy config when one should exist,
+// or nil otherwise
+func networkPolicyConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.CoreDNSNetworkPolicyConfig {
+	np := coreDNS.Spec.NetworkPolicy
+	if np == nil || !boolValue(np.Enabled, true) {
+		return nil
+	}
+	return np
+}
+
+// networkPolicyPorts returns a port for each of the given protocols
+func networkPolicyPorts(port int32, protocols ...corev1.Protocol) []networkingv1.NetworkPolicyPort {
+	ports := make([]networkingv1.NetworkPolicyPort, len(protocols))
+	for i, protocol := range protocols {
+		p := intstr.FromInt32(port)
+		ports[i] = networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &p}
+	}
+	return ports
+}
+
+// ipBlockPeer returns a peer matching a single IP address
+func ipBlockPeer(ip string) networkingv1.NetworkPolicyPeer {
+	cidr := ip + "/32"
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		cidr = ip + "/128"
+	}
+	return networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}}
+}
+
+// buildNetworkPolicyIngress allows DNS, encrypted listener and metrics
+// traffic from the configured namespaces and CIDRs, or from anywhere when
+// none are configured
+func buildNetworkPolicyIngress(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, cfg *nextdnsv1alpha1.CoreDNSNetworkPolicyConfig) []networkingv1.NetworkPolicyIngressRule {
+	ports := networkPolicyPorts(dnsPort, corev1.ProtocolUDP, corev1.ProtocolTCP)
+	for _, listener := range tlsListeners(coreDNS) {
+		ports = append(ports, networkPolicyPorts(listener.port, corev1.ProtocolTCP)...)
+	}
+	ports = append(ports, networkPolicyPorts(metricsPort(coreDNS), corev1.ProtocolTCP)...)
+
+	var from []networkingv1.NetworkPolicyPeer
+	if len(cfg.AllowedNamespaces) > 0 {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   cfg.AllowedNamespaces,
+				}},
+			},
+		})
+	}
+	for _, cidr := range cfg.AllowedCIDRs {
+		from = append(from, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+	}
+
+	return []networkingv1.NetworkPolicyIngressRule{{Ports: ports, From: from}}
+}
+
+// buildNetworkPolicyEgress allows traffic to the NextDNS upstreams and the
+// domain override upstreams. DoT and plain DNS are pinned to the upstream IPs
+// in the Corefile. DoH resolves dns.nextdns.io through cluster DNS and may
+// reach any NextDNS anycast address, so HTTPS is allowed to any destination.
+func buildNetworkPolicyEgress(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) []networkingv1.NetworkPolicyEgressRule {
+	protocol := upstreamProtocol(coreDNS)
+	cf := coreDNS.Spec.Corefile
+
+	var rules []networkingv1.NetworkPolicyEgressRule
+	if protocol == coredns.ProtocolDoH {
+		rules = append(rules,
+			networkingv1.NetworkPolicyEgressRule{Ports: networkPolicyPorts(dohPort, corev1.ProtocolTCP)},
+			networkingv1.NetworkPolicyEgressRule{
+				Ports: networkPolicyPorts(dnsPort, corev1.ProtocolUDP, corev1.ProtocolTCP),
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{corev1.LabelMetadataName: metav1.NamespaceSystem},
+					},
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+					},
+				}},
+			},
+		)
+	} else {
+		var to []networkingv1.NetworkPolicyPeer
+		for _, ip := range coredns.UpstreamAddresses(profileUpstreamIPs(coreDNS, profile)) {
+			to = append(to, ipBlockPeer(ip))
+		}
+		ports := networkPolicyPorts(dotPort, corev1.ProtocolTCP)
+		if protocol == coredns.ProtocolDNS {
+			ports = networkPolicyPorts(dnsPort, corev1.ProtocolUDP, corev1.ProtocolTCP)
+		}
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{Ports: ports, To: to})
+	}
+
+	if cf == nil {
+		return rules
+	}
+	for _, override := range cf.DomainOverrides {
+		for _, upstream := range override.Upstreams {
+			host, port := upstream, dnsPort
+			if h, p, err := net.SplitHostPort(upstream); err == nil {
+				if n, err := strconv.ParseInt(p, 10, 32); err == nil {
+					host, port = h, int32(n)
+				}
+			}
+			rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+				Ports: networkPolicyPorts(port, corev1.ProtocolUDP, corev1.ProtocolTCP),
+				To:    []networkingv1.NetworkPolicyPeer{ipBlockPeer(host)},
+			})
+		}
+	}
+	return rules
+}
+
+// reconcileNetworkPolicy creates, updates, or cleans up the NetworkPolicy for CoreDNS pods
+func (r *NextDNSCoreDNSReconciler) reconcileNetworkPolicy(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+	policyName := r.getResourceName(coreDNS, profile)
+
+	cfg := networkPolicyConfig(coreDNS)
+	if cfg == nil {
+		// Clean up any existing NetworkPolicy
+		existing := &networkingv1.NetworkPolicy{}
+		err := r.Get(ctx, types.NamespacedName{Name: policyName, Namespace: coreDNS.Namespace}, existing)
+		if err == nil {
+			logger.Info("Cleaning up stale NetworkPolicy", "name", policyName)
+			return r.Delete(ctx, existing)
+		}
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	labels := r.buildLabels(coreDNS, profile)
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      policyName,
+			Namespace: coreDNS.Namespace,
+		},
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		policy.Labels = r.ResourceLabels.merge(labels)
+		policy.Spec.PodSelector = metav1.LabelSelector{MatchLabels: labels}
+		policy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+		policy.Spec.Ingress = buildNetworkPolicyIngress(coreDNS, cfg)
+		policy.Spec.Egress = buildNetworkPolicyEgress(coreDNS, profile)
+
+		return controllerutil.SetControllerReference(coreDNS, policy, r.Scheme)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to reconcile NetworkPolicy: %w", err)
+	}
+
+	if op != controllerutil.OperationResultNone {
+		logger.Info("NetworkPolicy reconciled", "operation", op, "name", policyName)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// egressPeers returns the CIDRs and ports of an egress rule
+func egressPeers(rule networkingv1.NetworkPolicyEgressRule) ([]string, []string) {
+	var cidrs, ports []string
+	for _, peer := range rule.To {
+		if peer.IPBlock != nil {
+			cidrs = append(cidrs, peer.IPBlock.CIDR)
+		}
+	}
+	for _, port := range rule.Ports {
+		ports = append(ports, string(*port.Protocol)+"/"+port.Port.String())
+	}
+	return cidrs, ports
+}
+
+func TestBuildNetworkPolicyEgress(t *testing.T) {
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Setup: &nextdnsv1alpha1.ProfileSetup{
+				IPv4: []string{"45.90.28.198", "45.90.30.198"},
+				IPv6: []string{"2a07:a8c0::ab"},
+			},
+		},
+	}
+
+	t.Run("DoT pins the profile upstreams", func(t *testing.T) {
+		coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+			Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+				Corefile: &nextdnsv1alpha1.CorefileSpec{
+					Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDoT, IPv6: true},
+					DomainOverrides: []nextdnsv1alpha1.DomainOverride{
+						{Domain: "corp.example.com", Upstreams: []string{"10.0.0.53", "10.0.0.54:5353"}},
+					},
+				},
+			},
+		}
+
+		rules := buildNetworkPolicyEgress(coreDNS, profile)
+		require.Len(t, rules, 3)
+
+		cidrs, ports := egressPeers(rules[0])
+		assert.Equal(t, []string{"45.90.28.198/32", "45.90.30.198/32", "2a07:a8c0::ab/128"}, cidrs)
+		assert.Equal(t, []string{"TCP/853"}, ports)
+
+		cidrs, ports = egressPeers(rules[1])
+		assert.Equal(t, []string{"10.0.0.53/32"}, cidrs)
+		assert.Equal(t, []string{"UDP/53", "TCP/53"}, ports)
+
+		cidrs, ports = egressPeers(rules[2])
+		assert.Equal(t, []string{"10.0.0.54/32"}, cidrs)
+		assert.Equal(t, []string{"UDP/5353", "TCP/5353"}, ports)
+	})
+
+	t.Run("plain DNS uses port 53", func(t *testing.T) {
+		coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+			Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+				Corefile: &nextdnsv1alpha1.CorefileSpec{
+					Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDNS},
+				},
+			},
+		}
+
+		rules := buildNetworkPolicyEgress(coreDNS, profile)
+		require.Len(t, rules, 1)
+		cidrs, ports := egressPeers(rules[0])
+		assert.Equal(t, []string{"45.90.28.198/32", "45.90.30.198/32"}, cidrs)
+		assert.Equal(t, []string{"UDP/53", "TCP/53"}, ports)
+	})
+
+	t.Run("DoH allows HTTPS and cluster DNS", func(t *testing.T) {
+		coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+			Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+				Corefile: &nextdnsv1alpha1.CorefileSpec{
+					Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDoH},
+				},
+			},
+		}
+
+		rules := buildNetworkPolicyEgress(coreDNS, profile)
+		require.Len(t, rules, 2)
+		_, ports := egressPeers(rules[0])
+		assert.Empty(t, rules[0].To)
+		assert.Equal(t, []string{"TCP/443"}, ports)
+		require.Len(t, rules[1].To, 1)
+		assert.Equal(t, "kube-dns", rules[1].To[0].PodSelector.MatchLabels["k8s-app"])
+	})
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_NetworkPolicy(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "np-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			NetworkPolicy: &nextdnsv1alpha1.CoreDNSNetworkPolicyConfig{
+				AllowedNamespaces: []string{"apps"},
+				AllowedCIDRs:      []string{"192.168.1.0/24"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "np-dns", Namespace: "default"}}
+	policyKey := types.NamespacedName{Name: "np-dns-abc123-coredns", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	policy := &networkingv1.NetworkPolicy{}
+	require.NoError(t, fakeClient.Get(ctx, policyKey, policy), "NetworkPolicy should be created")
+	require.Len(t, policy.OwnerReferences, 1)
+	assert.Equal(t, "np-dns", policy.OwnerReferences[0].Name)
+	assert.Equal(t, "np-dns", policy.Spec.PodSelector.MatchLabels["app.kubernetes.io/instance"])
+	assert.ElementsMatch(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, policy.Spec.PolicyTypes)
+
+	require.Len(t, policy.Spec.Ingress, 1)
+	ingress := policy.Spec.Ingress[0]
+	require.Len(t, ingress.Ports, 3)
+	assert.Equal(t, corev1.ProtocolTCP, *ingress.Ports[2].Protocol)
+	assert.Equal(t, int32(9153), ingress.Ports[2].Port.IntVal)
+	require.Len(t, ingress.From, 2)
+	assert.Equal(t, []string{"apps"}, ingress.From[0].NamespaceSelector.MatchExpressions[0].Values)
+	assert.Equal(t, "192.168.1.0/24", ingress.From[1].IPBlock.CIDR)
+
+	// Without a profile setup, DoT egress is pinned to the anycast IPs
+	cidrs, ports := egressPeers(policy.Spec.Egress[0])
+	assert.Equal(t, []string{"45.90.28.0/32", "45.90.30.0/32"}, cidrs)
+	assert.Equal(t, []string{"TCP/853"}, ports)
+
+	// Disabling the policy deletes it
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.NetworkPolicy.Enabled = ptr.To(false)
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, policyKey, policy)
+	assert.True(t, apierrors.IsNotFound(err), "NetworkPolicy should be deleted when disabled")
+}
@@ -0,0 +1,482 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// NextDNSAccountReconciler reconciles a NextDNSAccount object. It validates
+// the account's API key, lists the profiles in the account, matching them to
+// the NextDNSProfiles syncing them and finding orphans, and applies the
+// account's rate limit to every client using the key.
+type NextDNSAccountReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ClientFactory ClientFactory
+	SyncPeriod    time.Duration
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+
+	// Metrics records orphaned profiles; metrics.Default() is used when nil
+	Metrics *metrics.Metrics
+
+	// APIReader reads NextDNSProfiles bypassing the cache, which
+	// --watch-namespaces and --watch-label-selector can restrict; the
+	// client is used when nil
+	APIReader client.Reader
+
+	// now returns the current time; time.Now when nil
+	now func() time.Time
+
+	// limitedKeys holds the API key whose rate limit each account set, so
+	// the override is removed when the account is deleted or its key changes
+	limitedKeysMu sync.Mutex
+	limitedKeys   map[types.NamespacedName]string
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsaccounts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *NextDNSAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var account nextdnsv1alpha1.NextDNSAccount
+	if err := r.Get(ctx, req.NamespacedName, &account); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.setRateLimit(req.NamespacedName, "", nil)
+			r.metrics().DeleteAccount(req.Name, req.Namespace)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if paused, err := reconcilePaused(ctx, r.Client, &account, &account.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	account.Status.ObservedGeneration = account.Generation
+
+	syncPeriod, err := resourceSyncPeriod(&account, account.Spec.SyncInterval, r.SyncPeriod)
+	if err != nil {
+		logger.Error(err, "Invalid sync interval")
+	}
+
+	apiKey, secretVersion, err := readAPIKey(ctx, r.Client, accountCredentials(&account), account.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get API credentials")
+		reason := "CredentialsNotFound"
+		if errors.Is(err, errCredentialsAccessDenied) {
+			reason = ReasonCrossNamespaceAccessDenied
+		}
+		account.Status.CredentialsSecretVersion = ""
+		r.setCondition(&account, metav1.ConditionFalse, reason, err.Error())
+		if updateErr := r.updateStatus(ctx, &account); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	account.Status.AccountFingerprint = nextdns.AccountFingerprint(apiKey)
+
+	if err := r.setRateLimit(req.NamespacedName, apiKey, account.Spec.RateLimit); err != nil {
+		r.setCondition(&account, metav1.ConditionFalse, "InvalidRateLimit", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &account)
+	}
+
+	factory := r.ClientFactory
+	if factory == nil {
+		factory = DefaultClientFactory
+	}
+	nextdnsClient, err := factory(apiKey)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create NextDNS client: %w", err)
+	}
+
+	remote, err := nextdnsClient.ListProfiles(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to list profiles")
+		reason := "ListFailed"
+		if nextdns.IsAuthError(err) {
+			reason = ReasonInvalidCredentials
+			account.Status.CredentialsSecretVersion = ""
+		}
+		r.setCondition(&account, metav1.ConditionFalse, reason, err.Error())
+		if updateErr := r.updateStatus(ctx, &account); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+	}
+	account.Status.CredentialsSecretVersion = secretVersion
+
+	managed, existing, err := r.managedProfiles(ctx, account.Status.AccountFingerprint)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	profiles := accountProfiles(remote, managed, existing, orphanedProfiles(&account))
+	r.confirmOrphans(ctx, profiles)
+	for _, p := range profiles {
+		if p.Orphaned && !wasOrphaned(account.Status.Profiles, p.ID) {
+			recordEvent(r.Recorder, &account, corev1.EventTypeWarning, EventReasonOrphanDetected,
+				"Profile %s was managed by NextDNSProfile %s, which was removed without deleting it", p.ID, p.ManagedBy)
+		}
+	}
+
+	var gcErr error
+	if account.Spec.GCPolicy == nextdnsv1alpha1.OrphanGCPolicyDelete {
+		profiles, gcErr = r.deleteOrphans(ctx, &account, nextdnsClient, profiles)
+	}
+
+	orphaned := 0
+	for _, p := range profiles {
+		if p.Orphaned {
+			orphaned++
+		}
+	}
+	account.Status.Profiles = profiles
+	account.Status.ProfileCount = int32(len(profiles))
+	account.Status.OrphanedProfiles = int32(orphaned)
+	account.Status.RemainingProfiles = remainingProfiles(account.Spec.ProfileLimit, len(profiles))
+	r.metrics().RecordAccountOrphans(account.Name, account.Namespace, orphaned)
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	account.Status.LastSyncTime = &metav1.Time{Time: now}
+
+	if gcErr != nil {
+		logger.Error(gcErr, "Failed to delete orphaned profiles")
+		r.setCondition(&account, metav1.ConditionFalse, "OrphanDeletionFailed", gcErr.Error())
+		if err := r.updateStatus(ctx, &account); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+	}
+
+	message := fmt.Sprintf("Found %d profile(s), %d managed by the operator, %d orphaned",
+		len(profiles), len(profiles)-orphaned-unmanagedCount(profiles), orphaned)
+	if remaining := account.Status.RemainingProfiles; remaining != nil {
+		message += fmt.Sprintf("; %d of %d profile(s) remaining", *remaining, *account.Spec.ProfileLimit)
+	}
+	r.setCondition(&account, metav1.ConditionTrue, "Synced", message)
+	if err := r.updateStatus(ctx, &account); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := pruneOrphanedProfiles(ctx, r.Client, &account, profiles, managed); err != nil {
+		logger.Error(err, "Failed to prune orphaned profiles")
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &account); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	if syncPeriod == 0 {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: CalculateSyncInterval(syncPeriod)}, nil
+}
+
+// remainingProfiles returns how many profiles can still be created under
+// limit, or nil when no limit is set
+func remainingProfiles(limit *int32, count int) *int32 {
+	if limit == nil {
+		return nil
+	}
+	remaining := max(*limit-int32(count), 0)
+	return &remaining
+}
+
+// managedProfiles maps the profile IDs of the NextDNSProfiles synced with
+// the account identified by fingerprint to the account profile entries
+// naming them. It also returns the namespace/name of every NextDNSProfile,
+// whatever its account, including those being deleted.
+func (r *NextDNSAccountReconciler) managedProfiles(ctx context.Context, fingerprint string) (map[string]nextdnsv1alpha1.AccountProfile, map[string]bool, error) {
+	var list nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, nil, fmt.Errorf("failed to list NextDNSProfiles: %w", err)
+	}
+	managed := make(map[string]nextdnsv1alpha1.AccountProfile)
+	existing := make(map[string]bool, len(list.Items))
+	for _, profile := range list.Items {
+		key := client.ObjectKeyFromObject(&profile).String()
+		existing[key] = true
+		if profile.Status.ProfileID != "" && profile.Status.AccountFingerprint == fingerprint {
+			managed[profile.Status.ProfileID] = nextdnsv1alpha1.AccountProfile{
+				ManagedBy:      key,
+				DeletionPolicy: deletionPolicy(&profile),
+			}
+		}
+	}
+	return managed, existing, nil
+}
+
+// accountProfiles lists the remote profiles of an account with the
+// NextDNSProfiles managing them. A profile is orphaned only when the profile
+// finalizer recorded it in orphaned, the profiles it removed with the
+// Delete policy but without deleting them, and its NextDNSProfile no longer
+// exists. Profiles the operator did not release that way, including those
+// whose finalizer was stripped, are never orphaned.
+func accountProfiles(remote []*sdknextdns.ProfileSummary, managed map[string]nextdnsv1alpha1.AccountProfile, existing map[string]bool, orphaned map[string]string) []nextdnsv1alpha1.AccountProfile {
+	profiles := make([]nextdnsv1alpha1.AccountProfile, 0, len(remote))
+	for _, p := range remote {
+		profile := nextdnsv1alpha1.AccountProfile{ID: p.ID, Name: p.Name}
+		if m, ok := managed[p.ID]; ok {
+			profile.ManagedBy = m.ManagedBy
+			profile.DeletionPolicy = m.DeletionPolicy
+		} else if owner, ok := orphaned[p.ID]; ok && !existing[owner] {
+			profile.ManagedBy = owner
+			profile.DeletionPolicy = nextdnsv1alpha1.DeletionPolicyDelete
+			profile.Orphaned = true
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// confirmOrphans reads the NextDNSProfile of each orphan candidate without
+// the cache, so a resource outside the namespaces or labels the cache holds
+// is not taken for deleted. A candidate whose resource exists, or cannot be
+// read, is not orphaned.
+func (r *NextDNSAccountReconciler) confirmOrphans(ctx context.Context, profiles []nextdnsv1alpha1.AccountProfile) {
+	logger := log.FromContext(ctx)
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	for i := range profiles {
+		p := &profiles[i]
+		if !p.Orphaned {
+			continue
+		}
+		namespace, name, _ := strings.Cut(p.ManagedBy, "/")
+		err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &nextdnsv1alpha1.NextDNSProfile{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			logger.Error(err, "Failed to confirm orphaned profile", "profileID", p.ID, "managedBy", p.ManagedBy)
+		}
+		p.Orphaned = false
+	}
+}
+
+// wasOrphaned reports whether the profile id is orphaned in profiles
+func wasOrphaned(profiles []nextdnsv1alpha1.AccountProfile, id string) bool {
+	for _, p := range profiles {
+		if p.ID == id {
+			return p.Orphaned
+		}
+	}
+	return false
+}
+
+// unmanagedCount returns the number of profiles no NextDNSProfile manages
+func unmanagedCount(profiles []nextdnsv1alpha1.AccountProfile) int {
+	n := 0
+	for _, p := range profiles {
+		if p.ManagedBy == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// deleteOrphans deletes the orphaned profiles from the account and returns
+// the profiles left. A profile already gone counts as deleted; those that
+// fail to delete stay listed and their errors are returned.
+func (r *NextDNSAccountReconciler) deleteOrphans(ctx context.Context, account *nextdnsv1alpha1.NextDNSAccount, nextdnsClient nextdns.ClientInterface, profiles []nextdnsv1alpha1.AccountProfile) ([]nextdnsv1alpha1.AccountProfile, error) {
+	logger := log.FromContext(ctx)
+
+	kept := profiles[:0]
+	var errs []error
+	for _, p := range profiles {
+		if !p.Orphaned {
+			kept = append(kept, p)
+			continue
+		}
+		if err := nextdnsClient.DeleteProfile(ctx, p.ID); err != nil && !nextdns.IsNotFoundError(err) {
+			errs = append(errs, fmt.Errorf("failed to delete orphaned profile %s: %w", p.ID, err))
+			kept = append(kept, p)
+			continue
+		}
+		logger.Info("Deleted orphaned profile", "profileID", p.ID, "managedBy", p.ManagedBy)
+		recordEvent(r.Recorder, account, corev1.EventTypeNormal, EventReasonOrphanDeleted,
+			"Deleted profile %s orphaned by NextDNSProfile %s", p.ID, p.ManagedBy)
+		r.metrics().RecordAccountOrphanDeleted(account.Name, account.Namespace)
+	}
+	return kept, errors.Join(errs...)
+}
+
+// metrics returns the metrics the reconciler records to
+func (r *NextDNSAccountReconciler) metrics() *metrics.Metrics {
+	if r.Metrics == nil {
+		return metrics.Default()
+	}
+	return r.Metrics
+}
+
+// setRateLimit applies the rate limit of the account key to the clients of
+// apiKey, removing the override the account set for another key before. An
+// empty apiKey only removes the account's override.
+func (r *NextDNSAccountReconciler) setRateLimit(key types.NamespacedName, apiKey string, limit *nextdnsv1alpha1.AccountRateLimit) error {
+	r.limitedKeysMu.Lock()
+	defer r.limitedKeysMu.Unlock()
+	if r.limitedKeys == nil {
+		r.limitedKeys = make(map[types.NamespacedName]string)
+	}
+
+	if previous, ok := r.limitedKeys[key]; ok && (previous != apiKey || limit == nil) {
+		if err := nextdns.SetAccountLimit(previous, nil); err != nil {
+			return err
+		}
+		delete(r.limitedKeys, key)
+	}
+	if apiKey == "" || limit == nil {
+		return nil
+	}
+
+	if err := nextdns.SetAccountLimit(apiKey, &nextdns.AccountLimit{
+		RequestsPerSecond: float64(limit.RequestsPerSecond),
+		Burst:             int(limit.Burst),
+	}); err != nil {
+		return err
+	}
+	r.limitedKeys[key] = apiKey
+	return nil
+}
+
+// updateStatus writes the status of account
+func (r *NextDNSAccountReconciler) updateStatus(ctx context.Context, account *nextdnsv1alpha1.NextDNSAccount) error {
+	if err := r.Status().Update(ctx, account); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return err
+	}
+	return nil
+}
+
+// setCondition sets the Ready condition of an account, emitting an event
+// when it changes
+func (r *NextDNSAccountReconciler) setCondition(account *nextdnsv1alpha1.NextDNSAccount, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               ConditionTypeReady,
+		Status:             status,
+		ObservedGeneration: account.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	recordConditionEvent(r.Recorder, account, account.Status.Conditions, condition)
+	meta.SetStatusCondition(&account.Status.Conditions, condition)
+}
+
+// findAccountsForSecret returns reconcile requests for the accounts whose
+// API key is in the given Secret
+func (r *NextDNSAccountReconciler) findAccountsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var accounts nextdnsv1alpha1.NextDNSAccountList
+	indexKey := obj.GetNamespace() + "/" + obj.GetName()
+	if err := r.List(ctx, &accounts, client.MatchingFields{accountCredentialsIndexField: indexKey}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list accounts for secret watch")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(accounts.Items))
+	for _, account := range accounts.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&account)})
+	}
+	return requests
+}
+
+// findAccountsForProfile returns reconcile requests for the account a
+// NextDNSProfile references and the accounts synced with its API key, so
+// their status follows the profile's deletion policy
+func (r *NextDNSAccountReconciler) findAccountsForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, key := range accountRefIndexFunc(profile) {
+		namespace, name, _ := strings.Cut(key, "/")
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}})
+	}
+	if profile.Status.AccountFingerprint == "" {
+		return requests
+	}
+
+	var accounts nextdnsv1alpha1.NextDNSAccountList
+	if err := r.List(ctx, &accounts); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list accounts for profile watch")
+		return requests
+	}
+	for _, account := range accounts.Items {
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&account)}
+		if account.Status.AccountFingerprint == profile.Status.AccountFingerprint && !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *NextDNSAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSAccount{}, accountCredentialsIndexField, accountCredentialsIndexFunc); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSAccount{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSAccountList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findAccountsForSecret),
+			// Skip informer resyncs; an edited Secret is validated again at once
+			ctrlbuilder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.findAccountsForProfile),
+			// Spec edits, such as the deletion policy, and removals; status
+			// syncs are picked up by the next scan
+			ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		WithOptions(controllerOptions("nextdnsaccount", r.Workqueue)).
+		Complete(r)
+}
@@ -0,0 +1,475 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestNextDNSAccountReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default", Generation: 2},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+			ProfileLimit:   ptr.To[int32](3),
+			RateLimit:      &nextdnsv1alpha1.AccountRateLimit{RequestsPerSecond: 2},
+			SyncInterval:   "1h",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("account-api-key")},
+	}
+	managed := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "family"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:          "abc123",
+			AccountFingerprint: nextdns.AccountFingerprint("account-api-key"),
+		},
+	}
+	otherAccount := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "office", Namespace: "family"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:          "def456",
+			AccountFingerprint: nextdns.AccountFingerprint("other-api-key"),
+		},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.Profiles["abc123"] = &sdknextdns.Profile{Name: "Home"}
+	mockNDS.Profiles["def456"] = &sdknextdns.Profile{Name: "Office"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(account, secret, managed, otherAccount).
+		WithStatusSubresource(account).
+		Build()
+	r := &NextDNSAccountReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+		now: func() time.Time { return now },
+	}
+	defer func() { require.NoError(t, nextdns.SetAccountLimit("account-api-key", nil)) }()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, result.RequeueAfter, float64(6*time.Minute))
+
+	var updated nextdnsv1alpha1.NextDNSAccount
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, nextdns.AccountFingerprint("account-api-key"), updated.Status.AccountFingerprint)
+	assert.NotEmpty(t, updated.Status.CredentialsSecretVersion)
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	assert.Equal(t, int32(2), updated.Status.ProfileCount)
+	require.NotNil(t, updated.Status.RemainingProfiles)
+	assert.Equal(t, int32(1), *updated.Status.RemainingProfiles)
+	assert.Equal(t, []nextdnsv1alpha1.AccountProfile{
+		{ID: "abc123", Name: "Home", ManagedBy: "family/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+		{ID: "def456", Name: "Office"},
+	}, updated.Status.Profiles, "profiles synced with another key are not matched")
+	require.NotNil(t, updated.Status.LastSyncTime)
+	assert.True(t, updated.Status.LastSyncTime.Time.Equal(now))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "Found 2 profile(s), 1 managed by the operator, 0 orphaned; 1 of 3 profile(s) remaining", cond.Message)
+	assert.Equal(t, map[types.NamespacedName]string{req.NamespacedName: "account-api-key"}, r.limitedKeys)
+
+	// A rejected key clears the validated Secret version
+	mockNDS.ListProfilesError = &sdknextdns.Error{Type: sdknextdns.ErrorTypeAuthentication}
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, result.RequeueAfter)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Empty(t, updated.Status.CredentialsSecretVersion)
+	cond = meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, ReasonInvalidCredentials, cond.Reason)
+
+	// Deleting the account removes its rate limit
+	require.NoError(t, fakeClient.Delete(ctx, &updated))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, r.limitedKeys)
+}
+
+func TestNextDNSAccountReconciler_MissingSecret(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "missing"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(account).
+		WithStatusSubresource(account).
+		Build()
+	r := &NextDNSAccountReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSAccount
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "CredentialsNotFound", cond.Reason)
+}
+
+func TestNextDNSAccountReconciler_OrphanedProfiles(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	fingerprint := nextdns.AccountFingerprint("account-api-key")
+
+	// family/home was removed without deleting abc123; family/adopted and
+	// family/office left theirs behind without the finalizer recording them
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "main",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationOrphanedProfiles: `{"abc123":"family/home","ghi789":"family/office"}`,
+			},
+		},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+		},
+		Status: nextdnsv1alpha1.NextDNSAccountStatus{
+			Profiles: []nextdnsv1alpha1.AccountProfile{
+				{ID: "abc123", ManagedBy: "family/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+				{ID: "def456", ManagedBy: "family/adopted", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+				{ID: "ghi789", ManagedBy: "family/office", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("account-api-key")},
+	}
+	// family/office still exists but now syncs another profile
+	office := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "office", Namespace: "family"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "new001", AccountFingerprint: fingerprint},
+	}
+
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	mockNDS := nextdns.NewMockClient()
+	for _, id := range []string{"abc123", "def456", "ghi789", "new001", "manual"} {
+		mockNDS.Profiles[id] = &sdknextdns.Profile{Name: id}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(account, secret, office).
+		WithStatusSubresource(account).
+		Build()
+	r := &NextDNSAccountReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+		Metrics: m,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}}
+
+	// Report lists the orphan without deleting it
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var updated nextdnsv1alpha1.NextDNSAccount
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, []nextdnsv1alpha1.AccountProfile{
+		{ID: "abc123", Name: "abc123", ManagedBy: "family/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete, Orphaned: true},
+		{ID: "def456", Name: "def456"},
+		{ID: "ghi789", Name: "ghi789"},
+		{ID: "manual", Name: "manual"},
+		{ID: "new001", Name: "new001", ManagedBy: "family/office", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+	}, updated.Status.Profiles)
+	assert.Equal(t, int32(1), updated.Status.OrphanedProfiles)
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.Metrics.AccountOrphanedProfiles.WithLabelValues("main", "default")))
+	assert.Contains(t, mockNDS.Profiles, "abc123")
+
+	// The orphan stays orphaned on the next scan
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, int32(1), updated.Status.OrphanedProfiles)
+
+	// Delete removes only the orphan
+	updated.Spec.GCPolicy = nextdnsv1alpha1.OrphanGCPolicyDelete
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	// A failed deletion keeps the orphan for the next scan
+	mockNDS.DeleteProfileError = assert.AnError
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, result.RequeueAfter)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, int32(1), updated.Status.OrphanedProfiles)
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, "OrphanDeletionFailed", cond.Reason)
+
+	mockNDS.DeleteProfileError = nil
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.NotContains(t, mockNDS.Profiles, "abc123")
+	assert.Len(t, mockNDS.Profiles, 4)
+	assert.Zero(t, updated.Status.OrphanedProfiles)
+	assert.Len(t, updated.Status.Profiles, 4)
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.Metrics.AccountOrphansDeletedTotal.WithLabelValues("main", "default")))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeReady))
+	assert.JSONEq(t, `{"ghi789":"family/office"}`, updated.Annotations[AnnotationOrphanedProfiles],
+		"the deleted orphan is no longer recorded")
+}
+
+// TestNextDNSAccountReconciler_OrphansOutsideCache checks that a profile
+// whose resource the cache does not hold, as with --watch-namespaces, is
+// not taken for an orphan and deleted
+func TestNextDNSAccountReconciler_OrphansOutsideCache(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	fingerprint := nextdns.AccountFingerprint("account-api-key")
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "main",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationOrphanedProfiles: `{"abc123":"family/home","def456":"family/gone"}`,
+			},
+		},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+			GCPolicy:       nextdnsv1alpha1.OrphanGCPolicyDelete,
+		},
+		Status: nextdnsv1alpha1.NextDNSAccountStatus{
+			Profiles: []nextdnsv1alpha1.AccountProfile{
+				{ID: "abc123", ManagedBy: "family/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+				{ID: "def456", ManagedBy: "family/gone", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("account-api-key")},
+	}
+	// family/home exists but the cache only watches the default namespace
+	home := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "family"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123", AccountFingerprint: fingerprint},
+	}
+
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	mockNDS := nextdns.NewMockClient()
+	for _, id := range []string{"abc123", "def456"} {
+		mockNDS.Profiles[id] = &sdknextdns.Profile{Name: id}
+	}
+
+	cached := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(account, secret).
+		WithStatusSubresource(account).
+		Build()
+	r := &NextDNSAccountReconciler{
+		Client: cached,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+		Metrics:   m,
+		APIReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(home).Build(),
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}}
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var updated nextdnsv1alpha1.NextDNSAccount
+	require.NoError(t, cached.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, []nextdnsv1alpha1.AccountProfile{
+		{ID: "abc123", Name: "abc123", ManagedBy: "family/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+	}, updated.Status.Profiles)
+	assert.Contains(t, mockNDS.Profiles, "abc123")
+	assert.NotContains(t, mockNDS.Profiles, "def456", "a confirmed orphan is still deleted")
+	assert.Zero(t, updated.Status.OrphanedProfiles)
+}
+
+// TestNextDNSAccountReconciler_OrphansRecordedByFinalizer checks that only
+// a profile the finalizer left behind with the Delete policy is collected,
+// not one whose policy was changed to Orphan just before its removal
+func TestNextDNSAccountReconciler_OrphansRecordedByFinalizer(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	fingerprint := nextdns.AccountFingerprint("account-api-key")
+
+	account := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSAccountSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+			GCPolicy:       nextdnsv1alpha1.OrphanGCPolicyDelete,
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("account-api-key")},
+	}
+	newProfile := func(name, id string) *nextdnsv1alpha1.NextDNSProfile {
+		return &nextdnsv1alpha1.NextDNSProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Finalizers: []string{FinalizerName}},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				Name:           name,
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"},
+			},
+			Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: id, AccountFingerprint: fingerprint},
+		}
+	}
+
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	mockNDS := nextdns.NewMockClient()
+	for _, id := range []string{"abc123", "def456"} {
+		mockNDS.Profiles[id] = &sdknextdns.Profile{Name: id}
+	}
+	factory := func(apiKey string) (nextdns.ClientInterface, error) {
+		return mockNDS, nil
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(account, secret, newProfile("home", "abc123"), newProfile("office", "def456")).
+		WithStatusSubresource(account, &nextdnsv1alpha1.NextDNSProfile{}).
+		Build()
+	accounts := &NextDNSAccountReconciler{Client: fakeClient, Scheme: scheme, ClientFactory: factory, Metrics: m}
+	profiles := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, ClientFactory: factory}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}}
+
+	_, err = accounts.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var updated nextdnsv1alpha1.NextDNSAccount
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, []nextdnsv1alpha1.AccountProfile{
+		{ID: "abc123", Name: "abc123", ManagedBy: "default/home", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+		{ID: "def456", Name: "def456", ManagedBy: "default/office", DeletionPolicy: nextdnsv1alpha1.DeletionPolicyDelete},
+	}, updated.Status.Profiles)
+
+	// remove deletes the profile resource and runs its finalizer
+	remove := func(name string, edit func(*nextdnsv1alpha1.NextDNSProfile)) {
+		t.Helper()
+		key := types.NamespacedName{Name: name, Namespace: "default"}
+		var profile nextdnsv1alpha1.NextDNSProfile
+		require.NoError(t, fakeClient.Get(ctx, key, &profile))
+		if edit != nil {
+			edit(&profile)
+			require.NoError(t, fakeClient.Update(ctx, &profile))
+		}
+		require.NoError(t, fakeClient.Delete(ctx, &profile))
+		require.NoError(t, fakeClient.Get(ctx, key, &profile))
+		_, err := profiles.handleDeletion(ctx, &profile)
+		require.NoError(t, err)
+	}
+
+	// Switching to Orphan right before the removal, ahead of any account
+	// sync, keeps the profile
+	remove("home", func(p *nextdnsv1alpha1.NextDNSProfile) {
+		p.Spec.DeletionPolicy = nextdnsv1alpha1.DeletionPolicyOrphan
+	})
+	// A failed deletion under the Delete policy records the orphan
+	mockNDS.DeleteProfileError = assert.AnError
+	remove("office", nil)
+	mockNDS.DeleteProfileError = nil
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.JSONEq(t, `{"def456":"default/office"}`, updated.Annotations[AnnotationOrphanedProfiles])
+
+	_, err = accounts.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Contains(t, mockNDS.Profiles, "abc123")
+	assert.NotContains(t, mockNDS.Profiles, "def456")
+	assert.Equal(t, []nextdnsv1alpha1.AccountProfile{
+		{ID: "abc123", Name: "abc123"},
+	}, updated.Status.Profiles)
+	assert.NotContains(t, updated.Annotations, AnnotationOrphanedProfiles)
+}
+
+func TestNextDNSAccountReconciler_FindAccountsForProfile(t *testing.T) {
+	scheme := newTestScheme()
+	fingerprint := nextdns.AccountFingerprint("account-api-key")
+
+	main := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSAccountStatus{AccountFingerprint: fingerprint},
+	}
+	other := &nextdnsv1alpha1.NextDNSAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSAccountStatus{AccountFingerprint: nextdns.AccountFingerprint("other-api-key")},
+	}
+	r := &NextDNSAccountReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(main, other).Build(),
+		Scheme: scheme,
+	}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "family"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			AccountRef: &nextdnsv1alpha1.ResourceReference{Name: "main", Namespace: "default"},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{AccountFingerprint: fingerprint},
+	}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}},
+	}, r.findAccountsForProfile(context.Background(), profile))
+
+	// A profile with its own credentials maps to the accounts of its key
+	profile.Spec.AccountRef = nil
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "main", Namespace: "default"}},
+	}, r.findAccountsForProfile(context.Background(), profile))
+
+	profile.Status.AccountFingerprint = ""
+	assert.Empty(t, r.findAccountsForProfile(context.Background(), profile))
+}
+
+func TestRemainingProfiles(t *testing.T) {
+	assert.Nil(t, remainingProfiles(nil, 4))
+	assert.Equal(t, int32(1), *remainingProfiles(ptr.To[int32](5), 4))
+	assert.Equal(t, int32(0), *remainingProfiles(ptr.To[int32](3), 4), "an exceeded limit leaves none")
+}
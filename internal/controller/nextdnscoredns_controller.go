@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -12,25 +13,33 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
 	"github.com/jacaudi/nextdns-operator/internal/coredns"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
 )
 
 const (
@@ -55,6 +64,14 @@ const (
 	// ConditionTypeUDPRouteReady indicates the UDPRoute is accepted
 	ConditionTypeUDPRouteReady = "UDPRouteReady"
 
+	// ConditionTypeWorkloadSuspended indicates spec.suspendWorkload is
+	// holding the Deployment or DaemonSet unchanged
+	ConditionTypeWorkloadSuspended = "WorkloadSuspended"
+
+	// ConditionTypeFallbackResolved indicates the profile set in
+	// spec.fallbackProfileRef is ready to take over from the primary
+	ConditionTypeFallbackResolved = "FallbackResolved"
+
 	// CorefileKey is the key in the ConfigMap for the Corefile
 	CorefileKey = "Corefile"
 
@@ -62,7 +79,7 @@ const (
 	maxResourceNameLength = 63
 
 	// defaultReplicas is the default number of CoreDNS replicas
-	defaultReplicas int32 = 2
+	defaultReplicas = coredns.DefaultReplicas
 )
 
 // NextDNSCoreDNSReconciler reconciles a NextDNSCoreDNS object
@@ -72,6 +89,35 @@ type NextDNSCoreDNSReconciler struct {
 	SyncPeriod          time.Duration
 	GatewayAPIAvailable bool
 	GatewayClassName    string
+
+	// ServiceMonitorAvailable is set when the Prometheus Operator
+	// ServiceMonitor CRD is installed
+	ServiceMonitorAvailable bool
+
+	// ResourceLabels are added to every object created for a NextDNSCoreDNS
+	ResourceLabels ResourceLabels
+
+	// DNSLookup performs test queries; DefaultDNSLookup is used when nil
+	DNSLookup DNSLookupFunc
+
+	// UpstreamProbe health checks the primary upstreams when a fallback
+	// profile is set; DefaultUpstreamProbe is used when nil
+	UpstreamProbe UpstreamProbeFunc
+
+	// Metrics records instance health and soak test lookups;
+	// metrics.Default() is used when nil
+	Metrics *metrics.Metrics
+
+	// AllowClusterDNSIntegration permits spec.clusterDNSIntegration to patch
+	// the cluster DNS Service
+	AllowClusterDNSIntegration bool
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
 }
 
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnscorednses,verbs=get;list;watch;create;update;patch;delete
@@ -82,14 +128,20 @@ type NextDNSCoreDNSReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways/status,verbs=get
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tcproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=udproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=envoyproxies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -106,11 +158,21 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, coreDNS, &coreDNS.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check if the resource is being deleted
 	if !coreDNS.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, coreDNS)
 	}
 
+	start := time.Now()
+	defer func() {
+		r.metrics().RecordCoreDNSReconcile(coreDNS.Name, coreDNS.Namespace, coreDNS.Status.Ready, time.Since(start).Seconds())
+	}()
+
 	// Add finalizer if not present
 	if !controllerutil.ContainsFinalizer(coreDNS, CoreDNSFinalizerName) {
 		logger.Info("Adding finalizer to NextDNSCoreDNS")
@@ -122,7 +184,7 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// Resolve the referenced NextDNSProfile
-	profile, err := r.resolveProfile(ctx, coreDNS)
+	profile, err := profileresolver.Resolve(ctx, r.Client, coreDNS.Spec.ProfileRef, coreDNS.Namespace)
 	if err != nil {
 		logger.Error(err, "Failed to resolve NextDNSProfile reference")
 		r.setCondition(coreDNS, ConditionTypeProfileResolved, metav1.ConditionFalse, "ProfileNotFound", err.Error())
@@ -135,7 +197,7 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// Check if profile is ready
-	if !r.isProfileReady(profile) {
+	if !profileresolver.IsReady(profile) {
 		logger.Info("Referenced NextDNSProfile is not ready", "profile", profile.Name)
 		r.setCondition(coreDNS, ConditionTypeProfileResolved, metav1.ConditionFalse, "ProfileNotReady", "Referenced profile is not in Ready state")
 		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ProfileNotReady", "Waiting for profile to become ready")
@@ -161,6 +223,10 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Resolve the optional fallback profile; the primary serves alone until
+	// it is ready
+	fallback := r.resolveFallbackProfile(ctx, coreDNS)
+
 	// Validate Multus configuration
 	if coreDNS.Spec.Multus != nil && len(coreDNS.Spec.Multus.IPs) > 0 {
 		var warnings []string
@@ -179,11 +245,14 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			warnings = append(warnings, fmt.Sprintf("Invalid IPv4 addresses: %v", invalidIPs))
 		}
 
-		// Warn if IPs < replicas
+		// Warn if IPs < replicas (the autoscaler may scale up to maxReplicas)
 		replicas := defaultReplicas
 		if coreDNS.Spec.Deployment != nil && coreDNS.Spec.Deployment.Replicas != nil {
 			replicas = *coreDNS.Spec.Deployment.Replicas
 		}
+		if autoscaling := autoscalingConfig(coreDNS); autoscaling != nil {
+			replicas = autoscaling.MaxReplicas
+		}
 		if int32(len(coreDNS.Spec.Multus.IPs)) < replicas {
 			logger.Info("WARNING: fewer Multus IPs than replicas; some pods may fail IPAM allocation",
 				"multusIPs", len(coreDNS.Spec.Multus.IPs),
@@ -216,6 +285,67 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			"deviceName is not set or protocol supports device identification")
 	}
 
+	// Report whether the DoH endpoint hostname is pinned or left to pod DNS
+	r.setDoHBootstrapCondition(coreDNS)
+
+	// Reject upstream protocols that cannot be rendered into the Corefile
+	if err := coredns.ValidateProtocol(upstreamProtocol(coreDNS)); err != nil {
+		reason := "InvalidProtocol"
+		if errors.Is(err, coredns.ErrUnsupportedProtocol) {
+			reason = "UnsupportedProtocol"
+		}
+		logger.Info("Invalid configuration: upstream protocol cannot be used", "error", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Reject bootstrap servers that cannot be written to /etc/hosts
+	if err := coredns.ValidateBootstrapServers(dohBootstrapServers(coreDNS)); err != nil {
+		logger.Info("Invalid configuration: bootstrap servers cannot be used", "error", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "InvalidBootstrapServers", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Reject extra containers whose names collide before rolling them out
+	if err := validateExtraContainers(coreDNS); err != nil {
+		logger.Info("Invalid configuration: extra containers cannot be added", "error", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "InvalidContainers", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Validate the encrypted listeners and resolve their certificates before
+	// rolling out pods that mount them
+	if err := validateTLSListeners(coreDNS); err != nil {
+		logger.Info("Invalid configuration: listener cannot be served", "error", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "InvalidListener", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{}, nil
+	}
+	if _, err := r.resolveTLSListeners(ctx, coreDNS); err != nil {
+		logger.Info("Listener certificate is not available", "error", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ListenerCertificateUnavailable", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
 	// Validate Gateway configuration
 	if coreDNS.Spec.Gateway != nil {
 		// Check mutual exclusivity with LoadBalancer
@@ -297,7 +427,7 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	coreDNS.Status.Fingerprint = profile.Status.Fingerprint
 
 	// Reconcile the ConfigMap with Corefile
-	if err := r.reconcileConfigMap(ctx, coreDNS, profile); err != nil {
+	if err := r.reconcileConfigMap(ctx, coreDNS, profile, fallback); err != nil {
 		logger.Error(err, "Failed to reconcile ConfigMap")
 		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ConfigMapFailed", err.Error())
 		coreDNS.Status.Ready = false
@@ -307,10 +437,10 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
-	// Reconcile the workload (Deployment or DaemonSet)
-	if err := r.reconcileWorkload(ctx, coreDNS, profile); err != nil {
-		logger.Error(err, "Failed to reconcile workload")
-		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "WorkloadFailed", err.Error())
+	// Reconcile the ServiceAccount before the pods that run as it
+	if err := r.reconcileServiceAccount(ctx, coreDNS, profile); err != nil {
+		logger.Error(err, "Failed to reconcile ServiceAccount")
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ServiceAccountFailed", err.Error())
 		coreDNS.Status.Ready = false
 		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
 			logger.Error(updateErr, "Failed to update status")
@@ -318,6 +448,25 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Reconcile the workload (Deployment or DaemonSet) unless it is held
+	// unchanged by spec.suspendWorkload
+	if coreDNS.Spec.SuspendWorkload {
+		logger.V(1).Info("Workload is suspended, leaving it unchanged")
+		r.setCondition(coreDNS, ConditionTypeWorkloadSuspended, metav1.ConditionTrue, "SuspendedBySpec",
+			"spec.suspendWorkload is set; configuration changes are staged but not rolled out")
+	} else {
+		meta.RemoveStatusCondition(&coreDNS.Status.Conditions, ConditionTypeWorkloadSuspended)
+		if err := r.reconcileWorkload(ctx, coreDNS, profile); err != nil {
+			logger.Error(err, "Failed to reconcile workload")
+			r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "WorkloadFailed", err.Error())
+			coreDNS.Status.Ready = false
+			if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+				logger.Error(updateErr, "Failed to update status")
+			}
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
+
 	// Reconcile the PodDisruptionBudget (only for Deployment mode)
 	if err := r.reconcilePDB(ctx, coreDNS, profile); err != nil {
 		logger.Error(err, "Failed to reconcile PodDisruptionBudget")
@@ -329,6 +478,17 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Reconcile the HorizontalPodAutoscaler (only for Deployment mode)
+	if err := r.reconcileHPA(ctx, coreDNS, profile); err != nil {
+		logger.Error(err, "Failed to reconcile HorizontalPodAutoscaler")
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "HPAFailed", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
 	// Reconcile the Service
 	if err := r.reconcileService(ctx, coreDNS, profile); err != nil {
 		logger.Error(err, "Failed to reconcile Service")
@@ -340,6 +500,40 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Take over cluster DNS if requested
+	if err := r.reconcileClusterDNS(ctx, coreDNS, profile); err != nil {
+		logger.Error(err, "Failed to reconcile cluster DNS integration")
+		r.setCondition(coreDNS, ConditionTypeClusterDNSIntegrated, metav1.ConditionFalse, "IntegrationFailed", err.Error())
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ClusterDNSIntegrationFailed", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// Reconcile the NetworkPolicy
+	if err := r.reconcileNetworkPolicy(ctx, coreDNS, profile); err != nil {
+		logger.Error(err, "Failed to reconcile NetworkPolicy")
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "NetworkPolicyFailed", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// Reconcile the ServiceMonitor if the Prometheus Operator is installed
+	if err := r.reconcileServiceMonitor(ctx, coreDNS, profile); err != nil {
+		logger.Error(err, "Failed to reconcile ServiceMonitor")
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ServiceMonitorFailed", err.Error())
+		coreDNS.Status.Ready = false
+		if updateErr := r.Status().Update(ctx, coreDNS); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
 	// Reconcile Gateway API resources if configured
 	if coreDNS.Spec.Gateway != nil && r.GatewayAPIAvailable {
 		serviceName := r.getServiceName(coreDNS, profile)
@@ -395,19 +589,57 @@ func (r *NextDNSCoreDNSReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		r.setCondition(coreDNS, ConditionTypeUDPRouteReady, metav1.ConditionTrue, "UDPRouteReconciled", "UDPRoute reconciled successfully")
 	}
 
+	// Run a requested test query through the Service
+	r.runTestQuery(ctx, coreDNS, profile)
+
+	// Compare with the legacy resolver when a soak test round is due
+	r.runSoakTest(ctx, coreDNS, profile, time.Now())
+
+	// Record whether the primary or the fallback profile serves queries
+	r.updateActiveUpstream(ctx, coreDNS, profile, fallback)
+
 	// Update status with current state
 	if err := r.updateStatus(ctx, coreDNS, profile); err != nil {
 		logger.Error(err, "Failed to update status")
 		return ctrl.Result{}, err
 	}
 
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, coreDNS); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	// Clear a processed test query request
+	if err := clearAnnotation(ctx, r.Client, coreDNS, AnnotationTestQuery); err != nil {
+		logger.Error(err, "Failed to clear test query request")
+	}
+
 	logger.Info("Successfully reconciled NextDNSCoreDNS",
 		"profileID", coreDNS.Status.ProfileID,
 		"dnsIP", coreDNS.Status.DNSIP,
 		"ready", coreDNS.Status.Ready)
 
 	// Schedule next sync with jitter
-	syncInterval := CalculateSyncInterval(r.SyncPeriod)
+	syncPeriod, err := resourceSyncPeriod(coreDNS, coreDNS.Spec.SyncInterval, r.SyncPeriod)
+	if err != nil {
+		logger.Error(err, "Invalid sync interval override")
+	}
+	syncInterval := CalculateSyncInterval(syncPeriod)
+	if wait := placementRefreshAfter(coreDNS, time.Now()); wait > 0 && (syncInterval == 0 || wait < syncInterval) {
+		// Placement was not refreshed for the latest pod changes
+		syncInterval = wait
+	}
+	if wait := soakTestRefreshAfter(coreDNS, time.Now()); wait > 0 && (syncInterval == 0 || wait < syncInterval) {
+		syncInterval = wait
+	}
+	if wait := clusterDNSRefreshAfter(coreDNS); wait > 0 && (syncInterval == 0 || wait < syncInterval) {
+		// The cluster DNS takeover waits for a ready CoreDNS pod
+		syncInterval = wait
+	}
+	if fallback != nil && (syncInterval == 0 || fallbackProbeInterval < syncInterval) {
+		// Keep the active upstream path current
+		syncInterval = fallbackProbeInterval
+	}
 	if syncInterval > 0 {
 		logger.V(1).Info("Scheduling next sync", "interval", syncInterval)
 	}
@@ -422,12 +654,24 @@ func (r *NextDNSCoreDNSReconciler) handleDeletion(ctx context.Context, coreDNS *
 	if controllerutil.ContainsFinalizer(coreDNS, CoreDNSFinalizerName) {
 		logger.Info("Handling deletion of NextDNSCoreDNS")
 
-		// Resources will be cleaned up automatically via OwnerReferences
-		// Just remove the finalizer
+		// Resources will be cleaned up automatically via OwnerReferences,
+		// except ServiceMonitors placed in another namespace
+		if r.ServiceMonitorAvailable {
+			if err := r.cleanupServiceMonitors(ctx, coreDNS, types.NamespacedName{}); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Hand a taken over cluster DNS Service back to its original pods
+		if err := r.releaseClusterDNSService(ctx, coreDNS, types.NamespacedName{}); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		controllerutil.RemoveFinalizer(coreDNS, CoreDNSFinalizerName)
 		if err := r.Update(ctx, coreDNS); err != nil {
 			return ctrl.Result{}, err
 		}
+		r.metrics().DeleteCoreDNS(coreDNS.Name, coreDNS.Namespace)
 	}
 
 	return ctrl.Result{}, nil
@@ -463,43 +707,22 @@ func (r *NextDNSCoreDNSReconciler) reconcileProxyReplicas(ctx context.Context, c
 		return nil, nil
 	}
 
-	return strategy.ReconcileProxyReplicas(ctx, r.Client, r.Scheme, coreDNS, *coreDNS.Spec.Gateway.Replicas)
-}
-
-// resolveProfile fetches the referenced NextDNSProfile
-func (r *NextDNSCoreDNSReconciler) resolveProfile(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) (*nextdnsv1alpha1.NextDNSProfile, error) {
-	profileRef := coreDNS.Spec.ProfileRef
-	ns := profileRef.Namespace
-	if ns == "" {
-		ns = coreDNS.Namespace
-	}
-
-	profile := &nextdnsv1alpha1.NextDNSProfile{}
-	if err := r.Get(ctx, types.NamespacedName{Name: profileRef.Name, Namespace: ns}, profile); err != nil {
-		return nil, fmt.Errorf("failed to get NextDNSProfile %s/%s: %w", ns, profileRef.Name, err)
-	}
-
-	return profile, nil
-}
-
-// isProfileReady checks if the profile has a Ready condition set to True
-func (r *NextDNSCoreDNSReconciler) isProfileReady(profile *nextdnsv1alpha1.NextDNSProfile) bool {
-	for _, cond := range profile.Status.Conditions {
-		if cond.Type == ConditionTypeReady && cond.Status == metav1.ConditionTrue {
-			return true
-		}
-	}
-	return false
+	return strategy.ReconcileProxyReplicas(ctx, r.Client, r.Scheme, coreDNS, *coreDNS.Spec.Gateway.Replicas, r.ResourceLabels)
 }
 
 // reconcileConfigMap creates or updates the ConfigMap containing the Corefile
-func (r *NextDNSCoreDNSReconciler) reconcileConfigMap(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+func (r *NextDNSCoreDNSReconciler) reconcileConfigMap(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile, fallback *nextdnsv1alpha1.NextDNSProfile) error {
 	logger := log.FromContext(ctx)
 	resourceName := r.getResourceName(coreDNS, profile)
 
 	// Build Corefile configuration
 	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	if err == nil {
+		cfg.Fallback = fallbackUpstreamConfig(coreDNS, fallback)
+		err = coredns.ValidateFallback(cfg.Fallback, cfg.ForwardTuning)
+	}
 	if err != nil {
+		r.metrics().RecordCoreDNSCorefileError(coreDNS.Name, coreDNS.Namespace)
 		return fmt.Errorf("invalid Corefile configuration: %w", err)
 	}
 	corefileContent := coredns.GenerateCorefile(cfg)
@@ -513,12 +736,13 @@ func (r *NextDNSCoreDNSReconciler) reconcileConfigMap(ctx context.Context, coreD
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
 		// Set labels
-		configMap.Labels = r.buildLabels(coreDNS, profile)
+		configMap.Labels = r.ResourceLabels.merge(r.buildLabels(coreDNS, profile))
 
 		// Set data
 		configMap.Data = map[string]string{
 			CorefileKey: corefileContent,
 		}
+		coreDNS.Status.LocalZone = applyLocalZone(configMap, cfg, time.Now())
 
 		// Set owner reference
 		return controllerutil.SetControllerReference(coreDNS, configMap, r.Scheme)
@@ -535,21 +759,55 @@ func (r *NextDNSCoreDNSReconciler) reconcileConfigMap(ctx context.Context, coreD
 	return nil
 }
 
+// buildCacheTuning maps the cache tuning options of the spec, or returns nil
+// when none are set
+func buildCacheTuning(cache *nextdnsv1alpha1.CoreDNSCacheConfig) *coredns.CacheTuningConfig {
+	if cache.DenialTTL == nil && cache.Prefetch == nil && cache.ServeStale == nil {
+		return nil
+	}
+	tuning := &coredns.CacheTuningConfig{DenialTTL: cache.DenialTTL}
+	if p := cache.Prefetch; p != nil {
+		tuning.Prefetch = &coredns.CachePrefetchConfig{
+			Amount:     p.Amount,
+			Duration:   p.Duration,
+			Percentage: p.Percentage,
+		}
+	}
+	if st := cache.ServeStale; st != nil {
+		tuning.ServeStale = &coredns.CacheServeStaleConfig{
+			Duration:    st.Duration,
+			RefreshMode: st.RefreshMode,
+		}
+	}
+	return tuning
+}
+
+// upstreamProtocol returns the primary upstream protocol, DoT unless set
+func upstreamProtocol(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	if cf := coreDNS.Spec.Corefile; cf != nil && cf.Upstream != nil && cf.Upstream.Primary != "" {
+		return string(cf.Upstream.Primary)
+	}
+	return coredns.ProtocolDoT
+}
+
 // buildCorefileConfig builds the CorefileConfig from the CR spec
 func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) (*coredns.CorefileConfig, error) {
 	cfg := &coredns.CorefileConfig{
 		ProfileID:       profile.Status.ProfileID,
 		PrimaryProtocol: coredns.ProtocolDoT, // default
-		CacheTTL:        3600,                // default
+		CacheTTL:        coredns.DefaultCacheTTL,
 		LoggingEnabled:  false,
 		MetricsEnabled:  true,
 	}
 
 	cf := coreDNS.Spec.Corefile
 
-	// Override primary protocol if specified
+	cfg.PrimaryProtocol = upstreamProtocol(coreDNS)
+	if err := coredns.ValidateProtocol(cfg.PrimaryProtocol); err != nil {
+		return nil, err
+	}
+
 	if cf != nil && cf.Upstream != nil {
-		cfg.PrimaryProtocol = string(cf.Upstream.Primary)
 		cfg.DeviceName = cf.Upstream.DeviceName
 
 		if cf.Upstream.Forward != nil {
@@ -570,8 +828,14 @@ func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.
 	if cf != nil && cf.Cache != nil {
 		if cf.Cache.Enabled != nil && !*cf.Cache.Enabled {
 			cfg.CacheTTL = 0
-		} else if cf.Cache.SuccessTTL != nil {
-			cfg.CacheTTL = *cf.Cache.SuccessTTL
+		} else {
+			if cf.Cache.SuccessTTL != nil {
+				cfg.CacheTTL = *cf.Cache.SuccessTTL
+			}
+			cfg.CacheTuning = buildCacheTuning(cf.Cache)
+			if err := coredns.ValidateCacheTuning(cfg.CacheTuning); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -579,6 +843,9 @@ func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.
 	if cf != nil && cf.Logging != nil && cf.Logging.Enabled != nil {
 		cfg.LoggingEnabled = *cf.Logging.Enabled
 	}
+	if cf != nil && cf.Logging != nil {
+		cfg.LogRedactClient = cf.Logging.RedactClientAddress
+	}
 
 	// Override metrics settings if specified
 	if cf != nil && cf.Metrics != nil && cf.Metrics.Enabled != nil {
@@ -586,14 +853,7 @@ func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.
 	}
 
 	// Use profile-specific upstream IPs if available
-	// Prefer Setup.IPv4, fall back to LinkedIP.Servers (linked-IP profiles
-	// expose per-profile IPs there instead of in the top-level IPv4 field)
-	if profile.Status.Setup != nil {
-		cfg.UpstreamIPv4 = profile.Status.Setup.IPv4
-		if len(cfg.UpstreamIPv4) == 0 && profile.Status.Setup.LinkedIP != nil {
-			cfg.UpstreamIPv4 = profile.Status.Setup.LinkedIP.Servers
-		}
-	}
+	cfg.UpstreamIPv4, cfg.UpstreamIPv6 = profileUpstreamIPs(coreDNS, profile)
 
 	// Add domain overrides if specified
 	if cf != nil && len(cf.DomainOverrides) > 0 {
@@ -656,6 +916,29 @@ func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.
 		cfg.Hosts = hosts
 	}
 
+	// Add local records if specified
+	if cf != nil && len(cf.LocalRecords) > 0 {
+		cfg.LocalRecords = make([]coredns.LocalRecordConfig, len(cf.LocalRecords))
+		for i, r := range cf.LocalRecords {
+			cfg.LocalRecords[i] = coredns.LocalRecordConfig{
+				Name:  r.Name,
+				Type:  r.Type,
+				Value: r.Value,
+				TTL:   coredns.DefaultLocalRecordTTL,
+			}
+			if r.Type == "" {
+				cfg.LocalRecords[i].Type = coredns.RecordTypeA
+			}
+			if r.TTL != nil {
+				cfg.LocalRecords[i].TTL = *r.TTL
+			}
+		}
+		if err := coredns.ValidateLocalRecords(cfg.LocalRecords); err != nil {
+			return nil, err
+		}
+		cfg.ReverseRecords = cf.ReverseRecords
+	}
+
 	// Copy health/ready/errors plugin config and metrics.port. The API
 	// types default Enabled=true via kubebuilder; we mirror that here so
 	// a user setting only Port does not silently disable the plugin.
@@ -694,14 +977,134 @@ func (r *NextDNSCoreDNSReconciler) buildCorefileConfig(coreDNS *nextdnsv1alpha1.
 		cfg.MetricsPort = *cf.Metrics.Port
 	}
 
+	// Copy metrics listen address and per-server enablement
+	if cf != nil && cf.Metrics != nil {
+		cfg.MetricsAddress = cf.Metrics.Address
+		if err := coredns.ValidateMetricsConfig(cf.Metrics.Address, cf.Metrics.ExcludeDomains, cfg.DomainOverrides); err != nil {
+			return nil, err
+		}
+		if cf.Metrics.DomainOverrides {
+			excluded := make(map[string]bool, len(cf.Metrics.ExcludeDomains))
+			for _, d := range cf.Metrics.ExcludeDomains {
+				excluded[d] = true
+			}
+			for i := range cfg.DomainOverrides {
+				cfg.DomainOverrides[i].Metrics = !excluded[cfg.DomainOverrides[i].Domain]
+			}
+		}
+	}
+
 	// Validate plugin config (port ranges, collisions, duration parsing).
 	if err := coredns.ValidatePluginConfig(cfg.Health, cfg.Ready, cfg.Errors, cfg.MetricsPort); err != nil {
 		return nil, err
 	}
 
+	// A node-local cache binds only its local IP and the node IP, leaving
+	// port 53 on other node addresses to resolvers already running there
+	if nodeLocalEnabled(coreDNS) {
+		addresses, err := nodeLocalBindAddresses(coreDNS)
+		if err != nil {
+			return nil, err
+		}
+		cfg.BindAddresses = addresses
+	}
+
+	// Serve the encrypted listeners with the certificates mounted from
+	// their TLS Secrets
+	for _, listener := range tlsListeners(coreDNS) {
+		switch listener.name {
+		case listenerDoH:
+			cfg.DoH = listener.corefileConfig()
+		case listenerDoT:
+			cfg.DoT = listener.corefileConfig()
+		}
+	}
+
+	// Serve the local zone for transfers to downstream resolvers
+	if zone := localZoneTransfer(coreDNS); zone != nil {
+		cfg.LocalZone = &coredns.LocalZoneConfig{
+			Zone:         zone.Zone,
+			AllowedCIDRs: zone.AllowedCIDRs,
+		}
+		if err := coredns.ValidateLocalZone(cfg.LocalZone, cfg.DomainOverrides); err != nil {
+			return nil, err
+		}
+	}
+
+	// Answer search domain expansions locally instead of forwarding them
+	if cf != nil && cf.SearchPath != nil && boolWithDefault(cf.SearchPath.Enabled, true) {
+		cfg.SearchDomains = cf.SearchPath.Domains
+		if len(cfg.SearchDomains) == 0 {
+			cfg.SearchDomains = []string{coredns.DefaultSearchDomain}
+		}
+		if err := coredns.ValidateSearchDomains(cfg.SearchDomains); err != nil {
+			return nil, err
+		}
+	}
+
+	// Limit the queries each client may send to NextDNS
+	if cf != nil && cf.RateLimit != nil {
+		cfg.RateLimit = buildRateLimit(cf.RateLimit)
+		if err := coredns.ValidateRateLimit(cfg.RateLimit); err != nil {
+			return nil, err
+		}
+		// The defaulting webhook sets the stock image, which lacks the plugin
+		if cfg.RateLimit.NeedsPlugin() && !customImage(coreDNS) {
+			return nil, fmt.Errorf("rate limits other than 0 need a CoreDNS image built with the ratelimit plugin in spec.deployment.image")
+		}
+	}
+
+	// Refuse clients outside the allowed networks
+	if ac := coreDNS.Spec.AccessControl; ac != nil {
+		cfg.AccessControl = &coredns.AccessControlConfig{
+			AllowCIDRs: ac.AllowCIDRs,
+			DenyCIDRs:  ac.DenyCIDRs,
+		}
+		if err := coredns.ValidateAccessControl(cfg.AccessControl); err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
 
+// customImage reports whether spec.deployment.image names an image other
+// than the default CoreDNS image
+func customImage(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	d := coreDNS.Spec.Deployment
+	return d != nil && d.Image != "" && d.Image != coredns.DefaultCoreDNSImage
+}
+
+// buildRateLimit maps the rate limit of the spec
+func buildRateLimit(rateLimit *nextdnsv1alpha1.CoreDNSRateLimitConfig) *coredns.RateLimitConfig {
+	cfg := &coredns.RateLimitConfig{}
+	if rateLimit.QueriesPerSecond != nil {
+		cfg.QueriesPerSecond = *rateLimit.QueriesPerSecond
+	}
+	for _, rule := range rateLimit.Rules {
+		cfg.Rules = append(cfg.Rules, coredns.RateLimitRuleConfig{
+			Sources:          rule.Sources,
+			QueriesPerSecond: rule.QueriesPerSecond,
+		})
+	}
+	return cfg
+}
+
+// configMapItems returns the ConfigMap keys mounted in /etc/coredns: the
+// Corefile, and the local zone file when it is served
+func configMapItems(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) []corev1.KeyToPath {
+	items := []corev1.KeyToPath{
+		{
+			Key:  CorefileKey,
+			Path: "Corefile",
+		},
+	}
+	if localZoneTransfer(coreDNS) != nil {
+		items = append(items, corev1.KeyToPath{Key: LocalZoneKey, Path: LocalZoneKey})
+	}
+	return items
+}
+
 // boolWithDefault returns *p if p is non-nil, otherwise def. Used to
 // mirror kubebuilder `default=true` semantics for pointer-to-bool API
 // fields that control plugin enablement.
@@ -712,12 +1115,13 @@ func boolWithDefault(p *bool, def bool) bool {
 	return *p
 }
 
-// defaultLivenessProbePort and defaultReadinessProbePort mirror the
-// defaults on the corresponding CoreDNS plugin API types and the
-// pre-feature hardcoded probe ports.
+// defaultLivenessProbePort, defaultReadinessProbePort and defaultMetricsPort
+// mirror the defaults on the corresponding CoreDNS plugin API types and the
+// pre-feature hardcoded ports.
 const (
-	defaultLivenessProbePort  int32 = 8080
-	defaultReadinessProbePort int32 = 8181
+	defaultLivenessProbePort  = coredns.DefaultHealthPort
+	defaultReadinessProbePort = coredns.DefaultReadyPort
+	defaultMetricsPort        = coredns.DefaultMetricsPort
 )
 
 // healthPluginEnabled reports whether the health plugin is enabled for
@@ -759,6 +1163,16 @@ func readinessProbePort(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) int32 {
 	return defaultReadinessProbePort
 }
 
+// metricsPort returns the TCP port the prometheus plugin listens on. The
+// container port and Service target port must match it (see
+// spec.corefile.metrics.port) or the default 9153.
+func metricsPort(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) int32 {
+	if cf := coreDNS.Spec.Corefile; cf != nil && cf.Metrics != nil && cf.Metrics.Port != nil {
+		return *cf.Metrics.Port
+	}
+	return defaultMetricsPort
+}
+
 // reconcileWorkload dispatches to Deployment or DaemonSet reconciliation based on mode
 func (r *NextDNSCoreDNSReconciler) reconcileWorkload(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
 	mode := nextdnsv1alpha1.DeploymentModeDeployment // default
@@ -791,6 +1205,7 @@ func (r *NextDNSCoreDNSReconciler) reconcilePDB(ctx context.Context, coreDNS *ne
 	// Determine if PDB should exist
 	shouldExist := coreDNS.Spec.Deployment != nil &&
 		coreDNS.Spec.Deployment.PodDisruptionBudget != nil &&
+		boolValue(coreDNS.Spec.Deployment.PodDisruptionBudget.Enabled, true) &&
 		coreDNS.Spec.Deployment.Mode != nextdnsv1alpha1.DeploymentModeDaemonSet
 
 	if !shouldExist {
@@ -818,7 +1233,7 @@ func (r *NextDNSCoreDNSReconciler) reconcilePDB(ctx context.Context, coreDNS *ne
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
-		pdb.Labels = labels
+		pdb.Labels = r.ResourceLabels.merge(labels)
 		pdb.Spec.Selector = &metav1.LabelSelector{
 			MatchLabels: labels,
 		}
@@ -893,6 +1308,19 @@ func (r *NextDNSCoreDNSReconciler) reconcileDeployment(ctx context.Context, core
 	if coreDNS.Spec.Deployment != nil && coreDNS.Spec.Deployment.Replicas != nil {
 		replicas = *coreDNS.Spec.Deployment.Replicas
 	}
+	autoscaling := autoscalingConfig(coreDNS)
+	if autoscaling != nil {
+		replicas = minReplicas(autoscaling)
+	}
+
+	listeners, err := r.resolveTLSListeners(ctx, coreDNS)
+	if err != nil {
+		return err
+	}
+	corefile, err := r.generateCorefile(coreDNS, profile)
+	if err != nil {
+		return fmt.Errorf("invalid Corefile configuration: %w", err)
+	}
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -901,21 +1329,34 @@ func (r *NextDNSCoreDNSReconciler) reconcileDeployment(ctx context.Context, core
 		},
 	}
 
+	var previousTemplate *corev1.PodTemplateSpec
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
-		deployment.Labels = labels
+		if deployment.ResourceVersion != "" {
+			previousTemplate = deployment.Spec.Template.DeepCopy()
+		}
+		// Leave the replica count of an existing Deployment to the autoscaler
+		if autoscaling != nil && deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		deployment.Labels = r.ResourceLabels.merge(labels)
 		deployment.Spec = appsv1.DeploymentSpec{
 			Replicas: &replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
+			Strategy:        deploymentStrategy(coreDNS),
+			MinReadySeconds: minReadySeconds(coreDNS),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      labels,
+					Labels:      r.ResourceLabels.merge(labels),
 					Annotations: r.buildPodAnnotations(ctx, coreDNS),
 				},
 				Spec: r.buildPodSpec(coreDNS, resourceName),
 			},
 		}
+		applyTLSListeners(&deployment.Spec.Template, coreDNS, listeners)
+		applyCorefileHash(&deployment.Spec.Template, corefileHash(corefile))
 
 		return controllerutil.SetControllerReference(coreDNS, deployment, r.Scheme)
 	})
@@ -927,6 +1368,7 @@ func (r *NextDNSCoreDNSReconciler) reconcileDeployment(ctx context.Context, core
 	if op != controllerutil.OperationResultNone {
 		logger.Info("Deployment reconciled", "operation", op, "name", resourceName)
 	}
+	r.recordWorkloadEvent(coreDNS, "Deployment", resourceName, op, previousTemplate, &deployment.Spec.Template)
 
 	return nil
 }
@@ -938,6 +1380,15 @@ func (r *NextDNSCoreDNSReconciler) reconcileDaemonSet(ctx context.Context, coreD
 	resourceName := r.getResourceName(coreDNS, profile)
 	labels := r.buildLabels(coreDNS, profile)
 
+	listeners, err := r.resolveTLSListeners(ctx, coreDNS)
+	if err != nil {
+		return err
+	}
+	corefile, err := r.generateCorefile(coreDNS, profile)
+	if err != nil {
+		return fmt.Errorf("invalid Corefile configuration: %w", err)
+	}
+
 	daemonSet := &appsv1.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      resourceName,
@@ -945,20 +1396,28 @@ func (r *NextDNSCoreDNSReconciler) reconcileDaemonSet(ctx context.Context, coreD
 		},
 	}
 
+	var previousTemplate *corev1.PodTemplateSpec
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, daemonSet, func() error {
-		daemonSet.Labels = labels
+		if daemonSet.ResourceVersion != "" {
+			previousTemplate = daemonSet.Spec.Template.DeepCopy()
+		}
+		daemonSet.Labels = r.ResourceLabels.merge(labels)
 		daemonSet.Spec = appsv1.DaemonSetSpec{
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
+			UpdateStrategy:  daemonSetUpdateStrategy(coreDNS),
+			MinReadySeconds: minReadySeconds(coreDNS),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      labels,
+					Labels:      r.ResourceLabels.merge(labels),
 					Annotations: r.buildPodAnnotations(ctx, coreDNS),
 				},
 				Spec: r.buildPodSpec(coreDNS, resourceName),
 			},
 		}
+		applyTLSListeners(&daemonSet.Spec.Template, coreDNS, listeners)
+		applyCorefileHash(&daemonSet.Spec.Template, corefileHash(corefile))
 
 		return controllerutil.SetControllerReference(coreDNS, daemonSet, r.Scheme)
 	})
@@ -970,6 +1429,7 @@ func (r *NextDNSCoreDNSReconciler) reconcileDaemonSet(ctx context.Context, coreD
 	if op != controllerutil.OperationResultNone {
 		logger.Info("DaemonSet reconciled", "operation", op, "name", resourceName)
 	}
+	r.recordWorkloadEvent(coreDNS, "DaemonSet", resourceName, op, previousTemplate, &daemonSet.Spec.Template)
 
 	return nil
 }
@@ -989,13 +1449,12 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 	runAsUser := int64(65534) // nobody user
 
 	podSpec := corev1.PodSpec{
-		// ServiceAccountName is intentionally left empty to use the namespace default
-		// ServiceAccount. The controller does not create a dedicated ServiceAccount,
-		// so hardcoding one would cause pods to fail scheduling. Users can configure
-		// a dedicated ServiceAccount via pod security policies if needed.
+		// An empty ServiceAccountName uses the namespace default
+		// ServiceAccount unless spec.deployment.serviceAccount is set
+		ServiceAccountName: serviceAccountName(coreDNS, configMapName),
 		Containers: []corev1.Container{
 			{
-				Name:  "coredns",
+				Name:  corednsContainerName,
 				Image: image,
 				Args:  []string{"-conf", "/etc/coredns/Corefile"},
 				Ports: []corev1.ContainerPort{
@@ -1011,7 +1470,7 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 					},
 					{
 						Name:          "metrics",
-						ContainerPort: 9153,
+						ContainerPort: metricsPort(coreDNS),
 						Protocol:      corev1.ProtocolTCP,
 					},
 				},
@@ -1040,12 +1499,7 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 						LocalObjectReference: corev1.LocalObjectReference{
 							Name: configMapName,
 						},
-						Items: []corev1.KeyToPath{
-							{
-								Key:  CorefileKey,
-								Path: "Corefile",
-							},
-						},
+						Items: configMapItems(coreDNS),
 					},
 				},
 			},
@@ -1055,6 +1509,7 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 			RunAsUser:    &runAsUser,
 		},
 	}
+	applyPodSecurity(&podSpec, coreDNS)
 
 	// Attach liveness / readiness probes conditionally. When the
 	// corresponding CoreDNS plugin is disabled via spec.corefile the
@@ -1095,6 +1550,16 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 		}
 	}
 
+	// Bind DNS ports on the node so clients can query any node directly
+	if hostPortEnabled(coreDNS) {
+		for i := range podSpec.Containers[0].Ports {
+			port := &podSpec.Containers[0].Ports[i]
+			if port.ContainerPort == 53 {
+				port.HostPort = 53
+			}
+		}
+	}
+
 	// Apply deployment-specific settings
 	if coreDNS.Spec.Deployment != nil {
 		if coreDNS.Spec.Deployment.NodeSelector != nil {
@@ -1106,11 +1571,35 @@ func (r *NextDNSCoreDNSReconciler) buildPodSpec(coreDNS *nextdnsv1alpha1.NextDNS
 		if coreDNS.Spec.Deployment.Tolerations != nil {
 			podSpec.Tolerations = coreDNS.Spec.Deployment.Tolerations
 		}
+		podSpec.TopologySpreadConstraints = topologySpreadConstraints(coreDNS)
 		if coreDNS.Spec.Deployment.Resources != nil {
 			podSpec.Containers[0].Resources = *coreDNS.Spec.Deployment.Resources
 		}
 	}
 
+	// Pin the DoH endpoint hostname to the bootstrap servers
+	applyDoHBootstrap(&podSpec, coreDNS)
+
+	// Run as a node-local cache on the host network
+	if nodeLocalEnabled(coreDNS) {
+		applyNodeLocal(&podSpec, coreDNS)
+	}
+
+	// Add the user's sidecars and init containers
+	applyExtraContainers(&podSpec, coreDNS)
+
+	// Keep DNS running on cordoned and not-ready nodes during recovery. A
+	// node-local cache is the resolver of its node, so it always gets this.
+	if criticalAddonEnabled(coreDNS) || nodeLocalEnabled(coreDNS) {
+		podSpec.PriorityClassName = systemNodeCriticalPriorityClass
+		podSpec.Tolerations = mergeTolerations(podSpec.Tolerations, criticalAddonTolerations())
+	}
+
+	// An explicit priority class takes precedence over the preset
+	if coreDNS.Spec.Deployment != nil && coreDNS.Spec.Deployment.PriorityClassName != "" {
+		podSpec.PriorityClassName = coreDNS.Spec.Deployment.PriorityClassName
+	}
+
 	return podSpec
 }
 
@@ -1130,6 +1619,18 @@ func (r *NextDNSCoreDNSReconciler) reconcileService(ctx context.Context, coreDNS
 		}
 	}
 
+	// Resolve node IPs up front so external-dns follows node joins and leaves
+	var externalDNSHostname string
+	var nodeIPs []string
+	if hostPortEnabled(coreDNS) && coreDNS.Spec.Deployment.HostPort.ExternalDNSHostname != "" {
+		externalDNSHostname = coreDNS.Spec.Deployment.HostPort.ExternalDNSHostname
+		ips, err := r.listHostPortNodeIPs(ctx, coreDNS)
+		if err != nil {
+			return err
+		}
+		nodeIPs = ips
+	}
+
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      serviceName,
@@ -1138,7 +1639,7 @@ func (r *NextDNSCoreDNSReconciler) reconcileService(ctx context.Context, coreDNS
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
-		service.Labels = labels
+		service.Labels = r.ResourceLabels.merge(labels)
 
 		// Apply additional annotations if specified
 		if coreDNS.Spec.Service != nil && coreDNS.Spec.Service.Annotations != nil {
@@ -1150,6 +1651,10 @@ func (r *NextDNSCoreDNSReconciler) reconcileService(ctx context.Context, coreDNS
 			}
 		}
 
+		if externalDNSHostname != "" {
+			applyExternalDNSAnnotations(service, externalDNSHostname, nodeIPs)
+		}
+
 		service.Spec = corev1.ServiceSpec{
 			Type:     serviceType,
 			Selector: labels,
@@ -1169,11 +1674,21 @@ func (r *NextDNSCoreDNSReconciler) reconcileService(ctx context.Context, coreDNS
 				{
 					Name:       "metrics",
 					Port:       9153,
-					TargetPort: intstr.FromInt(9153),
+					TargetPort: intstr.FromInt(int(metricsPort(coreDNS))),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
 		}
+		service.Spec.Ports = append(service.Spec.Ports, tlsListenerServicePorts(coreDNS)...)
+
+		// Single-stack, dual-stack or IPv6-only as requested
+		if coreDNS.Spec.Service != nil {
+			service.Spec.IPFamilies = coreDNS.Spec.Service.IPFamilies
+			service.Spec.IPFamilyPolicy = coreDNS.Spec.Service.IPFamilyPolicy
+		}
+
+		// Keep queries on the client's node or zone when requested
+		applyTrafficHints(service, coreDNS)
 
 		// Apply LoadBalancer IP if specified.
 		// NOTE: service.Spec.LoadBalancerIP is deprecated since Kubernetes v1.24
@@ -1197,6 +1712,18 @@ func (r *NextDNSCoreDNSReconciler) reconcileService(ctx context.Context, coreDNS
 	return nil
 }
 
+// serviceClusterIPs returns the ClusterIPs of service, primary first, or
+// nil for a headless Service
+func serviceClusterIPs(service *corev1.Service) []string {
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil
+	}
+	if len(service.Spec.ClusterIPs) > 0 {
+		return service.Spec.ClusterIPs
+	}
+	return []string{service.Spec.ClusterIP}
+}
+
 // buildLabels returns standard Kubernetes labels for the CoreDNS resources
 func (r *NextDNSCoreDNSReconciler) buildLabels(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) map[string]string {
 	return map[string]string{
@@ -1339,28 +1866,57 @@ func (r *NextDNSCoreDNSReconciler) getServiceName(coreDNS *nextdnsv1alpha1.NextD
 	return r.getResourceName(coreDNS, profile)
 }
 
-// updateStatus updates the status of the NextDNSCoreDNS resource
-func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
-	// Get upstream endpoint URL
+// profileUpstreamIPs returns the profile-specific upstream IPs from the
+// profile's NextDNS setup. IPv4 prefers Setup.IPv4 and falls back to
+// LinkedIP.Servers (linked-IP profiles expose per-profile IPs there instead of
+// in the top-level IPv4 field). IPv6 is only returned when enabled in the spec.
+func profileUpstreamIPs(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) ([]string, []string) {
+	setup := profile.Status.Setup
+	if setup == nil {
+		return nil, nil
+	}
+
+	ipv4 := setup.IPv4
+	if len(ipv4) == 0 && setup.LinkedIP != nil {
+		ipv4 = setup.LinkedIP.Servers
+	}
+
+	var ipv6 []string
+	if cf := coreDNS.Spec.Corefile; cf != nil && cf.Upstream != nil && cf.Upstream.IPv6 {
+		ipv6 = setup.IPv6
+	}
+	return ipv4, ipv6
+}
+
+// upstreamStatus returns the upstream connection status of profile
+func upstreamStatus(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) *nextdnsv1alpha1.UpstreamStatus {
 	primaryProtocol := coredns.ProtocolDoT
 	deviceName := ""
 	if coreDNS.Spec.Corefile != nil && coreDNS.Spec.Corefile.Upstream != nil {
 		primaryProtocol = string(coreDNS.Spec.Corefile.Upstream.Primary)
 		deviceName = coreDNS.Spec.Corefile.Upstream.DeviceName
 	}
-	var upstreamIPs []string
-	if profile.Status.Setup != nil {
-		upstreamIPs = profile.Status.Setup.IPv4
-		if len(upstreamIPs) == 0 && profile.Status.Setup.LinkedIP != nil {
-			upstreamIPs = profile.Status.Setup.LinkedIP.Servers
+	upstreamIPv4, upstreamIPv6 := profileUpstreamIPs(coreDNS, profile)
+
+	status := &nextdnsv1alpha1.UpstreamStatus{
+		URL: coredns.GetUpstreamEndpoint(profile.Status.ProfileID, primaryProtocol, deviceName, upstreamIPv4, upstreamIPv6),
+	}
+	if primaryProtocol != coredns.ProtocolDoH {
+		addrs := coredns.UpstreamAddresses(upstreamIPv4, upstreamIPv6)
+		status.IPv4 = addrs[:2]
+		if len(addrs) > 2 {
+			status.IPv6 = addrs[2:]
 		}
+	} else {
+		status.BootstrapServers = dohBootstrapServers(coreDNS)
 	}
-	upstreamURL := coredns.GetUpstreamEndpoint(profile.Status.ProfileID, primaryProtocol, deviceName, upstreamIPs)
+	return status
+}
 
+// updateStatus updates the status of the NextDNSCoreDNS resource
+func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
 	// Update upstream status
-	coreDNS.Status.Upstream = &nextdnsv1alpha1.UpstreamStatus{
-		URL: upstreamURL,
-	}
+	coreDNS.Status.Upstream = upstreamStatus(coreDNS, profile)
 
 	// Get endpoints from Gateway or Service
 	if coreDNS.Spec.Gateway != nil && r.GatewayAPIAvailable {
@@ -1372,6 +1928,7 @@ func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *ne
 		if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: coreDNS.Namespace}, service); err == nil {
 			var endpoints []nextdnsv1alpha1.DNSEndpoint
 
+			var addresses []string
 			switch service.Spec.Type {
 			case corev1.ServiceTypeLoadBalancer:
 				for _, ingress := range service.Status.LoadBalancer.Ingress {
@@ -1380,21 +1937,18 @@ func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *ne
 						ip = ingress.Hostname
 					}
 					if ip != "" {
-						endpoints = append(endpoints,
-							nextdnsv1alpha1.DNSEndpoint{IP: ip, Port: 53, Protocol: "UDP"},
-							nextdnsv1alpha1.DNSEndpoint{IP: ip, Port: 53, Protocol: "TCP"},
-						)
-						coreDNS.Status.DNSIP = ip
+						addresses = append(addresses, ip)
 					}
 				}
 			default:
-				if service.Spec.ClusterIP != "" && service.Spec.ClusterIP != "None" {
-					endpoints = append(endpoints,
-						nextdnsv1alpha1.DNSEndpoint{IP: service.Spec.ClusterIP, Port: 53, Protocol: "UDP"},
-						nextdnsv1alpha1.DNSEndpoint{IP: service.Spec.ClusterIP, Port: 53, Protocol: "TCP"},
-					)
-					coreDNS.Status.DNSIP = service.Spec.ClusterIP
-				}
+				// A dual-stack Service has a ClusterIP per family, primary first
+				addresses = serviceClusterIPs(service)
+			}
+			for _, ip := range addresses {
+				endpoints = append(endpoints, dnsEndpoints(coreDNS, ip)...)
+			}
+			if len(addresses) > 0 {
+				coreDNS.Status.DNSIP = addresses[0]
 			}
 
 			coreDNS.Status.Endpoints = endpoints
@@ -1407,11 +1961,31 @@ func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *ne
 		coreDNS.Status.MultusIPs = multusIPs
 
 		for _, ip := range multusIPs {
-			coreDNS.Status.Endpoints = append(coreDNS.Status.Endpoints,
-				nextdnsv1alpha1.DNSEndpoint{IP: ip, Port: 53, Protocol: "UDP"},
-				nextdnsv1alpha1.DNSEndpoint{IP: ip, Port: 53, Protocol: "TCP"},
-			)
+			coreDNS.Status.Endpoints = append(coreDNS.Status.Endpoints, dnsEndpoints(coreDNS, ip)...)
+		}
+	}
+
+	// Publish node IPs serving DNS via hostPort
+	coreDNS.Status.NodeIPs = nil
+	if hostPortEnabled(coreDNS) {
+		nodeIPs, err := r.listHostPortNodeIPs(ctx, coreDNS)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to resolve hostPort node IPs")
 		}
+		coreDNS.Status.NodeIPs = nodeIPs
+
+		for _, ip := range nodeIPs {
+			coreDNS.Status.Endpoints = append(coreDNS.Status.Endpoints, dnsEndpoints(coreDNS, ip)...)
+		}
+	}
+
+	// Publish a DNS stamp for every endpoint
+	r.setEndpointStamps(ctx, coreDNS)
+
+	// Publish the node-local cache address and kubelet configuration
+	coreDNS.Status.NodeLocal = nil
+	if nodeLocalEnabled(coreDNS) {
+		coreDNS.Status.NodeLocal = nodeLocalStatus(coreDNS)
 	}
 
 	// Get replica status
@@ -1422,17 +1996,26 @@ func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *ne
 
 	resourceName := r.getResourceName(coreDNS, profile)
 	var ready bool
+	now := metav1.Now()
+
+	// The Corefile the current spec produces, to tell a finished rollout of
+	// it from one of an earlier Corefile
+	var currentCorefileHash string
+	if corefile, err := r.generateCorefile(coreDNS, profile); err == nil {
+		currentCorefileHash = corefileHash(corefile)
+	}
 
 	switch mode {
 	case nextdnsv1alpha1.DeploymentModeDaemonSet:
 		daemonSet := &appsv1.DaemonSet{}
 		if err := r.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: coreDNS.Namespace}, daemonSet); err == nil {
+			recordCorefileApplied(coreDNS, &daemonSet.Spec.Template, currentCorefileHash, daemonSetRolledOut(daemonSet), now)
 			coreDNS.Status.Replicas = &nextdnsv1alpha1.ReplicaStatus{
 				Desired:   daemonSet.Status.DesiredNumberScheduled,
 				Ready:     daemonSet.Status.NumberReady,
 				Available: daemonSet.Status.NumberAvailable,
 			}
-			ready = daemonSet.Status.NumberReady > 0 && daemonSet.Status.NumberReady == daemonSet.Status.DesiredNumberScheduled
+			ready = workloadReady(coreDNS, *coreDNS.Status.Replicas)
 		}
 	default:
 		deployment := &appsv1.Deployment{}
@@ -1446,82 +2029,119 @@ func (r *NextDNSCoreDNSReconciler) updateStatus(ctx context.Context, coreDNS *ne
 				Ready:     deployment.Status.ReadyReplicas,
 				Available: deployment.Status.AvailableReplicas,
 			}
-			ready = deployment.Status.ReadyReplicas > 0 && deployment.Status.ReadyReplicas >= desired
+			ready = workloadReady(coreDNS, *coreDNS.Status.Replicas)
+			recordCorefileApplied(coreDNS, &deployment.Spec.Template, currentCorefileHash, deploymentRolledOut(deployment, desired), now)
 		}
 	}
 
+	r.updatePlacement(ctx, coreDNS, now)
+
 	// Update ready status
 	coreDNS.Status.Ready = ready
-	if ready {
+	replicas := nextdnsv1alpha1.ReplicaStatus{}
+	if coreDNS.Status.Replicas != nil {
+		replicas = *coreDNS.Status.Replicas
+	}
+	r.metrics().RecordCoreDNSReplicas(coreDNS.Name, coreDNS.Namespace, replicas.Desired, replicas.Ready, replicas.Available)
+	switch {
+	case ready && replicas.Ready < replicas.Desired:
+		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionTrue, "MinimumReplicasReady",
+			fmt.Sprintf("%d of %d CoreDNS pods are ready, meeting the minimum of %d", replicas.Ready, replicas.Desired, minReadyReplicas(coreDNS)))
+	case ready:
 		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionTrue, "AllResourcesReady", "All CoreDNS resources are ready")
-	} else {
+	default:
 		r.setCondition(coreDNS, ConditionTypeReady, metav1.ConditionFalse, "ResourcesNotReady", "Waiting for workload to become ready")
 	}
 
 	// Update metadata
-	now := metav1.Now()
 	coreDNS.Status.LastUpdated = &now
 	coreDNS.Status.ObservedGeneration = coreDNS.Generation
 
 	return r.Status().Update(ctx, coreDNS)
 }
 
-// setCondition sets a condition on the NextDNSCoreDNS resource
+// setCondition sets a condition on the NextDNSCoreDNS resource, emitting an
+// event when the Ready condition changes
 func (r *NextDNSCoreDNSReconciler) setCondition(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, conditionType string, status metav1.ConditionStatus, reason, message string) {
-	meta.SetStatusCondition(&coreDNS.Status.Conditions, metav1.Condition{
+	condition := metav1.Condition{
 		Type:               conditionType,
 		Status:             status,
 		ObservedGeneration: coreDNS.Generation,
 		LastTransitionTime: metav1.Now(),
 		Reason:             reason,
 		Message:            message,
-	})
-}
-
-// findCoreDNSForProfile returns reconcile requests for NextDNSCoreDNS resources referencing the profile
-func (r *NextDNSCoreDNSReconciler) findCoreDNSForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
-	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
-	if !ok {
-		return nil
 	}
-
-	var coreDNSList nextdnsv1alpha1.NextDNSCoreDNSList
-	if err := r.List(ctx, &coreDNSList); err != nil {
-		return nil
+	if conditionType == ConditionTypeReady {
+		recordConditionEvent(r.Recorder, coreDNS, coreDNS.Status.Conditions, condition)
 	}
+	meta.SetStatusCondition(&coreDNS.Status.Conditions, condition)
+}
 
-	var requests []reconcile.Request
-	for _, coreDNS := range coreDNSList.Items {
-		refNs := coreDNS.Spec.ProfileRef.Namespace
-		if refNs == "" {
-			refNs = coreDNS.Namespace
-		}
-		if coreDNS.Spec.ProfileRef.Name == profile.Name && refNs == profile.Namespace {
-			requests = append(requests, reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name:      coreDNS.Name,
-					Namespace: coreDNS.Namespace,
-				},
-			})
-		}
+// profileConsumedChangedPredicate filters NextDNSProfile updates down to the
+// fields NextDNSCoreDNS consumes: profile ID, fingerprint, setup endpoints and
+// Ready transitions. Profiles update their status on every sync, so other
+// status-only changes are ignored.
+func profileConsumedChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldProfile, ok := e.ObjectOld.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			newProfile, ok := e.ObjectNew.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			return profileresolver.ReadinessChanged(oldProfile, newProfile) ||
+				oldProfile.Status.Fingerprint != newProfile.Status.Fingerprint ||
+				!apiequality.Semantic.DeepEqual(oldProfile.Status.Setup, newProfile.Status.Setup)
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
 	}
-	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager
 func (r *NextDNSCoreDNSReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSCoreDNS{}, profileresolver.IndexField, coreDNSProfileRefIndexFunc); err != nil {
+		return err
+	}
+
 	builder := ctrl.NewControllerManagedBy(mgr).
-		For(&nextdnsv1alpha1.NextDNSCoreDNS{}).
+		For(&nextdnsv1alpha1.NextDNSCoreDNS{}, ctrlbuilder.WithPredicates(resyncClearedPredicate(), testQueryClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSCoreDNSList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.ServiceAccount{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Watches(
 			&nextdnsv1alpha1.NextDNSProfile{},
-			handler.EnqueueRequestsFromMapFunc(r.findCoreDNSForProfile),
+			profileresolver.EnqueueReferencing(mgr.GetClient(), &nextdnsv1alpha1.NextDNSCoreDNSList{}),
+			ctrlbuilder.WithPredicates(profileConsumedChangedPredicate()),
+		).
+		Watches(
+			&corev1.Node{},
+			handler.EnqueueRequestsFromMapFunc(r.findCoreDNSForNode),
+			ctrlbuilder.WithPredicates(nodeTopologyChangedPredicate()),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findCoreDNSForSecret),
 		)
 
+	if r.ServiceMonitorAvailable {
+		builder = builder.Owns(newServiceMonitor())
+	}
+
 	if r.GatewayAPIAvailable {
 		builder = builder.
 			Owns(&gatewayv1.Gateway{}).
@@ -1529,5 +2149,5 @@ func (r *NextDNSCoreDNSReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			Owns(&gatewayv1alpha2.UDPRoute{})
 	}
 
-	return builder.Complete(r)
+	return builder.WithOptions(controllerOptions("nextdnscoredns", r.Workqueue)).Complete(r)
 }
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
@@ -22,11 +24,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
 	"github.com/jacaudi/nextdns-operator/internal/coredns"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+	webhookv1alpha1 "github.com/jacaudi/nextdns-operator/internal/webhook/v1alpha1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
@@ -91,7 +98,7 @@ func TestNextDNSCoreDNSReconciler_ResolveProfile(t *testing.T) {
 	}
 
 	// Test resolveProfile
-	resolvedProfile, err := r.resolveProfile(ctx, coreDNS)
+	resolvedProfile, err := profileresolver.Resolve(ctx, r.Client, coreDNS.Spec.ProfileRef, coreDNS.Namespace)
 	require.NoError(t, err)
 	assert.Equal(t, "test-profile", resolvedProfile.Name)
 	assert.Equal(t, "abc123", resolvedProfile.Status.ProfileID)
@@ -125,7 +132,7 @@ func TestNextDNSCoreDNSReconciler_ResolveProfile_NotFound(t *testing.T) {
 	}
 
 	// Test resolveProfile with missing profile
-	resolvedProfile, err := r.resolveProfile(ctx, coreDNS)
+	resolvedProfile, err := profileresolver.Resolve(ctx, r.Client, coreDNS.Spec.ProfileRef, coreDNS.Namespace)
 	assert.Error(t, err)
 	assert.Nil(t, resolvedProfile)
 	assert.Contains(t, err.Error(), "failed to get NextDNSProfile")
@@ -179,12 +186,12 @@ func TestNextDNSCoreDNSReconciler_ResolveProfile_NotReady(t *testing.T) {
 	}
 
 	// resolveProfile should succeed (profile exists)
-	resolvedProfile, err := r.resolveProfile(ctx, coreDNS)
+	resolvedProfile, err := profileresolver.Resolve(ctx, r.Client, coreDNS.Spec.ProfileRef, coreDNS.Namespace)
 	require.NoError(t, err)
 	assert.NotNil(t, resolvedProfile)
 
-	// But isProfileReady should return false
-	isReady := r.isProfileReady(resolvedProfile)
+	// But the profile should not be ready
+	isReady := profileresolver.IsReady(resolvedProfile)
 	assert.False(t, isReady, "Profile without Ready=True condition should not be ready")
 }
 
@@ -236,7 +243,7 @@ func TestNextDNSCoreDNSReconciler_ResolveProfile_CrossNamespace(t *testing.T) {
 		Scheme: scheme,
 	}
 
-	resolvedProfile, err := r.resolveProfile(ctx, coreDNS)
+	resolvedProfile, err := profileresolver.Resolve(ctx, r.Client, coreDNS.Spec.ProfileRef, coreDNS.Namespace)
 	require.NoError(t, err)
 	assert.Equal(t, "shared-profile", resolvedProfile.Name)
 	assert.Equal(t, "shared", resolvedProfile.Namespace)
@@ -476,100 +483,6 @@ func TestNextDNSCoreDNSReconciler_BuildLabels(t *testing.T) {
 	assert.Len(t, labels, 5)
 }
 
-func TestNextDNSCoreDNSReconciler_IsProfileReady(t *testing.T) {
-	scheme := newCoreDNSTestScheme()
-
-	r := &NextDNSCoreDNSReconciler{
-		Scheme: scheme,
-	}
-
-	tests := []struct {
-		name      string
-		profile   *nextdnsv1alpha1.NextDNSProfile
-		wantReady bool
-	}{
-		{
-			name: "profile with Ready=True",
-			profile: &nextdnsv1alpha1.NextDNSProfile{
-				Status: nextdnsv1alpha1.NextDNSProfileStatus{
-					ProfileID: "abc123",
-					Conditions: []metav1.Condition{
-						{
-							Type:   ConditionTypeReady,
-							Status: metav1.ConditionTrue,
-							Reason: "Ready",
-						},
-					},
-				},
-			},
-			wantReady: true,
-		},
-		{
-			name: "profile with Ready=False",
-			profile: &nextdnsv1alpha1.NextDNSProfile{
-				Status: nextdnsv1alpha1.NextDNSProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   ConditionTypeReady,
-							Status: metav1.ConditionFalse,
-							Reason: "Syncing",
-						},
-					},
-				},
-			},
-			wantReady: false,
-		},
-		{
-			name: "profile with Ready=Unknown",
-			profile: &nextdnsv1alpha1.NextDNSProfile{
-				Status: nextdnsv1alpha1.NextDNSProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   ConditionTypeReady,
-							Status: metav1.ConditionUnknown,
-							Reason: "Initializing",
-						},
-					},
-				},
-			},
-			wantReady: false,
-		},
-		{
-			name: "profile without Ready condition",
-			profile: &nextdnsv1alpha1.NextDNSProfile{
-				Status: nextdnsv1alpha1.NextDNSProfileStatus{
-					ProfileID: "abc123",
-					Conditions: []metav1.Condition{
-						{
-							Type:   ConditionTypeSynced,
-							Status: metav1.ConditionTrue,
-							Reason: "Synced",
-						},
-					},
-				},
-			},
-			wantReady: false,
-		},
-		{
-			name: "profile with no conditions",
-			profile: &nextdnsv1alpha1.NextDNSProfile{
-				Status: nextdnsv1alpha1.NextDNSProfileStatus{
-					ProfileID:  "abc123",
-					Conditions: []metav1.Condition{},
-				},
-			},
-			wantReady: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			isReady := r.isProfileReady(tt.profile)
-			assert.Equal(t, tt.wantReady, isReady)
-		})
-	}
-}
-
 func TestNextDNSCoreDNSReconciler_Constants(t *testing.T) {
 	// Verify important constants are defined correctly
 	assert.Equal(t, "nextdns.io/coredns-finalizer", CoreDNSFinalizerName)
@@ -1176,21 +1089,56 @@ func TestNextDNSCoreDNSReconciler_Reconcile_LoadBalancerService(t *testing.T) {
 	assert.Equal(t, "dns.example.com", service.Annotations["external-dns.alpha.kubernetes.io/hostname"], "External DNS annotation should be present")
 }
 
-func TestNextDNSCoreDNSReconciler_BuildCorefileConfig(t *testing.T) {
+func TestNextDNSCoreDNSReconciler_DualStackService(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
 
-	r := &NextDNSCoreDNSReconciler{
-		Scheme: scheme,
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-profile", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	policy := corev1.IPFamilyPolicyRequireDualStack
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
+			Service: &nextdnsv1alpha1.CoreDNSServiceConfig{
+				IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+				IPFamilyPolicy: &policy,
+			},
+		},
 	}
 
-	// Helper to create a pointer to bool
-	boolPtr := func(b bool) *bool {
-		return &b
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, coreDNS).WithStatusSubresource(coreDNS).Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, r.reconcileService(ctx, coreDNS, profile))
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-coredns-abc123-coredns", Namespace: "default"}, service))
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}, service.Spec.IPFamilies)
+	assert.Equal(t, &policy, service.Spec.IPFamilyPolicy)
+
+	// Both ClusterIPs are reported, the primary one as dnsIP
+	service.Spec.ClusterIP = "fd00:10:96::53"
+	service.Spec.ClusterIPs = []string{"fd00:10:96::53", "10.96.0.53"}
+	require.NoError(t, fakeClient.Update(ctx, service))
+	require.NoError(t, r.updateStatus(ctx, coreDNS, profile))
+
+	assert.Equal(t, "fd00:10:96::53", coreDNS.Status.DNSIP)
+	var ips []string
+	for _, endpoint := range coreDNS.Status.Endpoints {
+		if endpoint.Protocol == "UDP" {
+			ips = append(ips, endpoint.IP)
+		}
 	}
+	assert.Equal(t, []string{"fd00:10:96::53", "10.96.0.53"}, ips)
+}
 
-	// Helper to create a pointer to int32
-	int32Ptr := func(i int32) *int32 {
-		return &i
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	r := &NextDNSCoreDNSReconciler{
+		Scheme: scheme,
 	}
 
 	tests := []struct {
@@ -1216,13 +1164,13 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig(t *testing.T) {
 							Primary: nextdnsv1alpha1.DNSProtocolDoT,
 						},
 						Cache: &nextdnsv1alpha1.CoreDNSCacheConfig{
-							SuccessTTL: int32Ptr(60),
+							SuccessTTL: ptr.To[int32](60),
 						},
 						Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
-							Enabled: boolPtr(true),
+							Enabled: ptr.To(true),
 						},
 						Logging: &nextdnsv1alpha1.CoreDNSLoggingConfig{
-							Enabled: boolPtr(true),
+							Enabled: ptr.To(true),
 						},
 					},
 				},
@@ -1869,6 +1817,150 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_DuplicateDomainError(t *te
 	assert.Contains(t, err.Error(), "duplicate domain override")
 }
 
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithSearchPath(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				SearchPath: &nextdnsv1alpha1.SearchPathConfig{},
+			},
+		},
+	}
+
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster.local"}, cfg.SearchDomains, "should default to the cluster domain")
+
+	coreDNS.Spec.Corefile.SearchPath.Domains = []string{"cluster.local", "ec2.internal"}
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster.local", "ec2.internal"}, cfg.SearchDomains)
+
+	coreDNS.Spec.Corefile.SearchPath.Domains = []string{"ec2..internal"}
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, "invalid search domain")
+
+	coreDNS.Spec.Corefile.SearchPath.Enabled = ptr.To(false)
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.SearchDomains)
+}
+
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithRateLimit(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				RateLimit: &nextdnsv1alpha1.CoreDNSRateLimitConfig{
+					Rules: []nextdnsv1alpha1.RateLimitRule{{Sources: []string{"10.42.7.0/24"}, QueriesPerSecond: ptr.To[int32](0)}},
+				},
+			},
+		},
+	}
+
+	// Drops only need the acl plugin of the default image
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.RateLimit)
+	assert.Equal(t, []coredns.RateLimitRuleConfig{{Sources: []string{"10.42.7.0/24"}, QueriesPerSecond: ptr.To[int32](0)}}, cfg.RateLimit.Rules)
+
+	coreDNS.Spec.Corefile.RateLimit.QueriesPerSecond = ptr.To[int32](50)
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, "need a CoreDNS image built with the ratelimit plugin")
+
+	coreDNS.Spec.Deployment = &nextdnsv1alpha1.CoreDNSDeploymentConfig{Image: "registry.example.com/coredns-ratelimit:1.13.1"}
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, int32(50), cfg.RateLimit.QueriesPerSecond)
+
+	coreDNS.Spec.Corefile.RateLimit.Rules[0].Sources = []string{"10.42.7.0"}
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, `invalid CIDR "10.42.7.0"`)
+}
+
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_RateLimitDefaultedImage(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				RateLimit: &nextdnsv1alpha1.CoreDNSRateLimitConfig{QueriesPerSecond: ptr.To[int32](50)},
+			},
+		},
+	}
+
+	// The defaulting webhook fills in the stock image, which has no ratelimit plugin
+	webhookv1alpha1.SetNextDNSCoreDNSDefaults(coreDNS)
+	require.Equal(t, coredns.DefaultCoreDNSImage, coreDNS.Spec.Deployment.Image)
+	_, err := r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, "need a CoreDNS image built with the ratelimit plugin")
+
+	// Drops still work on the stock image
+	coreDNS.Spec.Corefile.RateLimit = &nextdnsv1alpha1.CoreDNSRateLimitConfig{
+		Rules: []nextdnsv1alpha1.RateLimitRule{{Sources: []string{"10.42.7.0/24"}, QueriesPerSecond: ptr.To[int32](0)}},
+	}
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+}
+
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithAccessControl(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			AccessControl: &nextdnsv1alpha1.CoreDNSAccessControlConfig{
+				AllowCIDRs: []string{"192.168.0.0/16"},
+				DenyCIDRs:  []string{"192.168.50.0/24"},
+			},
+		},
+	}
+
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, &coredns.AccessControlConfig{
+		AllowCIDRs: []string{"192.168.0.0/16"},
+		DenyCIDRs:  []string{"192.168.50.0/24"},
+	}, cfg.AccessControl)
+
+	coreDNS.Spec.AccessControl.DenyCIDRs = []string{"192.168.50.1"}
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, `invalid denied CIDR "192.168.50.1"`)
+}
+
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithRedactedLogging(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Logging: &nextdnsv1alpha1.CoreDNSLoggingConfig{
+					Enabled:             ptr.To(true),
+					RedactClientAddress: true,
+				},
+			},
+		},
+	}
+
+	cfg, err := r.buildCorefileConfig(coreDNS, &nextdnsv1alpha1.NextDNSProfile{})
+	require.NoError(t, err)
+	assert.True(t, cfg.LoggingEnabled)
+	assert.True(t, cfg.LogRedactClient)
+}
+
 func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithDeviceName(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
@@ -2014,6 +2106,58 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_InvalidForwardTuning(t *te
 	assert.Contains(t, err.Error(), "forward tuning validation failed")
 }
 
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithCacheTuning(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Cache: &nextdnsv1alpha1.CoreDNSCacheConfig{
+					SuccessTTL: ptr.To[int32](600),
+					DenialTTL:  ptr.To[int32](30),
+					Prefetch: &nextdnsv1alpha1.CoreDNSCachePrefetchConfig{
+						Amount:     10,
+						Duration:   "2m",
+						Percentage: ptr.To[int32](15),
+					},
+					ServeStale: &nextdnsv1alpha1.CoreDNSCacheServeStaleConfig{
+						Duration:    "30m",
+						RefreshMode: "verify",
+					},
+				},
+			},
+		},
+	}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+		},
+	}
+
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, int32(600), cfg.CacheTTL)
+	assert.Equal(t, &coredns.CacheTuningConfig{
+		DenialTTL:  ptr.To[int32](30),
+		Prefetch:   &coredns.CachePrefetchConfig{Amount: 10, Duration: "2m", Percentage: ptr.To[int32](15)},
+		ServeStale: &coredns.CacheServeStaleConfig{Duration: "30m", RefreshMode: "verify"},
+	}, cfg.CacheTuning)
+
+	// Tuning is dropped along with the cache
+	coreDNS.Spec.Corefile.Cache.Enabled = ptr.To(false)
+	cfg, err = r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.CacheTuning)
+
+	// Invalid tuning is rejected
+	coreDNS.Spec.Corefile.Cache.Enabled = nil
+	coreDNS.Spec.Corefile.Cache.Prefetch.Amount = 0
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, "cache tuning validation failed")
+}
+
 // TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithPluginConfig verifies
 // that spec.corefile.{health,ready,errors,metrics.port} are copied into the
 // internal coredns.CorefileConfig and that ValidatePluginConfig is called.
@@ -2021,30 +2165,27 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithPluginConfig(t *testin
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	int32Ptr := func(i int32) *int32 { return &i }
-	boolPtr := func(b bool) *bool { return &b }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Health: &nextdnsv1alpha1.CoreDNSHealthConfig{
-					Enabled:  boolPtr(true),
-					Port:     int32Ptr(9090),
+					Enabled:  ptr.To(true),
+					Port:     ptr.To[int32](9090),
 					Lameduck: "10s",
 				},
 				Ready: &nextdnsv1alpha1.CoreDNSReadyConfig{
-					Enabled: boolPtr(true),
-					Port:    int32Ptr(9191),
+					Enabled: ptr.To(true),
+					Port:    ptr.To[int32](9191),
 				},
 				Errors: &nextdnsv1alpha1.CoreDNSErrorsConfig{
-					Enabled: boolPtr(true),
+					Enabled: ptr.To(true),
 					Consolidate: []nextdnsv1alpha1.ConsolidateRule{
 						{Interval: "5m", Pattern: "^[a-z]+error"},
 					},
 				},
 				Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
-					Enabled: boolPtr(true),
-					Port:    int32Ptr(9292),
+					Enabled: ptr.To(true),
+					Port:    ptr.To[int32](9292),
 				},
 			},
 		},
@@ -2085,6 +2226,61 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithPluginConfig(t *testin
 	assert.Contains(t, out, "errors {")
 }
 
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_MetricsServers(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				DomainOverrides: []nextdnsv1alpha1.DomainOverride{
+					{Domain: "corp.example.com", Upstreams: []string{"10.0.0.1"}},
+					{Domain: "lab.example.com", Upstreams: []string{"10.0.0.2"}},
+				},
+				Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
+					Address:         "10.0.0.53",
+					DomainOverrides: true,
+					ExcludeDomains:  []string{"lab.example.com"},
+				},
+			},
+		},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.53", cfg.MetricsAddress)
+	require.Len(t, cfg.DomainOverrides, 2)
+	assert.True(t, cfg.DomainOverrides[0].Metrics, "corp.example.com should record metrics")
+	assert.False(t, cfg.DomainOverrides[1].Metrics, "excluded lab.example.com should not record metrics")
+
+	coreDNS.Spec.Corefile.Metrics.ExcludeDomains = []string{"missing.example.com"}
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.Error(t, err)
+}
+
+func TestBuildPodSpec_MetricsPort(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	port := int32(9253)
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{Port: &port},
+			},
+		},
+	}
+
+	podSpec := r.buildPodSpec(coreDNS, "test-cm")
+	for _, p := range podSpec.Containers[0].Ports {
+		if p.Name == "metrics" {
+			assert.Equal(t, port, p.ContainerPort)
+		}
+	}
+}
+
 // TestNextDNSCoreDNSReconciler_BuildCorefileConfig_EnabledDefaultsTrue verifies
 // that when spec.corefile.health exists but spec.corefile.health.enabled is
 // nil, the copied config defaults to Enabled=true (matching the kubebuilder
@@ -2094,17 +2290,15 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_EnabledDefaultsTrue(t *tes
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	int32Ptr := func(i int32) *int32 { return &i }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Health: &nextdnsv1alpha1.CoreDNSHealthConfig{
-					Port: int32Ptr(9090),
+					Port: ptr.To[int32](9090),
 					// Enabled unset — must default to true
 				},
 				Ready: &nextdnsv1alpha1.CoreDNSReadyConfig{
-					Port: int32Ptr(9191),
+					Port: ptr.To[int32](9191),
 				},
 				Errors: &nextdnsv1alpha1.CoreDNSErrorsConfig{},
 			},
@@ -2132,19 +2326,16 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_PortCollision(t *testing.T
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	int32Ptr := func(i int32) *int32 { return &i }
-	boolPtr := func(b bool) *bool { return &b }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Health: &nextdnsv1alpha1.CoreDNSHealthConfig{
-					Enabled: boolPtr(true),
-					Port:    int32Ptr(9090),
+					Enabled: ptr.To(true),
+					Port:    ptr.To[int32](9090),
 				},
 				Ready: &nextdnsv1alpha1.CoreDNSReadyConfig{
-					Enabled: boolPtr(true),
-					Port:    int32Ptr(9090), // same port as health
+					Enabled: ptr.To(true),
+					Port:    ptr.To[int32](9090), // same port as health
 				},
 			},
 		},
@@ -2194,18 +2385,16 @@ func TestNextDNSCoreDNSReconciler_BuildPodSpec_ProbePorts_Configured(t *testing.
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	int32Ptr := func(i int32) *int32 { return &i }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Health: &nextdnsv1alpha1.CoreDNSHealthConfig{
-					Port: int32Ptr(9090),
+					Port: ptr.To[int32](9090),
 				},
 				Ready: &nextdnsv1alpha1.CoreDNSReadyConfig{
-					Port: int32Ptr(9191),
+					Port: ptr.To[int32](9191),
 				},
 			},
 		},
@@ -2234,15 +2423,13 @@ func TestNextDNSCoreDNSReconciler_BuildPodSpec_ProbePorts_HealthDisabled(t *test
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	boolPtr := func(b bool) *bool { return &b }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Health: &nextdnsv1alpha1.CoreDNSHealthConfig{
-					Enabled: boolPtr(false),
+					Enabled: ptr.To(false),
 				},
 			},
 		},
@@ -2262,15 +2449,13 @@ func TestNextDNSCoreDNSReconciler_BuildPodSpec_ProbePorts_ReadyDisabled(t *testi
 	scheme := newCoreDNSTestScheme()
 	r := &NextDNSCoreDNSReconciler{Scheme: scheme}
 
-	boolPtr := func(b bool) *bool { return &b }
-
 	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
 		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
 			Corefile: &nextdnsv1alpha1.CorefileSpec{
 				Ready: &nextdnsv1alpha1.CoreDNSReadyConfig{
-					Enabled: boolPtr(false),
+					Enabled: ptr.To(false),
 				},
 			},
 		},
@@ -3125,6 +3310,72 @@ func TestNextDNSCoreDNSReconciler_Reconcile_PDBWithMinAvailable(t *testing.T) {
 	assert.Nil(t, pdb.Spec.MaxUnavailable, "MaxUnavailable should not be set when MinAvailable is specified")
 }
 
+func TestNextDNSCoreDNSReconciler_Reconcile_PDBDisabledDeletesBudget(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	replicas := int32(3)
+	minAvailable := intstr.FromInt32(2)
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "ha-dns-toggle",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Replicas: &replicas,
+				PodDisruptionBudget: &nextdnsv1alpha1.CoreDNSPDBConfig{
+					Enabled:      ptr.To(true),
+					MinAvailable: &minAvailable,
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "ha-dns-toggle", Namespace: "default"}}
+	pdbKey := types.NamespacedName{Name: "ha-dns-toggle-abc123-coredns-pdb", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	require.NoError(t, fakeClient.Get(ctx, pdbKey, pdb), "PDB should be created when enabled")
+	require.Len(t, pdb.OwnerReferences, 1)
+	assert.Equal(t, "ha-dns-toggle", pdb.OwnerReferences[0].Name)
+
+	// Disable the budget but keep its settings
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.Deployment.PodDisruptionBudget.Enabled = ptr.To(false)
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, pdbKey, pdb)
+	assert.True(t, apierrors.IsNotFound(err), "PDB should be deleted when disabled")
+}
+
 func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithSetupIPs(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	reconciler := &NextDNSCoreDNSReconciler{Scheme: scheme}
@@ -3173,6 +3424,67 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithLinkedIPFallback(t *te
 	assert.Equal(t, []string{"45.90.28.208", "45.90.30.208"}, cfg.UpstreamIPv4)
 }
 
+func TestNextDNSCoreDNSReconciler_UpdateStatus_UpstreamIPs(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "ce63cd",
+			Setup: &nextdnsv1alpha1.ProfileSetup{
+				IPv4: []string{"45.90.28.208", "45.90.30.208"},
+				IPv6: []string{"2a07:a8c0::ce:63cd", "2a07:a8c1::ce:63cd"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		upstream *nextdnsv1alpha1.UpstreamConfig
+		wantIPv4 []string
+		wantIPv6 []string
+	}{
+		{
+			name:     "DoT defaults to IPv4 only",
+			wantIPv4: []string{"45.90.28.208", "45.90.30.208"},
+		},
+		{
+			name:     "DoT with IPv6",
+			upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDoT, IPv6: true},
+			wantIPv4: []string{"45.90.28.208", "45.90.30.208"},
+			wantIPv6: []string{"2a07:a8c0::ce:63cd", "2a07:a8c1::ce:63cd"},
+		},
+		{
+			name:     "DoH uses the hostname",
+			upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDoH, IPv6: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+					ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+					Corefile:   &nextdnsv1alpha1.CorefileSpec{Upstream: tt.upstream},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(coreDNS, profile).
+				WithStatusSubresource(coreDNS).
+				Build()
+			r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+			require.NoError(t, r.updateStatus(context.Background(), coreDNS, profile))
+			require.NotNil(t, coreDNS.Status.Upstream)
+			assert.Equal(t, tt.wantIPv4, coreDNS.Status.Upstream.IPv4)
+			assert.Equal(t, tt.wantIPv6, coreDNS.Status.Upstream.IPv6)
+		})
+	}
+}
+
 func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithoutSetup(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	reconciler := &NextDNSCoreDNSReconciler{Scheme: scheme}
@@ -3260,6 +3572,65 @@ func TestNextDNSCoreDNSReconciler_Reconcile_GatewayMutualExclusivity(t *testing.
 	assert.Equal(t, "InvalidConfiguration", cond.Reason)
 }
 
+func TestNextDNSCoreDNSReconciler_Reconcile_UnsupportedProtocol(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "default",
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{Name: "Test"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "abc123.dns.nextdns.io",
+			Conditions: []metav1.Condition{
+				{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-coredns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: nextdnsv1alpha1.DNSProtocolDoQ},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(profile, coreDNS).
+		Build()
+
+	reconciler := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-coredns", Namespace: "default"}}
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	updated := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, updated))
+
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "UnsupportedProtocol", cond.Reason)
+
+	// No Corefile is rendered for the unsupported protocol
+	configMaps := &corev1.ConfigMapList{}
+	require.NoError(t, fakeClient.List(ctx, configMaps))
+	assert.Empty(t, configMaps.Items)
+}
+
 func TestNextDNSCoreDNSReconciler_Reconcile_GatewayCRDsMissing(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	ctx := context.Background()
@@ -3523,6 +3894,38 @@ func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithHosts(t *testing.T) {
 	assert.Equal(t, int32(60), cfg.Hosts.TTL)
 }
 
+func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithLocalRecords(t *testing.T) {
+	reconciler := &NextDNSCoreDNSReconciler{Scheme: newCoreDNSTestScheme()}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				LocalRecords: []nextdnsv1alpha1.LocalRecord{
+					{Name: "nas.home.lan", Value: "192.168.1.10"},
+					{Name: "files.home.lan", Type: "CNAME", Value: "nas.home.lan", TTL: ptr.To[int32](60)},
+				},
+				ReverseRecords: true,
+			},
+		},
+	}
+
+	cfg, err := reconciler.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []coredns.LocalRecordConfig{
+		{Name: "nas.home.lan", Type: coredns.RecordTypeA, Value: "192.168.1.10", TTL: coredns.DefaultLocalRecordTTL},
+		{Name: "files.home.lan", Type: coredns.RecordTypeCNAME, Value: "nas.home.lan", TTL: 60},
+	}, cfg.LocalRecords)
+	assert.True(t, cfg.ReverseRecords)
+
+	coreDNS.Spec.Corefile.LocalRecords = append(coreDNS.Spec.Corefile.LocalRecords,
+		nextdnsv1alpha1.LocalRecord{Name: "files.home.lan", Value: "192.168.1.11"})
+	_, err = reconciler.buildCorefileConfig(coreDNS, profile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "local record validation failed")
+}
+
 func TestNextDNSCoreDNSReconciler_BuildCorefileConfig_WithHosts_DefaultFallthrough(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	reconciler := &NextDNSCoreDNSReconciler{Scheme: scheme}
@@ -3609,7 +4012,7 @@ func TestNextDNSCoreDNSReconciler_Reconcile_ReplicasAndParametersRefConflict(t *
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
 			Gateway: &nextdnsv1alpha1.GatewayConfig{
-				GatewayClassName: stringPtr("envoy-gateway"),
+				GatewayClassName: ptr.To("envoy-gateway"),
 				Addresses:        []nextdnsv1alpha1.GatewayAddress{{Type: &ipType, Value: "192.168.1.53"}},
 				Replicas:         &replicas,
 				Infrastructure: &nextdnsv1alpha1.GatewayInfrastructure{
@@ -3653,11 +4056,6 @@ func TestNextDNSCoreDNSReconciler_Reconcile_ReplicasAndParametersRefConflict(t *
 	assert.Contains(t, cond.Message, "mutually exclusive")
 }
 
-// stringPtr is a helper to get a pointer to a string literal.
-func stringPtr(s string) *string {
-	return &s
-}
-
 func TestNextDNSCoreDNSReconciler_Reconcile_GatewayReplicas(t *testing.T) {
 	scheme := newCoreDNSTestScheme()
 	utilruntime.Must(gatewayv1.Install(scheme))
@@ -3700,7 +4098,7 @@ func TestNextDNSCoreDNSReconciler_Reconcile_GatewayReplicas(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
 			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
 			Gateway: &nextdnsv1alpha1.GatewayConfig{
-				GatewayClassName: stringPtr("envoy-gateway"),
+				GatewayClassName: ptr.To("envoy-gateway"),
 				Addresses:        []nextdnsv1alpha1.GatewayAddress{{Type: &ipType, Value: "192.168.1.53"}},
 				Replicas:         &replicas,
 			},
@@ -3746,3 +4144,221 @@ func TestNextDNSCoreDNSReconciler_Reconcile_GatewayReplicas(t *testing.T) {
 	assert.Equal(t, gatewayv1.Kind("EnvoyProxy"), gw.Spec.Infrastructure.ParametersRef.Kind)
 	assert.Equal(t, "test-coredns-envoyproxy", gw.Spec.Infrastructure.ParametersRef.Name)
 }
+
+func TestProfileConsumedChangedPredicate(t *testing.T) {
+	p := profileConsumedChangedPredicate()
+
+	ready := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: "Synced"},
+			},
+		},
+	}
+
+	heartbeat := ready.DeepCopy()
+	now := metav1.Now()
+	heartbeat.Status.LastSyncTime = &now
+	heartbeat.Status.Conditions[0].Message = "synced again"
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: heartbeat}))
+
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = metav1.ConditionFalse
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: notReady}))
+
+	newFingerprint := ready.DeepCopy()
+	newFingerprint.Status.Fingerprint = "fp-def456"
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: newFingerprint}))
+
+	newSetup := ready.DeepCopy()
+	newSetup.Status.Setup = &nextdnsv1alpha1.ProfileSetup{IPv4: []string{"45.90.28.1"}}
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: newSetup}))
+
+	assert.True(t, p.Create(event.CreateEvent{Object: ready}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: ready}))
+	assert.False(t, p.Generic(event.GenericEvent{Object: ready}))
+}
+
+// TestSetNextDNSCoreDNSDefaults_PreservesBehavior verifies that the values
+// written by the defaulting webhook produce exactly the same Corefile and pod
+// spec as leaving the fields unset
+func TestSetNextDNSCoreDNSDefaults_PreservesBehavior(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+
+	minimal := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"},
+		},
+	}
+	defaulted := minimal.DeepCopy()
+	webhookv1alpha1.SetNextDNSCoreDNSDefaults(defaulted)
+
+	minimalCfg, err := r.buildCorefileConfig(minimal, profile)
+	require.NoError(t, err)
+	defaultedCfg, err := r.buildCorefileConfig(defaulted, profile)
+	require.NoError(t, err)
+	assert.Equal(t, coredns.GenerateCorefile(minimalCfg), coredns.GenerateCorefile(defaultedCfg))
+
+	assert.Equal(t, r.buildPodSpec(minimal, "test-cm"), r.buildPodSpec(defaulted, "test-cm"))
+	assert.Equal(t, defaultReplicas, *defaulted.Spec.Deployment.Replicas)
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_SuspendWorkload(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-dns", Namespace: "default"}}
+	resourceKey := types.NamespacedName{Name: "test-dns-abc123-coredns", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	// Suspend the workload and stage a new image and cache TTL
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	coreDNS.Spec.SuspendWorkload = true
+	coreDNS.Spec.Deployment = &nextdnsv1alpha1.CoreDNSDeploymentConfig{Image: "mirror.gcr.io/coredns/coredns:1.14.0"}
+	coreDNS.Spec.Corefile = &nextdnsv1alpha1.CorefileSpec{
+		Cache: &nextdnsv1alpha1.CoreDNSCacheConfig{SuccessTTL: ptr.To[int32](600)},
+	}
+	require.NoError(t, fakeClient.Update(ctx, coreDNS))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, deployment))
+	assert.Equal(t, coredns.DefaultCoreDNSImage, deployment.Spec.Template.Spec.Containers[0].Image, "the workload is left unchanged")
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, configMap))
+	assert.Contains(t, configMap.Data[CorefileKey], "cache 600", "the Corefile is still staged")
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	condition := meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeWorkloadSuspended)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// Clearing the field rolls out the staged changes
+	coreDNS.Spec.SuspendWorkload = false
+	require.NoError(t, fakeClient.Update(ctx, coreDNS))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, resourceKey, deployment))
+	assert.Equal(t, "mirror.gcr.io/coredns/coredns:1.14.0", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, corefileHash(configMap.Data[CorefileKey]), deployment.Spec.Template.Annotations[AnnotationCorefileHash])
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, coreDNS))
+	assert.Nil(t, meta.FindStatusCondition(coreDNS.Status.Conditions, ConditionTypeWorkloadSuspended))
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_Metrics(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "abc123.dns.nextdns.io",
+			Conditions: []metav1.Condition{
+				{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-dns", Namespace: "default", Finalizers: []string{CoreDNSFinalizerName}},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{Replicas: ptr.To[int32](3)},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(profile, coreDNS, &appsv1.Deployment{}).
+		Build()
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme, Metrics: m}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "metrics-dns", Namespace: "default"}}
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.CoreDNSReady.WithLabelValues("metrics-dns", "default")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.CoreDNSReplicasDesired.WithLabelValues("metrics-dns", "default")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.CoreDNSReplicasReady.WithLabelValues("metrics-dns", "default")))
+	assert.Greater(t, testutil.ToFloat64(m.CoreDNSReconcileDuration.WithLabelValues("metrics-dns", "default")), 0.0)
+
+	// Two of three pods become available
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "metrics-dns-abc123-coredns", Namespace: "default"}, deployment))
+	deployment.Status.ReadyReplicas = 2
+	deployment.Status.AvailableReplicas = 2
+	require.NoError(t, fakeClient.Status().Update(ctx, deployment))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.CoreDNSReplicasReady.WithLabelValues("metrics-dns", "default")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.CoreDNSReplicasAvailable.WithLabelValues("metrics-dns", "default")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.CoreDNSReady.WithLabelValues("metrics-dns", "default")))
+
+	// A deleted instance leaves no series behind
+	require.NoError(t, fakeClient.Delete(ctx, coreDNS))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, testutil.CollectAndCount(m.CoreDNSReady))
+	assert.Zero(t, testutil.CollectAndCount(m.CoreDNSReplicasDesired))
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_CorefileErrorMetric(t *testing.T) {
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	r := &NextDNSCoreDNSReconciler{Client: fake.NewClientBuilder().WithScheme(newCoreDNSTestScheme()).Build(), Metrics: m}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: "Carrier-Pigeon"},
+			},
+		},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"}}
+
+	require.Error(t, r.reconcileConfigMap(context.Background(), coreDNS, profile, nil))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CoreDNSCorefileErrorsTotal.WithLabelValues("broken-dns", "default")))
+}
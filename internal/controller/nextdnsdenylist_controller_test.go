@@ -8,8 +8,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -36,18 +36,18 @@ func TestNextDNSDenylistReconciler_countActiveDomains(t *testing.T) {
 		{
 			name: "mixed active and inactive domains",
 			domains: []nextdnsv1alpha1.DomainEntry{
-				{Domain: "example.com", Active: boolPtr(true)},
-				{Domain: "test.com", Active: boolPtr(false)},
+				{Domain: "example.com", Active: ptr.To(true)},
+				{Domain: "test.com", Active: ptr.To(false)},
 				{Domain: "demo.org", Active: nil},
-				{Domain: "inactive.com", Active: boolPtr(false)},
+				{Domain: "inactive.com", Active: ptr.To(false)},
 			},
 			expected: 2,
 		},
 		{
 			name: "no active domains",
 			domains: []nextdnsv1alpha1.DomainEntry{
-				{Domain: "example.com", Active: boolPtr(false)},
-				{Domain: "test.com", Active: boolPtr(false)},
+				{Domain: "example.com", Active: ptr.To(false)},
+				{Domain: "test.com", Active: ptr.To(false)},
 			},
 			expected: 0,
 		},
@@ -67,8 +67,7 @@ func TestNextDNSDenylistReconciler_countActiveDomains(t *testing.T) {
 }
 
 func TestNextDNSDenylistReconciler_findProfileReferences(t *testing.T) {
-	scheme := runtime.NewScheme()
-	_ = nextdnsv1alpha1.AddToScheme(scheme)
+	scheme := newTestScheme()
 
 	tests := []struct {
 		name     string
@@ -213,8 +212,7 @@ func TestNextDNSDenylistReconciler_findProfileReferences(t *testing.T) {
 }
 
 func TestNextDNSDenylistReconciler_Reconcile(t *testing.T) {
-	scheme := runtime.NewScheme()
-	_ = nextdnsv1alpha1.AddToScheme(scheme)
+	scheme := newTestScheme()
 
 	list := &nextdnsv1alpha1.NextDNSDenylist{
 		ObjectMeta: metav1.ObjectMeta{
@@ -224,7 +222,7 @@ func TestNextDNSDenylistReconciler_Reconcile(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
 			Domains: []nextdnsv1alpha1.DomainEntry{
 				{Domain: "example.com"},
-				{Domain: "test.com", Active: boolPtr(false)},
+				{Domain: "test.com", Active: ptr.To(false)},
 			},
 		},
 	}
@@ -293,8 +291,7 @@ func TestNextDNSDenylistReconciler_Reconcile(t *testing.T) {
 }
 
 func TestNextDNSDenylistReconciler_HandleDeletion(t *testing.T) {
-	scheme := runtime.NewScheme()
-	_ = nextdnsv1alpha1.AddToScheme(scheme)
+	scheme := newTestScheme()
 
 	t.Run("deletion blocked when profiles reference list", func(t *testing.T) {
 		now := metav1.Now()
@@ -407,8 +404,7 @@ func TestNextDNSDenylistReconciler_HandleDeletion(t *testing.T) {
 }
 
 func TestNextDNSDenylistReconciler_findDenylistsForProfile(t *testing.T) {
-	scheme := runtime.NewScheme()
-	_ = nextdnsv1alpha1.AddToScheme(scheme)
+	scheme := newTestScheme()
 
 	list1 := &nextdnsv1alpha1.NextDNSDenylist{
 		ObjectMeta: metav1.ObjectMeta{
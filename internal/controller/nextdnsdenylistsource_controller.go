@@ -0,0 +1,377 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+)
+
+const (
+	// DenylistSourceFinalizerName is the finalizer added to NextDNSDenylistSource resources
+	DenylistSourceFinalizerName = "nextdns.io/denylistsource-finalizer"
+
+	// DenylistSourceDomainsKey is the ConfigMap key holding the parsed
+	// domains of a NextDNSDenylistSource, one per line
+	DenylistSourceDomainsKey = "domains"
+
+	// maxDenylistSourceSize caps the parsed domains so they fit in a
+	// ConfigMap, leaving room for its metadata
+	maxDenylistSourceSize = 1000 * 1024
+)
+
+// NextDNSDenylistSourceReconciler reconciles a NextDNSDenylistSource object.
+// It fetches the blocklist, parses it, and stores the domains in a ConfigMap
+// that the profile reconciler reads for denylistRefs of this kind.
+type NextDNSDenylistSourceReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	SyncPeriod time.Duration
+
+	// ListSources caches fetched blocklists; shared with the list controllers
+	ListSources *listsource.Cache
+
+	// ResourceLabels are added to the domains ConfigMap
+	ResourceLabels ResourceLabels
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdenylistsources,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdenylistsources/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdenylistsources/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *NextDNSDenylistSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var source nextdnsv1alpha1.NextDNSDenylistSource
+	if err := r.Get(ctx, req.NamespacedName, &source); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, &source, &source.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Handle deletion
+	if !source.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, &source)
+	}
+
+	// Add finalizer if not present
+	if !controllerutil.ContainsFinalizer(&source, DenylistSourceFinalizerName) {
+		logger.Info("Adding finalizer to NextDNSDenylistSource")
+		controllerutil.AddFinalizer(&source, DenylistSourceFinalizerName)
+		if err := r.Update(ctx, &source); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	profileRefs, err := r.findProfileReferences(ctx, &source)
+	if err != nil {
+		logger.Error(err, "Failed to find profile references")
+		return ctrl.Result{}, err
+	}
+	source.Status.ProfileRefs = profileRefs
+	source.Status.ObservedGeneration = source.Generation
+
+	interval, fetchErr := r.fetch(ctx, &source)
+	if fetchErr != nil {
+		logger.Error(fetchErr, "Failed to fetch blocklist")
+		reason := "FetchFailed"
+		switch {
+		case errors.Is(fetchErr, listsource.ErrVerificationFailed):
+			reason = "SourceVerificationFailed"
+		case errors.Is(fetchErr, errDenylistSourceTooLarge):
+			reason = "ListTooLarge"
+		}
+		r.setReadyCondition(&source, metav1.ConditionFalse, reason, fetchErr.Error())
+	} else {
+		r.setReadyCondition(&source, metav1.ConditionTrue, "Fetched",
+			fmt.Sprintf("Fetched %d domains", source.Status.DomainCount))
+	}
+	setListConditions(&source.Status.Conditions, source.Status.DomainCount, len(profileRefs), "domains")
+
+	if err := r.Status().Update(ctx, &source); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &source); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	if fetchErr != nil {
+		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+	}
+	syncInterval := CalculateSyncInterval(r.SyncPeriod)
+	if interval < syncInterval {
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+	return ctrl.Result{RequeueAfter: syncInterval}, nil
+}
+
+// errDenylistSourceTooLarge is returned when the parsed domains do not fit
+// in a ConfigMap
+var errDenylistSourceTooLarge = errors.New("parsed blocklist exceeds the ConfigMap size limit")
+
+// fetch fetches and parses the blocklist and stores the domains in the
+// source's ConfigMap, recording the fetch in status. The ConfigMap keeps the
+// previous domains when the fetch fails. A resync request fetches the
+// blocklist regardless of the interval. It returns the fetch interval.
+func (r *NextDNSDenylistSourceReconciler) fetch(ctx context.Context, source *nextdnsv1alpha1.NextDNSDenylistSource) (time.Duration, error) {
+	spec := nextdnsv1alpha1.ListSource{
+		HTTP:     &nextdnsv1alpha1.HTTPListSource{URL: source.Spec.URL},
+		Cosign:   source.Spec.Cosign,
+		Interval: source.Spec.Interval,
+	}
+	interval, err := listsource.Interval(spec)
+	if err != nil {
+		return listsource.DefaultInterval, err
+	}
+	src, err := listsource.New(spec)
+	if err != nil {
+		return interval, err
+	}
+
+	cache := r.ListSources
+	if cache == nil {
+		cache = listsource.NewCache()
+	}
+	status := &nextdnsv1alpha1.ListSourceStatus{Source: src.Key()}
+	if source.Status.Source != nil && source.Status.Source.Source == status.Source {
+		status = source.Status.Source
+	}
+	source.Status.Source = status
+
+	maxAge := interval
+	if resyncRequested(source) {
+		maxAge = 0
+	}
+	fetched, fetchErr := cache.Get(ctx, src, "", maxAge)
+	if fetchErr != nil {
+		status.Error = fetchErr.Error()
+	}
+	if fetched == nil {
+		return interval, fetchErr
+	}
+
+	domains := parseDenylistSource(&source.Spec, fetched.Data)
+	data := strings.Join(domains, "\n")
+	if len(data) > maxDenylistSourceSize {
+		err := fmt.Errorf("%w: %d domains, %d bytes", errDenylistSourceTooLarge, len(domains), len(data))
+		status.Error = err.Error()
+		return interval, err
+	}
+
+	configMapName, err := r.reconcileDomainsConfigMap(ctx, source, data)
+	if err != nil {
+		err = fmt.Errorf("failed to store domains: %w", err)
+		status.Error = err.Error()
+		return interval, err
+	}
+
+	source.Status.ConfigMapName = configMapName
+	source.Status.DomainCount = len(domains)
+	status.Revision = fetched.Revision
+	status.Digest = fetched.Digest
+	status.Count = len(domains)
+	status.LastFetchTime = &metav1.Time{Time: fetched.FetchedAt}
+	if fetchErr == nil {
+		status.Error = ""
+	}
+	return interval, fetchErr
+}
+
+// parseDenylistSource returns the domains of a fetched blocklist in the
+// spec's format, without duplicates and excluded domains
+func parseDenylistSource(spec *nextdnsv1alpha1.NextDNSDenylistSourceSpec, data []byte) []string {
+	var entries []string
+	if spec.Format == nextdnsv1alpha1.DenylistSourceFormatAdblock {
+		entries = listsource.ParseAdblock(data)
+	} else {
+		entries = listsource.Parse(data)
+	}
+
+	excluded := make(map[string]bool, len(spec.Exclude))
+	for _, domain := range spec.Exclude {
+		excluded[strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")] = true
+	}
+
+	domains := make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if seen[entry] || isExcludedDomain(entry, excluded) {
+			continue
+		}
+		seen[entry] = true
+		domains = append(domains, entry)
+	}
+	return domains
+}
+
+// isExcludedDomain reports whether domain or one of its parent domains is
+// excluded
+func isExcludedDomain(domain string, excluded map[string]bool) bool {
+	if len(excluded) == 0 {
+		return false
+	}
+	for {
+		if excluded[domain] {
+			return true
+		}
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return false
+		}
+		domain = domain[i+1:]
+	}
+}
+
+// reconcileDomainsConfigMap stores the parsed domains in the ConfigMap owned
+// by source and returns its name
+func (r *NextDNSDenylistSourceReconciler) reconcileDomainsConfigMap(ctx context.Context, source *nextdnsv1alpha1.NextDNSDenylistSource, data string) (string, error) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name + "-domains",
+			Namespace: source.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		r.ResourceLabels.set(configMap)
+		configMap.Data = map[string]string{DenylistSourceDomainsKey: data}
+		return ctrl.SetControllerReference(source, configMap, r.Scheme)
+	})
+	if err != nil {
+		return "", err
+	}
+	return configMap.Name, nil
+}
+
+// setReadyCondition sets the Ready condition of a denylist source, emitting
+// an event when it changes
+func (r *NextDNSDenylistSourceReconciler) setReadyCondition(source *nextdnsv1alpha1.NextDNSDenylistSource, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               ConditionTypeReady,
+		Status:             status,
+		ObservedGeneration: source.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	recordConditionEvent(r.Recorder, source, source.Status.Conditions, condition)
+	meta.SetStatusCondition(&source.Status.Conditions, condition)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NextDNSDenylistSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.ListSources == nil {
+		r.ListSources = listsource.NewCache()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSDenylistSource{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSDenylistSourceList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		Owns(&corev1.ConfigMap{}).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.findDenylistSourcesForProfile),
+		).
+		WithOptions(controllerOptions("nextdnsdenylistsource", r.Workqueue)).
+		Complete(r)
+}
+
+// findDenylistSourcesForProfile returns reconcile requests for all denylist sources referenced by a profile
+func (r *NextDNSDenylistSourceReconciler) findDenylistSourcesForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ref := range allListRefs(&profile.Spec, denylistSourceRefs) {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = profile.Namespace
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      ref.Name,
+				Namespace: namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// findProfileReferences finds all profiles that reference this denylist source.
+// Note: Searches cluster-wide to support cross-namespace references.
+func (r *NextDNSDenylistSourceReconciler) findProfileReferences(ctx context.Context, source *nextdnsv1alpha1.NextDNSDenylistSource) ([]nextdnsv1alpha1.ResourceReference, error) {
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &profiles); err != nil {
+		return nil, err
+	}
+
+	return findRefsForList(profiles.Items, source.Name, source.Namespace, denylistSourceRefs), nil
+}
+
+// handleDeletion handles the deletion of a denylist source
+func (r *NextDNSDenylistSourceReconciler) handleDeletion(ctx context.Context, source *nextdnsv1alpha1.NextDNSDenylistSource) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Check if any profiles reference this source
+	if len(source.Status.ProfileRefs) > 0 {
+		logger.Info("Deletion blocked - denylist source is in use", "profileRefs", source.Status.ProfileRefs)
+
+		setDeletionBlockedCondition(r.Recorder, source, &source.Status.Conditions, source.Status.ProfileRefs)
+
+		// Update status and requeue
+		if err := r.Status().Update(ctx, source); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// No references - safe to delete; the ConfigMap is garbage collected
+	logger.Info("Removing finalizer from NextDNSDenylistSource")
+	controllerutil.RemoveFinalizer(source, DenylistSourceFinalizerName)
+	if err := r.Update(ctx, source); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
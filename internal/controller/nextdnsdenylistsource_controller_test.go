@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestNextDNSDenylistSourceReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	server := newListSourceServer(t, map[string]string{
+		"/hosts": "# StevenBlack\n127.0.0.1 localhost\n0.0.0.0 ads.example.com\n0.0.0.0 ads.example.com\n" +
+			"0.0.0.0 cdn.allowed.example\n0.0.0.0 tracker.example.net\n",
+	})
+
+	source := &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "hosts",
+			Namespace:  "default",
+			Finalizers: []string{DenylistSourceFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSourceSpec{
+			URL:      server.URL + "/hosts",
+			Interval: "6h",
+			Exclude:  []string{"allowed.example"},
+		},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "hosts"},
+				{Name: "hosts", Kind: nextdnsv1alpha1.KindDenylistSource},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(source, profile).
+		WithStatusSubresource(source).
+		Build()
+	r := &NextDNSDenylistSourceReconciler{
+		Client:         fakeClient,
+		Scheme:         scheme,
+		SyncPeriod:     24 * time.Hour,
+		ListSources:    listsource.NewCache(),
+		ResourceLabels: ResourceLabels{"team": "platform"},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hosts", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, result.RequeueAfter, "requeued for the next fetch")
+
+	var updated nextdnsv1alpha1.NextDNSDenylistSource
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, 2, updated.Status.DomainCount, "duplicates and excluded subdomains are dropped")
+	assert.Equal(t, "hosts-domains", updated.Status.ConfigMapName)
+	require.NotNil(t, updated.Status.Source)
+	assert.Equal(t, server.URL+"/hosts", updated.Status.Source.Source)
+	assert.NotEmpty(t, updated.Status.Source.Digest)
+	assert.NotNil(t, updated.Status.Source.LastFetchTime)
+	assert.Equal(t, []nextdnsv1alpha1.ResourceReference{{Name: "profile", Namespace: "default"}}, updated.Status.ProfileRefs,
+		"only references of kind NextDNSDenylistSource count")
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeReady))
+
+	var configMap corev1.ConfigMap
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "hosts-domains", Namespace: "default"}, &configMap))
+	assert.Equal(t, "ads.example.com\ntracker.example.net", configMap.Data[DenylistSourceDomainsKey])
+	assert.Equal(t, "platform", configMap.Labels["team"])
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, "hosts", configMap.OwnerReferences[0].Name)
+}
+
+func TestNextDNSDenylistSourceReconciler_Reconcile_FetchFailed(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	server := newListSourceServer(t, map[string]string{})
+
+	source := &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "missing",
+			Namespace:  "default",
+			Finalizers: []string{DenylistSourceFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSourceSpec{URL: server.URL + "/missing"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(source).
+		WithStatusSubresource(source).
+		Build()
+	r := &NextDNSDenylistSourceReconciler{Client: fakeClient, Scheme: scheme, SyncPeriod: time.Hour}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "missing", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSDenylistSource
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Empty(t, updated.Status.ConfigMapName)
+	require.NotNil(t, updated.Status.Source)
+	assert.NotEmpty(t, updated.Status.Source.Error)
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "FetchFailed", cond.Reason)
+}
+
+func TestParseDenylistSource(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSDenylistSourceSpec{
+		Format:  nextdnsv1alpha1.DenylistSourceFormatAdblock,
+		Exclude: []string{" .Example.org "},
+	}
+	data := []byte("! OISD\n||ads.example.com^\n||example.org^\n||cdn.example.org^\n||ads.example.com^$important\n")
+	assert.Equal(t, []string{"ads.example.com"}, parseDenylistSource(spec, data))
+}
+
+func TestResolveListReferences_DenylistSource(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	source := &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: "lists"},
+		Status:     nextdnsv1alpha1.NextDNSDenylistSourceStatus{ConfigMapName: "feed-domains"},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed-domains", Namespace: "lists"},
+		Data:       map[string]string{DenylistSourceDomainsKey: "ads.example.com\ntracker.example.net"},
+	}
+	unfetched := &nextdnsv1alpha1.NextDNSDenylistSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "unfetched", Namespace: "default"},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, configMap, unfetched).Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme, listCache: newListCache()}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name: "profile",
+			DenylistRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "feed", Namespace: "lists", Kind: nextdnsv1alpha1.KindDenylistSource},
+			},
+			Denylist: []nextdnsv1alpha1.DomainEntry{{Domain: "inline.example.com"}},
+		},
+	}
+
+	resolved, err := reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []nextdns.DomainEntry{
+		{Domain: "ads.example.com", Active: true},
+		{Domain: "tracker.example.net", Active: true},
+		{Domain: "inline.example.com", Active: true},
+	}, resolved.Denylist)
+	require.Len(t, resolved.ResourceStatus.Denylists, 1)
+	assert.Equal(t, 2, resolved.ResourceStatus.Denylists[0].Count)
+
+	// A source that was never fetched fails the resolution
+	profile.Spec.DenylistRefs = []nextdnsv1alpha1.ListReference{{Name: "unfetched", Kind: nextdnsv1alpha1.KindDenylistSource}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	assert.ErrorContains(t, err, "has not been fetched yet")
+
+	// Other reference fields do not accept a kind
+	profile.Spec.DenylistRefs = nil
+	profile.Spec.AllowlistRefs = []nextdnsv1alpha1.ListReference{{Name: "feed", Kind: nextdnsv1alpha1.KindDenylistSource}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	assert.ErrorContains(t, err, "allowlistRefs cannot reference kind NextDNSDenylistSource")
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+// NextDNSDeviceReconciler reconciles a NextDNSDevice object. NextDNS
+// identifies a device by the name embedded in the endpoint it queries, so
+// the reconciler only derives the device-specific endpoints from the
+// referenced profile; nothing is written to the NextDNS API.
+type NextDNSDeviceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdevices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdevices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *NextDNSDeviceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var device nextdnsv1alpha1.NextDNSDevice
+	if err := r.Get(ctx, req.NamespacedName, &device); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if paused, err := reconcilePaused(ctx, r.Client, &device, &device.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	deviceName := device.Spec.DeviceName
+	if deviceName == "" {
+		deviceName = device.Name
+	}
+
+	profileID, reason, err := r.resolveProfileID(ctx, &device)
+	if err != nil {
+		logger.Info("Referenced profile is not available", "reason", reason, "error", err.Error())
+		device.Status.ProfileID = ""
+		device.Status.DeviceName = deviceName
+		device.Status.DoTHostname = ""
+		device.Status.DoHURL = ""
+		device.Status.ObservedGeneration = device.Generation
+		r.setCondition(&device, metav1.ConditionFalse, reason, err.Error())
+		if updateErr := r.Status().Update(ctx, &device); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	device.Status.ProfileID = profileID
+	device.Status.DeviceName = deviceName
+	device.Status.DoTHostname = coredns.DeviceDoTHostname(profileID, deviceName)
+	device.Status.DoHURL = coredns.DeviceDoHURL(profileID, deviceName)
+	device.Status.ObservedGeneration = device.Generation
+	r.setCondition(&device, metav1.ConditionTrue, "EndpointsAvailable",
+		fmt.Sprintf("Device endpoints available for profile %s", profileID))
+
+	if err := r.Status().Update(ctx, &device); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &device); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// resolveProfileID returns the NextDNS profile ID of the referenced profile,
+// or the condition reason explaining why it is not available
+func (r *NextDNSDeviceReconciler) resolveProfileID(ctx context.Context, device *nextdnsv1alpha1.NextDNSDevice) (string, string, error) {
+	profile, err := profileresolver.Resolve(ctx, r.Client, device.Spec.ProfileRef, device.Namespace)
+	if apierrors.IsNotFound(err) {
+		return "", "ProfileNotFound", fmt.Errorf("NextDNSProfile %s not found", profileresolver.Key(device.Spec.ProfileRef, device.Namespace))
+	}
+	if err != nil {
+		return "", "ProfileNotFound", err
+	}
+
+	if profile.Status.ProfileID == "" {
+		return "", "ProfileNotReady", fmt.Errorf("NextDNSProfile %s/%s has no profile ID yet", profile.Namespace, profile.Name)
+	}
+
+	return profile.Status.ProfileID, "", nil
+}
+
+// setCondition sets the Ready condition of a device, emitting an event when
+// it changes
+func (r *NextDNSDeviceReconciler) setCondition(device *nextdnsv1alpha1.NextDNSDevice, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               ConditionTypeReady,
+		Status:             status,
+		ObservedGeneration: device.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	recordConditionEvent(r.Recorder, device, device.Status.Conditions, condition)
+	meta.SetStatusCondition(&device.Status.Conditions, condition)
+}
+
+// profileIDChangedPredicate filters NextDNSProfile updates down to changes
+// of the NextDNS profile ID, the only profile field devices consume
+func profileIDChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldProfile, ok := e.ObjectOld.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			newProfile, ok := e.ObjectNew.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			return oldProfile.Status.ProfileID != newProfile.Status.ProfileID
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *NextDNSDeviceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSDevice{}, profileresolver.IndexField, deviceProfileRefIndexFunc); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSDevice{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSDeviceList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			profileresolver.EnqueueReferencing(mgr.GetClient(), &nextdnsv1alpha1.NextDNSDeviceList{}),
+			ctrlbuilder.WithPredicates(profileIDChangedPredicate()),
+		).
+		WithOptions(controllerOptions("nextdnsdevice", r.Workqueue)).
+		Complete(r)
+}
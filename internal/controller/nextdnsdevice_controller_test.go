@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+func TestNextDNSDeviceReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	device := &nextdnsv1alpha1.NextDNSDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "tv", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDeviceSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"},
+			DeviceName: "Living Room TV",
+		},
+	}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(device, profile).
+		WithStatusSubresource(device, profile).
+		WithIndex(&nextdnsv1alpha1.NextDNSDevice{}, profileresolver.IndexField, deviceProfileRefIndexFunc).
+		Build()
+	r := &NextDNSDeviceReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tv", Namespace: "default"}}
+
+	// The profile has not been created in NextDNS yet
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSDevice
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "ProfileNotReady", cond.Reason)
+	assert.Empty(t, updated.Status.DoTHostname)
+
+	profile.Status.ProfileID = "abc123"
+	require.NoError(t, fakeClient.Status().Update(ctx, profile))
+	assert.Len(t, profileresolver.Referencing(ctx, fakeClient, &nextdnsv1alpha1.NextDNSDeviceList{}, profile), 1)
+
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, "abc123", updated.Status.ProfileID)
+	assert.Equal(t, "Living Room TV", updated.Status.DeviceName)
+	assert.Equal(t, "Living--Room--TV-abc123.dns.nextdns.io", updated.Status.DoTHostname)
+	assert.Equal(t, "https://dns.nextdns.io/abc123/Living%20Room%20TV", updated.Status.DoHURL)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeReady))
+}
+
+func TestNextDNSDeviceReconciler_Reconcile_ProfileNotFound(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	device := &nextdnsv1alpha1.NextDNSDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "laptop", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDeviceSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "missing", Namespace: "dns"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(device).
+		WithStatusSubresource(device).
+		Build()
+	r := &NextDNSDeviceReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "laptop", Namespace: "default"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSDevice
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, "ProfileNotFound", cond.Reason)
+	assert.Contains(t, cond.Message, "dns/missing")
+	assert.Equal(t, "laptop", updated.Status.DeviceName, "defaults to the resource name")
+}
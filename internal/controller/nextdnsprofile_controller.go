@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,16 +15,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
 	"github.com/jacaudi/nextdns-operator/internal/metrics"
 	"github.com/jacaudi/nextdns-operator/internal/nextdns"
 )
@@ -43,27 +50,12 @@ const (
 
 	// ConditionTypeObserveOnly indicates the profile is in observe-only mode
 	ConditionTypeObserveOnly = "ObserveOnly"
-)
 
-const (
-	// credentialsRefIndexField is the field index key for looking up profiles by their secret reference
-	credentialsRefIndexField = ".spec.credentialsRef"
+	// ConditionTypeCredentialsValid indicates NextDNS accepts the profile's
+	// API key
+	ConditionTypeCredentialsValid = "CredentialsValid"
 )
 
-// credentialsRefIndexFunc extracts the secret reference key (namespace/name) from a NextDNSProfile
-// for use with controller-runtime's field indexer. This enables efficient lookups when a Secret changes.
-func credentialsRefIndexFunc(obj client.Object) []string {
-	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
-	if !ok {
-		return nil
-	}
-	ns := profile.Spec.CredentialsRef.Namespace
-	if ns == "" {
-		ns = profile.Namespace
-	}
-	return []string{ns + "/" + profile.Spec.CredentialsRef.Name}
-}
-
 // ClientFactory is a function that creates a NextDNS client
 type ClientFactory func(apiKey string) (nextdns.ClientInterface, error)
 
@@ -79,14 +71,51 @@ type NextDNSProfileReconciler struct {
 	ClientFactory     ClientFactory
 	SyncPeriod        time.Duration
 	lastMetricsUpdate time.Time
+
+	// FanOutWindow spreads the reconciles triggered by a shared list change
+	// over this period. Zero enqueues them all immediately.
+	FanOutWindow time.Duration
+
+	// ListSources caches the fetched spec.sources of referenced lists;
+	// share it with the list reconcilers so each source is fetched once
+	ListSources *listsource.Cache
+
+	// ResourceLabels are added to the ConfigMaps created for a profile
+	ResourceLabels ResourceLabels
+
+	// SectionConcurrency is the number of profile sections written to
+	// NextDNS at once during a sync; 0 means DefaultSectionConcurrency
+	SectionConcurrency int
+
+	// CompatibilityLevel is the behavior version applied to profiles without
+	// the nextdns.io/compatibility-level annotation; 0 means
+	// DefaultCompatibilityLevel
+	CompatibilityLevel int
+
+	// Metrics records profile syncs and resource counts; metrics.Default()
+	// is used when nil
+	Metrics *metrics.Metrics
+
+	// listCache shares resolved list references between profiles; set up by
+	// SetupWithManager
+	listCache *listCache
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
 }
 
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles/finalizers,verbs=update
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsaccounts,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsallowlists,verbs=get;list;watch
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsdenylists,verbs=get;list;watch
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnstldlists,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsrewrites,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
@@ -124,6 +153,11 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: time.Second}, nil
 	}
 
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, profile, &profile.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check if the resource is being deleted
 	if !profile.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, profile)
@@ -140,11 +174,21 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// Get API credentials
-	apiKey, err := r.getAPIKey(ctx, profile)
+	setCredentialsStatus(ctx, r.Client, profile)
+	apiKey, secretVersion, err := r.getAPIKey(ctx, profile)
 	if err != nil {
 		logger.Error(err, "Failed to get API credentials")
-		metrics.RecordProfileSyncError(profile.Name, profile.Namespace, "CredentialsNotFound")
-		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "CredentialsNotFound", err.Error())
+		reason := "CredentialsNotFound"
+		switch {
+		case errors.Is(err, errCredentialsAccessDenied):
+			reason = ReasonCrossNamespaceAccessDenied
+		case errors.Is(err, errConflictingCredentials):
+			reason = "ConflictingCredentials"
+		}
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, reason)
+		profile.Status.CredentialsSecretVersion = ""
+		r.setCondition(profile, ConditionTypeCredentialsValid, metav1.ConditionFalse, reason, err.Error())
+		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
 		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
 			logger.Error(updateErr, "Failed to update status")
 		}
@@ -159,6 +203,9 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Observe mode: read-only reconciliation
 	if mode == nextdnsv1alpha1.ProfileModeObserve {
+		if rejected := r.validateCredentials(ctx, profile, apiKey, secretVersion); rejected {
+			return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+		}
 		return r.reconcileObserveMode(ctx, profile, apiKey)
 	}
 
@@ -172,6 +219,53 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Adopting an existing profile requires an explicit adoption policy so
+	// the first sync cannot silently replace a hand-curated configuration
+	if adoptionPolicyMissing(profile) {
+		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "AdoptionPolicyRequired",
+			"spec.adoptionPolicy (Overwrite, MergeOnce or ObserveFirst) is required to adopt an existing profile")
+		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// Check the API key before talking to the profile when the Secret
+	// changed since NextDNS last accepted it
+	if rejected := r.validateCredentials(ctx, profile, apiKey, secretVersion); rejected {
+		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+	}
+
+	// ObserveFirst reads the remote profile for review and holds back writes
+	if observeBeforeAdoption(profile) {
+		return r.reconcileObserveMode(ctx, profile, apiKey)
+	}
+
+	// Import the remote configuration into the spec before the first sync
+	if importPending(profile) {
+		return r.importRemoteConfig(ctx, profile, apiKey)
+	}
+
+	// Merge the active overlay into the in-memory spec so the whole sync
+	// applies it at once. The spec is not written back after this point.
+	merged, err := effectiveSpec(&profile.Spec)
+	if err != nil {
+		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "OverlayNotFound", err.Error())
+		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	profile.Spec = *merged
+
+	// Apply the behavior of the profile's compatibility level to the
+	// effective spec
+	compatibilityLevel, err := resourceCompatibilityLevel(profile, r.CompatibilityLevel)
+	if err != nil {
+		logger.Error(err, "Invalid compatibility level override")
+	}
+	applyCompatibilityLevel(&profile.Spec, compatibilityLevel)
+
 	// Transition guard: block if switching from observe to managed with empty spec
 	if profile.Status.ObservedConfig != nil && !specHasConfig(&profile.Spec) {
 		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "TransitionBlocked",
@@ -194,8 +288,12 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	resolvedLists, err := r.resolveListReferences(ctx, profile)
 	if err != nil {
 		logger.Error(err, "Failed to resolve list references")
-		metrics.RecordProfileSyncError(profile.Name, profile.Namespace, "ReferencesNotResolved")
-		r.setCondition(profile, ConditionTypeReferencesResolved, metav1.ConditionFalse, "ResolutionFailed", err.Error())
+		reason := listReferenceErrorReason(err)
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, "ReferencesNotResolved")
+		if reason == ReasonCrossNamespaceAccessDenied {
+			r.metrics().RecordCrossNamespaceAccessDenied(profile.Name, profile.Namespace)
+		}
+		r.setCondition(profile, ConditionTypeReferencesResolved, metav1.ConditionFalse, reason, err.Error())
 		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "ReferencesNotResolved", "Failed to resolve list references")
 		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
 			logger.Error(updateErr, "Failed to update status")
@@ -206,10 +304,22 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	// Mark references as resolved
 	r.setCondition(profile, ConditionTypeReferencesResolved, metav1.ConditionTrue, "AllResolved", "All referenced lists found and valid")
 
+	// Capture status snapshot before sync, which records drift in status
+	statusBefore := profile.Status.DeepCopy()
+	profile.Status.AccountFingerprint = nextdns.AccountFingerprint(apiKey)
+
+	// A break-glass window suspends blocking in the effective spec; the first
+	// sync after it ends applies the spec again
+	breakGlassRemaining, breakGlassExpired := r.reconcileBreakGlass(profile, time.Now())
+	if breakGlassRemaining > 0 {
+		suspendBlocking(&profile.Spec, resolvedLists)
+	}
+
 	// Sync with NextDNS API
 	if err := r.syncWithNextDNS(ctx, profile, apiKey, resolvedLists); err != nil {
 		logger.Error(err, "Failed to sync with NextDNS")
-		metrics.RecordProfileSyncError(profile.Name, profile.Namespace, "SyncFailed")
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, "SyncFailed")
+		r.recordRejectedCredentials(profile, err)
 		r.setCondition(profile, ConditionTypeSynced, metav1.ConditionFalse, "SyncFailed", err.Error())
 		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "SyncFailed", "Failed to sync with NextDNS API")
 		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
@@ -218,11 +328,8 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
 	}
 
-	// Capture status snapshot before updates
-	statusBefore := profile.Status.DeepCopy()
-
 	// Record successful sync
-	metrics.RecordProfileSync(profile.Name, profile.Namespace)
+	r.metrics().RecordProfileSync(profile.Name, profile.Namespace)
 
 	// Update status fields
 	profile.Status.ObservedGeneration = profile.Generation
@@ -230,8 +337,11 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		AllowlistDomains: len(resolvedLists.Allowlist),
 		DenylistDomains:  len(resolvedLists.Denylist),
 		BlockedTLDs:      len(resolvedLists.TLDs),
+		Rewrites:         len(resolvedLists.Rewrites),
 	}
 	profile.Status.ReferencedResources = resolvedLists.ResourceStatus
+	profile.Status.ActiveOverlay = profile.Spec.ActiveOverlay
+	profile.Status.CompatibilityLevel = compatibilityLevel
 
 	r.setCondition(profile, ConditionTypeSynced, metav1.ConditionTrue, "Success", "All settings applied")
 	r.setCondition(profile, ConditionTypeReady, metav1.ConditionTrue, "Synced", "Profile successfully synced with NextDNS")
@@ -242,6 +352,17 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// Don't fail the reconciliation for ConfigMap errors, just log
 	}
 
+	// Export the effective configuration if enabled
+	if err := r.reconcileEffectiveConfigMap(ctx, profile, resolvedLists); err != nil {
+		logger.Error(err, "Failed to reconcile effective config ConfigMap")
+		// Don't fail the reconciliation for ConfigMap errors, just log
+	}
+
+	// Deploy the NextDNSCoreDNS bound to the profile if enabled
+	if err := r.reconcileDeployedCoreDNS(ctx, profile); err != nil {
+		logger.Error(err, "Failed to reconcile deployed NextDNSCoreDNS")
+	}
+
 	// Populate setup data (informational, non-critical)
 	{
 		factory := r.ClientFactory
@@ -262,11 +383,23 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		!apiequality.Semantic.DeepEqual(statusBefore.ReferencedResources, profile.Status.ReferencedResources) ||
 		!apiequality.Semantic.DeepEqual(statusBefore.Conditions, profile.Status.Conditions) ||
 		!apiequality.Semantic.DeepEqual(statusBefore.Setup, profile.Status.Setup) ||
+		!apiequality.Semantic.DeepEqual(statusBefore.DriftSummary, profile.Status.DriftSummary) ||
+		!apiequality.Semantic.DeepEqual(statusBefore.Sections, profile.Status.Sections) ||
+		!apiequality.Semantic.DeepEqual(statusBefore.BreakGlass, profile.Status.BreakGlass) ||
+		!apiequality.Semantic.DeepEqual(statusBefore.ManagedEntries, profile.Status.ManagedEntries) ||
+		!apiequality.Semantic.DeepEqual(statusBefore.Rewrites, profile.Status.Rewrites) ||
+		statusBefore.AppliedConfigHash != profile.Status.AppliedConfigHash ||
+		statusBefore.ActiveOverlay != profile.Status.ActiveOverlay ||
+		statusBefore.CompatibilityLevel != profile.Status.CompatibilityLevel ||
+		statusBefore.EffectiveConfigMap != profile.Status.EffectiveConfigMap ||
+		statusBefore.DeployedCoreDNS != profile.Status.DeployedCoreDNS ||
 		statusBefore.ProfileID != profile.Status.ProfileID ||
 		statusBefore.Fingerprint != profile.Status.Fingerprint ||
+		statusBefore.AccountFingerprint != profile.Status.AccountFingerprint ||
 		statusBefore.ObservedGeneration != profile.Status.ObservedGeneration
 
-	if statusChanged || profile.Status.LastSyncTime == nil {
+	// A resync request records the sync even when nothing changed
+	if statusChanged || resyncRequested(profile) || profile.Status.LastSyncTime == nil {
 		now := metav1.Now()
 		profile.Status.LastSyncTime = &now
 
@@ -285,12 +418,34 @@ func (r *NextDNSProfileReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			"profileID", profile.Status.ProfileID)
 	}
 
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, profile); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	// Remove the annotation of an expired break-glass window now that the
+	// spec was applied again
+	if breakGlassExpired {
+		if err := clearAnnotation(ctx, r.Client, profile, AnnotationBreakGlass); err != nil {
+			logger.Error(err, "Failed to clear expired break-glass annotation")
+		}
+	}
+
 	// Schedule next sync with jitter for drift detection
-	syncInterval := CalculateSyncInterval(r.SyncPeriod)
+	syncPeriod, err := resourceSyncPeriod(profile, profile.Spec.SyncInterval, r.SyncPeriod)
+	if err != nil {
+		logger.Error(err, "Invalid sync interval override")
+	}
+	syncInterval := CalculateSyncInterval(syncPeriod)
 	if syncInterval > 0 {
 		logger.V(1).Info("Scheduling next drift detection sync", "interval", syncInterval)
 	}
 
+	// Restore blocking as soon as the break-glass window expires
+	if breakGlassRemaining > 0 && (syncInterval == 0 || breakGlassRemaining < syncInterval) {
+		syncInterval = breakGlassRemaining
+	}
+
 	return ctrl.Result{RequeueAfter: syncInterval}, nil
 }
 
@@ -301,35 +456,77 @@ func (r *NextDNSProfileReconciler) handleDeletion(ctx context.Context, profile *
 	if controllerutil.ContainsFinalizer(profile, FinalizerName) {
 		logger.Info("Handling deletion of NextDNSProfile")
 
-		// Only delete the profile from NextDNS if we created it (no profileID was specified in spec)
-		// and we have a profile ID in status
-		if profile.Spec.Mode == nextdnsv1alpha1.ProfileModeObserve {
+		policy := deletionPolicy(profile)
+		// orphaned is set when the finalizer is removed with the Delete
+		// policy but the profile is left in NextDNS
+		orphaned := false
+		switch {
+		case profile.Spec.Mode == nextdnsv1alpha1.ProfileModeObserve:
 			logger.Info("Skipping NextDNS profile deletion (observe mode, profile not owned)", "profileID", profile.Status.ProfileID)
-		} else if profile.Spec.ProfileID == "" && profile.Status.ProfileID != "" {
+		case profile.Status.ProfileID == "":
+			// The profile was never created or adopted; nothing to clean up
+		case policy == nextdnsv1alpha1.DeletionPolicyOrphan:
+			logger.Info("Skipping NextDNS profile deletion (deletionPolicy Orphan)", "profileID", profile.Status.ProfileID)
+			recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonProfileRetained,
+				"Orphaned NextDNS profile %s", profile.Status.ProfileID)
+		default:
 			// Get API credentials
-			apiKey, err := r.getAPIKey(ctx, profile)
+			apiKey, _, err := r.getAPIKey(ctx, profile)
 			if err != nil {
 				logger.Error(err, "Failed to get API credentials for deletion, proceeding with finalizer removal")
-			} else {
-				// Create NextDNS client using factory
-				factory := r.ClientFactory
-				if factory == nil {
-					factory = DefaultClientFactory
-				}
-				client, err := factory(apiKey)
-				if err != nil {
-					logger.Error(err, "Failed to create NextDNS client for deletion")
+				orphaned = policy == nextdnsv1alpha1.DeletionPolicyDelete
+				break
+			}
+			// Create NextDNS client using factory
+			factory := r.ClientFactory
+			if factory == nil {
+				factory = DefaultClientFactory
+			}
+			client, err := factory(apiKey)
+			if err != nil {
+				logger.Error(err, "Failed to create NextDNS client for deletion")
+				orphaned = policy == nextdnsv1alpha1.DeletionPolicyDelete
+				break
+			}
+			// Continue with finalizer removal even if the cleanup fails
+			if policy == nextdnsv1alpha1.DeletionPolicyRetain {
+				if err := r.stripManagedLists(ctx, client, profile); err != nil {
+					logger.Error(err, "Failed to remove managed lists from NextDNS profile", "profileID", profile.Status.ProfileID)
+					recordEvent(r.Recorder, profile, corev1.EventTypeWarning, EventReasonProfileDeleteFailed,
+						"Failed to remove managed lists from NextDNS profile %s: %v", profile.Status.ProfileID, err)
 				} else {
-					if err := client.DeleteProfile(ctx, profile.Status.ProfileID); err != nil {
-						logger.Error(err, "Failed to delete profile from NextDNS", "profileID", profile.Status.ProfileID)
-						// Continue with finalizer removal even if deletion fails
-					} else {
-						logger.Info("Deleted NextDNS profile", "profileID", profile.Status.ProfileID)
+					logger.Info("Retained NextDNS profile without managed lists", "profileID", profile.Status.ProfileID)
+					recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonProfileRetained,
+						"Retained NextDNS profile %s without managed lists", profile.Status.ProfileID)
+				}
+			} else if err := client.DeleteProfile(ctx, profile.Status.ProfileID); err != nil {
+				logger.Error(err, "Failed to delete profile from NextDNS", "profileID", profile.Status.ProfileID)
+				recordEvent(r.Recorder, profile, corev1.EventTypeWarning, EventReasonProfileDeleteFailed,
+					"Failed to delete NextDNS profile %s: %v", profile.Status.ProfileID, err)
+				// Removing the finalizer would orphan the profile in NextDNS;
+				// keep it until the key is fixed
+				if nextdns.IsAuthError(err) {
+					r.recordRejectedCredentials(profile, err)
+					r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, ReasonInvalidCredentials,
+						fmt.Sprintf("NextDNS rejected the API key in secret %s; the profile is not deleted until it is fixed", profile.Status.CredentialsSecret))
+					if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
+						logger.Error(updateErr, "Failed to update status")
 					}
+					return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
 				}
+				orphaned = !nextdns.IsNotFoundError(err)
+			} else {
+				logger.Info("Deleted NextDNS profile", "profileID", profile.Status.ProfileID)
+				recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonProfileDeleted,
+					"Deleted NextDNS profile %s", profile.Status.ProfileID)
+			}
+		}
+
+		// Let the account orphan GC find the profile left behind
+		if orphaned {
+			if err := recordOrphanedProfile(ctx, r.Client, profile); err != nil {
+				return ctrl.Result{}, err
 			}
-		} else if profile.Spec.ProfileID != "" {
-			logger.Info("Skipping NextDNS profile deletion (profile was adopted, not created)", "profileID", profile.Status.ProfileID)
 		}
 
 		// Remove finalizer
@@ -342,45 +539,93 @@ func (r *NextDNSProfileReconciler) handleDeletion(ctx context.Context, profile *
 	return ctrl.Result{}, nil
 }
 
-// getAPIKey retrieves the NextDNS API key from the referenced Secret
-func (r *NextDNSProfileReconciler) getAPIKey(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile) (string, error) {
-	secretName := profile.Spec.CredentialsRef.Name
-	secretKey := profile.Spec.CredentialsRef.Key
-	if secretKey == "" {
-		secretKey = "api-key"
+// getAPIKey retrieves the NextDNS API key from the profile's credentials
+// Secret, along with the Secret's resourceVersion
+func (r *NextDNSProfileReconciler) getAPIKey(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile) (string, string, error) {
+	return readCredentials(ctx, r.Client, profile)
+}
+
+// profileAPIKey reads the API key of profile from spec.credentialsRef, the
+// NextDNSAccount in spec.accountRef or, when neither is set, the default
+// credentials Secret. A reference to another namespace is subject to the
+// cross-namespace credentials policy.
+func profileAPIKey(ctx context.Context, c client.Reader, profile *nextdnsv1alpha1.NextDNSProfile) (string, error) {
+	apiKey, _, err := readCredentials(ctx, c, profile)
+	return apiKey, err
+}
+
+// readCredentials reads the API key of profile like profileAPIKey and also
+// returns the resourceVersion of the Secret holding it
+func readCredentials(ctx context.Context, c client.Reader, profile *nextdnsv1alpha1.NextDNSProfile) (string, string, error) {
+	ref, _, from, err := resolveCredentials(ctx, c, profile)
+	if err != nil {
+		return "", "", err
 	}
+	return readAPIKey(ctx, c, ref, from)
+}
 
-	// Use credentialsRef.namespace if set, otherwise default to the profile's namespace
-	secretNamespace := profile.Spec.CredentialsRef.Namespace
-	if secretNamespace == "" {
-		secretNamespace = profile.Namespace
+// readAPIKey reads the API key in ref, returning it with the resourceVersion
+// of its Secret. When from is set, a Secret outside that namespace is
+// subject to the cross-namespace credentials policy.
+func readAPIKey(ctx context.Context, c client.Reader, ref nextdnsv1alpha1.SecretKeySelector, from string) (string, string, error) {
+	crossNamespace := from != "" && ref.Namespace != from
+	if crossNamespace && crossNamespaceCredentials == CrossNamespaceCredentialsDeny {
+		return "", "", fmt.Errorf("%w: secret %s/%s is outside the namespace %s", errCredentialsAccessDenied, ref.Namespace, ref.Name, from)
 	}
 
 	secret := &corev1.Secret{}
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      secretName,
-		Namespace: secretNamespace,
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      ref.Name,
+		Namespace: ref.Namespace,
 	}, secret); err != nil {
-		return "", fmt.Errorf("failed to get secret %s/%s: %w", secretNamespace, secretName, err)
+		if crossNamespace && (apierrors.IsForbidden(err) || strings.Contains(err.Error(), unknownNamespaceMessage)) {
+			return "", "", fmt.Errorf("%w: secret %s/%s cannot be read; grant the operator permission to read it in namespace %s: %v",
+				errCredentialsAccessDenied, ref.Namespace, ref.Name, ref.Namespace, err)
+		}
+		return "", "", fmt.Errorf("failed to get secret %s/%s: %w", ref.Namespace, ref.Name, err)
 	}
 
-	apiKey, ok := secret.Data[secretKey]
+	if crossNamespace && !credentialsGranted(secret.Annotations, from) {
+		return "", "", fmt.Errorf("%w: secret %s/%s does not list namespace %s in its %s annotation",
+			errCredentialsAccessDenied, ref.Namespace, ref.Name, from, AnnotationAllowedNamespaces)
+	}
+
+	apiKey, ok := secret.Data[ref.Key]
 	if !ok {
-		return "", fmt.Errorf("key %s not found in secret %s/%s", secretKey, secretNamespace, secretName)
+		return "", "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, ref.Namespace, ref.Name)
 	}
 
-	return string(apiKey), nil
+	return string(apiKey), secret.ResourceVersion, nil
 }
 
 // ResolvedLists contains the merged lists from all sources
 type ResolvedLists struct {
-	Allowlist      []nextdns.DomainEntry
-	Denylist       []nextdns.DomainEntry
-	TLDs           []string // TLDs stay as strings - NextDNS API doesn't support active field for TLDs
+	Allowlist []nextdns.DomainEntry
+	Denylist  []nextdns.DomainEntry
+	TLDs      []string // TLDs stay as strings - NextDNS API doesn't support active field for TLDs
+	// Rewrites holds active rewrites; nil means neither inline rewrites nor
+	// rewriteRefs are set and remote rewrites are left unchanged
+	Rewrites       []nextdns.RewriteEntry
 	ResourceStatus *nextdnsv1alpha1.ReferencedResources
 }
 
-// resolveListReferences resolves all list references and merges with inline lists
+// fetchReferencedSources fetches the sources of a referenced list through the
+// shared source cache. Sources that fail after an earlier successful fetch
+// keep their previous content; a source that was never fetched fails the
+// resolution rather than syncing the list without its entries.
+func (r *NextDNSProfileReconciler) fetchReferencedSources(ctx context.Context, sources []nextdnsv1alpha1.ListSource) (*fetchedSources, error) {
+	fetched := fetchListSources(ctx, r.ListSources, sources, false)
+	if fetched.missing {
+		return nil, fmt.Errorf("failed to fetch list sources: %w", fetched.err)
+	}
+	if fetched.err != nil {
+		log.FromContext(ctx).Info("Using previously fetched list source content", "error", fetched.err.Error())
+	}
+	return fetched, nil
+}
+
+// resolveListReferences resolves all list references and merges with inline lists.
+// Referenced lists are resolved through the shared list cache.
 func (r *NextDNSProfileReconciler) resolveListReferences(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile) (*ResolvedLists, error) {
 	resolved := &ResolvedLists{
 		Allowlist: make([]nextdns.DomainEntry, 0),
@@ -390,9 +635,21 @@ func (r *NextDNSProfileReconciler) resolveListReferences(ctx context.Context, pr
 			Allowlists: make([]nextdnsv1alpha1.ReferencedResourceStatus, 0),
 			Denylists:  make([]nextdnsv1alpha1.ReferencedResourceStatus, 0),
 			TLDLists:   make([]nextdnsv1alpha1.ReferencedResourceStatus, 0),
+			Rewrites:   make([]nextdnsv1alpha1.ReferencedResourceStatus, 0),
 		},
 	}
 
+	referenceStatus := func(ref nextdnsv1alpha1.ListReference, ns string, list *resolvedList) nextdnsv1alpha1.ReferencedResourceStatus {
+		return nextdnsv1alpha1.ReferencedResourceStatus{
+			Name:        ref.Name,
+			Namespace:   ns,
+			Kind:        ref.Kind,
+			Ready:       true,
+			Count:       list.count,
+			ContentHash: list.hash,
+		}
+	}
+
 	// Resolve allowlist references
 	for _, ref := range profile.Spec.AllowlistRefs {
 		ns := ref.Namespace
@@ -400,39 +657,34 @@ func (r *NextDNSProfileReconciler) resolveListReferences(ctx context.Context, pr
 			ns = profile.Namespace
 		}
 
-		allowlist := &nextdnsv1alpha1.NextDNSAllowlist{}
-		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, allowlist); err != nil {
-			return nil, fmt.Errorf("failed to get allowlist %s/%s: %w", ns, ref.Name, err)
+		var list *resolvedList
+		var err error
+		switch ref.Kind {
+		case "", nextdnsv1alpha1.KindAllowlist:
+			allowlist := &nextdnsv1alpha1.NextDNSAllowlist{}
+			list, err = r.resolveDomainListRef(ctx, profile.Namespace, ns, ref.Name, nextdnsv1alpha1.KindAllowlist, allowlist,
+				func() ([]nextdnsv1alpha1.DomainEntry, []nextdnsv1alpha1.ListSource) {
+					return allowlist.Spec.Domains, allowlist.Spec.Sources
+				})
+		case nextdnsv1alpha1.KindClusterAllowlist:
+			ns = ""
+			allowlist := &nextdnsv1alpha1.ClusterNextDNSAllowlist{}
+			list, err = r.resolveDomainListRef(ctx, profile.Namespace, ns, ref.Name, nextdnsv1alpha1.KindClusterAllowlist, allowlist,
+				func() ([]nextdnsv1alpha1.DomainEntry, []nextdnsv1alpha1.ListSource) {
+					return allowlist.Spec.Domains, allowlist.Spec.Sources
+				})
+		default:
+			err = fmt.Errorf("allowlistRefs cannot reference kind %s", ref.Kind)
 		}
-
-		count := 0
-		for _, entry := range allowlist.Spec.Domains {
-			active := entry.Active == nil || *entry.Active
-			resolved.Allowlist = append(resolved.Allowlist, nextdns.DomainEntry{
-				Domain: entry.Domain,
-				Active: active,
-			})
-			if active {
-				count++
-			}
+		if err != nil {
+			return nil, err
 		}
-
-		resolved.ResourceStatus.Allowlists = append(resolved.ResourceStatus.Allowlists, nextdnsv1alpha1.ReferencedResourceStatus{
-			Name:      ref.Name,
-			Namespace: ns,
-			Ready:     true,
-			Count:     count,
-		})
+		resolved.Allowlist = append(resolved.Allowlist, list.Domains...)
+		resolved.ResourceStatus.Allowlists = append(resolved.ResourceStatus.Allowlists, referenceStatus(ref, ns, list))
 	}
 
 	// Add inline allowlist entries
-	for _, entry := range profile.Spec.Allowlist {
-		active := entry.Active == nil || *entry.Active
-		resolved.Allowlist = append(resolved.Allowlist, nextdns.DomainEntry{
-			Domain: entry.Domain,
-			Active: active,
-		})
-	}
+	resolved.Allowlist = append(resolved.Allowlist, resolveDomainList(profile.Spec.Allowlist).Domains...)
 
 	// Resolve denylist references
 	for _, ref := range profile.Spec.DenylistRefs {
@@ -441,39 +693,36 @@ func (r *NextDNSProfileReconciler) resolveListReferences(ctx context.Context, pr
 			ns = profile.Namespace
 		}
 
-		denylist := &nextdnsv1alpha1.NextDNSDenylist{}
-		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, denylist); err != nil {
-			return nil, fmt.Errorf("failed to get denylist %s/%s: %w", ns, ref.Name, err)
+		var list *resolvedList
+		var err error
+		switch ref.Kind {
+		case "", nextdnsv1alpha1.KindDenylist:
+			denylist := &nextdnsv1alpha1.NextDNSDenylist{}
+			list, err = r.resolveDomainListRef(ctx, profile.Namespace, ns, ref.Name, nextdnsv1alpha1.KindDenylist, denylist,
+				func() ([]nextdnsv1alpha1.DomainEntry, []nextdnsv1alpha1.ListSource) {
+					return denylist.Spec.Domains, denylist.Spec.Sources
+				})
+		case nextdnsv1alpha1.KindClusterDenylist:
+			ns = ""
+			denylist := &nextdnsv1alpha1.ClusterNextDNSDenylist{}
+			list, err = r.resolveDomainListRef(ctx, profile.Namespace, ns, ref.Name, nextdnsv1alpha1.KindClusterDenylist, denylist,
+				func() ([]nextdnsv1alpha1.DomainEntry, []nextdnsv1alpha1.ListSource) {
+					return denylist.Spec.Domains, denylist.Spec.Sources
+				})
+		case nextdnsv1alpha1.KindDenylistSource:
+			list, err = r.resolveDenylistSource(ctx, profile.Namespace, ns, ref.Name)
+		default:
+			err = fmt.Errorf("denylistRefs cannot reference kind %s", ref.Kind)
 		}
-
-		count := 0
-		for _, entry := range denylist.Spec.Domains {
-			active := entry.Active == nil || *entry.Active
-			resolved.Denylist = append(resolved.Denylist, nextdns.DomainEntry{
-				Domain: entry.Domain,
-				Active: active,
-			})
-			if active {
-				count++
-			}
+		if err != nil {
+			return nil, err
 		}
-
-		resolved.ResourceStatus.Denylists = append(resolved.ResourceStatus.Denylists, nextdnsv1alpha1.ReferencedResourceStatus{
-			Name:      ref.Name,
-			Namespace: ns,
-			Ready:     true,
-			Count:     count,
-		})
+		resolved.Denylist = append(resolved.Denylist, list.Domains...)
+		resolved.ResourceStatus.Denylists = append(resolved.ResourceStatus.Denylists, referenceStatus(ref, ns, list))
 	}
 
 	// Add inline denylist entries
-	for _, entry := range profile.Spec.Denylist {
-		active := entry.Active == nil || *entry.Active
-		resolved.Denylist = append(resolved.Denylist, nextdns.DomainEntry{
-			Domain: entry.Domain,
-			Active: active,
-		})
-	}
+	resolved.Denylist = append(resolved.Denylist, resolveDomainList(profile.Spec.Denylist).Domains...)
 
 	// Resolve TLD list references
 	for _, ref := range profile.Spec.TLDListRefs {
@@ -482,30 +731,162 @@ func (r *NextDNSProfileReconciler) resolveListReferences(ctx context.Context, pr
 			ns = profile.Namespace
 		}
 
+		if err := checkListKind("tldListRefs", ref); err != nil {
+			return nil, err
+		}
+
 		tldList := &nextdnsv1alpha1.NextDNSTLDList{}
 		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, tldList); err != nil {
-			return nil, fmt.Errorf("failed to get TLD list %s/%s: %w", ns, ref.Name, err)
+			return nil, newListReferenceError("TLD list", profile.Namespace, ns, ref.Name, err)
 		}
 
-		count := 0
-		for _, entry := range tldList.Spec.TLDs {
-			if entry.Active == nil || *entry.Active {
-				resolved.TLDs = append(resolved.TLDs, entry.TLD)
-				count++
+		sourced, err := r.fetchReferencedSources(ctx, tldList.Spec.Sources)
+		if err != nil {
+			return nil, newListReferenceError("TLD list", profile.Namespace, ns, ref.Name, err)
+		}
+		list := r.listCache.resolve("NextDNSTLDList", tldList, sourced.revision, func() *resolvedList {
+			return appendSourcedTLDs(resolveTLDList(tldList.Spec.TLDs), sourced.entries)
+		})
+		resolved.TLDs = append(resolved.TLDs, list.TLDs...)
+		resolved.ResourceStatus.TLDLists = append(resolved.ResourceStatus.TLDLists, referenceStatus(ref, ns, list))
+	}
+
+	// Resolve rewrite references and merge with inline rewrites
+	if profile.Spec.Rewrites != nil || len(profile.Spec.RewriteRefs) > 0 {
+		resolved.Rewrites = make([]nextdns.RewriteEntry, 0)
+		seen := make(map[nextdns.RewriteEntry]bool)
+		addRewrites := func(entries []nextdns.RewriteEntry) {
+			for _, entry := range entries {
+				if !seen[entry] {
+					seen[entry] = true
+					resolved.Rewrites = append(resolved.Rewrites, entry)
+				}
 			}
 		}
 
-		resolved.ResourceStatus.TLDLists = append(resolved.ResourceStatus.TLDLists, nextdnsv1alpha1.ReferencedResourceStatus{
-			Name:      ref.Name,
-			Namespace: ns,
-			Ready:     true,
-			Count:     count,
-		})
+		for _, ref := range profile.Spec.RewriteRefs {
+			ns := ref.Namespace
+			if ns == "" {
+				ns = profile.Namespace
+			}
+
+			if err := checkListKind("rewriteRefs", ref); err != nil {
+				return nil, err
+			}
+
+			rewriteList := &nextdnsv1alpha1.NextDNSRewrite{}
+			if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, rewriteList); err != nil {
+				return nil, newListReferenceError("rewrite list", profile.Namespace, ns, ref.Name, err)
+			}
+
+			list := r.listCache.resolve("NextDNSRewrite", rewriteList, "", func() *resolvedList {
+				return resolveRewriteList(rewriteList.Spec.Rewrites)
+			})
+			addRewrites(list.Rewrites)
+			resolved.ResourceStatus.Rewrites = append(resolved.ResourceStatus.Rewrites, referenceStatus(ref, ns, list))
+		}
+
+		addRewrites(activeRewrites(profile.Spec.Rewrites))
 	}
 
 	return resolved, nil
 }
 
+// checkListKind rejects a kind on references of fields that reference a
+// single kind; only allowlistRefs and denylistRefs accept a kind
+func checkListKind(field string, ref nextdnsv1alpha1.ListReference) error {
+	if ref.Kind != "" {
+		return fmt.Errorf("%s cannot reference kind %s", field, ref.Kind)
+	}
+	return nil
+}
+
+// listKindLabels names the kinds of allowlists and denylists in errors
+var listKindLabels = map[string]string{
+	nextdnsv1alpha1.KindAllowlist:        "allowlist",
+	nextdnsv1alpha1.KindClusterAllowlist: "cluster allowlist",
+	nextdnsv1alpha1.KindDenylist:         "denylist",
+	nextdnsv1alpha1.KindClusterDenylist:  "cluster denylist",
+}
+
+// resolveDomainListRef reads the referenced allowlist or denylist into obj
+// and resolves its domains, including those of its sources, through the
+// shared caches. An empty namespace reads a cluster-scoped list; entries
+// returns the list's domains and sources once obj is read.
+func (r *NextDNSProfileReconciler) resolveDomainListRef(
+	ctx context.Context,
+	profileNamespace, ns, name, kind string,
+	obj client.Object,
+	entries func() ([]nextdnsv1alpha1.DomainEntry, []nextdnsv1alpha1.ListSource),
+) (*resolvedList, error) {
+	label := listKindLabels[kind]
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, obj); err != nil {
+		return nil, newListReferenceError(label, profileNamespace, ns, name, err)
+	}
+
+	domains, sources := entries()
+	sourced, err := r.fetchReferencedSources(ctx, sources)
+	if err != nil {
+		return nil, newListReferenceError(label, profileNamespace, ns, name, err)
+	}
+
+	return r.listCache.resolve(kind, obj, sourced.revision, func() *resolvedList {
+		return appendSourcedDomains(resolveDomainList(domains), sourced.entries)
+	}), nil
+}
+
+// resolveDenylistSource resolves the domains of a NextDNSDenylistSource from
+// the ConfigMap its controller stores them in
+func (r *NextDNSProfileReconciler) resolveDenylistSource(ctx context.Context, profileNamespace, ns, name string) (*resolvedList, error) {
+	source := &nextdnsv1alpha1.NextDNSDenylistSource{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, source); err != nil {
+		return nil, newListReferenceError("denylist source", profileNamespace, ns, name, err)
+	}
+	if source.Status.ConfigMapName == "" {
+		return nil, newListReferenceError("denylist source", profileNamespace, ns, name,
+			errors.New("blocklist has not been fetched yet"))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: source.Status.ConfigMapName, Namespace: ns}, configMap); err != nil {
+		return nil, newListReferenceError("denylist source", profileNamespace, ns, name,
+			fmt.Errorf("failed to get ConfigMap %s: %w", source.Status.ConfigMapName, err))
+	}
+
+	return r.listCache.resolve(nextdnsv1alpha1.KindDenylistSource, source, configMap.ResourceVersion, func() *resolvedList {
+		list := &resolvedList{}
+		for _, domain := range strings.Split(configMap.Data[DenylistSourceDomainsKey], "\n") {
+			if domain != "" {
+				list.Domains = append(list.Domains, nextdns.DomainEntry{Domain: domain, Active: true})
+				list.count++
+			}
+		}
+		return list
+	}), nil
+}
+
+// rewriteStatuses converts the results of a rewrite sync to status entries
+func rewriteStatuses(results []nextdns.RewriteResult) []nextdnsv1alpha1.RewriteStatus {
+	if len(results) == 0 {
+		return nil
+	}
+	statuses := make([]nextdnsv1alpha1.RewriteStatus, 0, len(results))
+	for _, result := range results {
+		status := nextdnsv1alpha1.RewriteStatus{
+			Name:    result.Name,
+			Content: result.Content,
+			State:   nextdnsv1alpha1.RewriteStateApplied,
+			Type:    result.Type,
+		}
+		if result.Error != "" {
+			status.State = nextdnsv1alpha1.RewriteStateRejected
+			status.Message = result.Error
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
 // syncWithNextDNS syncs the profile with the NextDNS API
 func (r *NextDNSProfileReconciler) syncWithNextDNS(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, apiKey string, lists *ResolvedLists) error {
 	logger := log.FromContext(ctx)
@@ -524,6 +905,9 @@ func (r *NextDNSProfileReconciler) syncWithNextDNS(ctx context.Context, profile
 		"profileName", profile.Spec.Name,
 		"profileID", profile.Spec.ProfileID)
 
+	// Drift is only meaningful for a profile this operator already synced
+	previouslySynced := profile.Status.ProfileID != ""
+
 	// If no profile ID is set, create a new profile or adopt existing one
 	if profile.Status.ProfileID == "" {
 		var existingProfile, newProfile *sdknextdns.Profile
@@ -534,6 +918,8 @@ func (r *NextDNSProfileReconciler) syncWithNextDNS(ctx context.Context, profile
 				return fmt.Errorf("failed to get existing profile %s: %w", profile.Spec.ProfileID, err)
 			}
 			profile.Status.ProfileID = profile.Spec.ProfileID
+			recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonProfileAdopted,
+				"Adopted NextDNS profile %s", profile.Status.ProfileID)
 		} else {
 			// Create new profile via API
 			newProfileID, err := client.CreateProfile(ctx, profile.Spec.Name)
@@ -542,6 +928,8 @@ func (r *NextDNSProfileReconciler) syncWithNextDNS(ctx context.Context, profile
 			}
 			profile.Status.ProfileID = newProfileID
 			logger.Info("Created new NextDNS profile", "profileID", newProfileID)
+			recordEvent(r.Recorder, profile, corev1.EventTypeNormal, EventReasonProfileCreated,
+				"Created NextDNS profile %s", newProfileID)
 			newProfile, err = client.GetProfile(ctx, newProfileID)
 			if err != nil {
 				logger.Error(err, "Failed to get fingerprint for new profile", "profileID", newProfileID)
@@ -560,172 +948,239 @@ func (r *NextDNSProfileReconciler) syncWithNextDNS(ctx context.Context, profile
 
 	profileID := profile.Status.ProfileID
 
+	// While the desired state is unchanged since the last successful sync,
+	// any remote difference was made outside the operator
+	desiredHash, err := desiredConfigHash(&profile.Spec, lists)
+	if err != nil {
+		return err
+	}
+	if previouslySynced && profile.Status.AppliedConfigHash == desiredHash {
+		drift, err := detectDrift(ctx, client, profileID, &profile.Spec, lists)
+		if err != nil {
+			return fmt.Errorf("failed to detect drift: %w", err)
+		}
+		profile.Status.DriftSummary = drift
+		switch {
+		case drift == nil:
+			r.setCondition(profile, ConditionTypeDrifted, metav1.ConditionFalse, "InSync",
+				"Remote profile matches the desired state")
+		case profile.Spec.DriftPolicy == nextdnsv1alpha1.DriftPolicyReportOnly:
+			r.setCondition(profile, ConditionTypeDrifted, metav1.ConditionTrue, "DriftDetected",
+				formatDriftMessage(drift))
+		default:
+			drift.Corrected = true
+			r.setCondition(profile, ConditionTypeDrifted, metav1.ConditionTrue, "DriftCorrected",
+				formatDriftMessage(drift)+"; desired state re-applied")
+		}
+		if drift != nil {
+			logger.Info("Remote profile drifted from desired state",
+				"profileID", profileID, "sections", drift.Sections, "corrected", drift.Corrected)
+			recordEvent(r.Recorder, profile, corev1.EventTypeWarning, EventReasonDriftDetected,
+				"%s", formatDriftMessage(drift))
+		}
+
+		// ReportOnly leaves the remote profile as is until the desired state changes
+		if profile.Spec.DriftPolicy == nextdnsv1alpha1.DriftPolicyReportOnly {
+			return nil
+		}
+	} else {
+		// The desired state changed and is applied below, so the remote
+		// profile will match it
+		profile.Status.DriftSummary = nil
+		r.setCondition(profile, ConditionTypeDrifted, metav1.ConditionFalse, "InSync",
+			"Remote profile matches the desired state")
+	}
+
+	// MergeOnce keeps what the remote profile already has on the first sync
+	merge := mergeOnce(profile)
+	if merge {
+		lists, err = mergeRemoteLists(ctx, client, profileID, lists)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A resync request writes every section even when it is unchanged
+	force := resyncRequested(profile)
+
 	// Update profile name if needed
-	if err := client.UpdateProfile(ctx, profileID, profile.Spec.Name); err != nil {
-		return fmt.Errorf("failed to update profile name: %w", err)
+	if !previouslySynced || profile.Status.AppliedConfigHash != desiredHash || force {
+		if err := client.UpdateProfile(ctx, profileID, profile.Spec.Name); err != nil {
+			return fmt.Errorf("failed to update profile name: %w", err)
+		}
 	}
 
+	// Sections are written concurrently and each is written even when
+	// another fails; its outcome is recorded in status.sections. Sections
+	// already applied with the same content are skipped unless they drifted.
+	sections := newSectionSync(profile.Status.Sections, metav1.Now(), r.SectionConcurrency, force, profile.Status.DriftSummary)
+	var rewritesErr error
+
 	// Sync security settings
 	if profile.Spec.Security != nil {
-		securityConfig := &nextdns.SecurityConfig{
-			ThreatIntelligenceFeeds: boolValue(profile.Spec.Security.ThreatIntelligenceFeeds, true),
-			AIThreatDetection:       boolValue(profile.Spec.Security.AIThreatDetection, true),
-			GoogleSafeBrowsing:      boolValue(profile.Spec.Security.GoogleSafeBrowsing, true),
-			Cryptojacking:           boolValue(profile.Spec.Security.Cryptojacking, true),
-			DNSRebinding:            boolValue(profile.Spec.Security.DNSRebinding, true),
-			IDNHomographs:           boolValue(profile.Spec.Security.IDNHomographs, true),
-			Typosquatting:           boolValue(profile.Spec.Security.Typosquatting, true),
-			DGA:                     boolValue(profile.Spec.Security.DGA, true),
-			NRD:                     boolValue(profile.Spec.Security.NRD, false),
-			DDNS:                    boolValue(profile.Spec.Security.DDNS, false),
-			Parking:                 boolValue(profile.Spec.Security.Parking, true),
-			CSAM:                    boolValue(profile.Spec.Security.CSAM, true),
-		}
-		if err := client.UpdateSecurity(ctx, profileID, securityConfig); err != nil {
-			return fmt.Errorf("failed to update security settings: %w", err)
-		}
-	}
-
-	// Sync privacy settings
+		security := desiredSecurityConfig(profile.Spec.Security)
+		sections.apply(sectionSecurity, security, func() error {
+			if err := client.UpdateSecurity(ctx, profileID, security); err != nil {
+				return fmt.Errorf("failed to update security settings: %w", err)
+			}
+			return nil
+		})
+	}
+
+	// Sync privacy settings, blocklists and native tracking protection
 	if profile.Spec.Privacy != nil {
-		privacyConfig := &nextdns.PrivacyConfig{
-			DisguisedTrackers: boolValue(profile.Spec.Privacy.DisguisedTrackers, true),
-			AllowAffiliate:    boolValue(profile.Spec.Privacy.AllowAffiliate, false),
-		}
-		if err := client.UpdatePrivacy(ctx, profileID, privacyConfig); err != nil {
-			return fmt.Errorf("failed to update privacy settings: %w", err)
-		}
+		privacy := desiredPrivacyConfig(profile.Spec.Privacy)
+		blocklists := desiredPrivacyBlocklists(profile.Spec.Privacy)
+		natives := desiredPrivacyNatives(profile.Spec.Privacy)
+		sections.apply(sectionPrivacy, profile.Spec.Privacy, func() error {
+			if err := client.UpdatePrivacy(ctx, profileID, privacy); err != nil {
+				return fmt.Errorf("failed to update privacy settings: %w", err)
+			}
 
-		// Sync blocklists
-		if len(profile.Spec.Privacy.Blocklists) > 0 {
-			blocklists := make([]string, 0, len(profile.Spec.Privacy.Blocklists))
-			for _, bl := range profile.Spec.Privacy.Blocklists {
-				if bl.Active == nil || *bl.Active {
-					blocklists = append(blocklists, bl.ID)
+			// Sync blocklists
+			if len(profile.Spec.Privacy.Blocklists) > 0 {
+				if merge {
+					remote, err := client.GetPrivacyBlocklists(ctx, profileID)
+					if err != nil {
+						return fmt.Errorf("failed to get privacy blocklists: %w", err)
+					}
+					ids := make([]string, 0, len(remote))
+					for _, bl := range remote {
+						ids = append(ids, bl.ID)
+					}
+					blocklists = unionIDs(blocklists, ids)
+				}
+				if err := client.SyncPrivacyBlocklists(ctx, profileID, blocklists); err != nil {
+					return fmt.Errorf("failed to sync privacy blocklists: %w", err)
 				}
 			}
-			if err := client.SyncPrivacyBlocklists(ctx, profileID, blocklists); err != nil {
-				return fmt.Errorf("failed to sync privacy blocklists: %w", err)
-			}
-		}
 
-		// Sync native tracking protection
-		if len(profile.Spec.Privacy.Natives) > 0 {
-			natives := make([]string, 0, len(profile.Spec.Privacy.Natives))
-			for _, n := range profile.Spec.Privacy.Natives {
-				if n.Active == nil || *n.Active {
-					natives = append(natives, n.ID)
+			// Sync native tracking protection
+			if len(profile.Spec.Privacy.Natives) > 0 {
+				if merge {
+					remote, err := client.GetPrivacyNatives(ctx, profileID)
+					if err != nil {
+						return fmt.Errorf("failed to get privacy natives: %w", err)
+					}
+					ids := make([]string, 0, len(remote))
+					for _, n := range remote {
+						ids = append(ids, n.ID)
+					}
+					natives = unionIDs(natives, ids)
+				}
+				if err := client.SyncPrivacyNatives(ctx, profileID, natives); err != nil {
+					return fmt.Errorf("failed to sync privacy natives: %w", err)
 				}
 			}
-			if err := client.SyncPrivacyNatives(ctx, profileID, natives); err != nil {
-				return fmt.Errorf("failed to sync privacy natives: %w", err)
-			}
-		}
+			return nil
+		})
 	}
 
 	// Sync parental control settings
 	if profile.Spec.ParentalControl != nil {
-		categories := make([]string, 0)
-		for _, c := range profile.Spec.ParentalControl.Categories {
-			if c.Active == nil || *c.Active {
-				categories = append(categories, c.ID)
+		parentalControl := desiredParentalControlConfig(profile.Spec.ParentalControl)
+		sections.apply(sectionParentalControl, parentalControl, func() error {
+			if err := client.UpdateParentalControl(ctx, profileID, parentalControl); err != nil {
+				return fmt.Errorf("failed to update parental control settings: %w", err)
 			}
-		}
-		services := make([]string, 0)
-		for _, s := range profile.Spec.ParentalControl.Services {
-			if s.Active == nil || *s.Active {
-				services = append(services, s.ID)
-			}
-		}
-
-		pcConfig := &nextdns.ParentalControlConfig{
-			Categories:            categories,
-			Services:              services,
-			SafeSearch:            boolValue(profile.Spec.ParentalControl.SafeSearch, false),
-			YouTubeRestrictedMode: boolValue(profile.Spec.ParentalControl.YouTubeRestrictedMode, false),
-			BlockBypass:           boolValue(profile.Spec.ParentalControl.BlockBypass, false),
-		}
-		if err := client.UpdateParentalControl(ctx, profileID, pcConfig); err != nil {
-			return fmt.Errorf("failed to update parental control settings: %w", err)
-		}
+			return nil
+		})
 	}
 
 	// Sync settings (logs, block page, performance, web3)
 	if profile.Spec.Settings != nil {
-		settingsConfig := &nextdns.SettingsConfig{
-			// Log defaults
-			LogsEnabled:   true,
-			LogClientsIPs: false,
-			LogDomains:    true,
-			// Block page default
-			BlockPageEnable: true,
-			// Performance defaults
-			Ecs:             true,
-			CacheBoost:      true,
-			CnameFlattening: true,
-			// Web3 default
-			Web3: false,
-			BAV:  false,
-		}
-		if profile.Spec.Settings.Logs != nil {
-			settingsConfig.LogsEnabled = boolValue(profile.Spec.Settings.Logs.Enabled, true)
-			settingsConfig.LogClientsIPs = boolValue(profile.Spec.Settings.Logs.LogClientsIPs, false)
-			settingsConfig.LogDomains = boolValue(profile.Spec.Settings.Logs.LogDomains, true)
-			settingsConfig.LogRetention = parseRetentionSeconds(profile.Spec.Settings.Logs.Retention)
-			settingsConfig.Location = profile.Spec.Settings.Logs.Location
-		}
-		if profile.Spec.Settings.BlockPage != nil {
-			settingsConfig.BlockPageEnable = boolValue(profile.Spec.Settings.BlockPage.Enabled, true)
-		}
-		if profile.Spec.Settings.Performance != nil {
-			settingsConfig.Ecs = boolValue(profile.Spec.Settings.Performance.ECS, true)
-			settingsConfig.CacheBoost = boolValue(profile.Spec.Settings.Performance.CacheBoost, true)
-			settingsConfig.CnameFlattening = boolValue(profile.Spec.Settings.Performance.CNAMEFlattening, true)
-		}
-		settingsConfig.Web3 = boolValue(profile.Spec.Settings.Web3, false)
-		settingsConfig.BAV = boolValue(profile.Spec.Settings.BAV, false)
-		if err := client.UpdateSettings(ctx, profileID, settingsConfig); err != nil {
-			return fmt.Errorf("failed to update settings: %w", err)
-		}
-	}
-
-	// Sync rewrites (nil = field omitted, don't touch remote; empty = explicit clear)
-	if profile.Spec.Rewrites != nil {
-		rewriteEntries := make([]nextdns.RewriteEntry, 0, len(profile.Spec.Rewrites))
-		for _, rw := range profile.Spec.Rewrites {
-			if rw.Active == nil || *rw.Active {
-				rewriteEntries = append(rewriteEntries, nextdns.RewriteEntry{
-					Name:    rw.From,
-					Content: rw.To,
-				})
+		settingsConfig := desiredSettingsConfig(profile.Spec.Settings)
+		sections.apply(sectionSettings, settingsConfig, func() error {
+			if err := client.UpdateSettings(ctx, profileID, settingsConfig); err != nil {
+				return fmt.Errorf("failed to update settings: %w", err)
 			}
-		}
-		if err := client.SyncRewrites(ctx, profileID, rewriteEntries); err != nil {
-			return fmt.Errorf("failed to sync rewrites: %w", err)
-		}
+			return nil
+		})
+	}
+
+	// Sync rewrites (nil = fields omitted, don't touch remote; empty = explicit clear)
+	if lists.Rewrites != nil {
+		sections.run(func() {
+			results, err := client.SyncRewrites(ctx, profileID, lists.Rewrites)
+			if err != nil {
+				rewritesErr = fmt.Errorf("failed to sync rewrites: %w", err)
+				return
+			}
+			profile.Status.Rewrites = rewriteStatuses(results)
+			for _, rw := range profile.Status.Rewrites {
+				if rw.State == nextdnsv1alpha1.RewriteStateRejected {
+					logger.Info("NextDNS rejected rewrite", "name", rw.Name, "content", rw.Content, "reason", rw.Message)
+				}
+			}
+		})
+	} else {
+		profile.Status.Rewrites = nil
+	}
+
+	// Sync denylist and allowlist incrementally; with preserveUnmanagedEntries
+	// only domains recorded as managed are removed, and MergeOnce removes none
+	var managed nextdnsv1alpha1.ManagedListEntries
+	if profile.Status.ManagedEntries != nil {
+		managed = *profile.Status.ManagedEntries
 	}
 
-	// Sync denylist
 	if len(lists.Denylist) > 0 {
-		if err := client.SyncDenylist(ctx, profileID, lists.Denylist); err != nil {
-			return fmt.Errorf("failed to sync denylist: %w", err)
+		opts := nextdns.ListSyncOptions{
+			PreserveUnmanaged: profile.Spec.PreserveUnmanagedEntries || merge,
+			Managed:           managed.Denylist,
 		}
+		sections.apply(sectionDenylist, lists.Denylist, func() error {
+			if err := client.SyncDenylist(ctx, profileID, lists.Denylist, opts); err != nil {
+				return fmt.Errorf("failed to sync denylist: %w", err)
+			}
+			managed.Denylist = entryDomains(lists.Denylist)
+			return nil
+		})
 	}
 
-	// Sync allowlist
 	if len(lists.Allowlist) > 0 {
-		if err := client.SyncAllowlist(ctx, profileID, lists.Allowlist); err != nil {
-			return fmt.Errorf("failed to sync allowlist: %w", err)
+		opts := nextdns.ListSyncOptions{
+			PreserveUnmanaged: profile.Spec.PreserveUnmanagedEntries || merge,
+			Managed:           managed.Allowlist,
 		}
+		sections.apply(sectionAllowlist, lists.Allowlist, func() error {
+			if err := client.SyncAllowlist(ctx, profileID, lists.Allowlist, opts); err != nil {
+				return fmt.Errorf("failed to sync allowlist: %w", err)
+			}
+			managed.Allowlist = entryDomains(lists.Allowlist)
+			return nil
+		})
 	}
 
 	// Sync TLDs
 	if len(lists.TLDs) > 0 {
-		if err := client.SyncSecurityTLDs(ctx, profileID, lists.TLDs); err != nil {
-			return fmt.Errorf("failed to sync TLDs: %w", err)
-		}
+		sections.apply(sectionTLDs, lists.TLDs, func() error {
+			if err := client.SyncSecurityTLDs(ctx, profileID, lists.TLDs); err != nil {
+				return fmt.Errorf("failed to sync TLDs: %w", err)
+			}
+			return nil
+		})
 	}
 
+	// Wait for the concurrent writes before reading their results
+	sections.wait()
+
+	if profile.Spec.PreserveUnmanagedEntries {
+		profile.Status.ManagedEntries = &managed
+	} else {
+		profile.Status.ManagedEntries = nil
+	}
+
+	if len(sections.skipped) > 0 {
+		logger.V(1).Info("Skipped unchanged profile sections", "profileID", profileID, "sections", sections.skipped)
+	}
+	profile.Status.Sections = sections.status()
+	if err := errors.Join(sections.err(), rewritesErr); err != nil {
+		return err
+	}
+
+	profile.Status.AppliedConfigHash = desiredHash
+
 	logger.Info("Successfully synced with NextDNS API", "profileID", profileID)
 	return nil
 }
@@ -763,7 +1218,8 @@ func (r *NextDNSProfileReconciler) reconcileObserveMode(ctx context.Context, pro
 	observed, fingerprint, rawSetup, err := r.readFullProfile(ctx, client, profile.Spec.ProfileID)
 	if err != nil {
 		logger.Error(err, "Failed to read full profile from NextDNS")
-		metrics.RecordProfileSyncError(profile.Name, profile.Namespace, "ObserveFailed")
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, "ObserveFailed")
+		r.recordRejectedCredentials(profile, err)
 		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "ObserveFailed", err.Error())
 		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
 			logger.Error(updateErr, "Failed to update status")
@@ -777,15 +1233,32 @@ func (r *NextDNSProfileReconciler) reconcileObserveMode(ctx context.Context, pro
 	// Update status fields
 	profile.Status.ProfileID = profile.Spec.ProfileID
 	profile.Status.Fingerprint = fingerprint
+	profile.Status.AccountFingerprint = nextdns.AccountFingerprint(apiKey)
 	profile.Status.ObservedConfig = observed
 	profile.Status.SuggestedSpec = buildSuggestedSpec(observed)
 	profile.Status.Setup = buildProfileSetup(rawSetup, profile.Spec.ProfileID)
 	profile.Status.ObservedGeneration = profile.Generation
 
-	r.setCondition(profile, ConditionTypeObserveOnly, metav1.ConditionTrue, "ObserveMode", "Profile is in observe-only mode")
+	// Drift, overlays and the effective config export only apply in managed mode
+	profile.Status.DriftSummary = nil
+	profile.Status.Sections = nil
+	profile.Status.ActiveOverlay = ""
+	profile.Status.EffectiveConfigMap = ""
+	meta.RemoveStatusCondition(&profile.Status.Conditions, ConditionTypeDrifted)
+
+	if observeBeforeAdoption(profile) {
+		r.setCondition(profile, ConditionTypeObserveOnly, metav1.ConditionTrue, "AdoptionPending",
+			"spec.adoptionPolicy is ObserveFirst; review status.observedConfig and set it to Overwrite or MergeOnce to start syncing")
+	} else {
+		r.setCondition(profile, ConditionTypeObserveOnly, metav1.ConditionTrue, "ObserveMode", "Profile is in observe-only mode")
+	}
 	r.setCondition(profile, ConditionTypeSynced, metav1.ConditionTrue, "ObserveSuccess", "Remote profile read successfully")
 	r.setCondition(profile, ConditionTypeReady, metav1.ConditionTrue, "Observed", "Profile observed successfully")
 
+	if err := r.reconcileDeployedCoreDNS(ctx, profile); err != nil {
+		logger.Error(err, "Failed to reconcile deployed NextDNSCoreDNS")
+	}
+
 	// Check if status actually changed (compare all meaningful fields including conditions)
 	statusChanged := !apiequality.Semantic.DeepEqual(statusBefore.ObservedConfig, profile.Status.ObservedConfig) ||
 		!apiequality.Semantic.DeepEqual(statusBefore.SuggestedSpec, profile.Status.SuggestedSpec) ||
@@ -793,14 +1266,17 @@ func (r *NextDNSProfileReconciler) reconcileObserveMode(ctx context.Context, pro
 		!apiequality.Semantic.DeepEqual(statusBefore.Conditions, profile.Status.Conditions) ||
 		statusBefore.ProfileID != profile.Status.ProfileID ||
 		statusBefore.Fingerprint != profile.Status.Fingerprint ||
+		statusBefore.AccountFingerprint != profile.Status.AccountFingerprint ||
+		statusBefore.DeployedCoreDNS != profile.Status.DeployedCoreDNS ||
 		statusBefore.ObservedGeneration != profile.Status.ObservedGeneration
 
-	// Only update LastSyncTime and write status if data actually changed
-	if statusChanged || profile.Status.LastSyncTime == nil {
+	// Only update LastSyncTime and write status if data actually changed or a
+	// resync was requested
+	if statusChanged || resyncRequested(profile) || profile.Status.LastSyncTime == nil {
 		now := metav1.Now()
 		profile.Status.LastSyncTime = &now
 
-		metrics.RecordProfileSync(profile.Name, profile.Namespace)
+		r.metrics().RecordProfileSync(profile.Name, profile.Namespace)
 
 		if err := r.Status().Update(ctx, profile); err != nil {
 			logger.Error(err, "Failed to update status")
@@ -815,8 +1291,69 @@ func (r *NextDNSProfileReconciler) reconcileObserveMode(ctx context.Context, pro
 			"profileID", profile.Spec.ProfileID)
 	}
 
-	syncInterval := CalculateSyncInterval(r.SyncPeriod)
-	return ctrl.Result{RequeueAfter: syncInterval}, nil
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, profile); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	syncPeriod, err := resourceSyncPeriod(profile, profile.Spec.SyncInterval, r.SyncPeriod)
+	if err != nil {
+		logger.Error(err, "Invalid sync interval override")
+	}
+	return ctrl.Result{RequeueAfter: CalculateSyncInterval(syncPeriod)}, nil
+}
+
+// importRemoteConfig reads the adopted profile and writes its configuration
+// into the spec according to spec.importPolicy. The next reconcile syncs the
+// updated spec.
+func (r *NextDNSProfileReconciler) importRemoteConfig(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, apiKey string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	fail := func(err error) (ctrl.Result, error) {
+		logger.Error(err, "Failed to import remote profile configuration")
+		r.metrics().RecordProfileSyncError(profile.Name, profile.Namespace, "ImportFailed")
+		r.setCondition(profile, ConditionTypeReady, metav1.ConditionFalse, "ImportFailed", err.Error())
+		if updateErr := r.Status().Update(ctx, profile); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: 60 * time.Second}, nil
+	}
+
+	factory := r.ClientFactory
+	if factory == nil {
+		factory = DefaultClientFactory
+	}
+	client, err := factory(apiKey)
+	if err != nil {
+		return fail(fmt.Errorf("failed to create API client: %w", err))
+	}
+
+	observed, _, _, err := r.readFullProfile(ctx, client, profile.Spec.ProfileID)
+	if err != nil {
+		return fail(err)
+	}
+
+	overwrite := profile.Spec.ImportPolicy == nextdnsv1alpha1.ImportPolicyOverwrite
+	if err := mergeIntoSpec(&profile.Spec, buildSuggestedSpec(observed), overwrite); err != nil {
+		return fail(fmt.Errorf("failed to import remote configuration: %w", err))
+	}
+
+	status := profile.Status.DeepCopy()
+	if err := r.Update(ctx, profile); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	profile.Status = *status
+	profile.Status.ImportedAt = &now
+	if err := r.Status().Update(ctx, profile); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Imported remote profile configuration into spec",
+		"profileID", profile.Spec.ProfileID,
+		"importPolicy", profile.Spec.ImportPolicy)
+	return ctrl.Result{RequeueAfter: time.Second}, nil
 }
 
 // readFullProfile reads all sections of a NextDNS profile
@@ -1056,37 +1593,37 @@ func buildSuggestedSpec(observed *nextdnsv1alpha1.ObservedConfig) *nextdnsv1alph
 	// Security: bool -> *bool
 	if observed.Security != nil {
 		suggested.Security = &nextdnsv1alpha1.SecuritySpec{
-			ThreatIntelligenceFeeds: boolPtr(observed.Security.ThreatIntelligenceFeeds),
-			AIThreatDetection:       boolPtr(observed.Security.AIThreatDetection),
-			GoogleSafeBrowsing:      boolPtr(observed.Security.GoogleSafeBrowsing),
-			Cryptojacking:           boolPtr(observed.Security.Cryptojacking),
-			DNSRebinding:            boolPtr(observed.Security.DNSRebinding),
-			IDNHomographs:           boolPtr(observed.Security.IDNHomographs),
-			Typosquatting:           boolPtr(observed.Security.Typosquatting),
-			DGA:                     boolPtr(observed.Security.DGA),
-			NRD:                     boolPtr(observed.Security.NRD),
-			DDNS:                    boolPtr(observed.Security.DDNS),
-			Parking:                 boolPtr(observed.Security.Parking),
-			CSAM:                    boolPtr(observed.Security.CSAM),
+			ThreatIntelligenceFeeds: ptr.To(observed.Security.ThreatIntelligenceFeeds),
+			AIThreatDetection:       ptr.To(observed.Security.AIThreatDetection),
+			GoogleSafeBrowsing:      ptr.To(observed.Security.GoogleSafeBrowsing),
+			Cryptojacking:           ptr.To(observed.Security.Cryptojacking),
+			DNSRebinding:            ptr.To(observed.Security.DNSRebinding),
+			IDNHomographs:           ptr.To(observed.Security.IDNHomographs),
+			Typosquatting:           ptr.To(observed.Security.Typosquatting),
+			DGA:                     ptr.To(observed.Security.DGA),
+			NRD:                     ptr.To(observed.Security.NRD),
+			DDNS:                    ptr.To(observed.Security.DDNS),
+			Parking:                 ptr.To(observed.Security.Parking),
+			CSAM:                    ptr.To(observed.Security.CSAM),
 		}
 	}
 
 	// Privacy: bool -> *bool, blocklists/natives default Active to true
 	if observed.Privacy != nil {
 		suggested.Privacy = &nextdnsv1alpha1.PrivacySpec{
-			DisguisedTrackers: boolPtr(observed.Privacy.DisguisedTrackers),
-			AllowAffiliate:    boolPtr(observed.Privacy.AllowAffiliate),
+			DisguisedTrackers: ptr.To(observed.Privacy.DisguisedTrackers),
+			AllowAffiliate:    ptr.To(observed.Privacy.AllowAffiliate),
 		}
 		for _, bl := range observed.Privacy.Blocklists {
 			suggested.Privacy.Blocklists = append(suggested.Privacy.Blocklists, nextdnsv1alpha1.BlocklistEntry{
 				ID:     bl.ID,
-				Active: boolPtr(true),
+				Active: ptr.To(true),
 			})
 		}
 		for _, n := range observed.Privacy.Natives {
 			suggested.Privacy.Natives = append(suggested.Privacy.Natives, nextdnsv1alpha1.NativeEntry{
 				ID:     n.ID,
-				Active: boolPtr(true),
+				Active: ptr.To(true),
 			})
 		}
 	}
@@ -1094,21 +1631,21 @@ func buildSuggestedSpec(observed *nextdnsv1alpha1.ObservedConfig) *nextdnsv1alph
 	// ParentalControl: bool -> *bool, categories/services preserve Active as *bool
 	if observed.ParentalControl != nil {
 		suggested.ParentalControl = &nextdnsv1alpha1.ParentalControlSpec{
-			SafeSearch:            boolPtr(observed.ParentalControl.SafeSearch),
-			YouTubeRestrictedMode: boolPtr(observed.ParentalControl.YouTubeRestrictedMode),
-			BlockBypass:           boolPtr(observed.ParentalControl.BlockBypass),
+			SafeSearch:            ptr.To(observed.ParentalControl.SafeSearch),
+			YouTubeRestrictedMode: ptr.To(observed.ParentalControl.YouTubeRestrictedMode),
+			BlockBypass:           ptr.To(observed.ParentalControl.BlockBypass),
 		}
 		for _, cat := range observed.ParentalControl.Categories {
 			suggested.ParentalControl.Categories = append(suggested.ParentalControl.Categories, nextdnsv1alpha1.CategoryEntry{
 				ID:         cat.ID,
-				Active:     boolPtr(cat.Active),
-				Recreation: boolPtr(cat.Recreation),
+				Active:     ptr.To(cat.Active),
+				Recreation: ptr.To(cat.Recreation),
 			})
 		}
 		for _, svc := range observed.ParentalControl.Services {
 			suggested.ParentalControl.Services = append(suggested.ParentalControl.Services, nextdnsv1alpha1.ServiceEntry{
 				ID:     svc.ID,
-				Active: boolPtr(svc.Active),
+				Active: ptr.To(svc.Active),
 			})
 		}
 	}
@@ -1117,13 +1654,13 @@ func buildSuggestedSpec(observed *nextdnsv1alpha1.ObservedConfig) *nextdnsv1alph
 	for _, d := range observed.Denylist {
 		suggested.Denylist = append(suggested.Denylist, nextdnsv1alpha1.DomainEntry{
 			Domain: d.Domain,
-			Active: boolPtr(d.Active),
+			Active: ptr.To(d.Active),
 		})
 	}
 	for _, a := range observed.Allowlist {
 		suggested.Allowlist = append(suggested.Allowlist, nextdnsv1alpha1.DomainEntry{
 			Domain: a.Domain,
-			Active: boolPtr(a.Active),
+			Active: ptr.To(a.Active),
 		})
 	}
 
@@ -1132,35 +1669,35 @@ func buildSuggestedSpec(observed *nextdnsv1alpha1.ObservedConfig) *nextdnsv1alph
 		suggested.Rewrites = append(suggested.Rewrites, nextdnsv1alpha1.RewriteEntry{
 			From:   rw.Name,
 			To:     rw.Content,
-			Active: boolPtr(true),
+			Active: ptr.To(true),
 		})
 	}
 
 	// Settings: bool -> *bool, retention int -> string
 	if observed.Settings != nil {
 		suggested.Settings = &nextdnsv1alpha1.SettingsSpec{
-			Web3: boolPtr(observed.Settings.Web3),
-			BAV:  boolPtr(observed.Settings.BAV),
+			Web3: ptr.To(observed.Settings.Web3),
+			BAV:  ptr.To(observed.Settings.BAV),
 		}
 		if observed.Settings.Logs != nil {
 			suggested.Settings.Logs = &nextdnsv1alpha1.LogsSpec{
-				Enabled:       boolPtr(observed.Settings.Logs.Enabled),
-				Retention:     formatRetentionString(observed.Settings.Logs.Retention),
+				Enabled:       ptr.To(observed.Settings.Logs.Enabled),
+				Retention:     FormatRetention(observed.Settings.Logs.Retention),
 				Location:      observed.Settings.Logs.Location,
-				LogClientsIPs: boolPtr(observed.Settings.Logs.LogClientsIPs),
-				LogDomains:    boolPtr(observed.Settings.Logs.LogDomains),
+				LogClientsIPs: ptr.To(observed.Settings.Logs.LogClientsIPs),
+				LogDomains:    ptr.To(observed.Settings.Logs.LogDomains),
 			}
 		}
 		if observed.Settings.BlockPage != nil {
 			suggested.Settings.BlockPage = &nextdnsv1alpha1.BlockPageSpec{
-				Enabled: boolPtr(observed.Settings.BlockPage.Enabled),
+				Enabled: ptr.To(observed.Settings.BlockPage.Enabled),
 			}
 		}
 		if observed.Settings.Performance != nil {
 			suggested.Settings.Performance = &nextdnsv1alpha1.PerformanceSpec{
-				ECS:             boolPtr(observed.Settings.Performance.ECS),
-				CacheBoost:      boolPtr(observed.Settings.Performance.CacheBoost),
-				CNAMEFlattening: boolPtr(observed.Settings.Performance.CNAMEFlattening),
+				ECS:             ptr.To(observed.Settings.Performance.ECS),
+				CacheBoost:      ptr.To(observed.Settings.Performance.CacheBoost),
+				CNAMEFlattening: ptr.To(observed.Settings.Performance.CNAMEFlattening),
 			}
 		}
 	}
@@ -1205,7 +1742,8 @@ func specHasConfig(spec *nextdnsv1alpha1.NextDNSProfileSpec) bool {
 		len(spec.Rewrites) > 0 ||
 		len(spec.DenylistRefs) > 0 ||
 		len(spec.AllowlistRefs) > 0 ||
-		len(spec.TLDListRefs) > 0
+		len(spec.TLDListRefs) > 0 ||
+		len(spec.RewriteRefs) > 0
 }
 
 // reconcileConfigMap creates or updates the ConfigMap with connection details
@@ -1261,6 +1799,7 @@ func (r *NextDNSProfileReconciler) reconcileConfigMap(ctx context.Context, profi
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      configMapName,
 				Namespace: profile.Namespace,
+				Labels:    r.ResourceLabels.merge(nil),
 				OwnerReferences: []metav1.OwnerReference{
 					*metav1.NewControllerRef(profile, nextdnsv1alpha1.GroupVersion.WithKind("NextDNSProfile")),
 				},
@@ -1277,6 +1816,7 @@ func (r *NextDNSProfileReconciler) reconcileConfigMap(ctx context.Context, profi
 
 	// Update existing ConfigMap
 	existingConfigMap.Data = data
+	r.ResourceLabels.set(existingConfigMap)
 	// Ensure owner reference is set
 	if len(existingConfigMap.OwnerReferences) == 0 {
 		existingConfigMap.OwnerReferences = []metav1.OwnerReference{
@@ -1292,11 +1832,11 @@ func (r *NextDNSProfileReconciler) reconcileConfigMap(ctx context.Context, profi
 }
 
 // boolValue returns the value of a bool pointer, or the default if nil
-func boolValue(ptr *bool, defaultValue bool) bool {
-	if ptr == nil {
+func boolValue(value *bool, defaultValue bool) bool {
+	if value == nil {
 		return defaultValue
 	}
-	return *ptr
+	return *value
 }
 
 // parseRetentionSeconds parses a retention string (e.g., "7d", "1h") and returns seconds
@@ -1338,15 +1878,10 @@ func parseRetentionSeconds(retention string) int {
 	return 604800 // default 7 days in seconds
 }
 
-// boolPtr returns a pointer to a bool value
-func boolPtr(b bool) *bool {
-	return &b
-}
-
-// formatRetentionString converts a retention value in seconds (as returned by the
+// FormatRetention converts a retention value in seconds (as returned by the
 // NextDNS API) to the nearest valid CRD enum value.
 // Valid values: 1h, 6h, 1d, 7d, 30d, 90d, 1y, 2y
-func formatRetentionString(seconds int) string {
+func FormatRetention(seconds int) string {
 	switch {
 	case seconds <= 3600: // <= 1h
 		return "1h"
@@ -1367,116 +1902,97 @@ func formatRetentionString(seconds int) string {
 	}
 }
 
-// setCondition sets a condition on the profile
+// setCondition sets a condition on the profile, emitting an event when the
+// Ready condition changes
 func (r *NextDNSProfileReconciler) setCondition(profile *nextdnsv1alpha1.NextDNSProfile, conditionType string, status metav1.ConditionStatus, reason, message string) {
-	meta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+	condition := metav1.Condition{
 		Type:               conditionType,
 		Status:             status,
 		ObservedGeneration: profile.Generation,
 		LastTransitionTime: metav1.Now(),
 		Reason:             reason,
 		Message:            message,
-	})
+	}
+	if conditionType == ConditionTypeReady {
+		recordConditionEvent(r.Recorder, profile, profile.Status.Conditions, condition)
+	}
+	meta.SetStatusCondition(&profile.Status.Conditions, condition)
 }
 
 // findProfilesForAllowlist returns reconcile requests for profiles referencing the allowlist
 func (r *NextDNSProfileReconciler) findProfilesForAllowlist(ctx context.Context, obj client.Object) []reconcile.Request {
-	allowlist, ok := obj.(*nextdnsv1alpha1.NextDNSAllowlist)
-	if !ok {
+	if _, ok := obj.(*nextdnsv1alpha1.NextDNSAllowlist); !ok {
 		return nil
 	}
+	return r.findProfilesForList(ctx, listRefIndexKey(nextdnsv1alpha1.KindAllowlist, obj.GetNamespace(), obj.GetName()))
+}
 
-	var profiles nextdnsv1alpha1.NextDNSProfileList
-	if err := r.List(ctx, &profiles); err != nil {
-		log.FromContext(ctx).Error(err, "Failed to list profiles for allowlist watch")
+// findProfilesForDenylist returns reconcile requests for profiles referencing the denylist
+func (r *NextDNSProfileReconciler) findProfilesForDenylist(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*nextdnsv1alpha1.NextDNSDenylist); !ok {
 		return nil
 	}
-
-	var requests []reconcile.Request
-	for _, profile := range profiles.Items {
-		for _, ref := range profile.Spec.AllowlistRefs {
-			refNs := ref.Namespace
-			if refNs == "" {
-				refNs = profile.Namespace
-			}
-			if ref.Name == allowlist.Name && refNs == allowlist.Namespace {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      profile.Name,
-						Namespace: profile.Namespace,
-					},
-				})
-				break
-			}
-		}
-	}
-	return requests
+	return r.findProfilesForList(ctx, listRefIndexKey(nextdnsv1alpha1.KindDenylist, obj.GetNamespace(), obj.GetName()))
 }
 
-// findProfilesForDenylist returns reconcile requests for profiles referencing the denylist
-func (r *NextDNSProfileReconciler) findProfilesForDenylist(ctx context.Context, obj client.Object) []reconcile.Request {
-	denylist, ok := obj.(*nextdnsv1alpha1.NextDNSDenylist)
-	if !ok {
+// findProfilesForClusterAllowlist returns reconcile requests for profiles in any namespace referencing the cluster allowlist
+func (r *NextDNSProfileReconciler) findProfilesForClusterAllowlist(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*nextdnsv1alpha1.ClusterNextDNSAllowlist); !ok {
 		return nil
 	}
+	return r.findProfilesForList(ctx, listRefIndexKey(nextdnsv1alpha1.KindClusterAllowlist, "", obj.GetName()))
+}
 
-	var profiles nextdnsv1alpha1.NextDNSProfileList
-	if err := r.List(ctx, &profiles); err != nil {
-		log.FromContext(ctx).Error(err, "Failed to list profiles for denylist watch")
+// findProfilesForClusterDenylist returns reconcile requests for profiles in any namespace referencing the cluster denylist
+func (r *NextDNSProfileReconciler) findProfilesForClusterDenylist(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*nextdnsv1alpha1.ClusterNextDNSDenylist); !ok {
 		return nil
 	}
+	return r.findProfilesForList(ctx, listRefIndexKey(nextdnsv1alpha1.KindClusterDenylist, "", obj.GetName()))
+}
 
-	var requests []reconcile.Request
-	for _, profile := range profiles.Items {
-		for _, ref := range profile.Spec.DenylistRefs {
-			refNs := ref.Namespace
-			if refNs == "" {
-				refNs = profile.Namespace
-			}
-			if ref.Name == denylist.Name && refNs == denylist.Namespace {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      profile.Name,
-						Namespace: profile.Namespace,
-					},
-				})
-				break
-			}
-		}
+// findProfilesForDenylistSource returns reconcile requests for profiles referencing the denylist source
+func (r *NextDNSProfileReconciler) findProfilesForDenylistSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*nextdnsv1alpha1.NextDNSDenylistSource); !ok {
+		return nil
 	}
-	return requests
+	return r.findProfilesForList(ctx, listRefIndexKey(nextdnsv1alpha1.KindDenylistSource, obj.GetNamespace(), obj.GetName()))
 }
 
 // findProfilesForTLDList returns reconcile requests for profiles referencing the TLD list
 func (r *NextDNSProfileReconciler) findProfilesForTLDList(ctx context.Context, obj client.Object) []reconcile.Request {
-	tldList, ok := obj.(*nextdnsv1alpha1.NextDNSTLDList)
-	if !ok {
+	if _, ok := obj.(*nextdnsv1alpha1.NextDNSTLDList); !ok {
 		return nil
 	}
+	return r.findProfilesForList(ctx, listRefIndexKey("NextDNSTLDList", obj.GetNamespace(), obj.GetName()))
+}
 
+// findProfilesForRewrite returns reconcile requests for profiles referencing the rewrite list
+func (r *NextDNSProfileReconciler) findProfilesForRewrite(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.(*nextdnsv1alpha1.NextDNSRewrite); !ok {
+		return nil
+	}
+	return r.findProfilesForList(ctx, listRefIndexKey("NextDNSRewrite", obj.GetNamespace(), obj.GetName()))
+}
+
+// findProfilesForList returns reconcile requests for the profiles whose list
+// references include indexKey. Uses the list reference field index instead
+// of listing all profiles.
+func (r *NextDNSProfileReconciler) findProfilesForList(ctx context.Context, indexKey string) []reconcile.Request {
 	var profiles nextdnsv1alpha1.NextDNSProfileList
-	if err := r.List(ctx, &profiles); err != nil {
-		log.FromContext(ctx).Error(err, "Failed to list profiles for TLD list watch")
+	if err := r.List(ctx, &profiles, client.MatchingFields{listRefsIndexField: indexKey}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list profiles for list watch", "list", indexKey)
 		return nil
 	}
 
 	var requests []reconcile.Request
 	for _, profile := range profiles.Items {
-		for _, ref := range profile.Spec.TLDListRefs {
-			refNs := ref.Namespace
-			if refNs == "" {
-				refNs = profile.Namespace
-			}
-			if ref.Name == tldList.Name && refNs == tldList.Namespace {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      profile.Name,
-						Namespace: profile.Namespace,
-					},
-				})
-				break
-			}
-		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      profile.Name,
+				Namespace: profile.Namespace,
+			},
+		})
 	}
 	return requests
 }
@@ -1484,7 +2000,8 @@ func (r *NextDNSProfileReconciler) findProfilesForTLDList(ctx context.Context, o
 // findProfilesForSecret returns reconcile requests for profiles referencing the secret.
 // Uses a field index on credentialsRef for efficient lookups instead of listing all profiles.
 // Matches both same-namespace references (credentialsRef.namespace empty) and
-// cross-namespace references (credentialsRef.namespace explicitly set).
+// cross-namespace references (credentialsRef.namespace explicitly set), and
+// profiles using the default credentials Secret.
 func (r *NextDNSProfileReconciler) findProfilesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
 	secret, ok := obj.(*corev1.Secret)
 	if !ok {
@@ -1512,7 +2029,8 @@ func (r *NextDNSProfileReconciler) findProfilesForSecret(ctx context.Context, ob
 }
 
 // findProfilesForConfigMap returns reconcile requests for profiles that
-// reference the given ConfigMap via owner references (output ConfigMap from configMapRef)
+// own the given ConfigMap (output ConfigMap from configMapRef) or read it
+// (domains ConfigMap of a referenced NextDNSDenylistSource)
 func (r *NextDNSProfileReconciler) findProfilesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
 	configMap, ok := obj.(*corev1.ConfigMap)
 	if !ok {
@@ -1521,81 +2039,178 @@ func (r *NextDNSProfileReconciler) findProfilesForConfigMap(ctx context.Context,
 
 	var requests []reconcile.Request
 
-	// Check owner references (output ConfigMap from configMapRef)
 	for _, ref := range configMap.OwnerReferences {
-		if ref.Kind == "NextDNSProfile" && ref.APIVersion == nextdnsv1alpha1.GroupVersion.String() {
+		if ref.APIVersion != nextdnsv1alpha1.GroupVersion.String() {
+			continue
+		}
+		switch ref.Kind {
+		case "NextDNSProfile":
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      ref.Name,
 					Namespace: configMap.Namespace,
 				},
 			})
+		case nextdnsv1alpha1.KindDenylistSource:
+			// Recreating or rewriting the ConfigMap does not always change
+			// the source's status, so the source watch alone can miss it
+			requests = append(requests, r.findProfilesForList(ctx,
+				listRefIndexKey(nextdnsv1alpha1.KindDenylistSource, configMap.Namespace, ref.Name))...)
 		}
 	}
 
 	return requests
 }
 
+// metrics returns the metrics the reconciler records to
+func (r *NextDNSProfileReconciler) metrics() *metrics.Metrics {
+	if r.Metrics == nil {
+		return metrics.Default()
+	}
+	return r.Metrics
+}
+
 // updateResourceMetrics updates the gauge metrics for resource counts
 func (r *NextDNSProfileReconciler) updateResourceMetrics(ctx context.Context) {
 	// Count profiles
 	var profiles nextdnsv1alpha1.NextDNSProfileList
 	if err := r.List(ctx, &profiles); err == nil {
-		metrics.ProfilesTotal.Set(float64(len(profiles.Items)))
+		r.metrics().ProfilesTotal.Set(float64(len(profiles.Items)))
 	}
 
 	// Count allowlists
 	var allowlists nextdnsv1alpha1.NextDNSAllowlistList
 	if err := r.List(ctx, &allowlists); err == nil {
-		metrics.AllowlistsTotal.Set(float64(len(allowlists.Items)))
+		r.metrics().AllowlistsTotal.Set(float64(len(allowlists.Items)))
 	}
 
 	// Count denylists
 	var denylists nextdnsv1alpha1.NextDNSDenylistList
 	if err := r.List(ctx, &denylists); err == nil {
-		metrics.DenylistsTotal.Set(float64(len(denylists.Items)))
+		r.metrics().DenylistsTotal.Set(float64(len(denylists.Items)))
 	}
 
 	// Count TLD lists
 	var tldlists nextdnsv1alpha1.NextDNSTLDListList
 	if err := r.List(ctx, &tldlists); err == nil {
-		metrics.TLDListsTotal.Set(float64(len(tldlists.Items)))
+		r.metrics().TLDListsTotal.Set(float64(len(tldlists.Items)))
+	}
+
+	// Count rewrite lists
+	var rewrites nextdnsv1alpha1.NextDNSRewriteList
+	if err := r.List(ctx, &rewrites); err == nil {
+		r.metrics().RewritesTotal.Set(float64(len(rewrites.Items)))
+	}
+}
+
+// findProfilesForAccount returns reconcile requests for the profiles using
+// the credentials of the given NextDNSAccount
+func (r *NextDNSProfileReconciler) findProfilesForAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	indexKey := obj.GetNamespace() + "/" + obj.GetName()
+	if err := r.List(ctx, &profiles, client.MatchingFields{accountRefIndexField: indexKey}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list profiles for account watch")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(profiles.Items))
+	for _, profile := range profiles.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&profile)})
+	}
+	return requests
+}
+
+// accountCredentialsChangedPredicate passes account events that can change
+// the credentials of its profiles: spec edits, and the account validating a
+// new version of its Secret. Other status updates are filtered out.
+func accountCredentialsChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldAccount, ok := e.ObjectOld.(*nextdnsv1alpha1.NextDNSAccount)
+			if !ok {
+				return true
+			}
+			newAccount, ok := e.ObjectNew.(*nextdnsv1alpha1.NextDNSAccount)
+			if !ok {
+				return true
+			}
+			return oldAccount.Generation != newAccount.Generation ||
+				oldAccount.Status.CredentialsSecretVersion != newAccount.Status.CredentialsSecretVersion
+		},
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager
 func (r *NextDNSProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Register field index for efficient secret reference lookups
-	if err := mgr.GetFieldIndexer().IndexField(
-		context.Background(),
-		&nextdnsv1alpha1.NextDNSProfile{},
-		credentialsRefIndexField,
-		credentialsRefIndexFunc,
-	); err != nil {
-		return fmt.Errorf("failed to create field index for credentialsRef: %w", err)
+	r.listCache = newListCache()
+	if r.ListSources == nil {
+		r.ListSources = listsource.NewCache()
+	}
+	fanOut := newFanOutCoalescer(r.FanOutWindow)
+
+	// Register field indexes for efficient secret and list reference lookups
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSProfile{}, credentialsRefIndexField, credentialsRefIndexFunc); err != nil {
+		return err
+	}
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSProfile{}, accountRefIndexField, accountRefIndexFunc); err != nil {
+		return err
+	}
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc); err != nil {
+		return err
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&nextdnsv1alpha1.NextDNSProfile{}).
+		For(&nextdnsv1alpha1.NextDNSProfile{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		// Re-apply spec.deployCoreDNS when the deployed instance is edited or deleted
+		Owns(&nextdnsv1alpha1.NextDNSCoreDNS{}, ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSProfileList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
 		Watches(
 			&nextdnsv1alpha1.NextDNSAllowlist{},
-			handler.EnqueueRequestsFromMapFunc(r.findProfilesForAllowlist),
+			fanOut.handler(r.findProfilesForAllowlist),
 		).
 		Watches(
 			&nextdnsv1alpha1.NextDNSDenylist{},
-			handler.EnqueueRequestsFromMapFunc(r.findProfilesForDenylist),
+			fanOut.handler(r.findProfilesForDenylist),
+		).
+		Watches(
+			&nextdnsv1alpha1.ClusterNextDNSAllowlist{},
+			fanOut.handler(r.findProfilesForClusterAllowlist),
+		).
+		Watches(
+			&nextdnsv1alpha1.ClusterNextDNSDenylist{},
+			fanOut.handler(r.findProfilesForClusterDenylist),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSDenylistSource{},
+			fanOut.handler(r.findProfilesForDenylistSource),
 		).
 		Watches(
 			&nextdnsv1alpha1.NextDNSTLDList{},
-			handler.EnqueueRequestsFromMapFunc(r.findProfilesForTLDList),
+			fanOut.handler(r.findProfilesForTLDList),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSRewrite{},
+			fanOut.handler(r.findProfilesForRewrite),
 		).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findProfilesForSecret),
+			// Skip informer resyncs; an edited Secret is validated again at once
+			ctrlbuilder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSAccount{},
+			handler.EnqueueRequestsFromMapFunc(r.findProfilesForAccount),
+			ctrlbuilder.WithPredicates(accountCredentialsChangedPredicate()),
 		).
 		Watches(
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestsFromMapFunc(r.findProfilesForConfigMap),
 		).
+		WithOptions(controllerOptions("nextdnsprofile", r.Workqueue)).
 		Complete(r)
 }
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -53,13 +54,13 @@ func TestBoolValue(t *testing.T) {
 		},
 		{
 			name:         "true pointer returns true",
-			ptr:          boolPtr(true),
+			ptr:          ptr.To(true),
 			defaultValue: false,
 			expected:     true,
 		},
 		{
 			name:         "false pointer returns false",
-			ptr:          boolPtr(false),
+			ptr:          ptr.To(false),
 			defaultValue: true,
 			expected:     false,
 		},
@@ -283,7 +284,7 @@ func TestGetAPIKey(t *testing.T) {
 				Scheme: scheme,
 			}
 
-			apiKey, err := reconciler.getAPIKey(ctx, tt.profile)
+			apiKey, _, err := reconciler.getAPIKey(ctx, tt.profile)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -308,8 +309,8 @@ func TestResolveListReferences(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSAllowlistSpec{
 			Domains: []nextdnsv1alpha1.DomainEntry{
 				{Domain: "allowed1.com"},
-				{Domain: "allowed2.com", Active: boolPtr(true)},
-				{Domain: "inactive.com", Active: boolPtr(false)},
+				{Domain: "allowed2.com", Active: ptr.To(true)},
+				{Domain: "inactive.com", Active: ptr.To(false)},
 			},
 		},
 	}
@@ -337,8 +338,8 @@ func TestResolveListReferences(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSTLDListSpec{
 			TLDs: []nextdnsv1alpha1.TLDEntry{
 				{TLD: "xyz"},
-				{TLD: "tk", Active: boolPtr(true)},
-				{TLD: "ml", Active: boolPtr(false)},
+				{TLD: "tk", Active: ptr.To(true)},
+				{TLD: "ml", Active: ptr.To(false)},
 			},
 		},
 	}
@@ -415,6 +416,166 @@ func TestResolveListReferences(t *testing.T) {
 	assert.Equal(t, 2, resolved.ResourceStatus.TLDLists[0].Count)
 }
 
+func TestResolveListReferences_Rewrites(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	rewriteList := &nextdnsv1alpha1.NextDNSRewrite{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "home-rewrites",
+			Namespace: "dns",
+		},
+		Spec: nextdnsv1alpha1.NextDNSRewriteSpec{
+			Rewrites: []nextdnsv1alpha1.RewriteEntry{
+				{From: "nas.home", To: "192.168.1.10"},
+				{From: "printer.home", To: "192.168.1.20", Active: ptr.To(false)},
+				{From: "router.home", To: "192.168.1.1"},
+			},
+		},
+	}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "default",
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name: "Test Profile",
+			RewriteRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "home-rewrites", Namespace: "dns"},
+			},
+			Rewrites: []nextdnsv1alpha1.RewriteEntry{
+				{From: "app.home", To: "10.0.0.5"},
+				{From: "nas.home", To: "192.168.1.10"}, // duplicate of referenced entry
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(rewriteList, profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+	}
+
+	resolved, err := reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+
+	assert.Equal(t, []nextdns.RewriteEntry{
+		{Name: "nas.home", Content: "192.168.1.10"},
+		{Name: "router.home", Content: "192.168.1.1"},
+		{Name: "app.home", Content: "10.0.0.5"},
+	}, resolved.Rewrites)
+
+	require.Equal(t, 1, len(resolved.ResourceStatus.Rewrites))
+	assert.Equal(t, "home-rewrites", resolved.ResourceStatus.Rewrites[0].Name)
+	assert.Equal(t, "dns", resolved.ResourceStatus.Rewrites[0].Namespace)
+	assert.Equal(t, 2, resolved.ResourceStatus.Rewrites[0].Count)
+
+	// Without inline rewrites or refs, remote rewrites are left untouched
+	profile.Spec.Rewrites = nil
+	profile.Spec.RewriteRefs = nil
+	resolved, err = reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	assert.Nil(t, resolved.Rewrites)
+
+	// An explicit empty list clears remote rewrites
+	profile.Spec.Rewrites = []nextdnsv1alpha1.RewriteEntry{}
+	resolved, err = reconciler.resolveListReferences(ctx, profile)
+	require.NoError(t, err)
+	assert.NotNil(t, resolved.Rewrites)
+	assert.Empty(t, resolved.Rewrites)
+
+	// Missing rewrite list fails resolution
+	profile.Spec.RewriteRefs = []nextdnsv1alpha1.ListReference{{Name: "missing"}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get rewrite list default/missing")
+}
+
+func TestSyncWithNextDNS_ResolvedRewrites(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-profile", Namespace: "default"},
+		Spec:       nextdnsv1alpha1.NextDNSProfileSpec{Name: "Test Profile", ProfileID: "abc123"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()
+
+	t.Run("syncs resolved rewrites", func(t *testing.T) {
+		mockClient := newMockNextDNSClient()
+		reconciler := &NextDNSProfileReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+				return mockClient, nil
+			},
+		}
+
+		lists := &ResolvedLists{Rewrites: []nextdns.RewriteEntry{{Name: "nas.home", Content: "192.168.1.10"}}}
+		require.NoError(t, reconciler.syncWithNextDNS(ctx, profile, "test-api-key", lists))
+
+		assert.True(t, mockClient.syncRewritesCalled)
+		assert.Equal(t, lists.Rewrites, mockClient.rewriteEntries)
+	})
+
+	t.Run("nil rewrites leave remote untouched", func(t *testing.T) {
+		mockClient := newMockNextDNSClient()
+		reconciler := &NextDNSProfileReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+				return mockClient, nil
+			},
+		}
+
+		require.NoError(t, reconciler.syncWithNextDNS(ctx, profile, "test-api-key", &ResolvedLists{}))
+		assert.False(t, mockClient.syncRewritesCalled)
+	})
+}
+
+func TestFindProfilesForRewrite(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	rewriteList := &nextdnsv1alpha1.NextDNSRewrite{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-rewrites", Namespace: "dns"},
+	}
+	referencing := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			RewriteRefs: []nextdnsv1alpha1.ListReference{{Name: "shared-rewrites", Namespace: "dns"}},
+		},
+	}
+	sameNameOtherNamespace := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			RewriteRefs: []nextdnsv1alpha1.ListReference{{Name: "shared-rewrites"}},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(rewriteList, referencing, sameNameOtherNamespace).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+
+	requests := reconciler.findProfilesForRewrite(ctx, rewriteList)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "home", requests[0].Name)
+	assert.Equal(t, "default", requests[0].Namespace)
+
+	assert.Nil(t, reconciler.findProfilesForRewrite(ctx, &corev1.Secret{}))
+}
+
 func TestResolveListReferences_MissingResource(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.Background()
@@ -532,6 +693,7 @@ func TestFindProfilesForAllowlist(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(allowlist, profile1, profile2).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
 		Build()
 
 	reconciler := &NextDNSProfileReconciler{
@@ -573,6 +735,7 @@ func TestFindProfilesForDenylist(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(denylist, profile).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
 		Build()
 
 	reconciler := &NextDNSProfileReconciler{
@@ -613,6 +776,7 @@ func TestFindProfilesForTLDList(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(tldList, profile).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
 		Build()
 
 	reconciler := &NextDNSProfileReconciler{
@@ -1038,6 +1202,7 @@ func TestFindProfilesForAllowlist_MultipleProfiles(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(allowlist, profile1, profile2).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
 		Build()
 
 	reconciler := &NextDNSProfileReconciler{
@@ -1144,8 +1309,9 @@ func TestSyncWithNextDNS_AdoptExistingProfile(t *testing.T) {
 			Namespace: "default",
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Name:      "Adopted Profile",
-			ProfileID: "existing-profile-123",
+			Name:           "Adopted Profile",
+			ProfileID:      "existing-profile-123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 		},
 		Status: nextdnsv1alpha1.NextDNSProfileStatus{},
 	}
@@ -1193,9 +1359,9 @@ func TestSyncWithNextDNS_WithSecuritySettings(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
 			Name: "Security Profile",
 			Security: &nextdnsv1alpha1.SecuritySpec{
-				AIThreatDetection:  boolPtr(true),
-				GoogleSafeBrowsing: boolPtr(true),
-				Cryptojacking:      boolPtr(false),
+				AIThreatDetection:  ptr.To(true),
+				GoogleSafeBrowsing: ptr.To(true),
+				Cryptojacking:      ptr.To(false),
 			},
 		},
 		Status: nextdnsv1alpha1.NextDNSProfileStatus{
@@ -1242,11 +1408,11 @@ func TestSyncWithNextDNS_WithPrivacySettings(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
 			Name: "Privacy Profile",
 			Privacy: &nextdnsv1alpha1.PrivacySpec{
-				DisguisedTrackers: boolPtr(true),
-				AllowAffiliate:    boolPtr(false),
+				DisguisedTrackers: ptr.To(true),
+				AllowAffiliate:    ptr.To(false),
 				Blocklists: []nextdnsv1alpha1.BlocklistEntry{
 					{ID: "blocklist-1"},
-					{ID: "blocklist-2", Active: boolPtr(false)},
+					{ID: "blocklist-2", Active: ptr.To(false)},
 				},
 				Natives: []nextdnsv1alpha1.NativeEntry{
 					{ID: "apple"},
@@ -1298,13 +1464,13 @@ func TestSyncWithNextDNS_WithParentalControl(t *testing.T) {
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
 			Name: "Parental Profile",
 			ParentalControl: &nextdnsv1alpha1.ParentalControlSpec{
-				SafeSearch:            boolPtr(true),
-				YouTubeRestrictedMode: boolPtr(true),
-				BlockBypass:           boolPtr(true),
+				SafeSearch:            ptr.To(true),
+				YouTubeRestrictedMode: ptr.To(true),
+				BlockBypass:           ptr.To(true),
 				Categories: []nextdnsv1alpha1.CategoryEntry{
 					{ID: "adult"},
-					{ID: "gambling", Active: boolPtr(true)},
-					{ID: "drugs", Active: boolPtr(false)},
+					{ID: "gambling", Active: ptr.To(true)},
+					{ID: "drugs", Active: ptr.To(false)},
 				},
 				Services: []nextdnsv1alpha1.ServiceEntry{
 					{ID: "tiktok"},
@@ -1359,11 +1525,11 @@ func TestSyncWithNextDNS_WithSettings(t *testing.T) {
 			Name: "Settings Profile",
 			Settings: &nextdnsv1alpha1.SettingsSpec{
 				Logs: &nextdnsv1alpha1.LogsSpec{
-					Enabled:   boolPtr(true),
+					Enabled:   ptr.To(true),
 					Retention: "30d",
 				},
 				BlockPage: &nextdnsv1alpha1.BlockPageSpec{
-					Enabled: boolPtr(true),
+					Enabled: ptr.To(true),
 				},
 			},
 		},
@@ -1587,6 +1753,75 @@ func TestHandleDeletion_MissingCredentials(t *testing.T) {
 	assert.NotContains(t, profile.Finalizers, FinalizerName)
 }
 
+func TestHandleDeletion_InvalidCredentials(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name: "Test Profile",
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
+				Name: "nextdns-secret",
+			},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:         "profile-123",
+			CredentialsSecret: "default/nextdns-secret",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("rotated-api-key")},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.Profiles["profile-123"] = &sdknextdns.Profile{Name: "Test Profile"}
+	mockNDS.DeleteProfileError = &sdknextdns.Error{Type: sdknextdns.ErrorTypeAuthentication}
+
+	reconciler := &NextDNSProfileReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+
+	// A rejected key keeps the finalizer so the NextDNS profile is not orphaned
+	result, err := reconciler.handleDeletion(ctx, profile)
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, result.RequeueAfter)
+	assert.Contains(t, profile.Finalizers, FinalizerName)
+
+	updated := &nextdnsv1alpha1.NextDNSProfile{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-profile", Namespace: "default"}, updated))
+	assert.Contains(t, updated.Finalizers, FinalizerName)
+	for _, condType := range []string{ConditionTypeReady, ConditionTypeCredentialsValid} {
+		cond := findCondition(updated.Status.Conditions, condType)
+		require.NotNil(t, cond, condType)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status, condType)
+		assert.Equal(t, ReasonInvalidCredentials, cond.Reason, condType)
+	}
+
+	// Once the key is fixed the profile is deleted and the finalizer removed
+	mockNDS.DeleteProfileError = nil
+	result, err = reconciler.handleDeletion(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+	assert.NotContains(t, mockNDS.Profiles, "profile-123")
+	assert.NotContains(t, updated.Finalizers, FinalizerName)
+}
+
 func TestReconcile_FullFlow_WithMock(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.Background()
@@ -1615,10 +1850,10 @@ func TestReconcile_FullFlow_WithMock(t *testing.T) {
 				Name: "nextdns-secret",
 			},
 			Security: &nextdnsv1alpha1.SecuritySpec{
-				AIThreatDetection: boolPtr(true),
+				AIThreatDetection: ptr.To(true),
 			},
 			Privacy: &nextdnsv1alpha1.PrivacySpec{
-				DisguisedTrackers: boolPtr(true),
+				DisguisedTrackers: ptr.To(true),
 			},
 			Allowlist: []nextdnsv1alpha1.DomainEntry{
 				{Domain: "allowed.com"},
@@ -1667,6 +1902,7 @@ func TestReconcile_FullFlow_WithMock(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotEmpty(t, updatedProfile.Status.ProfileID)
 	assert.NotNil(t, updatedProfile.Status.LastSyncTime)
+	assert.Equal(t, nextdns.AccountFingerprint("test-api-key"), updatedProfile.Status.AccountFingerprint)
 }
 
 func TestReconcile_FailedSync(t *testing.T) {
@@ -1803,6 +2039,7 @@ type mockNextDNSClient struct {
 	syncSecurityTLDsCalled      bool
 	syncPrivacyBlocklistsCalled bool
 	syncPrivacyNativesCalled    bool
+	syncRewritesCalled          bool
 
 	// Captured values
 	createdProfileName    string
@@ -1814,10 +2051,13 @@ type mockNextDNSClient struct {
 	blocklists            []string
 	natives               []string
 	denylistEntries       []nextdns.DomainEntry
+	denylistOpts          nextdns.ListSyncOptions
+	rewriteEntries        []nextdns.RewriteEntry
 
 	// Error injection
 	createProfileError error
 	getProfileError    error
+	validateKeyError   error
 
 	// Profile counter for generating IDs
 	profileCounter int
@@ -1903,13 +2143,14 @@ func (m *mockNextDNSClient) GetParentalControl(ctx context.Context, profileID st
 	return &sdknextdns.ParentalControl{}, nil
 }
 
-func (m *mockNextDNSClient) SyncDenylist(ctx context.Context, profileID string, entries []nextdns.DomainEntry) error {
+func (m *mockNextDNSClient) SyncDenylist(ctx context.Context, profileID string, entries []nextdns.DomainEntry, opts nextdns.ListSyncOptions) error {
 	m.syncDenylistCalled = true
 	m.denylistEntries = entries
+	m.denylistOpts = opts
 	return nil
 }
 
-func (m *mockNextDNSClient) SyncAllowlist(ctx context.Context, profileID string, entries []nextdns.DomainEntry) error {
+func (m *mockNextDNSClient) SyncAllowlist(ctx context.Context, profileID string, entries []nextdns.DomainEntry, opts nextdns.ListSyncOptions) error {
 	m.syncAllowlistCalled = true
 	return nil
 }
@@ -1989,18 +2230,32 @@ func (m *mockNextDNSClient) GetParentalControlServices(ctx context.Context, prof
 	return []*sdknextdns.ParentalControlServices{}, nil
 }
 
-func (m *mockNextDNSClient) SyncRewrites(ctx context.Context, profileID string, entries []nextdns.RewriteEntry) error {
-	return nil
+func (m *mockNextDNSClient) SyncRewrites(ctx context.Context, profileID string, entries []nextdns.RewriteEntry) ([]nextdns.RewriteResult, error) {
+	m.syncRewritesCalled = true
+	m.rewriteEntries = entries
+	return nil, nil
 }
 
 func (m *mockNextDNSClient) GetRewrites(ctx context.Context, profileID string) ([]*sdknextdns.Rewrites, error) {
 	return []*sdknextdns.Rewrites{}, nil
 }
 
+func (m *mockNextDNSClient) ListProfiles(ctx context.Context) ([]*sdknextdns.ProfileSummary, error) {
+	return nil, nil
+}
+
+func (m *mockNextDNSClient) ValidateKey(ctx context.Context) error {
+	return m.validateKeyError
+}
+
 func (m *mockNextDNSClient) GetSetup(ctx context.Context, profileID string) (*sdknextdns.Setup, error) {
 	return &sdknextdns.Setup{}, nil
 }
 
+func (m *mockNextDNSClient) GetTopBlockedDomains(ctx context.Context, profileID string, from time.Time, limit int) ([]*sdknextdns.AnalyticsEntry, error) {
+	return nil, nil
+}
+
 func TestReconcileConfigMap(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.Background()
@@ -2345,9 +2600,29 @@ func TestFindProfilesForConfigMap(t *testing.T) {
 		},
 	}
 
+	// Domains ConfigMap of a denylist source the profile references
+	sourceConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "oisd-domains",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: nextdnsv1alpha1.GroupVersion.String(),
+					Kind:       nextdnsv1alpha1.KindDenylistSource,
+					Name:       "oisd",
+					UID:        "source-uid-123",
+				},
+			},
+		},
+	}
+	profile.Spec.DenylistRefs = []nextdnsv1alpha1.ListReference{
+		{Name: "oisd", Kind: nextdnsv1alpha1.KindDenylistSource},
+	}
+
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(profile).
+		WithIndex(&nextdnsv1alpha1.NextDNSProfile{}, listRefsIndexField, listRefsIndexFunc).
 		Build()
 
 	reconciler := &NextDNSProfileReconciler{
@@ -2355,6 +2630,13 @@ func TestFindProfilesForConfigMap(t *testing.T) {
 		Scheme: scheme,
 	}
 
+	t.Run("referenced denylist source ConfigMap triggers reconcile", func(t *testing.T) {
+		requests := reconciler.findProfilesForConfigMap(ctx, sourceConfigMap)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "test-profile", requests[0].Name)
+		assert.Equal(t, "default", requests[0].Namespace)
+	})
+
 	t.Run("owned ConfigMap triggers reconcile", func(t *testing.T) {
 		requests := reconciler.findProfilesForConfigMap(ctx, ownedConfigMap)
 		require.Len(t, requests, 1)
@@ -2586,29 +2868,29 @@ func TestReconcile_ObserveMode_Success(t *testing.T) {
 	// Verify suggestedSpec parental control has BlockBypass and category Recreation
 	require.NotNil(t, updated.Status.SuggestedSpec)
 	require.NotNil(t, updated.Status.SuggestedSpec.ParentalControl)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.ParentalControl.BlockBypass)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.ParentalControl.BlockBypass)
 	require.Equal(t, 2, len(updated.Status.SuggestedSpec.ParentalControl.Categories))
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.ParentalControl.Categories[0].Recreation)
-	assert.Equal(t, boolPtr(false), updated.Status.SuggestedSpec.ParentalControl.Categories[1].Recreation)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.ParentalControl.Categories[0].Recreation)
+	assert.Equal(t, ptr.To(false), updated.Status.SuggestedSpec.ParentalControl.Categories[1].Recreation)
 
 	// Verify suggestedSpec was populated
 	require.NotNil(t, updated.Status.SuggestedSpec)
 	assert.Equal(t, "Remote Profile", updated.Status.SuggestedSpec.Name)
 	require.NotNil(t, updated.Status.SuggestedSpec.Security)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Security.AIThreatDetection)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Security.GoogleSafeBrowsing)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Security.AIThreatDetection)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Security.GoogleSafeBrowsing)
 	require.NotNil(t, updated.Status.SuggestedSpec.Privacy)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Privacy.DisguisedTrackers)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Privacy.DisguisedTrackers)
 	require.Equal(t, 1, len(updated.Status.SuggestedSpec.Denylist))
 	assert.Equal(t, "bad.com", updated.Status.SuggestedSpec.Denylist[0].Domain)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Denylist[0].Active)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Denylist[0].Active)
 	require.Equal(t, 1, len(updated.Status.SuggestedSpec.Allowlist))
 	require.NotNil(t, updated.Status.SuggestedSpec.Settings)
 	require.NotNil(t, updated.Status.SuggestedSpec.Settings.Logs)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Settings.Logs.Enabled)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Settings.Logs.Enabled)
 	assert.Equal(t, "7d", updated.Status.SuggestedSpec.Settings.Logs.Retention)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Settings.Logs.LogClientsIPs)
-	assert.Equal(t, boolPtr(true), updated.Status.SuggestedSpec.Settings.Logs.LogDomains)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Settings.Logs.LogClientsIPs)
+	assert.Equal(t, ptr.To(true), updated.Status.SuggestedSpec.Settings.Logs.LogDomains)
 	assert.Equal(t, "eu", updated.Status.SuggestedSpec.Settings.Logs.Location)
 
 	// Verify conditions
@@ -2694,9 +2976,10 @@ func TestReconcile_TransitionBlocked(t *testing.T) {
 			Finalizers: []string{FinalizerName},
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Mode:      nextdnsv1alpha1.ProfileModeManaged,
-			Name:      "Test Profile",
-			ProfileID: "abc123",
+			Mode:           nextdnsv1alpha1.ProfileModeManaged,
+			Name:           "Test Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
 				Name: "nextdns-secret",
 			},
@@ -3173,7 +3456,7 @@ func TestFormatRetentionString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatRetentionString(tt.seconds)
+			result := FormatRetention(tt.seconds)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -3251,80 +3534,80 @@ func TestBuildSuggestedSpec(t *testing.T) {
 
 	// Security: bool -> *bool
 	require.NotNil(t, suggested.Security)
-	assert.Equal(t, boolPtr(true), suggested.Security.AIThreatDetection)
-	assert.Equal(t, boolPtr(true), suggested.Security.GoogleSafeBrowsing)
-	assert.Equal(t, boolPtr(false), suggested.Security.Cryptojacking)
-	assert.Equal(t, boolPtr(true), suggested.Security.DNSRebinding)
-	assert.Equal(t, boolPtr(true), suggested.Security.IDNHomographs)
-	assert.Equal(t, boolPtr(true), suggested.Security.Typosquatting)
-	assert.Equal(t, boolPtr(true), suggested.Security.DGA)
-	assert.Equal(t, boolPtr(false), suggested.Security.NRD)
-	assert.Equal(t, boolPtr(false), suggested.Security.DDNS)
-	assert.Equal(t, boolPtr(true), suggested.Security.Parking)
-	assert.Equal(t, boolPtr(true), suggested.Security.CSAM)
-	assert.Equal(t, boolPtr(true), suggested.Security.ThreatIntelligenceFeeds)
+	assert.Equal(t, ptr.To(true), suggested.Security.AIThreatDetection)
+	assert.Equal(t, ptr.To(true), suggested.Security.GoogleSafeBrowsing)
+	assert.Equal(t, ptr.To(false), suggested.Security.Cryptojacking)
+	assert.Equal(t, ptr.To(true), suggested.Security.DNSRebinding)
+	assert.Equal(t, ptr.To(true), suggested.Security.IDNHomographs)
+	assert.Equal(t, ptr.To(true), suggested.Security.Typosquatting)
+	assert.Equal(t, ptr.To(true), suggested.Security.DGA)
+	assert.Equal(t, ptr.To(false), suggested.Security.NRD)
+	assert.Equal(t, ptr.To(false), suggested.Security.DDNS)
+	assert.Equal(t, ptr.To(true), suggested.Security.Parking)
+	assert.Equal(t, ptr.To(true), suggested.Security.CSAM)
+	assert.Equal(t, ptr.To(true), suggested.Security.ThreatIntelligenceFeeds)
 
 	// Privacy: bool -> *bool, blocklists/natives get Active: true
 	require.NotNil(t, suggested.Privacy)
-	assert.Equal(t, boolPtr(true), suggested.Privacy.DisguisedTrackers)
-	assert.Equal(t, boolPtr(false), suggested.Privacy.AllowAffiliate)
+	assert.Equal(t, ptr.To(true), suggested.Privacy.DisguisedTrackers)
+	assert.Equal(t, ptr.To(false), suggested.Privacy.AllowAffiliate)
 	require.Equal(t, 2, len(suggested.Privacy.Blocklists))
 	assert.Equal(t, "nextdns-recommended", suggested.Privacy.Blocklists[0].ID)
-	assert.Equal(t, boolPtr(true), suggested.Privacy.Blocklists[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.Privacy.Blocklists[0].Active)
 	assert.Equal(t, "oisd", suggested.Privacy.Blocklists[1].ID)
-	assert.Equal(t, boolPtr(true), suggested.Privacy.Blocklists[1].Active)
+	assert.Equal(t, ptr.To(true), suggested.Privacy.Blocklists[1].Active)
 	require.Equal(t, 2, len(suggested.Privacy.Natives))
 	assert.Equal(t, "apple", suggested.Privacy.Natives[0].ID)
-	assert.Equal(t, boolPtr(true), suggested.Privacy.Natives[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.Privacy.Natives[0].Active)
 
 	// ParentalControl: bool -> *bool, categories/services preserve Active
 	require.NotNil(t, suggested.ParentalControl)
-	assert.Equal(t, boolPtr(true), suggested.ParentalControl.SafeSearch)
-	assert.Equal(t, boolPtr(false), suggested.ParentalControl.YouTubeRestrictedMode)
-	assert.Equal(t, boolPtr(true), suggested.ParentalControl.BlockBypass)
+	assert.Equal(t, ptr.To(true), suggested.ParentalControl.SafeSearch)
+	assert.Equal(t, ptr.To(false), suggested.ParentalControl.YouTubeRestrictedMode)
+	assert.Equal(t, ptr.To(true), suggested.ParentalControl.BlockBypass)
 	require.Equal(t, 2, len(suggested.ParentalControl.Categories))
 	assert.Equal(t, "gambling", suggested.ParentalControl.Categories[0].ID)
-	assert.Equal(t, boolPtr(true), suggested.ParentalControl.Categories[0].Active)
-	assert.Equal(t, boolPtr(true), suggested.ParentalControl.Categories[0].Recreation)
+	assert.Equal(t, ptr.To(true), suggested.ParentalControl.Categories[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.ParentalControl.Categories[0].Recreation)
 	assert.Equal(t, "adult", suggested.ParentalControl.Categories[1].ID)
-	assert.Equal(t, boolPtr(false), suggested.ParentalControl.Categories[1].Active)
-	assert.Equal(t, boolPtr(false), suggested.ParentalControl.Categories[1].Recreation)
+	assert.Equal(t, ptr.To(false), suggested.ParentalControl.Categories[1].Active)
+	assert.Equal(t, ptr.To(false), suggested.ParentalControl.Categories[1].Recreation)
 	require.Equal(t, 1, len(suggested.ParentalControl.Services))
 	assert.Equal(t, "tiktok", suggested.ParentalControl.Services[0].ID)
-	assert.Equal(t, boolPtr(true), suggested.ParentalControl.Services[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.ParentalControl.Services[0].Active)
 
 	// Denylist/Allowlist: Active preserved as *bool
 	require.Equal(t, 2, len(suggested.Denylist))
 	assert.Equal(t, "bad.com", suggested.Denylist[0].Domain)
-	assert.Equal(t, boolPtr(true), suggested.Denylist[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.Denylist[0].Active)
 	assert.Equal(t, "worse.com", suggested.Denylist[1].Domain)
-	assert.Equal(t, boolPtr(false), suggested.Denylist[1].Active)
+	assert.Equal(t, ptr.To(false), suggested.Denylist[1].Active)
 	require.Equal(t, 1, len(suggested.Allowlist))
 	assert.Equal(t, "good.com", suggested.Allowlist[0].Domain)
-	assert.Equal(t, boolPtr(true), suggested.Allowlist[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.Allowlist[0].Active)
 
 	// Settings: retention int -> string, bool -> *bool
 	require.NotNil(t, suggested.Settings)
 	require.NotNil(t, suggested.Settings.Logs)
-	assert.Equal(t, boolPtr(true), suggested.Settings.Logs.Enabled)
+	assert.Equal(t, ptr.To(true), suggested.Settings.Logs.Enabled)
 	assert.Equal(t, "30d", suggested.Settings.Logs.Retention)
-	assert.Equal(t, boolPtr(true), suggested.Settings.Logs.LogClientsIPs)
-	assert.Equal(t, boolPtr(false), suggested.Settings.Logs.LogDomains)
+	assert.Equal(t, ptr.To(true), suggested.Settings.Logs.LogClientsIPs)
+	assert.Equal(t, ptr.To(false), suggested.Settings.Logs.LogDomains)
 	assert.Equal(t, "eu", suggested.Settings.Logs.Location)
 	require.NotNil(t, suggested.Settings.BlockPage)
-	assert.Equal(t, boolPtr(true), suggested.Settings.BlockPage.Enabled)
+	assert.Equal(t, ptr.To(true), suggested.Settings.BlockPage.Enabled)
 	require.NotNil(t, suggested.Settings.Performance)
-	assert.Equal(t, boolPtr(true), suggested.Settings.Performance.ECS)
-	assert.Equal(t, boolPtr(true), suggested.Settings.Performance.CacheBoost)
-	assert.Equal(t, boolPtr(false), suggested.Settings.Performance.CNAMEFlattening)
-	assert.Equal(t, boolPtr(true), suggested.Settings.Web3)
-	assert.Equal(t, boolPtr(true), suggested.Settings.BAV)
+	assert.Equal(t, ptr.To(true), suggested.Settings.Performance.ECS)
+	assert.Equal(t, ptr.To(true), suggested.Settings.Performance.CacheBoost)
+	assert.Equal(t, ptr.To(false), suggested.Settings.Performance.CNAMEFlattening)
+	assert.Equal(t, ptr.To(true), suggested.Settings.Web3)
+	assert.Equal(t, ptr.To(true), suggested.Settings.BAV)
 
 	// Rewrites: ObservedRewriteEntry (Name/Content) -> RewriteEntry (From/To)
 	require.Equal(t, 1, len(suggested.Rewrites))
 	assert.Equal(t, "app.example.com", suggested.Rewrites[0].From)
 	assert.Equal(t, "192.168.1.1", suggested.Rewrites[0].To)
-	assert.Equal(t, boolPtr(true), suggested.Rewrites[0].Active)
+	assert.Equal(t, ptr.To(true), suggested.Rewrites[0].Active)
 
 	// BlockedTLDs
 	assert.Equal(t, []string{"xyz", "tk"}, suggested.BlockedTLDs)
@@ -3363,14 +3646,15 @@ func TestReconcile_TransitionClearsSuggestedSpec(t *testing.T) {
 			Finalizers: []string{FinalizerName},
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Mode:      nextdnsv1alpha1.ProfileModeManaged,
-			Name:      "Test Profile",
-			ProfileID: "abc123",
+			Mode:           nextdnsv1alpha1.ProfileModeManaged,
+			Name:           "Test Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
 				Name: "nextdns-secret",
 			},
 			Security: &nextdnsv1alpha1.SecuritySpec{
-				AIThreatDetection: boolPtr(true),
+				AIThreatDetection: ptr.To(true),
 			},
 		},
 		Status: nextdnsv1alpha1.NextDNSProfileStatus{
@@ -3435,29 +3719,30 @@ func TestSyncWithNextDNS_FullSettings(t *testing.T) {
 			Finalizers: []string{FinalizerName},
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Name:      "Full Settings Profile",
-			ProfileID: "abc123",
+			Name:           "Full Settings Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
 				Name: "nextdns-secret",
 			},
 			Settings: &nextdnsv1alpha1.SettingsSpec{
 				Logs: &nextdnsv1alpha1.LogsSpec{
-					Enabled:       boolPtr(true),
-					LogClientsIPs: boolPtr(true),
-					LogDomains:    boolPtr(false),
+					Enabled:       ptr.To(true),
+					LogClientsIPs: ptr.To(true),
+					LogDomains:    ptr.To(false),
 					Retention:     "30d",
 					Location:      "ch",
 				},
 				BlockPage: &nextdnsv1alpha1.BlockPageSpec{
-					Enabled: boolPtr(true),
+					Enabled: ptr.To(true),
 				},
 				Performance: &nextdnsv1alpha1.PerformanceSpec{
-					ECS:             boolPtr(true),
-					CacheBoost:      boolPtr(false),
-					CNAMEFlattening: boolPtr(true),
+					ECS:             ptr.To(true),
+					CacheBoost:      ptr.To(false),
+					CNAMEFlattening: ptr.To(true),
 				},
-				Web3: boolPtr(true),
-				BAV:  boolPtr(true),
+				Web3: ptr.To(true),
+				BAV:  ptr.To(true),
 			},
 		},
 	}
@@ -3526,15 +3811,16 @@ func TestSyncWithNextDNS_Rewrites(t *testing.T) {
 			Finalizers: []string{FinalizerName},
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Name:      "Rewrites Profile",
-			ProfileID: "abc123",
+			Name:           "Rewrites Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
 				Name: "nextdns-secret",
 			},
 			Rewrites: []nextdnsv1alpha1.RewriteEntry{
 				{From: "app.example.com", To: "192.168.1.1"},
 				{From: "api.example.com", To: "192.168.1.2"},
-				{From: "inactive.example.com", To: "10.0.0.1", Active: boolPtr(false)},
+				{From: "inactive.example.com", To: "10.0.0.1", Active: ptr.To(false)},
 			},
 		},
 	}
@@ -3578,6 +3864,69 @@ func TestSyncWithNextDNS_Rewrites(t *testing.T) {
 	require.Equal(t, 2, len(rewrites))
 }
 
+func TestReconcile_RewriteStatus(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "lan",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "LAN Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Rewrites: []nextdnsv1alpha1.RewriteEntry{
+				{From: "nas.home", To: "192.168.1.10"},
+				{From: "printer.home", To: "nas.home"},
+				{From: "bad.home", To: "192.168.1.300"},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "LAN Profile", "abc123.dns.nextdns.io")
+	mockNDS.RejectedRewrites = map[string]string{"bad.home": "invalid content"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "lan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "lan", Namespace: "default"}, &updated))
+	assert.Equal(t, []nextdnsv1alpha1.RewriteStatus{
+		{Name: "nas.home", Content: "192.168.1.10", State: nextdnsv1alpha1.RewriteStateApplied, Type: "A"},
+		{Name: "printer.home", Content: "nas.home", State: nextdnsv1alpha1.RewriteStateApplied, Type: "CNAME"},
+		{Name: "bad.home", Content: "192.168.1.300", State: nextdnsv1alpha1.RewriteStateRejected, Message: "invalid content"},
+	}, updated.Status.Rewrites)
+	ready := findCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status, "a rejected rewrite does not fail the sync")
+}
+
 func TestFindProfilesForSecret_WithFieldIndex(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.Background()
@@ -3762,13 +4111,14 @@ func TestReconcile_ManagedMode_SkipsUpdateWhenUnchanged(t *testing.T) {
 			Finalizers: []string{FinalizerName},
 		},
 		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
-			Name:      "Test Profile",
-			ProfileID: "abc123",
+			Name:           "Test Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
 			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
 				Name: "nextdns-secret",
 			},
 			Security: &nextdnsv1alpha1.SecuritySpec{
-				AIThreatDetection: boolPtr(true),
+				AIThreatDetection: ptr.To(true),
 			},
 		},
 	}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// LabelProfileGenerator is set on generated NextDNSProfiles to the name of
+// the NextDNSProfileGenerator that owns them
+const LabelProfileGenerator = "nextdns.io/generator"
+
+// errProfileNotGenerated is returned when a NextDNSProfile with an instance's
+// name exists but is not controlled by the generator
+var errProfileNotGenerated = errors.New("NextDNSProfile exists and is not managed by this generator")
+
+// NextDNSProfileGeneratorReconciler reconciles a NextDNSProfileGenerator
+// object. It creates one NextDNSProfile per instance from the referenced
+// NextDNSProfileTemplate, re-applies the template whenever it or a generated
+// profile changes, and deletes the profiles of removed instances. The
+// generated profiles are reconciled by the NextDNSProfile controller.
+type NextDNSProfileGeneratorReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	ResourceLabels ResourceLabels
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofilegenerators,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofilegenerators/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofilegenerators/finalizers,verbs=update
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiletemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *NextDNSProfileGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var generator nextdnsv1alpha1.NextDNSProfileGenerator
+	if err := r.Get(ctx, req.NamespacedName, &generator); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if paused, err := reconcilePaused(ctx, r.Client, &generator, &generator.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	template, err := r.getTemplate(ctx, &generator)
+	if err != nil {
+		logger.Info("Referenced template is not available", "error", err.Error())
+		generator.Status.ObservedGeneration = generator.Generation
+		r.setCondition(&generator, metav1.ConditionFalse, "TemplateNotFound", err.Error())
+		if updateErr := r.Status().Update(ctx, &generator); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	var generated, conflicts []string
+	instances := make(map[string]bool, len(generator.Spec.Instances))
+	for _, instance := range generator.Spec.Instances {
+		if instances[instance.Name] {
+			conflicts = append(conflicts, fmt.Sprintf("instance %s is listed more than once", instance.Name))
+			continue
+		}
+		instances[instance.Name] = true
+
+		if err := r.applyInstance(ctx, &generator, template, instance); err != nil {
+			if errors.Is(err, errProfileNotGenerated) {
+				conflicts = append(conflicts, fmt.Sprintf("NextDNSProfile %s exists and is not managed by this generator", instance.Name))
+				continue
+			}
+			logger.Error(err, "Failed to generate NextDNSProfile", "instance", instance.Name)
+			return ctrl.Result{}, err
+		}
+		generated = append(generated, instance.Name)
+	}
+
+	if err := r.pruneProfiles(ctx, &generator, instances); err != nil {
+		logger.Error(err, "Failed to delete NextDNSProfiles of removed instances")
+		return ctrl.Result{}, err
+	}
+
+	sort.Strings(generated)
+	generator.Status.Profiles = generated
+	generator.Status.ProfileCount = len(generated)
+	generator.Status.ObservedGeneration = generator.Generation
+
+	result := ctrl.Result{}
+	if len(conflicts) > 0 {
+		r.setCondition(&generator, metav1.ConditionFalse, "InstanceConflict", strings.Join(conflicts, "; "))
+		result.RequeueAfter = 60 * time.Second
+	} else {
+		r.setCondition(&generator, metav1.ConditionTrue, "ProfilesGenerated",
+			fmt.Sprintf("Generated %d NextDNSProfiles from template %s", len(generated), template.Name))
+	}
+
+	if err := r.Status().Update(ctx, &generator); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &generator); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	return result, nil
+}
+
+// getTemplate returns the NextDNSProfileTemplate the generator references
+func (r *NextDNSProfileGeneratorReconciler) getTemplate(ctx context.Context, generator *nextdnsv1alpha1.NextDNSProfileGenerator) (*nextdnsv1alpha1.NextDNSProfileTemplate, error) {
+	ref := generator.Spec.TemplateRef
+	ns := ref.Namespace
+	if ns == "" {
+		ns = generator.Namespace
+	}
+
+	var template nextdnsv1alpha1.NextDNSProfileTemplate
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, &template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("NextDNSProfileTemplate %s/%s not found", ns, ref.Name)
+		}
+		return nil, fmt.Errorf("failed to get NextDNSProfileTemplate %s/%s: %w", ns, ref.Name, err)
+	}
+	return &template, nil
+}
+
+// applyInstance creates or updates the NextDNSProfile of an instance. It
+// returns errProfileNotGenerated if a profile of that name exists that the
+// generator does not control.
+func (r *NextDNSProfileGeneratorReconciler) applyInstance(ctx context.Context, generator *nextdnsv1alpha1.NextDNSProfileGenerator, template *nextdnsv1alpha1.NextDNSProfileTemplate, instance nextdnsv1alpha1.ProfileInstance) error {
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: generator.Namespace},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, profile, func() error {
+		if profile.ResourceVersion != "" && !metav1.IsControlledBy(profile, generator) {
+			return errProfileNotGenerated
+		}
+		r.ResourceLabels.set(profile)
+		if profile.Labels == nil {
+			profile.Labels = map[string]string{}
+		}
+		profile.Labels[LabelProfileGenerator] = generator.Name
+		profile.Spec = generatedProfileSpec(template, instance)
+		return controllerutil.SetControllerReference(generator, profile, r.Scheme)
+	})
+	if err != nil && !errors.Is(err, errProfileNotGenerated) {
+		return fmt.Errorf("failed to apply NextDNSProfile %s: %w", instance.Name, err)
+	}
+	return err
+}
+
+// generatedProfileSpec returns the spec of the profile generated for an
+// instance: the template's spec with the instance's display name, extra list
+// references and log retention applied
+func generatedProfileSpec(template *nextdnsv1alpha1.NextDNSProfileTemplate, instance nextdnsv1alpha1.ProfileInstance) nextdnsv1alpha1.NextDNSProfileSpec {
+	spec := template.Spec.Template.DeepCopy()
+
+	// Every instance is its own NextDNS profile; adopting one ID for all
+	// of them would make them fight over it
+	spec.ProfileID = ""
+
+	// Each instance deploys its own NextDNSCoreDNS, named after the profile
+	if spec.DeployCoreDNS != nil {
+		spec.DeployCoreDNS.Name = ""
+	}
+
+	switch {
+	case instance.DisplayName != "":
+		spec.Name = instance.DisplayName
+	case spec.Name != "":
+		spec.Name = spec.Name + " " + instance.Name
+	default:
+		spec.Name = instance.Name
+	}
+
+	spec.AllowlistRefs = append(spec.AllowlistRefs, instance.AllowlistRefs...)
+	spec.DenylistRefs = append(spec.DenylistRefs, instance.DenylistRefs...)
+
+	if instance.LogRetention != "" {
+		if spec.Settings == nil {
+			spec.Settings = &nextdnsv1alpha1.SettingsSpec{}
+		}
+		if spec.Settings.Logs == nil {
+			spec.Settings.Logs = &nextdnsv1alpha1.LogsSpec{}
+		}
+		spec.Settings.Logs.Retention = instance.LogRetention
+	}
+
+	return *spec
+}
+
+// pruneProfiles deletes the generated NextDNSProfiles whose instance was
+// removed from the generator
+func (r *NextDNSProfileGeneratorReconciler) pruneProfiles(ctx context.Context, generator *nextdnsv1alpha1.NextDNSProfileGenerator, instances map[string]bool) error {
+	logger := log.FromContext(ctx)
+
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &profiles,
+		client.InNamespace(generator.Namespace),
+		client.MatchingLabels{LabelProfileGenerator: generator.Name}); err != nil {
+		return fmt.Errorf("failed to list generated NextDNSProfiles: %w", err)
+	}
+
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		if instances[profile.Name] || !metav1.IsControlledBy(profile, generator) {
+			continue
+		}
+		if err := r.Delete(ctx, profile); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete NextDNSProfile %s: %w", profile.Name, err)
+		}
+		logger.Info("Deleted NextDNSProfile of removed instance", "profile", profile.Name)
+	}
+	return nil
+}
+
+// setCondition sets the Ready condition of a generator, emitting an event
+// when it changes
+func (r *NextDNSProfileGeneratorReconciler) setCondition(generator *nextdnsv1alpha1.NextDNSProfileGenerator, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               ConditionTypeReady,
+		Status:             status,
+		ObservedGeneration: generator.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	recordConditionEvent(r.Recorder, generator, generator.Status.Conditions, condition)
+	meta.SetStatusCondition(&generator.Status.Conditions, condition)
+}
+
+// findGeneratorsForTemplate returns reconcile requests for NextDNSProfileGenerator resources referencing the template
+func (r *NextDNSProfileGeneratorReconciler) findGeneratorsForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	template, ok := obj.(*nextdnsv1alpha1.NextDNSProfileTemplate)
+	if !ok {
+		return nil
+	}
+
+	var generators nextdnsv1alpha1.NextDNSProfileGeneratorList
+	indexKey := template.Namespace + "/" + template.Name
+	if err := r.List(ctx, &generators, client.MatchingFields{templateRefIndexField: indexKey}); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, generator := range generators.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      generator.Name,
+				Namespace: generator.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *NextDNSProfileGeneratorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &nextdnsv1alpha1.NextDNSProfileGenerator{}, templateRefIndexField, generatorTemplateRefIndexFunc); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSProfileGenerator{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSProfileGeneratorList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		// Re-apply the template when a generated profile is edited or deleted
+		Owns(&nextdnsv1alpha1.NextDNSProfile{}, ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfileTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.findGeneratorsForTemplate),
+		).
+		WithOptions(controllerOptions("nextdnsprofilegenerator", r.Workqueue)).
+		Complete(r)
+}
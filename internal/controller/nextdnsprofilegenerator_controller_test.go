@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestGeneratedProfileSpec(t *testing.T) {
+	template := &nextdnsv1alpha1.NextDNSProfileTemplate{
+		Spec: nextdnsv1alpha1.NextDNSProfileTemplateSpec{
+			Template: nextdnsv1alpha1.NextDNSProfileSpec{
+				Name:           "School",
+				ProfileID:      "abc123",
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+				AllowlistRefs:  []nextdnsv1alpha1.ListReference{{Name: "common-allowed"}},
+				Settings: &nextdnsv1alpha1.SettingsSpec{
+					Logs: &nextdnsv1alpha1.LogsSpec{Retention: "30d"},
+				},
+				DeployCoreDNS: &nextdnsv1alpha1.DeployCoreDNSSpec{Enabled: true, Name: "school-dns"},
+			},
+		},
+	}
+
+	spec := generatedProfileSpec(template, nextdnsv1alpha1.ProfileInstance{
+		Name:          "north",
+		AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "north-allowed"}},
+		LogRetention:  "90d",
+	})
+	assert.Equal(t, "School north", spec.Name)
+	assert.Empty(t, spec.ProfileID, "generated profiles never share an adopted profile")
+	assert.Equal(t, []nextdnsv1alpha1.ListReference{{Name: "common-allowed"}, {Name: "north-allowed"}}, spec.AllowlistRefs)
+	assert.Equal(t, "90d", spec.Settings.Logs.Retention)
+	assert.Empty(t, spec.DeployCoreDNS.Name, "each instance deploys its own NextDNSCoreDNS")
+
+	// The template itself is not modified
+	assert.Len(t, template.Spec.Template.AllowlistRefs, 1)
+	assert.Equal(t, "30d", template.Spec.Template.Settings.Logs.Retention)
+	assert.Equal(t, "abc123", template.Spec.Template.ProfileID)
+	assert.Equal(t, "school-dns", template.Spec.Template.DeployCoreDNS.Name)
+
+	spec = generatedProfileSpec(template, nextdnsv1alpha1.ProfileInstance{Name: "south", DisplayName: "South High"})
+	assert.Equal(t, "South High", spec.Name)
+	assert.Equal(t, "30d", spec.Settings.Logs.Retention)
+}
+
+func TestNextDNSProfileGeneratorReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	template := &nextdnsv1alpha1.NextDNSProfileTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "school", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileTemplateSpec{
+			Template: nextdnsv1alpha1.NextDNSProfileSpec{
+				Name:           "School",
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+				DenylistRefs:   []nextdnsv1alpha1.ListReference{{Name: "common-blocked"}},
+			},
+		},
+	}
+	generator := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "schools", Namespace: "default", Generation: 1},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "school"},
+			Instances: []nextdnsv1alpha1.ProfileInstance{
+				{Name: "north", DisplayName: "North Elementary"},
+				{Name: "south", LogRetention: "90d"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(template, generator).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSProfileGenerator{}).
+		Build()
+
+	r := &NextDNSProfileGeneratorReconciler{
+		Client:         fakeClient,
+		Scheme:         scheme,
+		ResourceLabels: ResourceLabels{"team": "dns"},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "schools", Namespace: "default"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var north nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "north", Namespace: "default"}, &north))
+	assert.Equal(t, "North Elementary", north.Spec.Name)
+	assert.Equal(t, "nextdns-secret", north.Spec.CredentialsRef.Name)
+	assert.Equal(t, "schools", north.Labels[LabelProfileGenerator])
+	assert.Equal(t, "dns", north.Labels["team"])
+	require.Len(t, north.OwnerReferences, 1)
+	assert.Equal(t, "schools", north.OwnerReferences[0].Name)
+
+	var current nextdnsv1alpha1.NextDNSProfileGenerator
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &current))
+	assert.Equal(t, []string{"north", "south"}, current.Status.Profiles)
+	assert.Equal(t, 2, current.Status.ProfileCount)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, ConditionTypeReady))
+
+	// A template change is applied to every generated profile and a
+	// removed instance's profile is deleted
+	var currentTemplate nextdnsv1alpha1.NextDNSProfileTemplate
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "school", Namespace: "default"}, &currentTemplate))
+	currentTemplate.Spec.Template.DenylistRefs = append(currentTemplate.Spec.Template.DenylistRefs, nextdnsv1alpha1.ListReference{Name: "social-media"})
+	require.NoError(t, fakeClient.Update(ctx, &currentTemplate))
+
+	current.Spec.Instances = current.Spec.Instances[:1]
+	require.NoError(t, fakeClient.Update(ctx, &current))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "north", Namespace: "default"}, &north))
+	assert.Equal(t, []nextdnsv1alpha1.ListReference{{Name: "common-blocked"}, {Name: "social-media"}}, north.Spec.DenylistRefs)
+
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	require.NoError(t, fakeClient.List(ctx, &profiles, client.InNamespace("default")))
+	require.Len(t, profiles.Items, 1)
+	assert.Equal(t, "north", profiles.Items[0].Name)
+}
+
+func TestNextDNSProfileGeneratorReconciler_Reconcile_Conflicts(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	template := &nextdnsv1alpha1.NextDNSProfileTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "school", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileTemplateSpec{
+			Template: nextdnsv1alpha1.NextDNSProfileSpec{
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			},
+		},
+	}
+	existing := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "north", Namespace: "default"},
+		Spec:       nextdnsv1alpha1.NextDNSProfileSpec{Name: "Hand-made"},
+	}
+	generator := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "schools", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "school"},
+			Instances: []nextdnsv1alpha1.ProfileInstance{
+				{Name: "north"},
+				{Name: "south"},
+				{Name: "south"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(template, existing, generator).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSProfileGenerator{}).
+		Build()
+
+	r := &NextDNSProfileGeneratorReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "schools", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+
+	var north nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "north", Namespace: "default"}, &north))
+	assert.Equal(t, "Hand-made", north.Spec.Name, "profiles the generator does not own are left alone")
+
+	var current nextdnsv1alpha1.NextDNSProfileGenerator
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &current))
+	assert.Equal(t, []string{"south"}, current.Status.Profiles)
+	cond := meta.FindStatusCondition(current.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "InstanceConflict", cond.Reason)
+	assert.Contains(t, cond.Message, "north")
+	assert.Contains(t, cond.Message, "south is listed more than once")
+}
+
+func TestNextDNSProfileGeneratorReconciler_Reconcile_TemplateNotFound(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	generator := &nextdnsv1alpha1.NextDNSProfileGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "schools", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileGeneratorSpec{
+			TemplateRef: nextdnsv1alpha1.ResourceReference{Name: "missing"},
+			Instances:   []nextdnsv1alpha1.ProfileInstance{{Name: "north"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(generator).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSProfileGenerator{}).
+		Build()
+
+	r := &NextDNSProfileGeneratorReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "schools", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+
+	var current nextdnsv1alpha1.NextDNSProfileGenerator
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &current))
+	cond := meta.FindStatusCondition(current.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, "TemplateNotFound", cond.Reason)
+
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	require.NoError(t, fakeClient.List(ctx, &profiles))
+	assert.Empty(t, profiles.Items)
+}
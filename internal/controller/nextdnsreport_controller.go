@@ -0,0 +1,415 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/profileresolver"
+)
+
+const (
+	// defaultReportInterval is the report interval when spec.interval is empty
+	defaultReportInterval = 7 * 24 * time.Hour
+
+	// defaultReportTopBlocked is the number of blocked domains listed per
+	// profile when spec.topBlocked is unset
+	defaultReportTopBlocked = 10
+
+	// reportTimeout bounds the delivery of a report
+	reportTimeout = 10 * time.Second
+
+	// reportRetryInterval is how long a failed report waits before it is
+	// attempted again
+	reportRetryInterval = 5 * time.Minute
+)
+
+// PolicyReport is the digest a NextDNSReport posts to its webhook
+type PolicyReport struct {
+	// Report is the namespace/name of the NextDNSReport
+	Report string `json:"report"`
+
+	// From and To bound the period the report covers
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	Profiles []ProfileReport `json:"profiles"`
+}
+
+// ProfileReport summarizes one profile in a PolicyReport
+type ProfileReport struct {
+	// Profile is the namespace/name of the NextDNSProfile
+	Profile   string `json:"profile"`
+	ProfileID string `json:"profileID,omitempty"`
+
+	// TopBlocked lists the domains blocked most often during the period
+	TopBlocked []BlockedDomain `json:"topBlocked,omitempty"`
+
+	// PolicyChanges lists the profile sections whose content was applied
+	// during the period
+	PolicyChanges []PolicyChange `json:"policyChanges,omitempty"`
+
+	// Drift describes the drift detected on the profile during the period
+	Drift *DriftIncident `json:"drift,omitempty"`
+
+	// Error explains why part of the profile could not be summarized
+	Error string `json:"error,omitempty"`
+}
+
+// BlockedDomain is a domain and the number of its blocked queries
+type BlockedDomain struct {
+	Domain  string `json:"domain"`
+	Queries int    `json:"queries"`
+}
+
+// PolicyChange is a profile section applied to NextDNS with new content
+type PolicyChange struct {
+	Section string    `json:"section"`
+	Time    time.Time `json:"time"`
+}
+
+// DriftIncident is drift detected on a profile
+type DriftIncident struct {
+	Since     time.Time `json:"since"`
+	Sections  []string  `json:"sections,omitempty"`
+	Corrected bool      `json:"corrected"`
+}
+
+// NextDNSReportReconciler reconciles a NextDNSReport object. Each interval
+// it summarizes the referenced profiles, the domains NextDNS blocked most,
+// the sections the operator applied and the drift it detected, and posts
+// the digest as JSON to the report's webhook.
+type NextDNSReportReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ClientFactory ClientFactory
+
+	// HTTPClient delivers reports; a client with a 10 second timeout is
+	// used when nil
+	HTTPClient *http.Client
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+
+	// now returns the current time; time.Now when nil
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsreports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsreports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *NextDNSReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var report nextdnsv1alpha1.NextDNSReport
+	if err := r.Get(ctx, req.NamespacedName, &report); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if paused, err := reconcilePaused(ctx, r.Client, &report, &report.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	specChanged := report.Status.ObservedGeneration != report.Generation
+	report.Status.ObservedGeneration = report.Generation
+
+	interval, err := reportInterval(report.Spec.Interval)
+	if err != nil {
+		r.setCondition(&report, metav1.ConditionFalse, "InvalidInterval", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &report)
+	}
+
+	url, reason, err := r.webhookURL(ctx, &report)
+	if err != nil {
+		r.setCondition(&report, metav1.ConditionFalse, reason, err.Error())
+		if updateErr := r.updateStatus(ctx, &report); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: reportRetryInterval}, nil
+	}
+
+	// The first report covers the interval before it; later reports
+	// continue where the last one ended
+	from := now.Add(-interval)
+	if last := report.Status.LastReportTime; last != nil {
+		from = last.Time
+		if due := last.Add(interval); now.Before(due) && !resyncRequested(&report) {
+			if next := report.Status.NextReportTime; specChanged || next == nil || !next.Time.Equal(due) {
+				report.Status.NextReportTime = &metav1.Time{Time: due}
+				if err := r.updateStatus(ctx, &report); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: due.Sub(now)}, nil
+		}
+	}
+
+	digest := r.buildReport(ctx, &report, from, now)
+	if err := r.deliver(ctx, url, digest); err != nil {
+		logger.Error(err, "Failed to deliver report")
+		r.setCondition(&report, metav1.ConditionFalse, "DeliveryFailed", err.Error())
+		report.Status.NextReportTime = &metav1.Time{Time: now.Add(reportRetryInterval)}
+		if updateErr := r.updateStatus(ctx, &report); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: reportRetryInterval}, nil
+	}
+
+	report.Status.LastReportTime = &metav1.Time{Time: now}
+	report.Status.NextReportTime = &metav1.Time{Time: now.Add(interval)}
+	r.setCondition(&report, metav1.ConditionTrue, "Delivered",
+		fmt.Sprintf("Report on %d profile(s) delivered", len(digest.Profiles)))
+	if err := r.updateStatus(ctx, &report); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &report); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// reportInterval parses spec.interval, defaulting to a week
+func reportInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultReportInterval, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid report interval %q", value)
+	}
+	return interval, nil
+}
+
+// webhookURL returns the endpoint reports are posted to, or the condition
+// reason explaining why it is not available
+func (r *NextDNSReportReconciler) webhookURL(ctx context.Context, report *nextdnsv1alpha1.NextDNSReport) (string, string, error) {
+	webhook := report.Spec.Webhook
+	switch {
+	case webhook.URL != "" && webhook.URLSecretRef != nil:
+		return "", "InvalidWebhook", errors.New("webhook url and urlSecretRef are mutually exclusive")
+	case webhook.URL != "":
+		return webhook.URL, "", nil
+	case webhook.URLSecretRef == nil:
+		return "", "InvalidWebhook", errors.New("one of webhook url or urlSecretRef must be set")
+	}
+
+	ref := webhook.URLSecretRef
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: report.Namespace}, &secret); err != nil {
+		return "", "SecretNotFound", fmt.Errorf("failed to get secret %s/%s: %w", report.Namespace, ref.Name, err)
+	}
+	url := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "InvalidWebhook", fmt.Errorf("key %s of secret %s/%s does not hold an http(s) URL", ref.Key, report.Namespace, ref.Name)
+	}
+	return url, "", nil
+}
+
+// buildReport summarizes the referenced profiles over [from, to). A profile
+// that cannot be read is listed with an error instead of failing the report.
+func (r *NextDNSReportReconciler) buildReport(ctx context.Context, report *nextdnsv1alpha1.NextDNSReport, from, to time.Time) *PolicyReport {
+	digest := &PolicyReport{
+		Report:   client.ObjectKeyFromObject(report).String(),
+		From:     from.UTC(),
+		To:       to.UTC(),
+		Profiles: []ProfileReport{},
+	}
+
+	limit := report.Spec.TopBlocked
+	if limit <= 0 {
+		limit = defaultReportTopBlocked
+	}
+
+	for _, ref := range report.Spec.ProfileRefs {
+		profile, err := profileresolver.Resolve(ctx, r.Client, ref, report.Namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				err = fmt.Errorf("NextDNSProfile %s not found", profileresolver.Key(ref, report.Namespace))
+			}
+			digest.Profiles = append(digest.Profiles, ProfileReport{
+				Profile: profileresolver.Key(ref, report.Namespace),
+				Error:   err.Error(),
+			})
+			continue
+		}
+
+		summary := ProfileReport{
+			Profile:       client.ObjectKeyFromObject(profile).String(),
+			ProfileID:     profile.Status.ProfileID,
+			PolicyChanges: policyChanges(profile.Status.Sections, from, to),
+			Drift:         driftIncident(profile, from),
+		}
+		topBlocked, err := r.topBlocked(ctx, profile, from, limit)
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		summary.TopBlocked = topBlocked
+		digest.Profiles = append(digest.Profiles, summary)
+	}
+	return digest
+}
+
+// topBlocked reads the domains blocked most often on profile since from
+func (r *NextDNSReportReconciler) topBlocked(ctx context.Context, profile *nextdnsv1alpha1.NextDNSProfile, from time.Time, limit int) ([]BlockedDomain, error) {
+	if profile.Status.ProfileID == "" {
+		return nil, fmt.Errorf("NextDNSProfile %s/%s has no profile ID yet", profile.Namespace, profile.Name)
+	}
+
+	apiKey, err := profileAPIKey(ctx, r.Client, profile)
+	if err != nil {
+		return nil, err
+	}
+	factory := r.ClientFactory
+	if factory == nil {
+		factory = DefaultClientFactory
+	}
+	nextdnsClient, err := factory(apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NextDNS client: %w", err)
+	}
+
+	entries, err := nextdnsClient.GetTopBlockedDomains(ctx, profile.Status.ProfileID, from, limit)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]BlockedDomain, 0, len(entries))
+	for _, entry := range entries {
+		domains = append(domains, BlockedDomain{Domain: entry.ID, Queries: entry.Queries})
+	}
+	return domains, nil
+}
+
+// policyChanges lists the sections of status.sections last applied with new
+// content within [from, to), in section order. Only the latest change of a
+// section is known.
+func policyChanges(sections *nextdnsv1alpha1.ProfileSectionStatus, from, to time.Time) []PolicyChange {
+	if sections == nil {
+		return nil
+	}
+	var changes []PolicyChange
+	for _, section := range sectionOrder {
+		status := *sectionStatus(sections, section)
+		if status == nil || status.LastSynced == nil {
+			continue
+		}
+		if synced := status.LastSynced.Time; !synced.Before(from) && synced.Before(to) {
+			changes = append(changes, PolicyChange{Section: section, Time: synced.UTC()})
+		}
+	}
+	return changes
+}
+
+// driftIncident describes the drift on profile when its Drifted condition
+// is True or turned False since from, or nil when no drift was detected
+// during the period
+func driftIncident(profile *nextdnsv1alpha1.NextDNSProfile, from time.Time) *DriftIncident {
+	condition := meta.FindStatusCondition(profile.Status.Conditions, ConditionTypeDrifted)
+	if condition == nil || (condition.Status != metav1.ConditionTrue && condition.LastTransitionTime.Time.Before(from)) {
+		return nil
+	}
+	if condition.Status != metav1.ConditionTrue {
+		// The drift was resolved during the period; its details are gone
+		return &DriftIncident{Since: condition.LastTransitionTime.UTC(), Corrected: true}
+	}
+	incident := &DriftIncident{Since: condition.LastTransitionTime.UTC()}
+	if summary := profile.Status.DriftSummary; summary != nil {
+		incident.Sections = append([]string{}, summary.Sections...)
+		incident.Corrected = summary.Corrected
+	}
+	return incident
+}
+
+// deliver posts digest to url as JSON
+func (r *NextDNSReportReconciler) deliver(ctx context.Context, url string, digest *PolicyReport) error {
+	encoded, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: reportTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to create report request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("report webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// updateStatus writes the status of report
+func (r *NextDNSReportReconciler) updateStatus(ctx context.Context, report *nextdnsv1alpha1.NextDNSReport) error {
+	if err := r.Status().Update(ctx, report); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return err
+	}
+	return nil
+}
+
+// setCondition sets the Ready condition of a report, emitting an event when
+// it changes
+func (r *NextDNSReportReconciler) setCondition(report *nextdnsv1alpha1.NextDNSReport, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               ConditionTypeReady,
+		Status:             status,
+		ObservedGeneration: report.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	recordConditionEvent(r.Recorder, report, report.Status.Conditions, condition)
+	meta.SetStatusCondition(&report.Status.Conditions, condition)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *NextDNSReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSReport{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSReportList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		WithOptions(controllerOptions("nextdnsreport", r.Workqueue)).
+		Complete(r)
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestNextDNSReportReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	var received []PolicyReport
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var digest PolicyReport
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&digest))
+		received = append(received, digest)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	report := &nextdnsv1alpha1.NextDNSReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "weekly", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSReportSpec{
+			ProfileRefs: []nextdnsv1alpha1.ResourceReference{{Name: "home"}, {Name: "missing"}},
+			Interval:    "168h",
+			TopBlocked:  1,
+			Webhook: nextdnsv1alpha1.ReportWebhook{
+				URLSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "webhook"},
+					Key:                  "url",
+				},
+			},
+		},
+	}
+	webhook := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(server.URL + "\n")},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+	changed := metav1.NewTime(now.Add(-48 * time.Hour))
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Sections: &nextdnsv1alpha1.ProfileSectionStatus{
+				Denylist: &nextdnsv1alpha1.SectionSyncStatus{LastSynced: &changed},
+				Security: &nextdnsv1alpha1.SectionSyncStatus{LastSynced: &metav1.Time{Time: now.Add(-30 * 24 * time.Hour)}},
+			},
+			DriftSummary: &nextdnsv1alpha1.DriftSummary{Sections: []string{"security"}, Corrected: true},
+			Conditions: []metav1.Condition{{
+				Type:               ConditionTypeDrifted,
+				Status:             metav1.ConditionTrue,
+				Reason:             "DriftCorrected",
+				LastTransitionTime: changed,
+			}},
+		},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.BlockedDomains["abc123"] = []*sdknextdns.AnalyticsEntry{
+		{ID: "ads.example.com", Queries: 412},
+		{ID: "tracker.example.com", Queries: 80},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(report, webhook, credentials, profile).
+		WithStatusSubresource(report, profile).
+		Build()
+	r := &NextDNSReportReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+		now: func() time.Time { return now },
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "weekly", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 168*time.Hour, result.RequeueAfter)
+
+	require.Len(t, received, 1)
+	digest := received[0]
+	assert.Equal(t, "default/weekly", digest.Report)
+	assert.True(t, digest.From.Equal(now.Add(-168*time.Hour)))
+	assert.True(t, digest.To.Equal(now))
+	require.Len(t, digest.Profiles, 2)
+
+	home := digest.Profiles[0]
+	assert.Equal(t, "default/home", home.Profile)
+	assert.Equal(t, []BlockedDomain{{Domain: "ads.example.com", Queries: 412}}, home.TopBlocked)
+	require.Len(t, home.PolicyChanges, 1, "changes before the period are not reported")
+	assert.Equal(t, sectionDenylist, home.PolicyChanges[0].Section)
+	require.NotNil(t, home.Drift)
+	assert.Equal(t, []string{"security"}, home.Drift.Sections)
+	assert.True(t, home.Drift.Corrected)
+	assert.Empty(t, home.Error)
+
+	assert.Equal(t, "default/missing", digest.Profiles[1].Profile)
+	assert.Contains(t, digest.Profiles[1].Error, "not found")
+
+	var updated nextdnsv1alpha1.NextDNSReport
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.LastReportTime)
+	assert.True(t, updated.Status.LastReportTime.Time.Equal(now))
+	require.NotNil(t, updated.Status.NextReportTime)
+	assert.True(t, updated.Status.NextReportTime.Time.Equal(now.Add(168*time.Hour)))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeReady))
+
+	// No report is sent before the interval elapsed
+	now = now.Add(time.Hour)
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 167*time.Hour, result.RequeueAfter)
+	assert.Len(t, received, 1)
+
+	// A failed delivery is retried and keeps the last report time
+	now = now.Add(167 * time.Hour)
+	status = http.StatusBadGateway
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, reportRetryInterval, result.RequeueAfter)
+	require.Len(t, received, 2)
+	assert.True(t, received[1].From.Equal(updated.Status.LastReportTime.Time), "the report continues where the last one ended")
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.True(t, updated.Status.LastReportTime.Time.Equal(now.Add(-168*time.Hour)))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, "DeliveryFailed", cond.Reason)
+}
+
+func TestNextDNSReportReconciler_InvalidWebhook(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	report := &nextdnsv1alpha1.NextDNSReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "weekly", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSReportSpec{
+			ProfileRefs: []nextdnsv1alpha1.ResourceReference{{Name: "home"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(report).
+		WithStatusSubresource(report).
+		Build()
+	r := &NextDNSReportReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "weekly", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, reportRetryInterval, result.RequeueAfter)
+
+	var updated nextdnsv1alpha1.NextDNSReport
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, "InvalidWebhook", cond.Reason)
+	assert.Nil(t, updated.Status.LastReportTime)
+}
+
+func TestReportTopBlocked_Error(t *testing.T) {
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.GetTopBlockedDomainsError = errors.New("analytics unavailable")
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+	r := &NextDNSReportReconciler{
+		Client: fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(credentials).Build(),
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+
+	_, err := r.topBlocked(context.Background(), profile, time.Now(), 10)
+	assert.EqualError(t, err, "analytics unavailable")
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// RewriteFinalizerName is the finalizer added to NextDNSRewrite resources
+	RewriteFinalizerName = "nextdns.io/rewrite-finalizer"
+)
+
+// NextDNSRewriteReconciler reconciles a NextDNSRewrite object
+type NextDNSRewriteReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	SyncPeriod time.Duration
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsrewrites,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsrewrites/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nextdns.io,resources=nextdnsrewrites/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *NextDNSRewriteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the rewrite list
+	var list nextdnsv1alpha1.NextDNSRewrite
+	if err := r.Get(ctx, req.NamespacedName, &list); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, &list, &list.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Handle deletion
+	if !list.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, &list)
+	}
+
+	// Add finalizer if not present
+	if !controllerutil.ContainsFinalizer(&list, RewriteFinalizerName) {
+		logger.Info("Adding finalizer to NextDNSRewrite")
+		controllerutil.AddFinalizer(&list, RewriteFinalizerName)
+		if err := r.Update(ctx, &list); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	// Count active rewrites
+	count := countActiveRewrites(list.Spec.Rewrites)
+
+	// Find profile references
+	profileRefs, err := r.findProfileReferences(ctx, &list)
+	if err != nil {
+		logger.Error(err, "Failed to find profile references")
+		return ctrl.Result{}, err
+	}
+
+	// Update status
+	list.Status.RewriteCount = count
+	list.Status.ProfileRefs = profileRefs
+
+	// Set conditions
+	setListConditions(&list.Status.Conditions, count, len(profileRefs), "rewrites")
+
+	// Update status subresource
+	if err := r.Status().Update(ctx, &list); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &list); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
+	// Schedule next sync with jitter for drift detection
+	syncInterval := CalculateSyncInterval(r.SyncPeriod)
+	return ctrl.Result{RequeueAfter: syncInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NextDNSRewriteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nextdnsv1alpha1.NextDNSRewrite{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSRewriteList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.findRewritesForProfile),
+		).
+		WithOptions(controllerOptions("nextdnsrewrite", r.Workqueue)).
+		Complete(r)
+}
+
+// findRewritesForProfile returns reconcile requests for all rewrite lists referenced by a profile
+func (r *NextDNSRewriteReconciler) findRewritesForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ref := range allListRefs(&profile.Spec, rewriteRefs) {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = profile.Namespace
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      ref.Name,
+				Namespace: namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// findProfileReferences finds all profiles that reference this rewrite list.
+// Note: Searches cluster-wide to support cross-namespace references.
+func (r *NextDNSRewriteReconciler) findProfileReferences(ctx context.Context, list *nextdnsv1alpha1.NextDNSRewrite) ([]nextdnsv1alpha1.ResourceReference, error) {
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &profiles); err != nil {
+		return nil, err
+	}
+
+	return findRefsForList(profiles.Items, list.Name, list.Namespace, rewriteRefs), nil
+}
+
+// handleDeletion handles the deletion of a rewrite list
+func (r *NextDNSRewriteReconciler) handleDeletion(ctx context.Context, list *nextdnsv1alpha1.NextDNSRewrite) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Check if any profiles reference this list
+	if len(list.Status.ProfileRefs) > 0 {
+		logger.Info("Deletion blocked - list is in use", "profileRefs", list.Status.ProfileRefs)
+
+		setDeletionBlockedCondition(r.Recorder, list, &list.Status.Conditions, list.Status.ProfileRefs)
+
+		// Update status and requeue
+		if err := r.Status().Update(ctx, list); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// No references - safe to delete
+	logger.Info("Removing finalizer from NextDNSRewrite")
+	controllerutil.RemoveFinalizer(list, RewriteFinalizerName)
+	if err := r.Update(ctx, list); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
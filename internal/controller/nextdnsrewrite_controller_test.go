@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestCountActiveRewrites(t *testing.T) {
+	rewrites := []nextdnsv1alpha1.RewriteEntry{
+		{From: "a.home", To: "10.0.0.1"},
+		{From: "b.home", To: "10.0.0.2", Active: ptr.To(true)},
+		{From: "c.home", To: "10.0.0.3", Active: ptr.To(false)},
+	}
+
+	assert.Equal(t, 2, countActiveRewrites(rewrites))
+	assert.Equal(t, 0, countActiveRewrites(nil))
+}
+
+func TestNextDNSRewriteReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+
+	list := &nextdnsv1alpha1.NextDNSRewrite{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-rewrites",
+			Namespace: "default",
+		},
+		Spec: nextdnsv1alpha1.NextDNSRewriteSpec{
+			Rewrites: []nextdnsv1alpha1.RewriteEntry{
+				{From: "nas.home", To: "192.168.1.10"},
+				{From: "old.home", To: "192.168.1.99", Active: ptr.To(false)},
+			},
+		},
+	}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "default",
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			RewriteRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "test-rewrites"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list, profile).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSRewrite{}).
+		Build()
+
+	r := &NextDNSRewriteReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+	}
+
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-rewrites",
+			Namespace: "default",
+		},
+	}
+
+	// First reconcile - should add finalizer
+	result, err := r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Duration(0))
+
+	var updatedList nextdnsv1alpha1.NextDNSRewrite
+	err = fakeClient.Get(context.Background(), req.NamespacedName, &updatedList)
+	assert.NoError(t, err)
+	assert.Contains(t, updatedList.Finalizers, RewriteFinalizerName)
+
+	// Second reconcile - should update status
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NoError(t, err)
+
+	err = fakeClient.Get(context.Background(), req.NamespacedName, &updatedList)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updatedList.Status.RewriteCount)
+	assert.Len(t, updatedList.Status.ProfileRefs, 1)
+	assert.Equal(t, "test-profile", updatedList.Status.ProfileRefs[0].Name)
+
+	validCond := meta.FindStatusCondition(updatedList.Status.Conditions, "Valid")
+	assert.NotNil(t, validCond)
+	assert.Equal(t, "All 1 rewrites are valid", validCond.Message)
+
+	inUseCond := meta.FindStatusCondition(updatedList.Status.Conditions, "InUse")
+	assert.NotNil(t, inUseCond)
+	assert.Equal(t, metav1.ConditionTrue, inUseCond.Status)
+}
+
+func TestNextDNSRewriteReconciler_HandleDeletion(t *testing.T) {
+	scheme := newTestScheme()
+
+	t.Run("deletion blocked when profiles reference list", func(t *testing.T) {
+		now := metav1.Now()
+		list := &nextdnsv1alpha1.NextDNSRewrite{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-rewrites",
+				Namespace:         "default",
+				Finalizers:        []string{RewriteFinalizerName},
+				DeletionTimestamp: &now,
+			},
+			Spec: nextdnsv1alpha1.NextDNSRewriteSpec{
+				Rewrites: []nextdnsv1alpha1.RewriteEntry{{From: "nas.home", To: "192.168.1.10"}},
+			},
+			Status: nextdnsv1alpha1.NextDNSRewriteStatus{
+				ProfileRefs: []nextdnsv1alpha1.ResourceReference{
+					{Name: "profile1", Namespace: "default"},
+				},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(list).
+			WithStatusSubresource(&nextdnsv1alpha1.NextDNSRewrite{}).
+			Build()
+
+		r := &NextDNSRewriteReconciler{Client: fakeClient, Scheme: scheme}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-rewrites", Namespace: "default"}}
+
+		result, err := r.Reconcile(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+		var updatedList nextdnsv1alpha1.NextDNSRewrite
+		err = fakeClient.Get(context.Background(), req.NamespacedName, &updatedList)
+		assert.NoError(t, err)
+
+		deletionBlockedCond := meta.FindStatusCondition(updatedList.Status.Conditions, "DeletionBlocked")
+		assert.NotNil(t, deletionBlockedCond)
+		assert.Contains(t, deletionBlockedCond.Message, "profile1")
+		assert.Contains(t, updatedList.Finalizers, RewriteFinalizerName)
+	})
+
+	t.Run("deletion allowed when no profiles reference list", func(t *testing.T) {
+		now := metav1.Now()
+		list := &nextdnsv1alpha1.NextDNSRewrite{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-rewrites",
+				Namespace:         "default",
+				Finalizers:        []string{RewriteFinalizerName},
+				DeletionTimestamp: &now,
+			},
+			Spec: nextdnsv1alpha1.NextDNSRewriteSpec{
+				Rewrites: []nextdnsv1alpha1.RewriteEntry{{From: "nas.home", To: "192.168.1.10"}},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(list).
+			WithStatusSubresource(&nextdnsv1alpha1.NextDNSRewrite{}).
+			Build()
+
+		r := &NextDNSRewriteReconciler{Client: fakeClient, Scheme: scheme}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-rewrites", Namespace: "default"}}
+
+		result, err := r.Reconcile(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), result.RequeueAfter)
+
+		var updatedList nextdnsv1alpha1.NextDNSRewrite
+		err = fakeClient.Get(context.Background(), req.NamespacedName, &updatedList)
+		assert.True(t, client.IgnoreNotFound(err) == nil && err != nil)
+	})
+}
+
+func TestNextDNSRewriteReconciler_findRewritesForProfile(t *testing.T) {
+	scheme := newTestScheme()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "default",
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			RewriteRefs: []nextdnsv1alpha1.ListReference{
+				{Name: "list1"},
+				{Name: "list2", Namespace: "other"},
+			},
+		},
+	}
+
+	r := &NextDNSRewriteReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	expected := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "list1", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "list2", Namespace: "other"}},
+	}
+	assert.ElementsMatch(t, expected, r.findRewritesForProfile(context.Background(), profile))
+}
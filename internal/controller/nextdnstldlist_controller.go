@@ -4,9 +4,12 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -14,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
 )
 
 const (
@@ -26,6 +30,16 @@ type NextDNSTLDListReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	SyncPeriod time.Duration
+
+	// ListSources caches fetched spec.sources; shared with the profile reconciler
+	ListSources *listsource.Cache
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
 }
 
 // +kubebuilder:rbac:groups=nextdns.io,resources=nextdnstldlists,verbs=get;list;watch;create;update;patch;delete
@@ -50,6 +64,11 @@ func (r *NextDNSTLDListReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: time.Second}, nil
 	}
 
+	// A paused resource keeps its finalizer but is neither synced nor deleted
+	if paused, err := reconcilePaused(ctx, r.Client, &list, &list.Status.Conditions); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Handle deletion
 	if !list.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, &list)
@@ -65,8 +84,12 @@ func (r *NextDNSTLDListReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: time.Second}, nil
 	}
 
-	// Count active TLDs
-	count := countActiveTLDs(list.Spec.TLDs)
+	// Count active TLDs, including those fetched from sources
+	fetched := fetchListSources(ctx, r.ListSources, list.Spec.Sources, resyncRequested(&list))
+	if fetched.err != nil {
+		logger.Error(fetched.err, "Failed to fetch list sources")
+	}
+	count := appendSourcedTLDs(resolveTLDList(list.Spec.TLDs), fetched.entries).count
 
 	// Find profile references
 	profileRefs, err := r.findProfileReferences(ctx, &list)
@@ -77,10 +100,12 @@ func (r *NextDNSTLDListReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Update status
 	list.Status.TLDCount = count
+	list.Status.Sources = fetched.status
 	list.Status.ProfileRefs = profileRefs
 
 	// Set conditions
 	setListConditions(&list.Status.Conditions, count, len(profileRefs), "TLDs")
+	setSourcesCondition(r.Recorder, &list, &list.Status.Conditions, fetched, len(list.Spec.Sources))
 
 	// Update status subresource
 	if err := r.Status().Update(ctx, &list); err != nil {
@@ -88,19 +113,30 @@ func (r *NextDNSTLDListReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	// Clear a processed resync request
+	if err := clearResync(ctx, r.Client, &list); err != nil {
+		logger.Error(err, "Failed to clear resync request")
+	}
+
 	// Schedule next sync with jitter for drift detection
 	syncInterval := CalculateSyncInterval(r.SyncPeriod)
-	return ctrl.Result{RequeueAfter: syncInterval}, nil
+	return ctrl.Result{RequeueAfter: fetched.requeueAfter(syncInterval)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NextDNSTLDListReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&nextdnsv1alpha1.NextDNSTLDList{}).
+		For(&nextdnsv1alpha1.NextDNSTLDList{}, ctrlbuilder.WithPredicates(resyncClearedPredicate())).
+		Watches(
+			&corev1.Namespace{},
+			enqueueForNamespacePause(mgr.GetClient(), &nextdnsv1alpha1.NextDNSTLDListList{}),
+			ctrlbuilder.WithPredicates(namespacePausedChangedPredicate()),
+		).
 		Watches(
 			&nextdnsv1alpha1.NextDNSProfile{},
 			handler.EnqueueRequestsFromMapFunc(r.findTLDListsForProfile),
 		).
+		WithOptions(controllerOptions("nextdnstldlist", r.Workqueue)).
 		Complete(r)
 }
 
@@ -112,7 +148,7 @@ func (r *NextDNSTLDListReconciler) findTLDListsForProfile(ctx context.Context, o
 	}
 
 	var requests []reconcile.Request
-	for _, ref := range profile.Spec.TLDListRefs {
+	for _, ref := range allListRefs(&profile.Spec, tldListRefs) {
 		namespace := ref.Namespace
 		if namespace == "" {
 			namespace = profile.Namespace
@@ -137,9 +173,7 @@ func (r *NextDNSTLDListReconciler) findProfileReferences(ctx context.Context, li
 		return nil, err
 	}
 
-	return findRefsForList(profiles.Items, list.Name, list.Namespace, func(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
-		return spec.TLDListRefs
-	}), nil
+	return findRefsForList(profiles.Items, list.Name, list.Namespace, tldListRefs), nil
 }
 
 // handleDeletion handles the deletion of a TLD list
@@ -150,7 +184,7 @@ func (r *NextDNSTLDListReconciler) handleDeletion(ctx context.Context, list *nex
 	if len(list.Status.ProfileRefs) > 0 {
 		logger.Info("Deletion blocked - list is in use", "profileRefs", list.Status.ProfileRefs)
 
-		setDeletionBlockedCondition(&list.Status.Conditions, list.Status.ProfileRefs)
+		setDeletionBlockedCondition(r.Recorder, list, &list.Status.Conditions, list.Status.ProfileRefs)
 
 		// Update status and requeue
 		if err := r.Status().Update(ctx, list); err != nil {
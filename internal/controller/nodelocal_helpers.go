@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// defaultNodeLocalIP is the link-local address node-local-dns uses
+	defaultNodeLocalIP = "169.254.20.10"
+
+	// defaultNodeLocalInterface is the dummy interface holding the local IP
+	defaultNodeLocalInterface = "nodelocaldns"
+
+	// defaultNodeLocalSetupImage provides the ip command for the init container
+	defaultNodeLocalSetupImage = "mirror.gcr.io/library/busybox:1.37"
+
+	// nodeIPEnvVar holds the node IP in the CoreDNS container, so the
+	// Corefile can bind it through environment substitution
+	nodeIPEnvVar = "NODE_IP"
+)
+
+// nodeLocalEnabled reports whether CoreDNS runs as a node-local cache.
+// The mode is only honored in DaemonSet mode, where each node runs one pod.
+func nodeLocalEnabled(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	d := coreDNS.Spec.Deployment
+	return d != nil && d.Mode == nextdnsv1alpha1.DeploymentModeDaemonSet &&
+		d.NodeLocal != nil && d.NodeLocal.Enabled
+}
+
+// nodeLocalIP returns the address bound on every node, defaulting to
+// 169.254.20.10
+func nodeLocalIP(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	if ip := coreDNS.Spec.Deployment.NodeLocal.LocalIP; ip != "" {
+		return ip
+	}
+	return defaultNodeLocalIP
+}
+
+// nodeLocalInterface returns the dummy interface name, defaulting to nodelocaldns
+func nodeLocalInterface(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	if name := coreDNS.Spec.Deployment.NodeLocal.Interface; name != "" {
+		return name
+	}
+	return defaultNodeLocalInterface
+}
+
+// nodeLocalBindAddresses returns the addresses the Corefile binds in
+// node-local mode: the local IP for pods on the node, and the node IP so the
+// Service, status endpoints and test queries keep working
+func nodeLocalBindAddresses(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]string, error) {
+	ip := nodeLocalIP(coreDNS)
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid nodeLocal.localIP %q", ip)
+	}
+	return []string{ip, "{$" + nodeIPEnvVar + "}"}, nil
+}
+
+// applyNodeLocal switches a CoreDNS pod spec to host networking, adds the
+// init container that puts the local IP on a dummy interface, and exposes
+// the node IP to the Corefile
+func applyNodeLocal(podSpec *corev1.PodSpec, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	podSpec.HostNetwork = true
+	// Resolve upstream names through the node rather than the cluster DNS,
+	// which may itself point at this cache
+	podSpec.DNSPolicy = corev1.DNSDefault
+
+	container := &podSpec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name: nodeIPEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.hostIP"},
+		},
+	})
+
+	image := coreDNS.Spec.Deployment.NodeLocal.SetupImage
+	if image == "" {
+		image = defaultNodeLocalSetupImage
+	}
+	prefix := "/32"
+	if ip := net.ParseIP(nodeLocalIP(coreDNS)); ip != nil && ip.To4() == nil {
+		prefix = "/128"
+	}
+	iface := nodeLocalInterface(coreDNS)
+	script := fmt.Sprintf("ip link add %[1]s type dummy 2>/dev/null || true; ip addr replace %[2]s%[3]s dev %[1]s && ip link set %[1]s up",
+		iface, nodeLocalIP(coreDNS), prefix)
+
+	allowPrivilegeEscalation := false
+	runAsNonRoot := false
+	runAsUser := int64(0)
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:    setupInterfaceContainerName,
+		Image:   image,
+		Command: []string{"sh", "-c", script},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			RunAsNonRoot:             &runAsNonRoot,
+			RunAsUser:                &runAsUser,
+			Capabilities: &corev1.Capabilities{
+				Add:  []corev1.Capability{"NET_ADMIN"},
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+	})
+}
+
+// nodeLocalStatus returns the node-local cache address with the kubelet
+// configuration that points pods at it
+func nodeLocalStatus(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.NodeLocalStatus {
+	ip := nodeLocalIP(coreDNS)
+	return &nextdnsv1alpha1.NodeLocalStatus{
+		LocalIP:       ip,
+		Interface:     nodeLocalInterface(coreDNS),
+		KubeletConfig: fmt.Sprintf("clusterDNS:\n- %s\n", ip),
+		KubeletFlag:   "--cluster-dns=" + ip,
+	}
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newNodeLocalCoreDNS(mode nextdnsv1alpha1.DeploymentMode, nodeLocal *nextdnsv1alpha1.CoreDNSNodeLocalConfig) *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode:      mode,
+				NodeLocal: nodeLocal,
+			},
+		},
+	}
+}
+
+func TestBuildPodSpec_NodeLocal(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	coreDNS := newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, &nextdnsv1alpha1.CoreDNSNodeLocalConfig{Enabled: true})
+	podSpec := r.buildPodSpec(coreDNS, "test-cm")
+
+	assert.True(t, podSpec.HostNetwork)
+	assert.Equal(t, corev1.DNSDefault, podSpec.DNSPolicy)
+	assert.Equal(t, "system-node-critical", podSpec.PriorityClassName)
+	assert.Equal(t, criticalAddonTolerations(), podSpec.Tolerations)
+
+	require.Len(t, podSpec.InitContainers, 1)
+	setup := podSpec.InitContainers[0]
+	assert.Equal(t, defaultNodeLocalSetupImage, setup.Image)
+	assert.Equal(t, []string{"sh", "-c",
+		"ip link add nodelocaldns type dummy 2>/dev/null || true; ip addr replace 169.254.20.10/32 dev nodelocaldns && ip link set nodelocaldns up"},
+		setup.Command)
+	assert.Equal(t, []corev1.Capability{"NET_ADMIN"}, setup.SecurityContext.Capabilities.Add)
+
+	require.Len(t, podSpec.Containers[0].Env, 1)
+	assert.Equal(t, nodeIPEnvVar, podSpec.Containers[0].Env[0].Name)
+	assert.Equal(t, "status.hostIP", podSpec.Containers[0].Env[0].ValueFrom.FieldRef.FieldPath)
+
+	// Ignored outside DaemonSet mode
+	coreDNS = newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDeployment, &nextdnsv1alpha1.CoreDNSNodeLocalConfig{Enabled: true})
+	podSpec = r.buildPodSpec(coreDNS, "test-cm")
+	assert.False(t, podSpec.HostNetwork)
+	assert.Empty(t, podSpec.InitContainers)
+	assert.Empty(t, podSpec.PriorityClassName)
+}
+
+func TestBuildPodSpec_NodeLocalCustomAddress(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	coreDNS := newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, &nextdnsv1alpha1.CoreDNSNodeLocalConfig{
+		Enabled:    true,
+		LocalIP:    "fd00::a",
+		Interface:  "nextdns0",
+		SetupImage: "registry.example.com/tools:1",
+	})
+	setup := r.buildPodSpec(coreDNS, "test-cm").InitContainers[0]
+	assert.Equal(t, "registry.example.com/tools:1", setup.Image)
+	assert.Contains(t, setup.Command[2], "ip addr replace fd00::a/128 dev nextdns0")
+}
+
+func TestBuildCorefileConfig_NodeLocal(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+
+	coreDNS := newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, &nextdnsv1alpha1.CoreDNSNodeLocalConfig{Enabled: true})
+	cfg, err := r.buildCorefileConfig(coreDNS, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"169.254.20.10", "{$NODE_IP}"}, cfg.BindAddresses)
+
+	coreDNS.Spec.Deployment.NodeLocal.LocalIP = "not-an-ip"
+	_, err = r.buildCorefileConfig(coreDNS, profile)
+	assert.ErrorContains(t, err, `invalid nodeLocal.localIP "not-an-ip"`)
+
+	// Without node-local mode CoreDNS binds all interfaces
+	cfg, err = r.buildCorefileConfig(newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, nil), profile)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.BindAddresses)
+}
+
+func TestNodeLocalStatus(t *testing.T) {
+	coreDNS := newNodeLocalCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, &nextdnsv1alpha1.CoreDNSNodeLocalConfig{
+		Enabled: true,
+		LocalIP: "169.254.53.53",
+	})
+	assert.Equal(t, &nextdnsv1alpha1.NodeLocalStatus{
+		LocalIP:       "169.254.53.53",
+		Interface:     "nodelocaldns",
+		KubeletConfig: "clusterDNS:\n- 169.254.53.53\n",
+		KubeletFlag:   "--cluster-dns=169.254.53.53",
+	}, nodeLocalStatus(coreDNS))
+}
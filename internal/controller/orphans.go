@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// AnnotationOrphanedProfiles is set on a NextDNSAccount by the profile
+// finalizer. It maps, as a JSON object, the ID of each profile whose
+// NextDNSProfile was removed with the Delete policy but without deleting
+// the profile to that resource's namespace/name.
+const AnnotationOrphanedProfiles = "nextdns.io/orphaned-profiles"
+
+// orphanedProfiles returns the profiles the finalizer recorded as orphaned
+// on account. An unreadable annotation records none.
+func orphanedProfiles(account *nextdnsv1alpha1.NextDNSAccount) map[string]string {
+	value, ok := account.Annotations[AnnotationOrphanedProfiles]
+	if !ok {
+		return nil
+	}
+	var orphaned map[string]string
+	if err := json.Unmarshal([]byte(value), &orphaned); err != nil {
+		return nil
+	}
+	return orphaned
+}
+
+// setOrphanedProfiles patches the orphaned profiles annotation of account to
+// orphaned, removing it when orphaned is empty. The patch fails if account
+// changed since it was read, so concurrent writers do not drop entries.
+func setOrphanedProfiles(ctx context.Context, c client.Client, account *nextdnsv1alpha1.NextDNSAccount, orphaned map[string]string) error {
+	patch := client.MergeFromWithOptions(account.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if len(orphaned) == 0 {
+		delete(account.Annotations, AnnotationOrphanedProfiles)
+	} else {
+		value, err := json.Marshal(orphaned)
+		if err != nil {
+			return fmt.Errorf("failed to encode orphaned profiles: %w", err)
+		}
+		if account.Annotations == nil {
+			account.Annotations = map[string]string{}
+		}
+		account.Annotations[AnnotationOrphanedProfiles] = string(value)
+	}
+	if err := c.Patch(ctx, account, patch); err != nil {
+		return fmt.Errorf("failed to update orphaned profiles of account %s/%s: %w", account.Namespace, account.Name, err)
+	}
+	return nil
+}
+
+// recordOrphanedProfile records the profile of a NextDNSProfile that is
+// being removed without deleting it on the accounts synced with the same
+// API key, so their orphan GC can find it
+func recordOrphanedProfile(ctx context.Context, c client.Client, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	fingerprint := profile.Status.AccountFingerprint
+	if fingerprint == "" {
+		return nil
+	}
+
+	var accounts nextdnsv1alpha1.NextDNSAccountList
+	if err := c.List(ctx, &accounts); err != nil {
+		return fmt.Errorf("failed to list NextDNSAccounts: %w", err)
+	}
+	key := client.ObjectKeyFromObject(profile).String()
+	for i := range accounts.Items {
+		account := &accounts.Items[i]
+		if account.Status.AccountFingerprint != fingerprint {
+			continue
+		}
+		orphaned := orphanedProfiles(account)
+		if orphaned[profile.Status.ProfileID] == key {
+			continue
+		}
+		orphaned = maps.Clone(orphaned)
+		if orphaned == nil {
+			orphaned = map[string]string{}
+		}
+		orphaned[profile.Status.ProfileID] = key
+		if err := setOrphanedProfiles(ctx, c, account, orphaned); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("Recorded orphaned profile on account",
+			"profileID", profile.Status.ProfileID, "account", client.ObjectKeyFromObject(account))
+	}
+	return nil
+}
+
+// pruneOrphanedProfiles drops from the orphaned profiles annotation of
+// account the profiles no longer in profiles, which were deleted, and those
+// a NextDNSProfile in managed syncs again. An unreadable annotation is
+// removed.
+func pruneOrphanedProfiles(ctx context.Context, c client.Client, account *nextdnsv1alpha1.NextDNSAccount, profiles []nextdnsv1alpha1.AccountProfile, managed map[string]nextdnsv1alpha1.AccountProfile) error {
+	if _, ok := account.Annotations[AnnotationOrphanedProfiles]; !ok {
+		return nil
+	}
+	orphaned := orphanedProfiles(account)
+
+	kept := make(map[string]string, len(orphaned))
+	for _, p := range profiles {
+		if _, ok := managed[p.ID]; ok {
+			continue
+		}
+		if owner, ok := orphaned[p.ID]; ok {
+			kept[p.ID] = owner
+		}
+	}
+	if orphaned != nil && len(kept) == len(orphaned) {
+		return nil
+	}
+	return setOrphanedProfiles(ctx, c, account, kept)
+}
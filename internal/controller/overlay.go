@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"fmt"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// findOverlay returns the overlay with the given name, or nil if it is not defined
+func findOverlay(spec *nextdnsv1alpha1.NextDNSProfileSpec, name string) *nextdnsv1alpha1.ProfileOverlay {
+	for i := range spec.Overlays {
+		if spec.Overlays[i].Name == name {
+			return &spec.Overlays[i]
+		}
+	}
+	return nil
+}
+
+// effectiveSpec returns the spec with the active overlay merged in. List
+// references are appended to the base references and settings sections set
+// in the overlay replace the base section. The input spec is not modified.
+func effectiveSpec(spec *nextdnsv1alpha1.NextDNSProfileSpec) (*nextdnsv1alpha1.NextDNSProfileSpec, error) {
+	merged := spec.DeepCopy()
+	if spec.ActiveOverlay == "" {
+		return merged, nil
+	}
+
+	overlay := findOverlay(merged, spec.ActiveOverlay)
+	if overlay == nil {
+		return nil, fmt.Errorf("overlay %q is not defined in spec.overlays", spec.ActiveOverlay)
+	}
+
+	merged.AllowlistRefs = append(merged.AllowlistRefs, overlay.AllowlistRefs...)
+	merged.DenylistRefs = append(merged.DenylistRefs, overlay.DenylistRefs...)
+	merged.TLDListRefs = append(merged.TLDListRefs, overlay.TLDListRefs...)
+	merged.RewriteRefs = append(merged.RewriteRefs, overlay.RewriteRefs...)
+	if overlay.Security != nil {
+		merged.Security = overlay.Security
+	}
+	if overlay.Privacy != nil {
+		merged.Privacy = overlay.Privacy
+	}
+	if overlay.ParentalControl != nil {
+		merged.ParentalControl = overlay.ParentalControl
+	}
+	if overlay.Settings != nil {
+		merged.Settings = overlay.Settings
+	}
+
+	return merged, nil
+}
+
+// allListRefs returns the list references extracted from the base spec and
+// from every overlay, so lists used by an inactive overlay are still watched
+// and protected from deletion
+func allListRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec, extractRefs func(*nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference) []nextdnsv1alpha1.ListReference {
+	refs := append([]nextdnsv1alpha1.ListReference(nil), extractRefs(spec)...)
+	for _, overlay := range spec.Overlays {
+		refs = append(refs, extractRefs(&nextdnsv1alpha1.NextDNSProfileSpec{
+			AllowlistRefs: overlay.AllowlistRefs,
+			DenylistRefs:  overlay.DenylistRefs,
+			TLDListRefs:   overlay.TLDListRefs,
+			RewriteRefs:   overlay.RewriteRefs,
+		})...)
+	}
+	return refs
+}
+
+// allowlistRefs extracts NextDNSAllowlist references from a spec
+func allowlistRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return refsOfKind(spec.AllowlistRefs, nextdnsv1alpha1.KindAllowlist, nextdnsv1alpha1.KindAllowlist)
+}
+
+// clusterAllowlistRefs extracts ClusterNextDNSAllowlist references from a spec
+func clusterAllowlistRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return refsOfKind(spec.AllowlistRefs, nextdnsv1alpha1.KindAllowlist, nextdnsv1alpha1.KindClusterAllowlist)
+}
+
+// denylistRefs extracts NextDNSDenylist references from a spec
+func denylistRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return refsOfKind(spec.DenylistRefs, nextdnsv1alpha1.KindDenylist, nextdnsv1alpha1.KindDenylist)
+}
+
+// clusterDenylistRefs extracts ClusterNextDNSDenylist references from a spec
+func clusterDenylistRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return refsOfKind(spec.DenylistRefs, nextdnsv1alpha1.KindDenylist, nextdnsv1alpha1.KindClusterDenylist)
+}
+
+// denylistSourceRefs extracts NextDNSDenylistSource references from a spec
+func denylistSourceRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return refsOfKind(spec.DenylistRefs, nextdnsv1alpha1.KindDenylist, nextdnsv1alpha1.KindDenylistSource)
+}
+
+// refsOfKind returns the references to kind. References without a kind
+// reference defaultKind.
+func refsOfKind(refs []nextdnsv1alpha1.ListReference, defaultKind, kind string) []nextdnsv1alpha1.ListReference {
+	var matching []nextdnsv1alpha1.ListReference
+	for _, ref := range refs {
+		refKind := ref.Kind
+		if refKind == "" {
+			refKind = defaultKind
+		}
+		if refKind == kind {
+			matching = append(matching, ref)
+		}
+	}
+	return matching
+}
+
+// tldListRefs extracts TLD list references from a spec
+func tldListRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return spec.TLDListRefs
+}
+
+// rewriteRefs extracts rewrite list references from a spec
+func rewriteRefs(spec *nextdnsv1alpha1.NextDNSProfileSpec) []nextdnsv1alpha1.ListReference {
+	return spec.RewriteRefs
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestEffectiveSpec(t *testing.T) {
+	base := &nextdnsv1alpha1.NextDNSProfileSpec{
+		Name:         "Home",
+		DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "base-blocks"}},
+		Security:     &nextdnsv1alpha1.SecuritySpec{NRD: ptr.To(false)},
+		Privacy:      &nextdnsv1alpha1.PrivacySpec{DisguisedTrackers: ptr.To(true)},
+		Overlays: []nextdnsv1alpha1.ProfileOverlay{
+			{
+				Name:         "strict",
+				DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "strict-blocks"}},
+				Security:     &nextdnsv1alpha1.SecuritySpec{NRD: ptr.To(true)},
+			},
+		},
+	}
+
+	t.Run("no active overlay returns the base spec", func(t *testing.T) {
+		merged, err := effectiveSpec(base)
+		require.NoError(t, err)
+		assert.Equal(t, base, merged)
+	})
+
+	t.Run("active overlay adds refs and replaces sections", func(t *testing.T) {
+		spec := base.DeepCopy()
+		spec.ActiveOverlay = "strict"
+
+		merged, err := effectiveSpec(spec)
+		require.NoError(t, err)
+		assert.Equal(t, []nextdnsv1alpha1.ListReference{{Name: "base-blocks"}, {Name: "strict-blocks"}}, merged.DenylistRefs)
+		assert.True(t, *merged.Security.NRD)
+		assert.Equal(t, base.Privacy, merged.Privacy)
+
+		// The input spec is left untouched
+		assert.Len(t, spec.DenylistRefs, 1)
+		assert.False(t, *spec.Security.NRD)
+	})
+
+	t.Run("unknown overlay", func(t *testing.T) {
+		spec := base.DeepCopy()
+		spec.ActiveOverlay = "relaxed"
+
+		_, err := effectiveSpec(spec)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `overlay "relaxed" is not defined`)
+	})
+}
+
+func TestAllListRefs(t *testing.T) {
+	spec := &nextdnsv1alpha1.NextDNSProfileSpec{
+		AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "base"}},
+		Overlays: []nextdnsv1alpha1.ProfileOverlay{
+			{Name: "strict", DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "strict-blocks"}}},
+			{Name: "relaxed", AllowlistRefs: []nextdnsv1alpha1.ListReference{{Name: "relaxed-allows"}}},
+		},
+	}
+
+	assert.Equal(t, []nextdnsv1alpha1.ListReference{{Name: "base"}, {Name: "relaxed-allows"}}, allListRefs(spec, allowlistRefs))
+	assert.Equal(t, []nextdnsv1alpha1.ListReference{{Name: "strict-blocks"}}, allListRefs(spec, denylistRefs))
+	assert.Empty(t, allListRefs(spec, tldListRefs))
+}
+
+func TestReconcile_ActiveOverlay(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "overlay-profile",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Overlay Profile",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Denylist:       []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+			Overlays: []nextdnsv1alpha1.ProfileOverlay{
+				{
+					Name:         "strict",
+					DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "strict-blocks"}},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+	strict := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "strict-blocks", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Domains: []nextdnsv1alpha1.DomainEntry{{Domain: "social.example.com"}},
+		},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Overlay Profile", "abc123.dns.nextdns.io")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret, strict).
+		WithStatusSubresource(profile).
+		Build()
+
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: 5 * time.Minute,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "overlay-profile", Namespace: "default"}}
+
+	remoteDenylist := func() []string {
+		denylist, err := mockNDS.GetDenylist(ctx, "abc123")
+		require.NoError(t, err)
+		var domains []string
+		for _, e := range denylist {
+			domains = append(domains, e.ID)
+		}
+		return domains
+	}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ads.example.com"}, remoteDenylist())
+
+	// Switching the overlay is a single field change
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.ActiveOverlay = "strict"
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ads.example.com", "social.example.com"}, remoteDenylist())
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, "strict", updated.Status.ActiveOverlay)
+	assert.Equal(t, 2, updated.Status.AggregatedCounts.DenylistDomains)
+	assert.Len(t, updated.Spec.DenylistRefs, 0, "merged overlay must not be written back to the spec")
+
+	// An unknown overlay blocks the sync
+	updated.Spec.ActiveOverlay = "missing"
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "OverlayNotFound", cond.Reason)
+}
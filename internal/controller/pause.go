@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// AnnotationPaused suspends reconciliation of a resource while set to "true".
+// The resource is neither synced nor deleted, and keeps its finalizer. On a
+// Namespace, it pauses every NextDNS resource in the namespace.
+const AnnotationPaused = "nextdns.io/paused"
+
+// ConditionTypePaused indicates reconciliation is suspended by the paused annotation
+const ConditionTypePaused = "Paused"
+
+// isPaused reports whether obj carries the paused annotation
+func isPaused(obj client.Object) bool {
+	return obj.GetAnnotations()[AnnotationPaused] == "true"
+}
+
+// namespacePaused reports whether the namespace of obj carries the paused
+// annotation. Cluster-scoped objects and missing namespaces are not paused.
+func namespacePaused(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	if obj.GetNamespace() == "" {
+		return false, nil
+	}
+
+	var namespace corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, &namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace %s: %w", obj.GetNamespace(), err)
+	}
+	return isPaused(&namespace), nil
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// reconcilePaused sets the Paused condition in conditions while obj or its
+// namespace is paused and removes it otherwise, writing the status of obj
+// when the condition changed. It returns true if obj is paused and the
+// reconcile must stop before touching the NextDNS API or any owned resource.
+func reconcilePaused(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+	condition := metav1.Condition{
+		Type:               ConditionTypePaused,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+	}
+
+	paused := isPaused(obj)
+	if paused {
+		condition.Reason = "PausedByAnnotation"
+		condition.Message = fmt.Sprintf("Reconciliation is paused by the %s annotation", AnnotationPaused)
+	} else {
+		nsPaused, err := namespacePaused(ctx, c, obj)
+		if err != nil {
+			return false, err
+		}
+		if nsPaused {
+			paused = true
+			condition.Reason = "PausedByNamespace"
+			condition.Message = fmt.Sprintf("Reconciliation is paused by the %s annotation on namespace %s",
+				AnnotationPaused, obj.GetNamespace())
+		}
+	}
+
+	var changed bool
+	if paused {
+		changed = meta.SetStatusCondition(conditions, condition)
+	} else {
+		changed = meta.RemoveStatusCondition(conditions, ConditionTypePaused)
+	}
+
+	if changed {
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return paused, fmt.Errorf("failed to update paused status: %w", err)
+		}
+	}
+	return paused, nil
+}
+
+// namespacePausedChangedPredicate passes Namespace updates that pause or
+// resume the namespace
+func namespacePausedChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isPaused(e.ObjectOld) != isPaused(e.ObjectNew)
+		},
+	}
+}
+
+// enqueueForNamespacePause returns a handler mapping a Namespace to every
+// resource of list's kind in it, so pausing or resuming the namespace takes
+// effect without waiting for the next resync
+func enqueueForNamespacePause(c client.Client, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		items := list.DeepCopyObject().(client.ObjectList)
+		if err := c.List(ctx, items, client.InNamespace(obj.GetName())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list resources for paused namespace", "namespace", obj.GetName())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		_ = meta.EachListItem(items, func(item runtime.Object) error {
+			if o, ok := item.(client.Object); ok {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+			}
+			return nil
+		})
+		return requests
+	})
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestReconcilePaused(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	list := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "list",
+			Namespace:   "default",
+			Generation:  2,
+			Annotations: map[string]string{AnnotationPaused: "true"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSAllowlist{}).
+		Build()
+	key := types.NamespacedName{Name: "list", Namespace: "default"}
+
+	var current nextdnsv1alpha1.NextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	paused, err := reconcilePaused(ctx, fakeClient, &current, &current.Status.Conditions)
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	cond := meta.FindStatusCondition(current.Status.Conditions, ConditionTypePaused)
+	require.NotNil(t, cond, "the Paused condition is persisted")
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "PausedByAnnotation", cond.Reason)
+	assert.Equal(t, int64(2), cond.ObservedGeneration)
+
+	current.Annotations[AnnotationPaused] = "false"
+	require.NoError(t, fakeClient.Update(ctx, &current))
+	paused, err = reconcilePaused(ctx, fakeClient, &current, &current.Status.Conditions)
+	require.NoError(t, err)
+	assert.False(t, paused, "only \"true\" pauses")
+
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, ConditionTypePaused),
+		"the Paused condition is removed on resume")
+}
+
+func TestReconcile_PausedDeletionKeepsFinalizer(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	now := metav1.Now()
+	list := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "list",
+			Namespace:         "default",
+			Finalizers:        []string{AllowlistFinalizerName},
+			DeletionTimestamp: &now,
+			Annotations:       map[string]string{AnnotationPaused: "true"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSAllowlist{}).
+		Build()
+
+	r := &NextDNSAllowlistReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "list", Namespace: "default"}}
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result, "a paused resource is not requeued")
+
+	var current nextdnsv1alpha1.NextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &current))
+	assert.Contains(t, current.Finalizers, AllowlistFinalizerName)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, ConditionTypePaused))
+}
+
+func TestReconcilePaused_Namespace(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "tenant",
+			Annotations: map[string]string{AnnotationPaused: "true"},
+		},
+	}
+	list := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "list", Namespace: "tenant"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(namespace, list).
+		WithStatusSubresource(&nextdnsv1alpha1.NextDNSAllowlist{}).
+		Build()
+	key := types.NamespacedName{Name: "list", Namespace: "tenant"}
+
+	var current nextdnsv1alpha1.NextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	paused, err := reconcilePaused(ctx, fakeClient, &current, &current.Status.Conditions)
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	cond := meta.FindStatusCondition(current.Status.Conditions, ConditionTypePaused)
+	require.NotNil(t, cond)
+	assert.Equal(t, "PausedByNamespace", cond.Reason)
+	assert.Contains(t, cond.Message, "namespace tenant")
+
+	// The resource's own annotation takes precedence in the condition
+	current.Annotations = map[string]string{AnnotationPaused: "true"}
+	paused, err = reconcilePaused(ctx, fakeClient, &current, &current.Status.Conditions)
+	require.NoError(t, err)
+	assert.True(t, paused)
+	assert.Equal(t, "PausedByAnnotation", meta.FindStatusCondition(current.Status.Conditions, ConditionTypePaused).Reason)
+
+	// Resuming the namespace resumes the resources in it
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	namespace.Annotations = nil
+	require.NoError(t, fakeClient.Update(ctx, namespace))
+	paused, err = reconcilePaused(ctx, fakeClient, &current, &current.Status.Conditions)
+	require.NoError(t, err)
+	assert.False(t, paused)
+	assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, ConditionTypePaused))
+
+	// Cluster-scoped resources ignore namespaces
+	clusterList := &nextdnsv1alpha1.ClusterNextDNSAllowlist{ObjectMeta: metav1.ObjectMeta{Name: "shared"}}
+	paused, err = reconcilePaused(ctx, fakeClient, clusterList, &clusterList.Status.Conditions)
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestNamespacePausedChangedPredicate(t *testing.T) {
+	p := namespacePausedChangedPredicate()
+
+	running := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}
+	paused := running.DeepCopy()
+	paused.Annotations = map[string]string{AnnotationPaused: "true"}
+	labeled := running.DeepCopy()
+	labeled.Labels = map[string]string{"team": "dns"}
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: paused}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: running}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: labeled}))
+	assert.False(t, p.Create(event.CreateEvent{Object: paused}))
+}
+
+func TestEnqueueForNamespacePause(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&nextdnsv1alpha1.NextDNSAllowlist{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "tenant"}},
+			&nextdnsv1alpha1.NextDNSAllowlist{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "tenant"}},
+			&nextdnsv1alpha1.NextDNSAllowlist{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "other"}},
+		).
+		Build()
+
+	h := enqueueForNamespacePause(fakeClient, &nextdnsv1alpha1.NextDNSAllowlistList{})
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	running := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}
+	paused := running.DeepCopy()
+	paused.Annotations = map[string]string{AnnotationPaused: "true"}
+	h.Update(ctx, event.UpdateEvent{ObjectOld: running, ObjectNew: paused}, queue)
+
+	require.Equal(t, 2, queue.Len())
+	var names []string
+	for queue.Len() > 0 {
+		req, _ := queue.Get()
+		names = append(names, req.Name)
+		queue.Done(req)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, names)
+}
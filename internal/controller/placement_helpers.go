@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// placementRefreshInterval bounds how often status.placement is rebuilt from
+// the pod list. Rollouts trigger a reconcile for every workload status change,
+// so without a bound placement would churn on every pod transition.
+const placementRefreshInterval = 30 * time.Second
+
+// updatePlacement refreshes status.placement once the refresh interval has
+// elapsed or a resync was requested
+func (r *NextDNSCoreDNSReconciler) updatePlacement(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, now metav1.Time) {
+	if last := coreDNS.Status.PlacementUpdated; last != nil && now.Sub(last.Time) < placementRefreshInterval && !resyncRequested(coreDNS) {
+		return
+	}
+
+	placement, err := r.listPodPlacement(ctx, coreDNS)
+	if err != nil {
+		// Keep the last known placement rather than publishing an empty one
+		log.FromContext(ctx).Error(err, "Failed to resolve pod placement")
+		return
+	}
+	coreDNS.Status.Placement = placement
+	coreDNS.Status.PlacementUpdated = &now
+}
+
+// listPodPlacement groups the ready CoreDNS pods by node, sorted by zone and node name
+func (r *NextDNSCoreDNSReconciler) listPodPlacement(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]nextdnsv1alpha1.PodPlacement, error) {
+	podList := &corev1.PodList{}
+	labels := map[string]string{
+		"app.kubernetes.io/name":     "coredns",
+		"app.kubernetes.io/instance": coreDNS.Name,
+	}
+	if err := r.List(ctx, podList, client.InNamespace(coreDNS.Namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("failed to list CoreDNS pods: %w", err)
+	}
+
+	byNode := make(map[string]*nextdnsv1alpha1.PodPlacement)
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || !isPodReady(&pod) {
+			continue
+		}
+		if entry, ok := byNode[pod.Spec.NodeName]; ok {
+			entry.ReadyPods++
+			continue
+		}
+
+		entry := &nextdnsv1alpha1.PodPlacement{Node: pod.Spec.NodeName, ReadyPods: 1}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err == nil {
+			entry.Zone = node.Labels[corev1.LabelTopologyZone]
+		} else {
+			// The pod still serves DNS; only its zone is unknown
+			log.FromContext(ctx).V(1).Info("Unable to resolve node zone", "pod", pod.Name, "node", pod.Spec.NodeName, "error", err)
+		}
+		byNode[pod.Spec.NodeName] = entry
+	}
+
+	placement := make([]nextdnsv1alpha1.PodPlacement, 0, len(byNode))
+	for _, entry := range byNode {
+		placement = append(placement, *entry)
+	}
+	sort.Slice(placement, func(i, j int) bool {
+		if placement[i].Zone != placement[j].Zone {
+			return placement[i].Zone < placement[j].Zone
+		}
+		return placement[i].Node < placement[j].Node
+	})
+	return placement, nil
+}
+
+// placementRefreshAfter returns how long to wait before placement can catch up
+// with the workload's ready replica count, or 0 when it is already in sync
+func placementRefreshAfter(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, now time.Time) time.Duration {
+	var ready int32
+	if coreDNS.Status.Replicas != nil {
+		ready = coreDNS.Status.Replicas.Ready
+	}
+	var placed int32
+	for _, entry := range coreDNS.Status.Placement {
+		placed += entry.ReadyPods
+	}
+	if placed == ready || coreDNS.Status.PlacementUpdated == nil {
+		return 0
+	}
+
+	wait := placementRefreshInterval - now.Sub(coreDNS.Status.PlacementUpdated.Time)
+	if wait <= 0 {
+		// Due now; the pod cache may simply lag the workload status
+		return time.Second
+	}
+	return wait
+}
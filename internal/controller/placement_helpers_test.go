@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newZonedNode(name, zone string) *corev1.Node {
+	node := newNode(name, "", "")
+	if zone != "" {
+		node.Labels = map[string]string{corev1.LabelTopologyZone: zone}
+	}
+	return node
+}
+
+func TestListPodPlacement(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	coreDNS := newHostPortCoreDNS(nil)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newZonedNode("node-a", "zone-b"),
+			newZonedNode("node-b", "zone-a"),
+			newZonedNode("node-c", "zone-a"),
+			newCoreDNSPod("dns-1", "node-a", true),
+			newCoreDNSPod("dns-2", "node-a", true),
+			newCoreDNSPod("dns-3", "node-b", true),
+			newCoreDNSPod("dns-4", "node-c", false),
+			newCoreDNSPod("dns-5", "", true),
+			newCoreDNSPod("dns-6", "node-gone", true),
+		).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+
+	placement, err := r.listPodPlacement(context.Background(), coreDNS)
+	require.NoError(t, err)
+	assert.Equal(t, []nextdnsv1alpha1.PodPlacement{
+		{Node: "node-gone", ReadyPods: 1},
+		{Node: "node-b", Zone: "zone-a", ReadyPods: 1},
+		{Node: "node-a", Zone: "zone-b", ReadyPods: 2},
+	}, placement, "unready and unscheduled pods are skipped; missing nodes have no zone")
+}
+
+func TestUpdatePlacement_RefreshInterval(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	coreDNS := newHostPortCoreDNS(nil)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newZonedNode("node-a", "zone-a"), newCoreDNSPod("dns-1", "node-a", true)).
+		Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	start := metav1.Now()
+	r.updatePlacement(ctx, coreDNS, start)
+	require.Len(t, coreDNS.Status.Placement, 1)
+	assert.Equal(t, start, *coreDNS.Status.PlacementUpdated)
+
+	// A new pod within the refresh interval is not picked up yet
+	require.NoError(t, fakeClient.Create(ctx, newCoreDNSPod("dns-2", "node-a", true)))
+	r.updatePlacement(ctx, coreDNS, metav1.NewTime(start.Add(10*time.Second)))
+	assert.Equal(t, int32(1), coreDNS.Status.Placement[0].ReadyPods)
+	assert.Equal(t, start, *coreDNS.Status.PlacementUpdated)
+
+	later := metav1.NewTime(start.Add(placementRefreshInterval))
+	r.updatePlacement(ctx, coreDNS, later)
+	assert.Equal(t, int32(2), coreDNS.Status.Placement[0].ReadyPods)
+	assert.Equal(t, later, *coreDNS.Status.PlacementUpdated)
+}
+
+func TestPlacementRefreshAfter(t *testing.T) {
+	now := time.Now()
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Status: nextdnsv1alpha1.NextDNSCoreDNSStatus{
+			Replicas:         &nextdnsv1alpha1.ReplicaStatus{Ready: 2},
+			Placement:        []nextdnsv1alpha1.PodPlacement{{Node: "node-a", ReadyPods: 2}},
+			PlacementUpdated: &metav1.Time{Time: now.Add(-10 * time.Second)},
+		},
+	}
+	assert.Zero(t, placementRefreshAfter(coreDNS, now), "in sync with the workload")
+
+	coreDNS.Status.Replicas.Ready = 3
+	assert.Equal(t, 20*time.Second, placementRefreshAfter(coreDNS, now))
+
+	coreDNS.Status.PlacementUpdated = &metav1.Time{Time: now.Add(-time.Minute)}
+	assert.Equal(t, time.Second, placementRefreshAfter(coreDNS, now))
+
+	coreDNS.Status.Replicas = nil
+	coreDNS.Status.Placement = nil
+	assert.Zero(t, placementRefreshAfter(coreDNS, now))
+}
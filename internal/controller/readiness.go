@@ -0,0 +1,28 @@
+package controller
+
+import (
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// minReadyReplicas returns the spec.readiness.minReadyReplicas of coreDNS,
+// or 0 when every desired pod must be ready
+func minReadyReplicas(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) int32 {
+	if r := coreDNS.Spec.Readiness; r != nil && r.MinReadyReplicas != nil {
+		return *r.MinReadyReplicas
+	}
+	return 0
+}
+
+// workloadReady reports whether the CoreDNS workload with replicas counts as
+// Ready: every desired pod is ready, or at least spec.readiness.minReadyReplicas
+// pods are available. A workload without ready pods is never Ready.
+func workloadReady(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, replicas nextdnsv1alpha1.ReplicaStatus) bool {
+	if replicas.Ready <= 0 {
+		return false
+	}
+	if replicas.Ready >= replicas.Desired {
+		return true
+	}
+	minimum := minReadyReplicas(coreDNS)
+	return minimum > 0 && replicas.Available >= minimum
+}
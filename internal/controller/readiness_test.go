@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestWorkloadReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		minimum  *int32
+		replicas nextdnsv1alpha1.ReplicaStatus
+		want     bool
+	}{
+		{name: "all ready", replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 3, Available: 3}, want: true},
+		{name: "one missing", replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 2, Available: 2}},
+		{name: "none ready", replicas: nextdnsv1alpha1.ReplicaStatus{}},
+		{name: "minimum met", minimum: ptr.To[int32](2), replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 2, Available: 2}, want: true},
+		{name: "minimum counts available pods", minimum: ptr.To[int32](2), replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 2, Available: 1}},
+		{name: "minimum not met", minimum: ptr.To[int32](2), replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 1, Available: 1}},
+		{name: "minimum above desired", minimum: ptr.To[int32](5), replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Ready: 3, Available: 3}, want: true},
+		{name: "minimum with no ready pods", minimum: ptr.To[int32](1), replicas: nextdnsv1alpha1.ReplicaStatus{Desired: 3, Available: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+			if tt.minimum != nil {
+				coreDNS.Spec.Readiness = &nextdnsv1alpha1.CoreDNSReadinessConfig{MinReadyReplicas: tt.minimum}
+			}
+			assert.Equal(t, tt.want, workloadReady(coreDNS, tt.replicas))
+		})
+	}
+}
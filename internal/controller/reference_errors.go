@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// ReasonCrossNamespaceAccessDenied is set when a referenced list lives in
+	// a namespace the operator is not allowed to read or does not watch
+	ReasonCrossNamespaceAccessDenied = "CrossNamespaceAccessDenied"
+
+	// ReasonReferenceNotFound is set when a referenced list does not exist
+	ReasonReferenceNotFound = "ReferenceNotFound"
+
+	// reasonResolutionFailed is set for any other list resolution failure
+	reasonResolutionFailed = "ResolutionFailed"
+)
+
+// unknownNamespaceMessage is returned by a namespace-scoped cache for objects
+// outside the namespaces it watches
+const unknownNamespaceMessage = "unknown namespace for the cache"
+
+// listReferenceError describes a list reference that could not be read
+type listReferenceError struct {
+	kind      string
+	namespace string
+	name      string
+	reason    string
+	err       error
+}
+
+func (e *listReferenceError) Error() string {
+	if e.reason == ReasonCrossNamespaceAccessDenied {
+		return fmt.Sprintf("access to %s %s/%s denied; grant the operator permission to read it in namespace %s: %v",
+			e.kind, e.namespace, e.name, e.namespace, e.err)
+	}
+	if e.namespace == "" {
+		return fmt.Sprintf("failed to get %s %s: %v", e.kind, e.name, e.err)
+	}
+	return fmt.Sprintf("failed to get %s %s/%s: %v", e.kind, e.namespace, e.name, e.err)
+}
+
+func (e *listReferenceError) Unwrap() error {
+	return e.err
+}
+
+// newListReferenceError classifies err from reading a referenced list. An
+// empty namespace denotes a cluster-scoped list. Forbidden errors and
+// namespace-scoped cache misses for lists outside the profile's namespace
+// are reported as access denied, since they are fixed with RBAC or the
+// watched namespaces rather than the reference itself.
+func newListReferenceError(kind, profileNamespace, namespace, name string, err error) error {
+	reason := reasonResolutionFailed
+	switch {
+	case apierrors.IsNotFound(err):
+		reason = ReasonReferenceNotFound
+	case namespace != "" && namespace != profileNamespace && (apierrors.IsForbidden(err) || strings.Contains(err.Error(), unknownNamespaceMessage)):
+		reason = ReasonCrossNamespaceAccessDenied
+	}
+	return &listReferenceError{kind: kind, namespace: namespace, name: name, reason: reason, err: err}
+}
+
+// listReferenceErrorReason returns the condition reason for a list resolution
+// error
+func listReferenceErrorReason(err error) string {
+	var refErr *listReferenceError
+	if errors.As(err, &refErr) {
+		return refErr.reason
+	}
+	return reasonResolutionFailed
+}
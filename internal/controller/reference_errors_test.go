@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestNewListReferenceError(t *testing.T) {
+	gr := schema.GroupResource{Group: "nextdns.io", Resource: "nextdnsallowlists"}
+
+	tests := []struct {
+		name      string
+		namespace string
+		err       error
+		want      string
+	}{
+		{name: "not found", namespace: "shared", err: apierrors.NewNotFound(gr, "list"), want: ReasonReferenceNotFound},
+		{name: "forbidden across namespaces", namespace: "shared", err: apierrors.NewForbidden(gr, "list", errors.New("rbac")), want: ReasonCrossNamespaceAccessDenied},
+		{
+			name:      "namespace not watched",
+			namespace: "shared",
+			err:       errors.New("unable to get: shared/list because of unknown namespace for the cache"),
+			want:      ReasonCrossNamespaceAccessDenied,
+		},
+		{name: "forbidden in own namespace", namespace: "default", err: apierrors.NewForbidden(gr, "list", errors.New("rbac")), want: reasonResolutionFailed},
+		{name: "other error", namespace: "shared", err: errors.New("timeout"), want: reasonResolutionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newListReferenceError("allowlist", "default", tt.namespace, "list", tt.err)
+			assert.Equal(t, tt.want, listReferenceErrorReason(err))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestResolveListReferences_CrossNamespaceAccessDenied(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if key.Namespace == "shared" {
+					return apierrors.NewForbidden(schema.GroupResource{Group: "nextdns.io", Resource: "nextdnsdenylists"}, key.Name, errors.New("rbac"))
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+	reconciler := &NextDNSProfileReconciler{Client: fakeClient, Scheme: scheme}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:         "profile",
+			DenylistRefs: []nextdnsv1alpha1.ListReference{{Name: "ads", Namespace: "shared"}},
+		},
+	}
+
+	_, err := reconciler.resolveListReferences(ctx, profile)
+	require.Error(t, err)
+	assert.Equal(t, ReasonCrossNamespaceAccessDenied, listReferenceErrorReason(err))
+	assert.Contains(t, err.Error(), "denylist shared/ads")
+
+	// A missing list in the same namespace is reported distinctly
+	profile.Spec.DenylistRefs = []nextdnsv1alpha1.ListReference{{Name: "typo"}}
+	_, err = reconciler.resolveListReferences(ctx, profile)
+	require.Error(t, err)
+	assert.Equal(t, ReasonReferenceNotFound, listReferenceErrorReason(err))
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ResourceLabels are added to every object the operator creates, e.g. for
+// cost attribution or policy engines. They are never added to selectors.
+type ResourceLabels map[string]string
+
+// ParseResourceLabels parses a comma-separated list of key=value labels
+func ParseResourceLabels(s string) (ResourceLabels, error) {
+	labels := ResourceLabels{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", pair)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value for label %q: %s", key, strings.Join(errs, "; "))
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// String returns the labels in key=value,... form, sorted by key
+func (l ResourceLabels) String() string {
+	pairs := make([]string, 0, len(l))
+	for k, v := range l {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// merge returns a copy of labels with the resource labels added. Keys
+// already in labels win, so the operator's own labels are never replaced.
+func (l ResourceLabels) merge(labels map[string]string) map[string]string {
+	if len(l) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(l))
+	for k, v := range l {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// set adds the resource labels to obj, keeping its other labels
+func (l ResourceLabels) set(obj metav1.Object) {
+	if len(l) == 0 {
+		return
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, len(l))
+	}
+	for k, v := range l {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestParseResourceLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ResourceLabels
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: ResourceLabels{}},
+		{name: "single", input: "team=platform", want: ResourceLabels{"team": "platform"}},
+		{
+			name:  "multiple with spaces",
+			input: "team=platform, example.com/cost-center=dns ,",
+			want:  ResourceLabels{"team": "platform", "example.com/cost-center": "dns"},
+		},
+		{name: "empty value", input: "team=", want: ResourceLabels{"team": ""}},
+		{name: "missing value", input: "team", wantErr: true},
+		{name: "invalid key", input: "-team=platform", wantErr: true},
+		{name: "invalid value", input: "team=platform team", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResourceLabels(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResourceLabels_String(t *testing.T) {
+	labels := ResourceLabels{"team": "platform", "cost-center": "dns"}
+	assert.Equal(t, "cost-center=dns,team=platform", labels.String())
+}
+
+func TestResourceLabels_Merge(t *testing.T) {
+	labels := ResourceLabels{"team": "platform", "app.kubernetes.io/name": "other"}
+	own := map[string]string{"app.kubernetes.io/name": "coredns"}
+
+	merged := labels.merge(own)
+	assert.Equal(t, map[string]string{
+		"team":                   "platform",
+		"app.kubernetes.io/name": "coredns",
+	}, merged)
+	assert.Len(t, own, 1, "input labels should not be modified")
+
+	assert.Equal(t, own, ResourceLabels(nil).merge(own))
+}
+
+func TestResourceLabels_Set(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"keep": "me", "team": "old"}}}
+	ResourceLabels{"team": "platform"}.set(cm)
+	assert.Equal(t, map[string]string{"keep": "me", "team": "platform"}, cm.Labels)
+
+	unlabeled := &corev1.ConfigMap{}
+	ResourceLabels{"team": "platform"}.set(unlabeled)
+	assert.Equal(t, map[string]string{"team": "platform"}, unlabeled.Labels)
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_ResourceLabels(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{{
+				Type:               ConditionTypeReady,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Ready",
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-coredns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(profile, coreDNS).
+		Build()
+
+	reconciler := &NextDNSCoreDNSReconciler{
+		Client:         fakeClient,
+		Scheme:         scheme,
+		ResourceLabels: ResourceLabels{"team": "platform"},
+	}
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-coredns", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	key := types.NamespacedName{Name: "test-coredns-abc123-coredns", Namespace: "default"}
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(ctx, key, configMap))
+	assert.Equal(t, "platform", configMap.Labels["team"])
+
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, key, service))
+	assert.Equal(t, "platform", service.Labels["team"])
+	assert.NotContains(t, service.Spec.Selector, "team", "resource labels must not be added to selectors")
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, key, deployment))
+	assert.Equal(t, "platform", deployment.Labels["team"])
+	assert.Equal(t, "platform", deployment.Spec.Template.Labels["team"])
+	assert.NotContains(t, deployment.Spec.Selector.MatchLabels, "team", "resource labels must not be added to selectors")
+	assert.Equal(t, "coredns", deployment.Labels["app.kubernetes.io/name"])
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// AnnotationResync requests an immediate full sync of a resource. Any value
+// works; a timestamp makes repeated requests distinct. The annotation is
+// removed once the sync was processed.
+const AnnotationResync = "nextdns.io/resync"
+
+// resyncRequested reports whether obj carries the resync annotation
+func resyncRequested(obj client.Object) bool {
+	_, ok := obj.GetAnnotations()[AnnotationResync]
+	return ok
+}
+
+// clearResync removes the resync annotation from obj. The removal only
+// applies while the annotation still holds the processed value, so a
+// request made during the sync is kept and processed next.
+func clearResync(ctx context.Context, c client.Client, obj client.Object) error {
+	return clearAnnotation(ctx, c, obj, AnnotationResync)
+}
+
+// clearAnnotation removes the annotation key from obj, unless its value
+// changed since obj was read
+func clearAnnotation(ctx context.Context, c client.Client, obj client.Object, key string) error {
+	value, ok := obj.GetAnnotations()[key]
+	if !ok {
+		return nil
+	}
+
+	path := "/metadata/annotations/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+	patch, err := json.Marshal([]map[string]string{
+		{"op": "test", "path": path, "value": value},
+		{"op": "remove", "path": path},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build %s patch: %w", key, err)
+	}
+	if err := c.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return fmt.Errorf("failed to clear %s annotation: %w", key, err)
+	}
+	return nil
+}
+
+// resyncClearedPredicate drops the update event caused by clearResync, so
+// removing the annotation does not trigger another sync
+func resyncClearedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			return !isResyncCleared(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// isResyncCleared reports whether the only change from oldObj to newObj is
+// the removal of the resync annotation
+func isResyncCleared(oldObj, newObj client.Object) bool {
+	return isAnnotationCleared(oldObj, newObj, AnnotationResync)
+}
+
+// isAnnotationCleared reports whether the only change from oldObj to newObj
+// is the removal of the annotation key
+func isAnnotationCleared(oldObj, newObj client.Object, key string) bool {
+	if _, ok := oldObj.GetAnnotations()[key]; !ok {
+		return false
+	}
+	if _, ok := newObj.GetAnnotations()[key]; ok {
+		return false
+	}
+	if oldObj.GetGeneration() != newObj.GetGeneration() ||
+		!oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) {
+		return false
+	}
+
+	oldAnnotations := make(map[string]string, len(oldObj.GetAnnotations()))
+	for k, v := range oldObj.GetAnnotations() {
+		if k != key {
+			oldAnnotations[k] = v
+		}
+	}
+	return equality.Semantic.DeepEqual(oldAnnotations, newObj.GetAnnotations()) &&
+		equality.Semantic.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) &&
+		equality.Semantic.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) &&
+		equality.Semantic.DeepEqual(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences())
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/listsource"
+)
+
+func TestResyncClearedPredicate(t *testing.T) {
+	requested := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "list",
+			Generation:  1,
+			Annotations: map[string]string{AnnotationResync: "2026-10-16T10:00:00Z", "team": "dns"},
+		},
+	}
+	cleared := requested.DeepCopy()
+	cleared.Annotations = map[string]string{"team": "dns"}
+
+	p := resyncClearedPredicate()
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: cleared}), "own cleanup is ignored")
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: cleared, ObjectNew: requested}), "a new request triggers a sync")
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: requested}))
+
+	edited := cleared.DeepCopy()
+	edited.Generation = 2
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: edited}), "spec changes still sync")
+
+	relabeled := cleared.DeepCopy()
+	relabeled.Labels = map[string]string{"tier": "home"}
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: relabeled}))
+}
+
+func TestClearResync(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	list := &nextdnsv1alpha1.NextDNSAllowlist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "list",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationResync: "1", "team": "dns"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(list).Build()
+	key := types.NamespacedName{Name: "list", Namespace: "default"}
+
+	// A request made while the sync ran is kept
+	var current nextdnsv1alpha1.NextDNSAllowlist
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	processed := current.DeepCopy()
+	current.Annotations[AnnotationResync] = "2"
+	require.NoError(t, fakeClient.Update(ctx, &current))
+	assert.Error(t, clearResync(ctx, fakeClient, processed))
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	assert.Equal(t, "2", current.Annotations[AnnotationResync])
+
+	require.NoError(t, clearResync(ctx, fakeClient, &current))
+	require.NoError(t, fakeClient.Get(ctx, key, &current))
+	assert.Equal(t, map[string]string{"team": "dns"}, current.Annotations)
+
+	// Nothing to clear
+	require.NoError(t, clearResync(ctx, fakeClient, &current))
+}
+
+func TestNextDNSDenylistReconciler_Reconcile_Resync(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte("ads.example.com\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	list := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sourced",
+			Namespace:  "default",
+			Finalizers: []string{DenylistFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+			Sources: []nextdnsv1alpha1.ListSource{{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"}}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(list).
+		WithStatusSubresource(list).
+		Build()
+	r := &NextDNSDenylistReconciler{
+		Client:      fakeClient,
+		Scheme:      scheme,
+		SyncPeriod:  time.Hour,
+		ListSources: listsource.NewCache(),
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sourced", Namespace: "default"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "sources are fetched once per interval")
+
+	var updated nextdnsv1alpha1.NextDNSDenylist
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Annotations = map[string]string{AnnotationResync: "2026-10-16T10:00:00Z"}
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "a resync request fetches the sources again")
+
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.NotContains(t, updated.Annotations, AnnotationResync)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// systemNodeCriticalPriorityClass is the built-in priority class for pods
+// that must run on every node, such as node-local DNS
+const systemNodeCriticalPriorityClass = "system-node-critical"
+
+// criticalAddonEnabled reports whether the critical addon preset applies.
+// The preset is only honored in DaemonSet mode, where each node runs one pod.
+func criticalAddonEnabled(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	d := coreDNS.Spec.Deployment
+	return d != nil && d.Mode == nextdnsv1alpha1.DeploymentModeDaemonSet && d.CriticalAddon
+}
+
+// criticalAddonTolerations returns the tolerations applied by the critical
+// addon preset. They keep DNS pods scheduled and running on nodes that are
+// cordoned, not ready, unreachable or under resource pressure.
+func criticalAddonTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{Key: "CriticalAddonsOnly", Operator: corev1.TolerationOpExists},
+		{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}
+}
+
+// mergeTolerations appends each preset toleration not already present in
+// tolerations, keeping user-supplied entries first and unchanged
+func mergeTolerations(tolerations, preset []corev1.Toleration) []corev1.Toleration {
+	merged := append([]corev1.Toleration{}, tolerations...)
+	for _, p := range preset {
+		found := false
+		for _, t := range tolerations {
+			if t.MatchToleration(&p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// topologySpreadConstraints returns the spread constraints of the CoreDNS
+// pods. Constraints without a label selector get one matching the pods of
+// this instance, since a constraint selecting no pods spreads nothing.
+func topologySpreadConstraints(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) []corev1.TopologySpreadConstraint {
+	d := coreDNS.Spec.Deployment
+	if d == nil || len(d.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	constraints := make([]corev1.TopologySpreadConstraint, len(d.TopologySpreadConstraints))
+	for i := range d.TopologySpreadConstraints {
+		d.TopologySpreadConstraints[i].DeepCopyInto(&constraints[i])
+		if constraints[i].LabelSelector == nil {
+			constraints[i].LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app.kubernetes.io/name":     "coredns",
+					"app.kubernetes.io/instance": coreDNS.Name,
+				},
+			}
+		}
+	}
+	return constraints
+}
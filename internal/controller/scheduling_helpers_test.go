@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newCriticalAddonCoreDNS(mode nextdnsv1alpha1.DeploymentMode, tolerations []corev1.Toleration) *nextdnsv1alpha1.NextDNSCoreDNS {
+	return &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode:          mode,
+				CriticalAddon: true,
+				Tolerations:   tolerations,
+			},
+		},
+	}
+}
+
+func TestBuildPodSpec_CriticalAddon(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	podSpec := r.buildPodSpec(newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, nil), "test-cm")
+	assert.Equal(t, "system-node-critical", podSpec.PriorityClassName)
+	assert.Equal(t, criticalAddonTolerations(), podSpec.Tolerations)
+
+	// Ignored outside DaemonSet mode
+	podSpec = r.buildPodSpec(newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDeployment, nil), "test-cm")
+	assert.Empty(t, podSpec.PriorityClassName)
+	assert.Nil(t, podSpec.Tolerations)
+}
+
+func TestBuildPodSpec_CriticalAddonKeepsUserTolerations(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	user := []corev1.Toleration{
+		{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "CriticalAddonsOnly", Operator: corev1.TolerationOpExists},
+	}
+	podSpec := r.buildPodSpec(newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, user), "test-cm")
+
+	assert.Equal(t, user, podSpec.Tolerations[:2], "user tolerations should come first and be unchanged")
+	assert.Len(t, podSpec.Tolerations, len(criticalAddonTolerations())+1, "duplicate preset entries should be skipped")
+}
+
+func TestBuildPodSpec_PriorityClassName(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	coreDNS := newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDaemonSet, nil)
+	coreDNS.Spec.Deployment.PriorityClassName = "dns-critical"
+	podSpec := r.buildPodSpec(coreDNS, "test-cm")
+	assert.Equal(t, "dns-critical", podSpec.PriorityClassName, "explicit class should override the preset")
+	assert.Equal(t, criticalAddonTolerations(), podSpec.Tolerations)
+
+	coreDNS.Spec.Deployment.Mode = nextdnsv1alpha1.DeploymentModeDeployment
+	podSpec = r.buildPodSpec(coreDNS, "test-cm")
+	assert.Equal(t, "dns-critical", podSpec.PriorityClassName)
+}
+
+func TestBuildPodSpec_TopologySpreadConstraints(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+
+	custom := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "dns"}}
+	coreDNS := newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDeployment, nil)
+	coreDNS.Spec.Deployment.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule},
+		{MaxSkew: 1, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: custom},
+	}
+	podSpec := r.buildPodSpec(coreDNS, "test-cm")
+
+	assert.Equal(t, []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+				"app.kubernetes.io/name":     "coredns",
+				"app.kubernetes.io/instance": "home-dns",
+			}},
+		},
+		{MaxSkew: 1, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: custom},
+	}, podSpec.TopologySpreadConstraints)
+	assert.Nil(t, coreDNS.Spec.Deployment.TopologySpreadConstraints[0].LabelSelector, "spec should not be modified")
+
+	assert.Nil(t, r.buildPodSpec(newCriticalAddonCoreDNS(nextdnsv1alpha1.DeploymentModeDeployment, nil), "test-cm").TopologySpreadConstraints)
+}
+
+func TestMergeTolerations(t *testing.T) {
+	tolerations := []corev1.Toleration{
+		{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	}
+	preset := []corev1.Toleration{
+		{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	}
+
+	merged := mergeTolerations(tolerations, preset)
+	assert.Equal(t, preset, merged)
+	assert.Len(t, tolerations, 1, "input slice should not be modified")
+	assert.Equal(t, preset, mergeTolerations(nil, preset))
+}
@@ -0,0 +1,455 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// AnnotationReplicate opts a credentials Secret into replication when set
+	// to "true"
+	AnnotationReplicate = "nextdns.io/replicate"
+
+	// AnnotationReplicateTo lists, comma-separated, the namespaces a
+	// replication source may be replicated to
+	AnnotationReplicateTo = "nextdns.io/replicate-to"
+
+	// AnnotationReplicatedFrom records the namespace/name of the source of a
+	// replicated Secret
+	AnnotationReplicatedFrom = "nextdns.io/replicated-from"
+
+	// AnnotationReplicaHash records the content hash of the source data a
+	// replicated Secret was written from
+	AnnotationReplicaHash = "nextdns.io/replica-hash"
+
+	// LabelReplica marks Secrets written by the replication controller
+	LabelReplica = "nextdns.io/replica"
+
+	// replicationSourceIndexField is the field index key for looking up
+	// replication source Secrets by name
+	replicationSourceIndexField = ".metadata.replicationSourceName"
+)
+
+// SecretReplicationReconciler replicates credentials Secrets annotated with
+// nextdns.io/replicate into the namespaces listed in their
+// nextdns.io/replicate-to annotation that hold a NextDNSProfile whose
+// credentialsRef names a Secret of the same name in its own namespace.
+// Replicas are rewritten when their data no longer matches the source and
+// deleted once no profile in their namespace references them. Existing
+// Secrets that are not replicas of the source are never touched, and a
+// namespace two sources of the same name replicate to is left to neither.
+type SecretReplicationReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	ResourceLabels ResourceLabels
+
+	// Recorder emits Kubernetes events; none are emitted when nil
+	Recorder record.EventRecorder
+
+	// Workqueue tunes the controller's workqueue and concurrency;
+	// DefaultWorkqueueConfig is used when nil
+	Workqueue *WorkqueueConfig
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile replicates one source Secret
+func (r *SecretReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	sourceKey := req.String()
+
+	var source corev1.Secret
+	if err := r.Get(ctx, req.NamespacedName, &source); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		// The source is gone: remove all of its replicas
+		return ctrl.Result{}, r.pruneReplicas(ctx, sourceKey, nil)
+	}
+
+	if !isReplicationSource(&source) || !source.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.pruneReplicas(ctx, sourceKey, nil)
+	}
+
+	if len(replicationTargets(&source)) == 0 {
+		recordEvent(r.Recorder, &source, corev1.EventTypeWarning, "ReplicationTargetsMissing",
+			"No namespaces are listed in the %s annotation; the Secret is not replicated", AnnotationReplicateTo)
+	}
+
+	namespaces, err := r.targetNamespaces(ctx, &source)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	conflicts, err := r.conflictingNamespaces(ctx, &source, namespaces)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	hash := secretDataHash(source.Data)
+	for namespace := range namespaces {
+		if other, ok := conflicts[namespace]; ok {
+			// Keep whatever replica is there rather than flipping it
+			// between the sources on every reconcile
+			logger.Info("Another source replicates to the namespace, skipping",
+				"namespace", namespace, "other", other)
+			recordEvent(r.Recorder, &source, corev1.EventTypeWarning, "ReplicaConflict",
+				"Secret %s is also replicated to namespace %s, skipping", other, namespace)
+			continue
+		}
+		if err := r.replicate(ctx, &source, namespace, hash); err != nil {
+			logger.Error(err, "Failed to replicate Secret", "namespace", namespace)
+			recordEvent(r.Recorder, &source, corev1.EventTypeWarning, "ReplicationFailed", "%v", err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Replicas in contested namespaces are kept until the conflict is resolved
+	return ctrl.Result{}, r.pruneReplicas(ctx, sourceKey, namespaces)
+}
+
+// targetNamespaces returns the namespaces the source lists in its
+// nextdns.io/replicate-to annotation, other than its own, holding a
+// NextDNSProfile whose credentials resolve to a Secret named like the source
+// in the profile's own namespace. Profiles being deleted still count, as
+// their finalizer needs the credentials.
+func (r *SecretReplicationReconciler) targetNamespaces(ctx context.Context, source *corev1.Secret) (map[string]bool, error) {
+	var profiles nextdnsv1alpha1.NextDNSProfileList
+	if err := r.List(ctx, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to list NextDNSProfiles: %w", err)
+	}
+
+	namespaces := map[string]bool{}
+	for _, profile := range profiles.Items {
+		ref := profile.Spec.CredentialsRef
+		if ref.Name != source.Name || profile.Namespace == source.Namespace || !replicatesTo(source, profile.Namespace) {
+			continue
+		}
+		if ref.Namespace != "" && ref.Namespace != profile.Namespace {
+			continue
+		}
+		namespaces[profile.Namespace] = true
+	}
+	return namespaces, nil
+}
+
+// conflictingNamespaces returns the namespaces of namespaces that another
+// replication source of the same name also replicates to, with the
+// namespace/name of that source
+func (r *SecretReplicationReconciler) conflictingNamespaces(ctx context.Context, source *corev1.Secret, namespaces map[string]bool) (map[string]string, error) {
+	var sources corev1.SecretList
+	if err := r.List(ctx, &sources, client.MatchingFields{replicationSourceIndexField: source.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list replication sources: %w", err)
+	}
+
+	conflicts := map[string]string{}
+	for i := range sources.Items {
+		other := &sources.Items[i]
+		if other.Namespace == source.Namespace || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for namespace := range namespaces {
+			if namespace != other.Namespace && replicatesTo(other, namespace) {
+				conflicts[namespace] = client.ObjectKeyFromObject(other).String()
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// replicate writes the replica of source in namespace unless it already
+// holds the source data. A Secret of the same name that is not a replica of
+// source is left alone.
+func (r *SecretReplicationReconciler) replicate(ctx context.Context, source *corev1.Secret, namespace, hash string) error {
+	logger := log.FromContext(ctx)
+	sourceKey := client.ObjectKeyFromObject(source).String()
+
+	var replica corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{Name: source.Name, Namespace: namespace}, &replica)
+	switch {
+	case apierrors.IsNotFound(err):
+		replica = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: namespace},
+			Type:       source.Type,
+		}
+		r.setReplicaData(&replica, source, hash)
+		if err := r.Create(ctx, &replica); err != nil {
+			return fmt.Errorf("failed to create replica %s/%s: %w", namespace, source.Name, err)
+		}
+		logger.Info("Replicated Secret", "source", sourceKey, "namespace", namespace)
+		recordEvent(r.Recorder, source, corev1.EventTypeNormal, "SecretReplicated", "Replicated Secret to namespace %s", namespace)
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get replica %s/%s: %w", namespace, source.Name, err)
+	}
+
+	if replica.Annotations[AnnotationReplicatedFrom] != sourceKey {
+		logger.Info("Secret exists and is not a replica of the source, skipping",
+			"source", sourceKey, "namespace", namespace)
+		recordEvent(r.Recorder, source, corev1.EventTypeWarning, "ReplicaConflict",
+			"Secret %s/%s exists and is not a replica, skipping", namespace, source.Name)
+		return nil
+	}
+
+	// Verify the data itself, not just the recorded hash, so edits made to
+	// the replica are reverted
+	if replica.Annotations[AnnotationReplicaHash] == hash && secretDataHash(replica.Data) == hash {
+		return nil
+	}
+
+	r.setReplicaData(&replica, source, hash)
+	if err := r.Update(ctx, &replica); err != nil {
+		return fmt.Errorf("failed to update replica %s/%s: %w", namespace, source.Name, err)
+	}
+	logger.Info("Updated replicated Secret", "source", sourceKey, "namespace", namespace)
+	recordEvent(r.Recorder, source, corev1.EventTypeNormal, "SecretReplicated", "Updated replica in namespace %s", namespace)
+	return nil
+}
+
+// setReplicaData copies the data of source to replica and marks it as its
+// replica
+func (r *SecretReplicationReconciler) setReplicaData(replica, source *corev1.Secret, hash string) {
+	r.ResourceLabels.set(replica)
+	if replica.Labels == nil {
+		replica.Labels = map[string]string{}
+	}
+	replica.Labels[LabelReplica] = "true"
+	if replica.Annotations == nil {
+		replica.Annotations = map[string]string{}
+	}
+	replica.Annotations[AnnotationReplicatedFrom] = client.ObjectKeyFromObject(source).String()
+	replica.Annotations[AnnotationReplicaHash] = hash
+	replica.Data = make(map[string][]byte, len(source.Data))
+	for k, v := range source.Data {
+		replica.Data[k] = v
+	}
+}
+
+// pruneReplicas deletes the replicas of the source outside keep
+func (r *SecretReplicationReconciler) pruneReplicas(ctx context.Context, sourceKey string, keep map[string]bool) error {
+	logger := log.FromContext(ctx)
+
+	var replicas corev1.SecretList
+	if err := r.List(ctx, &replicas, client.MatchingLabels{LabelReplica: "true"}); err != nil {
+		return fmt.Errorf("failed to list replicated Secrets: %w", err)
+	}
+
+	for i := range replicas.Items {
+		replica := &replicas.Items[i]
+		if replica.Annotations[AnnotationReplicatedFrom] != sourceKey || keep[replica.Namespace] {
+			continue
+		}
+		if err := r.Delete(ctx, replica); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete replica %s/%s: %w", replica.Namespace, replica.Name, err)
+		}
+		logger.Info("Deleted replicated Secret", "source", sourceKey, "namespace", replica.Namespace)
+	}
+	return nil
+}
+
+// isReplicationSource reports whether a Secret opted into replication
+func isReplicationSource(obj client.Object) bool {
+	return obj.GetAnnotations()[AnnotationReplicate] == "true"
+}
+
+// replicationTargets returns the namespaces in the nextdns.io/replicate-to
+// annotation of source
+func replicationTargets(source *corev1.Secret) map[string]bool {
+	targets := map[string]bool{}
+	for _, namespace := range strings.Split(source.Annotations[AnnotationReplicateTo], ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			targets[namespace] = true
+		}
+	}
+	return targets
+}
+
+// replicatesTo reports whether source may be replicated to namespace: the
+// namespace is listed in its nextdns.io/replicate-to annotation and the
+// cross-namespace credentials policy lets it use the source, as it would if
+// its profiles referenced the source directly. Profiles read a replica as a
+// Secret of their own namespace, so the policy must be applied here.
+func replicatesTo(source *corev1.Secret, namespace string) bool {
+	return replicationTargets(source)[namespace] && credentialsGranted(source.Annotations, namespace)
+}
+
+// secretDataHash returns the SHA-256 content hash of Secret data
+func secretDataHash(data map[string][]byte) string {
+	// Maps marshal with sorted keys, so the hash is stable
+	encoded, _ := json.Marshal(data)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// replicationSourceIndexFunc indexes replication source Secrets by name
+func replicationSourceIndexFunc(obj client.Object) []string {
+	if !isReplicationSource(obj) {
+		return nil
+	}
+	return []string{obj.GetName()}
+}
+
+// findSourcesForProfile returns reconcile requests for the replication
+// sources named like the Secret a profile's credentials reference
+func (r *SecretReplicationReconciler) findSourcesForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	profile, ok := obj.(*nextdnsv1alpha1.NextDNSProfile)
+	if !ok {
+		return nil
+	}
+
+	var sources corev1.SecretList
+	if err := r.List(ctx, &sources, client.MatchingFields{replicationSourceIndexField: profile.Spec.CredentialsRef.Name}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list replication sources for profile watch")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, source := range sources.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: source.Name, Namespace: source.Namespace},
+		})
+	}
+	return requests
+}
+
+// profileHandler returns an event handler enqueuing the replication sources
+// of a profile. Updates enqueue the sources of the old and new credentials
+// reference, so a replica the profile no longer uses is pruned.
+func (r *SecretReplicationReconciler) profileHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objs ...client.Object) {
+		for _, obj := range objs {
+			for _, req := range r.findSourcesForProfile(ctx, obj) {
+				q.Add(req)
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
+// findSiblingSources returns reconcile requests for the other replication
+// sources named like a source, so a conflict between them is re-evaluated
+// when one of them changes
+func (r *SecretReplicationReconciler) findSiblingSources(ctx context.Context, obj client.Object) []reconcile.Request {
+	var sources corev1.SecretList
+	if err := r.List(ctx, &sources, client.MatchingFields{replicationSourceIndexField: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list replication sources for source watch")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, source := range sources.Items {
+		if source.Namespace == obj.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: source.Name, Namespace: source.Namespace},
+		})
+	}
+	return requests
+}
+
+// findSourceForReplica returns a reconcile request for the source of a
+// replica, so edited or deleted replicas are repaired
+func (r *SecretReplicationReconciler) findSourceForReplica(_ context.Context, obj client.Object) []reconcile.Request {
+	namespace, name, ok := strings.Cut(obj.GetAnnotations()[AnnotationReplicatedFrom], "/")
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
+
+// replicationSourcePredicate passes events of Secrets that are, or were
+// until this update, replication sources
+func replicationSourcePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isReplicationSource(e.Object) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return isReplicationSource(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isReplicationSource(e.ObjectOld) || isReplicationSource(e.ObjectNew)
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *SecretReplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &corev1.Secret{}, replicationSourceIndexField, replicationSourceIndexFunc); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("secretreplication").
+		For(&corev1.Secret{}, ctrlbuilder.WithPredicates(replicationSourcePredicate())).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findSourceForReplica),
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[LabelReplica] == "true"
+			})),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findSiblingSources),
+			ctrlbuilder.WithPredicates(replicationSourcePredicate()),
+		).
+		Watches(
+			&nextdnsv1alpha1.NextDNSProfile{},
+			r.profileHandler(),
+			ctrlbuilder.WithPredicates(credentialsRefChangedPredicate()),
+		).
+		WithOptions(controllerOptions("secretreplication", r.Workqueue)).
+		Complete(r)
+}
+
+// credentialsRefChangedPredicate filters NextDNSProfile updates down to
+// changes of the credentials reference. Creates and deletes always pass.
+func credentialsRefChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldProfile, ok := e.ObjectOld.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			newProfile, ok := e.ObjectNew.(*nextdnsv1alpha1.NextDNSProfile)
+			if !ok {
+				return false
+			}
+			return oldProfile.Spec.CredentialsRef != newProfile.Spec.CredentialsRef
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newReplicationProfile(name, namespace string, ref nextdnsv1alpha1.SecretKeySelector) *nextdnsv1alpha1.NextDNSProfile {
+	return &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       nextdnsv1alpha1.NextDNSProfileSpec{CredentialsRef: ref},
+	}
+}
+
+func TestSecretReplicationReconciler_Reconcile(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nextdns-credentials",
+			Namespace: "nextdns-system",
+			Annotations: map[string]string{
+				AnnotationReplicate:   "true",
+				AnnotationReplicateTo: "team-a, team-b,team-c,team-d",
+			},
+		},
+		Data: map[string][]byte{"api-key": []byte("key-1")},
+	}
+	// A hand-made Secret of the same name is never overwritten
+	handMade := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "team-c"},
+		Data:       map[string][]byte{"api-key": []byte("own-key")},
+	}
+	ref := nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(source, handMade,
+			newReplicationProfile("home", "team-a", ref),
+			newReplicationProfile("kids", "team-a", ref),
+			newReplicationProfile("office", "team-b", ref),
+			newReplicationProfile("lab", "team-c", ref),
+			newReplicationProfile("system", "nextdns-system", ref),
+			newReplicationProfile("direct", "team-d", nextdnsv1alpha1.SecretKeySelector{
+				Name: "nextdns-credentials", Namespace: "nextdns-system",
+			}),
+			newReplicationProfile("unlisted", "team-e", ref)).
+		WithIndex(&corev1.Secret{}, replicationSourceIndexField, replicationSourceIndexFunc).
+		Build()
+
+	r := &SecretReplicationReconciler{Client: fakeClient, Scheme: scheme, ResourceLabels: ResourceLabels{"team": "dns"}}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nextdns-credentials", Namespace: "nextdns-system"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	getReplica := func(namespace string) (*corev1.Secret, error) {
+		var secret corev1.Secret
+		err := fakeClient.Get(ctx, types.NamespacedName{Name: "nextdns-credentials", Namespace: namespace}, &secret)
+		return &secret, err
+	}
+
+	replica, err := getReplica("team-a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key-1"), replica.Data["api-key"])
+	assert.Equal(t, "true", replica.Labels[LabelReplica])
+	assert.Equal(t, "dns", replica.Labels["team"])
+	assert.Equal(t, "nextdns-system/nextdns-credentials", replica.Annotations[AnnotationReplicatedFrom])
+	assert.Equal(t, secretDataHash(source.Data), replica.Annotations[AnnotationReplicaHash])
+	assert.Empty(t, replica.Annotations[AnnotationReplicate], "replicas are not replicated further")
+
+	_, err = getReplica("team-b")
+	require.NoError(t, err)
+
+	kept, err := getReplica("team-c")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("own-key"), kept.Data["api-key"])
+
+	_, err = getReplica("team-d")
+	assert.True(t, apierrors.IsNotFound(err), "profiles referencing the source directly need no replica")
+
+	_, err = getReplica("team-e")
+	assert.True(t, apierrors.IsNotFound(err), "namespaces not listed in replicate-to get no replica")
+
+	// Edits to a replica are reverted and source changes are propagated
+	replica.Data["api-key"] = []byte("tampered")
+	require.NoError(t, fakeClient.Update(ctx, replica))
+	var currentSource corev1.Secret
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &currentSource))
+	currentSource.Data["api-key"] = []byte("key-2")
+	require.NoError(t, fakeClient.Update(ctx, &currentSource))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	for _, namespace := range []string{"team-a", "team-b"} {
+		replica, err := getReplica(namespace)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key-2"), replica.Data["api-key"], namespace)
+	}
+
+	// The replica is removed with the last profile in its namespace
+	require.NoError(t, fakeClient.Delete(ctx, newReplicationProfile("office", "team-b", ref)))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	_, err = getReplica("team-b")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = getReplica("team-a")
+	require.NoError(t, err)
+
+	// Opting out removes all replicas
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &currentSource))
+	delete(currentSource.Annotations, AnnotationReplicate)
+	require.NoError(t, fakeClient.Update(ctx, &currentSource))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	_, err = getReplica("team-a")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = getReplica("team-c")
+	require.NoError(t, err, "the hand-made Secret is kept")
+}
+
+func TestSecretReplicationReconciler_Conflict(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	newSource := func(namespace, key, targets string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nextdns-credentials",
+				Namespace: namespace,
+				Annotations: map[string]string{
+					AnnotationReplicate:   "true",
+					AnnotationReplicateTo: targets,
+				},
+			},
+			Data: map[string][]byte{"api-key": []byte(key)},
+		}
+	}
+	ref := nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newSource("nextdns-system", "key-1", "team-a,team-b"),
+			newSource("other-system", "key-2", "team-b"),
+			newReplicationProfile("home", "team-a", ref),
+			newReplicationProfile("office", "team-b", ref)).
+		WithIndex(&corev1.Secret{}, replicationSourceIndexField, replicationSourceIndexFunc).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SecretReplicationReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	for _, namespace := range []string{"nextdns-system", "other-system"} {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "nextdns-credentials", Namespace: namespace}})
+		require.NoError(t, err)
+	}
+
+	var replica corev1.Secret
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "nextdns-credentials", Namespace: "team-a"}, &replica))
+	assert.Equal(t, []byte("key-1"), replica.Data["api-key"])
+	err := fakeClient.Get(ctx, types.NamespacedName{Name: "nextdns-credentials", Namespace: "team-b"}, &replica)
+	assert.True(t, apierrors.IsNotFound(err), "neither source replicates to a contested namespace")
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Contains(t, events, "Warning ReplicaConflict Secret other-system/nextdns-credentials is also replicated to namespace team-b, skipping")
+	assert.Contains(t, events, "Warning ReplicaConflict Secret nextdns-system/nextdns-credentials is also replicated to namespace team-b, skipping")
+}
+
+func TestSecretReplicationReconciler_CrossNamespacePolicy(t *testing.T) {
+	defer func() { crossNamespaceCredentials = CrossNamespaceCredentialsAllow }()
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nextdns-credentials",
+			Namespace: "nextdns-system",
+			Annotations: map[string]string{
+				AnnotationReplicate:         "true",
+				AnnotationReplicateTo:       "team-a,team-b",
+				AnnotationAllowedNamespaces: "team-a",
+			},
+		},
+		Data: map[string][]byte{"api-key": []byte("key-1")},
+	}
+	ref := nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(source,
+			newReplicationProfile("home", "team-a", ref),
+			newReplicationProfile("office", "team-b", ref)).
+		WithIndex(&corev1.Secret{}, replicationSourceIndexField, replicationSourceIndexFunc).
+		Build()
+	r := &SecretReplicationReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nextdns-credentials", Namespace: "nextdns-system"}}
+	hasReplica := func(namespace string) bool {
+		var secret corev1.Secret
+		err := fakeClient.Get(ctx, types.NamespacedName{Name: "nextdns-credentials", Namespace: namespace}, &secret)
+		if !apierrors.IsNotFound(err) {
+			require.NoError(t, err)
+		}
+		return err == nil
+	}
+
+	// allow replicates to every listed namespace
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, hasReplica("team-a"))
+	assert.True(t, hasReplica("team-b"))
+
+	// grant prunes the replicas of namespaces the source does not grant
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsGrant))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, hasReplica("team-a"))
+	assert.False(t, hasReplica("team-b"), "a replica would bypass the grant")
+
+	// deny replicates nowhere
+	require.NoError(t, SetCrossNamespaceCredentials(CrossNamespaceCredentialsDeny))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, hasReplica("team-a"))
+	assert.False(t, hasReplica("team-b"))
+}
+
+func TestSecretReplicationReconciler_FindSources(t *testing.T) {
+	scheme := newTestScheme()
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nextdns-credentials",
+			Namespace:   "nextdns-system",
+			Annotations: map[string]string{AnnotationReplicate: "true"},
+		},
+	}
+	notSource := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "other"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(source, notSource).
+		WithIndex(&corev1.Secret{}, replicationSourceIndexField, replicationSourceIndexFunc).
+		Build()
+	r := &SecretReplicationReconciler{Client: fakeClient, Scheme: scheme}
+
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "nextdns-credentials", Namespace: "nextdns-system"}}}
+	profile := newReplicationProfile("home", "team-a", nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-credentials"})
+	assert.Equal(t, want, r.findSourcesForProfile(context.Background(), profile))
+
+	sibling := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "nextdns-credentials", Namespace: "other-system"}}
+	assert.Equal(t, want, r.findSiblingSources(context.Background(), sibling))
+	assert.Empty(t, r.findSiblingSources(context.Background(), source), "a source is not its own sibling")
+
+	replica := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nextdns-credentials",
+			Namespace:   "team-a",
+			Annotations: map[string]string{AnnotationReplicatedFrom: "nextdns-system/nextdns-credentials"},
+		},
+	}
+	assert.Equal(t, want, r.findSourceForReplica(context.Background(), replica))
+	assert.Nil(t, r.findSourceForReplica(context.Background(), notSource))
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// Profile sections written by syncWithNextDNS, named like the fields of
+// status.sections
+const (
+	sectionSecurity        = "security"
+	sectionPrivacy         = "privacy"
+	sectionParentalControl = "parentalControl"
+	sectionDenylist        = "denylist"
+	sectionAllowlist       = "allowlist"
+	sectionTLDs            = "tlds"
+	sectionSettings        = "settings"
+)
+
+// sectionOrder is the order failed sections are reported in, independent
+// of the order their concurrent writes finished in
+var sectionOrder = []string{
+	sectionSecurity, sectionPrivacy, sectionParentalControl, sectionSettings,
+	sectionDenylist, sectionAllowlist, sectionTLDs,
+}
+
+// DefaultSectionConcurrency is the number of profile sections written to
+// NextDNS at once during a sync
+const DefaultSectionConcurrency = 4
+
+// sectionSync writes the sections of a profile independently and
+// concurrently, so one failing API call does not keep the others from being
+// applied, and records the outcome of each in status.sections. A section
+// whose content was already applied is not written again unless it drifted
+// or the sync is forced.
+type sectionSync struct {
+	previous *nextdnsv1alpha1.ProfileSectionStatus
+	now      metav1.Time
+	force    bool
+	drifted  map[string]bool
+	group    errgroup.Group
+
+	// mu guards the fields below, which writes update as they finish
+	mu       sync.Mutex
+	current  nextdnsv1alpha1.ProfileSectionStatus
+	skipped  []string
+	failures map[string]error
+}
+
+// newSectionSync starts a sync whose section statuses build on previous,
+// writing up to concurrency sections at once. force writes every section;
+// the sections listed in drift are written even when their content is
+// unchanged.
+func newSectionSync(previous *nextdnsv1alpha1.ProfileSectionStatus, now metav1.Time, concurrency int, force bool, drift *nextdnsv1alpha1.DriftSummary) *sectionSync {
+	s := &sectionSync{previous: previous, now: now, force: force, drifted: driftedSections(drift)}
+	if concurrency <= 0 {
+		concurrency = DefaultSectionConcurrency
+	}
+	s.group.SetLimit(concurrency)
+	return s
+}
+
+// apply starts writing a section with write and records its status once
+// the write finished. desired is the content written; a section last
+// applied with the same content is skipped. LastSynced only moves when the
+// content changes or the section recovers from a failure, so an unchanged
+// profile leaves status alone. write runs concurrently with the other
+// writes of the sync and must not share state with them.
+func (s *sectionSync) apply(section string, desired any, write func() error) {
+	status := &nextdnsv1alpha1.SectionSyncStatus{}
+	if previous := sectionStatus(s.previous, section); previous != nil && *previous != nil {
+		status = (*previous).DeepCopy()
+	}
+
+	hash := sectionHash(desired)
+	if !s.force && !s.drifted[section] && status.Hash == hash && status.Error == "" && status.LastSynced != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.skipped = append(s.skipped, section)
+		*sectionStatus(&s.current, section) = status
+		return
+	}
+
+	s.run(func() {
+		err := write()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			status.Error = err.Error()
+			if s.failures == nil {
+				s.failures = make(map[string]error)
+			}
+			s.failures[section] = err
+		} else {
+			if status.Hash != hash || status.Error != "" || status.LastSynced == nil {
+				status.LastSynced = s.now.DeepCopy()
+			}
+			status.Hash = hash
+			status.Error = ""
+		}
+		*sectionStatus(&s.current, section) = status
+	})
+}
+
+// run starts fn alongside the section writes, within the same concurrency
+// limit
+func (s *sectionSync) run(fn func()) {
+	s.group.Go(func() error {
+		fn()
+		return nil
+	})
+}
+
+// wait blocks until every started write finished. status and err are only
+// complete after wait returned.
+func (s *sectionSync) wait() {
+	_ = s.group.Wait()
+}
+
+// status returns the recorded section statuses, or nil when no section
+// was written
+func (s *sectionSync) status() *nextdnsv1alpha1.ProfileSectionStatus {
+	if s.current == (nextdnsv1alpha1.ProfileSectionStatus{}) {
+		return nil
+	}
+	return &s.current
+}
+
+// err returns an error naming the failed sections and wrapping their
+// errors, or nil when every section was applied
+func (s *sectionSync) err() error {
+	if len(s.failures) == 0 {
+		return nil
+	}
+	var failed []string
+	var errs []error
+	for _, section := range sectionOrder {
+		if err, ok := s.failures[section]; ok {
+			failed = append(failed, section)
+			errs = append(errs, err)
+		}
+	}
+	return fmt.Errorf("failed to sync %s: %w", strings.Join(failed, ", "), errors.Join(errs...))
+}
+
+// driftedSections returns the sections of status.sections that drift
+// reports differences in
+func driftedSections(drift *nextdnsv1alpha1.DriftSummary) map[string]bool {
+	if drift == nil {
+		return nil
+	}
+	drifted := make(map[string]bool, len(drift.Sections))
+	for _, section := range drift.Sections {
+		// Drift names subsections like privacy.blocklists
+		section, _, _ = strings.Cut(section, ".")
+		if section == "blockedTLDs" {
+			section = sectionTLDs
+		}
+		drifted[section] = true
+	}
+	return drifted
+}
+
+// sectionStatus returns the field of sections holding section, or nil when
+// sections is nil
+func sectionStatus(sections *nextdnsv1alpha1.ProfileSectionStatus, section string) **nextdnsv1alpha1.SectionSyncStatus {
+	if sections == nil {
+		return nil
+	}
+	switch section {
+	case sectionSecurity:
+		return &sections.Security
+	case sectionPrivacy:
+		return &sections.Privacy
+	case sectionParentalControl:
+		return &sections.ParentalControl
+	case sectionDenylist:
+		return &sections.Denylist
+	case sectionAllowlist:
+		return &sections.Allowlist
+	case sectionTLDs:
+		return &sections.TLDs
+	case sectionSettings:
+		return &sections.Settings
+	default:
+		panic("unknown profile section " + section)
+	}
+}
+
+// sectionHash returns a short hash of the content written to a section
+func sectionHash(desired any) string {
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
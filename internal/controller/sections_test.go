@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func TestSectionSync(t *testing.T) {
+	first := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	sections := newSectionSync(nil, first, 0, false, nil)
+	sections.apply(sectionSecurity, "v1", func() error { return nil })
+	sections.apply(sectionTLDs, "v1", func() error { return errors.New("rate limited") })
+	sections.wait()
+
+	status := sections.status()
+	require.NotNil(t, status)
+	assert.Equal(t, first, *status.Security.LastSynced)
+	assert.NotEmpty(t, status.Security.Hash)
+	assert.Empty(t, status.Security.Error)
+	assert.Nil(t, status.TLDs.LastSynced)
+	assert.Equal(t, "rate limited", status.TLDs.Error)
+	assert.Nil(t, status.Privacy, "sections that were not written have no entry")
+	assert.EqualError(t, sections.err(), "failed to sync tlds: rate limited")
+
+	// Unchanged content keeps the time it was applied; a recovery moves it
+	second := metav1.NewTime(first.Add(time.Hour))
+	sections = newSectionSync(status, second, 0, false, nil)
+	sections.apply(sectionSecurity, "v1", func() error { return nil })
+	sections.apply(sectionTLDs, "v1", func() error { return nil })
+	sections.wait()
+	assert.NoError(t, sections.err())
+	assert.Equal(t, first, *sections.status().Security.LastSynced)
+	assert.Equal(t, second, *sections.status().TLDs.LastSynced)
+	assert.Empty(t, sections.status().TLDs.Error)
+
+	// A failed write keeps the last successful hash and time
+	third := metav1.NewTime(second.Add(time.Hour))
+	previous := sections.status()
+	sections = newSectionSync(previous, third, 0, false, nil)
+	sections.apply(sectionSecurity, "v2", func() error { return errors.New("bad request") })
+	sections.wait()
+	assert.Equal(t, first, *sections.status().Security.LastSynced)
+	assert.Equal(t, previous.Security.Hash, sections.status().Security.Hash)
+	assert.Equal(t, "bad request", sections.status().Security.Error)
+
+	assert.Nil(t, newSectionSync(previous, third, 0, false, nil).status())
+}
+
+func TestSyncWithNextDNS_SectionFailure(t *testing.T) {
+	ctx := context.Background()
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:            "Home",
+			ProfileID:       "abc123",
+			Security:        &nextdnsv1alpha1.SecuritySpec{},
+			ParentalControl: &nextdnsv1alpha1.ParentalControlSpec{},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Home", "abc123.dns.nextdns.io")
+	mockNDS.UpdateParentalControlError = errors.New("internal server error")
+	reconciler := &NextDNSProfileReconciler{
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+
+	lists := &ResolvedLists{Denylist: []nextdns.DomainEntry{{Domain: "ads.example.com", Active: true}}}
+	err := reconciler.syncWithNextDNS(ctx, profile, "test-api-key", lists)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to sync parentalControl")
+
+	assert.True(t, mockNDS.WasMethodCalled("SyncDenylist"), "sections after the failure are still written")
+	sections := profile.Status.Sections
+	require.NotNil(t, sections)
+	assert.Empty(t, sections.Security.Error)
+	assert.NotNil(t, sections.Security.LastSynced)
+	assert.Contains(t, sections.ParentalControl.Error, "internal server error")
+	assert.NotNil(t, sections.Denylist.LastSynced)
+	assert.Nil(t, sections.Allowlist)
+	assert.Empty(t, profile.Status.AppliedConfigHash, "a partial sync is not recorded as applied")
+}
+
+func TestSectionSync_SkipsUnchanged(t *testing.T) {
+	now := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	applied := newSectionSync(nil, now, 0, false, nil)
+	applied.apply(sectionSecurity, "v1", func() error { return nil })
+	applied.apply(sectionTLDs, []string{"zip"}, func() error { return nil })
+	applied.wait()
+	previous := applied.status()
+
+	var writes atomic.Int32
+	write := func() error { writes.Add(1); return nil }
+
+	sections := newSectionSync(previous, now, 0, false, nil)
+	sections.apply(sectionSecurity, "v1", write)
+	sections.apply(sectionTLDs, []string{"zip"}, write)
+	sections.wait()
+	assert.Zero(t, writes.Load(), "unchanged sections are not written")
+	assert.Equal(t, []string{sectionSecurity, sectionTLDs}, sections.skipped)
+	assert.Equal(t, previous, sections.status())
+
+	sections = newSectionSync(previous, now, 0, false, &nextdnsv1alpha1.DriftSummary{Sections: []string{"blockedTLDs"}})
+	sections.apply(sectionSecurity, "v1", write)
+	sections.apply(sectionTLDs, []string{"zip"}, write)
+	sections.wait()
+	assert.Equal(t, int32(1), writes.Load(), "a drifted section is written again")
+	assert.Equal(t, []string{sectionSecurity}, sections.skipped)
+
+	writes.Store(0)
+	sections = newSectionSync(previous, now, 0, true, nil)
+	sections.apply(sectionSecurity, "v1", write)
+	sections.apply(sectionTLDs, []string{"zip"}, write)
+	sections.wait()
+	assert.Equal(t, int32(2), writes.Load(), "a forced sync writes every section")
+}
+
+func TestReconcile_SkipsUnchangedSections(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "home",
+			Namespace:  "default",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name:           "Home",
+			ProfileID:      "abc123",
+			AdoptionPolicy: nextdnsv1alpha1.AdoptionPolicyOverwrite,
+			CredentialsRef: nextdnsv1alpha1.SecretKeySelector{Name: "nextdns-secret"},
+			Security:       &nextdnsv1alpha1.SecuritySpec{Cryptojacking: ptr.To(true)},
+			Denylist:       []nextdnsv1alpha1.DomainEntry{{Domain: "ads.example.com"}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nextdns-secret", Namespace: "default"},
+		Data:       map[string][]byte{"api-key": []byte("test-api-key")},
+	}
+
+	mockNDS := nextdns.NewMockClient()
+	mockNDS.SetProfile("abc123", "Home", "abc123.dns.nextdns.io")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, secret).
+		WithStatusSubresource(profile).
+		Build()
+	reconciler := &NextDNSProfileReconciler{
+		Client:     fakeClient,
+		Scheme:     scheme,
+		SyncPeriod: time.Hour,
+		ClientFactory: func(apiKey string) (nextdns.ClientInterface, error) {
+			return mockNDS, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "home", Namespace: "default"}}
+
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, mockNDS.GetCallCount("UpdateSecurity"))
+	assert.Equal(t, 1, mockNDS.GetCallCount("SyncDenylist"))
+
+	// An unchanged profile only checks for drift
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, mockNDS.GetCallCount("UpdateProfile"))
+	assert.Equal(t, 1, mockNDS.GetCallCount("UpdateSecurity"))
+	assert.Equal(t, 1, mockNDS.GetCallCount("SyncDenylist"))
+
+	// A resync request writes every section again
+	var updated nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Annotations = map[string]string{AnnotationResync: "2026-10-17T10:00:00Z"}
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, mockNDS.GetCallCount("UpdateProfile"))
+	assert.Equal(t, 2, mockNDS.GetCallCount("UpdateSecurity"))
+	assert.Equal(t, 2, mockNDS.GetCallCount("SyncDenylist"))
+}
+
+func TestSectionSync_Concurrency(t *testing.T) {
+	sections := newSectionSync(nil, metav1.Now(), 2, false, nil)
+
+	var running, peak atomic.Int32
+	write := func(err error) func() error {
+		return func() error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return err
+		}
+	}
+
+	// Sections fail in reverse order of reporting
+	sections.apply(sectionTLDs, "v1", write(errors.New("tlds down")))
+	sections.apply(sectionDenylist, "v1", write(nil))
+	sections.apply(sectionParentalControl, "v1", write(nil))
+	sections.apply(sectionSecurity, "v1", write(errors.New("security down")))
+	sections.wait()
+
+	assert.Equal(t, int32(2), peak.Load(), "writes run concurrently up to the limit")
+	assert.EqualError(t, sections.err(), "failed to sync security, tlds: security down\ntlds down")
+	assert.NotNil(t, sections.status().Denylist.LastSynced)
+}
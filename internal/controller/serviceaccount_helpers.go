@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// createServiceAccount reports whether the operator creates the
+// ServiceAccount of the CoreDNS pods
+func createServiceAccount(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	d := coreDNS.Spec.Deployment
+	return d != nil && d.ServiceAccount != nil && boolWithDefault(d.ServiceAccount.Create, true)
+}
+
+// serviceAccountName returns the ServiceAccount the CoreDNS pods run as, or
+// empty for the namespace default. A created ServiceAccount defaults to
+// resourceName, the name of the Deployment or DaemonSet.
+func serviceAccountName(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, resourceName string) string {
+	d := coreDNS.Spec.Deployment
+	if d == nil || d.ServiceAccount == nil {
+		return ""
+	}
+	if d.ServiceAccount.Name != "" {
+		return d.ServiceAccount.Name
+	}
+	if createServiceAccount(coreDNS) {
+		return resourceName
+	}
+	return ""
+}
+
+// applyPodSecurity applies the pod-level security overrides to podSpec
+func applyPodSecurity(podSpec *corev1.PodSpec, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	d := coreDNS.Spec.Deployment
+	if d == nil || d.PodSecurityContext == nil {
+		return
+	}
+	s := d.PodSecurityContext
+	if s.RunAsUser != nil {
+		podSpec.SecurityContext.RunAsUser = s.RunAsUser
+	}
+	podSpec.SecurityContext.RunAsGroup = s.RunAsGroup
+	podSpec.SecurityContext.FSGroup = s.FSGroup
+	podSpec.SecurityContext.SeccompProfile = s.SeccompProfile
+}
+
+// reconcileServiceAccount creates or updates the ServiceAccount of the
+// CoreDNS pods when the operator owns it, and deletes owned ServiceAccounts
+// that are no longer used, such as after a rename
+func (r *NextDNSCoreDNSReconciler) reconcileServiceAccount(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+
+	labels := r.buildLabels(coreDNS, profile)
+	name := ""
+	if createServiceAccount(coreDNS) {
+		name = serviceAccountName(coreDNS, r.getResourceName(coreDNS, profile))
+	}
+
+	var owned corev1.ServiceAccountList
+	if err := r.List(ctx, &owned, client.InNamespace(coreDNS.Namespace), client.MatchingLabels(labels)); err != nil {
+		return fmt.Errorf("failed to list ServiceAccounts: %w", err)
+	}
+	for i := range owned.Items {
+		sa := &owned.Items[i]
+		if sa.Name == name || !metav1.IsControlledBy(sa, coreDNS) {
+			continue
+		}
+		logger.Info("Cleaning up stale ServiceAccount", "name", sa.Name)
+		if err := r.Delete(ctx, sa); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ServiceAccount %s: %w", sa.Name, err)
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: coreDNS.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		sa.Labels = r.ResourceLabels.merge(labels)
+		sa.Annotations = coreDNS.Spec.Deployment.ServiceAccount.Annotations
+		return controllerutil.SetControllerReference(coreDNS, sa, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ServiceAccount: %w", err)
+	}
+
+	if op != controllerutil.OperationResultNone {
+		logger.Info("ServiceAccount reconciled", "operation", op, "name", name)
+	}
+	return nil
+}
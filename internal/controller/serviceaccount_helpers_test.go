@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestServiceAccountName(t *testing.T) {
+	tests := []struct {
+		name string
+		sa   *nextdnsv1alpha1.CoreDNSServiceAccountConfig
+		want string
+	}{
+		{name: "unset uses the namespace default", sa: nil, want: ""},
+		{name: "created defaults to the workload name", sa: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{}, want: "dns-abc123-coredns"},
+		{name: "created with a name", sa: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{Name: "dns"}, want: "dns"},
+		{name: "existing", sa: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{Create: ptr.To(false), Name: "shared"}, want: "shared"},
+		{name: "not created without a name", sa: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{Create: ptr.To(false)}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+					Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{ServiceAccount: tt.sa},
+				},
+			}
+			assert.Equal(t, tt.want, serviceAccountName(coreDNS, "dns-abc123-coredns"))
+		})
+	}
+}
+
+func TestBuildPodSpec_PodSecurityContext(t *testing.T) {
+	r := &NextDNSCoreDNSReconciler{}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+	}
+
+	podSpec := r.buildPodSpec(coreDNS, "dns-abc123-coredns")
+	assert.Empty(t, podSpec.ServiceAccountName)
+	assert.Equal(t, int64(65534), *podSpec.SecurityContext.RunAsUser)
+	assert.Nil(t, podSpec.SecurityContext.FSGroup)
+
+	coreDNS.Spec.Deployment = &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+		ServiceAccount: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{},
+		PodSecurityContext: &nextdnsv1alpha1.CoreDNSPodSecurityConfig{
+			RunAsUser:      ptr.To[int64](1000),
+			FSGroup:        ptr.To[int64](2000),
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+	}
+	podSpec = r.buildPodSpec(coreDNS, "dns-abc123-coredns")
+	assert.Equal(t, "dns-abc123-coredns", podSpec.ServiceAccountName)
+	assert.True(t, *podSpec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, int64(1000), *podSpec.SecurityContext.RunAsUser)
+	assert.Equal(t, int64(2000), *podSpec.SecurityContext.FSGroup)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, podSpec.SecurityContext.SeccompProfile.Type)
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_ServiceAccount(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sa-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				ServiceAccount: &nextdnsv1alpha1.CoreDNSServiceAccountConfig{
+					Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/dns"},
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sa-dns", Namespace: "default"}}
+	saKey := types.NamespacedName{Name: "sa-dns-abc123-coredns", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	sa := &corev1.ServiceAccount{}
+	require.NoError(t, fakeClient.Get(ctx, saKey, sa), "ServiceAccount should be created")
+	require.Len(t, sa.OwnerReferences, 1)
+	assert.Equal(t, "sa-dns", sa.OwnerReferences[0].Name)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/dns", sa.Annotations["eks.amazonaws.com/role-arn"])
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, saKey, deployment))
+	assert.Equal(t, saKey.Name, deployment.Spec.Template.Spec.ServiceAccountName)
+
+	// Switching to an existing ServiceAccount deletes the created one
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.Deployment.ServiceAccount = &nextdnsv1alpha1.CoreDNSServiceAccountConfig{Create: ptr.To(false), Name: "shared"}
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, saKey, sa)
+	assert.True(t, apierrors.IsNotFound(err), "created ServiceAccount should be deleted")
+	require.NoError(t, fakeClient.Get(ctx, saKey, deployment))
+	assert.Equal(t, "shared", deployment.Spec.Template.Spec.ServiceAccountName)
+}
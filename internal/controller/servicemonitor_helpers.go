@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// defaultServiceMonitorInterval is the scrape interval when none is set
+	defaultServiceMonitorInterval = "30s"
+
+	// AnnotationServiceMonitorOwner records the NextDNSCoreDNS owning a
+	// ServiceMonitor, which may live in another namespace
+	AnnotationServiceMonitorOwner = "nextdns.io/owner"
+)
+
+// ServiceMonitorGVK identifies the Prometheus Operator ServiceMonitor kind
+var ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// newServiceMonitor returns an empty ServiceMonitor object
+func newServiceMonitor() *unstructured.Unstructured {
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(ServiceMonitorGVK)
+	return sm
+}
+
+// serviceMonitorConfig returns the ServiceMonitor config when one should
+// exist, or nil otherwise
+func serviceMonitorConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.CoreDNSServiceMonitorConfig {
+	cf := coreDNS.Spec.Corefile
+	if cf == nil || cf.Metrics == nil || cf.Metrics.ServiceMonitor == nil {
+		return nil
+	}
+	if !boolValue(cf.Metrics.Enabled, true) || !boolValue(cf.Metrics.ServiceMonitor.Enabled, true) {
+		return nil
+	}
+	return cf.Metrics.ServiceMonitor
+}
+
+// serviceMonitorOwner returns the owner annotation value for coreDNS
+func serviceMonitorOwner(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	return coreDNS.Namespace + "/" + coreDNS.Name
+}
+
+// buildServiceMonitorSpec returns the ServiceMonitor spec scraping the metrics
+// port of the CoreDNS Service
+func buildServiceMonitorSpec(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, cfg *nextdnsv1alpha1.CoreDNSServiceMonitorConfig) map[string]interface{} {
+	interval := cfg.Interval
+	if interval == "" {
+		interval = defaultServiceMonitorInterval
+	}
+
+	return map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"app.kubernetes.io/name":       "coredns",
+				"app.kubernetes.io/instance":   coreDNS.Name,
+				"app.kubernetes.io/managed-by": "nextdns-operator",
+			},
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{coreDNS.Namespace},
+		},
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"port":     "metrics",
+				"path":     "/metrics",
+				"interval": interval,
+			},
+		},
+	}
+}
+
+// reconcileServiceMonitor creates, updates, or cleans up the ServiceMonitor
+// for the CoreDNS metrics endpoint. It does nothing when the ServiceMonitor
+// CRD is not installed.
+func (r *NextDNSCoreDNSReconciler) reconcileServiceMonitor(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) error {
+	logger := log.FromContext(ctx)
+
+	cfg := serviceMonitorConfig(coreDNS)
+	if !r.ServiceMonitorAvailable {
+		if cfg != nil {
+			logger.Info("ServiceMonitor CRD not installed, skipping spec.corefile.metrics.serviceMonitor")
+		}
+		return nil
+	}
+
+	if cfg == nil {
+		return r.cleanupServiceMonitors(ctx, coreDNS, types.NamespacedName{})
+	}
+
+	namespace := coreDNS.Namespace
+	if cfg.Namespace != "" {
+		namespace = cfg.Namespace
+	}
+	key := types.NamespacedName{Name: r.getResourceName(coreDNS, profile), Namespace: namespace}
+
+	// Remove monitors left behind by a namespace or profile change
+	if err := r.cleanupServiceMonitors(ctx, coreDNS, key); err != nil {
+		return err
+	}
+
+	sm := newServiceMonitor()
+	sm.SetName(key.Name)
+	sm.SetNamespace(key.Namespace)
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, sm, func() error {
+		labels := r.buildLabels(coreDNS, profile)
+		for k, v := range cfg.Labels {
+			labels[k] = v
+		}
+		sm.SetLabels(r.ResourceLabels.merge(labels))
+
+		annotations := sm.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[AnnotationServiceMonitorOwner] = serviceMonitorOwner(coreDNS)
+		sm.SetAnnotations(annotations)
+
+		if err := unstructured.SetNestedField(sm.Object, buildServiceMonitorSpec(coreDNS, cfg), "spec"); err != nil {
+			return err
+		}
+
+		// Owner references cannot cross namespaces; those monitors are
+		// removed by cleanupServiceMonitors instead
+		if key.Namespace != coreDNS.Namespace {
+			return nil
+		}
+		return controllerutil.SetControllerReference(coreDNS, sm, r.Scheme)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ServiceMonitor: %w", err)
+	}
+
+	if op != controllerutil.OperationResultNone {
+		logger.Info("ServiceMonitor reconciled", "operation", op, "name", key.Name, "namespace", key.Namespace)
+	}
+
+	return nil
+}
+
+// cleanupServiceMonitors deletes the ServiceMonitors owned by coreDNS except keep
+func (r *NextDNSCoreDNSReconciler) cleanupServiceMonitors(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, keep types.NamespacedName) error {
+	logger := log.FromContext(ctx)
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ServiceMonitorGVK.GroupVersion().WithKind(ServiceMonitorGVK.Kind + "List"))
+	if err := r.List(ctx, list, client.MatchingLabels{
+		"app.kubernetes.io/instance":   coreDNS.Name,
+		"app.kubernetes.io/managed-by": "nextdns-operator",
+	}); err != nil {
+		return fmt.Errorf("failed to list ServiceMonitors: %w", err)
+	}
+
+	for i := range list.Items {
+		sm := &list.Items[i]
+		if sm.GetAnnotations()[AnnotationServiceMonitorOwner] != serviceMonitorOwner(coreDNS) {
+			continue
+		}
+		if sm.GetName() == keep.Name && sm.GetNamespace() == keep.Namespace {
+			continue
+		}
+		logger.Info("Cleaning up stale ServiceMonitor", "name", sm.GetName(), "namespace", sm.GetNamespace())
+		if err := r.Delete(ctx, sm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ServiceMonitor %s/%s: %w", sm.GetNamespace(), sm.GetName(), err)
+		}
+	}
+
+	return nil
+}
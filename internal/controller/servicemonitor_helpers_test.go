@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// newServiceMonitorTestScheme registers ServiceMonitor as an unstructured kind
+func newServiceMonitorTestScheme() *runtime.Scheme {
+	scheme := newCoreDNSTestScheme()
+	scheme.AddKnownTypeWithName(ServiceMonitorGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(ServiceMonitorGVK.GroupVersion().WithKind(ServiceMonitorGVK.Kind+"List"), &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func TestServiceMonitorConfig(t *testing.T) {
+	sm := &nextdnsv1alpha1.CoreDNSServiceMonitorConfig{}
+
+	tests := []struct {
+		name    string
+		metrics *nextdnsv1alpha1.CoreDNSMetricsConfig
+		want    bool
+	}{
+		{name: "no metrics config", metrics: nil, want: false},
+		{name: "no service monitor", metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{}, want: false},
+		{name: "enabled by default", metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{ServiceMonitor: sm}, want: true},
+		{
+			name: "explicitly disabled",
+			metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
+				ServiceMonitor: &nextdnsv1alpha1.CoreDNSServiceMonitorConfig{Enabled: ptr.To(false)},
+			},
+			want: false,
+		},
+		{
+			name:    "metrics disabled",
+			metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{Enabled: ptr.To(false), ServiceMonitor: sm},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+					Corefile: &nextdnsv1alpha1.CorefileSpec{Metrics: tt.metrics},
+				},
+			}
+			assert.Equal(t, tt.want, serviceMonitorConfig(coreDNS) != nil)
+		})
+	}
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_ServiceMonitor(t *testing.T) {
+	scheme := newServiceMonitorTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sm-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
+					ServiceMonitor: &nextdnsv1alpha1.CoreDNSServiceMonitorConfig{
+						Interval: "15s",
+						Labels:   map[string]string{"release": "prometheus"},
+					},
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme, ServiceMonitorAvailable: true}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sm-dns", Namespace: "default"}}
+	localKey := types.NamespacedName{Name: "sm-dns-abc123-coredns", Namespace: "default"}
+	monitoringKey := types.NamespacedName{Name: "sm-dns-abc123-coredns", Namespace: "monitoring"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	sm := newServiceMonitor()
+	require.NoError(t, fakeClient.Get(ctx, localKey, sm), "ServiceMonitor should be created")
+	assert.Equal(t, "prometheus", sm.GetLabels()["release"])
+	require.Len(t, sm.GetOwnerReferences(), 1)
+	assert.Equal(t, "sm-dns", sm.GetOwnerReferences()[0].Name)
+
+	endpoints, _, err := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "metrics", endpoints[0].(map[string]interface{})["port"])
+	assert.Equal(t, "15s", endpoints[0].(map[string]interface{})["interval"])
+	selector, _, err := unstructured.NestedStringMap(sm.Object, "spec", "selector", "matchLabels")
+	require.NoError(t, err)
+	assert.Equal(t, "sm-dns", selector["app.kubernetes.io/instance"])
+
+	// Moving the monitor to another namespace replaces the old one
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.Corefile.Metrics.ServiceMonitor.Namespace = "monitoring"
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, localKey, newServiceMonitor())
+	assert.True(t, apierrors.IsNotFound(err), "old ServiceMonitor should be deleted")
+	sm = newServiceMonitor()
+	require.NoError(t, fakeClient.Get(ctx, monitoringKey, sm))
+	assert.Empty(t, sm.GetOwnerReferences(), "owner references cannot cross namespaces")
+	matchNames, _, err := unstructured.NestedStringSlice(sm.Object, "spec", "namespaceSelector", "matchNames")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default"}, matchNames)
+
+	// Deletion removes the cross-namespace monitor
+	require.NoError(t, fakeClient.Delete(ctx, &updated))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	err = fakeClient.Get(ctx, monitoringKey, newServiceMonitor())
+	assert.True(t, apierrors.IsNotFound(err), "ServiceMonitor should be deleted with the NextDNSCoreDNS")
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_ServiceMonitorCRDMissing(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sm-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Metrics: &nextdnsv1alpha1.CoreDNSMetricsConfig{
+					ServiceMonitor: &nextdnsv1alpha1.CoreDNSServiceMonitorConfig{},
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sm-dns", Namespace: "default"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	for _, cond := range updated.Status.Conditions {
+		assert.NotEqual(t, "ServiceMonitorFailed", cond.Reason)
+	}
+}
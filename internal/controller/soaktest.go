@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+)
+
+const (
+	// DefaultSoakTestInterval is the default time between soak test rounds
+	DefaultSoakTestInterval = 5 * time.Minute
+
+	// MinSoakTestInterval is the shortest time between soak test rounds
+	MinSoakTestInterval = time.Minute
+
+	// soakTestTimeout bounds each soak test lookup
+	soakTestTimeout = 2 * time.Second
+
+	// soakTestConcurrency bounds the domains looked up at once
+	soakTestConcurrency = 8
+
+	// soakTestResolverNextDNS and soakTestResolverLegacy label the soak
+	// test metrics by resolver
+	soakTestResolverNextDNS = "nextdns"
+	soakTestResolverLegacy  = "legacy"
+)
+
+// soakTestConfig returns the soak test configuration, or nil unless it is
+// enabled
+func soakTestConfig(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.SoakTestConfig {
+	cfg := coreDNS.Spec.SoakTest
+	if cfg == nil || !boolWithDefault(cfg.Enabled, true) {
+		return nil
+	}
+	return cfg
+}
+
+// soakTestInterval returns the time between rounds of cfg, raised to
+// MinSoakTestInterval
+func soakTestInterval(cfg *nextdnsv1alpha1.SoakTestConfig) time.Duration {
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil || interval <= 0 {
+		return DefaultSoakTestInterval
+	}
+	return max(interval, MinSoakTestInterval)
+}
+
+// soakTestRefreshAfter returns how long to wait before the next soak test
+// round, or 0 when the soak test is disabled
+func soakTestRefreshAfter(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, now time.Time) time.Duration {
+	cfg := soakTestConfig(coreDNS)
+	if cfg == nil {
+		return 0
+	}
+	status := coreDNS.Status.SoakTest
+	if status == nil || status.LastRound == nil {
+		return time.Second
+	}
+	wait := soakTestInterval(cfg) - now.Sub(status.LastRound.Time)
+	if wait <= 0 {
+		return time.Second
+	}
+	return wait
+}
+
+// legacyResolverAddress returns the host:port of a legacy resolver given as
+// an IP or IP:port
+func legacyResolverAddress(address string) (string, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		return net.JoinHostPort(address, "53"), nil
+	}
+	if host, port, err := net.SplitHostPort(address); err == nil && port != "" && net.ParseIP(host) != nil {
+		return address, nil
+	}
+	return "", fmt.Errorf("invalid legacy resolver %q: must be an IP or IP:port", address)
+}
+
+// sampleDomains returns percent of domains, at least one, chosen at random
+// and kept in their configured order
+func sampleDomains(domains []string, percent int32) []string {
+	n := (len(domains)*int(percent) + 99) / 100
+	if n >= len(domains) {
+		return domains
+	}
+	picked := rand.Perm(len(domains))[:max(n, 1)]
+	slices.Sort(picked)
+	sample := make([]string, len(picked))
+	for i, index := range picked {
+		sample[i] = domains[index]
+	}
+	return sample
+}
+
+// runSoakTest runs a soak test round when one is due: a sample of the
+// configured domains is looked up through the Service of coreDNS and
+// through the legacy resolver, and the results are compared. Lookups with
+// different results are counted as divergences in status.soakTest and the
+// soak test metrics.
+func (r *NextDNSCoreDNSReconciler) runSoakTest(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile, now time.Time) {
+	cfg := soakTestConfig(coreDNS)
+	if cfg == nil {
+		coreDNS.Status.SoakTest = nil
+		return
+	}
+	status := coreDNS.Status.SoakTest
+	if status == nil {
+		status = &nextdnsv1alpha1.SoakTestStatus{}
+		coreDNS.Status.SoakTest = status
+	}
+	if status.LastRound != nil && now.Sub(status.LastRound.Time) < soakTestInterval(cfg) {
+		return
+	}
+	status.LastRound = &metav1.Time{Time: now}
+	status.Error = ""
+
+	if !coreDNS.Status.Ready {
+		// Lookups would fail while the pods roll out, not because NextDNS
+		// answers differently
+		status.Error = "round skipped: CoreDNS is not ready"
+		return
+	}
+	legacy, err := legacyResolverAddress(cfg.LegacyResolver)
+	if err != nil {
+		status.Error = err.Error()
+		return
+	}
+	server, err := r.serviceDNSServer(ctx, coreDNS, profile)
+	if err != nil {
+		status.Error = err.Error()
+		return
+	}
+
+	samplePercent := int32(100)
+	if cfg.SamplePercent != nil {
+		samplePercent = *cfg.SamplePercent
+	}
+	domains := sampleDomains(cfg.Domains, samplePercent)
+
+	type comparison struct {
+		divergence            nextdnsv1alpha1.SoakTestDivergence
+		nextDNSRTT, legacyRTT time.Duration
+	}
+	comparisons := make([]comparison, len(domains))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, soakTestConcurrency)
+	for i, name := range domains {
+		wg.Go(func() {
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			c := &comparisons[i]
+			c.divergence.NextDNS, c.nextDNSRTT = r.lookupTestQuery(ctx, server, name, soakTestTimeout)
+			c.divergence.Legacy, c.legacyRTT = r.lookupTestQuery(ctx, legacy, name, soakTestTimeout)
+		})
+	}
+	wg.Wait()
+
+	m := r.metrics()
+	var nextDNSTotal, legacyTotal time.Duration
+	for i := range comparisons {
+		c := &comparisons[i]
+		nextDNSTotal += c.nextDNSRTT
+		legacyTotal += c.legacyRTT
+		m.RecordSoakTestLookup(coreDNS.Name, coreDNS.Namespace, soakTestResolverNextDNS, c.divergence.NextDNS.Result, c.nextDNSRTT.Seconds())
+		m.RecordSoakTestLookup(coreDNS.Name, coreDNS.Namespace, soakTestResolverLegacy, c.divergence.Legacy.Result, c.legacyRTT.Seconds())
+		if c.divergence.NextDNS.Result != c.divergence.Legacy.Result {
+			status.Divergences++
+			status.LastDivergence = &c.divergence
+			m.RecordSoakTestDivergence(coreDNS.Name, coreDNS.Namespace)
+		}
+	}
+	status.Queries += int64(len(comparisons))
+	if n := time.Duration(len(comparisons)); n > 0 {
+		status.NextDNSLatency = (nextDNSTotal / n).Round(100 * time.Microsecond).String()
+		status.LegacyLatency = (legacyTotal / n).Round(100 * time.Microsecond).String()
+	}
+}
+
+// metrics returns the metrics the reconciler records to
+func (r *NextDNSCoreDNSReconciler) metrics() *metrics.Metrics {
+	if r.Metrics == nil {
+		return metrics.Default()
+	}
+	return r.Metrics
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+)
+
+func TestLegacyResolverAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "10.0.0.10", want: "10.0.0.10:53"},
+		{address: "10.0.0.10:5353", want: "10.0.0.10:5353"},
+		{address: "fd00::10", want: "[fd00::10]:53"},
+		{address: "[fd00::10]:5353", want: "[fd00::10]:5353"},
+		{address: "dns.example.com", wantErr: true},
+		{address: "10.0.0.10:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := legacyResolverAddress(tt.address)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "must be an IP or IP:port")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSampleDomains(t *testing.T) {
+	domains := []string{"a.example", "b.example", "c.example", "d.example", "e.example", "f.example", "g.example", "h.example", "i.example", "j.example"}
+
+	assert.Equal(t, domains, sampleDomains(domains, 100))
+	assert.Len(t, sampleDomains(domains, 30), 3)
+	assert.Len(t, sampleDomains(domains, 1), 1, "at least one domain is looked up")
+
+	sample := sampleDomains(domains, 50)
+	assert.Len(t, sample, 5)
+	assert.IsIncreasing(t, sample, "the configured order is kept")
+}
+
+func TestSoakTestRefreshAfter(t *testing.T) {
+	now := time.Now()
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{}
+	assert.Zero(t, soakTestRefreshAfter(coreDNS, now))
+
+	coreDNS.Spec.SoakTest = &nextdnsv1alpha1.SoakTestConfig{Interval: "10m"}
+	assert.Equal(t, time.Second, soakTestRefreshAfter(coreDNS, now), "first round is due")
+
+	coreDNS.Status.SoakTest = &nextdnsv1alpha1.SoakTestStatus{LastRound: &metav1.Time{Time: now.Add(-4 * time.Minute)}}
+	assert.Equal(t, 6*time.Minute, soakTestRefreshAfter(coreDNS, now))
+
+	coreDNS.Spec.SoakTest.Interval = "10s"
+	assert.Equal(t, time.Second, soakTestRefreshAfter(coreDNS, now), "intervals are raised to the minimum")
+	coreDNS.Status.SoakTest.LastRound = &metav1.Time{Time: now.Add(-30 * time.Second)}
+	assert.Equal(t, 30*time.Second, soakTestRefreshAfter(coreDNS, now))
+
+	coreDNS.Spec.SoakTest.Enabled = ptr.To(false)
+	assert.Zero(t, soakTestRefreshAfter(coreDNS, now))
+}
+
+func TestRunSoakTest(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "soak-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			SoakTest: &nextdnsv1alpha1.SoakTestConfig{
+				LegacyResolver: "192.168.1.1",
+				Domains:        []string{"example.com", "ads.example.com", "missing.example.com"},
+			},
+		},
+		Status: nextdnsv1alpha1.NextDNSCoreDNSStatus{Ready: true},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "soak-dns-abc123-coredns", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.53"},
+	}
+
+	// NextDNS blocks the ad domain, which the legacy resolver resolves
+	answers := map[string][]string{
+		"10.96.0.53:53 example.com":      {"93.184.215.14"},
+		"192.168.1.1:53 example.com":     {"93.184.215.14"},
+		"10.96.0.53:53 ads.example.com":  {"0.0.0.0"},
+		"192.168.1.1:53 ads.example.com": {"203.0.113.7"},
+	}
+	registry := prometheus.NewRegistry()
+	m, err := metrics.New(registry)
+	require.NoError(t, err)
+	r := &NextDNSCoreDNSReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(service).Build(),
+		Scheme:  scheme,
+		Metrics: m,
+		DNSLookup: func(ctx context.Context, server, name string) ([]string, error) {
+			key := server + " " + strings.TrimSuffix(name, ".")
+			if a, ok := answers[key]; ok {
+				return a, nil
+			}
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		},
+	}
+
+	now := time.Now()
+	r.runSoakTest(ctx, coreDNS, profile, now)
+	status := coreDNS.Status.SoakTest
+	require.NotNil(t, status)
+	assert.Empty(t, status.Error)
+	assert.Equal(t, int64(3), status.Queries)
+	assert.Equal(t, int64(1), status.Divergences)
+	assert.NotEmpty(t, status.NextDNSLatency)
+	assert.NotEmpty(t, status.LegacyLatency)
+	require.NotNil(t, status.LastDivergence)
+	assert.Equal(t, "ads.example.com", status.LastDivergence.NextDNS.Name)
+	assert.Equal(t, TestQueryBlocked, status.LastDivergence.NextDNS.Result)
+	assert.Equal(t, TestQueryResolved, status.LastDivergence.Legacy.Result)
+	assert.Equal(t, "192.168.1.1:53", status.LastDivergence.Legacy.Server)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.SoakTestDivergencesTotal.WithLabelValues("soak-dns", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.SoakTestLookupsTotal.WithLabelValues("soak-dns", "default", "nextdns", TestQueryBlocked)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.SoakTestLookupsTotal.WithLabelValues("soak-dns", "default", "legacy", TestQueryResolved)))
+
+	// No round runs before the interval has passed
+	r.runSoakTest(ctx, coreDNS, profile, now.Add(time.Minute))
+	assert.Equal(t, int64(3), coreDNS.Status.SoakTest.Queries)
+
+	// Totals accumulate across rounds
+	r.runSoakTest(ctx, coreDNS, profile, now.Add(DefaultSoakTestInterval))
+	assert.Equal(t, int64(6), coreDNS.Status.SoakTest.Queries)
+	assert.Equal(t, int64(2), coreDNS.Status.SoakTest.Divergences)
+
+	// Rounds are skipped while CoreDNS is not ready
+	coreDNS.Status.Ready = false
+	r.runSoakTest(ctx, coreDNS, profile, now.Add(2*DefaultSoakTestInterval))
+	assert.Equal(t, int64(6), coreDNS.Status.SoakTest.Queries)
+	assert.Contains(t, coreDNS.Status.SoakTest.Error, "not ready")
+
+	// An invalid legacy resolver is reported
+	coreDNS.Status.Ready = true
+	coreDNS.Spec.SoakTest.LegacyResolver = "dns.example.com"
+	r.runSoakTest(ctx, coreDNS, profile, now.Add(3*DefaultSoakTestInterval))
+	assert.Equal(t, `invalid legacy resolver "dns.example.com": must be an IP or IP:port`, coreDNS.Status.SoakTest.Error)
+
+	// Disabling clears the status
+	coreDNS.Spec.SoakTest.Enabled = ptr.To(false)
+	r.runSoakTest(ctx, coreDNS, profile, now.Add(4*DefaultSoakTestInterval))
+	assert.Nil(t, coreDNS.Status.SoakTest)
+}
@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultStatusBudget is the default maximum encoded size of a status in
+	// bytes, leaving most of the 1.5 MiB etcd object limit to the spec
+	DefaultStatusBudget = 256 * 1024
+
+	// MinStatusBudget is the smallest status budget that can be configured
+	MinStatusBudget = 16 * 1024
+
+	// minPrunedMessageLength is the length messages and errors are never
+	// truncated below
+	minPrunedMessageLength = 256
+
+	// truncatedSuffix marks a truncated message
+	truncatedSuffix = "... (truncated)"
+)
+
+// statusBudget is used by pruneStatus
+var statusBudget = DefaultStatusBudget
+
+// unprunedStatusFields are status fields holding state the controllers read
+// back, which must never be truncated
+var unprunedStatusFields = map[string]bool{
+	// The allowlist and denylist domains the operator owns on the remote
+	// profile; dropping one would leave it behind on deletion
+	"ManagedEntries": true,
+	// Conditions are keyed by type and bounded by the controllers; only
+	// their messages are truncated
+	"Conditions": true,
+}
+
+// prunedStatusStrings are the names of the status string fields holding
+// free-form messages that may be truncated
+var prunedStatusStrings = map[string]bool{
+	"Message": true,
+	"Error":   true,
+}
+
+// SetStatusBudget sets the maximum encoded size of a status in bytes. It must
+// be called before the controllers start.
+func SetStatusBudget(bytes int) error {
+	if bytes < MinStatusBudget {
+		return fmt.Errorf("status budget must be at least %d bytes", MinStatusBudget)
+	}
+	statusBudget = bytes
+	return nil
+}
+
+// WithStatusBudget returns a client that prunes the status of every object
+// it writes through Status() to the status budget, so no controller can grow
+// a status past the etcd object limit
+func WithStatusBudget(c client.Client) client.Client {
+	return statusBudgetClient{Client: c}
+}
+
+// statusBudgetClient prunes statuses written through Status()
+type statusBudgetClient struct {
+	client.Client
+}
+
+// Status implements client.StatusClient
+func (c statusBudgetClient) Status() client.SubResourceWriter {
+	return statusBudgetWriter{SubResourceWriter: c.Client.Status()}
+}
+
+// statusBudgetWriter prunes the status of objects before writing them
+type statusBudgetWriter struct {
+	client.SubResourceWriter
+}
+
+// Update implements client.SubResourceWriter
+func (w statusBudgetWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	pruneObjectStatus(ctx, obj)
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.SubResourceWriter
+func (w statusBudgetWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	pruneObjectStatus(ctx, obj)
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// pruneObjectStatus prunes the Status field of obj to the status budget and
+// logs when anything was dropped
+func pruneObjectStatus(ctx context.Context, obj client.Object) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	status := v.Elem().FieldByName("Status")
+	if !status.IsValid() || !status.CanAddr() {
+		return
+	}
+	before, after, pruned := pruneStatus(status.Addr().Interface(), statusBudget)
+	if pruned {
+		log.FromContext(ctx).Info("Pruned status to fit the status budget",
+			"name", obj.GetName(), "namespace", obj.GetNamespace(),
+			"bytes", before, "prunedBytes", after, "budget", statusBudget)
+	}
+}
+
+// pruneStatus shrinks status, a pointer to a status struct, until it encodes
+// to at most budget bytes. The largest list is halved, keeping its first
+// items, or the longest message is truncated, until the status fits or
+// nothing is left to shrink. Lists keep at least one item, so a reference
+// list that blocks deletion still does. Returns the encoded size before and
+// after, and whether anything was pruned.
+func pruneStatus(status any, budget int) (before, after int, pruned bool) {
+	before = encodedSize(status)
+	after = before
+	for after > budget {
+		candidate, ok := largestPrunable(reflect.ValueOf(status).Elem())
+		if !ok {
+			break
+		}
+		shrink(candidate)
+		pruned = true
+		after = encodedSize(status)
+	}
+	return before, after, pruned
+}
+
+// encodedSize returns the size of v encoded as JSON
+func encodedSize(v any) int {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
+
+// largestPrunable returns the list or message within v that encodes to the
+// most bytes and can still be shrunk
+func largestPrunable(v reflect.Value) (reflect.Value, bool) {
+	var largest reflect.Value
+	largestSize := 0
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Struct:
+			t := v.Type()
+			for i := range t.NumField() {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				value := v.Field(i)
+				if value.Kind() == reflect.String {
+					if prunedStatusStrings[field.Name] && value.Len() > minPrunedMessageLength+len(truncatedSuffix) &&
+						value.Len() > largestSize {
+						largest, largestSize = value, value.Len()
+					}
+					continue
+				}
+				if unprunedStatusFields[field.Name] {
+					// Messages within the items may still be truncated
+					for j := 0; value.Kind() == reflect.Slice && j < value.Len(); j++ {
+						walk(value.Index(j))
+					}
+					continue
+				}
+				walk(value)
+			}
+		case reflect.Slice:
+			if v.Len() > 1 {
+				if size := encodedSize(v.Interface()); size > largestSize {
+					largest, largestSize = v, size
+				}
+			}
+			for i := range v.Len() {
+				walk(v.Index(i))
+			}
+		}
+	}
+	walk(v)
+	return largest, largest.IsValid()
+}
+
+// shrink halves a list or truncates a message to half its length
+func shrink(v reflect.Value) {
+	if v.Kind() == reflect.Slice {
+		v.Set(v.Slice(0, v.Len()/2))
+		return
+	}
+	s := v.String()
+	cut := max(len(s)/2, minPrunedMessageLength)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	v.SetString(strings.TrimSuffix(s[:cut], truncatedSuffix) + truncatedSuffix)
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// statusSize returns the encoded size of status
+func statusSize(t *testing.T, status any) int {
+	t.Helper()
+	encoded, err := json.Marshal(status)
+	require.NoError(t, err)
+	return len(encoded)
+}
+
+// hugeConditions returns n conditions with long messages
+func hugeConditions(n int) []metav1.Condition {
+	conditions := make([]metav1.Condition, n)
+	for i := range conditions {
+		conditions[i] = metav1.Condition{
+			Type:    fmt.Sprintf("Type%d", i),
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: strings.Repeat("é", 16*1024),
+		}
+	}
+	return conditions
+}
+
+func TestSetStatusBudget(t *testing.T) {
+	defer func() { statusBudget = DefaultStatusBudget }()
+
+	require.NoError(t, SetStatusBudget(64*1024))
+	assert.Equal(t, 64*1024, statusBudget)
+	assert.ErrorContains(t, SetStatusBudget(1024), "status budget must be at least 16384 bytes")
+}
+
+func TestPruneStatus_WithinBudget(t *testing.T) {
+	status := &nextdnsv1alpha1.NextDNSCoreDNSStatus{
+		Endpoints:  []nextdnsv1alpha1.DNSEndpoint{{IP: "10.0.0.1", Port: 53, Protocol: "DNS"}},
+		Conditions: []metav1.Condition{{Type: ConditionTypeReady, Message: "ok"}},
+	}
+	before := status.DeepCopy()
+
+	_, _, pruned := pruneStatus(status, DefaultStatusBudget)
+	assert.False(t, pruned)
+	assert.Equal(t, before, status)
+}
+
+func TestPruneStatus_PathologicalCoreDNS(t *testing.T) {
+	status := &nextdnsv1alpha1.NextDNSCoreDNSStatus{
+		Conditions: hugeConditions(40),
+		NodeIPs:    make([]string, 50000),
+	}
+	for i := range 50000 {
+		status.Endpoints = append(status.Endpoints, nextdnsv1alpha1.DNSEndpoint{IP: fmt.Sprintf("10.%d.%d.%d", i>>16, (i>>8)&255, i&255), Port: 53, Protocol: "DNS"})
+	}
+
+	before, after, pruned := pruneStatus(status, DefaultStatusBudget)
+	assert.True(t, pruned)
+	assert.Greater(t, before, DefaultStatusBudget)
+	assert.LessOrEqual(t, after, DefaultStatusBudget)
+	assert.LessOrEqual(t, statusSize(t, status), DefaultStatusBudget)
+
+	assert.Len(t, status.Conditions, 40, "conditions are never dropped")
+	assert.True(t, strings.HasSuffix(status.Conditions[0].Message, truncatedSuffix))
+	assert.True(t, utf8.ValidString(status.Conditions[0].Message), "messages are cut between runes")
+	require.NotEmpty(t, status.Endpoints)
+	assert.Equal(t, "10.0.0.0", status.Endpoints[0].IP, "the first items are kept")
+}
+
+func TestPruneStatus_KeepsManagedEntries(t *testing.T) {
+	managed := make([]string, 20000)
+	observed := make([]nextdnsv1alpha1.ObservedDomainEntry, 20000)
+	for i := range managed {
+		managed[i] = fmt.Sprintf("managed-%d.example.com", i)
+		observed[i] = nextdnsv1alpha1.ObservedDomainEntry{Domain: fmt.Sprintf("observed-%d.example.com", i), Active: true}
+	}
+	status := &nextdnsv1alpha1.NextDNSProfileStatus{
+		ManagedEntries: &nextdnsv1alpha1.ManagedListEntries{Denylist: managed},
+		ObservedConfig: &nextdnsv1alpha1.ObservedConfig{Denylist: observed},
+		Conditions:     hugeConditions(5),
+	}
+	budget := MinStatusBudget + statusSize(t, status.ManagedEntries)
+
+	_, after, pruned := pruneStatus(status, budget)
+	assert.True(t, pruned)
+	assert.LessOrEqual(t, after, budget)
+	assert.Len(t, status.ManagedEntries.Denylist, 20000, "state the controller reads back is kept")
+	assert.Less(t, len(status.ObservedConfig.Denylist), 20000)
+}
+
+func TestPruneStatus_ListsKeepOneItem(t *testing.T) {
+	status := &nextdnsv1alpha1.NextDNSDenylistStatus{}
+	for i := range 100000 {
+		status.ProfileRefs = append(status.ProfileRefs, nextdnsv1alpha1.ResourceReference{Name: fmt.Sprintf("profile-%d", i), Namespace: "default"})
+	}
+
+	_, after, _ := pruneStatus(status, MinStatusBudget)
+	assert.LessOrEqual(t, after, MinStatusBudget)
+	assert.NotEmpty(t, status.ProfileRefs, "a list blocking deletion still blocks it")
+	assert.Equal(t, "profile-0", status.ProfileRefs[0].Name)
+
+	// A budget the status cannot fit stops once nothing can shrink
+	_, after, _ = pruneStatus(status, 10)
+	assert.Greater(t, after, 10)
+	assert.Len(t, status.ProfileRefs, 1)
+}
+
+func TestWithStatusBudget(t *testing.T) {
+	ctx := context.Background()
+	list := &nextdnsv1alpha1.NextDNSDenylist{
+		ObjectMeta: metav1.ObjectMeta{Name: "ads", Namespace: "default"},
+	}
+	c := WithStatusBudget(fake.NewClientBuilder().
+		WithScheme(newCoreDNSTestScheme()).
+		WithObjects(list).
+		WithStatusSubresource(list).
+		Build())
+
+	list.Status.Conditions = hugeConditions(30)
+	require.NoError(t, c.Status().Update(ctx, list))
+
+	var stored nextdnsv1alpha1.NextDNSDenylist
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "ads", Namespace: "default"}, &stored))
+	assert.Len(t, stored.Status.Conditions, 30)
+	assert.LessOrEqual(t, statusSize(t, stored.Status), DefaultStatusBudget)
+}
@@ -1,21 +1,113 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"math/rand/v2"
 	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// CalculateSyncInterval calculates the next sync interval with ±10% jitter
-// to prevent thundering herd when multiple resources sync simultaneously.
-// Returns 0 if syncPeriod is 0 (periodic sync disabled).
-func CalculateSyncInterval(syncPeriod time.Duration) time.Duration {
+// AnnotationSyncPeriod overrides the sync period of a resource that does not
+// set spec.syncInterval
+const AnnotationSyncPeriod = "nextdns.io/sync-period"
+
+// MinResourceSyncPeriod is the shortest sync period a resource can request,
+// protecting the NextDNS API from aggressive overrides
+const MinResourceSyncPeriod = 5 * time.Minute
+
+// SyncIntervalConfig tunes how periodic sync intervals are spread and bounded
+type SyncIntervalConfig struct {
+	// Jitter is the maximum deviation from the sync period, as a fraction of
+	// it. Larger values spread syncs of a big fleet more evenly.
+	Jitter float64
+
+	// MinInterval is the shortest interval returned; 0 means no lower bound
+	MinInterval time.Duration
+
+	// MaxInterval is the longest interval returned; 0 means no upper bound
+	MaxInterval time.Duration
+}
+
+// DefaultSyncIntervalConfig applies ±10% jitter without bounds
+var DefaultSyncIntervalConfig = SyncIntervalConfig{Jitter: 0.1}
+
+// syncIntervalConfig is used by CalculateSyncInterval
+var syncIntervalConfig = DefaultSyncIntervalConfig
+
+// Validate checks that the jitter is within [0, 1) and the bounds are consistent
+func (c SyncIntervalConfig) Validate() error {
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		return errors.New("sync jitter must be at least 0 and less than 1")
+	}
+	if c.MinInterval < 0 || c.MaxInterval < 0 {
+		return errors.New("sync interval bounds must not be negative")
+	}
+	if c.MaxInterval > 0 && c.MinInterval > c.MaxInterval {
+		return errors.New("minimum sync interval must not exceed the maximum")
+	}
+	return nil
+}
+
+// Interval returns syncPeriod with random jitter applied, clamped to the
+// configured bounds. Returns 0 if syncPeriod is 0 (periodic sync disabled).
+func (c SyncIntervalConfig) Interval(syncPeriod time.Duration) time.Duration {
 	if syncPeriod == 0 {
 		return 0
 	}
 
-	// Calculate 10% jitter (±10%)
-	jitterRange := float64(syncPeriod) * 0.1
-	jitter := time.Duration(rand.Float64()*2*jitterRange - jitterRange)
+	jitterRange := float64(syncPeriod) * c.Jitter
+	interval := syncPeriod + time.Duration(rand.Float64()*2*jitterRange-jitterRange)
 
-	return syncPeriod + jitter
+	if c.MinInterval > 0 && interval < c.MinInterval {
+		interval = c.MinInterval
+	}
+	if c.MaxInterval > 0 && interval > c.MaxInterval {
+		interval = c.MaxInterval
+	}
+	return interval
+}
+
+// SetSyncIntervalConfig sets the configuration used by CalculateSyncInterval.
+// It must be called before the controllers start.
+func SetSyncIntervalConfig(c SyncIntervalConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	syncIntervalConfig = c
+	return nil
+}
+
+// CalculateSyncInterval calculates the next sync interval with jitter
+// (±10% unless configured otherwise) to prevent thundering herd when
+// multiple resources sync simultaneously.
+// Returns 0 if syncPeriod is 0 (periodic sync disabled).
+func CalculateSyncInterval(syncPeriod time.Duration) time.Duration {
+	return syncIntervalConfig.Interval(syncPeriod)
+}
+
+// resourceSyncPeriod returns the sync period of obj: specInterval if set,
+// otherwise the nextdns.io/sync-period annotation, otherwise defaultPeriod.
+// An override of 0 disables periodic syncing; shorter non-zero overrides are
+// raised to MinResourceSyncPeriod. The returned period is always usable; the
+// error reports an override that was invalid (defaultPeriod is used) or
+// raised.
+func resourceSyncPeriod(obj client.Object, specInterval string, defaultPeriod time.Duration) (time.Duration, error) {
+	source, value := "spec.syncInterval", specInterval
+	if value == "" {
+		source, value = AnnotationSyncPeriod, obj.GetAnnotations()[AnnotationSyncPeriod]
+	}
+	if value == "" {
+		return defaultPeriod, nil
+	}
+
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		return defaultPeriod, fmt.Errorf("invalid %s %q, using the default sync period", source, value)
+	}
+	if period > 0 && period < MinResourceSyncPeriod {
+		return MinResourceSyncPeriod, fmt.Errorf("%s %s is below the minimum of %s", source, period, MinResourceSyncPeriod)
+	}
+	return period, nil
 }
@@ -3,6 +3,10 @@ package controller
 import (
 	"testing"
 	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
 )
 
 func TestCalculateSyncInterval(t *testing.T) {
@@ -62,3 +66,142 @@ func TestCalculateSyncIntervalDistribution(t *testing.T) {
 		t.Errorf("CalculateSyncInterval produced only %d unique values from 100 runs, expected variety due to jitter", len(results))
 	}
 }
+
+func TestSyncIntervalConfigInterval(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     SyncIntervalConfig
+		syncPeriod time.Duration
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{
+			name:       "no jitter returns the period",
+			config:     SyncIntervalConfig{},
+			syncPeriod: 10 * time.Minute,
+			wantMin:    10 * time.Minute,
+			wantMax:    10 * time.Minute,
+		},
+		{
+			name:       "wider jitter",
+			config:     SyncIntervalConfig{Jitter: 0.5},
+			syncPeriod: 1 * time.Hour,
+			wantMin:    30 * time.Minute,
+			wantMax:    90 * time.Minute,
+		},
+		{
+			name:       "minimum clamps short intervals",
+			config:     SyncIntervalConfig{Jitter: 0.5, MinInterval: 55 * time.Minute},
+			syncPeriod: 1 * time.Hour,
+			wantMin:    55 * time.Minute,
+			wantMax:    90 * time.Minute,
+		},
+		{
+			name:       "maximum clamps long intervals",
+			config:     SyncIntervalConfig{Jitter: 0.5, MaxInterval: 45 * time.Minute},
+			syncPeriod: 1 * time.Hour,
+			wantMin:    30 * time.Minute,
+			wantMax:    45 * time.Minute,
+		},
+		{
+			name:       "bounds do not enable a disabled sync",
+			config:     SyncIntervalConfig{Jitter: 0.1, MinInterval: time.Minute},
+			syncPeriod: 0,
+			wantMin:    0,
+			wantMax:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := tt.config.Interval(tt.syncPeriod)
+
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Errorf("Interval(%v) = %v, want between %v and %v",
+						tt.syncPeriod, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncIntervalConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SyncIntervalConfig
+		wantErr bool
+	}{
+		{name: "default", config: DefaultSyncIntervalConfig},
+		{name: "no jitter", config: SyncIntervalConfig{}},
+		{name: "bounds", config: SyncIntervalConfig{Jitter: 0.2, MinInterval: time.Minute, MaxInterval: time.Hour}},
+		{name: "negative jitter", config: SyncIntervalConfig{Jitter: -0.1}, wantErr: true},
+		{name: "full jitter", config: SyncIntervalConfig{Jitter: 1}, wantErr: true},
+		{name: "negative bound", config: SyncIntervalConfig{MinInterval: -time.Minute}, wantErr: true},
+		{name: "minimum above maximum", config: SyncIntervalConfig{MinInterval: time.Hour, MaxInterval: time.Minute}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetSyncIntervalConfig(t *testing.T) {
+	t.Cleanup(func() { syncIntervalConfig = DefaultSyncIntervalConfig })
+
+	if err := SetSyncIntervalConfig(SyncIntervalConfig{Jitter: 2}); err == nil {
+		t.Error("SetSyncIntervalConfig accepted an invalid jitter")
+	}
+	if syncIntervalConfig != DefaultSyncIntervalConfig {
+		t.Error("an invalid configuration replaced the current one")
+	}
+
+	if err := SetSyncIntervalConfig(SyncIntervalConfig{MaxInterval: 5 * time.Minute}); err != nil {
+		t.Fatalf("SetSyncIntervalConfig() error = %v", err)
+	}
+	if got := CalculateSyncInterval(time.Hour); got != 5*time.Minute {
+		t.Errorf("CalculateSyncInterval(1h) = %v, want 5m", got)
+	}
+}
+
+func TestResourceSyncPeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		specInterval string
+		annotation   string
+		want         time.Duration
+		wantErr      bool
+	}{
+		{name: "no override uses the default", want: time.Hour},
+		{name: "spec interval", specInterval: "15m", want: 15 * time.Minute},
+		{name: "annotation", annotation: "6h", want: 6 * time.Hour},
+		{name: "spec takes precedence over annotation", specInterval: "30m", annotation: "6h", want: 30 * time.Minute},
+		{name: "zero disables periodic sync", specInterval: "0s", want: 0},
+		{name: "short interval is raised to the minimum", annotation: "30s", want: MinResourceSyncPeriod, wantErr: true},
+		{name: "invalid annotation uses the default", annotation: "daily", want: time.Hour, wantErr: true},
+		{name: "negative annotation uses the default", annotation: "-1h", want: time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &nextdnsv1alpha1.NextDNSProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"},
+			}
+			if tt.annotation != "" {
+				profile.Annotations = map[string]string{AnnotationSyncPeriod: tt.annotation}
+			}
+
+			got, err := resourceSyncPeriod(profile, tt.specInterval, time.Hour)
+			if got != tt.want {
+				t.Errorf("resourceSyncPeriod() = %v, want %v", got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resourceSyncPeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
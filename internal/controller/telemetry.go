@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// TelemetryOff disables telemetry
+	TelemetryOff = "off"
+
+	// TelemetryPrint logs the report that would be sent without sending it
+	TelemetryPrint = "print"
+
+	// TelemetrySend sends the report to the telemetry endpoint
+	TelemetrySend = "send"
+
+	// DefaultTelemetryInterval is the default period between telemetry reports
+	DefaultTelemetryInterval = 24 * time.Hour
+
+	// telemetryTimeout bounds sending a telemetry report
+	telemetryTimeout = 10 * time.Second
+)
+
+// TelemetryReport is the anonymous usage report. It holds no names,
+// namespaces, profile IDs, domains or cluster identifiers.
+type TelemetryReport struct {
+	// OperatorVersion is the version of the operator build
+	OperatorVersion string `json:"operatorVersion"`
+
+	// Features are the optional operator features turned on, sorted
+	Features []string `json:"features"`
+
+	// Resources counts the custom resources of each kind
+	Resources map[string]int `json:"resources"`
+}
+
+// telemetryLists returns an empty list of each custom resource kind counted
+// in telemetry reports
+func telemetryLists() []client.ObjectList {
+	return []client.ObjectList{
+		&nextdnsv1alpha1.NextDNSProfileList{},
+		&nextdnsv1alpha1.NextDNSProfileTemplateList{},
+		&nextdnsv1alpha1.NextDNSProfileGeneratorList{},
+		&nextdnsv1alpha1.NextDNSCoreDNSList{},
+		&nextdnsv1alpha1.NextDNSDeviceList{},
+		&nextdnsv1alpha1.NextDNSReportList{},
+		&nextdnsv1alpha1.NextDNSAccountList{},
+		&nextdnsv1alpha1.NextDNSAllowlistList{},
+		&nextdnsv1alpha1.NextDNSDenylistList{},
+		&nextdnsv1alpha1.ClusterNextDNSAllowlistList{},
+		&nextdnsv1alpha1.ClusterNextDNSDenylistList{},
+		&nextdnsv1alpha1.NextDNSDenylistSourceList{},
+		&nextdnsv1alpha1.NextDNSTLDListList{},
+		&nextdnsv1alpha1.NextDNSRewriteList{},
+	}
+}
+
+// ValidateTelemetryMode returns an error unless mode is off, print or send
+func ValidateTelemetryMode(mode string) error {
+	switch mode {
+	case TelemetryOff, TelemetryPrint, TelemetrySend:
+		return nil
+	}
+	return fmt.Errorf("invalid telemetry mode %q: must be %s, %s or %s", mode, TelemetryOff, TelemetryPrint, TelemetrySend)
+}
+
+// TelemetryReporter periodically reports anonymous usage of the operator:
+// its version, the optional features turned on and the number of custom
+// resources of each kind. In print mode the report is only logged, so it
+// can be reviewed before sending is turned on.
+type TelemetryReporter struct {
+	Client          client.Client
+	Mode            string
+	Endpoint        string
+	Interval        time.Duration
+	OperatorVersion string
+	Features        []string
+
+	// HTTPClient sends reports; a client with a 10 second timeout is used
+	// when nil
+	HTTPClient *http.Client
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (t *TelemetryReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start reports every Interval until ctx is done
+func (t *TelemetryReporter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("telemetry")
+
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Report(ctx); err != nil {
+			logger.Error(err, "Failed to report telemetry")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report builds the telemetry report and logs or sends it
+func (t *TelemetryReporter) Report(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("telemetry")
+
+	report, err := t.buildReport(ctx)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry report: %w", err)
+	}
+
+	if t.Mode != TelemetrySend {
+		logger.Info("Telemetry report (not sent)", "report", string(encoded))
+		return nil
+	}
+
+	httpClient := t.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: telemetryTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+
+	logger.V(1).Info("Sent telemetry report", "endpoint", t.Endpoint)
+	return nil
+}
+
+// buildReport counts the custom resources of each kind
+func (t *TelemetryReporter) buildReport(ctx context.Context) (*TelemetryReport, error) {
+	report := &TelemetryReport{
+		OperatorVersion: t.OperatorVersion,
+		Features:        append([]string{}, t.Features...),
+		Resources:       map[string]int{},
+	}
+	slices.Sort(report.Features)
+
+	for _, list := range telemetryLists() {
+		gvk, err := apiutil.GVKForObject(list, t.Client.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		kind := strings.TrimSuffix(gvk.Kind, "List")
+		if err := t.Client.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("failed to list %s resources: %w", kind, err)
+		}
+		report.Resources[kind] = meta.LenList(list)
+	}
+	return report, nil
+}
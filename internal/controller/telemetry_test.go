@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newTelemetryReporter(mode, endpoint string) *TelemetryReporter {
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(newCoreDNSTestScheme()).
+		WithObjects(
+			&nextdnsv1alpha1.NextDNSProfile{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"}},
+			&nextdnsv1alpha1.NextDNSProfile{ObjectMeta: metav1.ObjectMeta{Name: "kids", Namespace: "family"}},
+			&nextdnsv1alpha1.NextDNSCoreDNS{ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"}},
+			&nextdnsv1alpha1.NextDNSTLDList{ObjectMeta: metav1.ObjectMeta{Name: "tlds", Namespace: "default"}},
+		).
+		Build()
+	return &TelemetryReporter{
+		Client:          fakeClient,
+		Mode:            mode,
+		Endpoint:        endpoint,
+		OperatorVersion: "v1.2.3",
+		Features:        []string{"webhooks", "catalog"},
+	}
+}
+
+func TestValidateTelemetryMode(t *testing.T) {
+	for _, mode := range []string{TelemetryOff, TelemetryPrint, TelemetrySend} {
+		assert.NoError(t, ValidateTelemetryMode(mode))
+	}
+	assert.ErrorContains(t, ValidateTelemetryMode("on"), `invalid telemetry mode "on"`)
+}
+
+func TestTelemetryReporter_BuildReport(t *testing.T) {
+	report, err := newTelemetryReporter(TelemetryPrint, "").buildReport(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.2.3", report.OperatorVersion)
+	assert.Equal(t, []string{"catalog", "webhooks"}, report.Features)
+	assert.Len(t, report.Resources, len(telemetryLists()))
+	assert.Equal(t, 2, report.Resources["NextDNSProfile"])
+	assert.Equal(t, 1, report.Resources["NextDNSCoreDNS"])
+	assert.Equal(t, 1, report.Resources["NextDNSTLDList"])
+	assert.Equal(t, 0, report.Resources["NextDNSRewrite"])
+}
+
+func TestTelemetryReporter_Report(t *testing.T) {
+	var received []byte
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Print mode sends nothing
+	require.NoError(t, newTelemetryReporter(TelemetryPrint, server.URL).Report(ctx))
+	assert.Nil(t, received)
+
+	reporter := newTelemetryReporter(TelemetrySend, server.URL)
+	require.NoError(t, reporter.Report(ctx))
+	var report TelemetryReport
+	require.NoError(t, json.Unmarshal(received, &report))
+	assert.Equal(t, 2, report.Resources["NextDNSProfile"])
+	assert.NotContains(t, string(received), "home-dns", "no resource names are sent")
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, reporter.Report(ctx), "telemetry endpoint returned 503")
+}
@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"fmt"
 	"testing"
 
-	"github.com/jacaudi/nextdns-operator/internal/nextdns"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
 )
 
 // assertContainsDomainEntry is a test helper that asserts a slice of DomainEntry
@@ -19,3 +23,17 @@ func assertContainsDomainEntry(t *testing.T, entries []nextdns.DomainEntry, doma
 	}
 	assert.Fail(t, "domain not found in entries", "domain %s not found in %v", domain, entries)
 }
+
+// largeDomainEntries returns n distinct domains in the form a large
+// blocklist takes, with every twentieth entry paused
+func largeDomainEntries(n int) []nextdnsv1alpha1.DomainEntry {
+	entries := make([]nextdnsv1alpha1.DomainEntry, 0, n)
+	for i := range n {
+		entry := nextdnsv1alpha1.DomainEntry{Domain: fmt.Sprintf("host-%d.example.com", i)}
+		if i%20 == 0 {
+			entry.Active = ptr.To(false)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// AnnotationTestQuery requests a lookup of the annotated domain through the
+// Service of a NextDNSCoreDNS. The outcome is written to
+// status.lastTestQuery and the annotation is removed.
+const AnnotationTestQuery = "nextdns.io/test-query"
+
+const (
+	// TestQueryResolved means the domain resolved to routable addresses
+	TestQueryResolved = "Resolved"
+
+	// TestQueryBlocked means every address returned was unspecified
+	TestQueryBlocked = "Blocked"
+
+	// TestQueryNXDomain means the domain does not exist or has no addresses
+	TestQueryNXDomain = "NXDOMAIN"
+
+	// TestQueryFailed means the lookup itself failed
+	TestQueryFailed = "Failed"
+
+	// testQueryTimeout bounds a test query lookup
+	testQueryTimeout = 5 * time.Second
+)
+
+// DNSLookupFunc looks up the addresses of name through the DNS server at
+// server, given as host:port
+type DNSLookupFunc func(ctx context.Context, server, name string) ([]string, error)
+
+// DefaultDNSLookup looks up name through server with the Go resolver. The
+// name is treated as fully qualified so the search domains of the operator
+// pod are not tried.
+func DefaultDNSLookup(ctx context.Context, server, name string) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return resolver.LookupHost(ctx, name)
+}
+
+// runTestQuery performs the lookup requested with the test-query annotation
+// through the Service of coreDNS and records the outcome in
+// status.lastTestQuery
+func (r *NextDNSCoreDNSReconciler) runTestQuery(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) {
+	name, ok := coreDNS.Annotations[AnnotationTestQuery]
+	if !ok {
+		return
+	}
+	name = strings.TrimSpace(name)
+
+	result := &nextdnsv1alpha1.TestQueryStatus{Name: name, Time: metav1.Now()}
+	coreDNS.Status.LastTestQuery = result
+	if name == "" {
+		result.Result = TestQueryFailed
+		result.Error = "annotation " + AnnotationTestQuery + " names no domain"
+		return
+	}
+
+	server, err := r.serviceDNSServer(ctx, coreDNS, profile)
+	if err != nil {
+		result.Result = TestQueryFailed
+		result.Error = err.Error()
+		return
+	}
+	*result, _ = r.lookupTestQuery(ctx, server, name, testQueryTimeout)
+}
+
+// serviceDNSServer returns the host:port of plain DNS on the cluster IP of
+// the Service of coreDNS
+func (r *NextDNSCoreDNSReconciler) serviceDNSServer(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, profile *nextdnsv1alpha1.NextDNSProfile) (string, error) {
+	service := &corev1.Service{}
+	serviceName := r.getServiceName(coreDNS, profile)
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: coreDNS.Namespace}, service); err != nil {
+		return "", fmt.Errorf("failed to get Service %s: %v", serviceName, err)
+	}
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", fmt.Errorf("Service %s has no cluster IP", serviceName)
+	}
+	return net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(dnsPort))), nil
+}
+
+// lookupTestQuery looks up name through server, bounded by timeout, and
+// returns the classified outcome with the time the lookup took
+func (r *NextDNSCoreDNSReconciler) lookupTestQuery(ctx context.Context, server, name string, timeout time.Duration) (nextdnsv1alpha1.TestQueryStatus, time.Duration) {
+	lookup := r.DNSLookup
+	if lookup == nil {
+		lookup = DefaultDNSLookup
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	answers, err := lookup(lookupCtx, server, name)
+	rtt := time.Since(start)
+
+	result := nextdnsv1alpha1.TestQueryStatus{
+		Name:    name,
+		Server:  server,
+		Answers: answers,
+		RTT:     rtt.Round(100 * time.Microsecond).String(),
+		Time:    metav1.NewTime(start),
+	}
+	result.Result, result.Error = classifyTestQuery(answers, err)
+	return result, rtt
+}
+
+// classifyTestQuery returns the result and error message of a lookup.
+// NextDNS answers blocked domains with 0.0.0.0 and :: unless the profile
+// is set to answer NXDOMAIN, which cannot be told apart from a missing
+// domain.
+func classifyTestQuery(answers []string, err error) (string, string) {
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return TestQueryNXDomain, ""
+		}
+		return TestQueryFailed, err.Error()
+	}
+	if len(answers) == 0 {
+		return TestQueryNXDomain, ""
+	}
+	for _, answer := range answers {
+		ip := net.ParseIP(answer)
+		if ip == nil || !ip.IsUnspecified() {
+			return TestQueryResolved, ""
+		}
+	}
+	return TestQueryBlocked, ""
+}
+
+// testQueryClearedPredicate drops the update event caused by removing the
+// test-query annotation, so clearing it does not trigger another sync
+func testQueryClearedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			return !isAnnotationCleared(e.ObjectOld, e.ObjectNew, AnnotationTestQuery)
+		},
+	}
+}
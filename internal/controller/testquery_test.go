@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestClassifyTestQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		answers    []string
+		err        error
+		wantResult string
+		wantError  string
+	}{
+		{name: "resolved", answers: []string{"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"}, wantResult: TestQueryResolved},
+		{name: "blocked", answers: []string{"0.0.0.0", "::"}, wantResult: TestQueryBlocked},
+		{name: "partly unspecified", answers: []string{"0.0.0.0", "93.184.215.14"}, wantResult: TestQueryResolved},
+		{name: "nxdomain", err: &net.DNSError{Err: "no such host", Name: "missing.example.", IsNotFound: true}, wantResult: TestQueryNXDomain},
+		{name: "no answers", wantResult: TestQueryNXDomain},
+		{name: "timeout", err: errors.New("i/o timeout"), wantResult: TestQueryFailed, wantError: "i/o timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, message := classifyTestQuery(tt.answers, tt.err)
+			assert.Equal(t, tt.wantResult, result)
+			assert.Equal(t, tt.wantError, message)
+		})
+	}
+}
+
+func TestTestQueryClearedPredicate(t *testing.T) {
+	requested := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "dns",
+			Generation:  1,
+			Annotations: map[string]string{AnnotationTestQuery: "example.com"},
+		},
+	}
+	cleared := requested.DeepCopy()
+	cleared.Annotations = map[string]string{}
+
+	p := testQueryClearedPredicate()
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: cleared}), "own cleanup is ignored")
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: cleared, ObjectNew: requested}), "a new request triggers a sync")
+}
+
+func TestNextDNSCoreDNSReconciler_Reconcile_TestQuery(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID: "abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-dns",
+			Namespace:   "default",
+			Finalizers:  []string{CoreDNSFinalizerName},
+			Annotations: map[string]string{AnnotationTestQuery: " ads.example.com "},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	var lookups []string
+	r := &NextDNSCoreDNSReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		DNSLookup: func(ctx context.Context, server, name string) ([]string, error) {
+			lookups = append(lookups, server+" "+name)
+			return []string{"0.0.0.0", "::"}, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-dns", Namespace: "default"}}
+
+	// The fake client assigns no cluster IP, so the lookup cannot be sent;
+	// the outcome is still recorded and the request cleared
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, lookups)
+
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	assert.NotContains(t, updated.Annotations, AnnotationTestQuery)
+	require.NotNil(t, updated.Status.LastTestQuery)
+	assert.Equal(t, "ads.example.com", updated.Status.LastTestQuery.Name)
+	assert.Equal(t, TestQueryFailed, updated.Status.LastTestQuery.Result)
+	assert.Equal(t, "Service test-dns-abc123-coredns has no cluster IP", updated.Status.LastTestQuery.Error)
+
+	// Without a request the last outcome is kept
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	require.NotNil(t, updated.Status.LastTestQuery)
+	assert.Equal(t, TestQueryFailed, updated.Status.LastTestQuery.Result)
+
+	// The lookup is sent to port 53 of the Service cluster IP
+	service := &corev1.Service{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-dns-abc123-coredns", Namespace: "default"}, service))
+	service.Spec.ClusterIP = "10.96.0.53"
+	require.NoError(t, fakeClient.Update(ctx, service))
+
+	updated.Annotations = map[string]string{AnnotationTestQuery: "ads.example.com"}
+	r.runTestQuery(ctx, &updated, profile)
+	assert.Equal(t, []string{"10.96.0.53:53 ads.example.com"}, lookups)
+	result := updated.Status.LastTestQuery
+	require.NotNil(t, result)
+	assert.Equal(t, "10.96.0.53:53", result.Server)
+	assert.Equal(t, TestQueryBlocked, result.Result)
+	assert.Equal(t, []string{"0.0.0.0", "::"}, result.Answers)
+	assert.NotEmpty(t, result.RTT)
+	assert.False(t, result.Time.IsZero())
+}
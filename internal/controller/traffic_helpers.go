@@ -0,0 +1,46 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// topologyModeAuto is the service.kubernetes.io/topology-mode value turning
+// on topology aware routing
+const topologyModeAuto = "Auto"
+
+// applyTrafficHints sets the traffic policies and topology aware routing
+// annotation of the CoreDNS Service. A LoadBalancer Service of an instance
+// with access control uses the Local external traffic policy, since the
+// Cluster policy replaces the client address the acl plugin checks with a
+// node address. The annotation is removed when topology aware routing is
+// turned off, unless spec.service.annotations sets it.
+func applyTrafficHints(service *corev1.Service, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	config := coreDNS.Spec.Service
+	if config == nil {
+		config = &nextdnsv1alpha1.CoreDNSServiceConfig{}
+	}
+	service.Spec.InternalTrafficPolicy = config.InternalTrafficPolicy
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer && accessControlEnabled(coreDNS) {
+		service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyLocal
+	}
+
+	if config.TopologyAwareRouting {
+		if service.Annotations == nil {
+			service.Annotations = make(map[string]string)
+		}
+		service.Annotations[corev1.AnnotationTopologyMode] = topologyModeAuto
+		return
+	}
+	if _, ok := config.Annotations[corev1.AnnotationTopologyMode]; !ok && service.Annotations[corev1.AnnotationTopologyMode] == topologyModeAuto {
+		delete(service.Annotations, corev1.AnnotationTopologyMode)
+	}
+}
+
+// accessControlEnabled reports whether the instance restricts which clients
+// may query it
+func accessControlEnabled(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) bool {
+	ac := coreDNS.Spec.AccessControl
+	return ac != nil && (len(ac.AllowCIDRs) > 0 || len(ac.DenyCIDRs) > 0)
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestApplyTrafficHints(t *testing.T) {
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Service: &nextdnsv1alpha1.CoreDNSServiceConfig{
+				InternalTrafficPolicy: &local,
+				TopologyAwareRouting:  true,
+			},
+		},
+	}
+	service := &corev1.Service{}
+
+	applyTrafficHints(service, coreDNS)
+	assert.Equal(t, &local, service.Spec.InternalTrafficPolicy)
+	assert.Equal(t, "Auto", service.Annotations[corev1.AnnotationTopologyMode])
+
+	// Turning topology aware routing off removes the annotation
+	coreDNS.Spec.Service = nil
+	applyTrafficHints(service, coreDNS)
+	assert.Nil(t, service.Spec.InternalTrafficPolicy)
+	assert.NotContains(t, service.Annotations, corev1.AnnotationTopologyMode)
+
+	// An annotation set through spec.service.annotations is kept
+	coreDNS.Spec.Service = &nextdnsv1alpha1.CoreDNSServiceConfig{
+		Annotations: map[string]string{corev1.AnnotationTopologyMode: "Auto"},
+	}
+	service.Annotations = map[string]string{corev1.AnnotationTopologyMode: "Auto"}
+	applyTrafficHints(service, coreDNS)
+	assert.Equal(t, "Auto", service.Annotations[corev1.AnnotationTopologyMode])
+}
+
+func TestApplyTrafficHints_AccessControl(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			AccessControl: &nextdnsv1alpha1.CoreDNSAccessControlConfig{AllowCIDRs: []string{"192.168.0.0/16"}},
+		},
+	}
+
+	service := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+	applyTrafficHints(service, coreDNS)
+	assert.Empty(t, service.Spec.ExternalTrafficPolicy, "a ClusterIP Service has no external traffic policy")
+
+	service = &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+	applyTrafficHints(service, coreDNS)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, service.Spec.ExternalTrafficPolicy,
+		"a LoadBalancer Service keeps the client address for the acl plugin")
+
+	coreDNS.Spec.AccessControl = &nextdnsv1alpha1.CoreDNSAccessControlConfig{}
+	service = &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+	applyTrafficHints(service, coreDNS)
+	assert.Empty(t, service.Spec.ExternalTrafficPolicy, "empty access control leaves the default policy")
+}
+
+func TestNextDNSCoreDNSReconciler_TrafficHints(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-profile", Namespace: "default"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-coredns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "test-profile"},
+			Service: &nextdnsv1alpha1.CoreDNSServiceConfig{
+				InternalTrafficPolicy: &local,
+				TopologyAwareRouting:  true,
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, coreDNS).Build()
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	require.NoError(t, r.reconcileService(ctx, coreDNS, profile))
+
+	service := &corev1.Service{}
+	key := types.NamespacedName{Name: "test-coredns-abc123-coredns", Namespace: "default"}
+	require.NoError(t, fakeClient.Get(ctx, key, service))
+	assert.Equal(t, &local, service.Spec.InternalTrafficPolicy)
+	assert.Equal(t, "Auto", service.Annotations[corev1.AnnotationTopologyMode])
+
+	coreDNS.Spec.Service.TopologyAwareRouting = false
+	require.NoError(t, r.reconcileService(ctx, coreDNS, profile))
+	require.NoError(t, fakeClient.Get(ctx, key, service))
+	assert.NotContains(t, service.Annotations, corev1.AnnotationTopologyMode)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// updateStrategy returns the configured rolling update settings, or nil
+// when none are set
+func updateStrategy(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) *nextdnsv1alpha1.CoreDNSUpdateStrategyConfig {
+	d := coreDNS.Spec.Deployment
+	if d == nil || d.Strategy == nil || (d.Strategy.MaxUnavailable == nil && d.Strategy.MaxSurge == nil) {
+		return nil
+	}
+	return d.Strategy
+}
+
+// deploymentStrategy returns the update strategy of the CoreDNS Deployment.
+// Without rolling update settings it is left empty for the API server to
+// default.
+func deploymentStrategy(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) appsv1.DeploymentStrategy {
+	s := updateStrategy(coreDNS)
+	if s == nil {
+		return appsv1.DeploymentStrategy{}
+	}
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: s.MaxUnavailable,
+			MaxSurge:       s.MaxSurge,
+		},
+	}
+}
+
+// daemonSetUpdateStrategy returns the update strategy of the CoreDNS
+// DaemonSet. MaxSurge is not applied: surge pods would compete with the old
+// pod for the host ports and node-local address of the node.
+func daemonSetUpdateStrategy(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) appsv1.DaemonSetUpdateStrategy {
+	s := updateStrategy(coreDNS)
+	if s == nil || s.MaxUnavailable == nil {
+		return appsv1.DaemonSetUpdateStrategy{}
+	}
+	return appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: s.MaxUnavailable,
+		},
+	}
+}
+
+// minReadySeconds returns how long new pods must be ready before they count
+// as available
+func minReadySeconds(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) int32 {
+	if d := coreDNS.Spec.Deployment; d != nil && d.MinReadySeconds != nil {
+		return *d.MinReadySeconds
+	}
+	return 0
+}
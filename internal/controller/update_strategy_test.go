@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestUpdateStrategy(t *testing.T) {
+	zero := intstr.FromInt32(0)
+	one := intstr.FromInt32(1)
+	percent := intstr.FromString("50%")
+
+	tests := []struct {
+		name       string
+		deployment *nextdnsv1alpha1.CoreDNSDeploymentConfig
+		wantDeploy appsv1.DeploymentStrategy
+		wantDS     appsv1.DaemonSetUpdateStrategy
+	}{
+		{name: "no deployment config"},
+		{name: "empty strategy", deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{Strategy: &nextdnsv1alpha1.CoreDNSUpdateStrategyConfig{}}},
+		{
+			name: "surge only",
+			deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Strategy: &nextdnsv1alpha1.CoreDNSUpdateStrategyConfig{MaxSurge: &percent},
+			},
+			wantDeploy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &percent},
+			},
+		},
+		{
+			name: "both",
+			deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Strategy: &nextdnsv1alpha1.CoreDNSUpdateStrategyConfig{MaxUnavailable: &zero, MaxSurge: &one},
+			},
+			wantDeploy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &zero, MaxSurge: &one},
+			},
+			wantDS: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &zero},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+				Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{Deployment: tt.deployment},
+			}
+			assert.Equal(t, tt.wantDeploy, deploymentStrategy(coreDNS))
+			assert.Equal(t, tt.wantDS, daemonSetUpdateStrategy(coreDNS))
+		})
+	}
+}
+
+func TestNextDNSCoreDNSReconciler_UpdateStrategy(t *testing.T) {
+	scheme := newCoreDNSTestScheme()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-profile", Namespace: "default"},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:   "abc123",
+			Fingerprint: "fp-abc123",
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	maxUnavailable := intstr.FromInt32(0)
+	maxSurge := intstr.FromInt32(1)
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "rollout-dns",
+			Namespace:  "default",
+			Finalizers: []string{CoreDNSFinalizerName},
+		},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "my-profile"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Strategy: &nextdnsv1alpha1.CoreDNSUpdateStrategyConfig{
+					MaxUnavailable: &maxUnavailable,
+					MaxSurge:       &maxSurge,
+				},
+				MinReadySeconds: ptr.To[int32](10),
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(profile, coreDNS).
+		WithStatusSubresource(coreDNS, profile).
+		Build()
+
+	r := &NextDNSCoreDNSReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "rollout-dns", Namespace: "default"}}
+	workloadKey := types.NamespacedName{Name: "rollout-dns-abc123-coredns", Namespace: "default"}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, fakeClient.Get(ctx, workloadKey, deployment))
+	require.NotNil(t, deployment.Spec.Strategy.RollingUpdate)
+	assert.Equal(t, maxUnavailable, *deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, maxSurge, *deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, int32(10), deployment.Spec.MinReadySeconds)
+
+	// DaemonSet mode applies maxUnavailable and minReadySeconds
+	var updated nextdnsv1alpha1.NextDNSCoreDNS
+	require.NoError(t, fakeClient.Get(ctx, req.NamespacedName, &updated))
+	updated.Spec.Deployment.Mode = nextdnsv1alpha1.DeploymentModeDaemonSet
+	require.NoError(t, fakeClient.Update(ctx, &updated))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	daemonSet := &appsv1.DaemonSet{}
+	require.NoError(t, fakeClient.Get(ctx, workloadKey, daemonSet))
+	require.NotNil(t, daemonSet.Spec.UpdateStrategy.RollingUpdate)
+	assert.Equal(t, maxUnavailable, *daemonSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable)
+	assert.Nil(t, daemonSet.Spec.UpdateStrategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, int32(10), daemonSet.Spec.MinReadySeconds)
+}
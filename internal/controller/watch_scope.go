@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// WatchScope restricts the resources an operator instance reconciles, so
+// several instances can share a cluster with disjoint scopes
+type WatchScope struct {
+	// Namespaces are the namespaces whose resources are watched; all
+	// namespaces when empty. Cluster-scoped resources are always watched.
+	Namespaces []string
+
+	// LabelSelector selects the nextdns.io resources that are reconciled;
+	// all of them when nil. Objects the operator creates, such as
+	// Deployments and Secrets, and objects it only reads are not filtered.
+	LabelSelector labels.Selector
+}
+
+// ParseWatchNamespaces parses a comma-separated list of namespaces
+func ParseWatchNamespaces(value string) ([]string, error) {
+	var namespaces []string
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+		}
+		if !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
+}
+
+// ParseWatchLabelSelector parses a label selector such as
+// "nextdns.io/instance=blue", returning nil for an empty value
+func ParseWatchLabelSelector(value string) (labels.Selector, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", value, err)
+	}
+	return selector, nil
+}
+
+// watchedResources returns an empty object of each nextdns.io kind the
+// operator reconciles
+func watchedResources() []client.Object {
+	return []client.Object{
+		&nextdnsv1alpha1.NextDNSProfile{},
+		&nextdnsv1alpha1.NextDNSProfileTemplate{},
+		&nextdnsv1alpha1.NextDNSProfileGenerator{},
+		&nextdnsv1alpha1.NextDNSCoreDNS{},
+		&nextdnsv1alpha1.NextDNSDevice{},
+		&nextdnsv1alpha1.NextDNSReport{},
+		&nextdnsv1alpha1.NextDNSAllowlist{},
+		&nextdnsv1alpha1.NextDNSDenylist{},
+		&nextdnsv1alpha1.ClusterNextDNSAllowlist{},
+		&nextdnsv1alpha1.ClusterNextDNSDenylist{},
+		&nextdnsv1alpha1.NextDNSDenylistSource{},
+		&nextdnsv1alpha1.NextDNSTLDList{},
+		&nextdnsv1alpha1.NextDNSRewrite{},
+	}
+}
+
+// CacheOptions returns the manager cache options watching only the
+// resources in scope
+func (s WatchScope) CacheOptions() cache.Options {
+	var options cache.Options
+	if len(s.Namespaces) > 0 {
+		options.DefaultNamespaces = make(map[string]cache.Config, len(s.Namespaces))
+		for _, namespace := range s.Namespaces {
+			options.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+	if s.LabelSelector != nil && !s.LabelSelector.Empty() {
+		options.ByObject = make(map[client.Object]cache.ByObject)
+		for _, obj := range watchedResources() {
+			options.ByObject[obj] = cache.ByObject{Label: s.LabelSelector}
+		}
+	}
+	return options
+}
+
+// String describes the scope for logs
+func (s WatchScope) String() string {
+	namespaces := "all namespaces"
+	if len(s.Namespaces) > 0 {
+		namespaces = "namespaces " + strings.Join(s.Namespaces, ",")
+	}
+	if s.LabelSelector == nil || s.LabelSelector.Empty() {
+		return namespaces
+	}
+	return fmt.Sprintf("%s, resources matching %s", namespaces, s.LabelSelector)
+}
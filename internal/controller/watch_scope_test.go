@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestParseWatchNamespaces(t *testing.T) {
+	namespaces, err := ParseWatchNamespaces(" team-a, team-b ,team-a,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-a", "team-b"}, namespaces)
+
+	namespaces, err = ParseWatchNamespaces("")
+	require.NoError(t, err)
+	assert.Empty(t, namespaces)
+
+	_, err = ParseWatchNamespaces("team_a")
+	assert.Error(t, err)
+}
+
+func TestParseWatchLabelSelector(t *testing.T) {
+	selector, err := ParseWatchLabelSelector("nextdns.io/instance=blue")
+	require.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set{"nextdns.io/instance": "blue"}))
+	assert.False(t, selector.Matches(labels.Set{"nextdns.io/instance": "green"}))
+
+	selector, err = ParseWatchLabelSelector(" ")
+	require.NoError(t, err)
+	assert.Nil(t, selector)
+
+	_, err = ParseWatchLabelSelector("nextdns.io/instance in (blue")
+	assert.Error(t, err)
+}
+
+func TestWatchScope_CacheOptions(t *testing.T) {
+	assert.Equal(t, cache.Options{}, WatchScope{}.CacheOptions())
+	assert.Equal(t, "all namespaces", WatchScope{}.String())
+
+	selector, err := labels.Parse("nextdns.io/instance=blue")
+	require.NoError(t, err)
+	scope := WatchScope{Namespaces: []string{"team-a", "team-b"}, LabelSelector: selector}
+	options := scope.CacheOptions()
+
+	assert.Equal(t, map[string]cache.Config{"team-a": {}, "team-b": {}}, options.DefaultNamespaces)
+	assert.Len(t, options.ByObject, len(watchedResources()))
+	assert.Contains(t, watchedResources(), &nextdnsv1alpha1.NextDNSProfile{})
+	for obj, byObject := range options.ByObject {
+		assert.Equal(t, selector, byObject.Label, "%T", obj)
+	}
+	assert.Equal(t, "namespaces team-a,team-b, resources matching nextdns.io/instance=blue", scope.String())
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ControllerNames are the names of the controllers whose workqueue rate
+// limits and concurrency can be overridden, as used in metrics and logs
+var ControllerNames = []string{
+	"clusternextdnsallowlist",
+	"clusternextdnsdenylist",
+	"nextdnsaccount",
+	"nextdnsallowlist",
+	"nextdnscoredns",
+	"nextdnsdenylist",
+	"nextdnsdenylistsource",
+	"nextdnsdevice",
+	"nextdnsprofile",
+	"nextdnsprofilegenerator",
+	"nextdnsreport",
+	"nextdnsrewrite",
+	"nextdnstldlist",
+	"secretreplication",
+}
+
+// ControllerRateLimit overrides the workqueue rate limit of one controller
+type ControllerRateLimit struct {
+	// QPS is the sustained rate at which requests are released
+	QPS float64
+
+	// Burst is the number of requests released above QPS in a burst; 0 keeps
+	// the default burst
+	Burst int
+}
+
+// WorkqueueConfig tunes how fast the controllers pull requests from their
+// workqueues and how many they reconcile at once
+type WorkqueueConfig struct {
+	// BaseDelay is the requeue delay after the first failure of a request,
+	// doubled on every further failure
+	BaseDelay time.Duration
+
+	// MaxDelay caps the requeue delay of a failing request
+	MaxDelay time.Duration
+
+	// QPS is the sustained rate at which each controller releases requests
+	QPS float64
+
+	// Burst is the number of requests each controller releases above QPS in
+	// a burst
+	Burst int
+
+	// MaxConcurrentReconciles is the number of requests each controller
+	// reconciles at once
+	MaxConcurrentReconciles int
+
+	// ControllerRateLimits overrides QPS and Burst by controller name
+	ControllerRateLimits map[string]ControllerRateLimit
+
+	// ControllerConcurrency overrides MaxConcurrentReconciles by controller
+	// name
+	ControllerConcurrency map[string]int
+}
+
+// DefaultWorkqueueConfig matches the controller-runtime defaults
+var DefaultWorkqueueConfig = WorkqueueConfig{
+	BaseDelay:               5 * time.Millisecond,
+	MaxDelay:                1000 * time.Second,
+	QPS:                     10,
+	Burst:                   100,
+	MaxConcurrentReconciles: 1,
+}
+
+// Validate checks that the delays, rates and concurrency are positive and
+// the overrides name known controllers
+func (c WorkqueueConfig) Validate() error {
+	if c.BaseDelay <= 0 || c.MaxDelay <= 0 {
+		return errors.New("workqueue delays must be positive")
+	}
+	if c.BaseDelay > c.MaxDelay {
+		return errors.New("workqueue base delay must not exceed the maximum delay")
+	}
+	if c.QPS <= 0 || c.Burst <= 0 {
+		return errors.New("workqueue QPS and burst must be positive")
+	}
+	if c.MaxConcurrentReconciles < 1 {
+		return errors.New("max concurrent reconciles must be at least 1")
+	}
+	for name, limit := range c.ControllerRateLimits {
+		if !slices.Contains(ControllerNames, name) {
+			return fmt.Errorf("unknown controller %q in controller rate limits", name)
+		}
+		if limit.QPS <= 0 || limit.Burst < 0 {
+			return fmt.Errorf("rate limit of controller %q must have a positive QPS and a non-negative burst", name)
+		}
+	}
+	for name, concurrency := range c.ControllerConcurrency {
+		if !slices.Contains(ControllerNames, name) {
+			return fmt.Errorf("unknown controller %q in controller concurrency", name)
+		}
+		if concurrency < 1 {
+			return fmt.Errorf("concurrency of controller %q must be at least 1", name)
+		}
+	}
+	return nil
+}
+
+// ParseControllerRateLimits parses comma-separated NAME=QPS[/BURST] items
+func ParseControllerRateLimits(value string) (map[string]ControllerRateLimit, error) {
+	limits := make(map[string]ControllerRateLimit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, budget, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid controller rate limit %q: expected NAME=QPS[/BURST]", item)
+		}
+		qpsValue, burstValue, hasBurst := strings.Cut(budget, "/")
+		var limit ControllerRateLimit
+		var err error
+		if limit.QPS, err = strconv.ParseFloat(qpsValue, 64); err != nil {
+			return nil, fmt.Errorf("invalid QPS in controller rate limit %q: %w", item, err)
+		}
+		if hasBurst {
+			if limit.Burst, err = strconv.Atoi(burstValue); err != nil {
+				return nil, fmt.Errorf("invalid burst in controller rate limit %q: %w", item, err)
+			}
+		}
+		limits[strings.ToLower(name)] = limit
+	}
+	return limits, nil
+}
+
+// ParseControllerConcurrency parses comma-separated NAME=N items
+func ParseControllerConcurrency(value string) (map[string]int, error) {
+	concurrency := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, workers, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid controller concurrency %q: expected NAME=N", item)
+		}
+		n, err := strconv.Atoi(workers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid controller concurrency %q: %w", item, err)
+		}
+		concurrency[strings.ToLower(name)] = n
+	}
+	return concurrency, nil
+}
+
+// concurrency returns the number of requests the named controller
+// reconciles at once
+func (c WorkqueueConfig) concurrency(name string) int {
+	if n, ok := c.ControllerConcurrency[name]; ok {
+		return n
+	}
+	return c.MaxConcurrentReconciles
+}
+
+// rateLimit returns the QPS and burst of the named controller
+func (c WorkqueueConfig) rateLimit(name string) (float64, int) {
+	qps, burst := c.QPS, c.Burst
+	if limit, ok := c.ControllerRateLimits[name]; ok {
+		qps = limit.QPS
+		if limit.Burst > 0 {
+			burst = limit.Burst
+		}
+	}
+	return qps, burst
+}
+
+// controllerOptions returns the options of the named controller: its
+// concurrency and a workqueue combining per-request exponential backoff with
+// an overall token bucket, as controller-runtime does by default, with the
+// delays and rates of c. DefaultWorkqueueConfig is used when c is nil.
+func controllerOptions(name string, c *WorkqueueConfig) controller.Options {
+	if c == nil {
+		c = &DefaultWorkqueueConfig
+	}
+	qps, burst := c.rateLimit(name)
+	return controller.Options{
+		MaxConcurrentReconciles: c.concurrency(name),
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](c.BaseDelay, c.MaxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+		),
+	}
+}
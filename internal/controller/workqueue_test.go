@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParseControllerRateLimits(t *testing.T) {
+	limits, err := ParseControllerRateLimits(" NextDNSProfile=2/20, nextdnscoredns=50 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ControllerRateLimit{
+		"nextdnsprofile": {QPS: 2, Burst: 20},
+		"nextdnscoredns": {QPS: 50},
+	}, limits)
+
+	limits, err = ParseControllerRateLimits("")
+	require.NoError(t, err)
+	assert.Empty(t, limits)
+
+	for _, value := range []string{"nextdnsprofile", "=5", "nextdnsprofile=fast", "nextdnsprofile=5/many"} {
+		_, err := ParseControllerRateLimits(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseControllerConcurrency(t *testing.T) {
+	concurrency, err := ParseControllerConcurrency(" NextDNSProfile=8, nextdnscoredns=2 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"nextdnsprofile": 8, "nextdnscoredns": 2}, concurrency)
+
+	concurrency, err = ParseControllerConcurrency("")
+	require.NoError(t, err)
+	assert.Empty(t, concurrency)
+
+	for _, value := range []string{"nextdnsprofile", "=5", "nextdnsprofile=many"} {
+		_, err := ParseControllerConcurrency(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseControllerOverrides_AccountController(t *testing.T) {
+	limits, err := ParseControllerRateLimits("nextdnsaccount=1/5")
+	require.NoError(t, err)
+	concurrency, err := ParseControllerConcurrency("NextDNSAccount=2")
+	require.NoError(t, err)
+
+	c := DefaultWorkqueueConfig
+	c.ControllerRateLimits = limits
+	c.ControllerConcurrency = concurrency
+	require.NoError(t, c.Validate())
+	assert.Equal(t, 2, c.concurrency("nextdnsaccount"))
+}
+
+func TestWorkqueueConfig_Validate(t *testing.T) {
+	require.NoError(t, DefaultWorkqueueConfig.Validate())
+
+	tests := map[string]func(c *WorkqueueConfig){
+		"zero base delay":          func(c *WorkqueueConfig) { c.BaseDelay = 0 },
+		"base delay above maximum": func(c *WorkqueueConfig) { c.BaseDelay = 2 * c.MaxDelay },
+		"zero QPS":                 func(c *WorkqueueConfig) { c.QPS = 0 },
+		"zero burst":               func(c *WorkqueueConfig) { c.Burst = 0 },
+		"zero concurrency":         func(c *WorkqueueConfig) { c.MaxConcurrentReconciles = 0 },
+		"unknown controller": func(c *WorkqueueConfig) {
+			c.ControllerRateLimits = map[string]ControllerRateLimit{"nextdnsprofiles": {QPS: 1}}
+		},
+		"zero controller QPS": func(c *WorkqueueConfig) {
+			c.ControllerRateLimits = map[string]ControllerRateLimit{"nextdnsprofile": {}}
+		},
+		"unknown controller concurrency": func(c *WorkqueueConfig) {
+			c.ControllerConcurrency = map[string]int{"nextdnsprofiles": 2}
+		},
+		"zero controller concurrency": func(c *WorkqueueConfig) {
+			c.ControllerConcurrency = map[string]int{"nextdnsprofile": 0}
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultWorkqueueConfig
+			mutate(&c)
+			assert.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestControllerOptions(t *testing.T) {
+	c := &WorkqueueConfig{
+		BaseDelay:               time.Second,
+		MaxDelay:                time.Minute,
+		QPS:                     10,
+		Burst:                   100,
+		MaxConcurrentReconciles: 4,
+		ControllerRateLimits:    map[string]ControllerRateLimit{"nextdnsprofile": {QPS: 1, Burst: 1}},
+		ControllerConcurrency:   map[string]int{"nextdnsprofile": 8},
+	}
+	require.NoError(t, c.Validate())
+
+	options := controllerOptions("nextdnscoredns", c)
+	assert.Equal(t, 4, options.MaxConcurrentReconciles)
+	request := reconcile.Request{}
+	assert.Equal(t, time.Second, options.RateLimiter.When(request), "the first failure waits the base delay")
+	assert.Equal(t, 2*time.Second, options.RateLimiter.When(request))
+	options.RateLimiter.Forget(request)
+	assert.Equal(t, time.Second, options.RateLimiter.When(request))
+
+	// The override leaves one token, so the second request waits for the bucket
+	options = controllerOptions("nextdnsprofile", c)
+	assert.Equal(t, 8, options.MaxConcurrentReconciles)
+	options.RateLimiter.When(reconcile.Request{})
+	other := reconcile.Request{}
+	other.Name = "other"
+	assert.Greater(t, options.RateLimiter.When(other), time.Second/2)
+
+	// Without a configuration the controller-runtime defaults apply
+	options = controllerOptions("nextdnsprofile", nil)
+	assert.Equal(t, DefaultWorkqueueConfig.MaxConcurrentReconciles, options.MaxConcurrentReconciles)
+	assert.Equal(t, DefaultWorkqueueConfig.BaseDelay, options.RateLimiter.When(reconcile.Request{}))
+}
@@ -0,0 +1,95 @@
+package coredns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AccessControlConfig restricts which clients may query the resolver. It is
+// rendered with the acl plugin into every server block clients reach;
+// refused clients get REFUSED.
+type AccessControlConfig struct {
+	// AllowCIDRs are the networks of the clients that may query. Empty
+	// allows every client not in DenyCIDRs.
+	AllowCIDRs []string
+
+	// DenyCIDRs are the networks of the clients refused, even when they are
+	// also in AllowCIDRs
+	DenyCIDRs []string
+}
+
+// enabled reports whether the config restricts any client
+func (c *AccessControlConfig) enabled() bool {
+	return c != nil && (len(c.AllowCIDRs) > 0 || len(c.DenyCIDRs) > 0)
+}
+
+// ValidateAccessControl checks that every allowed and denied network is a
+// valid CIDR. Returns an error describing all validation failures.
+func ValidateAccessControl(cfg *AccessControlConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []string
+	for _, cidr := range cfg.AllowCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid allowed CIDR %q", cidr))
+		}
+	}
+	for _, cidr := range cfg.DenyCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid denied CIDR %q", cidr))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("access control validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// listenerRelay returns the address the encrypted listeners forward their
+// queries to: the loopback interface, or the first bind address
+func listenerRelay(bindAddresses []string) string {
+	if len(bindAddresses) > 0 {
+		return bindAddresses[0]
+	}
+	return "127.0.0.1"
+}
+
+// relaySources returns the networks queries relayed by the encrypted
+// listeners arrive from, which already passed the listeners' own access
+// control, or nil when no listener is served
+func relaySources(cfg *CorefileConfig) []string {
+	if cfg.DoH == nil && cfg.DoT == nil {
+		return nil
+	}
+	relay := listenerRelay(cfg.BindAddresses)
+	if ip := net.ParseIP(relay); ip != nil && ip.To4() == nil {
+		return []string{relay + "/128"}
+	}
+	return []string{relay + "/32"}
+}
+
+// writeAccessControlBlock writes an acl plugin block refusing the denied
+// clients and, when there is an allow list, every client outside it and
+// trusted. policies are written between the two, so the server block's own
+// rules only apply to clients that are not denied. Nothing is written when
+// there is neither access control nor a policy.
+func writeAccessControlBlock(sb *strings.Builder, ac *AccessControlConfig, trusted []string, policies ...string) {
+	if !ac.enabled() && len(policies) == 0 {
+		return
+	}
+	sb.WriteString("    acl {\n")
+	if ac.enabled() && len(ac.DenyCIDRs) > 0 {
+		fmt.Fprintf(sb, "        block net %s\n", strings.Join(ac.DenyCIDRs, " "))
+	}
+	for _, policy := range policies {
+		fmt.Fprintf(sb, "        %s\n", policy)
+	}
+	if ac.enabled() && len(ac.AllowCIDRs) > 0 {
+		allowed := append(append([]string{}, ac.AllowCIDRs...), trusted...)
+		fmt.Fprintf(sb, "        allow net %s\n", strings.Join(allowed, " "))
+		sb.WriteString("        block\n")
+	}
+	sb.WriteString("    }\n")
+}
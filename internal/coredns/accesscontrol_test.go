@@ -0,0 +1,138 @@
+package coredns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateAccessControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *AccessControlConfig
+		wantErr string
+	}{
+		{name: "nil", cfg: nil},
+		{name: "valid", cfg: &AccessControlConfig{
+			AllowCIDRs: []string{"192.168.0.0/16", "fd00::/8"},
+			DenyCIDRs:  []string{"192.168.50.0/24"},
+		}},
+		{name: "invalid allowed CIDR", cfg: &AccessControlConfig{AllowCIDRs: []string{"192.168.1.1"}}, wantErr: `invalid allowed CIDR "192.168.1.1"`},
+		{name: "invalid denied CIDR", cfg: &AccessControlConfig{DenyCIDRs: []string{"lan"}}, wantErr: `invalid denied CIDR "lan"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAccessControl(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateCorefile_AccessControl(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		DomainOverrides: []DomainOverrideConfig{
+			{Domain: "corp.example.com", Upstreams: []string{"10.0.0.1"}},
+		},
+		LocalZone: &LocalZoneConfig{Zone: "home.lan", AllowedCIDRs: []string{"192.168.1.53/32"}},
+		DoT: &TLSListenerConfig{
+			CertFile: TLSMountPath + "/dot/tls.crt",
+			KeyFile:  TLSMountPath + "/dot/tls.key",
+		},
+		AccessControl: &AccessControlConfig{
+			AllowCIDRs: []string{"192.168.0.0/16", "fd00::/8"},
+			DenyCIDRs:  []string{"192.168.50.0/24"},
+		},
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	expected := `corp.example.com {
+    acl {
+        block net 192.168.50.0/24
+        allow net 192.168.0.0/16 fd00::/8 127.0.0.1/32
+        block
+    }
+    forward . 10.0.0.1
+    cache 30
+    errors
+}
+
+home.lan {
+    file /etc/coredns/local.zone
+    acl {
+        block net 192.168.50.0/24
+        allow type AXFR IXFR net 192.168.1.53/32
+        block type AXFR IXFR
+        allow net 192.168.0.0/16 fd00::/8 127.0.0.1/32
+        block
+    }
+    transfer {
+        to *
+    }
+    errors
+}
+
+. {
+    acl {
+        block net 192.168.50.0/24
+        allow net 192.168.0.0/16 fd00::/8 127.0.0.1/32
+        block
+    }
+    forward . tls://45.90.28.0 tls://45.90.30.0 {
+        tls_servername abc123.dns.nextdns.io
+    }
+    cache 3600
+    health :8080
+    ready :8181
+    errors
+}
+
+tls://.:853 {
+    acl {
+        block net 192.168.50.0/24
+        allow net 192.168.0.0/16 fd00::/8
+        block
+    }
+    tls /etc/coredns-tls/dot/tls.crt /etc/coredns-tls/dot/tls.key
+    forward . 127.0.0.1:53
+    errors
+}`
+	assert.Equal(t, expected, corefile)
+}
+
+func TestGenerateCorefile_AccessControlDenyOnly(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		AccessControl:   &AccessControlConfig{DenyCIDRs: []string{"10.0.0.0/8"}},
+		RateLimit: &RateLimitConfig{Rules: []RateLimitRuleConfig{
+			{Sources: []string{"192.168.9.0/24"}, QueriesPerSecond: ptr.To[int32](0)},
+			{Sources: []string{"192.168.1.0/24"}},
+		}},
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	assert.Equal(t, 2, strings.Count(corefile, "    acl {\n        block net 10.0.0.0/8\n    }\n"),
+		"the resolving blocks refuse denied clients, got:\n%s", corefile)
+	assert.Contains(t, corefile, "    acl {\n        drop\n    }\n", "the dropping block only drops")
+	assert.NotContains(t, corefile, "allow net")
+
+	cfg.AccessControl = &AccessControlConfig{}
+	cfg.RateLimit = nil
+	assert.NotContains(t, GenerateCorefile(cfg), "acl", "empty access control writes no acl block")
+}
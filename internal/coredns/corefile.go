@@ -3,9 +3,12 @@
 package coredns
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,13 +16,47 @@ import (
 // DefaultCoreDNSImage is the default CoreDNS container image to use.
 const DefaultCoreDNSImage = "mirror.gcr.io/coredns/coredns:1.13.1"
 
+// DefaultReplicas is the default number of CoreDNS replicas in Deployment mode.
+const DefaultReplicas int32 = 2
+
+// DefaultCacheTTL is the default cache TTL in seconds for successful responses.
+const DefaultCacheTTL int32 = 3600
+
+// DefaultCacheCapacity is the cache plugin's default number of entries per
+// response class. It is written when a denial TTL is set, since the denial
+// directive takes the capacity first.
+const DefaultCacheCapacity int32 = 9984
+
+// RedactedLogFormat is the log plugin's default common log format with the
+// client address and port replaced by "-".
+const RedactedLogFormat = `- - {>id} "{type} {class} {name} {proto} {size} {>do} {>bufsize}" {rcode} {>rflags} {rsize} {duration}`
+
 // Protocol constants for DNS resolution methods.
 const (
 	ProtocolDoT = "DoT" // DNS-over-TLS
 	ProtocolDoH = "DoH" // DNS-over-HTTPS
 	ProtocolDNS = "DNS" // Plain DNS (UDP/TCP)
+	ProtocolDoQ = "DoQ" // DNS-over-QUIC, not supported by the forward plugin
 )
 
+// ErrUnsupportedProtocol is returned by ValidateProtocol for protocols the
+// operator knows but cannot render into a Corefile
+var ErrUnsupportedProtocol = errors.New("unsupported upstream protocol")
+
+// ValidateProtocol checks that protocol can be rendered into the forward
+// plugin. DoQ fails with ErrUnsupportedProtocol; unknown values fail with a
+// plain error so a typo never produces a Corefile without an upstream.
+func ValidateProtocol(protocol string) error {
+	switch protocol {
+	case ProtocolDoT, ProtocolDoH, ProtocolDNS:
+		return nil
+	case ProtocolDoQ:
+		return fmt.Errorf("%w %s: the CoreDNS forward plugin cannot reach QUIC upstreams; use DoT or DoH", ErrUnsupportedProtocol, protocol)
+	default:
+		return fmt.Errorf("unknown upstream protocol %q: must be one of DoT, DoH or DNS", protocol)
+	}
+}
+
 // NextDNS server endpoints.
 const (
 	nextDNSDoTServer  = "dns.nextdns.io"
@@ -28,12 +65,39 @@ const (
 	nextDNSAnycastIP2 = "45.90.30.0"
 )
 
+// DoHHost is the hostname of the NextDNS DoH endpoint. CoreDNS has to
+// resolve it before it can forward DoH queries.
+const DoHHost = nextDNSDoHServer
+
+// ValidateBootstrapServers checks that each DoH bootstrap server is an IP
+// address. Returns an error describing all validation failures.
+func ValidateBootstrapServers(servers []string) error {
+	var errs []string
+	for i, server := range servers {
+		if net.ParseIP(server) == nil {
+			errs = append(errs, fmt.Sprintf("bootstrap server %d: invalid ip %q", i, server))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("bootstrap servers validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // Default plugin listen ports. These preserve the pre-feature hardcoded
 // behavior when the corresponding config pointer is nil or Port is 0.
 const (
-	defaultHealthPort  int32 = 8080
-	defaultReadyPort   int32 = 8181
-	defaultMetricsPort int32 = 9153
+	DefaultHealthPort  int32 = 8080
+	DefaultReadyPort   int32 = 8181
+	DefaultMetricsPort int32 = 9153
+)
+
+// Encrypted listener defaults. Each listener's certificate is mounted from a
+// kubernetes.io/tls Secret in its own directory under TLSMountPath.
+const (
+	DefaultDoHListenerPort int32 = 443
+	DefaultDoTListenerPort int32 = 853
+	TLSMountPath                 = "/etc/coredns-tls"
 )
 
 // ForwardTuningConfig holds per-deployment forward plugin tuning options.
@@ -81,6 +145,66 @@ func ValidateForwardTuning(t *ForwardTuningConfig) error {
 	return nil
 }
 
+// CachePrefetchConfig configures the cache plugin prefetch directive.
+type CachePrefetchConfig struct {
+	Amount     int32  // queries within Duration that make an entry popular
+	Duration   string // duration string; empty means CoreDNS default (1m)
+	Percentage *int32 // remaining TTL percentage at which to prefetch
+}
+
+// CacheServeStaleConfig configures the cache plugin serve_stale directive.
+type CacheServeStaleConfig struct {
+	Duration    string // duration string; empty means CoreDNS default (1h)
+	RefreshMode string // immediate or verify; empty means CoreDNS default
+}
+
+// CacheTuningConfig holds cache plugin options beyond the success TTL.
+// All fields optional; nil fields keep the CoreDNS defaults.
+type CacheTuningConfig struct {
+	DenialTTL  *int32
+	Prefetch   *CachePrefetchConfig
+	ServeStale *CacheServeStaleConfig
+}
+
+// ValidateCacheTuning checks that durations parse cleanly and numbers are in
+// range. A nil config is valid.
+func ValidateCacheTuning(t *CacheTuningConfig) error {
+	if t == nil {
+		return nil
+	}
+	var errs []string
+	if t.DenialTTL != nil && *t.DenialTTL < 0 {
+		errs = append(errs, fmt.Sprintf("denialTTL must be >= 0, got %d", *t.DenialTTL))
+	}
+	if p := t.Prefetch; p != nil {
+		if p.Amount < 1 {
+			errs = append(errs, fmt.Sprintf("prefetch amount must be >= 1, got %d", p.Amount))
+		}
+		if p.Duration != "" {
+			if _, err := time.ParseDuration(p.Duration); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid prefetch duration %q: %v", p.Duration, err))
+			}
+		}
+		if p.Percentage != nil && (*p.Percentage < 0 || *p.Percentage > 100) {
+			errs = append(errs, fmt.Sprintf("prefetch percentage must be between 0 and 100, got %d", *p.Percentage))
+		}
+	}
+	if st := t.ServeStale; st != nil {
+		if st.Duration != "" {
+			if _, err := time.ParseDuration(st.Duration); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid serveStale duration %q: %v", st.Duration, err))
+			}
+		}
+		if st.RefreshMode != "" && st.RefreshMode != "immediate" && st.RefreshMode != "verify" {
+			errs = append(errs, fmt.Sprintf("invalid serveStale refreshMode %q", st.RefreshMode))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("cache tuning validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // HostsEntryConfig is a single IP-to-hostnames mapping for the hosts plugin.
 type HostsEntryConfig struct {
 	IP        string
@@ -119,11 +243,105 @@ func ValidateHostsEntries(entries []HostsEntryConfig) error {
 	return nil
 }
 
+// Local record types answered by the template plugin.
+const (
+	RecordTypeA     = "A"
+	RecordTypeAAAA  = "AAAA"
+	RecordTypeCNAME = "CNAME"
+)
+
+// DefaultLocalRecordTTL is the TTL in seconds of local records that set none.
+const DefaultLocalRecordTTL int32 = 3600
+
+// LocalRecordConfig is a static record answered by a template plugin block.
+type LocalRecordConfig struct {
+	Name  string
+	Type  string // A, AAAA or CNAME
+	Value string
+	TTL   int32
+}
+
+// dnsLabelPattern matches one label of a record name. Underscores are
+// allowed for service names like _sip._tcp.
+var dnsLabelPattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?$`)
+
+// validDNSName reports whether name is a DNS name with an optional
+// trailing dot
+func validDNSName(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !dnsLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// fqdn returns name in lower case with a trailing dot
+func fqdn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
+
+// ValidateLocalRecords checks that each record has a valid name and a value
+// matching its type, and that a name with a CNAME record has no other
+// records. Returns an error describing all validation failures.
+func ValidateLocalRecords(records []LocalRecordConfig) error {
+	var errs []string
+	types := map[string]map[string]int{}
+	for i, r := range records {
+		if !validDNSName(r.Name) {
+			errs = append(errs, fmt.Sprintf("local record %d: invalid name %q", i, r.Name))
+			continue
+		}
+		switch r.Type {
+		case RecordTypeA:
+			if ip := net.ParseIP(r.Value); ip == nil || ip.To4() == nil {
+				errs = append(errs, fmt.Sprintf("local record %d: A value %q is not an IPv4 address", i, r.Value))
+			}
+		case RecordTypeAAAA:
+			if ip := net.ParseIP(r.Value); ip == nil || ip.To4() != nil {
+				errs = append(errs, fmt.Sprintf("local record %d: AAAA value %q is not an IPv6 address", i, r.Value))
+			}
+		case RecordTypeCNAME:
+			if !validDNSName(r.Value) {
+				errs = append(errs, fmt.Sprintf("local record %d: CNAME value %q is not a valid name", i, r.Value))
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("local record %d: invalid type %q", i, r.Type))
+			continue
+		}
+		if r.TTL < 0 {
+			errs = append(errs, fmt.Sprintf("local record %d: ttl must be >= 0, got %d", i, r.TTL))
+		}
+		name := fqdn(r.Name)
+		if types[name] == nil {
+			types[name] = map[string]int{}
+		}
+		types[name][r.Type]++
+	}
+	for _, r := range records {
+		name := fqdn(r.Name)
+		counts := types[name]
+		if counts[RecordTypeCNAME] > 0 && (counts[RecordTypeCNAME] > 1 || len(counts) > 1) {
+			errs = append(errs, fmt.Sprintf("local record name %s: a CNAME record cannot share its name with other records", name))
+			delete(types, name)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("local record validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // DomainOverrideConfig represents a domain-specific upstream configuration
 type DomainOverrideConfig struct {
 	Domain    string
 	Upstreams []string
 	CacheTTL  int32 // 0 means use default (30 seconds)
+	Metrics   bool  // load the prometheus plugin in this block (requires MetricsEnabled)
 }
 
 // RewriteRuleConfig represents a single CoreDNS rewrite plugin rule.
@@ -191,6 +409,13 @@ type ErrorsPluginConfig struct {
 	Consolidate []ConsolidateRuleConfig
 }
 
+// TLSListenerConfig configures an encrypted (DoH or DoT) server block.
+type TLSListenerConfig struct {
+	Port     int32 // 0 means use the listener's default port
+	CertFile string
+	KeyFile  string
+}
+
 // CorefileConfig holds the configuration for generating a CoreDNS Corefile.
 type CorefileConfig struct {
 	// ProfileID is the NextDNS profile ID to use for DNS resolution.
@@ -205,9 +430,17 @@ type CorefileConfig struct {
 	// CacheTTL specifies the cache TTL in seconds.
 	CacheTTL int32
 
+	// CacheTuning optionally configures negative caching, prefetch and
+	// serve_stale. When nil the cache directive is a single line.
+	CacheTuning *CacheTuningConfig
+
 	// LoggingEnabled controls whether the log plugin is enabled.
 	LoggingEnabled bool
 
+	// LogRedactClient logs queries with RedactedLogFormat, which leaves out
+	// the client address and port. Only honored when LoggingEnabled is true.
+	LogRedactClient bool
+
 	// MetricsEnabled controls whether the prometheus plugin is enabled.
 	MetricsEnabled bool
 
@@ -218,6 +451,10 @@ type CorefileConfig struct {
 	// Falls back to anycast IPs (45.90.28.0, 45.90.30.0) if empty.
 	UpstreamIPv4 []string
 
+	// UpstreamIPv6 contains profile-specific IPv6 addresses forwarded to in
+	// addition to the IPv4 ones for DoT/DNS. Empty keeps IPv4-only forwarding.
+	UpstreamIPv6 []string
+
 	// RewriteRules specifies CoreDNS rewrite plugin rules to emit before the
 	// forward directive in the catch-all server block.
 	RewriteRules []RewriteRuleConfig
@@ -230,6 +467,14 @@ type CorefileConfig struct {
 	// When set, a hosts block is emitted before the forward plugin.
 	Hosts *HostsPluginConfig
 
+	// LocalRecords are answered by template plugin blocks emitted before the
+	// forward plugin, one block per name and type.
+	LocalRecords []LocalRecordConfig
+
+	// ReverseRecords adds PTR answers for the private addresses of the A
+	// and AAAA local records.
+	ReverseRecords bool
+
 	// Health configures the CoreDNS health plugin. nil means "use defaults
 	// (enabled on port 8080, no lameduck)" so the generated output is
 	// byte-identical to the pre-feature behavior.
@@ -246,6 +491,45 @@ type CorefileConfig struct {
 	// MetricsPort overrides the prometheus plugin port. 0 means default 9153.
 	// Only honored when MetricsEnabled is true.
 	MetricsPort int32
+
+	// MetricsAddress is the IP the prometheus plugin binds to. Empty binds
+	// all interfaces. Only honored when MetricsEnabled is true.
+	MetricsAddress string
+
+	// DoH adds a DNS-over-HTTPS server block that relays queries to the
+	// plain DNS listener, so they get the same overrides, hosts, cache and
+	// upstream. nil disables it.
+	DoH *TLSListenerConfig
+
+	// DoT adds a DNS-over-TLS server block relaying to the plain DNS
+	// listener like DoH. nil disables it.
+	DoT *TLSListenerConfig
+
+	// BindAddresses restricts every server block to these addresses with the
+	// bind plugin. Empty binds all interfaces. Encrypted listeners then relay
+	// to the first address instead of the loopback interface.
+	BindAddresses []string
+
+	// LocalZone adds a server block answering the zone from the local zone
+	// file and allowing zone transfers. nil disables it.
+	LocalZone *LocalZoneConfig
+
+	// SearchDomains are answered NXDOMAIN in the catch-all block after the
+	// hosts entries and local records, instead of being forwarded
+	SearchDomains []string
+
+	// Fallback adds a loopback server block forwarding to a second profile,
+	// which the catch-all block fails over to when every primary upstream
+	// fails its health check. nil disables it.
+	Fallback *FallbackUpstreamConfig
+
+	// RateLimit limits the queries each client may send to the catch-all
+	// zone. nil leaves clients unlimited.
+	RateLimit *RateLimitConfig
+
+	// AccessControl restricts which clients may query any server block.
+	// nil allows every client.
+	AccessControl *AccessControlConfig
 }
 
 // ValidateDomainOverrides checks for duplicate domains and invalid upstream values.
@@ -277,51 +561,132 @@ func GenerateCorefile(cfg *CorefileConfig) string {
 
 	// Generate domain override blocks first (order matters in CoreDNS)
 	for _, override := range cfg.DomainOverrides {
-		writeDomainOverrideBlock(&sb, &override)
+		writeDomainOverrideBlock(&sb, &override, cfg)
 	}
 
+	// Local zone block (conditional)
+	writeLocalZoneBlock(&sb, cfg)
+
+	// Rate limit rules select copies of the catch-all block by client
+	writeRateLimitViewBlocks(&sb, cfg)
+
 	// Generate the catch-all block for NextDNS
 	sb.WriteString(". {\n")
+	writeBindDirective(&sb, cfg.BindAddresses)
+	writeAccessControlBlock(&sb, cfg.AccessControl, relaySources(cfg))
+	if cfg.RateLimit != nil {
+		writeRateLimitDirective(&sb, cfg.RateLimit.QueriesPerSecond)
+	}
+
+	writeResolverPlugins(&sb, cfg)
+
+	// Health plugin for liveness probes (configurable port + optional lameduck)
+	writeHealthBlock(&sb, cfg.Health)
+
+	// Ready plugin for readiness probes (configurable port, can be disabled)
+	writeReadyBlock(&sb, cfg.Ready)
 
+	writeReportingPlugins(&sb, cfg)
+
+	sb.WriteString("}")
+
+	// Fallback profile reached from the catch-all forward (conditional)
+	writeFallbackBlock(&sb, cfg)
+
+	// Encrypted listeners (conditional)
+	writeTLSListenerBlock(&sb, "https", cfg.DoH, DefaultDoHListenerPort, cfg)
+	writeTLSListenerBlock(&sb, "tls", cfg.DoT, DefaultDoTListenerPort, cfg)
+
+	return sb.String()
+}
+
+// writeResolverPlugins writes the plugins of the catch-all block that
+// answer or forward queries
+func writeResolverPlugins(sb *strings.Builder, cfg *CorefileConfig) {
 	// Rewrite directives fire first so the (possibly rewritten) query is
 	// matched by hosts and then forwarded (CoreDNS plugin order matters).
-	writeRewriteRules(&sb, cfg.RewriteRules)
+	writeRewriteRules(sb, cfg.RewriteRules)
 
 	// Hosts block (before forward, so static entries resolve without hitting NextDNS)
-	writeHostsBlock(&sb, cfg.Hosts)
+	writeHostsBlock(sb, cfg.Hosts)
 
-	// Generate forward plugin configuration
-	writeForwardPlugin(&sb, cfg)
+	// Local records (before forward, so they resolve without hitting NextDNS)
+	writeLocalRecordBlocks(sb, cfg.LocalRecords)
+	if cfg.ReverseRecords {
+		writeReverseRecordBlocks(sb, cfg.LocalRecords)
+	}
 
-	// Cache plugin
-	fmt.Fprintf(&sb, "    cache %d\n", cfg.CacheTTL)
+	// Search domain expansions (after local names, before forward)
+	writeSearchDomainBlock(sb, cfg.SearchDomains)
 
-	// Health plugin for liveness probes (configurable port + optional lameduck)
-	writeHealthBlock(&sb, cfg.Health)
+	// Generate forward plugin configuration
+	writeForwardPlugin(sb, cfg)
 
-	// Ready plugin for readiness probes (configurable port, can be disabled)
-	writeReadyBlock(&sb, cfg.Ready)
+	// Cache plugin
+	writeCacheBlock(sb, cfg.CacheTTL, cfg.CacheTuning)
+}
 
-	// Prometheus plugin for metrics (conditional, configurable port)
+// writeReportingPlugins writes the metrics, query log and error plugins of
+// the catch-all block
+func writeReportingPlugins(sb *strings.Builder, cfg *CorefileConfig) {
+	// Prometheus plugin for metrics (conditional, configurable listen address)
 	if cfg.MetricsEnabled {
-		mPort := cfg.MetricsPort
-		if mPort == 0 {
-			mPort = defaultMetricsPort
-		}
-		fmt.Fprintf(&sb, "    prometheus :%d\n", mPort)
+		writePrometheusDirective(sb, cfg)
 	}
 
 	// Log plugin (conditional)
-	if cfg.LoggingEnabled {
-		sb.WriteString("    log\n")
-	}
+	writeLogDirective(sb, cfg)
 
 	// Errors plugin (configurable, may include consolidate rules)
-	writeErrorsBlock(&sb, cfg.Errors)
+	writeErrorsBlock(sb, cfg.Errors)
+}
+
+// writeLogDirective writes the log plugin directive when logging is enabled,
+// with RedactedLogFormat when the client address is redacted. The log
+// plugin only logs the queries of the server block loading it, so every
+// block clients reach writes it.
+func writeLogDirective(sb *strings.Builder, cfg *CorefileConfig) {
+	switch {
+	case !cfg.LoggingEnabled:
+	case cfg.LogRedactClient:
+		fmt.Fprintf(sb, "    log . %s\n", strconv.Quote(RedactedLogFormat))
+	default:
+		sb.WriteString("    log\n")
+	}
+}
 
+// writeTLSListenerBlock writes an encrypted listener server block. Queries
+// are forwarded to the plain DNS listener on the loopback interface rather
+// than duplicating the catch-all block, so domain overrides also apply.
+// When the server blocks are bound to specific addresses, queries are
+// relayed to the first of them. Access control and the query log are
+// written here, against the real client address.
+func writeTLSListenerBlock(sb *strings.Builder, scheme string, listener *TLSListenerConfig, defaultPort int32, cfg *CorefileConfig) {
+	if listener == nil {
+		return
+	}
+	port := listener.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	relay := listenerRelay(cfg.BindAddresses)
+	fmt.Fprintf(sb, "\n\n%s://.:%d {\n", scheme, port)
+	writeBindDirective(sb, cfg.BindAddresses)
+	writeAccessControlBlock(sb, cfg.AccessControl, nil)
+	fmt.Fprintf(sb, "    tls %s %s\n", listener.CertFile, listener.KeyFile)
+	fmt.Fprintf(sb, "    forward . %s\n", net.JoinHostPort(relay, "53"))
+	writeLogDirective(sb, cfg)
+	sb.WriteString("    errors\n")
 	sb.WriteString("}")
+}
 
-	return sb.String()
+// writeBindDirective writes the bind plugin directive restricting a server
+// block to addresses, or nothing when addresses is empty
+func writeBindDirective(sb *strings.Builder, addresses []string) {
+	if len(addresses) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "    bind %s\n", strings.Join(addresses, " "))
 }
 
 // writeRewriteRules writes rewrite directives to the string builder.
@@ -336,13 +701,27 @@ func writeRewriteRules(sb *strings.Builder, rules []RewriteRuleConfig) {
 	}
 }
 
+// writePrometheusDirective writes the prometheus plugin directive. Every
+// server block uses the same listen address, so CoreDNS serves all of
+// them from one metrics endpoint.
+func writePrometheusDirective(sb *strings.Builder, cfg *CorefileConfig) {
+	mPort := cfg.MetricsPort
+	if mPort == 0 {
+		mPort = DefaultMetricsPort
+	}
+	fmt.Fprintf(sb, "    prometheus %s\n", net.JoinHostPort(cfg.MetricsAddress, fmt.Sprint(mPort)))
+}
+
 // writeDomainOverrideBlock writes a domain-specific server block.
-// Override blocks intentionally only include forward, cache, and errors.
-// Plugins like health, ready, prometheus, and log are omitted because they
-// only need to be configured once in the catch-all block — CoreDNS applies
-// them process-wide from there.
-func writeDomainOverrideBlock(sb *strings.Builder, override *DomainOverrideConfig) {
+// Override blocks only include forward, cache, log, errors and, when
+// requested, prometheus. Plugins like health and ready are omitted because
+// they only need to be configured once in the catch-all block — CoreDNS
+// applies them process-wide from there. log and prometheus are different:
+// they only see queries for the server blocks that load them.
+func writeDomainOverrideBlock(sb *strings.Builder, override *DomainOverrideConfig, cfg *CorefileConfig) {
 	fmt.Fprintf(sb, "%s {\n", override.Domain)
+	writeBindDirective(sb, cfg.BindAddresses)
+	writeAccessControlBlock(sb, cfg.AccessControl, relaySources(cfg))
 
 	// Build upstream list
 	upstreams := strings.Join(override.Upstreams, " ")
@@ -355,6 +734,11 @@ func writeDomainOverrideBlock(sb *strings.Builder, override *DomainOverrideConfi
 	}
 	fmt.Fprintf(sb, "    cache %d\n", cacheTTL)
 
+	if cfg.MetricsEnabled && override.Metrics {
+		writePrometheusDirective(sb, cfg)
+	}
+
+	writeLogDirective(sb, cfg)
 	sb.WriteString("    errors\n")
 	sb.WriteString("}\n\n")
 }
@@ -379,6 +763,86 @@ func writeHostsBlock(sb *strings.Builder, hosts *HostsPluginConfig) {
 	sb.WriteString("    }\n")
 }
 
+// writeLocalRecordBlocks writes a template plugin block for each name and
+// type of the local records, in the order they first appear. CNAME records
+// answer every query type; A and AAAA records answer only their own, so
+// other query types for the name fall through to the forward plugin.
+func writeLocalRecordBlocks(sb *strings.Builder, records []LocalRecordConfig) {
+	type recordKey struct{ name, recordType string }
+	var keys []recordKey
+	answers := map[recordKey][]LocalRecordConfig{}
+	for _, r := range records {
+		key := recordKey{fqdn(r.Name), r.Type}
+		if _, ok := answers[key]; !ok {
+			keys = append(keys, key)
+		}
+		answers[key] = append(answers[key], r)
+	}
+
+	for _, key := range keys {
+		queryType := key.recordType
+		if queryType == RecordTypeCNAME {
+			queryType = "ANY"
+		}
+		fmt.Fprintf(sb, "    template IN %s %s {\n", queryType, key.name)
+		fmt.Fprintf(sb, "        match \"(?i)^%s$\"\n", regexp.QuoteMeta(key.name))
+		for _, r := range answers[key] {
+			value := r.Value
+			if r.Type == RecordTypeCNAME {
+				value = fqdn(value)
+			}
+			fmt.Fprintf(sb, "        answer \"{{ .Name }} %d IN %s %s\"\n", r.TTL, r.Type, value)
+		}
+		sb.WriteString("        fallthrough\n")
+		sb.WriteString("    }\n")
+	}
+}
+
+// writeCacheBlock writes the cache plugin directive. A nil tuning config
+// produces the single "    cache TTL\n" line; otherwise the directive is a
+// block with one line per tuning option.
+func writeCacheBlock(sb *strings.Builder, ttl int32, t *CacheTuningConfig) {
+	if t == nil || (t.DenialTTL == nil && t.Prefetch == nil && t.ServeStale == nil) {
+		fmt.Fprintf(sb, "    cache %d\n", ttl)
+		return
+	}
+	fmt.Fprintf(sb, "    cache %d {\n", ttl)
+	if t.DenialTTL != nil {
+		fmt.Fprintf(sb, "        denial %d %d\n", DefaultCacheCapacity, *t.DenialTTL)
+	}
+	if p := t.Prefetch; p != nil {
+		fmt.Fprintf(sb, "        prefetch %d", p.Amount)
+		if p.Duration != "" || p.Percentage != nil {
+			duration := p.Duration
+			if duration == "" {
+				// The percentage is only read after a duration
+				duration = "1m"
+			}
+			fmt.Fprintf(sb, " %s", duration)
+		}
+		if p.Percentage != nil {
+			fmt.Fprintf(sb, " %d%%", *p.Percentage)
+		}
+		sb.WriteString("\n")
+	}
+	if st := t.ServeStale; st != nil {
+		sb.WriteString("        serve_stale")
+		if st.Duration != "" || st.RefreshMode != "" {
+			duration := st.Duration
+			if duration == "" {
+				// The refresh mode is only read after a duration
+				duration = "1h"
+			}
+			fmt.Fprintf(sb, " %s", duration)
+		}
+		if st.RefreshMode != "" {
+			fmt.Fprintf(sb, " %s", st.RefreshMode)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("    }\n")
+}
+
 // writeHealthBlock writes the health plugin directive. A nil config or
 // Enabled=false omits the directive entirely. The lameduck directive is
 // emitted inside a block when set; otherwise the directive is a single line.
@@ -387,7 +851,7 @@ func writeHostsBlock(sb *strings.Builder, hosts *HostsPluginConfig) {
 // which exactly matches the pre-feature output.
 func writeHealthBlock(sb *strings.Builder, h *HealthPluginConfig) {
 	enabled := true
-	port := defaultHealthPort
+	port := DefaultHealthPort
 	lameduck := ""
 	if h != nil {
 		enabled = h.Enabled
@@ -413,7 +877,7 @@ func writeHealthBlock(sb *strings.Builder, h *HealthPluginConfig) {
 // the pre-feature default.
 func writeReadyBlock(sb *strings.Builder, r *ReadyPluginConfig) {
 	enabled := true
-	port := defaultReadyPort
+	port := DefaultReadyPort
 	if r != nil {
 		enabled = r.Enabled
 		if r.Port != 0 {
@@ -451,6 +915,28 @@ func writeErrorsBlock(sb *strings.Builder, e *ErrorsPluginConfig) {
 	sb.WriteString("    }\n")
 }
 
+// ValidateMetricsConfig checks that the prometheus listen address is an IP
+// and that every excluded domain names one of the domain overrides.
+func ValidateMetricsConfig(address string, excludeDomains []string, overrides []DomainOverrideConfig) error {
+	var errs []string
+	if address != "" && net.ParseIP(address) == nil {
+		errs = append(errs, fmt.Sprintf("invalid metrics address %q: must be an IP address", address))
+	}
+	known := make(map[string]bool, len(overrides))
+	for _, o := range overrides {
+		known[o.Domain] = true
+	}
+	for _, d := range excludeDomains {
+		if !known[d] {
+			errs = append(errs, fmt.Sprintf("metrics excludeDomains entry %q does not match any domain override", d))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("metrics validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // ValidatePluginConfig checks that configured plugin ports are distinct,
 // within the 1-65535 TCP range, and that durations parse cleanly. Pass
 // metricsPort=0 to mean "use the 9153 default".
@@ -461,15 +947,15 @@ func writeErrorsBlock(sb *strings.Builder, e *ErrorsPluginConfig) {
 func ValidatePluginConfig(health *HealthPluginConfig, ready *ReadyPluginConfig, errors *ErrorsPluginConfig, metricsPort int32) error {
 	var errs []string
 
-	healthPort := defaultHealthPort
+	healthPort := DefaultHealthPort
 	if health != nil && health.Port != 0 {
 		healthPort = health.Port
 	}
-	readyPort := defaultReadyPort
+	readyPort := DefaultReadyPort
 	if ready != nil && ready.Port != 0 {
 		readyPort = ready.Port
 	}
-	mPort := defaultMetricsPort
+	mPort := DefaultMetricsPort
 	if metricsPort != 0 {
 		mPort = metricsPort
 	}
@@ -541,6 +1027,16 @@ func buildDoTSNIHost(profileID, deviceName string) string {
 	return profileID
 }
 
+// DeviceDoTHostname returns the DoT hostname identifying deviceName on a profile
+func DeviceDoTHostname(profileID, deviceName string) string {
+	return buildDoTSNIHost(profileID, deviceName) + "." + nextDNSDoTServer
+}
+
+// DeviceDoHURL returns the DoH URL identifying deviceName on a profile
+func DeviceDoHURL(profileID, deviceName string) string {
+	return fmt.Sprintf("https://%s/%s", nextDNSDoHServer, buildDoHPath(profileID, deviceName))
+}
+
 // buildDoHPath returns the URL path segment for DoH, with optional device name suffix.
 func buildDoHPath(profileID, deviceName string) string {
 	if deviceName != "" {
@@ -575,39 +1071,59 @@ func writeForwardTuning(sb *strings.Builder, t *ForwardTuningConfig) {
 // writeForwardPlugin writes the forward plugin configuration to the string builder.
 // Note: Cross-protocol fallback (e.g., DoT→DoH) is not supported because CoreDNS's
 // forward plugin cannot mix tls:// and https:// upstreams with a single tls_servername.
+// A fallback profile is listed last as a plain DNS relay to its own server
+// block, which the sequential policy only uses once the primary upstreams
+// are marked down.
 func writeForwardPlugin(sb *strings.Builder, cfg *CorefileConfig) {
-	ip1, ip2 := resolveUpstreamIPs(cfg.UpstreamIPv4)
+	upstreams, serverName := forwardUpstreams(cfg.PrimaryProtocol, cfg.ProfileID, cfg.DeviceName,
+		UpstreamAddresses(cfg.UpstreamIPv4, cfg.UpstreamIPv6))
+	tuning := cfg.ForwardTuning
+	if cfg.Fallback != nil {
+		upstreams = append(upstreams, FallbackRelay())
+		tuning = sequentialTuning(tuning)
+	}
+	writeForward(sb, upstreams, serverName, tuning)
+}
 
-	switch cfg.PrimaryProtocol {
+// forwardUpstreams returns the forward plugin upstreams of a profile and the
+// TLS server name they need, which is empty for DoH and plain DNS
+func forwardUpstreams(protocol, profileID, deviceName string, addrs []string) ([]string, string) {
+	switch protocol {
 	case ProtocolDoT:
 		// DoT uses IPs with TLS and tls_servername for SNI
 		// The profile ID is embedded in the SNI hostname for NextDNS routing
-		fmt.Fprintf(sb, "    forward . tls://%s tls://%s {\n", ip1, ip2)
-		fmt.Fprintf(sb, "        tls_servername %s.%s\n", buildDoTSNIHost(cfg.ProfileID, cfg.DeviceName), nextDNSDoTServer)
-		writeForwardTuning(sb, cfg.ForwardTuning)
-		sb.WriteString("    }\n")
-
+		upstreams := make([]string, len(addrs))
+		for i, addr := range addrs {
+			upstreams[i] = "tls://" + addr
+		}
+		return upstreams, buildDoTSNIHost(profileID, deviceName) + "." + nextDNSDoTServer
 	case ProtocolDoH:
 		// DoH uses https:// URL directly
-		upstream := fmt.Sprintf("https://%s/%s", nextDNSDoHServer, buildDoHPath(cfg.ProfileID, cfg.DeviceName))
-		if cfg.ForwardTuning != nil {
-			fmt.Fprintf(sb, "    forward . %s {\n", upstream)
-			writeForwardTuning(sb, cfg.ForwardTuning)
-			sb.WriteString("    }\n")
-		} else {
-			fmt.Fprintf(sb, "    forward . %s\n", upstream)
-		}
-
+		return []string{DeviceDoHURL(profileID, deviceName)}, ""
 	case ProtocolDNS:
 		// Plain DNS uses upstream IPs
-		if cfg.ForwardTuning != nil {
-			fmt.Fprintf(sb, "    forward . %s %s {\n", ip1, ip2)
-			writeForwardTuning(sb, cfg.ForwardTuning)
-			sb.WriteString("    }\n")
-		} else {
-			fmt.Fprintf(sb, "    forward . %s %s\n", ip1, ip2)
-		}
+		return addrs, ""
+	default:
+		return nil, ""
+	}
+}
+
+// writeForward writes a forward directive, as a block when it needs a TLS
+// server name or tuning options
+func writeForward(sb *strings.Builder, upstreams []string, serverName string, tuning *ForwardTuningConfig) {
+	if len(upstreams) == 0 {
+		return
 	}
+	if serverName == "" && tuning == nil {
+		fmt.Fprintf(sb, "    forward . %s\n", strings.Join(upstreams, " "))
+		return
+	}
+	fmt.Fprintf(sb, "    forward . %s {\n", strings.Join(upstreams, " "))
+	if serverName != "" {
+		fmt.Fprintf(sb, "        tls_servername %s\n", serverName)
+	}
+	writeForwardTuning(sb, tuning)
+	sb.WriteString("    }\n")
 }
 
 // resolveUpstreamIPs returns two upstream IPs. Uses profile-specific IPs if
@@ -619,18 +1135,39 @@ func resolveUpstreamIPs(profileIPs []string) (string, string) {
 	return nextDNSAnycastIP1, nextDNSAnycastIP2
 }
 
+// UpstreamAddresses returns the IPs the forward plugin sends DoT and plain DNS
+// queries to: two IPv4 addresses, profile-specific when available and the
+// anycast IPs otherwise, followed by up to two profile-specific IPv6 addresses.
+func UpstreamAddresses(profileIPv4, profileIPv6 []string) []string {
+	ip1, ip2 := resolveUpstreamIPs(profileIPv4)
+	addrs := []string{ip1, ip2}
+	if len(profileIPv6) > 2 {
+		profileIPv6 = profileIPv6[:2]
+	}
+	return append(addrs, profileIPv6...)
+}
+
+// joinUpstreams prefixes each address with scheme and joins them with sep
+func joinUpstreams(scheme, sep string, addrs []string) string {
+	upstreams := make([]string, len(addrs))
+	for i, addr := range addrs {
+		upstreams[i] = scheme + addr
+	}
+	return strings.Join(upstreams, sep)
+}
+
 // GetUpstreamEndpoint returns a human-readable endpoint string for the given
 // protocol, suitable for use in status reporting.
-func GetUpstreamEndpoint(profileID, protocol, deviceName string, upstreamIPv4 []string) string {
-	ip1, ip2 := resolveUpstreamIPs(upstreamIPv4)
+func GetUpstreamEndpoint(profileID, protocol, deviceName string, upstreamIPv4, upstreamIPv6 []string) string {
+	addrs := UpstreamAddresses(upstreamIPv4, upstreamIPv6)
 
 	switch protocol {
 	case ProtocolDoT:
-		return fmt.Sprintf("tls://%s, tls://%s (SNI: %s.%s)", ip1, ip2, buildDoTSNIHost(profileID, deviceName), nextDNSDoTServer)
+		return fmt.Sprintf("%s (SNI: %s.%s)", joinUpstreams("tls://", ", ", addrs), buildDoTSNIHost(profileID, deviceName), nextDNSDoTServer)
 	case ProtocolDoH:
 		return fmt.Sprintf("https://%s/%s", nextDNSDoHServer, buildDoHPath(profileID, deviceName))
 	case ProtocolDNS:
-		return fmt.Sprintf("%s, %s", ip1, ip2)
+		return strings.Join(addrs, ", ")
 	default:
 		return ""
 	}
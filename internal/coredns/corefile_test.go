@@ -1,12 +1,17 @@
 package coredns
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jacaudi/nextdns-operator/internal/perfbudget"
 )
 
 func TestGenerateCorefile_DoTPrimary(t *testing.T) {
@@ -91,6 +96,65 @@ func TestGenerateCorefile_DoHPrimary(t *testing.T) {
 	assert.Contains(t, corefile, "errors")
 }
 
+func TestGenerateCorefile_LogRedactClient(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		LoggingEnabled:  true,
+		LogRedactClient: true,
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	assert.Contains(t, corefile, `    log . "- - {>id} \"{type} {class} {name} {proto} {size} {>do} {>bufsize}\" {rcode} {>rflags} {rsize} {duration}"`+"\n")
+	assert.NotContains(t, corefile, "{remote}")
+
+	// Redaction alone does not enable logging
+	cfg.LoggingEnabled = false
+	assert.NotContains(t, GenerateCorefile(cfg), "log .")
+}
+
+func TestGenerateCorefile_LogRedactClient_EveryBlock(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		LoggingEnabled:  true,
+		LogRedactClient: true,
+		DomainOverrides: []DomainOverrideConfig{
+			{Domain: "corp.example.com", Upstreams: []string{"10.0.0.1"}},
+		},
+		LocalZone: &LocalZoneConfig{Zone: "home.lan", AllowedCIDRs: []string{"192.168.1.53/32"}},
+		DoH: &TLSListenerConfig{
+			CertFile: TLSMountPath + "/doh/tls.crt",
+			KeyFile:  TLSMountPath + "/doh/tls.key",
+		},
+		DoT: &TLSListenerConfig{
+			CertFile: TLSMountPath + "/dot/tls.crt",
+			KeyFile:  TLSMountPath + "/dot/tls.key",
+		},
+	}
+	redacted := `    log . "- - {>id} \"{type} {class} {name} {proto} {size} {>do} {>bufsize}\" {rcode} {>rflags} {rsize} {duration}"` + "\n"
+
+	corefile := GenerateCorefile(cfg)
+
+	// The override, local zone, catch-all, DoH and DoT blocks each log with
+	// the redacted format, and none with the default one
+	blocks := strings.Split(corefile, "\n}")
+	require.Len(t, blocks, 6)
+	for _, block := range blocks[:5] {
+		assert.Contains(t, block, redacted)
+	}
+	assert.Equal(t, 5, strings.Count(corefile, redacted))
+	assert.NotContains(t, corefile, "    log\n")
+	assert.NotContains(t, corefile, "{remote}")
+
+	// Without redaction every block logs with the default format
+	cfg.LogRedactClient = false
+	assert.Equal(t, 5, strings.Count(GenerateCorefile(cfg), "    log\n"))
+}
+
 func TestGenerateCorefile_DNSPrimary(t *testing.T) {
 	cfg := &CorefileConfig{
 		ProfileID:       "ghi789",
@@ -174,22 +238,22 @@ func TestGenerateCorefile_ZeroCacheTTL(t *testing.T) {
 }
 
 func TestGetUpstreamEndpoint_DoT(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoT, "", nil)
+	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoT, "", nil, nil)
 	assert.Equal(t, "tls://45.90.28.0, tls://45.90.30.0 (SNI: abc123.dns.nextdns.io)", endpoint)
 }
 
 func TestGetUpstreamEndpoint_DoH(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("def456", ProtocolDoH, "", nil)
+	endpoint := GetUpstreamEndpoint("def456", ProtocolDoH, "", nil, nil)
 	assert.Equal(t, "https://dns.nextdns.io/def456", endpoint)
 }
 
 func TestGetUpstreamEndpoint_DNS(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("ghi789", ProtocolDNS, "", nil)
+	endpoint := GetUpstreamEndpoint("ghi789", ProtocolDNS, "", nil, nil)
 	assert.Equal(t, "45.90.28.0, 45.90.30.0", endpoint)
 }
 
 func TestGetUpstreamEndpoint_UnknownProtocol(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("xyz", "UNKNOWN", "", nil)
+	endpoint := GetUpstreamEndpoint("xyz", "UNKNOWN", "", nil, nil)
 	// Should return empty string or some default for unknown protocols
 	assert.Empty(t, endpoint)
 }
@@ -362,17 +426,17 @@ func TestGenerateCorefile_DNSWithDeviceName(t *testing.T) {
 }
 
 func TestGetUpstreamEndpoint_DoTWithDeviceName(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoT, "Home Router", nil)
+	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoT, "Home Router", nil, nil)
 	assert.Contains(t, endpoint, "Home--Router-abc123.dns.nextdns.io")
 }
 
 func TestGetUpstreamEndpoint_DoHWithDeviceName(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoH, "Home Router", nil)
+	endpoint := GetUpstreamEndpoint("abc123", ProtocolDoH, "Home Router", nil, nil)
 	assert.Contains(t, endpoint, "/abc123/Home%20Router")
 }
 
 func TestGetUpstreamEndpoint_DNSWithDeviceName(t *testing.T) {
-	endpoint := GetUpstreamEndpoint("abc123", ProtocolDNS, "Home Router", nil)
+	endpoint := GetUpstreamEndpoint("abc123", ProtocolDNS, "Home Router", nil, nil)
 	// Plain DNS ignores device name
 	assert.NotContains(t, endpoint, "Home")
 	assert.Equal(t, "45.90.28.0, 45.90.30.0", endpoint)
@@ -417,6 +481,11 @@ func TestBuildDoHPath(t *testing.T) {
 	}
 }
 
+func TestDeviceEndpoints(t *testing.T) {
+	assert.Equal(t, "Home--Router-abc123.dns.nextdns.io", DeviceDoTHostname("abc123", "Home Router"))
+	assert.Equal(t, "https://dns.nextdns.io/abc123/Home%20Router", DeviceDoHURL("abc123", "Home Router"))
+}
+
 func TestGenerateCorefile_DoT_ProfileSpecificIPs(t *testing.T) {
 	cfg := &CorefileConfig{
 		ProfileID:       "abc123",
@@ -458,11 +527,43 @@ func TestGenerateCorefile_DoT_FallbackToAnycast(t *testing.T) {
 }
 
 func TestGetUpstreamEndpoint_ProfileSpecificIPs(t *testing.T) {
-	result := GetUpstreamEndpoint("abc123", ProtocolDoT, "", []string{"45.90.28.198", "45.90.30.198"})
+	result := GetUpstreamEndpoint("abc123", ProtocolDoT, "", []string{"45.90.28.198", "45.90.30.198"}, nil)
 	assert.Contains(t, result, "45.90.28.198")
 	assert.NotContains(t, result, "45.90.28.0")
 }
 
+func TestGenerateCorefile_ProfileSpecificIPv6(t *testing.T) {
+	ipv4 := []string{"45.90.28.198", "45.90.30.198"}
+	ipv6 := []string{"2a07:a8c0::ab", "2a07:a8c1::ab", "2a07:a8c2::ab"}
+
+	dot := GenerateCorefile(&CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		UpstreamIPv4:    ipv4,
+		UpstreamIPv6:    ipv6,
+	})
+	assert.Contains(t, dot, "forward . tls://45.90.28.198 tls://45.90.30.198 tls://2a07:a8c0::ab tls://2a07:a8c1::ab {")
+	assert.NotContains(t, dot, "2a07:a8c2::ab", "at most two IPv6 upstreams")
+
+	dns := GenerateCorefile(&CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDNS,
+		UpstreamIPv4:    ipv4,
+		UpstreamIPv6:    ipv6[:1],
+	})
+	assert.Contains(t, dns, "forward . 45.90.28.198 45.90.30.198 2a07:a8c0::ab\n")
+}
+
+func TestUpstreamAddresses(t *testing.T) {
+	// IPv6 is appended even when IPv4 falls back to anycast
+	assert.Equal(t,
+		[]string{"45.90.28.0", "45.90.30.0", "2a07:a8c0::ab"},
+		UpstreamAddresses(nil, []string{"2a07:a8c0::ab"}))
+
+	endpoint := GetUpstreamEndpoint("abc123", ProtocolDNS, "", []string{"45.90.28.198", "45.90.30.198"}, []string{"2a07:a8c0::ab"})
+	assert.Equal(t, "45.90.28.198, 45.90.30.198, 2a07:a8c0::ab", endpoint)
+}
+
 func TestGenerateCorefile_WithRewriteRules(t *testing.T) {
 	cfg := &CorefileConfig{
 		ProfileID:       "abc123",
@@ -654,6 +755,99 @@ func TestValidateForwardTuning(t *testing.T) {
 	}
 }
 
+func TestGenerateCorefile_WithCacheTuning(t *testing.T) {
+	denialTTL := int32(30)
+	percentage := int32(20)
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		MetricsEnabled:  true,
+		CacheTuning: &CacheTuningConfig{
+			DenialTTL:  &denialTTL,
+			Prefetch:   &CachePrefetchConfig{Amount: 10, Percentage: &percentage},
+			ServeStale: &CacheServeStaleConfig{RefreshMode: "verify"},
+		},
+	}
+
+	out := GenerateCorefile(cfg)
+	want := "    cache 3600 {\n" +
+		"        denial 9984 30\n" +
+		"        prefetch 10 1m 20%\n" +
+		"        serve_stale 1h verify\n" +
+		"    }\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("expected cache block %q; got:\n%s", want, out)
+	}
+
+	cfg.CacheTuning = &CacheTuningConfig{
+		Prefetch:   &CachePrefetchConfig{Amount: 5},
+		ServeStale: &CacheServeStaleConfig{},
+	}
+	out = GenerateCorefile(cfg)
+	want = "    cache 3600 {\n" +
+		"        prefetch 5\n" +
+		"        serve_stale\n" +
+		"    }\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("expected cache block %q; got:\n%s", want, out)
+	}
+
+	// An empty tuning config keeps the single-line directive
+	cfg.CacheTuning = &CacheTuningConfig{}
+	if out = GenerateCorefile(cfg); !strings.Contains(out, "    cache 3600\n") {
+		t.Errorf("expected single-line cache directive; got:\n%s", out)
+	}
+}
+
+func TestValidateCacheTuning(t *testing.T) {
+	int32p := func(v int32) *int32 { return &v }
+	tests := []struct {
+		name    string
+		t       *CacheTuningConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"empty", &CacheTuningConfig{}, false},
+		{"valid", &CacheTuningConfig{
+			DenialTTL:  int32p(0),
+			Prefetch:   &CachePrefetchConfig{Amount: 2, Duration: "10m", Percentage: int32p(50)},
+			ServeStale: &CacheServeStaleConfig{Duration: "30m", RefreshMode: "immediate"},
+		}, false},
+		{"negative denialTTL", &CacheTuningConfig{DenialTTL: int32p(-1)}, true},
+		{"prefetch amount zero", &CacheTuningConfig{Prefetch: &CachePrefetchConfig{}}, true},
+		{"bad prefetch duration", &CacheTuningConfig{Prefetch: &CachePrefetchConfig{Amount: 1, Duration: "soon"}}, true},
+		{"prefetch percentage above 100", &CacheTuningConfig{Prefetch: &CachePrefetchConfig{Amount: 1, Percentage: int32p(101)}}, true},
+		{"bad serveStale duration", &CacheTuningConfig{ServeStale: &CacheServeStaleConfig{Duration: "1d"}}, true},
+		{"bad refreshMode", &CacheTuningConfig{ServeStale: &CacheServeStaleConfig{RefreshMode: "lazy"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCacheTuning(tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateProtocol(t *testing.T) {
+	for _, protocol := range []string{ProtocolDoT, ProtocolDoH, ProtocolDNS} {
+		if err := ValidateProtocol(protocol); err != nil {
+			t.Errorf("ValidateProtocol(%q) = %v, want nil", protocol, err)
+		}
+	}
+
+	if err := ValidateProtocol(ProtocolDoQ); !errors.Is(err, ErrUnsupportedProtocol) {
+		t.Errorf("ValidateProtocol(DoQ) = %v, want ErrUnsupportedProtocol", err)
+	}
+
+	err := ValidateProtocol("DoX")
+	if err == nil || errors.Is(err, ErrUnsupportedProtocol) {
+		t.Errorf("ValidateProtocol(DoX) = %v, want unknown protocol error", err)
+	}
+}
+
 func TestGenerateCorefile_WithHostsBlock(t *testing.T) {
 	cfg := &CorefileConfig{
 		ProfileID:       "abc123",
@@ -720,6 +914,110 @@ func TestValidateHostsEntries(t *testing.T) {
 	}
 }
 
+func TestValidateBootstrapServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"IPv4 and IPv6", []string{"45.90.28.0", "2a07:a8c0::"}, false},
+		{"hostname", []string{"dns.nextdns.io"}, true},
+		{"empty", []string{""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBootstrapServers(tt.servers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateCorefile_WithLocalRecords(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		LocalRecords: []LocalRecordConfig{
+			{Name: "NAS.home.lan", Type: RecordTypeA, Value: "192.168.1.10", TTL: 300},
+			{Name: "nas.home.lan.", Type: RecordTypeA, Value: "192.168.1.11", TTL: 300},
+			{Name: "nas.home.lan", Type: RecordTypeAAAA, Value: "fd00::10", TTL: 3600},
+			{Name: "files.home.lan", Type: RecordTypeCNAME, Value: "nas.home.lan", TTL: 60},
+		},
+	}
+
+	out := GenerateCorefile(cfg)
+
+	want := `    template IN A nas.home.lan. {
+        match "(?i)^nas\.home\.lan\.$"
+        answer "{{ .Name }} 300 IN A 192.168.1.10"
+        answer "{{ .Name }} 300 IN A 192.168.1.11"
+        fallthrough
+    }
+    template IN AAAA nas.home.lan. {
+        match "(?i)^nas\.home\.lan\.$"
+        answer "{{ .Name }} 3600 IN AAAA fd00::10"
+        fallthrough
+    }
+    template IN ANY files.home.lan. {
+        match "(?i)^files\.home\.lan\.$"
+        answer "{{ .Name }} 60 IN CNAME nas.home.lan."
+        fallthrough
+    }
+`
+	if !strings.Contains(out, want) {
+		t.Errorf("expected local record template blocks; got:\n%s", out)
+	}
+
+	// Template blocks must precede forward
+	templateIdx := strings.Index(out, "template IN")
+	forwardIdx := strings.Index(out, "forward .")
+	if templateIdx == -1 || forwardIdx == -1 || templateIdx > forwardIdx {
+		t.Errorf("template blocks must precede forward; templateIdx=%d forwardIdx=%d", templateIdx, forwardIdx)
+	}
+}
+
+func TestValidateLocalRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []LocalRecordConfig
+		wantErr bool
+	}{
+		{"valid A", []LocalRecordConfig{{Name: "nas.home.lan", Type: RecordTypeA, Value: "192.168.1.10"}}, false},
+		{"valid AAAA", []LocalRecordConfig{{Name: "nas.home.lan", Type: RecordTypeAAAA, Value: "fd00::10"}}, false},
+		{"valid CNAME", []LocalRecordConfig{{Name: "files.home.lan.", Type: RecordTypeCNAME, Value: "nas.home.lan."}}, false},
+		{"A and AAAA share a name", []LocalRecordConfig{
+			{Name: "nas.home.lan", Type: RecordTypeA, Value: "192.168.1.10"},
+			{Name: "nas.home.lan", Type: RecordTypeAAAA, Value: "fd00::10"},
+		}, false},
+		{"invalid name", []LocalRecordConfig{{Name: "nas home", Type: RecordTypeA, Value: "192.168.1.10"}}, true},
+		{"empty name", []LocalRecordConfig{{Type: RecordTypeA, Value: "192.168.1.10"}}, true},
+		{"A with IPv6", []LocalRecordConfig{{Name: "nas.home.lan", Type: RecordTypeA, Value: "fd00::10"}}, true},
+		{"AAAA with IPv4", []LocalRecordConfig{{Name: "nas.home.lan", Type: RecordTypeAAAA, Value: "192.168.1.10"}}, true},
+		{"invalid CNAME target", []LocalRecordConfig{{Name: "files.home.lan", Type: RecordTypeCNAME, Value: "nas home"}}, true},
+		{"unknown type", []LocalRecordConfig{{Name: "nas.home.lan", Type: "MX", Value: "mail.home.lan"}}, true},
+		{"negative ttl", []LocalRecordConfig{{Name: "nas.home.lan", Type: RecordTypeA, Value: "192.168.1.10", TTL: -1}}, true},
+		{"CNAME with other records", []LocalRecordConfig{
+			{Name: "files.home.lan", Type: RecordTypeCNAME, Value: "nas.home.lan"},
+			{Name: "FILES.home.lan", Type: RecordTypeA, Value: "192.168.1.10"},
+		}, true},
+		{"two CNAMEs", []LocalRecordConfig{
+			{Name: "files.home.lan", Type: RecordTypeCNAME, Value: "nas.home.lan"},
+			{Name: "files.home.lan", Type: RecordTypeCNAME, Value: "backup.home.lan"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLocalRecords(tt.records)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestGenerateCorefile_DefaultPlugins_Unchanged is a regression guard:
 // when Health/Ready/Errors/Metrics configs are nil (or zero-value),
 // the generated Corefile MUST match today's hardcoded output exactly.
@@ -921,3 +1219,154 @@ func TestGenerateCorefile_ValidCorefileSyntax(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCorefile_MetricsAddress(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		MetricsEnabled:  true,
+		MetricsPort:     9253,
+		MetricsAddress:  "127.0.0.1",
+	}
+	assert.Contains(t, GenerateCorefile(cfg), "    prometheus 127.0.0.1:9253\n")
+
+	cfg.MetricsAddress = "fd00::10"
+	assert.Contains(t, GenerateCorefile(cfg), "    prometheus [fd00::10]:9253\n")
+}
+
+func TestGenerateCorefile_DomainOverrideMetrics(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		MetricsEnabled:  true,
+		DomainOverrides: []DomainOverrideConfig{
+			{Domain: "corp.example.com", Upstreams: []string{"10.0.0.1"}, Metrics: true},
+			{Domain: "lab.example.com", Upstreams: []string{"10.0.0.2"}},
+		},
+	}
+
+	corefile := GenerateCorefile(cfg)
+	assert.Contains(t, corefile, "corp.example.com {\n    forward . 10.0.0.1\n    cache 30\n    prometheus :9153\n    errors\n}")
+	assert.Contains(t, corefile, "lab.example.com {\n    forward . 10.0.0.2\n    cache 30\n    errors\n}")
+	assert.Equal(t, 2, strings.Count(corefile, "prometheus :9153"))
+
+	// Per-block metrics require metrics to be enabled globally
+	cfg.MetricsEnabled = false
+	assert.NotContains(t, GenerateCorefile(cfg), "prometheus")
+}
+
+func TestValidateMetricsConfig(t *testing.T) {
+	overrides := []DomainOverrideConfig{{Domain: "corp.example.com", Upstreams: []string{"10.0.0.1"}}}
+
+	assert.NoError(t, ValidateMetricsConfig("", nil, nil))
+	assert.NoError(t, ValidateMetricsConfig("0.0.0.0", []string{"corp.example.com"}, overrides))
+
+	err := ValidateMetricsConfig("localhost", []string{"typo.example.com"}, overrides)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid metrics address "localhost"`)
+	assert.Contains(t, err.Error(), `"typo.example.com" does not match any domain override`)
+}
+
+func TestGenerateCorefile_TLSListeners(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+	}
+	assert.NotContains(t, GenerateCorefile(cfg), "https://.")
+	assert.NotContains(t, GenerateCorefile(cfg), "tls://.")
+
+	cfg.DoH = &TLSListenerConfig{
+		CertFile: TLSMountPath + "/doh/tls.crt",
+		KeyFile:  TLSMountPath + "/doh/tls.key",
+	}
+	corefile := GenerateCorefile(cfg)
+	assert.True(t, strings.HasSuffix(corefile, "}\n\nhttps://.:443 {\n    tls /etc/coredns-tls/doh/tls.crt /etc/coredns-tls/doh/tls.key\n    forward . 127.0.0.1:53\n    errors\n}"),
+		"the DoH block relays to the plain DNS listener, got:\n%s", corefile)
+	assert.Equal(t, 1, strings.Count(corefile, "cache 3600"), "the DoH block relies on the catch-all block for caching")
+
+	cfg.DoH.Port = 8443
+	assert.Contains(t, GenerateCorefile(cfg), "https://.:8443 {\n")
+
+	cfg.DoT = &TLSListenerConfig{
+		CertFile: TLSMountPath + "/dot/tls.crt",
+		KeyFile:  TLSMountPath + "/dot/tls.key",
+	}
+	corefile = GenerateCorefile(cfg)
+	assert.True(t, strings.HasSuffix(corefile, "}\n\ntls://.:853 {\n    tls /etc/coredns-tls/dot/tls.crt /etc/coredns-tls/dot/tls.key\n    forward . 127.0.0.1:53\n    errors\n}"),
+		"the DoT block follows the DoH block, got:\n%s", corefile)
+}
+
+func TestGenerateCorefile_BindAddresses(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		DomainOverrides: []DomainOverrideConfig{
+			{Domain: "home.lan", Upstreams: []string{"192.168.1.1"}},
+		},
+		DoT: &TLSListenerConfig{
+			CertFile: TLSMountPath + "/dot/tls.crt",
+			KeyFile:  TLSMountPath + "/dot/tls.key",
+		},
+	}
+	assert.NotContains(t, GenerateCorefile(cfg), "bind ")
+
+	cfg.BindAddresses = []string{"169.254.20.10", "{$NODE_IP}"}
+	corefile := GenerateCorefile(cfg)
+	assert.Contains(t, corefile, "home.lan {\n    bind 169.254.20.10 {$NODE_IP}\n    forward . 192.168.1.1\n")
+	assert.Contains(t, corefile, ". {\n    bind 169.254.20.10 {$NODE_IP}\n    forward . tls://")
+	assert.True(t, strings.HasSuffix(corefile, "tls://.:853 {\n    bind 169.254.20.10 {$NODE_IP}\n    tls /etc/coredns-tls/dot/tls.crt /etc/coredns-tls/dot/tls.key\n    forward . 169.254.20.10:53\n    errors\n}"),
+		"the DoT block relays to the first bound address, got:\n%s", corefile)
+}
+
+// largeCorefileConfig returns a configuration with n hosts entries and n/10
+// local A records with reverse records
+func largeCorefileConfig(n int) *CorefileConfig {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		MetricsEnabled:  true,
+		Hosts:           &HostsPluginConfig{Fallthrough: true},
+		ReverseRecords:  true,
+	}
+	for i := range n {
+		cfg.Hosts.Entries = append(cfg.Hosts.Entries, HostsEntryConfig{
+			IP:        "0.0.0.0",
+			Hostnames: []string{fmt.Sprintf("host-%d.example.com", i)},
+		})
+	}
+	for i := range n / 10 {
+		cfg.LocalRecords = append(cfg.LocalRecords, LocalRecordConfig{
+			Name:  fmt.Sprintf("svc-%d.home.lan", i),
+			Type:  RecordTypeA,
+			Value: fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255),
+			TTL:   300,
+		})
+	}
+	return cfg
+}
+
+func BenchmarkGenerateCorefile(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		cfg := largeCorefileConfig(n)
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				GenerateCorefile(cfg)
+			}
+		})
+	}
+}
+
+func TestGenerateCorefile_PerformanceBudget(t *testing.T) {
+	cfg := largeCorefileConfig(100_000)
+	require.Contains(t, GenerateCorefile(cfg), "0.0.0.0 host-99999.example.com\n")
+
+	perfbudget.Enforce(t, perfbudget.Budget{Time: 300 * time.Millisecond, Allocs: 800_000}, func() {
+		GenerateCorefile(cfg)
+	})
+}
@@ -0,0 +1,86 @@
+package coredns
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// FallbackListenerPort is the loopback port of the server block forwarding
+// to the fallback profile
+const FallbackListenerPort int32 = 5301
+
+// fallbackListenerAddress is the address the fallback server block binds to
+const fallbackListenerAddress = "127.0.0.1"
+
+// FallbackUpstreamConfig is a second NextDNS profile the catch-all block
+// fails over to once every primary upstream fails its health check. It is
+// reached over the primary protocol and device name.
+type FallbackUpstreamConfig struct {
+	// ProfileID is the NextDNS profile ID of the fallback profile
+	ProfileID string
+
+	// UpstreamIPv4 and UpstreamIPv6 are the fallback profile's own
+	// addresses, chosen like the primary ones
+	UpstreamIPv4 []string
+	UpstreamIPv6 []string
+}
+
+// FallbackRelay returns the address the catch-all block forwards to when the
+// primary upstreams are down
+func FallbackRelay() string {
+	return net.JoinHostPort(fallbackListenerAddress, strconv.Itoa(int(FallbackListenerPort)))
+}
+
+// ValidateFallback checks that the fallback has a profile ID and that the
+// forward policy keeps it last. Any policy but sequential would send
+// queries to the fallback while the primary upstreams are healthy.
+func ValidateFallback(fallback *FallbackUpstreamConfig, tuning *ForwardTuningConfig) error {
+	if fallback == nil {
+		return nil
+	}
+	var errs []string
+	if fallback.ProfileID == "" {
+		errs = append(errs, "fallback profile has no profile ID")
+	}
+	if tuning != nil && tuning.Policy != "" && tuning.Policy != "sequential" {
+		errs = append(errs, fmt.Sprintf("forward policy %q would use the fallback while the primary is healthy; use sequential", tuning.Policy))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("fallback validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// sequentialTuning returns tuning with the sequential policy, so the
+// fallback relay listed last only receives queries once the primary
+// upstreams are marked down
+func sequentialTuning(tuning *ForwardTuningConfig) *ForwardTuningConfig {
+	sequential := ForwardTuningConfig{}
+	if tuning != nil {
+		sequential = *tuning
+	}
+	sequential.Policy = "sequential"
+	return &sequential
+}
+
+// writeFallbackBlock writes the loopback server block forwarding to the
+// fallback profile. It shares the metrics endpoint of the catch-all block,
+// so queries answered by the fallback show up under its server label.
+func writeFallbackBlock(sb *strings.Builder, cfg *CorefileConfig) {
+	fallback := cfg.Fallback
+	if fallback == nil {
+		return
+	}
+	fmt.Fprintf(sb, "\n\n.:%d {\n", FallbackListenerPort)
+	writeBindDirective(sb, []string{fallbackListenerAddress})
+	upstreams, serverName := forwardUpstreams(cfg.PrimaryProtocol, fallback.ProfileID, cfg.DeviceName,
+		UpstreamAddresses(fallback.UpstreamIPv4, fallback.UpstreamIPv6))
+	writeForward(sb, upstreams, serverName, cfg.ForwardTuning)
+	if cfg.MetricsEnabled {
+		writePrometheusDirective(sb, cfg)
+	}
+	sb.WriteString("    errors\n")
+	sb.WriteString("}")
+}
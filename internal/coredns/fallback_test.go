@@ -0,0 +1,72 @@
+package coredns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFallback(t *testing.T) {
+	assert.NoError(t, ValidateFallback(nil, &ForwardTuningConfig{Policy: "random"}))
+	assert.NoError(t, ValidateFallback(&FallbackUpstreamConfig{ProfileID: "def456"}, nil))
+	assert.NoError(t, ValidateFallback(&FallbackUpstreamConfig{ProfileID: "def456"}, &ForwardTuningConfig{Policy: "sequential"}))
+
+	err := ValidateFallback(&FallbackUpstreamConfig{}, &ForwardTuningConfig{Policy: "round_robin"})
+	assert.ErrorContains(t, err, "fallback profile has no profile ID")
+	assert.ErrorContains(t, err, `forward policy "round_robin"`)
+}
+
+func TestGenerateCorefile_FallbackDoT(t *testing.T) {
+	corefile := GenerateCorefile(&CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		DeviceName:      "home",
+		CacheTTL:        DefaultCacheTTL,
+		MetricsEnabled:  true,
+		ForwardTuning:   &ForwardTuningConfig{HealthCheck: "5s"},
+		Fallback: &FallbackUpstreamConfig{
+			ProfileID:    "def456",
+			UpstreamIPv4: []string{"45.90.28.10", "45.90.30.10"},
+		},
+	})
+
+	assert.Contains(t, corefile, "    forward . tls://45.90.28.0 tls://45.90.30.0 127.0.0.1:5301 {\n"+
+		"        tls_servername home-abc123.dns.nextdns.io\n"+
+		"        policy sequential\n"+
+		"        health_check 5s\n"+
+		"    }\n")
+	assert.Contains(t, corefile, "\n\n.:5301 {\n"+
+		"    bind 127.0.0.1\n"+
+		"    forward . tls://45.90.28.10 tls://45.90.30.10 {\n"+
+		"        tls_servername home-def456.dns.nextdns.io\n"+
+		"        health_check 5s\n"+
+		"    }\n"+
+		"    prometheus :9153\n"+
+		"    errors\n"+
+		"}")
+}
+
+func TestGenerateCorefile_FallbackDNS(t *testing.T) {
+	corefile := GenerateCorefile(&CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDNS,
+		CacheTTL:        DefaultCacheTTL,
+		Fallback:        &FallbackUpstreamConfig{ProfileID: "def456"},
+	})
+
+	assert.Contains(t, corefile, "    forward . 45.90.28.0 45.90.30.0 127.0.0.1:5301 {\n        policy sequential\n    }\n")
+	assert.Contains(t, corefile, ".:5301 {\n    bind 127.0.0.1\n    forward . 45.90.28.0 45.90.30.0\n    errors\n}")
+	assert.NotContains(t, corefile, "prometheus")
+}
+
+func TestGenerateCorefile_NoFallback(t *testing.T) {
+	corefile := GenerateCorefile(&CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoH,
+		CacheTTL:        DefaultCacheTTL,
+	})
+
+	assert.Contains(t, corefile, "    forward . https://dns.nextdns.io/abc123\n")
+	assert.False(t, strings.Contains(corefile, "5301"))
+}
@@ -0,0 +1,124 @@
+package coredns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// RateLimitConfig limits the queries clients may send to the catch-all
+// zone. Drops use the acl plugin; other limits use the ratelimit plugin,
+// which is not part of the default CoreDNS image.
+type RateLimitConfig struct {
+	// QueriesPerSecond is the rate of each client not matched by a rule.
+	// 0 leaves them unlimited.
+	QueriesPerSecond int32
+
+	// Rules set the rate of the clients in their sources; the first
+	// matching rule applies
+	Rules []RateLimitRuleConfig
+}
+
+// RateLimitRuleConfig is the rate limit of the clients in Sources
+type RateLimitRuleConfig struct {
+	// Sources are the CIDRs of the clients the rule applies to
+	Sources []string
+
+	// QueriesPerSecond is the rate of each client in Sources. 0 drops every
+	// query; nil exempts them from rate limiting.
+	QueriesPerSecond *int32
+}
+
+// NeedsPlugin reports whether the config limits some client to a non-zero
+// rate, which the ratelimit plugin enforces
+func (c *RateLimitConfig) NeedsPlugin() bool {
+	if c == nil {
+		return false
+	}
+	if c.QueriesPerSecond > 0 {
+		return true
+	}
+	for _, rule := range c.Rules {
+		if rule.QueriesPerSecond != nil && *rule.QueriesPerSecond > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRateLimit checks that every rule has valid source CIDRs and a
+// rate that is not negative. Returns an error describing all validation
+// failures.
+func ValidateRateLimit(cfg *RateLimitConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []string
+	if cfg.QueriesPerSecond < 0 {
+		errs = append(errs, fmt.Sprintf("negative queries per second %d", cfg.QueriesPerSecond))
+	}
+	for i, rule := range cfg.Rules {
+		if len(rule.Sources) == 0 {
+			errs = append(errs, fmt.Sprintf("rule %d has no sources", i))
+		}
+		for _, cidr := range rule.Sources {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				errs = append(errs, fmt.Sprintf("rule %d: invalid CIDR %q", i, cidr))
+			}
+		}
+		if rule.QueriesPerSecond != nil && *rule.QueriesPerSecond < 0 {
+			errs = append(errs, fmt.Sprintf("rule %d: negative queries per second %d", i, *rule.QueriesPerSecond))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rate limit validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// writeRateLimitViewBlocks writes one catch-all server block per rate limit
+// rule, selected with the view plugin by the client address. CoreDNS tries
+// the blocks in order and uses the catch-all block without a view for
+// clients no rule matches. A dropping rule's block only drops queries with
+// the acl plugin, so access control is left out; the others resolve like
+// the catch-all block, under their own limit. health and ready are
+// process-wide and stay in the catch-all block.
+func writeRateLimitViewBlocks(sb *strings.Builder, cfg *CorefileConfig) {
+	if cfg.RateLimit == nil {
+		return
+	}
+	for i, rule := range cfg.RateLimit.Rules {
+		sb.WriteString(". {\n")
+		writeBindDirective(sb, cfg.BindAddresses)
+		matches := make([]string, len(rule.Sources))
+		for j, cidr := range rule.Sources {
+			matches[j] = fmt.Sprintf("incidr(client_ip(), '%s')", cidr)
+		}
+		fmt.Fprintf(sb, "    view ratelimit-%d {\n", i)
+		fmt.Fprintf(sb, "        expr %s\n", strings.Join(matches, " || "))
+		sb.WriteString("    }\n")
+
+		if rule.QueriesPerSecond != nil && *rule.QueriesPerSecond == 0 {
+			sb.WriteString("    acl {\n")
+			sb.WriteString("        drop\n")
+			sb.WriteString("    }\n")
+		} else {
+			writeAccessControlBlock(sb, cfg.AccessControl, relaySources(cfg))
+			if rule.QueriesPerSecond != nil {
+				writeRateLimitDirective(sb, *rule.QueriesPerSecond)
+			}
+			writeResolverPlugins(sb, cfg)
+		}
+		writeReportingPlugins(sb, cfg)
+		sb.WriteString("}\n\n")
+	}
+}
+
+// writeRateLimitDirective writes the ratelimit plugin directive limiting
+// each client to queriesPerSecond, or nothing when it is 0
+func writeRateLimitDirective(sb *strings.Builder, queriesPerSecond int32) {
+	if queriesPerSecond <= 0 {
+		return
+	}
+	fmt.Fprintf(sb, "    ratelimit %d\n", queriesPerSecond)
+}
@@ -0,0 +1,124 @@
+package coredns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateRateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *RateLimitConfig
+		wantErr string
+	}{
+		{name: "nil", cfg: nil},
+		{name: "valid", cfg: &RateLimitConfig{QueriesPerSecond: 50, Rules: []RateLimitRuleConfig{
+			{Sources: []string{"10.0.0.0/8", "fd00::/8"}, QueriesPerSecond: ptr.To[int32](0)},
+			{Sources: []string{"192.168.1.0/24"}},
+		}}},
+		{name: "no sources", cfg: &RateLimitConfig{Rules: []RateLimitRuleConfig{{}}}, wantErr: "rule 0 has no sources"},
+		{name: "invalid CIDR", cfg: &RateLimitConfig{Rules: []RateLimitRuleConfig{{Sources: []string{"10.0.0.1"}}}}, wantErr: `rule 0: invalid CIDR "10.0.0.1"`},
+		{name: "negative rule rate", cfg: &RateLimitConfig{Rules: []RateLimitRuleConfig{{Sources: []string{"10.0.0.0/8"}, QueriesPerSecond: ptr.To[int32](-1)}}}, wantErr: "rule 0: negative queries per second -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRateLimit(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimitConfig_NeedsPlugin(t *testing.T) {
+	var cfg *RateLimitConfig
+	assert.False(t, cfg.NeedsPlugin())
+
+	cfg = &RateLimitConfig{Rules: []RateLimitRuleConfig{
+		{Sources: []string{"10.0.0.0/8"}, QueriesPerSecond: ptr.To[int32](0)},
+		{Sources: []string{"192.168.0.0/16"}},
+	}}
+	assert.False(t, cfg.NeedsPlugin(), "drops and exemptions need no ratelimit plugin")
+
+	cfg.Rules[1].QueriesPerSecond = ptr.To[int32](10)
+	assert.True(t, cfg.NeedsPlugin())
+
+	assert.True(t, (&RateLimitConfig{QueriesPerSecond: 50}).NeedsPlugin())
+}
+
+func TestGenerateCorefile_RateLimit(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		MetricsEnabled:  true,
+		RateLimit: &RateLimitConfig{
+			QueriesPerSecond: 50,
+			Rules: []RateLimitRuleConfig{
+				{Sources: []string{"10.42.7.0/24", "fd00:7::/64"}, QueriesPerSecond: ptr.To[int32](0)},
+				{Sources: []string{"192.168.1.0/24"}, QueriesPerSecond: ptr.To[int32](200)},
+				{Sources: []string{"192.168.2.0/24"}},
+			},
+		},
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	expected := `. {
+    view ratelimit-0 {
+        expr incidr(client_ip(), '10.42.7.0/24') || incidr(client_ip(), 'fd00:7::/64')
+    }
+    acl {
+        drop
+    }
+    prometheus :9153
+    errors
+}
+
+. {
+    view ratelimit-1 {
+        expr incidr(client_ip(), '192.168.1.0/24')
+    }
+    ratelimit 200
+    forward . tls://45.90.28.0 tls://45.90.30.0 {
+        tls_servername abc123.dns.nextdns.io
+    }
+    cache 3600
+    prometheus :9153
+    errors
+}
+
+. {
+    view ratelimit-2 {
+        expr incidr(client_ip(), '192.168.2.0/24')
+    }
+    forward . tls://45.90.28.0 tls://45.90.30.0 {
+        tls_servername abc123.dns.nextdns.io
+    }
+    cache 3600
+    prometheus :9153
+    errors
+}
+
+. {
+    ratelimit 50
+    forward . tls://45.90.28.0 tls://45.90.30.0 {
+        tls_servername abc123.dns.nextdns.io
+    }
+    cache 3600
+    health :8080
+    ready :8181
+    prometheus :9153
+    errors
+}`
+	assert.Equal(t, expected, corefile)
+}
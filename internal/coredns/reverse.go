@@ -0,0 +1,67 @@
+package coredns
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// reverseName returns the in-addr.arpa or ip6.arpa name of ip
+func reverseName(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", v4[3], v4[2], v4[1], v4[0])
+	}
+	const hexDigits = "0123456789abcdef"
+	v6 := ip.To16()
+	var sb strings.Builder
+	for i := len(v6) - 1; i >= 0; i-- {
+		sb.WriteByte(hexDigits[v6[i]&0x0f])
+		sb.WriteByte('.')
+		sb.WriteByte(hexDigits[v6[i]>>4])
+		sb.WriteByte('.')
+	}
+	sb.WriteString("ip6.arpa.")
+	return sb.String()
+}
+
+// writeReverseRecordBlocks writes a template plugin block answering PTR
+// queries for each private address of the A and AAAA local records, in the
+// order the addresses first appear. An address used by several names
+// answers all of them. Public addresses are skipped, since their reverse
+// zones belong to whoever owns the network. Reverse lookups for addresses
+// without a local record fall through to the forward plugin.
+func writeReverseRecordBlocks(sb *strings.Builder, records []LocalRecordConfig) {
+	var names []string
+	answers := map[string][]LocalRecordConfig{}
+	for _, r := range records {
+		if r.Type != RecordTypeA && r.Type != RecordTypeAAAA {
+			continue
+		}
+		ip := net.ParseIP(r.Value)
+		if ip == nil || !ip.IsPrivate() {
+			continue
+		}
+		name := reverseName(ip)
+		if _, ok := answers[name]; !ok {
+			names = append(names, name)
+		}
+		answers[name] = append(answers[name], r)
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(sb, "    template IN PTR %s {\n", name)
+		fmt.Fprintf(sb, "        match \"(?i)^%s$\"\n", regexp.QuoteMeta(name))
+		seen := map[string]bool{}
+		for _, r := range answers[name] {
+			target := fqdn(r.Name)
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			fmt.Fprintf(sb, "        answer \"{{ .Name }} %d IN PTR %s\"\n", r.TTL, target)
+		}
+		sb.WriteString("        fallthrough\n")
+		sb.WriteString("    }\n")
+	}
+}
@@ -0,0 +1,51 @@
+package coredns
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseName(t *testing.T) {
+	assert.Equal(t, "10.1.168.192.in-addr.arpa.", reverseName(net.ParseIP("192.168.1.10")))
+	assert.Equal(t,
+		"0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa.",
+		reverseName(net.ParseIP("fd00::10")))
+}
+
+func TestGenerateCorefile_WithReverseRecords(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		ReverseRecords:  true,
+		LocalRecords: []LocalRecordConfig{
+			{Name: "NAS.home.lan", Type: RecordTypeA, Value: "192.168.1.10", TTL: 300},
+			{Name: "files.home.lan", Type: RecordTypeA, Value: "192.168.1.10", TTL: 300},
+			{Name: "nas.home.lan.", Type: RecordTypeA, Value: "192.168.1.10", TTL: 300},
+			{Name: "nas.home.lan", Type: RecordTypeAAAA, Value: "fd00::10", TTL: 3600},
+			{Name: "www.home.lan", Type: RecordTypeA, Value: "203.0.113.7", TTL: 300},
+			{Name: "media.home.lan", Type: RecordTypeCNAME, Value: "nas.home.lan", TTL: 60},
+		},
+	}
+
+	out := GenerateCorefile(cfg)
+
+	want := `    template IN PTR 10.1.168.192.in-addr.arpa. {
+        match "(?i)^10\.1\.168\.192\.in-addr\.arpa\.$"
+        answer "{{ .Name }} 300 IN PTR nas.home.lan."
+        answer "{{ .Name }} 300 IN PTR files.home.lan."
+        fallthrough
+    }
+    template IN PTR 0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa. {
+`
+	assert.Contains(t, out, want)
+	assert.NotContains(t, out, "7.113.0.203.in-addr.arpa", "public addresses get no PTR")
+	assert.Equal(t, 2, strings.Count(out, "template IN PTR"))
+	assert.Less(t, strings.Index(out, "template IN PTR"), strings.Index(out, "forward ."))
+
+	cfg.ReverseRecords = false
+	assert.NotContains(t, GenerateCorefile(cfg), "template IN PTR")
+}
@@ -0,0 +1,56 @@
+package coredns
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSearchDomain is the Kubernetes cluster domain, the last search
+// domain of pods using cluster DNS
+const DefaultSearchDomain = "cluster.local"
+
+// SearchDomainNegativeTTL is the SOA minimum in seconds of the NXDOMAIN
+// answers for search domain names, so clients and the cache keep them
+const SearchDomainNegativeTTL = 60
+
+// ValidateSearchDomains checks that each search domain is a valid DNS name
+// and listed once. Returns an error describing all validation failures.
+func ValidateSearchDomains(domains []string) error {
+	var errs []string
+	seen := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if !validDNSName(d) {
+			errs = append(errs, fmt.Sprintf("invalid search domain %q", d))
+			continue
+		}
+		if seen[fqdn(d)] {
+			errs = append(errs, fmt.Sprintf("duplicate search domain %s", d))
+		}
+		seen[fqdn(d)] = true
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("search domain validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// writeSearchDomainBlock writes a template plugin block answering NXDOMAIN
+// for every name in the search domains. It follows the hosts entries and
+// local records, so names they serve still resolve, while the expansions a
+// stub resolver with ndots:5 tries first never reach NextDNS. Domain
+// overrides and the local zone have their own server blocks and are not
+// affected.
+func writeSearchDomainBlock(sb *strings.Builder, domains []string) {
+	if len(domains) == 0 {
+		return
+	}
+	zones := make([]string, len(domains))
+	for i, d := range domains {
+		zones[i] = fqdn(d)
+	}
+	fmt.Fprintf(sb, "    template ANY ANY %s {\n", strings.Join(zones, " "))
+	sb.WriteString("        rcode NXDOMAIN\n")
+	fmt.Fprintf(sb, "        authority \"{{ .Zone }} %d IN SOA ns.{{ .Zone }} hostmaster.{{ .Zone }} 1 7200 1800 86400 %d\"\n",
+		SearchDomainNegativeTTL, SearchDomainNegativeTTL)
+	sb.WriteString("    }\n")
+}
@@ -0,0 +1,62 @@
+package coredns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSearchDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+		wantErr string
+	}{
+		{name: "nil", domains: nil},
+		{name: "valid", domains: []string{"cluster.local", "us-west-2.compute.internal."}},
+		{name: "invalid", domains: []string{"cluster..local"}, wantErr: `invalid search domain "cluster..local"`},
+		{name: "root", domains: []string{"."}, wantErr: `invalid search domain "."`},
+		{name: "duplicate", domains: []string{"cluster.local", "Cluster.Local."}, wantErr: "duplicate search domain Cluster.Local."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSearchDomains(tt.domains)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateCorefile_SearchDomains(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		LocalRecords:    []LocalRecordConfig{{Name: "nas.cluster.local", Type: RecordTypeA, Value: "10.0.0.10", TTL: 3600}},
+		SearchDomains:   []string{"cluster.local", "ec2.internal."},
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	block := `    template ANY ANY cluster.local. ec2.internal. {
+        rcode NXDOMAIN
+        authority "{{ .Zone }} 60 IN SOA ns.{{ .Zone }} hostmaster.{{ .Zone }} 1 7200 1800 86400 60"
+    }
+`
+	assert.Contains(t, corefile, block)
+	local := strings.Index(corefile, "template IN A nas.cluster.local.")
+	search := strings.Index(corefile, block)
+	forward := strings.Index(corefile, "    forward .")
+	assert.True(t, local < search && search < forward, "search domains should follow local records and precede forward:\n%s", corefile)
+
+	cfg.SearchDomains = nil
+	assert.NotContains(t, GenerateCorefile(cfg), "rcode NXDOMAIN")
+}
@@ -0,0 +1,77 @@
+package coredns
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"strconv"
+)
+
+// DNS stamp protocol identifiers (https://dnscrypt.info/stamps-specifications)
+const (
+	StampProtocolPlain byte = 0x00
+	StampProtocolDoH   byte = 0x02
+	StampProtocolDoT   byte = 0x03
+)
+
+// DNS stamp properties
+const (
+	StampPropDNSSEC   uint64 = 1 << 0
+	StampPropNoLogs   uint64 = 1 << 1
+	StampPropNoFilter uint64 = 1 << 2
+)
+
+// DoHPath is the path CoreDNS serves DNS-over-HTTPS on
+const DoHPath = "/dns-query"
+
+// Stamp describes a resolver as a DNS stamp. Hashes and bootstrap resolvers
+// are left empty.
+type Stamp struct {
+	Protocol byte
+	Props    uint64
+
+	// Addr is the IP address with an optional port, as returned by
+	// StampAddr. Required for plain DNS.
+	Addr string
+
+	// Hostname is the TLS server name, with a port if Addr is empty and the
+	// port is not the protocol default. DoH and DoT only.
+	Hostname string
+
+	// Path is the URL path of the DoH endpoint. DoH only.
+	Path string
+}
+
+// String encodes the stamp as an sdns:// URI
+func (s Stamp) String() string {
+	buf := []byte{s.Protocol}
+	buf = binary.LittleEndian.AppendUint64(buf, s.Props)
+	buf = appendLP(buf, s.Addr)
+	switch s.Protocol {
+	case StampProtocolDoH:
+		buf = append(buf, 0) // no certificate hashes
+		buf = appendLP(buf, s.Hostname)
+		buf = appendLP(buf, s.Path)
+	case StampProtocolDoT:
+		buf = append(buf, 0) // no certificate hashes
+		buf = appendLP(buf, s.Hostname)
+	}
+	return "sdns://" + base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// appendLP appends s prefixed by its length
+func appendLP(buf []byte, s string) []byte {
+	return append(append(buf, byte(len(s))), s...)
+}
+
+// StampAddr formats ip and port for a stamp, omitting the port when it is
+// the protocol default
+func StampAddr(ip string, port, defaultPort int32) string {
+	if port == defaultPort {
+		if net.ParseIP(ip).To4() == nil {
+			return "[" + ip + "]"
+		}
+		return ip
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
@@ -0,0 +1,65 @@
+package coredns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampString(t *testing.T) {
+	tests := []struct {
+		name  string
+		stamp Stamp
+		want  string
+	}{
+		{
+			name:  "plain DNS",
+			stamp: Stamp{Protocol: StampProtocolPlain, Props: StampPropDNSSEC | StampPropNoLogs | StampPropNoFilter, Addr: "8.8.8.8"},
+			want:  "sdns://AAcAAAAAAAAABzguOC44Ljg",
+		},
+		{
+			name: "DoH",
+			stamp: Stamp{
+				Protocol: StampProtocolDoH,
+				Props:    StampPropDNSSEC | StampPropNoLogs | StampPropNoFilter,
+				Addr:     "1.0.0.1",
+				Hostname: "dns.cloudflare.com",
+				Path:     DoHPath,
+			},
+			want: "sdns://AgcAAAAAAAAABzEuMC4wLjEAEmRucy5jbG91ZGZsYXJlLmNvbQovZG5zLXF1ZXJ5",
+		},
+		{
+			name:  "DoT on a custom port",
+			stamp: Stamp{Protocol: StampProtocolDoT, Addr: "[2001:db8::1]:8853", Hostname: "dns.example.com"},
+			want:  "sdns://AwAAAAAAAAAAElsyMDAxOmRiODo6MV06ODg1MwAPZG5zLmV4YW1wbGUuY29t",
+		},
+		{
+			name:  "no properties",
+			stamp: Stamp{Protocol: StampProtocolPlain, Addr: "192.168.1.53"},
+			want:  "sdns://AAAAAAAAAAAADDE5Mi4xNjguMS41Mw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.stamp.String())
+		})
+	}
+}
+
+func TestStampAddr(t *testing.T) {
+	tests := []struct {
+		ip   string
+		port int32
+		want string
+	}{
+		{"192.168.1.53", 53, "192.168.1.53"},
+		{"192.168.1.53", 5353, "192.168.1.53:5353"},
+		{"2001:db8::1", 53, "[2001:db8::1]"},
+		{"2001:db8::1", 5353, "[2001:db8::1]:5353"},
+	}
+	for _, tt := range tests {
+		if got := StampAddr(tt.ip, tt.port, 53); got != tt.want {
+			t.Errorf("StampAddr(%q, %d) = %q, want %q", tt.ip, tt.port, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package coredns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// LocalZonePath is where the local zone file is mounted in the CoreDNS pods
+const LocalZonePath = "/etc/coredns/local.zone"
+
+// LocalZoneConfig serves the local records of a zone from a zone file and
+// allows transferring it with AXFR and IXFR.
+type LocalZoneConfig struct {
+	Zone         string
+	AllowedCIDRs []string
+}
+
+// ValidateLocalZone checks the zone name and transfer CIDRs, and that no
+// domain override serves the same zone. Returns an error describing all
+// validation failures.
+func ValidateLocalZone(zone *LocalZoneConfig, overrides []DomainOverrideConfig) error {
+	if zone == nil {
+		return nil
+	}
+	var errs []string
+	if !validDNSName(zone.Zone) {
+		errs = append(errs, fmt.Sprintf("invalid zone %q", zone.Zone))
+	}
+	if len(zone.AllowedCIDRs) == 0 {
+		errs = append(errs, "at least one allowed CIDR required")
+	}
+	for _, cidr := range zone.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid CIDR %q", cidr))
+		}
+	}
+	for _, o := range overrides {
+		if fqdn(o.Domain) == fqdn(zone.Zone) {
+			errs = append(errs, fmt.Sprintf("zone %s is also a domain override", zone.Zone))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("local zone validation failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// inZone reports whether name is zone or a name below it
+func inZone(name, zone string) bool {
+	name, zone = fqdn(name), fqdn(zone)
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+// LocalZoneRecords returns the hosts entries and local records within zone
+// as zone records. Hosts entries use the hosts TTL, or DefaultLocalRecordTTL
+// when it is not set.
+func LocalZoneRecords(zone string, hosts *HostsPluginConfig, records []LocalRecordConfig) []LocalRecordConfig {
+	var result []LocalRecordConfig
+	if hosts != nil {
+		ttl := hosts.TTL
+		if ttl == 0 {
+			ttl = DefaultLocalRecordTTL
+		}
+		for _, e := range hosts.Entries {
+			recordType := RecordTypeA
+			if ip := net.ParseIP(e.IP); ip != nil && ip.To4() == nil {
+				recordType = RecordTypeAAAA
+			}
+			for _, h := range e.Hostnames {
+				if inZone(h, zone) {
+					result = append(result, LocalRecordConfig{Name: h, Type: recordType, Value: e.IP, TTL: ttl})
+				}
+			}
+		}
+	}
+	for _, r := range records {
+		if inZone(r.Name, zone) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// GenerateZoneFile renders records as an RFC 1035 zone file for zone, with
+// an SOA and NS record naming ns.<zone> as the primary server
+func GenerateZoneFile(zone string, serial uint32, records []LocalRecordConfig) string {
+	origin := fqdn(zone)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(&sb, "@ %d IN SOA ns.%s hostmaster.%s %d 7200 3600 1209600 %d\n",
+		DefaultLocalRecordTTL, origin, origin, serial, DefaultLocalRecordTTL)
+	fmt.Fprintf(&sb, "@ %d IN NS ns.%s\n", DefaultLocalRecordTTL, origin)
+	for _, r := range records {
+		value := r.Value
+		if r.Type == RecordTypeCNAME {
+			value = fqdn(value)
+		}
+		fmt.Fprintf(&sb, "%s %d IN %s %s\n", fqdn(r.Name), r.TTL, r.Type, value)
+	}
+	return sb.String()
+}
+
+// writeLocalZoneBlock writes the server block serving the local zone from
+// its zone file. Only the allowed networks may transfer it; the transfer
+// plugin sends no NOTIFY, so secondaries poll the SOA. The transfer rules
+// share the acl block of the access control, since the acl plugin stops at
+// the first block allowing a query.
+func writeLocalZoneBlock(sb *strings.Builder, cfg *CorefileConfig) {
+	zone := cfg.LocalZone
+	if zone == nil {
+		return
+	}
+	fmt.Fprintf(sb, "%s {\n", strings.TrimSuffix(zone.Zone, "."))
+	writeBindDirective(sb, cfg.BindAddresses)
+	fmt.Fprintf(sb, "    file %s\n", LocalZonePath)
+	writeAccessControlBlock(sb, cfg.AccessControl, relaySources(cfg),
+		"allow type AXFR IXFR net "+strings.Join(zone.AllowedCIDRs, " "),
+		"block type AXFR IXFR")
+	sb.WriteString("    transfer {\n")
+	sb.WriteString("        to *\n")
+	sb.WriteString("    }\n")
+	writeLogDirective(sb, cfg)
+	sb.WriteString("    errors\n")
+	sb.WriteString("}\n\n")
+}
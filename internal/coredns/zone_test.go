@@ -0,0 +1,105 @@
+package coredns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLocalZone(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      *LocalZoneConfig
+		overrides []DomainOverrideConfig
+		wantErr   string
+	}{
+		{name: "nil", zone: nil},
+		{name: "valid", zone: &LocalZoneConfig{Zone: "home.arpa", AllowedCIDRs: []string{"192.168.1.1/32", "fd00::/8"}}},
+		{name: "invalid zone", zone: &LocalZoneConfig{Zone: "home..arpa", AllowedCIDRs: []string{"192.168.1.0/24"}}, wantErr: `invalid zone "home..arpa"`},
+		{name: "no CIDRs", zone: &LocalZoneConfig{Zone: "home.arpa"}, wantErr: "at least one allowed CIDR required"},
+		{name: "invalid CIDR", zone: &LocalZoneConfig{Zone: "home.arpa", AllowedCIDRs: []string{"192.168.1.1"}}, wantErr: `invalid CIDR "192.168.1.1"`},
+		{
+			name:      "domain override",
+			zone:      &LocalZoneConfig{Zone: "home.arpa", AllowedCIDRs: []string{"192.168.1.0/24"}},
+			overrides: []DomainOverrideConfig{{Domain: "home.arpa.", Upstreams: []string{"192.168.1.1"}}},
+			wantErr:   "zone home.arpa is also a domain override",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLocalZone(tt.zone, tt.overrides)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalZoneRecords(t *testing.T) {
+	hosts := &HostsPluginConfig{
+		Entries: []HostsEntryConfig{
+			{IP: "192.168.1.10", Hostnames: []string{"nas.home.arpa", "nas.example.com"}},
+			{IP: "fd00::10", Hostnames: []string{"NAS.home.arpa."}},
+		},
+	}
+	records := []LocalRecordConfig{
+		{Name: "files.home.arpa", Type: RecordTypeCNAME, Value: "nas.home.arpa", TTL: 300},
+		{Name: "myhome.arpa", Type: RecordTypeA, Value: "192.168.1.20", TTL: 300},
+	}
+
+	assert.Equal(t, []LocalRecordConfig{
+		{Name: "nas.home.arpa", Type: RecordTypeA, Value: "192.168.1.10", TTL: DefaultLocalRecordTTL},
+		{Name: "NAS.home.arpa.", Type: RecordTypeAAAA, Value: "fd00::10", TTL: DefaultLocalRecordTTL},
+		{Name: "files.home.arpa", Type: RecordTypeCNAME, Value: "nas.home.arpa", TTL: 300},
+	}, LocalZoneRecords("home.arpa", hosts, records))
+
+	hosts.TTL = 60
+	assert.Equal(t, int32(60), LocalZoneRecords("home.arpa", hosts, nil)[0].TTL)
+}
+
+func TestGenerateZoneFile(t *testing.T) {
+	zone := GenerateZoneFile("home.arpa", 1760601600, []LocalRecordConfig{
+		{Name: "nas.home.arpa", Type: RecordTypeA, Value: "192.168.1.10", TTL: 3600},
+		{Name: "files.home.arpa", Type: RecordTypeCNAME, Value: "nas.home.arpa", TTL: 300},
+	})
+
+	assert.Equal(t, `$ORIGIN home.arpa.
+@ 3600 IN SOA ns.home.arpa. hostmaster.home.arpa. 1760601600 7200 3600 1209600 3600
+@ 3600 IN NS ns.home.arpa.
+nas.home.arpa. 3600 IN A 192.168.1.10
+files.home.arpa. 300 IN CNAME nas.home.arpa.
+`, zone)
+}
+
+func TestGenerateCorefile_LocalZone(t *testing.T) {
+	cfg := &CorefileConfig{
+		ProfileID:       "abc123",
+		PrimaryProtocol: ProtocolDoT,
+		CacheTTL:        3600,
+		LocalZone:       &LocalZoneConfig{Zone: "home.arpa", AllowedCIDRs: []string{"192.168.1.1/32", "192.168.2.0/24"}},
+	}
+
+	corefile := GenerateCorefile(cfg)
+
+	assert.True(t, strings.HasPrefix(corefile, `home.arpa {
+    file /etc/coredns/local.zone
+    acl {
+        allow type AXFR IXFR net 192.168.1.1/32 192.168.2.0/24
+        block type AXFR IXFR
+    }
+    transfer {
+        to *
+    }
+    errors
+}
+
+. {
+`), corefile)
+}
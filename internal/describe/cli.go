@@ -0,0 +1,78 @@
+package describe
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// Options configures a describe run
+type Options struct {
+	// Name of the NextDNSProfile to describe
+	Name string
+	// Namespace of the NextDNSProfile
+	Namespace string
+	// CatalogNamespace holds the nextdns-catalog ConfigMap used for display
+	// names; built-in names are used when empty
+	CatalogNamespace string
+}
+
+// ParseFlags parses the arguments of the describe subcommand
+func ParseFlags(args []string, output io.Writer) (*Options, error) {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: nextdns-operator describe [flags] NAME")
+		fs.PrintDefaults()
+	}
+
+	opts := &Options{}
+	fs.StringVar(&opts.Namespace, "namespace", "default", "Namespace of the NextDNSProfile.")
+	fs.StringVar(&opts.CatalogNamespace, "catalog-namespace", os.Getenv("CATALOG_NAMESPACE"),
+		"Namespace of the nextdns-catalog ConfigMap providing blocklist names. Defaults to $CATALOG_NAMESPACE.")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != 1 {
+		return nil, errors.New("exactly one NextDNSProfile name is required")
+	}
+	opts.Name = fs.Arg(0)
+	return opts, nil
+}
+
+// Run executes the describe subcommand, writing the summary to stdout
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer, newClient func() (client.Client, error)) error {
+	opts, err := ParseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	var profile nextdnsv1alpha1.NextDNSProfile
+	if err := c.Get(ctx, types.NamespacedName{Name: opts.Name, Namespace: opts.Namespace}, &profile); err != nil {
+		return fmt.Errorf("failed to get NextDNSProfile %s/%s: %w", opts.Namespace, opts.Name, err)
+	}
+	policy, err := Policy(&profile)
+	if err != nil {
+		return err
+	}
+	catalog, err := LoadCatalog(ctx, c, opts.CatalogNamespace)
+	if err != nil {
+		return err
+	}
+	return Write(stdout, &profile, policy, catalog)
+}
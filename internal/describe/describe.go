@@ -0,0 +1,331 @@
+// Package describe renders the effective policy of a NextDNSProfile as a
+// one-screen summary, resolving blocklist, native, category and service IDs
+// into display names.
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/controller"
+)
+
+// nativeNames are the display names of the native tracking protection lists
+var nativeNames = map[string]string{
+	"alexa":   "Amazon Alexa",
+	"apple":   "Apple",
+	"huawei":  "Huawei",
+	"roku":    "Roku",
+	"samsung": "Samsung",
+	"sonos":   "Sonos",
+	"windows": "Windows",
+	"xiaomi":  "Xiaomi",
+}
+
+// categoryNames are the display names of the parental control categories
+var categoryNames = map[string]string{
+	"dating":          "Dating",
+	"gambling":        "Gambling",
+	"gaming":          "Online Gaming",
+	"piracy":          "Piracy",
+	"porn":            "Porn",
+	"social-networks": "Social Networks",
+	"video-streaming": "Video Streaming",
+}
+
+// serviceNames are the display names of services whose ID does not read as
+// a name when capitalized
+var serviceNames = map[string]string{
+	"9gag":                "9GAG",
+	"blizzard":            "Blizzard",
+	"chatgpt":             "ChatGPT",
+	"leagueoflegends":     "League of Legends",
+	"playstation-network": "PlayStation Network",
+	"tiktok":              "TikTok",
+	"twitter":             "X (Twitter)",
+	"vk":                  "VK",
+	"whatsapp":            "WhatsApp",
+	"xboxlive":            "Xbox Live",
+	"youtube":             "YouTube",
+}
+
+// Catalog holds the display names of the blocklists, natives and categories
+// read from the catalog ConfigMap the operator publishes
+type Catalog struct {
+	Blocklists map[string]string
+	Natives    map[string]string
+	Categories map[string]string
+}
+
+// LoadCatalog reads the nextdns-catalog ConfigMap in namespace. A missing
+// ConfigMap returns an empty catalog, so IDs fall back to built-in names.
+func LoadCatalog(ctx context.Context, c client.Reader, namespace string) (*Catalog, error) {
+	catalog := &Catalog{
+		Blocklists: map[string]string{},
+		Natives:    map[string]string{},
+		Categories: map[string]string{},
+	}
+	if namespace == "" {
+		return catalog, nil
+	}
+
+	var configMap corev1.ConfigMap
+	err := c.Get(ctx, types.NamespacedName{Name: controller.CatalogConfigMapName, Namespace: namespace}, &configMap)
+	if apierrors.IsNotFound(err) {
+		return catalog, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get catalog ConfigMap: %w", err)
+	}
+
+	for key, names := range map[string]map[string]string{
+		controller.CatalogBlocklistsKey: catalog.Blocklists,
+		controller.CatalogNativesKey:    catalog.Natives,
+		controller.CatalogCategoriesKey: catalog.Categories,
+	} {
+		data, ok := configMap.Data[key]
+		if !ok {
+			continue
+		}
+		var entries []controller.CatalogEntry
+		if err := json.Unmarshal([]byte(data), &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog %s: %w", key, err)
+		}
+		for _, entry := range entries {
+			if entry.Name != "" {
+				names[entry.ID] = entry.Name
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// displayName returns "Name (id)" using the first table naming id, or the
+// capitalized id when none does
+func displayName(id string, tables ...map[string]string) string {
+	for _, table := range tables {
+		if name, ok := table[id]; ok && name != id {
+			return fmt.Sprintf("%s (%s)", name, id)
+		}
+	}
+	words := strings.Split(id, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	name := strings.Join(words, " ")
+	if name == id {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", name, id)
+}
+
+// Policy returns the policy to describe: the remote configuration read in
+// observe mode, or the settings the spec applies in managed mode
+func Policy(profile *nextdnsv1alpha1.NextDNSProfile) (*nextdnsv1alpha1.ObservedConfig, error) {
+	if profile.Spec.Mode == nextdnsv1alpha1.ProfileModeObserve {
+		if profile.Status.ObservedConfig == nil {
+			return &nextdnsv1alpha1.ObservedConfig{}, nil
+		}
+		return profile.Status.ObservedConfig, nil
+	}
+	return controller.EffectivePolicy(&profile.Spec)
+}
+
+// summary writes labelled lines, keeping the errors of the writer
+type summary struct {
+	w   io.Writer
+	err error
+}
+
+func (s *summary) line(label, format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	if label != "" {
+		label += ":"
+	}
+	_, s.err = fmt.Fprintf(s.w, "%-14s"+format+"\n", append([]any{label}, args...)...)
+}
+
+func (s *summary) blank() {
+	if s.err != nil {
+		return
+	}
+	_, s.err = io.WriteString(s.w, "\n")
+}
+
+func (s *summary) list(label string, items []string) {
+	if len(items) == 0 {
+		s.line(label, "none")
+		return
+	}
+	s.line(label, "%s", strings.Join(items, ", "))
+}
+
+// onOff returns the names of the enabled and disabled toggles
+func onOff(toggles []toggle) (on, off []string) {
+	for _, t := range toggles {
+		if t.enabled {
+			on = append(on, t.name)
+		} else {
+			off = append(off, t.name)
+		}
+	}
+	return on, off
+}
+
+// toggle is a named boolean setting
+type toggle struct {
+	name    string
+	enabled bool
+}
+
+// states renders each toggle as "name on" or "name off"
+func states(toggles ...toggle) string {
+	parts := make([]string, len(toggles))
+	for i, t := range toggles {
+		parts[i] = t.name + " off"
+		if t.enabled {
+			parts[i] = t.name + " on"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Write renders the summary of profile and its policy
+func Write(w io.Writer, profile *nextdnsv1alpha1.NextDNSProfile, policy *nextdnsv1alpha1.ObservedConfig, catalog *Catalog) error {
+	s := &summary{w: w}
+
+	mode := profile.Spec.Mode
+	if mode == "" {
+		mode = nextdnsv1alpha1.ProfileModeManaged
+	}
+	profileID := profile.Status.ProfileID
+	if profileID == "" {
+		profileID = "not created"
+	}
+	s.line("Profile", "%s/%s (%s)", profile.Namespace, profile.Name, profileID)
+	if profile.Spec.ActiveOverlay != "" {
+		s.line("Mode", "%s, overlay %s", mode, profile.Spec.ActiveOverlay)
+	} else {
+		s.line("Mode", "%s", mode)
+	}
+	status := "Unknown"
+	if cond := meta.FindStatusCondition(profile.Status.Conditions, controller.ConditionTypeReady); cond != nil {
+		status = fmt.Sprintf("Ready=%s (%s)", cond.Status, cond.Reason)
+	}
+	if profile.Status.LastSyncTime != nil {
+		status += ", last sync " + profile.Status.LastSyncTime.UTC().Format(time.RFC3339)
+	}
+	s.line("Status", "%s", status)
+	s.blank()
+
+	if sec := policy.Security; sec != nil {
+		on, off := onOff([]toggle{
+			{"threat intelligence feeds", sec.ThreatIntelligenceFeeds},
+			{"AI threat detection", sec.AIThreatDetection},
+			{"Google Safe Browsing", sec.GoogleSafeBrowsing},
+			{"cryptojacking", sec.Cryptojacking},
+			{"DNS rebinding", sec.DNSRebinding},
+			{"IDN homographs", sec.IDNHomographs},
+			{"typosquatting", sec.Typosquatting},
+			{"DGA", sec.DGA},
+			{"newly registered domains", sec.NRD},
+			{"dynamic DNS", sec.DDNS},
+			{"parked domains", sec.Parking},
+			{"CSAM", sec.CSAM},
+		})
+		s.list("Security", on)
+		if len(off) > 0 {
+			s.line("", "off: %s", strings.Join(off, ", "))
+		}
+	} else {
+		s.line("Security", "unmanaged")
+	}
+
+	if p := policy.Privacy; p != nil {
+		var blocklists, natives []string
+		for _, b := range p.Blocklists {
+			blocklists = append(blocklists, displayName(b.ID, catalog.Blocklists))
+		}
+		for _, n := range p.Natives {
+			natives = append(natives, displayName(n.ID, catalog.Natives, nativeNames))
+		}
+		s.list("Blocklists", blocklists)
+		s.list("Natives", natives)
+		s.line("Privacy", "%s", states(
+			toggle{"disguised trackers blocking", p.DisguisedTrackers},
+			toggle{"affiliate links", p.AllowAffiliate}))
+	} else {
+		s.line("Privacy", "unmanaged")
+	}
+
+	if pc := policy.ParentalControl; pc != nil {
+		var categories, services []string
+		for _, c := range pc.Categories {
+			if c.Active {
+				categories = append(categories, displayName(c.ID, catalog.Categories, categoryNames))
+			}
+		}
+		for _, svc := range pc.Services {
+			if svc.Active {
+				services = append(services, displayName(svc.ID, serviceNames))
+			}
+		}
+		s.list("Categories", categories)
+		s.list("Services", services)
+		s.line("Parental", "%s", states(
+			toggle{"safe search", pc.SafeSearch},
+			toggle{"YouTube restricted mode", pc.YouTubeRestrictedMode},
+			toggle{"bypass blocking", pc.BlockBypass}))
+	} else {
+		s.line("Parental", "unmanaged")
+	}
+
+	allowed, denied, tlds, rewrites := len(policy.Allowlist), len(policy.Denylist), len(policy.BlockedTLDs), len(policy.Rewrites)
+	if profile.Spec.Mode != nextdnsv1alpha1.ProfileModeObserve && profile.Status.AggregatedCounts != nil {
+		counts := profile.Status.AggregatedCounts
+		allowed, denied, tlds, rewrites = counts.AllowlistDomains, counts.DenylistDomains, counts.BlockedTLDs, counts.Rewrites
+	}
+	s.line("Lists", "%d allowed, %d denied, %d TLDs blocked, %d rewrites", allowed, denied, tlds, rewrites)
+
+	if st := policy.Settings; st != nil {
+		if logs := st.Logs; logs != nil && logs.Enabled {
+			location := ""
+			if logs.Location != "" {
+				location = " in " + logs.Location
+			}
+			s.line("Logs", "kept %s%s, %s", controller.FormatRetention(logs.Retention), location, states(
+				toggle{"domains", logs.LogDomains},
+				toggle{"client IPs", logs.LogClientsIPs}))
+		} else {
+			s.line("Logs", "off")
+		}
+		toggles := []toggle{{"Web3", st.Web3}, {"bypass age verification", st.BAV}}
+		if st.BlockPage != nil {
+			toggles = append([]toggle{{"block page", st.BlockPage.Enabled}}, toggles...)
+		}
+		if perf := st.Performance; perf != nil {
+			toggles = append(toggles,
+				toggle{"ECS", perf.ECS}, toggle{"cache boost", perf.CacheBoost}, toggle{"CNAME flattening", perf.CNAMEFlattening})
+		}
+		s.line("Settings", "%s", states(toggles...))
+	} else {
+		s.line("Settings", "unmanaged")
+	}
+
+	return s.err
+}
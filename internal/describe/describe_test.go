@@ -0,0 +1,150 @@
+package describe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/controller"
+)
+
+func newFakeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(nextdnsv1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newProfile() *nextdnsv1alpha1.NextDNSProfile {
+	synced := metav1.NewTime(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	return &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "dns"},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+			Name: "Home",
+			Security: &nextdnsv1alpha1.SecuritySpec{
+				AIThreatDetection: ptr.To(true),
+				NRD:               ptr.To(false),
+			},
+			Privacy: &nextdnsv1alpha1.PrivacySpec{
+				Blocklists: []nextdnsv1alpha1.BlocklistEntry{{ID: "nextdns-recommended"}, {ID: "oisd", Active: ptr.To(false)}},
+				Natives:    []nextdnsv1alpha1.NativeEntry{{ID: "apple"}},
+			},
+			ParentalControl: &nextdnsv1alpha1.ParentalControlSpec{
+				Categories: []nextdnsv1alpha1.CategoryEntry{{ID: "social-networks"}},
+				Services:   []nextdnsv1alpha1.ServiceEntry{{ID: "tiktok"}, {ID: "fortnite"}},
+				SafeSearch: ptr.To(true),
+			},
+		},
+		Status: nextdnsv1alpha1.NextDNSProfileStatus{
+			ProfileID:        "abc123",
+			LastSyncTime:     &synced,
+			AggregatedCounts: &nextdnsv1alpha1.AggregatedCounts{AllowlistDomains: 3, DenylistDomains: 120, BlockedTLDs: 2},
+			Conditions: []metav1.Condition{{
+				Type:   controller.ConditionTypeReady,
+				Status: metav1.ConditionTrue,
+				Reason: "Synced",
+			}},
+		},
+	}
+}
+
+func newCatalog() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: controller.CatalogConfigMapName, Namespace: "nextdns-system"},
+		Data: map[string]string{
+			controller.CatalogBlocklistsKey: `[{"id":"nextdns-recommended","name":"NextDNS Ads & Trackers Blocklist","entries":120000,"profiles":2}]`,
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := newFakeClient(newProfile(), newCatalog())
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), []string{"--namespace", "dns", "--catalog-namespace", "nextdns-system", "home"},
+		&stdout, &stderr, func() (client.Client, error) { return c, nil })
+	require.NoError(t, err)
+
+	want := `Profile:      dns/home (abc123)
+Mode:         managed
+Status:       Ready=True (Synced), last sync 2026-10-17T09:00:00Z
+
+Security:     threat intelligence feeds, AI threat detection, Google Safe Browsing, cryptojacking, DNS rebinding, IDN homographs, typosquatting, DGA, parked domains, CSAM
+              off: newly registered domains, dynamic DNS
+Blocklists:   NextDNS Ads & Trackers Blocklist (nextdns-recommended)
+Natives:      Apple (apple)
+Privacy:      disguised trackers blocking on, affiliate links off
+Categories:   Social Networks (social-networks)
+Services:     TikTok (tiktok), Fortnite (fortnite)
+Parental:     safe search on, YouTube restricted mode off, bypass blocking off
+Lists:        3 allowed, 120 denied, 2 TLDs blocked, 0 rewrites
+Settings:     unmanaged
+`
+	assert.Equal(t, want, stdout.String())
+}
+
+func TestRun_ObserveMode(t *testing.T) {
+	profile := newProfile()
+	profile.Spec = nextdnsv1alpha1.NextDNSProfileSpec{Mode: nextdnsv1alpha1.ProfileModeObserve}
+	profile.Status.ObservedConfig = &nextdnsv1alpha1.ObservedConfig{
+		Privacy: &nextdnsv1alpha1.ObservedPrivacy{
+			DisguisedTrackers: true,
+			Blocklists:        []nextdnsv1alpha1.ObservedBlocklistEntry{{ID: "nextdns-recommended"}},
+		},
+		Denylist: []nextdnsv1alpha1.ObservedDomainEntry{{Domain: "ads.example.com", Active: true}},
+		Settings: &nextdnsv1alpha1.ObservedSettings{
+			Logs:      &nextdnsv1alpha1.ObservedLogs{Enabled: true, Retention: 604800, Location: "eu", LogDomains: true},
+			BlockPage: &nextdnsv1alpha1.ObservedBlockPage{Enabled: true},
+		},
+	}
+	c := newFakeClient(profile)
+
+	var stdout bytes.Buffer
+	err := Run(context.Background(), []string{"--namespace", "dns", "home"},
+		&stdout, &bytes.Buffer{}, func() (client.Client, error) { return c, nil })
+	require.NoError(t, err)
+
+	out := stdout.String()
+	assert.Contains(t, out, "Mode:         observe\n")
+	assert.Contains(t, out, "Security:     unmanaged\n")
+	assert.Contains(t, out, "Blocklists:   Nextdns Recommended (nextdns-recommended)\n", "without a catalog the ID is capitalized")
+	assert.Contains(t, out, "Lists:        0 allowed, 1 denied, 0 TLDs blocked, 0 rewrites\n")
+	assert.Contains(t, out, "Logs:         kept 7d in eu, domains on, client IPs off\n")
+	assert.Contains(t, out, "Settings:     block page on, Web3 off, bypass age verification off\n")
+}
+
+func TestRun_Errors(t *testing.T) {
+	newClient := func() (client.Client, error) { return newFakeClient(), nil }
+
+	err := Run(context.Background(), nil, &bytes.Buffer{}, &bytes.Buffer{}, newClient)
+	assert.EqualError(t, err, "exactly one NextDNSProfile name is required")
+
+	err = Run(context.Background(), []string{"-h"}, &bytes.Buffer{}, &bytes.Buffer{}, newClient)
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+
+	err = Run(context.Background(), []string{"missing"}, &bytes.Buffer{}, &bytes.Buffer{}, newClient)
+	assert.ErrorContains(t, err, "failed to get NextDNSProfile default/missing")
+}
+
+func TestLoadCatalog_Invalid(t *testing.T) {
+	catalog := newCatalog()
+	catalog.Data[controller.CatalogBlocklistsKey] = "not json"
+
+	_, err := LoadCatalog(context.Background(), newFakeClient(catalog), "nextdns-system")
+	assert.ErrorContains(t, err, "failed to parse catalog blocklists.json")
+}
@@ -0,0 +1,86 @@
+package listsource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fetched is cached content and when it was fetched
+type Fetched struct {
+	*Content
+	FetchedAt time.Time
+}
+
+// cacheEntry holds the last verified content of a source. Its mutex
+// serializes fetches of the source.
+type cacheEntry struct {
+	mu      sync.Mutex
+	fetched *Fetched
+}
+
+// Cache shares fetched list sources between reconciles, so each source is
+// fetched at most once per interval however many lists and profiles use it.
+type Cache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// NewCache returns an empty cache
+func NewCache() *Cache {
+	return &Cache{now: time.Now, entries: make(map[string]*cacheEntry)}
+}
+
+// entry returns the cache entry for key, creating it if needed
+func (c *Cache) entry(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		e = &cacheEntry{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+// cacheKey returns the key src is cached under. Verified sources are cached
+// apart from unverified ones at the same location.
+func cacheKey(src ListSource) string {
+	if v, ok := src.(*verifiedSource); ok {
+		return v.Key() + "|cosign:" + v.verifier.keyDigest
+	}
+	return src.Key()
+}
+
+// Get returns the content of src, fetching it when the cached content is
+// older than interval or does not match checksum. A non-empty checksum must
+// match the fetched content's digest. When a fetch fails, Get returns the
+// previously fetched content, if any, together with the error.
+func (c *Cache) Get(ctx context.Context, src ListSource, checksum string, interval time.Duration) (*Fetched, error) {
+	e := c.entry(cacheKey(src))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := c.now()
+	if f := e.fetched; f != nil && now.Sub(f.FetchedAt) < interval && (checksum == "" || f.Digest == checksum) {
+		return f, nil
+	}
+
+	content, err := src.Fetch(ctx)
+	if err == nil && checksum != "" && content.Digest != checksum {
+		err = fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, src.Key(), checksum, content.Digest)
+	}
+	if err != nil {
+		if f := e.fetched; f != nil && (checksum == "" || f.Digest == checksum) {
+			return f, err
+		}
+		return nil, err
+	}
+
+	e.fetched = &Fetched{Content: content, FetchedAt: now}
+	return e.fetched, nil
+}
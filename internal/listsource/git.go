@@ -0,0 +1,117 @@
+package listsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// GitSource fetches a list from a file in a Git repository. The repository
+// is shallow-cloned into memory on every fetch and accessed anonymously.
+type GitSource struct {
+	URL string
+
+	// Ref is a branch, tag, or full reference name; the default branch when empty
+	Ref string
+
+	// Path of the list file within the repository
+	Path string
+
+	// SignaturePath is read from the same commit along with the list when set
+	SignaturePath string
+}
+
+// Key implements ListSource
+func (s *GitSource) Key() string {
+	key := s.URL
+	if s.Ref != "" {
+		key += "@" + s.Ref
+	}
+	return key + "#" + s.Path
+}
+
+// referenceNames returns the references Ref may name, in lookup order
+func (s *GitSource) referenceNames() []plumbing.ReferenceName {
+	switch {
+	case s.Ref == "":
+		return []plumbing.ReferenceName{plumbing.HEAD}
+	case strings.HasPrefix(s.Ref, "refs/"):
+		return []plumbing.ReferenceName{plumbing.ReferenceName(s.Ref)}
+	default:
+		return []plumbing.ReferenceName{plumbing.NewBranchReferenceName(s.Ref), plumbing.NewTagReferenceName(s.Ref)}
+	}
+}
+
+// Fetch implements ListSource
+func (s *GitSource) Fetch(ctx context.Context) (*Content, error) {
+	var repo *git.Repository
+	var err error
+	for _, name := range s.referenceNames() {
+		repo, err = git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+			URL:           s.URL,
+			ReferenceName: name,
+			SingleBranch:  true,
+			Depth:         1,
+			NoCheckout:    true,
+			Tags:          git.NoTags,
+		})
+		if !errors.Is(err, git.NoMatchingRefSpecError{}) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", s.Key(), err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", s.Key(), err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s of %s: %w", head.Hash(), s.URL, err)
+	}
+	data, err := readCommitFile(commit, s.Path)
+	if err != nil {
+		return nil, err
+	}
+	content := newContent(data, head.Hash().String())
+
+	if s.SignaturePath != "" {
+		sig, err := readCommitFile(commit, s.SignaturePath)
+		if err != nil {
+			return nil, err
+		}
+		content.Signatures = []Signature{{Value: sig}}
+	}
+	return content, nil
+}
+
+// readCommitFile returns the contents of the file at path in commit
+func readCommitFile(commit *object.Commit, path string) ([]byte, error) {
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, commit.Hash, err)
+	}
+	if file.Size > maxContentSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", path, maxContentSize)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, commit.Hash, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	data, err := readLimited(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, commit.Hash, err)
+	}
+	return data, nil
+}
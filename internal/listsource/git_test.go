@@ -0,0 +1,68 @@
+package listsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepository creates a repository with lists/ads.txt on main, a
+// v1 tag, and a later commit
+func newTestRepository(t *testing.T) (string, plumbing.Hash) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(content string) plumbing.Hash {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "lists"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "lists", "ads.txt"), []byte(content), 0o644))
+		_, err := worktree.Add("lists/ads.txt")
+		require.NoError(t, err)
+		hash, err := worktree.Commit("update list", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash
+	}
+
+	tagged := commit("ads.example.com\n")
+	_, err = repo.CreateTag("v1", tagged, nil)
+	require.NoError(t, err)
+	commit("ads.example.com\ntracker.example.com\n")
+
+	return dir, tagged
+}
+
+func TestGitSource_Fetch(t *testing.T) {
+	dir, tagged := newTestRepository(t)
+	ctx := context.Background()
+
+	content, err := (&GitSource{URL: dir, Path: "lists/ads.txt"}).Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ads.example.com\ntracker.example.com\n", string(content.Data))
+
+	content, err = (&GitSource{URL: dir, Ref: "v1", Path: "lists/ads.txt"}).Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ads.example.com\n", string(content.Data))
+	assert.Equal(t, tagged.String(), content.Revision)
+
+	_, err = (&GitSource{URL: dir, Ref: "missing", Path: "lists/ads.txt"}).Fetch(ctx)
+	assert.Error(t, err)
+
+	_, err = (&GitSource{URL: dir, Path: "missing.txt"}).Fetch(ctx)
+	assert.ErrorContains(t, err, "missing.txt")
+}
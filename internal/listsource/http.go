@@ -0,0 +1,82 @@
+package listsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPSource fetches a list from an HTTP(S) URL
+type HTTPSource struct {
+	URL string
+
+	// SignatureURL is fetched along with the list when set
+	SignatureURL string
+
+	// Client is used for requests; http.DefaultClient when nil
+	Client *http.Client
+}
+
+// Key implements ListSource
+func (s *HTTPSource) Key() string {
+	return s.URL
+}
+
+// Fetch implements ListSource
+func (s *HTTPSource) Fetch(ctx context.Context) (*Content, error) {
+	data, etag, err := s.get(ctx, s.URL)
+	if err != nil {
+		return nil, err
+	}
+	content := newContent(data, etag)
+
+	if s.SignatureURL != "" {
+		sig, _, err := s.get(ctx, s.SignatureURL)
+		if err != nil {
+			return nil, err
+		}
+		content.Signatures = []Signature{{Value: sig}}
+	}
+	return content, nil
+}
+
+// get downloads url, returning its body and ETag
+func (s *HTTPSource) get(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	data, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// readLimited reads r, failing when it exceeds maxContentSize
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxContentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxContentSize {
+		return nil, fmt.Errorf("content exceeds %d bytes", maxContentSize)
+	}
+	return data, nil
+}
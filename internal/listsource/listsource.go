@@ -0,0 +1,194 @@
+// Package listsource fetches list entries from outside the cluster for the
+// list CRDs' spec.sources: HTTP(S) URLs, OCI artifacts, and files in Git
+// repositories.
+package listsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultInterval is the time between fetches when a source sets none
+	DefaultInterval = time.Hour
+
+	// maxContentSize caps the size of a fetched list
+	maxContentSize = 16 << 20
+)
+
+var (
+	// ErrVerificationFailed is returned when fetched content fails the
+	// source's checksum or signature verification
+	ErrVerificationFailed = errors.New("source verification failed")
+
+	// ErrChecksumMismatch is returned when fetched content does not match the
+	// source's pinned checksum
+	ErrChecksumMismatch = fmt.Errorf("%w: checksum mismatch", ErrVerificationFailed)
+)
+
+// ListSource fetches the contents of a remote list
+type ListSource interface {
+	// Key identifies the source and its location, for caching and status
+	Key() string
+
+	// Fetch downloads the list contents
+	Fetch(ctx context.Context) (*Content, error)
+}
+
+// Content is the fetched contents of a list source
+type Content struct {
+	// Data is the raw list file
+	Data []byte
+	// Revision is the source's version of the content: an OCI manifest
+	// digest, a Git commit, or an HTTP ETag. It may be empty.
+	Revision string
+	// Digest is the SHA-256 digest of Data
+	Digest string
+	// Signatures are the signatures fetched along with the content when the
+	// source is verified
+	Signatures []Signature
+}
+
+// Signature is a signature of fetched content
+type Signature struct {
+	// Value is the raw signature
+	Value []byte
+	// Payload is the signed payload when it is not the content itself, such
+	// as the cosign simple signing payload of an OCI manifest
+	Payload []byte
+}
+
+// newContent returns the content of data at revision
+func newContent(data []byte, revision string) *Content {
+	return &Content{Data: data, Revision: revision, Digest: Digest(data)}
+}
+
+// Digest returns the sha256:<hex> digest of data
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// New returns the ListSource for spec. Sources with cosign verification
+// fetch their signatures and reject content that fails verification.
+func New(spec nextdnsv1alpha1.ListSource) (ListSource, error) {
+	cosign := spec.Cosign
+	var sources []ListSource
+	if spec.HTTP != nil {
+		src := &HTTPSource{URL: spec.HTTP.URL}
+		if cosign != nil {
+			src.SignatureURL = signatureLocation(cosign, spec.HTTP.URL)
+		}
+		sources = append(sources, src)
+	}
+	if spec.OCI != nil {
+		sources = append(sources, &OCISource{Reference: spec.OCI.Reference, File: spec.OCI.File, Signed: cosign != nil})
+	}
+	if spec.Git != nil {
+		src := &GitSource{URL: spec.Git.URL, Ref: spec.Git.Ref, Path: spec.Git.Path}
+		if cosign != nil {
+			src.SignaturePath = signatureLocation(cosign, spec.Git.Path)
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("exactly one of http, oci, or git must be set, got %d", len(sources))
+	}
+
+	if cosign == nil {
+		return sources[0], nil
+	}
+	verifier, err := NewCosignVerifier(cosign.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+	}
+	return &verifiedSource{ListSource: sources[0], verifier: verifier}, nil
+}
+
+// signatureLocation returns the configured signature location, or the
+// content location with a .sig suffix
+func signatureLocation(cosign *nextdnsv1alpha1.CosignVerification, location string) string {
+	if cosign.Signature != "" {
+		return cosign.Signature
+	}
+	return location + ".sig"
+}
+
+// Interval returns the fetch interval of spec
+func Interval(spec nextdnsv1alpha1.ListSource) (time.Duration, error) {
+	if spec.Interval == "" {
+		return DefaultInterval, nil
+	}
+	interval, err := time.ParseDuration(spec.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", spec.Interval, err)
+	}
+	return interval, nil
+}
+
+// Parse returns the entries of a list file, one per line. Blank lines and
+// comments starting with # or ! are skipped, and hosts-file lines such as
+// "0.0.0.0 ads.example.com" yield the hostname. Entries are lowercased and
+// leading dots are removed.
+func Parse(data []byte) []string {
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxContentSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexAny(line, "#!"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		entry := fields[0]
+		if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			entry = fields[1]
+		}
+		entry = strings.TrimPrefix(strings.ToLower(entry), ".")
+		if entry == "" || entry == "localhost" || net.ParseIP(entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ParseAdblock returns the domains blocked by the domain rules of an
+// Adblock-style filter list, such as "||ads.example.com^" or
+// "||ads.example.com^$important". Comments, exception rules, and rules
+// matching paths, wildcards, or page elements are skipped.
+func ParseAdblock(data []byte) []string {
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxContentSize)
+	for scanner.Scan() {
+		rule, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "||")
+		if !ok {
+			continue
+		}
+		domain, modifiers, ok := strings.Cut(rule, "^")
+		if !ok || (modifiers != "" && !strings.HasPrefix(modifiers, "$")) {
+			continue
+		}
+		domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+		if domain == "" || strings.ContainsAny(domain, "*/:") || net.ParseIP(domain) != nil {
+			continue
+		}
+		entries = append(entries, domain)
+	}
+	return entries
+}
@@ -0,0 +1,163 @@
+package listsource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`# Blocklist
+ads.example.com
+  Tracker.Example.com   # inline comment
+! adblock-style comment
+
+0.0.0.0 malware.example.net
+127.0.0.1 localhost
+.zip
+`)
+
+	assert.Equal(t, []string{
+		"ads.example.com",
+		"tracker.example.com",
+		"malware.example.net",
+		"zip",
+	}, Parse(data))
+}
+
+func TestParseAdblock(t *testing.T) {
+	data := []byte(`[Adblock Plus]
+! Title: Blocklist
+||ads.example.com^
+||Tracker.Example.com^$important
+@@||allowed.example.com^
+||example.org/banner.js
+||*.wildcard.example^
+example.net##.banner
+||1.2.3.4^
+`)
+
+	assert.Equal(t, []string{
+		"ads.example.com",
+		"tracker.example.com",
+	}, ParseAdblock(data))
+}
+
+func TestNew(t *testing.T) {
+	src, err := New(nextdnsv1alpha1.ListSource{HTTP: &nextdnsv1alpha1.HTTPListSource{URL: "https://example.com/list.txt"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/list.txt", src.Key())
+
+	src, err = New(nextdnsv1alpha1.ListSource{Git: &nextdnsv1alpha1.GitListSource{URL: "https://example.com/lists.git", Ref: "main", Path: "ads.txt"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/lists.git@main#ads.txt", src.Key())
+
+	src, err = New(nextdnsv1alpha1.ListSource{OCI: &nextdnsv1alpha1.OCIListSource{Reference: "ghcr.io/org/lists:v1", File: "ads.txt"}})
+	require.NoError(t, err)
+	assert.Equal(t, "oci://ghcr.io/org/lists:v1#ads.txt", src.Key())
+
+	_, err = New(nextdnsv1alpha1.ListSource{})
+	assert.Error(t, err)
+
+	_, err = New(nextdnsv1alpha1.ListSource{
+		HTTP: &nextdnsv1alpha1.HTTPListSource{URL: "https://example.com/list.txt"},
+		Git:  &nextdnsv1alpha1.GitListSource{URL: "https://example.com/lists.git", Path: "ads.txt"},
+	})
+	assert.Error(t, err)
+}
+
+func TestInterval(t *testing.T) {
+	interval, err := Interval(nextdnsv1alpha1.ListSource{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultInterval, interval)
+
+	interval, err = Interval(nextdnsv1alpha1.ListSource{Interval: "15m"})
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, interval)
+
+	_, err = Interval(nextdnsv1alpha1.ListSource{Interval: "soon"})
+	assert.Error(t, err)
+}
+
+func TestHTTPSource_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/list.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("ads.example.com\n"))
+	}))
+	defer server.Close()
+
+	content, err := (&HTTPSource{URL: server.URL + "/list.txt"}).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ads.example.com\n", string(content.Data))
+	assert.Equal(t, `"v1"`, content.Revision)
+	assert.Equal(t, Digest([]byte("ads.example.com\n")), content.Digest)
+
+	_, err = (&HTTPSource{URL: server.URL + "/missing.txt"}).Fetch(context.Background())
+	assert.ErrorContains(t, err, "404")
+}
+
+// fakeSource returns canned content or an error
+type fakeSource struct {
+	data  string
+	err   error
+	calls int
+}
+
+func (s *fakeSource) Key() string { return "fake" }
+
+func (s *fakeSource) Fetch(context.Context) (*Content, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return newContent([]byte(s.data), ""), nil
+}
+
+func TestCache_Get(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache()
+	cache.now = func() time.Time { return now }
+	src := &fakeSource{data: "ads.example.com\n"}
+
+	fetched, err := cache.Get(ctx, src, "", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, now, fetched.FetchedAt)
+
+	// Fresh content is served from the cache
+	_, err = cache.Get(ctx, src, "", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, src.calls)
+
+	// A failed refresh returns the previous content with the error
+	now = now.Add(2 * time.Hour)
+	src.err = errors.New("unavailable")
+	fetched, err = cache.Get(ctx, src, "", time.Hour)
+	assert.Error(t, err)
+	require.NotNil(t, fetched)
+	assert.Equal(t, "ads.example.com\n", string(fetched.Data))
+
+	// Content not matching the checksum is rejected
+	src.err = nil
+	src.data = "tampered.example.com\n"
+	fetched, err = cache.Get(ctx, src, Digest([]byte("ads.example.com\n")), time.Hour)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	require.NotNil(t, fetched, "previous content still matches the checksum")
+	assert.Equal(t, "ads.example.com\n", string(fetched.Data))
+
+	fetched, err = cache.Get(ctx, src, Digest([]byte("other\n")), time.Hour)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Nil(t, fetched)
+}
@@ -0,0 +1,143 @@
+package listsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// OCISource fetches a list from a layer of an OCI artifact. Registries are
+// accessed anonymously.
+type OCISource struct {
+	Reference string
+
+	// File selects the layer by its org.opencontainers.image.title annotation
+	File string
+
+	// PlainHTTP accesses the registry over HTTP instead of HTTPS
+	PlainHTTP bool
+
+	// Signed fetches the cosign signatures of the manifest along with the list
+	Signed bool
+}
+
+// cosignSignatureAnnotation holds the base64 signature on each layer of a
+// cosign signature manifest
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// Key implements ListSource
+func (s *OCISource) Key() string {
+	if s.File == "" {
+		return "oci://" + s.Reference
+	}
+	return "oci://" + s.Reference + "#" + s.File
+}
+
+// Fetch implements ListSource
+func (s *OCISource) Fetch(ctx context.Context) (*Content, error) {
+	repo, err := remote.NewRepository(s.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %s: %w", s.Reference, err)
+	}
+	repo.PlainHTTP = s.PlainHTTP
+
+	desc, rc, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", s.Reference, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	if desc.Size > maxContentSize {
+		return nil, fmt.Errorf("manifest %s exceeds %d bytes", s.Reference, maxContentSize)
+	}
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", s.Reference, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", s.Reference, err)
+	}
+
+	layer, err := s.selectLayer(manifest.Layers)
+	if err != nil {
+		return nil, err
+	}
+	if layer.Size > maxContentSize {
+		return nil, fmt.Errorf("layer %s exceeds %d bytes", layer.Digest, maxContentSize)
+	}
+
+	// FetchAll verifies the layer digest
+	blob, err := content.FetchAll(ctx, repo.Blobs(), layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layer %s of %s: %w", layer.Digest, s.Reference, err)
+	}
+	content := newContent(blob, desc.Digest.String())
+
+	if s.Signed {
+		content.Signatures, err = s.fetchSignatures(ctx, repo, desc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// fetchSignatures returns the signatures stored by cosign for the manifest,
+// found at the sha256-<hex>.sig tag
+func (s *OCISource) fetchSignatures(ctx context.Context, repo *remote.Repository, manifest ocispec.Descriptor) ([]Signature, error) {
+	tag := manifest.Digest.Algorithm().String() + "-" + manifest.Digest.Encoded() + ".sig"
+	desc, rc, err := repo.FetchReference(ctx, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signatures of %s: %w", s.Reference, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	if desc.Size > maxContentSize {
+		return nil, fmt.Errorf("signature manifest %s exceeds %d bytes", tag, maxContentSize)
+	}
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature manifest %s: %w", tag, err)
+	}
+	var sigManifest ocispec.Manifest
+	if err := json.Unmarshal(data, &sigManifest); err != nil {
+		return nil, fmt.Errorf("failed to parse signature manifest %s: %w", tag, err)
+	}
+
+	var signatures []Signature
+	for _, layer := range sigManifest.Layers {
+		value, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok || layer.Size > maxContentSize {
+			continue
+		}
+		payload, err := content.FetchAll(ctx, repo.Blobs(), layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch signature payload %s: %w", layer.Digest, err)
+		}
+		signatures = append(signatures, Signature{Value: []byte(value), Payload: payload})
+	}
+	return signatures, nil
+}
+
+// selectLayer returns the layer holding the list
+func (s *OCISource) selectLayer(layers []ocispec.Descriptor) (ocispec.Descriptor, error) {
+	if s.File == "" {
+		if len(layers) != 1 {
+			return ocispec.Descriptor{}, fmt.Errorf("artifact %s has %d layers; set file to select one", s.Reference, len(layers))
+		}
+		return layers[0], nil
+	}
+
+	for _, layer := range layers {
+		if layer.Annotations[ocispec.AnnotationTitle] == s.File {
+			return layer, nil
+		}
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("artifact %s has no layer titled %s", s.Reference, s.File)
+}
@@ -0,0 +1,111 @@
+package listsource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry serves manifests by reference and blobs by digest from the
+// lists repository
+type testRegistry struct {
+	server    *httptest.Server
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+// addManifest serves a manifest with the given layers at ref and returns its digest
+func (r *testRegistry) addManifest(t *testing.T, ref string, layers []ocispec.Descriptor, data map[digest.Digest][]byte) digest.Digest {
+	t.Helper()
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    layers,
+	}
+	manifest.SchemaVersion = 2
+	r.blobs[ocispec.DescriptorEmptyJSON.Digest.String()] = ocispec.DescriptorEmptyJSON.Data
+	for d, blob := range data {
+		r.blobs[d.String()] = blob
+	}
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	manifestDigest := digest.FromBytes(manifestData)
+	r.manifests[ref] = manifestData
+	r.manifests[manifestDigest.String()] = manifestData
+	return manifestDigest
+}
+
+// newTestRegistry serves an artifact with the given titled layers at lists:v1
+func newTestRegistry(t *testing.T, layers map[string]string) (*testRegistry, string) {
+	t.Helper()
+
+	registry := &testRegistry{manifests: make(map[string][]byte), blobs: make(map[string][]byte)}
+	var descriptors []ocispec.Descriptor
+	data := make(map[digest.Digest][]byte)
+	for title, content := range layers {
+		d := digest.FromString(content)
+		data[d] = []byte(content)
+		descriptors = append(descriptors, ocispec.Descriptor{
+			MediaType:   "text/plain",
+			Digest:      d,
+			Size:        int64(len(content)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		})
+	}
+	manifestDigest := registry.addManifest(t, "v1", descriptors, data)
+
+	registry.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v2/lists/manifests/"):
+			manifest, ok := registry.manifests[strings.TrimPrefix(r.URL.Path, "/v2/lists/manifests/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/lists/blobs/"):
+			blob, ok := registry.blobs[strings.TrimPrefix(r.URL.Path, "/v2/lists/blobs/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(blob)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(registry.server.Close)
+
+	return registry, manifestDigest.String()
+}
+
+func TestOCISource_Fetch(t *testing.T) {
+	registry, manifestDigest := newTestRegistry(t, map[string]string{
+		"ads.txt":  "ads.example.com\n",
+		"tlds.txt": "zip\n",
+	})
+	host := strings.TrimPrefix(registry.server.URL, "http://")
+	ctx := context.Background()
+
+	content, err := (&OCISource{Reference: host + "/lists:v1", File: "tlds.txt", PlainHTTP: true}).Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "zip\n", string(content.Data))
+	assert.Equal(t, manifestDigest, content.Revision)
+
+	// Multi-layer artifacts need a file
+	_, err = (&OCISource{Reference: host + "/lists:v1", PlainHTTP: true}).Fetch(ctx)
+	assert.ErrorContains(t, err, "set file")
+
+	_, err = (&OCISource{Reference: host + "/lists:v1", File: "missing.txt", PlainHTTP: true}).Fetch(ctx)
+	assert.ErrorContains(t, err, "no layer titled missing.txt")
+}
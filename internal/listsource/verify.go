@@ -0,0 +1,138 @@
+package listsource
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CosignVerifier verifies cosign signatures made with a key pair. Only
+// key-based signatures are supported; keyless signatures and transparency
+// log entries are not checked.
+type CosignVerifier struct {
+	key crypto.PublicKey
+
+	// keyDigest identifies the key
+	keyDigest string
+}
+
+// NewCosignVerifier returns a verifier for the PEM-encoded public key
+func NewCosignVerifier(publicKey string) (*CosignVerifier, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("cosign public key is not PEM-encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cosign public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported cosign public key type %T", key)
+	}
+	return &CosignVerifier{key: key, keyDigest: Digest(block.Bytes)}, nil
+}
+
+// Verify checks that at least one signature of content is valid. Signatures
+// of a separate payload must bind the payload to content.Revision, the
+// digest of the signed OCI manifest.
+func (v *CosignVerifier) Verify(content *Content) error {
+	if len(content.Signatures) == 0 {
+		return fmt.Errorf("%w: no signatures found", ErrVerificationFailed)
+	}
+
+	var errs []error
+	for _, sig := range content.Signatures {
+		err := v.verifySignature(content, sig)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("%w: %w", ErrVerificationFailed, errors.Join(errs...))
+}
+
+// verifySignature checks a single signature of content
+func (v *CosignVerifier) verifySignature(content *Content, sig Signature) error {
+	value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig.Value)))
+	if err != nil {
+		return fmt.Errorf("signature is not base64-encoded: %w", err)
+	}
+
+	payload := content.Data
+	if sig.Payload != nil {
+		if err := checkSimpleSigningPayload(sig.Payload, content.Revision); err != nil {
+			return err
+		}
+		payload = sig.Payload
+	}
+
+	hash := sha256.Sum256(payload)
+	switch key := v.key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], value) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], value); err != nil {
+			return errors.New("invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, value) {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
+
+// simpleSigningPayload is the part of a cosign simple signing payload that
+// names the signed manifest
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// checkSimpleSigningPayload checks that payload signs the manifest digest
+func checkSimpleSigningPayload(payload []byte, manifestDigest string) error {
+	var p simpleSigningPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("failed to parse signature payload: %w", err)
+	}
+	if signed := p.Critical.Image.DockerManifestDigest; signed != manifestDigest {
+		return fmt.Errorf("signature is for manifest %s, not %s", signed, manifestDigest)
+	}
+	return nil
+}
+
+// verifiedSource rejects content of the wrapped source that fails
+// verification
+type verifiedSource struct {
+	ListSource
+	verifier *CosignVerifier
+}
+
+// Fetch implements ListSource
+func (s *verifiedSource) Fetch(ctx context.Context) (*Content, error) {
+	content, err := s.ListSource.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifier.Verify(content); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", s.Key(), err)
+	}
+	return content, nil
+}
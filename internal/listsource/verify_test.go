@@ -0,0 +1,188 @@
+package listsource
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// testSigner signs like "cosign sign-blob" with an ECDSA P-256 key
+type testSigner struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return &testSigner{
+		key:       key,
+		publicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}
+}
+
+// sign returns the base64 signature of data
+func (s *testSigner) sign(t *testing.T, data []byte) string {
+	t.Helper()
+	hash := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.key, hash[:])
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestNew_CosignHTTP(t *testing.T) {
+	signer := newTestSigner(t)
+	list := []byte("ads.example.com\n")
+	files := map[string]string{
+		"/ads.txt":       string(list),
+		"/ads.txt.sig":   signer.sign(t, list) + "\n",
+		"/other.txt":     "tampered.example.com\n",
+		"/other.txt.sig": signer.sign(t, list),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(data))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	src, err := New(nextdnsv1alpha1.ListSource{
+		HTTP:   &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: signer.publicKey},
+	})
+	require.NoError(t, err)
+	content, err := src.Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, string(list), string(content.Data))
+
+	// Content not matching its signature is rejected
+	src, err = New(nextdnsv1alpha1.ListSource{
+		HTTP:   &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/other.txt"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: signer.publicKey},
+	})
+	require.NoError(t, err)
+	_, err = src.Fetch(ctx)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+
+	// A signature made with another key is rejected
+	src, err = New(nextdnsv1alpha1.ListSource{
+		HTTP:   &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: newTestSigner(t).publicKey},
+	})
+	require.NoError(t, err)
+	_, err = src.Fetch(ctx)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+
+	_, err = New(nextdnsv1alpha1.ListSource{
+		HTTP:   &nextdnsv1alpha1.HTTPListSource{URL: server.URL + "/ads.txt"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: "not a key"},
+	})
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+}
+
+func TestNew_CosignGit(t *testing.T) {
+	dir, _ := newTestRepository(t)
+	signer := newTestSigner(t)
+
+	// The test repository has no signature next to the list
+	src, err := New(nextdnsv1alpha1.ListSource{
+		Git:    &nextdnsv1alpha1.GitListSource{URL: dir, Path: "lists/ads.txt"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: signer.publicKey},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "lists/ads.txt.sig", src.(*verifiedSource).ListSource.(*GitSource).SignaturePath)
+	_, err = src.Fetch(context.Background())
+	assert.ErrorContains(t, err, "lists/ads.txt.sig")
+}
+
+func TestNew_CosignOCI(t *testing.T) {
+	signer := newTestSigner(t)
+	registry, manifestDigest := newTestRegistry(t, map[string]string{"ads.txt": "ads.example.com\n"})
+	host := strings.TrimPrefix(registry.server.URL, "http://")
+	spec := nextdnsv1alpha1.ListSource{
+		OCI:    &nextdnsv1alpha1.OCIListSource{Reference: host + "/lists:v1"},
+		Cosign: &nextdnsv1alpha1.CosignVerification{PublicKey: signer.publicKey},
+	}
+	ctx := context.Background()
+
+	fetch := func() (*Content, error) {
+		src, err := New(spec)
+		require.NoError(t, err)
+		src.(*verifiedSource).ListSource.(*OCISource).PlainHTTP = true
+		return src.Fetch(ctx)
+	}
+
+	// Unsigned artifacts are rejected
+	_, err := fetch()
+	assert.ErrorContains(t, err, "failed to fetch signatures")
+
+	// sign stores a cosign signature of the payload at the signature tag
+	sign := func(payload string) {
+		d := digest.FromString(payload)
+		registry.addManifest(t, "sha256-"+strings.TrimPrefix(manifestDigest, "sha256:")+".sig", []ocispec.Descriptor{{
+			MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+			Digest:      d,
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{cosignSignatureAnnotation: signer.sign(t, []byte(payload))},
+		}}, map[digest.Digest][]byte{d: []byte(payload)})
+	}
+
+	sign(`{"critical":{"identity":{"docker-reference":"lists"},"image":{"docker-manifest-digest":"` + manifestDigest + `"},"type":"cosign container image signature"}}`)
+	content, err := fetch()
+	require.NoError(t, err)
+	assert.Equal(t, "ads.example.com\n", string(content.Data))
+
+	// A signature of another manifest is rejected
+	sign(`{"critical":{"image":{"docker-manifest-digest":"sha256:0000"}}}`)
+	_, err = fetch()
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+}
+
+func TestCosignVerifier_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	verifier, err := NewCosignVerifier(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	require.NoError(t, err)
+
+	content := newContent([]byte("ads.example.com\n"), "")
+	content.Signatures = []Signature{{Value: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, content.Data)))}}
+	assert.NoError(t, verifier.Verify(content))
+
+	content.Signatures = nil
+	assert.ErrorIs(t, verifier.Verify(content), ErrVerificationFailed)
+}
+
+func TestCache_VerifiedSourcesCachedSeparately(t *testing.T) {
+	signer := newTestSigner(t)
+	plain := &HTTPSource{URL: "https://example.com/ads.txt"}
+	verifier, err := NewCosignVerifier(signer.publicKey)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, cacheKey(plain), cacheKey(&verifiedSource{ListSource: plain, verifier: verifier}))
+}
@@ -1,91 +1,393 @@
 package metrics
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
-var (
+// Metrics holds the operator's Prometheus collectors
+type Metrics struct {
 	// ProfilesTotal tracks the total number of NextDNSProfile resources
-	ProfilesTotal = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "nextdns_profiles_total",
-		Help: "Total number of NextDNSProfile resources",
-	})
+	ProfilesTotal prometheus.Gauge
 
 	// ProfilesSyncedTotal tracks successful profile syncs
-	ProfilesSyncedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "nextdns_profiles_synced_total",
-		Help: "Total number of successful profile syncs",
-	}, []string{"profile", "namespace"})
+	ProfilesSyncedTotal *prometheus.CounterVec
 
 	// ProfilesSyncErrorsTotal tracks failed profile syncs
-	ProfilesSyncErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "nextdns_profiles_sync_errors_total",
-		Help: "Total number of failed profile syncs",
-	}, []string{"profile", "namespace", "reason"})
+	ProfilesSyncErrorsTotal *prometheus.CounterVec
+
+	// CrossNamespaceAccessDeniedTotal tracks list references the operator
+	// could not read because of RBAC or the watched namespaces
+	CrossNamespaceAccessDeniedTotal *prometheus.CounterVec
 
 	// APIRequestDuration tracks NextDNS API call latency
-	APIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "nextdns_api_request_duration_seconds",
-		Help:    "Duration of NextDNS API requests in seconds",
-		Buckets: prometheus.DefBuckets,
-	}, []string{"operation"})
+	APIRequestDuration *prometheus.HistogramVec
 
 	// APIRequestsTotal tracks total NextDNS API calls
-	APIRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "nextdns_api_requests_total",
-		Help: "Total number of NextDNS API requests",
-	}, []string{"operation", "status"})
+	APIRequestsTotal *prometheus.CounterVec
+
+	// APIRetriesTotal tracks NextDNS API requests retried after a rate limit
+	// or server error
+	APIRetriesTotal *prometheus.CounterVec
+
+	// ListEntriesAddedTotal tracks entries syncs added to NextDNS lists
+	ListEntriesAddedTotal *prometheus.CounterVec
+
+	// ListEntriesRemovedTotal tracks entries syncs removed from NextDNS lists
+	ListEntriesRemovedTotal *prometheus.CounterVec
 
 	// AllowlistsTotal tracks the total number of NextDNSAllowlist resources
-	AllowlistsTotal = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "nextdns_allowlists_total",
-		Help: "Total number of NextDNSAllowlist resources",
-	})
+	AllowlistsTotal prometheus.Gauge
 
 	// DenylistsTotal tracks the total number of NextDNSDenylist resources
-	DenylistsTotal = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "nextdns_denylists_total",
-		Help: "Total number of NextDNSDenylist resources",
-	})
+	DenylistsTotal prometheus.Gauge
 
 	// TLDListsTotal tracks the total number of NextDNSTLDList resources
-	TLDListsTotal = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "nextdns_tldlists_total",
-		Help: "Total number of NextDNSTLDList resources",
-	})
+	TLDListsTotal prometheus.Gauge
+
+	// RewritesTotal tracks the total number of NextDNSRewrite resources
+	RewritesTotal prometheus.Gauge
+
+	// SoakTestLookupsTotal tracks soak test lookups by resolver and result
+	SoakTestLookupsTotal *prometheus.CounterVec
+
+	// SoakTestLookupDuration tracks soak test lookup latency by resolver
+	SoakTestLookupDuration *prometheus.HistogramVec
+
+	// SoakTestDivergencesTotal tracks soak test lookups whose results
+	// differed between NextDNS and the legacy resolver
+	SoakTestDivergencesTotal *prometheus.CounterVec
+
+	// CoreDNSReady tracks whether each NextDNSCoreDNS is ready (1) or not (0)
+	CoreDNSReady *prometheus.GaugeVec
+
+	// CoreDNSReplicasDesired tracks the CoreDNS pods each NextDNSCoreDNS
+	// should run
+	CoreDNSReplicasDesired *prometheus.GaugeVec
+
+	// CoreDNSReplicasReady tracks the ready CoreDNS pods of each
+	// NextDNSCoreDNS
+	CoreDNSReplicasReady *prometheus.GaugeVec
+
+	// CoreDNSReplicasAvailable tracks the available CoreDNS pods of each
+	// NextDNSCoreDNS
+	CoreDNSReplicasAvailable *prometheus.GaugeVec
+
+	// CoreDNSReconcileDuration tracks the duration of the last reconcile of
+	// each NextDNSCoreDNS
+	CoreDNSReconcileDuration *prometheus.GaugeVec
+
+	// CoreDNSCorefileErrorsTotal tracks reconciles whose Corefile could not
+	// be generated
+	CoreDNSCorefileErrorsTotal *prometheus.CounterVec
+
+	// AccountOrphanedProfiles tracks the orphaned profiles found in each
+	// NextDNSAccount
+	AccountOrphanedProfiles *prometheus.GaugeVec
+
+	// AccountOrphansDeletedTotal tracks orphaned profiles deleted by the
+	// garbage collection of each NextDNSAccount
+	AccountOrphansDeletedTotal *prometheus.CounterVec
+}
+
+var (
+	defaultMetrics     *Metrics
+	defaultMetricsOnce sync.Once
 )
 
-func init() {
-	// Register custom metrics with the controller-runtime metrics registry
-	metrics.Registry.MustRegister(
-		ProfilesTotal,
-		ProfilesSyncedTotal,
-		ProfilesSyncErrorsTotal,
-		APIRequestDuration,
-		APIRequestsTotal,
-		AllowlistsTotal,
-		DenylistsTotal,
-		TLDListsTotal,
-	)
-}
-
-// RecordAPIRequest records an API request with its duration and status
-func RecordAPIRequest(operation string, duration float64, success bool) {
+// Default returns the metrics registered with the controller-runtime
+// metrics registry, which the manager serves. Collectors that cannot be
+// registered still record, but are not exported.
+func Default() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = newMetrics()
+		_ = defaultMetrics.register(metrics.Registry)
+	})
+	return defaultMetrics
+}
+
+// New returns metrics registered with registerer, or with the
+// controller-runtime metrics registry when registerer is nil. Collectors
+// already registered by another Metrics are shared rather than reported as
+// an error, so several managers or tests can use the same registry.
+func New(registerer prometheus.Registerer) (*Metrics, error) {
+	if registerer == nil {
+		registerer = metrics.Registry
+	}
+	m := newMetrics()
+	if err := m.register(registerer); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// newMetrics returns unregistered collectors
+func newMetrics() *Metrics {
+	return &Metrics{
+		ProfilesTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nextdns_profiles_total",
+			Help: "Total number of NextDNSProfile resources",
+		}),
+		ProfilesSyncedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_profiles_synced_total",
+			Help: "Total number of successful profile syncs",
+		}, []string{"profile", "namespace"}),
+		ProfilesSyncErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_profiles_sync_errors_total",
+			Help: "Total number of failed profile syncs",
+		}, []string{"profile", "namespace", "reason"}),
+		CrossNamespaceAccessDeniedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_cross_namespace_access_denied_total",
+			Help: "Total number of list references denied across namespaces",
+		}, []string{"profile", "namespace"}),
+		APIRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "nextdns_api_request_duration_seconds",
+			Help:    "Duration of NextDNS API requests in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "account"}),
+		APIRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_api_requests_total",
+			Help: "Total number of NextDNS API requests",
+		}, []string{"operation", "status", "account"}),
+		APIRetriesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_api_retries_total",
+			Help: "Total number of retried NextDNS API requests",
+		}, []string{"reason", "account"}),
+		ListEntriesAddedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_list_entries_added_total",
+			Help: "Total number of entries added to NextDNS lists by syncs",
+		}, []string{"list_type"}),
+		ListEntriesRemovedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_list_entries_removed_total",
+			Help: "Total number of entries removed from NextDNS lists by syncs",
+		}, []string{"list_type"}),
+		AllowlistsTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nextdns_allowlists_total",
+			Help: "Total number of NextDNSAllowlist resources",
+		}),
+		DenylistsTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nextdns_denylists_total",
+			Help: "Total number of NextDNSDenylist resources",
+		}),
+		TLDListsTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nextdns_tldlists_total",
+			Help: "Total number of NextDNSTLDList resources",
+		}),
+		RewritesTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nextdns_rewrites_total",
+			Help: "Total number of NextDNSRewrite resources",
+		}),
+		SoakTestLookupsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_soak_test_lookups_total",
+			Help: "Total number of soak test lookups",
+		}, []string{"coredns", "namespace", "resolver", "result"}),
+		SoakTestLookupDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "nextdns_soak_test_lookup_duration_seconds",
+			Help:    "Duration of soak test lookups in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"coredns", "namespace", "resolver"}),
+		SoakTestDivergencesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_soak_test_divergences_total",
+			Help: "Total number of soak test lookups answered differently by NextDNS and the legacy resolver",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSReady: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_coredns_ready",
+			Help: "Whether the NextDNSCoreDNS is ready (1) or not (0)",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSReplicasDesired: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_coredns_replicas_desired",
+			Help: "Number of CoreDNS pods the NextDNSCoreDNS should run",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSReplicasReady: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_coredns_replicas_ready",
+			Help: "Number of ready CoreDNS pods of the NextDNSCoreDNS",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSReplicasAvailable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_coredns_replicas_available",
+			Help: "Number of available CoreDNS pods of the NextDNSCoreDNS",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSReconcileDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_coredns_last_reconcile_duration_seconds",
+			Help: "Duration of the last reconcile of the NextDNSCoreDNS in seconds",
+		}, []string{"coredns", "namespace"}),
+		CoreDNSCorefileErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_coredns_corefile_errors_total",
+			Help: "Total number of NextDNSCoreDNS reconciles whose Corefile could not be generated",
+		}, []string{"coredns", "namespace"}),
+		AccountOrphanedProfiles: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nextdns_account_orphaned_profiles",
+			Help: "Number of orphaned profiles in the NextDNSAccount",
+		}, []string{"nextdnsaccount", "namespace"}),
+		AccountOrphansDeletedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nextdns_account_orphaned_profiles_deleted_total",
+			Help: "Total number of orphaned profiles deleted from the NextDNSAccount",
+		}, []string{"nextdnsaccount", "namespace"}),
+	}
+}
+
+// register registers the collectors with registerer. A collector that is
+// already registered is replaced by the registered one.
+func (m *Metrics) register(registerer prometheus.Registerer) error {
+	var errs []error
+	registerCollector(registerer, &m.ProfilesTotal, &errs)
+	registerCollector(registerer, &m.ProfilesSyncedTotal, &errs)
+	registerCollector(registerer, &m.ProfilesSyncErrorsTotal, &errs)
+	registerCollector(registerer, &m.CrossNamespaceAccessDeniedTotal, &errs)
+	registerCollector(registerer, &m.APIRequestDuration, &errs)
+	registerCollector(registerer, &m.APIRequestsTotal, &errs)
+	registerCollector(registerer, &m.APIRetriesTotal, &errs)
+	registerCollector(registerer, &m.ListEntriesAddedTotal, &errs)
+	registerCollector(registerer, &m.ListEntriesRemovedTotal, &errs)
+	registerCollector(registerer, &m.AllowlistsTotal, &errs)
+	registerCollector(registerer, &m.DenylistsTotal, &errs)
+	registerCollector(registerer, &m.TLDListsTotal, &errs)
+	registerCollector(registerer, &m.RewritesTotal, &errs)
+	registerCollector(registerer, &m.SoakTestLookupsTotal, &errs)
+	registerCollector(registerer, &m.SoakTestLookupDuration, &errs)
+	registerCollector(registerer, &m.SoakTestDivergencesTotal, &errs)
+	registerCollector(registerer, &m.CoreDNSReady, &errs)
+	registerCollector(registerer, &m.CoreDNSReplicasDesired, &errs)
+	registerCollector(registerer, &m.CoreDNSReplicasReady, &errs)
+	registerCollector(registerer, &m.CoreDNSReplicasAvailable, &errs)
+	registerCollector(registerer, &m.CoreDNSReconcileDuration, &errs)
+	registerCollector(registerer, &m.CoreDNSCorefileErrorsTotal, &errs)
+	registerCollector(registerer, &m.AccountOrphanedProfiles, &errs)
+	registerCollector(registerer, &m.AccountOrphansDeletedTotal, &errs)
+	return errors.Join(errs...)
+}
+
+// registerCollector registers *c, or points it at the collector already
+// registered under the same descriptors
+func registerCollector[C prometheus.Collector](registerer prometheus.Registerer, c *C, errs *[]error) {
+	err := registerer.Register(*c)
+	if err == nil {
+		return
+	}
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		if existing, ok := already.ExistingCollector.(C); ok {
+			*c = existing
+			return
+		}
+	}
+	*errs = append(*errs, err)
+}
+
+// RecordAPIRequest records an API request made for account with its
+// duration and status
+func (m *Metrics) RecordAPIRequest(account, operation string, duration float64, success bool) {
 	status := "success"
 	if !success {
 		status = "error"
 	}
-	APIRequestDuration.WithLabelValues(operation).Observe(duration)
-	APIRequestsTotal.WithLabelValues(operation, status).Inc()
+	m.APIRequestDuration.WithLabelValues(operation, account).Observe(duration)
+	m.APIRequestsTotal.WithLabelValues(operation, status, account).Inc()
+}
+
+// RecordAPIRetry records a retried API request made for account with the
+// reason it was retried
+func (m *Metrics) RecordAPIRetry(account, reason string) {
+	m.APIRetriesTotal.WithLabelValues(reason, account).Inc()
+}
+
+// RecordListEntriesChanged records the entries a sync added to and removed
+// from a NextDNS list
+func (m *Metrics) RecordListEntriesChanged(listType string, added, removed int) {
+	if added > 0 {
+		m.ListEntriesAddedTotal.WithLabelValues(listType).Add(float64(added))
+	}
+	if removed > 0 {
+		m.ListEntriesRemovedTotal.WithLabelValues(listType).Add(float64(removed))
+	}
 }
 
 // RecordProfileSync records a successful profile sync
-func RecordProfileSync(profile, namespace string) {
-	ProfilesSyncedTotal.WithLabelValues(profile, namespace).Inc()
+func (m *Metrics) RecordProfileSync(profile, namespace string) {
+	m.ProfilesSyncedTotal.WithLabelValues(profile, namespace).Inc()
 }
 
 // RecordProfileSyncError records a failed profile sync
-func RecordProfileSyncError(profile, namespace, reason string) {
-	ProfilesSyncErrorsTotal.WithLabelValues(profile, namespace, reason).Inc()
+func (m *Metrics) RecordProfileSyncError(profile, namespace, reason string) {
+	m.ProfilesSyncErrorsTotal.WithLabelValues(profile, namespace, reason).Inc()
+}
+
+// RecordCrossNamespaceAccessDenied records a list reference the operator was
+// not allowed to read
+func (m *Metrics) RecordCrossNamespaceAccessDenied(profile, namespace string) {
+	m.CrossNamespaceAccessDeniedTotal.WithLabelValues(profile, namespace).Inc()
+}
+
+// RecordSoakTestLookup records a soak test lookup of the NextDNSCoreDNS
+// name through resolver, "nextdns" or "legacy", with its result and
+// duration
+func (m *Metrics) RecordSoakTestLookup(name, namespace, resolver, result string, duration float64) {
+	m.SoakTestLookupsTotal.WithLabelValues(name, namespace, resolver, result).Inc()
+	m.SoakTestLookupDuration.WithLabelValues(name, namespace, resolver).Observe(duration)
+}
+
+// RecordSoakTestDivergence records a soak test lookup of the NextDNSCoreDNS
+// name whose results differed between the resolvers
+func (m *Metrics) RecordSoakTestDivergence(name, namespace string) {
+	m.SoakTestDivergencesTotal.WithLabelValues(name, namespace).Inc()
+}
+
+// RecordCoreDNSReplicas records the pod counts of the NextDNSCoreDNS name
+func (m *Metrics) RecordCoreDNSReplicas(name, namespace string, desired, ready, available int32) {
+	m.CoreDNSReplicasDesired.WithLabelValues(name, namespace).Set(float64(desired))
+	m.CoreDNSReplicasReady.WithLabelValues(name, namespace).Set(float64(ready))
+	m.CoreDNSReplicasAvailable.WithLabelValues(name, namespace).Set(float64(available))
+}
+
+// RecordCoreDNSReconcile records the readiness of the NextDNSCoreDNS name
+// after a reconcile and the duration of the reconcile
+func (m *Metrics) RecordCoreDNSReconcile(name, namespace string, ready bool, duration float64) {
+	readyValue := 0.0
+	if ready {
+		readyValue = 1
+	}
+	m.CoreDNSReady.WithLabelValues(name, namespace).Set(readyValue)
+	m.CoreDNSReconcileDuration.WithLabelValues(name, namespace).Set(duration)
+}
+
+// RecordCoreDNSCorefileError records a reconcile of the NextDNSCoreDNS name
+// whose Corefile could not be generated
+func (m *Metrics) RecordCoreDNSCorefileError(name, namespace string) {
+	m.CoreDNSCorefileErrorsTotal.WithLabelValues(name, namespace).Inc()
+}
+
+// DeleteCoreDNS removes the series of the deleted NextDNSCoreDNS name, so
+// dashboards do not alert on instances that no longer exist
+func (m *Metrics) DeleteCoreDNS(name, namespace string) {
+	labels := prometheus.Labels{"coredns": name, "namespace": namespace}
+	m.CoreDNSReady.DeletePartialMatch(labels)
+	m.CoreDNSReplicasDesired.DeletePartialMatch(labels)
+	m.CoreDNSReplicasReady.DeletePartialMatch(labels)
+	m.CoreDNSReplicasAvailable.DeletePartialMatch(labels)
+	m.CoreDNSReconcileDuration.DeletePartialMatch(labels)
+	m.CoreDNSCorefileErrorsTotal.DeletePartialMatch(labels)
+	m.SoakTestLookupsTotal.DeletePartialMatch(labels)
+	m.SoakTestLookupDuration.DeletePartialMatch(labels)
+	m.SoakTestDivergencesTotal.DeletePartialMatch(labels)
+}
+
+// RecordAccountOrphans records the number of orphaned profiles found in the
+// NextDNSAccount name
+func (m *Metrics) RecordAccountOrphans(name, namespace string, orphaned int) {
+	m.AccountOrphanedProfiles.WithLabelValues(name, namespace).Set(float64(orphaned))
+}
+
+// RecordAccountOrphanDeleted records an orphaned profile deleted from the
+// NextDNSAccount name
+func (m *Metrics) RecordAccountOrphanDeleted(name, namespace string) {
+	m.AccountOrphansDeletedTotal.WithLabelValues(name, namespace).Inc()
+}
+
+// DeleteAccount removes the series of the deleted NextDNSAccount name
+func (m *Metrics) DeleteAccount(name, namespace string) {
+	labels := prometheus.Labels{"nextdnsaccount": name, "namespace": namespace}
+	m.AccountOrphanedProfiles.DeletePartialMatch(labels)
+	m.AccountOrphansDeletedTotal.DeletePartialMatch(labels)
 }
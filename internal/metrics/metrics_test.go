@@ -4,75 +4,177 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestMetrics returns metrics registered with a fresh registry
+func newTestMetrics(t *testing.T) *Metrics {
+	t.Helper()
+	m, err := New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	return m
+}
+
 func TestRecordAPIRequest_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
 	// RecordAPIRequest should not panic regardless of input
 	assert.NotPanics(t, func() {
-		RecordAPIRequest("get-profile", 0.123, true)
+		m.RecordAPIRequest("0123456789ab", "get-profile", 0.123, true)
 	})
 	assert.NotPanics(t, func() {
-		RecordAPIRequest("update-profile", 1.5, false)
+		m.RecordAPIRequest("0123456789ab", "update-profile", 1.5, false)
 	})
 	assert.NotPanics(t, func() {
-		RecordAPIRequest("", 0, true)
+		m.RecordAPIRequest("0123456789ab", "", 0, true)
 	})
 }
 
 func TestRecordProfileSync_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
 	assert.NotPanics(t, func() {
-		RecordProfileSync("my-profile", "default")
+		m.RecordProfileSync("my-profile", "default")
 	})
 	assert.NotPanics(t, func() {
-		RecordProfileSync("", "")
+		m.RecordProfileSync("", "")
 	})
 }
 
 func TestRecordProfileSyncError_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
 	assert.NotPanics(t, func() {
-		RecordProfileSyncError("my-profile", "default", "api-error")
+		m.RecordProfileSyncError("my-profile", "default", "api-error")
 	})
 	assert.NotPanics(t, func() {
-		RecordProfileSyncError("", "", "")
+		m.RecordProfileSyncError("", "", "")
+	})
+}
+
+func TestRecordCrossNamespaceAccessDenied_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
+	assert.NotPanics(t, func() {
+		m.RecordCrossNamespaceAccessDenied("my-profile", "default")
+	})
+}
+
+func TestRecordAPIRetry_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
+	assert.NotPanics(t, func() {
+		m.RecordAPIRetry("0123456789ab", "rate_limited")
 	})
+	assert.NotPanics(t, func() {
+		m.RecordAPIRetry("0123456789ab", "server_error")
+	})
+}
+
+func TestRecordSoakTest(t *testing.T) {
+	m := newTestMetrics(t)
+	m.RecordSoakTestLookup("home-dns", "default", "nextdns", "Blocked", 0.012)
+	m.RecordSoakTestLookup("home-dns", "default", "legacy", "Resolved", 0.004)
+	m.RecordSoakTestDivergence("home-dns", "default")
+
+	lookups, err := m.SoakTestLookupsTotal.GetMetricWithLabelValues("home-dns", "default", "nextdns", "Blocked")
+	require.NoError(t, err)
+	assert.NotNil(t, lookups)
+
+	divergences, err := m.SoakTestDivergencesTotal.GetMetricWithLabelValues("home-dns", "default")
+	require.NoError(t, err)
+	assert.NotNil(t, divergences)
+}
+
+func TestRecordCoreDNS(t *testing.T) {
+	m := newTestMetrics(t)
+	m.RecordCoreDNSReplicas("home-dns", "default", 3, 2, 1)
+	m.RecordCoreDNSReconcile("home-dns", "default", false, 0.25)
+	m.RecordCoreDNSCorefileError("home-dns", "default")
+	m.RecordSoakTestDivergence("home-dns", "default")
+	m.RecordCoreDNSReconcile("other-dns", "default", true, 0.1)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.CoreDNSReady.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.CoreDNSReplicasDesired.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.CoreDNSReplicasReady.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CoreDNSReplicasAvailable.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 0.25, testutil.ToFloat64(m.CoreDNSReconcileDuration.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CoreDNSCorefileErrorsTotal.WithLabelValues("home-dns", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CoreDNSReady.WithLabelValues("other-dns", "default")))
+
+	// Deleting an instance removes only its series
+	m.DeleteCoreDNS("home-dns", "default")
+	assert.Equal(t, 1, testutil.CollectAndCount(m.CoreDNSReady))
+	assert.Zero(t, testutil.CollectAndCount(m.CoreDNSReplicasDesired))
+	assert.Zero(t, testutil.CollectAndCount(m.CoreDNSCorefileErrorsTotal))
+	assert.Zero(t, testutil.CollectAndCount(m.SoakTestDivergencesTotal))
+}
+
+func TestRecordAccountOrphans(t *testing.T) {
+	m := newTestMetrics(t)
+	m.RecordAccountOrphans("family", "default", 2)
+	m.RecordAccountOrphanDeleted("family", "default")
+	m.RecordAccountOrphans("work", "default", 0)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.AccountOrphanedProfiles.WithLabelValues("family", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.AccountOrphansDeletedTotal.WithLabelValues("family", "default")))
+
+	m.DeleteAccount("family", "default")
+	assert.Equal(t, 1, testutil.CollectAndCount(m.AccountOrphanedProfiles))
+	assert.Zero(t, testutil.CollectAndCount(m.AccountOrphansDeletedTotal))
 }
 
 func TestGaugeMetrics_NoPanic(t *testing.T) {
+	m := newTestMetrics(t)
+
 	// Setting gauge values should not panic
 	assert.NotPanics(t, func() {
-		ProfilesTotal.Set(5)
+		m.ProfilesTotal.Set(5)
+	})
+	assert.NotPanics(t, func() {
+		m.AllowlistsTotal.Set(3)
 	})
 	assert.NotPanics(t, func() {
-		AllowlistsTotal.Set(3)
+		m.DenylistsTotal.Set(7)
 	})
 	assert.NotPanics(t, func() {
-		DenylistsTotal.Set(7)
+		m.TLDListsTotal.Set(2)
 	})
 	assert.NotPanics(t, func() {
-		TLDListsTotal.Set(2)
+		m.RewritesTotal.Set(4)
 	})
 }
 
 func TestMetricsAreRegistered(t *testing.T) {
 	// Verify all metrics are collectors (implement prometheus.Collector)
-	// The init() function registers them; if registration had failed,
-	// it would have panicked via MustRegister.
-	// Here we verify the metrics are valid collectors by describing them.
+	// by describing them
+	m := newTestMetrics(t)
 
 	collectors := []struct {
 		name      string
 		collector prometheus.Collector
 	}{
-		{"ProfilesTotal", ProfilesTotal},
-		{"ProfilesSyncedTotal", ProfilesSyncedTotal},
-		{"ProfilesSyncErrorsTotal", ProfilesSyncErrorsTotal},
-		{"APIRequestDuration", APIRequestDuration},
-		{"APIRequestsTotal", APIRequestsTotal},
-		{"AllowlistsTotal", AllowlistsTotal},
-		{"DenylistsTotal", DenylistsTotal},
-		{"TLDListsTotal", TLDListsTotal},
+		{"ProfilesTotal", m.ProfilesTotal},
+		{"ProfilesSyncedTotal", m.ProfilesSyncedTotal},
+		{"ProfilesSyncErrorsTotal", m.ProfilesSyncErrorsTotal},
+		{"CrossNamespaceAccessDeniedTotal", m.CrossNamespaceAccessDeniedTotal},
+		{"APIRequestDuration", m.APIRequestDuration},
+		{"APIRequestsTotal", m.APIRequestsTotal},
+		{"APIRetriesTotal", m.APIRetriesTotal},
+		{"ListEntriesAddedTotal", m.ListEntriesAddedTotal},
+		{"ListEntriesRemovedTotal", m.ListEntriesRemovedTotal},
+		{"AllowlistsTotal", m.AllowlistsTotal},
+		{"DenylistsTotal", m.DenylistsTotal},
+		{"TLDListsTotal", m.TLDListsTotal},
+		{"RewritesTotal", m.RewritesTotal},
+		{"SoakTestLookupsTotal", m.SoakTestLookupsTotal},
+		{"SoakTestLookupDuration", m.SoakTestLookupDuration},
+		{"SoakTestDivergencesTotal", m.SoakTestDivergencesTotal},
+		{"CoreDNSReady", m.CoreDNSReady},
+		{"CoreDNSReplicasDesired", m.CoreDNSReplicasDesired},
+		{"CoreDNSReplicasReady", m.CoreDNSReplicasReady},
+		{"CoreDNSReplicasAvailable", m.CoreDNSReplicasAvailable},
+		{"CoreDNSReconcileDuration", m.CoreDNSReconcileDuration},
+		{"CoreDNSCorefileErrorsTotal", m.CoreDNSCorefileErrorsTotal},
+		{"AccountOrphanedProfiles", m.AccountOrphanedProfiles},
+		{"AccountOrphansDeletedTotal", m.AccountOrphansDeletedTotal},
 	}
 
 	for _, tc := range collectors {
@@ -91,26 +193,60 @@ func TestMetricsAreRegistered(t *testing.T) {
 }
 
 func TestRecordAPIRequest_StatusLabels(t *testing.T) {
+	m := newTestMetrics(t)
 	// Verify that success and error produce distinct counter increments
 	// by calling the function and checking it doesn't error out
-	RecordAPIRequest("test-op-success", 0.05, true)
-	RecordAPIRequest("test-op-error", 0.1, false)
+	m.RecordAPIRequest("0123456789ab", "test-op-success", 0.05, true)
+	m.RecordAPIRequest("0123456789ab", "test-op-error", 0.1, false)
 
 	// Verify the counter vectors can retrieve metrics for both status labels
-	successMetric, err := APIRequestsTotal.GetMetricWithLabelValues("test-op-success", "success")
+	successMetric, err := m.APIRequestsTotal.GetMetricWithLabelValues("test-op-success", "success", "0123456789ab")
 	require.NoError(t, err)
 	assert.NotNil(t, successMetric)
 
-	errorMetric, err := APIRequestsTotal.GetMetricWithLabelValues("test-op-error", "error")
+	errorMetric, err := m.APIRequestsTotal.GetMetricWithLabelValues("test-op-error", "error", "0123456789ab")
 	require.NoError(t, err)
 	assert.NotNil(t, errorMetric)
 }
 
 func TestRecordAPIRequest_DurationObserved(t *testing.T) {
+	m := newTestMetrics(t)
 	// Verify the histogram can retrieve a metric after observation
-	RecordAPIRequest("duration-test", 0.25, true)
+	m.RecordAPIRequest("0123456789ab", "duration-test", 0.25, true)
 
-	observer, err := APIRequestDuration.GetMetricWithLabelValues("duration-test")
+	observer, err := m.APIRequestDuration.GetMetricWithLabelValues("duration-test", "0123456789ab")
 	require.NoError(t, err)
 	assert.NotNil(t, observer)
 }
+
+func TestNew_SharesRegisteredCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first, err := New(registry)
+	require.NoError(t, err)
+
+	// A second manager on the same registry records to the same collectors
+	second, err := New(registry)
+	require.NoError(t, err)
+	assert.Same(t, first.APIRequestsTotal, second.APIRequestsTotal)
+	assert.Same(t, first.ProfilesTotal, second.ProfilesTotal)
+}
+
+func TestNew_ConflictingCollector(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nextdns_profiles_total",
+		Help: "Some other metric",
+	}))
+
+	_, err := New(registry)
+	assert.Error(t, err)
+}
+
+func TestDefault(t *testing.T) {
+	assert.Same(t, Default(), Default())
+
+	// Registering with the controller-runtime registry again does not panic
+	m, err := New(nil)
+	require.NoError(t, err)
+	assert.Same(t, Default().APIRequestsTotal, m.APIRequestsTotal)
+}
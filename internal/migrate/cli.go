@@ -0,0 +1,219 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// Options configures a migration run
+type Options struct {
+	// Format of the input files
+	Format Format
+	// Files are read as blocking rules ("-" reads stdin)
+	Files []string
+	// AllowFiles are read as allow rules (Pi-hole whitelist exports)
+	AllowFiles []string
+	// Resources controls naming of the generated resources
+	Resources ResourceOptions
+	// Apply creates or updates the resources in the cluster instead of
+	// printing them
+	Apply bool
+}
+
+// ParseFlags parses the arguments of the migrate subcommand
+func ParseFlags(args []string, output io.Writer) (*Options, error) {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	var format string
+	var files, allowFiles stringList
+	opts := &Options{}
+	fs.StringVar(&format, "format", "", "Input format (pihole, adguard).")
+	fs.Var(&files, "file", "Input list to translate; repeatable. Use - for stdin.")
+	fs.Var(&allowFiles, "allowlist-file",
+		"Pi-hole whitelist export to translate into allowlist entries; repeatable.")
+	fs.StringVar(&opts.Resources.Name, "name", "migrated", "Base name of the generated list resources.")
+	fs.StringVar(&opts.Resources.Namespace, "namespace", "default", "Namespace of the generated list resources.")
+	fs.StringVar(&opts.Resources.Description, "description", "", "Description copied into each generated list.")
+	fs.IntVar(&opts.Resources.ChunkSize, "chunk-size", DefaultChunkSize, "Maximum domains per generated resource.")
+	fs.BoolVar(&opts.Apply, "apply", false,
+		"Create or update the resources in the cluster instead of printing YAML (for running as a Job).")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts.Format = Format(format)
+	opts.Files = files
+	opts.AllowFiles = allowFiles
+
+	if opts.Format != FormatPihole && opts.Format != FormatAdGuard {
+		return nil, fmt.Errorf("--format must be %q or %q", FormatPihole, FormatAdGuard)
+	}
+	if len(opts.Files) == 0 && len(opts.AllowFiles) == 0 {
+		return nil, errors.New("at least one --file or --allowlist-file is required")
+	}
+	if len(opts.AllowFiles) > 0 && opts.Format != FormatPihole {
+		return nil, errors.New("--allowlist-file is only supported for the pihole format")
+	}
+	return opts, nil
+}
+
+// Translate reads all input files of opts and merges them into one Result
+func Translate(opts *Options, stdin io.Reader) (*Result, error) {
+	res := NewResult()
+	read := func(path string, kind ListKind) error {
+		var r io.Reader = stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", path, err)
+			}
+			defer func() { _ = f.Close() }()
+			r = f
+		}
+
+		parsed, err := Parse(opts.Format, kind, r)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for i := range parsed.Unsupported {
+			parsed.Unsupported[i].Source = path
+		}
+		res.Merge(parsed)
+		return nil
+	}
+
+	for _, path := range opts.Files {
+		if err := read(path, KindDenylist); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range opts.AllowFiles {
+		if err := read(path, KindAllowlist); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Objects returns the list resources generated from res
+func Objects(res *Result, opts ResourceOptions) []client.Object {
+	var objs []client.Object
+	for _, l := range res.Denylists(opts) {
+		objs = append(objs, l)
+	}
+	allowOpts := opts
+	if len(res.Denylist) > 0 {
+		// Avoid confusing same-named deny and allow resources
+		allowOpts.Name = opts.Name + "-allow"
+	}
+	for _, l := range res.Allowlists(allowOpts) {
+		objs = append(objs, l)
+	}
+	return objs
+}
+
+// WriteYAML writes objs as a multi-document YAML stream
+func WriteYAML(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply creates or updates objs in the cluster, replacing the spec of
+// existing resources with the translated one. Progress is written to out.
+func Apply(ctx context.Context, c client.Client, objs []client.Object, out io.Writer) error {
+	for _, obj := range objs {
+		desired := obj.DeepCopyObject().(client.Object)
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		op, err := controllerutil.CreateOrUpdate(ctx, c, obj, func() error {
+			return copySpec(desired, obj)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(out, "%s %s/%s %s\n", kind, obj.GetNamespace(), obj.GetName(), op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run executes the migrate subcommand. newClient is only called with --apply.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer,
+	newClient func() (client.Client, error)) error {
+	opts, err := ParseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	res, err := Translate(opts, stdin)
+	if err != nil {
+		return err
+	}
+	if err := res.WriteReport(stderr); err != nil {
+		return err
+	}
+
+	objs := Objects(res, opts.Resources)
+	if !opts.Apply {
+		return WriteYAML(stdout, objs)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return Apply(ctx, c, objs, stderr)
+}
+
+// copySpec copies the translated spec and labels from desired onto obj
+func copySpec(desired, obj client.Object) error {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for k, v := range desired.GetLabels() {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+
+	switch o := obj.(type) {
+	case *nextdnsv1alpha1.NextDNSDenylist:
+		o.Spec = desired.(*nextdnsv1alpha1.NextDNSDenylist).Spec
+	case *nextdnsv1alpha1.NextDNSAllowlist:
+		o.Spec = desired.(*nextdnsv1alpha1.NextDNSAllowlist).Spec
+	default:
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func noClient() (client.Client, error) {
+	return nil, errors.New("client should not be created")
+}
+
+func TestParseFlags_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing format", args: []string{"--file", "x"}, wantErr: "--format"},
+		{name: "unknown format", args: []string{"--format", "unbound", "--file", "x"}, wantErr: "--format"},
+		{name: "missing files", args: []string{"--format", "pihole"}, wantErr: "at least one"},
+		{
+			name:    "allowlist file with adguard",
+			args:    []string{"--format", "adguard", "--file", "x", "--allowlist-file", "y"},
+			wantErr: "only supported for the pihole format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlags(tt.args, &bytes.Buffer{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRun_PrintsYAML(t *testing.T) {
+	dir := t.TempDir()
+	gravity := filepath.Join(dir, "gravity.list")
+	whitelist := filepath.Join(dir, "whitelist.txt")
+	require.NoError(t, os.WriteFile(gravity, []byte("0.0.0.0 ads.example.com\n(^|\\.)bad\\.com$\n"), 0o600))
+	require.NoError(t, os.WriteFile(whitelist, []byte("good.example.com\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), []string{
+		"--format", "pihole",
+		"--file", gravity,
+		"--allowlist-file", whitelist,
+		"--name", "pihole",
+		"--namespace", "dns",
+	}, strings.NewReader(""), &stdout, &stderr, noClient)
+	require.NoError(t, err)
+
+	out := stdout.String()
+	assert.Equal(t, 2, strings.Count(out, "---\n"))
+	assert.Contains(t, out, "kind: NextDNSDenylist")
+	assert.Contains(t, out, "name: pihole\n")
+	assert.Contains(t, out, "kind: NextDNSAllowlist")
+	assert.Contains(t, out, "name: pihole-allow\n")
+	assert.Contains(t, out, "domain: ads.example.com")
+	assert.Contains(t, out, "domain: good.example.com")
+
+	assert.Contains(t, stderr.String(), "translated 1 denylist and 1 allowlist domains, 1 unsupported rules")
+	assert.Contains(t, stderr.String(), gravity+":2: regex rules are not supported by NextDNS")
+}
+
+func TestRun_ReadsStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), []string{"--format", "adguard", "--file", "-"},
+		strings.NewReader("||ads.example.com^\n"), &stdout, &stderr, noClient)
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout.String(), "name: migrated\n")
+	assert.Contains(t, stdout.String(), "domain: ads.example.com")
+}
+
+func TestRun_MissingFile(t *testing.T) {
+	err := Run(context.Background(), []string{"--format", "adguard", "--file", "/does/not/exist"},
+		strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, noClient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open")
+}
+
+func TestRun_Apply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(nextdnsv1alpha1.AddToScheme(scheme))
+
+	existing := &nextdnsv1alpha1.NextDNSDenylist{}
+	existing.Name = "migrated"
+	existing.Namespace = "default"
+	existing.Labels = map[string]string{"team": "home"}
+	existing.Spec.Domains = []nextdnsv1alpha1.DomainEntry{{Domain: "old.example.com"}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+
+	var stderr bytes.Buffer
+	err := Run(context.Background(), []string{"--format", "adguard", "--file", "-", "--apply"},
+		strings.NewReader("||ads.example.com^\n@@||good.example.com^\n"), &bytes.Buffer{}, &stderr,
+		func() (client.Client, error) { return fakeClient, nil })
+	require.NoError(t, err)
+
+	var deny nextdnsv1alpha1.NextDNSDenylist
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "migrated", Namespace: "default"}, &deny))
+	assert.Equal(t, []string{"ads.example.com"}, domains(deny.Spec.Domains))
+	assert.Equal(t, "home", deny.Labels["team"])
+	assert.Equal(t, "nextdns-operator-migrate", deny.Labels["app.kubernetes.io/created-by"])
+
+	var allow nextdnsv1alpha1.NextDNSAllowlist
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "migrated-allow", Namespace: "default"}, &allow))
+	assert.Equal(t, []string{"good.example.com"}, domains(allow.Spec.Domains))
+
+	assert.Contains(t, stderr.String(), "NextDNSDenylist default/migrated updated")
+	assert.Contains(t, stderr.String(), "NextDNSAllowlist default/migrated-allow created")
+}
@@ -0,0 +1,326 @@
+// Package migrate translates Pi-hole and AdGuard Home block and allow lists
+// into NextDNSDenylist and NextDNSAllowlist resources.
+//
+// Translation is best-effort: NextDNS lists only match domains (and their
+// subdomains), so regex, cosmetic, URL and modifier-based rules have no
+// equivalent. Such rules are collected in Result.Unsupported instead of being
+// silently dropped.
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// Format identifies the source ad-blocker of an input list
+type Format string
+
+const (
+	// FormatPihole reads Pi-hole gravity lists (hosts or plain domain format)
+	FormatPihole Format = "pihole"
+	// FormatAdGuard reads AdGuard Home filtering rules
+	FormatAdGuard Format = "adguard"
+)
+
+// ListKind selects whether plain entries are treated as blocked or allowed
+type ListKind string
+
+const (
+	// KindDenylist treats entries as domains to block
+	KindDenylist ListKind = "denylist"
+	// KindAllowlist treats entries as domains to allow
+	KindAllowlist ListKind = "allowlist"
+)
+
+// domainPattern mirrors the DomainEntry CRD validation pattern so generated
+// resources are accepted by the API server.
+var domainPattern = regexp.MustCompile(`^(\*\.)?([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`)
+
+// hostsPlaceholders are hostnames found in hosts-format lists that describe
+// the local machine rather than something to block.
+var hostsPlaceholders = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+// UnsupportedRule records an input rule that could not be translated
+type UnsupportedRule struct {
+	// Source is the input the rule was read from (file name or "-")
+	Source string
+	// Line is the 1-based line number within Source
+	Line int
+	// Rule is the original rule text
+	Rule string
+	// Reason explains why the rule was skipped
+	Reason string
+}
+
+// Result holds the translated entries of one or more input lists
+type Result struct {
+	Denylist    []nextdnsv1alpha1.DomainEntry
+	Allowlist   []nextdnsv1alpha1.DomainEntry
+	Unsupported []UnsupportedRule
+
+	denySeen  map[string]bool
+	allowSeen map[string]bool
+}
+
+// NewResult returns an empty Result
+func NewResult() *Result {
+	return &Result{
+		denySeen:  make(map[string]bool),
+		allowSeen: make(map[string]bool),
+	}
+}
+
+// add appends a domain to the list selected by kind, skipping duplicates
+func (r *Result) add(kind ListKind, domain string) {
+	if r.denySeen == nil {
+		r.denySeen = make(map[string]bool)
+	}
+	if r.allowSeen == nil {
+		r.allowSeen = make(map[string]bool)
+	}
+
+	entry := nextdnsv1alpha1.DomainEntry{Domain: domain, Active: ptr.To(true)}
+	switch kind {
+	case KindAllowlist:
+		if r.allowSeen[domain] {
+			return
+		}
+		r.allowSeen[domain] = true
+		r.Allowlist = append(r.Allowlist, entry)
+	default:
+		if r.denySeen[domain] {
+			return
+		}
+		r.denySeen[domain] = true
+		r.Denylist = append(r.Denylist, entry)
+	}
+}
+
+// Merge adds all entries and unsupported rules from other into r
+func (r *Result) Merge(other *Result) {
+	if other == nil {
+		return
+	}
+	for _, e := range other.Denylist {
+		r.add(KindDenylist, e.Domain)
+	}
+	for _, e := range other.Allowlist {
+		r.add(KindAllowlist, e.Domain)
+	}
+	r.Unsupported = append(r.Unsupported, other.Unsupported...)
+}
+
+// unsupported records a rule that could not be translated
+func (r *Result) unsupported(line int, rule, reason string) {
+	r.Unsupported = append(r.Unsupported, UnsupportedRule{Line: line, Rule: rule, Reason: reason})
+}
+
+// WriteReport writes a human-readable summary of the translation to w
+func (r *Result) WriteReport(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "translated %d denylist and %d allowlist domains, %d unsupported rules\n",
+		len(r.Denylist), len(r.Allowlist), len(r.Unsupported)); err != nil {
+		return err
+	}
+	for _, u := range r.Unsupported {
+		source := u.Source
+		if source == "" {
+			source = "-"
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d: %s: %s\n", source, u.Line, u.Reason, u.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse reads rules in the given format. kind is only used for Pi-hole lists,
+// which do not mark allow rules themselves.
+func Parse(format Format, kind ListKind, r io.Reader) (*Result, error) {
+	switch format {
+	case FormatPihole:
+		return ParsePihole(r, kind)
+	case FormatAdGuard:
+		return ParseAdGuard(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected %q or %q)", format, FormatPihole, FormatAdGuard)
+	}
+}
+
+// ParsePihole reads a Pi-hole gravity list, whitelist or blacklist export.
+// Both hosts format ("0.0.0.0 ads.example.com") and one domain per line are
+// accepted. Regex entries are reported as unsupported.
+func ParsePihole(r io.Reader, kind ListKind) (*Result, error) {
+	res := NewResult()
+	err := scanLines(r, func(lineNo int, line string) {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 1 {
+			if net.ParseIP(fields[0]) == nil {
+				res.unsupported(lineNo, line, "unrecognized line format")
+				return
+			}
+			for _, host := range fields[1:] {
+				addDomain(res, kind, lineNo, host)
+			}
+			return
+		}
+
+		if isRegexRule(fields[0]) {
+			res.unsupported(lineNo, line, "regex rules are not supported by NextDNS")
+			return
+		}
+		addDomain(res, kind, lineNo, fields[0])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseAdGuard reads AdGuard Home filtering rules. Basic domain rules
+// ("||example.com^"), exceptions ("@@||example.com^"), hosts-format lines and
+// plain domains are translated; everything else is reported as unsupported.
+func ParseAdGuard(r io.Reader) (*Result, error) {
+	res := NewResult()
+	err := scanLines(r, func(lineNo int, line string) {
+		if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "[") {
+			return
+		}
+		if strings.HasPrefix(line, "#") && !isCosmeticRule(line) {
+			return
+		}
+		if isCosmeticRule(line) {
+			res.unsupported(lineNo, line, "cosmetic rules are not supported by NextDNS")
+			return
+		}
+
+		rule := line
+		kind := KindDenylist
+		if strings.HasPrefix(rule, "@@") {
+			kind = KindAllowlist
+			rule = rule[2:]
+		}
+
+		if len(rule) > 1 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/") {
+			res.unsupported(lineNo, line, "regex rules are not supported by NextDNS")
+			return
+		}
+
+		if i := strings.Index(rule, "$"); i >= 0 {
+			for _, mod := range strings.Split(rule[i+1:], ",") {
+				// $important only changes rule priority, which has no
+				// NextDNS equivalent and is safe to drop.
+				if mod != "important" {
+					res.unsupported(lineNo, line, fmt.Sprintf("modifier %q is not supported by NextDNS", mod))
+					return
+				}
+			}
+			rule = rule[:i]
+		}
+
+		if fields := strings.Fields(rule); len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			for _, host := range fields[1:] {
+				if strings.HasPrefix(host, "#") {
+					break
+				}
+				addDomain(res, kind, lineNo, host)
+			}
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(rule, "||"):
+			rule = rule[2:]
+		case strings.HasPrefix(rule, "|"):
+			res.unsupported(lineNo, line, "URL rules are not supported by NextDNS")
+			return
+		}
+		rule = strings.TrimSuffix(rule, "|")
+		rule = strings.TrimSuffix(rule, "^")
+
+		if strings.ContainsAny(rule, "/:^|") {
+			res.unsupported(lineNo, line, "URL rules are not supported by NextDNS")
+			return
+		}
+		if strings.Contains(strings.TrimPrefix(rule, "*."), "*") {
+			res.unsupported(lineNo, line, "partial wildcard rules are not supported by NextDNS")
+			return
+		}
+		addDomain(res, kind, lineNo, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// scanLines calls fn for every trimmed line of r with its 1-based number
+func scanLines(r io.Reader, fn func(lineNo int, line string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fn(lineNo, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return nil
+}
+
+// addDomain validates and normalizes a domain before adding it to res
+func addDomain(res *Result, kind ListKind, lineNo int, raw string) {
+	domain := strings.TrimSuffix(strings.ToLower(raw), ".")
+	if hostsPlaceholders[domain] {
+		return
+	}
+	if len(domain) > 253 || !domainPattern.MatchString(domain) {
+		res.unsupported(lineNo, raw, "invalid domain")
+		return
+	}
+	res.add(kind, domain)
+}
+
+// isRegexRule reports whether a Pi-hole entry uses regex syntax
+func isRegexRule(s string) bool {
+	return strings.ContainsAny(s, `^$()[]{}|\+?`)
+}
+
+// isCosmeticRule reports whether an AdGuard rule is an element-hiding or
+// scriptlet rule, which only apply inside a browser
+func isCosmeticRule(s string) bool {
+	for _, marker := range []string{"##", "#@#", "#?#", "#$#", "#%#", "#@$#", "#@%#", "#@?#"} {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
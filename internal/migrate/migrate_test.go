@@ -0,0 +1,162 @@
+package migrate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func domains(entries []nextdnsv1alpha1.DomainEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Domain)
+	}
+	return out
+}
+
+func TestParsePihole(t *testing.T) {
+	input := `# StevenBlack hosts
+127.0.0.1 localhost
+::1 ip6-localhost
+0.0.0.0 0.0.0.0
+0.0.0.0 ads.example.com
+0.0.0.0 tracker.example.com  # inline comment
+Ads.Example.com
+plain.example.org.
+(^|\.)doubleclick\.net$
+not_a_domain
+foo bar
+`
+	res, err := ParsePihole(strings.NewReader(input), KindDenylist)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ads.example.com", "tracker.example.com", "plain.example.org"}, domains(res.Denylist))
+	assert.Empty(t, res.Allowlist)
+	require.Len(t, res.Unsupported, 3)
+	assert.Equal(t, 9, res.Unsupported[0].Line)
+	assert.Contains(t, res.Unsupported[0].Reason, "regex")
+	assert.Equal(t, "invalid domain", res.Unsupported[1].Reason)
+	assert.Equal(t, "unrecognized line format", res.Unsupported[2].Reason)
+
+	for _, e := range res.Denylist {
+		require.NotNil(t, e.Active)
+		assert.True(t, *e.Active)
+	}
+}
+
+func TestParsePihole_Allowlist(t *testing.T) {
+	res, err := ParsePihole(strings.NewReader("good.example.com\n"), KindAllowlist)
+	require.NoError(t, err)
+
+	assert.Empty(t, res.Denylist)
+	assert.Equal(t, []string{"good.example.com"}, domains(res.Allowlist))
+}
+
+func TestParseAdGuard(t *testing.T) {
+	input := `! Title: AdGuard DNS filter
+[Adblock Plus 2.0]
+# comment
+||ads.example.com^
+||tracker.example.com^$important
+@@||good.example.com^
+@@||cdn.example.com^|
+0.0.0.0 hosts.example.com
+plain.example.org
+||*.wild.example.com^
+example.com##.banner
+/ads[0-9]+\.example\.com/
+||third.example.com^$third-party
+|https://example.com/ads|
+||example.com/path^
+||ad*.example.com^
+`
+	res, err := ParseAdGuard(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"ads.example.com",
+		"tracker.example.com",
+		"hosts.example.com",
+		"plain.example.org",
+		"*.wild.example.com",
+	}, domains(res.Denylist))
+	assert.Equal(t, []string{"good.example.com", "cdn.example.com"}, domains(res.Allowlist))
+
+	reasons := make([]string, 0, len(res.Unsupported))
+	for _, u := range res.Unsupported {
+		reasons = append(reasons, u.Reason)
+	}
+	assert.Equal(t, []string{
+		"cosmetic rules are not supported by NextDNS",
+		"regex rules are not supported by NextDNS",
+		`modifier "third-party" is not supported by NextDNS`,
+		"URL rules are not supported by NextDNS",
+		"URL rules are not supported by NextDNS",
+		"partial wildcard rules are not supported by NextDNS",
+	}, reasons)
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	_, err := Parse("unbound", KindDenylist, strings.NewReader(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+func TestResult_MergeDeduplicates(t *testing.T) {
+	a, err := ParseAdGuard(strings.NewReader("||a.example.com^\n@@||b.example.com^\n"))
+	require.NoError(t, err)
+	b, err := ParseAdGuard(strings.NewReader("||a.example.com^\n||c.example.com^\n/regex/\n"))
+	require.NoError(t, err)
+
+	res := NewResult()
+	res.Merge(a)
+	res.Merge(b)
+	res.Merge(nil)
+
+	assert.Equal(t, []string{"a.example.com", "c.example.com"}, domains(res.Denylist))
+	assert.Equal(t, []string{"b.example.com"}, domains(res.Allowlist))
+	assert.Len(t, res.Unsupported, 1)
+}
+
+func TestResult_WriteReport(t *testing.T) {
+	res, err := ParseAdGuard(strings.NewReader("||a.example.com^\nexample.com##.ad\n"))
+	require.NoError(t, err)
+	res.Unsupported[0].Source = "filters.txt"
+
+	var buf bytes.Buffer
+	require.NoError(t, res.WriteReport(&buf))
+
+	assert.Equal(t, "translated 1 denylist and 0 allowlist domains, 1 unsupported rules\n"+
+		"filters.txt:2: cosmetic rules are not supported by NextDNS: example.com##.ad\n", buf.String())
+}
+
+func TestResult_DenylistsChunking(t *testing.T) {
+	res := NewResult()
+	for _, d := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		res.add(KindDenylist, d)
+	}
+
+	lists := res.Denylists(ResourceOptions{Name: "gravity", Namespace: "dns", ChunkSize: 2})
+	require.Len(t, lists, 2)
+	assert.Equal(t, "gravity-1", lists[0].Name)
+	assert.Equal(t, "gravity-2", lists[1].Name)
+	assert.Equal(t, "dns", lists[0].Namespace)
+	assert.Equal(t, "NextDNSDenylist", lists[0].Kind)
+	assert.Equal(t, nextdnsv1alpha1.GroupVersion.String(), lists[0].APIVersion)
+	assert.Len(t, lists[0].Spec.Domains, 2)
+	assert.Len(t, lists[1].Spec.Domains, 1)
+
+	single := res.Denylists(ResourceOptions{Name: "gravity"})
+	require.Len(t, single, 1)
+	assert.Equal(t, "gravity", single[0].Name)
+	assert.Len(t, single[0].Spec.Domains, 3)
+}
+
+func TestResult_AllowlistsEmpty(t *testing.T) {
+	assert.Nil(t, NewResult().Allowlists(ResourceOptions{Name: "empty"}))
+}
@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+// DefaultChunkSize is the maximum number of domains written to a single list
+// resource. Gravity lists routinely contain hundreds of thousands of domains,
+// which would exceed the etcd object size limit in one resource.
+const DefaultChunkSize = 5000
+
+// ResourceOptions controls how translated entries are turned into resources
+type ResourceOptions struct {
+	// Name is the base resource name; chunks are suffixed with -1, -2, ...
+	Name string
+	// Namespace of the generated resources
+	Namespace string
+	// Description is copied into spec.description
+	Description string
+	// ChunkSize caps the domains per resource (DefaultChunkSize if <= 0)
+	ChunkSize int
+}
+
+// Denylists builds NextDNSDenylist resources from the result's blocked domains.
+// It returns nil when there is nothing to block.
+func (r *Result) Denylists(opts ResourceOptions) []*nextdnsv1alpha1.NextDNSDenylist {
+	var lists []*nextdnsv1alpha1.NextDNSDenylist
+	for i, chunk := range chunkEntries(r.Denylist, opts.ChunkSize) {
+		lists = append(lists, &nextdnsv1alpha1.NextDNSDenylist{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: nextdnsv1alpha1.GroupVersion.String(),
+				Kind:       "NextDNSDenylist",
+			},
+			ObjectMeta: objectMeta(opts, i, len(r.Denylist)),
+			Spec: nextdnsv1alpha1.NextDNSDenylistSpec{
+				Description: opts.Description,
+				Domains:     chunk,
+			},
+		})
+	}
+	return lists
+}
+
+// Allowlists builds NextDNSAllowlist resources from the result's allowed
+// domains. It returns nil when there is nothing to allow.
+func (r *Result) Allowlists(opts ResourceOptions) []*nextdnsv1alpha1.NextDNSAllowlist {
+	var lists []*nextdnsv1alpha1.NextDNSAllowlist
+	for i, chunk := range chunkEntries(r.Allowlist, opts.ChunkSize) {
+		lists = append(lists, &nextdnsv1alpha1.NextDNSAllowlist{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: nextdnsv1alpha1.GroupVersion.String(),
+				Kind:       "NextDNSAllowlist",
+			},
+			ObjectMeta: objectMeta(opts, i, len(r.Allowlist)),
+			Spec: nextdnsv1alpha1.NextDNSAllowlistSpec{
+				Description: opts.Description,
+				Domains:     chunk,
+			},
+		})
+	}
+	return lists
+}
+
+// objectMeta names chunk i of a list; single-chunk lists keep the base name
+func objectMeta(opts ResourceOptions, i, total int) metav1.ObjectMeta {
+	name := opts.Name
+	if total > chunkSize(opts.ChunkSize) {
+		name = fmt.Sprintf("%s-%d", opts.Name, i+1)
+	}
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: opts.Namespace,
+		Labels: map[string]string{
+			"app.kubernetes.io/created-by": "nextdns-operator-migrate",
+		},
+	}
+}
+
+// chunkEntries splits entries into slices of at most size elements
+func chunkEntries(entries []nextdnsv1alpha1.DomainEntry, size int) [][]nextdnsv1alpha1.DomainEntry {
+	size = chunkSize(size)
+	var chunks [][]nextdnsv1alpha1.DomainEntry
+	for start := 0; start < len(entries); start += size {
+		end := min(start+size, len(entries))
+		chunks = append(chunks, entries[start:end])
+	}
+	return chunks
+}
+
+// chunkSize applies DefaultChunkSize to non-positive sizes
+func chunkSize(size int) int {
+	if size <= 0 {
+		return DefaultChunkSize
+	}
+	return size
+}
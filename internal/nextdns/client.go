@@ -11,19 +11,26 @@ import (
 
 // Client wraps the NextDNS API client
 type Client struct {
-	client *nextdns.Client
+	client  *nextdns.Client
+	metrics *metrics.Metrics
+
+	// account is the fingerprint of the API key's account, used as the
+	// metrics label
+	account string
 }
 
 // NewClient creates a new NextDNS API client
 func NewClient(apiKey string) (*Client, error) {
 	client, err := nextdns.New(
+		// The HTTP client must be set first: WithAPIKey wraps its transport
+		nextdns.WithHTTPClient(newHTTPClient(apiKey)),
 		nextdns.WithAPIKey(nextdns.Secret(apiKey)),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create NextDNS client: %w", err)
 	}
 
-	return &Client{client: client}, nil
+	return &Client{client: client, metrics: clientMetrics(), account: AccountFingerprint(apiKey)}, nil
 }
 
 // ProfileConfig represents the configuration for a NextDNS profile
@@ -108,7 +115,7 @@ func (c *Client) CreateProfile(ctx context.Context, name string) (string, error)
 
 	profileID, err := c.client.Profiles.Create(ctx, request)
 	duration := time.Since(start).Seconds()
-	metrics.RecordAPIRequest("CreateProfile", duration, err == nil)
+	c.metrics.RecordAPIRequest(c.account, "CreateProfile", duration, err == nil)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to create profile: %w", err)
@@ -125,7 +132,7 @@ func (c *Client) GetProfile(ctx context.Context, profileID string) (*nextdns.Pro
 	}
 
 	profile, err := c.client.Profiles.Get(ctx, request)
-	metrics.RecordAPIRequest("GetProfile", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetProfile", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
@@ -145,7 +152,7 @@ func (c *Client) UpdateProfile(ctx context.Context, profileID, name string) erro
 	}
 
 	err := c.client.Profiles.Update(ctx, request)
-	metrics.RecordAPIRequest("UpdateProfile", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "UpdateProfile", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return fmt.Errorf("failed to update profile: %w", err)
@@ -154,6 +161,41 @@ func (c *Client) UpdateProfile(ctx context.Context, profileID, name string) erro
 	return nil
 }
 
+// ListProfiles returns every profile in the account, following pagination
+func (c *Client) ListProfiles(ctx context.Context) ([]*nextdns.ProfileSummary, error) {
+	var profiles []*nextdns.ProfileSummary
+	request := &nextdns.ListProfileRequest{}
+	for {
+		start := time.Now()
+		response, err := c.client.Profiles.List(ctx, request)
+		c.metrics.RecordAPIRequest(c.account, "ListProfiles", time.Since(start).Seconds(), err == nil)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list profiles: %w", err)
+		}
+
+		profiles = append(profiles, response.Profiles...)
+		if response.Cursor == "" {
+			return profiles, nil
+		}
+		request.Cursor = response.Cursor
+	}
+}
+
+// ValidateKey checks that the API key is accepted by reading the first page
+// of the account's profiles, the cheapest authenticated request
+func (c *Client) ValidateKey(ctx context.Context) error {
+	start := time.Now()
+	_, err := c.client.Profiles.List(ctx, &nextdns.ListProfileRequest{})
+	c.metrics.RecordAPIRequest(c.account, "ValidateKey", time.Since(start).Seconds(), err == nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to validate API key: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteProfile deletes a NextDNS profile
 func (c *Client) DeleteProfile(ctx context.Context, profileID string) error {
 	start := time.Now()
@@ -162,7 +204,7 @@ func (c *Client) DeleteProfile(ctx context.Context, profileID string) error {
 	}
 
 	err := c.client.Profiles.Delete(ctx, request)
-	metrics.RecordAPIRequest("DeleteProfile", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "DeleteProfile", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return fmt.Errorf("failed to delete profile: %w", err)
@@ -197,7 +239,7 @@ func (c *Client) UpdateSecurity(ctx context.Context, profileID string, config *S
 	}
 
 	err := c.client.Security.Update(ctx, request)
-	metrics.RecordAPIRequest("UpdateSecurity", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "UpdateSecurity", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return fmt.Errorf("failed to update security settings: %w", err)
@@ -222,7 +264,7 @@ func (c *Client) UpdatePrivacy(ctx context.Context, profileID string, config *Pr
 	}
 
 	err := c.client.Privacy.Update(ctx, request)
-	metrics.RecordAPIRequest("UpdatePrivacy", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "UpdatePrivacy", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return fmt.Errorf("failed to update privacy settings: %w", err)
@@ -231,18 +273,32 @@ func (c *Client) UpdatePrivacy(ctx context.Context, profileID string, config *Pr
 	return nil
 }
 
+// RewriteResult reports the outcome of syncing one desired rewrite
+type RewriteResult struct {
+	Name    string
+	Content string
+
+	// Type is the record type NextDNS resolved the content to (A, AAAA or
+	// CNAME). Empty when the rewrite was rejected.
+	Type string
+
+	// Error is the reason NextDNS rejected the rewrite; empty when applied
+	Error string
+}
+
 // SyncRewrites synchronizes DNS rewrites for a profile using diff-based create/delete.
 // The NextDNS API does not support update for rewrites, so we delete removed entries
-// and create new ones.
-func (c *Client) SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) error {
+// and create new ones. A rewrite the API rejects does not stop the sync; it is
+// reported in the returned results, one per entry.
+func (c *Client) SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) ([]RewriteResult, error) {
 	start := time.Now()
 
 	// Get current rewrites
 	listRequest := &nextdns.ListRewritesRequest{ProfileID: profileID}
 	current, err := c.client.Rewrites.List(ctx, listRequest)
 	if err != nil {
-		metrics.RecordAPIRequest("SyncRewrites", time.Since(start).Seconds(), false)
-		return fmt.Errorf("failed to list rewrites: %w", err)
+		c.metrics.RecordAPIRequest(c.account, "SyncRewrites", time.Since(start).Seconds(), false)
+		return nil, fmt.Errorf("failed to list rewrites: %w", err)
 	}
 
 	// Build desired set keyed by name+content
@@ -253,36 +309,62 @@ func (c *Client) SyncRewrites(ctx context.Context, profileID string, entries []R
 	}
 
 	// Find entries to delete (in current but not in desired)
-	currentSet := make(map[rewriteKey]bool, len(current))
+	currentTypes := make(map[rewriteKey]string, len(current))
 	for _, rw := range current {
 		key := rewriteKey{rw.Name, rw.Content}
-		currentSet[key] = true
+		currentTypes[key] = rw.Type
 		if !desired[key] {
 			deleteReq := &nextdns.DeleteRewritesRequest{ProfileID: profileID, ID: rw.ID}
 			if err := c.client.Rewrites.Delete(ctx, deleteReq); err != nil {
-				metrics.RecordAPIRequest("SyncRewrites", time.Since(start).Seconds(), false)
-				return fmt.Errorf("failed to delete rewrite %s: %w", rw.Name, err)
+				c.metrics.RecordAPIRequest(c.account, "SyncRewrites", time.Since(start).Seconds(), false)
+				return nil, fmt.Errorf("failed to delete rewrite %s: %w", rw.Name, err)
 			}
 		}
 	}
 
 	// Create entries not in current state
+	results := make([]RewriteResult, 0, len(entries))
+	created := false
 	for _, e := range entries {
+		result := RewriteResult{Name: e.Name, Content: e.Content}
 		key := rewriteKey(e)
-		if !currentSet[key] {
+		if _, ok := currentTypes[key]; !ok {
 			createReq := &nextdns.CreateRewritesRequest{
 				ProfileID: profileID,
 				Rewrites:  &nextdns.Rewrites{Name: e.Name, Content: e.Content},
 			}
 			if _, err := c.client.Rewrites.Create(ctx, createReq); err != nil {
-				metrics.RecordAPIRequest("SyncRewrites", time.Since(start).Seconds(), false)
-				return fmt.Errorf("failed to create rewrite %s: %w", e.Name, err)
+				if !IsRejectedError(err) {
+					c.metrics.RecordAPIRequest(c.account, "SyncRewrites", time.Since(start).Seconds(), false)
+					return nil, fmt.Errorf("failed to create rewrite %s: %w", e.Name, err)
+				}
+				result.Error = err.Error()
+			} else {
+				created = true
 			}
 		}
+		results = append(results, result)
 	}
 
-	metrics.RecordAPIRequest("SyncRewrites", time.Since(start).Seconds(), true)
-	return nil
+	// The create response has no record type, so read back the rewrites
+	if created {
+		current, err = c.client.Rewrites.List(ctx, listRequest)
+		if err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncRewrites", time.Since(start).Seconds(), false)
+			return nil, fmt.Errorf("failed to list rewrites: %w", err)
+		}
+		for _, rw := range current {
+			currentTypes[rewriteKey{rw.Name, rw.Content}] = rw.Type
+		}
+	}
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Type = currentTypes[rewriteKey{results[i].Name, results[i].Content}]
+		}
+	}
+
+	c.metrics.RecordAPIRequest(c.account, "SyncRewrites", time.Since(start).Seconds(), true)
+	return results, nil
 }
 
 // GetRewrites retrieves the current rewrites for a profile
@@ -293,7 +375,7 @@ func (c *Client) GetRewrites(ctx context.Context, profileID string) ([]*nextdns.
 	}
 
 	list, err := c.client.Rewrites.List(ctx, request)
-	metrics.RecordAPIRequest("GetRewrites", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetRewrites", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rewrites: %w", err)
@@ -302,57 +384,115 @@ func (c *Client) GetRewrites(ctx context.Context, profileID string) ([]*nextdns.
 	return list, nil
 }
 
-// SyncDenylist synchronizes the denylist for a profile
-func (c *Client) SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry) error {
+// SyncDenylist synchronizes the denylist for a profile using diff-based add/update/delete.
+// Only the delta against the current remote list is sent.
+func (c *Client) SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error {
 	start := time.Now()
 
-	// Build the desired denylist
-	var denylist []*nextdns.Denylist
-	for _, entry := range entries {
-		denylist = append(denylist, &nextdns.Denylist{
-			ID:     entry.Domain,
-			Active: entry.Active,
-		})
+	current, err := c.client.Denylist.List(ctx, &nextdns.ListDenylistRequest{ProfileID: profileID})
+	if err != nil {
+		c.metrics.RecordAPIRequest(c.account, "SyncDenylist", time.Since(start).Seconds(), false)
+		return fmt.Errorf("failed to list denylist: %w", err)
 	}
 
-	// PUT replaces the entire list
-	createRequest := &nextdns.CreateDenylistRequest{
-		ProfileID: profileID,
-		Denylist:  denylist,
+	currentSet := make(map[string]bool, len(current))
+	for _, e := range current {
+		currentSet[e.ID] = e.Active
 	}
-	if err := c.client.Denylist.Create(ctx, createRequest); err != nil {
-		metrics.RecordAPIRequest("SyncDenylist", time.Since(start).Seconds(), false)
-		return fmt.Errorf("failed to sync denylist: %w", err)
+	plan := planDomainListSync(currentSet, entries, opts)
+
+	// Record the entries changed, including those before a failure
+	var added, removed int
+	defer func() { c.metrics.RecordListEntriesChanged(listTypeDenylist, added, removed) }()
+
+	for _, domain := range plan.Delete {
+		deleteReq := &nextdns.DeleteDenylistRequest{ProfileID: profileID, ID: domain}
+		if err := c.client.Denylist.Delete(ctx, deleteReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncDenylist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to delete denylist entry %s: %w", domain, err)
+		}
+		removed++
 	}
 
-	metrics.RecordAPIRequest("SyncDenylist", time.Since(start).Seconds(), true)
+	for _, e := range plan.Update {
+		updateReq := &nextdns.UpdateDenylistRequest{
+			ProfileID: profileID,
+			ID:        e.Domain,
+			Denylist:  &nextdns.Denylist{Active: e.Active},
+		}
+		if err := c.client.Denylist.Update(ctx, updateReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncDenylist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to update denylist entry %s: %w", e.Domain, err)
+		}
+	}
+
+	for _, e := range plan.Add {
+		active := e.Active
+		addReq := &nextdns.AddDenylistRequest{ProfileID: profileID, ID: e.Domain, Active: &active}
+		if err := c.client.Denylist.Add(ctx, addReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncDenylist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to add denylist entry %s: %w", e.Domain, err)
+		}
+		added++
+	}
+
+	c.metrics.RecordAPIRequest(c.account, "SyncDenylist", time.Since(start).Seconds(), true)
 	return nil
 }
 
-// SyncAllowlist synchronizes the allowlist for a profile
-func (c *Client) SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry) error {
+// SyncAllowlist synchronizes the allowlist for a profile using diff-based add/update/delete.
+// Only the delta against the current remote list is sent.
+func (c *Client) SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error {
 	start := time.Now()
 
-	// Build the desired allowlist
-	var allowlist []*nextdns.Allowlist
-	for _, entry := range entries {
-		allowlist = append(allowlist, &nextdns.Allowlist{
-			ID:     entry.Domain,
-			Active: entry.Active,
-		})
+	current, err := c.client.Allowlist.List(ctx, &nextdns.ListAllowlistRequest{ProfileID: profileID})
+	if err != nil {
+		c.metrics.RecordAPIRequest(c.account, "SyncAllowlist", time.Since(start).Seconds(), false)
+		return fmt.Errorf("failed to list allowlist: %w", err)
 	}
 
-	// Create/update the allowlist (PUT replaces the entire list)
-	createRequest := &nextdns.CreateAllowlistRequest{
-		ProfileID: profileID,
-		Allowlist: allowlist,
+	currentSet := make(map[string]bool, len(current))
+	for _, e := range current {
+		currentSet[e.ID] = e.Active
+	}
+	plan := planDomainListSync(currentSet, entries, opts)
+
+	// Record the entries changed, including those before a failure
+	var added, removed int
+	defer func() { c.metrics.RecordListEntriesChanged(listTypeAllowlist, added, removed) }()
+
+	for _, domain := range plan.Delete {
+		deleteReq := &nextdns.DeleteAllowlistRequest{ProfileID: profileID, ID: domain}
+		if err := c.client.Allowlist.Delete(ctx, deleteReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncAllowlist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to delete allowlist entry %s: %w", domain, err)
+		}
+		removed++
 	}
-	if err := c.client.Allowlist.Create(ctx, createRequest); err != nil {
-		metrics.RecordAPIRequest("SyncAllowlist", time.Since(start).Seconds(), false)
-		return fmt.Errorf("failed to sync allowlist: %w", err)
+
+	for _, e := range plan.Update {
+		updateReq := &nextdns.UpdateAllowlistRequest{
+			ProfileID: profileID,
+			ID:        e.Domain,
+			Allowlist: &nextdns.Allowlist{Active: e.Active},
+		}
+		if err := c.client.Allowlist.Update(ctx, updateReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncAllowlist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to update allowlist entry %s: %w", e.Domain, err)
+		}
+	}
+
+	for _, e := range plan.Add {
+		active := e.Active
+		addReq := &nextdns.AddAllowlistRequest{ProfileID: profileID, ID: e.Domain, Active: &active}
+		if err := c.client.Allowlist.Add(ctx, addReq); err != nil {
+			c.metrics.RecordAPIRequest(c.account, "SyncAllowlist", time.Since(start).Seconds(), false)
+			return fmt.Errorf("failed to add allowlist entry %s: %w", e.Domain, err)
+		}
+		added++
 	}
 
-	metrics.RecordAPIRequest("SyncAllowlist", time.Since(start).Seconds(), true)
+	c.metrics.RecordAPIRequest(c.account, "SyncAllowlist", time.Since(start).Seconds(), true)
 	return nil
 }
 
@@ -367,7 +507,7 @@ func (c *Client) AddAllowlistEntry(ctx context.Context, profileID string, domain
 	}
 
 	err := c.client.Allowlist.Add(ctx, request)
-	metrics.RecordAPIRequest("AddAllowlistEntry", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "AddAllowlistEntry", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to add allowlist entry %s: %w", domain, err)
 	}
@@ -385,7 +525,7 @@ func (c *Client) DeleteAllowlistEntry(ctx context.Context, profileID string, dom
 	}
 
 	err := c.client.Allowlist.Delete(ctx, request)
-	metrics.RecordAPIRequest("DeleteAllowlistEntry", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "DeleteAllowlistEntry", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete allowlist entry %s: %w", domain, err)
 	}
@@ -404,7 +544,7 @@ func (c *Client) AddDenylistEntry(ctx context.Context, profileID string, domain
 	}
 
 	err := c.client.Denylist.Add(ctx, request)
-	metrics.RecordAPIRequest("AddDenylistEntry", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "AddDenylistEntry", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to add denylist entry %s: %w", domain, err)
 	}
@@ -422,7 +562,7 @@ func (c *Client) DeleteDenylistEntry(ctx context.Context, profileID string, doma
 	}
 
 	err := c.client.Denylist.Delete(ctx, request)
-	metrics.RecordAPIRequest("DeleteDenylistEntry", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "DeleteDenylistEntry", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete denylist entry %s: %w", domain, err)
 	}
@@ -440,7 +580,7 @@ func (c *Client) AddSecurityTLD(ctx context.Context, profileID string, tld strin
 	}
 
 	err := c.client.SecurityTlds.Add(ctx, request)
-	metrics.RecordAPIRequest("AddSecurityTLD", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "AddSecurityTLD", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to add security TLD %s: %w", tld, err)
 	}
@@ -458,7 +598,7 @@ func (c *Client) DeleteSecurityTLD(ctx context.Context, profileID string, tld st
 	}
 
 	err := c.client.SecurityTlds.Delete(ctx, request)
-	metrics.RecordAPIRequest("DeleteSecurityTLD", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "DeleteSecurityTLD", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete security TLD %s: %w", tld, err)
 	}
@@ -476,7 +616,7 @@ func (c *Client) AddPrivacyNative(ctx context.Context, profileID string, nativeI
 	}
 
 	err := c.client.PrivacyNatives.Add(ctx, request)
-	metrics.RecordAPIRequest("AddPrivacyNative", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "AddPrivacyNative", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to add privacy native %s: %w", nativeID, err)
 	}
@@ -494,7 +634,7 @@ func (c *Client) DeletePrivacyNative(ctx context.Context, profileID string, nati
 	}
 
 	err := c.client.PrivacyNatives.Delete(ctx, request)
-	metrics.RecordAPIRequest("DeletePrivacyNative", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "DeletePrivacyNative", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete privacy native %s: %w", nativeID, err)
 	}
@@ -542,7 +682,7 @@ func (c *Client) UpdateSettings(ctx context.Context, profileID string, config *S
 	}
 
 	err := c.client.Settings.Update(ctx, request)
-	metrics.RecordAPIRequest("UpdateSettings", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "UpdateSettings", time.Since(start).Seconds(), err == nil)
 	if err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
@@ -550,10 +690,26 @@ func (c *Client) UpdateSettings(ctx context.Context, profileID string, config *S
 	return nil
 }
 
-// SyncSecurityTLDs synchronizes blocked TLDs for a profile
+// SyncSecurityTLDs synchronizes blocked TLDs for a profile. The remote list
+// is read first so an unchanged list is not rewritten.
 func (c *Client) SyncSecurityTLDs(ctx context.Context, profileID string, tlds []string) error {
 	start := time.Now()
 
+	current, err := c.client.SecurityTlds.List(ctx, &nextdns.ListSecurityTldsRequest{ProfileID: profileID})
+	if err != nil {
+		c.metrics.RecordAPIRequest(c.account, "SyncSecurityTLDs", time.Since(start).Seconds(), false)
+		return fmt.Errorf("failed to list security TLDs: %w", err)
+	}
+	currentIDs := make([]string, 0, len(current))
+	for _, tld := range current {
+		currentIDs = append(currentIDs, tld.ID)
+	}
+	added, removed := planTLDSync(currentIDs, tlds)
+	if len(added) == 0 && len(removed) == 0 {
+		c.metrics.RecordAPIRequest(c.account, "SyncSecurityTLDs", time.Since(start).Seconds(), true)
+		return nil
+	}
+
 	// Build the desired TLD list
 	var securityTlds []*nextdns.SecurityTlds
 	for _, tld := range tlds {
@@ -568,11 +724,12 @@ func (c *Client) SyncSecurityTLDs(ctx context.Context, profileID string, tlds []
 		SecurityTlds: securityTlds,
 	}
 	if err := c.client.SecurityTlds.Create(ctx, createRequest); err != nil {
-		metrics.RecordAPIRequest("SyncSecurityTLDs", time.Since(start).Seconds(), false)
+		c.metrics.RecordAPIRequest(c.account, "SyncSecurityTLDs", time.Since(start).Seconds(), false)
 		return fmt.Errorf("failed to sync security TLDs: %w", err)
 	}
 
-	metrics.RecordAPIRequest("SyncSecurityTLDs", time.Since(start).Seconds(), true)
+	c.metrics.RecordListEntriesChanged(listTypeTLD, len(added), len(removed))
+	c.metrics.RecordAPIRequest(c.account, "SyncSecurityTLDs", time.Since(start).Seconds(), true)
 	return nil
 }
 
@@ -594,7 +751,7 @@ func (c *Client) UpdateParentalControl(ctx context.Context, profileID string, co
 
 	err := c.client.ParentalControl.Update(ctx, request)
 	if err != nil {
-		metrics.RecordAPIRequest("UpdateParentalControl", time.Since(start).Seconds(), false)
+		c.metrics.RecordAPIRequest(c.account, "UpdateParentalControl", time.Since(start).Seconds(), false)
 		return fmt.Errorf("failed to update parental control settings: %w", err)
 	}
 
@@ -612,7 +769,7 @@ func (c *Client) UpdateParentalControl(ctx context.Context, profileID string, co
 			ParentalControlCategories: categories,
 		}
 		if err := c.client.ParentalControlCategories.Create(ctx, catRequest); err != nil {
-			metrics.RecordAPIRequest("UpdateParentalControl", time.Since(start).Seconds(), false)
+			c.metrics.RecordAPIRequest(c.account, "UpdateParentalControl", time.Since(start).Seconds(), false)
 			return fmt.Errorf("failed to sync parental control categories: %w", err)
 		}
 	}
@@ -631,12 +788,12 @@ func (c *Client) UpdateParentalControl(ctx context.Context, profileID string, co
 			ParentalControlServices: services,
 		}
 		if err := c.client.ParentalControlServices.Create(ctx, svcRequest); err != nil {
-			metrics.RecordAPIRequest("UpdateParentalControl", time.Since(start).Seconds(), false)
+			c.metrics.RecordAPIRequest(c.account, "UpdateParentalControl", time.Since(start).Seconds(), false)
 			return fmt.Errorf("failed to sync parental control services: %w", err)
 		}
 	}
 
-	metrics.RecordAPIRequest("UpdateParentalControl", time.Since(start).Seconds(), true)
+	c.metrics.RecordAPIRequest(c.account, "UpdateParentalControl", time.Since(start).Seconds(), true)
 	return nil
 }
 
@@ -655,11 +812,11 @@ func (c *Client) SyncPrivacyBlocklists(ctx context.Context, profileID string, bl
 		PrivacyBlocklists: privacyBlocklists,
 	}
 	if err := c.client.PrivacyBlocklists.Create(ctx, request); err != nil {
-		metrics.RecordAPIRequest("SyncPrivacyBlocklists", time.Since(start).Seconds(), false)
+		c.metrics.RecordAPIRequest(c.account, "SyncPrivacyBlocklists", time.Since(start).Seconds(), false)
 		return fmt.Errorf("failed to sync privacy blocklists: %w", err)
 	}
 
-	metrics.RecordAPIRequest("SyncPrivacyBlocklists", time.Since(start).Seconds(), true)
+	c.metrics.RecordAPIRequest(c.account, "SyncPrivacyBlocklists", time.Since(start).Seconds(), true)
 	return nil
 }
 
@@ -678,11 +835,11 @@ func (c *Client) SyncPrivacyNatives(ctx context.Context, profileID string, nativ
 		PrivacyNatives: privacyNatives,
 	}
 	if err := c.client.PrivacyNatives.Create(ctx, request); err != nil {
-		metrics.RecordAPIRequest("SyncPrivacyNatives", time.Since(start).Seconds(), false)
+		c.metrics.RecordAPIRequest(c.account, "SyncPrivacyNatives", time.Since(start).Seconds(), false)
 		return fmt.Errorf("failed to sync privacy natives: %w", err)
 	}
 
-	metrics.RecordAPIRequest("SyncPrivacyNatives", time.Since(start).Seconds(), true)
+	c.metrics.RecordAPIRequest(c.account, "SyncPrivacyNatives", time.Since(start).Seconds(), true)
 	return nil
 }
 
@@ -694,7 +851,7 @@ func (c *Client) GetDenylist(ctx context.Context, profileID string) ([]*nextdns.
 	}
 
 	list, err := c.client.Denylist.List(ctx, request)
-	metrics.RecordAPIRequest("GetDenylist", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetDenylist", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get denylist: %w", err)
@@ -711,7 +868,7 @@ func (c *Client) GetAllowlist(ctx context.Context, profileID string) ([]*nextdns
 	}
 
 	list, err := c.client.Allowlist.List(ctx, request)
-	metrics.RecordAPIRequest("GetAllowlist", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetAllowlist", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get allowlist: %w", err)
@@ -728,7 +885,7 @@ func (c *Client) GetSecurityTLDs(ctx context.Context, profileID string) ([]*next
 	}
 
 	list, err := c.client.SecurityTlds.List(ctx, request)
-	metrics.RecordAPIRequest("GetSecurityTLDs", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetSecurityTLDs", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get security TLDs: %w", err)
@@ -745,7 +902,7 @@ func (c *Client) GetSecurity(ctx context.Context, profileID string) (*nextdns.Se
 	}
 
 	security, err := c.client.Security.Get(ctx, request)
-	metrics.RecordAPIRequest("GetSecurity", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetSecurity", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get security settings: %w", err)
@@ -762,7 +919,7 @@ func (c *Client) GetPrivacy(ctx context.Context, profileID string) (*nextdns.Pri
 	}
 
 	privacy, err := c.client.Privacy.Get(ctx, request)
-	metrics.RecordAPIRequest("GetPrivacy", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetPrivacy", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
@@ -779,7 +936,7 @@ func (c *Client) GetParentalControl(ctx context.Context, profileID string) (*nex
 	}
 
 	pc, err := c.client.ParentalControl.Get(ctx, request)
-	metrics.RecordAPIRequest("GetParentalControl", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetParentalControl", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parental control settings: %w", err)
@@ -796,7 +953,7 @@ func (c *Client) GetSetup(ctx context.Context, profileID string) (*nextdns.Setup
 	}
 
 	setup, err := c.client.Setup.Get(ctx, request)
-	metrics.RecordAPIRequest("GetSetup", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetSetup", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get setup: %w", err)
@@ -813,7 +970,7 @@ func (c *Client) GetSettings(ctx context.Context, profileID string) (*nextdns.Se
 	}
 
 	settings, err := c.client.Settings.Get(ctx, request)
-	metrics.RecordAPIRequest("GetSettings", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetSettings", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get settings: %w", err)
@@ -830,7 +987,7 @@ func (c *Client) GetPrivacyBlocklists(ctx context.Context, profileID string) ([]
 	}
 
 	list, err := c.client.PrivacyBlocklists.List(ctx, request)
-	metrics.RecordAPIRequest("GetPrivacyBlocklists", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetPrivacyBlocklists", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get privacy blocklists: %w", err)
@@ -847,7 +1004,7 @@ func (c *Client) GetPrivacyNatives(ctx context.Context, profileID string) ([]*ne
 	}
 
 	list, err := c.client.PrivacyNatives.List(ctx, request)
-	metrics.RecordAPIRequest("GetPrivacyNatives", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetPrivacyNatives", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get privacy natives: %w", err)
@@ -864,7 +1021,7 @@ func (c *Client) GetParentalControlCategories(ctx context.Context, profileID str
 	}
 
 	list, err := c.client.ParentalControlCategories.List(ctx, request)
-	metrics.RecordAPIRequest("GetParentalControlCategories", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetParentalControlCategories", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parental control categories: %w", err)
@@ -881,7 +1038,7 @@ func (c *Client) GetParentalControlServices(ctx context.Context, profileID strin
 	}
 
 	list, err := c.client.ParentalControlServices.List(ctx, request)
-	metrics.RecordAPIRequest("GetParentalControlServices", time.Since(start).Seconds(), err == nil)
+	c.metrics.RecordAPIRequest(c.account, "GetParentalControlServices", time.Since(start).Seconds(), err == nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parental control services: %w", err)
@@ -889,3 +1046,26 @@ func (c *Client) GetParentalControlServices(ctx context.Context, profileID strin
 
 	return list, nil
 }
+
+// GetTopBlockedDomains retrieves the domains blocked most often since from,
+// most blocked first
+func (c *Client) GetTopBlockedDomains(ctx context.Context, profileID string, from time.Time, limit int) ([]*nextdns.AnalyticsEntry, error) {
+	start := time.Now()
+	request := &nextdns.GetAnalyticsDomainsRequest{
+		ProfileID: profileID,
+		Options: &nextdns.AnalyticsOptions{
+			From:  from.UTC().Format(time.RFC3339),
+			Limit: limit,
+		},
+		Status: nextdns.StatusBlocked,
+	}
+
+	response, err := c.client.Analytics.GetDomains(ctx, request)
+	c.metrics.RecordAPIRequest(c.account, "GetTopBlockedDomains", time.Since(start).Seconds(), err == nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top blocked domains: %w", err)
+	}
+
+	return response.Data, nil
+}
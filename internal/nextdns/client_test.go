@@ -2,11 +2,16 @@ package nextdns
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
 )
 
 func TestNewClient(t *testing.T) {
@@ -210,6 +215,20 @@ func TestMockClient_DeleteProfile(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestMockClient_ListProfiles(t *testing.T) {
+	mock := NewMockClient()
+	mock.SetProfile("def456", "Second", "fp2")
+	mock.SetProfile("abc123", "First", "fp1")
+
+	profiles, err := mock.ListProfiles(context.Background())
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "abc123", profiles[0].ID)
+	assert.Equal(t, "First", profiles[0].Name)
+	assert.Equal(t, "fp1", profiles[0].Fingerprint)
+	assert.Equal(t, "def456", profiles[1].ID)
+}
+
 func TestMockClient_UpdateSecurity(t *testing.T) {
 	mock := NewMockClient()
 
@@ -262,7 +281,7 @@ func TestSyncDenylist_NoPrefetch(t *testing.T) {
 		{Domain: "worse.com", Active: false},
 	}
 
-	err := mockClient.SyncDenylist(ctx, "test-profile", entries)
+	err := mockClient.SyncDenylist(ctx, "test-profile", entries, ListSyncOptions{})
 	require.NoError(t, err)
 
 	result, err := mockClient.GetDenylist(ctx, "test-profile")
@@ -278,7 +297,7 @@ func TestMockClient_SyncDenylist(t *testing.T) {
 		{Domain: "bad2.com", Active: true},
 		{Domain: "bad3.com", Active: false},
 	}
-	err := mock.SyncDenylist(context.Background(), "profile-1", entries)
+	err := mock.SyncDenylist(context.Background(), "profile-1", entries, ListSyncOptions{})
 	require.NoError(t, err)
 
 	denylist, err := mock.GetDenylist(context.Background(), "profile-1")
@@ -303,7 +322,7 @@ func TestMockClient_SyncAllowlist(t *testing.T) {
 		{Domain: "good2.com", Active: true},
 		{Domain: "good3.com", Active: false},
 	}
-	err := mock.SyncAllowlist(context.Background(), "profile-1", entries)
+	err := mock.SyncAllowlist(context.Background(), "profile-1", entries, ListSyncOptions{})
 	require.NoError(t, err)
 
 	allowlist, err := mock.GetAllowlist(context.Background(), "profile-1")
@@ -412,7 +431,7 @@ func TestMockClient_ErrorInjection(t *testing.T) {
 
 	// Test error injection for SyncDenylist
 	mock.SyncDenylistError = assert.AnError
-	err = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}})
+	err = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}}, ListSyncOptions{})
 	assert.Error(t, err)
 }
 
@@ -421,7 +440,7 @@ func TestMockClient_Reset(t *testing.T) {
 
 	// Create some data
 	_, _ = mock.CreateProfile(context.Background(), "Test")
-	_ = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}})
+	_ = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}}, ListSyncOptions{})
 	mock.CreateProfileError = assert.AnError
 
 	// Reset
@@ -462,7 +481,7 @@ func TestMockClient_ThreadSafety(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		go func(idx int) {
 			_, _ = mock.CreateProfile(context.Background(), "Test")
-			_ = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}})
+			_ = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{{Domain: "bad.com", Active: true}}, ListSyncOptions{})
 			mock.GetCallCount("CreateProfile")
 			done <- true
 		}(i)
@@ -575,7 +594,7 @@ func TestMockClient_GetDenylist(t *testing.T) {
 	err = mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{
 		{Domain: "bad.com", Active: true},
 		{Domain: "evil.com", Active: true},
-	})
+	}, ListSyncOptions{})
 	require.NoError(t, err)
 
 	// Now get it
@@ -601,7 +620,7 @@ func TestMockClient_GetAllowlist(t *testing.T) {
 	err = mock.SyncAllowlist(context.Background(), "profile-1", []DomainEntry{
 		{Domain: "good.com", Active: true},
 		{Domain: "trusted.com", Active: true},
-	})
+	}, ListSyncOptions{})
 	require.NoError(t, err)
 
 	// Now get it
@@ -733,7 +752,7 @@ func TestMockClient_SyncAllowlist_Error(t *testing.T) {
 	mock := NewMockClient()
 	mock.SyncAllowlistError = assert.AnError
 
-	err := mock.SyncAllowlist(context.Background(), "profile-1", []DomainEntry{{Domain: "good.com", Active: true}})
+	err := mock.SyncAllowlist(context.Background(), "profile-1", []DomainEntry{{Domain: "good.com", Active: true}}, ListSyncOptions{})
 	assert.Error(t, err)
 }
 
@@ -765,10 +784,10 @@ func TestMockClient_EmptyListsSync(t *testing.T) {
 	mock := NewMockClient()
 
 	// Sync empty lists - should not panic
-	err := mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{})
+	err := mock.SyncDenylist(context.Background(), "profile-1", []DomainEntry{}, ListSyncOptions{})
 	require.NoError(t, err)
 
-	err = mock.SyncAllowlist(context.Background(), "profile-1", []DomainEntry{})
+	err = mock.SyncAllowlist(context.Background(), "profile-1", []DomainEntry{}, ListSyncOptions{})
 	require.NoError(t, err)
 
 	err = mock.SyncSecurityTLDs(context.Background(), "profile-1", []string{})
@@ -1029,8 +1048,8 @@ func TestSyncRewrites(t *testing.T) {
 
 	// Set up existing rewrites
 	mockClient.Rewrites["test-profile"] = []*sdknextdns.Rewrites{
-		{ID: "rw1", Name: "old.example.com", Content: "1.2.3.4"},
-		{ID: "rw2", Name: "keep.example.com", Content: "5.6.7.8"},
+		{ID: "rw1", Name: "old.example.com", Type: "A", Content: "1.2.3.4"},
+		{ID: "rw2", Name: "keep.example.com", Type: "A", Content: "5.6.7.8"},
 	}
 
 	// Desired state: keep one, add one, remove one
@@ -1039,8 +1058,12 @@ func TestSyncRewrites(t *testing.T) {
 		{Name: "new.example.com", Content: "9.10.11.12"},
 	}
 
-	err := mockClient.SyncRewrites(ctx, "test-profile", desired)
+	results, err := mockClient.SyncRewrites(ctx, "test-profile", desired)
 	require.NoError(t, err)
+	assert.Equal(t, []RewriteResult{
+		{Name: "keep.example.com", Content: "5.6.7.8", Type: "A"},
+		{Name: "new.example.com", Content: "9.10.11.12", Type: "A"},
+	}, results)
 
 	result, err := mockClient.GetRewrites(ctx, "test-profile")
 	require.NoError(t, err)
@@ -1064,10 +1087,144 @@ func TestSyncRewrites_EmptyDesired(t *testing.T) {
 		{ID: "rw1", Name: "old.example.com", Content: "1.2.3.4"},
 	}
 
-	err := mockClient.SyncRewrites(ctx, "test-profile", []RewriteEntry{})
+	results, err := mockClient.SyncRewrites(ctx, "test-profile", []RewriteEntry{})
 	require.NoError(t, err)
+	assert.Empty(t, results)
 
 	result, err := mockClient.GetRewrites(ctx, "test-profile")
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(result))
 }
+
+func TestSyncRewrites_Rejected(t *testing.T) {
+	mockClient := NewMockClient()
+	mockClient.RejectedRewrites = map[string]string{"bad..example.com": "invalid name"}
+
+	results, err := mockClient.SyncRewrites(context.Background(), "test-profile", []RewriteEntry{
+		{Name: "nas.home", Content: "fd00::10"},
+		{Name: "bad..example.com", Content: "10.0.0.1"},
+		{Name: "alias.home", Content: "nas.home"},
+	})
+	require.NoError(t, err, "a rejected rewrite does not fail the sync")
+	assert.Equal(t, []RewriteResult{
+		{Name: "nas.home", Content: "fd00::10", Type: "AAAA"},
+		{Name: "bad..example.com", Content: "10.0.0.1", Error: "invalid name"},
+		{Name: "alias.home", Content: "nas.home", Type: "CNAME"},
+	}, results)
+
+	remote, err := mockClient.GetRewrites(context.Background(), "test-profile")
+	require.NoError(t, err)
+	assert.Len(t, remote, 2)
+}
+
+// newTestAPIClient returns a Client for a fake NextDNS API that answers GET
+// requests from lists and acknowledges every write, recording the writes.
+// A PUT is acknowledged with an empty list, as the SDK decodes its reply as one.
+// The client records to a fresh metrics registry.
+func newTestAPIClient(t *testing.T, lists map[string]string, writes *[]string) (*Client, *prometheus.Registry) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(lists[r.URL.Path]))
+			return
+		}
+		*writes = append(*writes, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPut {
+			_, _ = w.Write([]byte(`{"data": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	sdk, err := sdknextdns.New(sdknextdns.WithBaseURL(server.URL))
+	require.NoError(t, err)
+	registry := prometheus.NewRegistry()
+	m, err := metrics.New(registry)
+	require.NoError(t, err)
+	return &Client{client: sdk, metrics: m}, registry
+}
+
+// listEntryCounts returns the added and removed list entry counters of listType
+func listEntryCounts(t *testing.T, registry *prometheus.Registry, listType string) (added, removed float64) {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := metric.GetLabel()
+			if len(labels) != 1 || labels[0].GetValue() != listType {
+				continue
+			}
+			switch family.GetName() {
+			case "nextdns_list_entries_added_total":
+				added = metric.GetCounter().GetValue()
+			case "nextdns_list_entries_removed_total":
+				removed = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return added, removed
+}
+
+func TestSyncDenylist_RecordsListEntryMetrics(t *testing.T) {
+	var writes []string
+	client, registry := newTestAPIClient(t, map[string]string{
+		"/profiles/p1/denylist": `{"data": [{"id": "old.com", "active": true}, {"id": "keep.com", "active": true}]}`,
+	}, &writes)
+
+	err := client.SyncDenylist(context.Background(), "p1", []DomainEntry{
+		{Domain: "keep.com", Active: true},
+		{Domain: "new.com", Active: true},
+		{Domain: "other.com", Active: false},
+	}, ListSyncOptions{})
+	require.NoError(t, err)
+	assert.Len(t, writes, 3)
+
+	added, removed := listEntryCounts(t, registry, "denylist")
+	assert.Equal(t, 2.0, added)
+	assert.Equal(t, 1.0, removed)
+}
+
+func TestSyncSecurityTLDs_RecordsListEntryMetrics(t *testing.T) {
+	var writes []string
+	client, registry := newTestAPIClient(t, map[string]string{
+		"/profiles/p1/security/tlds": `{"data": [{"id": "zip"}, {"id": "mov"}]}`,
+	}, &writes)
+	ctx := context.Background()
+
+	// An unchanged list is not rewritten
+	require.NoError(t, client.SyncSecurityTLDs(ctx, "p1", []string{"mov", "zip"}))
+	assert.Empty(t, writes)
+
+	require.NoError(t, client.SyncSecurityTLDs(ctx, "p1", []string{"zip", "xyz", "top"}))
+	assert.Len(t, writes, 1)
+
+	added, removed := listEntryCounts(t, registry, "tld")
+	assert.Equal(t, 2.0, added)
+	assert.Equal(t, 1.0, removed)
+}
+
+func TestValidateKey(t *testing.T) {
+	var writes []string
+	client, _ := newTestAPIClient(t, map[string]string{
+		"/profiles": `{"data": [{"id": "p1", "name": "Home"}]}`,
+	}, &writes)
+	require.NoError(t, client.ValidateKey(context.Background()))
+	assert.Empty(t, writes)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors": [{"code": "forbidden"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	sdk, err := sdknextdns.New(sdknextdns.WithBaseURL(server.URL))
+	require.NoError(t, err)
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	err = (&Client{client: sdk, metrics: m}).ValidateKey(context.Background())
+	assert.True(t, IsAuthError(err), "a rejected key is an authentication error: %v", err)
+}
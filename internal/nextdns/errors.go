@@ -1,7 +1,9 @@
 package nextdns
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/jacaudi/nextdns-go/nextdns"
 )
@@ -30,6 +32,16 @@ func IsDuplicateError(err error) bool {
 	return nextdns.IsDuplicateError(err)
 }
 
+// IsRejectedError returns true if the API rejected the request's content,
+// e.g. a value that failed validation. Rate limiting is not a rejection.
+func IsRejectedError(err error) bool {
+	var e *nextdns.Error
+	if !errors.As(err, &e) || e.Type != nextdns.ErrorTypeRequest {
+		return false
+	}
+	return e.Meta["http_status"] != http.StatusText(http.StatusTooManyRequests)
+}
+
 // HasErrorCode returns true if the error contains the specified error code.
 func HasErrorCode(err error, code string) bool {
 	if err == nil {
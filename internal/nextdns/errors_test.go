@@ -3,6 +3,8 @@ package nextdns
 import (
 	"errors"
 	"testing"
+
+	"github.com/jacaudi/nextdns-go/nextdns"
 )
 
 func TestIsNotFoundError(t *testing.T) {
@@ -91,6 +93,54 @@ func TestIsDuplicateError(t *testing.T) {
 	}
 }
 
+func TestIsRejectedError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "regular error",
+			err:      errors.New("invalid"),
+			expected: false,
+		},
+		{
+			name: "wrapped validation error",
+			err: WrapError("failed to create rewrite", &nextdns.Error{
+				Type: nextdns.ErrorTypeRequest,
+				Meta: map[string]string{"http_status": "Bad Request"},
+			}),
+			expected: true,
+		},
+		{
+			name: "rate limited",
+			err: &nextdns.Error{
+				Type: nextdns.ErrorTypeRequest,
+				Meta: map[string]string{"http_status": "Too Many Requests"},
+			},
+			expected: false,
+		},
+		{
+			name:     "server error",
+			err:      &nextdns.Error{Type: nextdns.ErrorTypeServiceError},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRejectedError(tt.err); got != tt.expected {
+				t.Errorf("IsRejectedError() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestWrapError(t *testing.T) {
 	origErr := errors.New("original error")
 	wrapped := WrapError("operation failed", origErr)
@@ -2,6 +2,7 @@ package nextdns
 
 import (
 	"context"
+	"time"
 
 	"github.com/jacaudi/nextdns-go/nextdns"
 )
@@ -14,6 +15,10 @@ type ClientInterface interface {
 	GetProfile(ctx context.Context, profileID string) (*nextdns.Profile, error)
 	UpdateProfile(ctx context.Context, profileID, name string) error
 	DeleteProfile(ctx context.Context, profileID string) error
+	ListProfiles(ctx context.Context) ([]*nextdns.ProfileSummary, error)
+
+	// Account operations
+	ValidateKey(ctx context.Context) error
 
 	// Security operations
 	UpdateSecurity(ctx context.Context, profileID string, config *SecurityConfig) error
@@ -30,8 +35,8 @@ type ClientInterface interface {
 	GetParentalControl(ctx context.Context, profileID string) (*nextdns.ParentalControl, error)
 
 	// List operations
-	SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry) error
-	SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry) error
+	SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error
+	SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error
 	SyncSecurityTLDs(ctx context.Context, profileID string, tlds []string) error
 	GetDenylist(ctx context.Context, profileID string) ([]*nextdns.Denylist, error)
 	GetAllowlist(ctx context.Context, profileID string) ([]*nextdns.Allowlist, error)
@@ -52,7 +57,7 @@ type ClientInterface interface {
 	DeletePrivacyNative(ctx context.Context, profileID string, nativeID string) error
 
 	// Rewrite operations
-	SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) error
+	SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) ([]RewriteResult, error)
 	GetRewrites(ctx context.Context, profileID string) ([]*nextdns.Rewrites, error)
 
 	// Settings operations
@@ -67,6 +72,9 @@ type ClientInterface interface {
 	GetPrivacyNatives(ctx context.Context, profileID string) ([]*nextdns.PrivacyNatives, error)
 	GetParentalControlCategories(ctx context.Context, profileID string) ([]*nextdns.ParentalControlCategories, error)
 	GetParentalControlServices(ctx context.Context, profileID string) ([]*nextdns.ParentalControlServices, error)
+
+	// Analytics operations
+	GetTopBlockedDomains(ctx context.Context, profileID string, from time.Time, limit int) ([]*nextdns.AnalyticsEntry, error)
 }
 
 // Ensure Client implements ClientInterface
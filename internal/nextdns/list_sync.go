@@ -0,0 +1,95 @@
+package nextdns
+
+import "sort"
+
+// List types reported by the list entry metrics
+const (
+	listTypeDenylist  = "denylist"
+	listTypeAllowlist = "allowlist"
+	listTypeTLD       = "tld"
+)
+
+// ListSyncOptions controls how SyncDenylist and SyncAllowlist treat remote
+// entries that are not part of the desired state
+type ListSyncOptions struct {
+	// PreserveUnmanaged keeps remote entries the operator did not create.
+	// Only domains listed in Managed are removed when they leave the desired state.
+	PreserveUnmanaged bool
+
+	// Managed lists the domains applied by the previous sync
+	Managed []string
+}
+
+// domainListPlan is the set of changes needed to turn the remote list into the desired one
+type domainListPlan struct {
+	Add    []DomainEntry
+	Update []DomainEntry
+	Delete []string
+}
+
+// empty reports whether the plan requires no API calls
+func (p domainListPlan) empty() bool {
+	return len(p.Add) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// planDomainListSync computes the adds, active-flag updates and deletes needed
+// to reconcile current with desired. Duplicate desired domains keep their first entry.
+func planDomainListSync(current map[string]bool, desired []DomainEntry, opts ListSyncOptions) domainListPlan {
+	var plan domainListPlan
+
+	want := make(map[string]bool, len(desired))
+	for _, e := range desired {
+		if _, seen := want[e.Domain]; seen {
+			continue
+		}
+		want[e.Domain] = e.Active
+
+		active, exists := current[e.Domain]
+		switch {
+		case !exists:
+			plan.Add = append(plan.Add, e)
+		case active != e.Active:
+			plan.Update = append(plan.Update, e)
+		}
+	}
+
+	managed := make(map[string]bool, len(opts.Managed))
+	for _, domain := range opts.Managed {
+		managed[domain] = true
+	}
+
+	for domain := range current {
+		if _, ok := want[domain]; ok {
+			continue
+		}
+		if opts.PreserveUnmanaged && !managed[domain] {
+			continue
+		}
+		plan.Delete = append(plan.Delete, domain)
+	}
+	sort.Strings(plan.Delete)
+
+	return plan
+}
+
+// planTLDSync returns the TLDs in desired but not current, and those in
+// current but not desired
+func planTLDSync(current, desired []string) (added, removed []string) {
+	have := make(map[string]bool, len(current))
+	for _, tld := range current {
+		have[tld] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, tld := range desired {
+		if !want[tld] && !have[tld] {
+			added = append(added, tld)
+		}
+		want[tld] = true
+	}
+	for _, tld := range current {
+		if !want[tld] {
+			removed = append(removed, tld)
+		}
+	}
+	return added, removed
+}
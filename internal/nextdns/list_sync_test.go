@@ -0,0 +1,150 @@
+package nextdns
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jacaudi/nextdns-operator/internal/perfbudget"
+)
+
+func TestPlanDomainListSync(t *testing.T) {
+	current := map[string]bool{
+		"keep.com":      true,
+		"toggle.com":    true,
+		"stale.com":     true,
+		"dashboard.com": false,
+	}
+	desired := []DomainEntry{
+		{Domain: "keep.com", Active: true},
+		{Domain: "toggle.com", Active: false},
+		{Domain: "new.com", Active: true},
+		{Domain: "new.com", Active: false},
+	}
+
+	tests := []struct {
+		name       string
+		opts       ListSyncOptions
+		wantDelete []string
+	}{
+		{
+			name:       "full replacement removes every extra entry",
+			opts:       ListSyncOptions{},
+			wantDelete: []string{"dashboard.com", "stale.com"},
+		},
+		{
+			name:       "preserve only removes managed entries",
+			opts:       ListSyncOptions{PreserveUnmanaged: true, Managed: []string{"keep.com", "stale.com"}},
+			wantDelete: []string{"stale.com"},
+		},
+		{
+			name:       "preserve without history removes nothing",
+			opts:       ListSyncOptions{PreserveUnmanaged: true},
+			wantDelete: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planDomainListSync(current, desired, tt.opts)
+			assert.Equal(t, []DomainEntry{{Domain: "new.com", Active: true}}, plan.Add)
+			assert.Equal(t, []DomainEntry{{Domain: "toggle.com", Active: false}}, plan.Update)
+			assert.Equal(t, tt.wantDelete, plan.Delete)
+		})
+	}
+}
+
+func TestPlanDomainListSync_NoChanges(t *testing.T) {
+	plan := planDomainListSync(
+		map[string]bool{"a.com": true, "b.com": false},
+		[]DomainEntry{{Domain: "a.com", Active: true}, {Domain: "b.com", Active: false}},
+		ListSyncOptions{},
+	)
+	assert.True(t, plan.empty())
+}
+
+func TestMockClient_SyncDenylist_PreserveUnmanaged(t *testing.T) {
+	mock := NewMockClient()
+	ctx := context.Background()
+
+	// Operator applies two entries, then someone adds one in the dashboard
+	require.NoError(t, mock.SyncDenylist(ctx, "profile-1", []DomainEntry{
+		{Domain: "old.com", Active: true},
+		{Domain: "kept.com", Active: true},
+	}, ListSyncOptions{}))
+	require.NoError(t, mock.AddDenylistEntry(ctx, "profile-1", "manual.com", true))
+
+	// old.com leaves the spec
+	err := mock.SyncDenylist(ctx, "profile-1", []DomainEntry{{Domain: "kept.com", Active: false}}, ListSyncOptions{
+		PreserveUnmanaged: true,
+		Managed:           []string{"kept.com", "old.com"},
+	})
+	require.NoError(t, err)
+
+	denylist, err := mock.GetDenylist(ctx, "profile-1")
+	require.NoError(t, err)
+	got := make(map[string]bool, len(denylist))
+	for _, e := range denylist {
+		got[e.ID] = e.Active
+	}
+	assert.Equal(t, map[string]bool{"kept.com": false, "manual.com": true}, got)
+}
+
+func TestPlanTLDSync(t *testing.T) {
+	added, removed := planTLDSync([]string{"zip", "mov"}, []string{"zip", "xyz", "xyz"})
+	assert.Equal(t, []string{"xyz"}, added)
+	assert.Equal(t, []string{"mov"}, removed)
+
+	added, removed = planTLDSync([]string{"zip"}, []string{"zip"})
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+// largeListSync returns a remote list and a desired list of n domains each
+// that differ like a large blocklist between two fetches: a tenth of the
+// remote entries were dropped, a tenth are new and a twentieth were paused.
+// managed holds every remote domain, as after a previous sync.
+func largeListSync(n int) (current map[string]bool, desired []DomainEntry, managed []string) {
+	current = make(map[string]bool, n)
+	managed = make([]string, 0, n)
+	for i := range n {
+		domain := fmt.Sprintf("host-%d.example.com", i)
+		current[domain] = true
+		managed = append(managed, domain)
+	}
+	desired = make([]DomainEntry, 0, n)
+	for i := n / 10; i < n+n/10; i++ {
+		desired = append(desired, DomainEntry{Domain: fmt.Sprintf("host-%d.example.com", i), Active: i%20 != 0})
+	}
+	return current, desired, managed
+}
+
+func BenchmarkPlanDomainListSync(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		current, desired, managed := largeListSync(n)
+		opts := ListSyncOptions{PreserveUnmanaged: true, Managed: managed}
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				planDomainListSync(current, desired, opts)
+			}
+		})
+	}
+}
+
+func TestPlanDomainListSync_PerformanceBudget(t *testing.T) {
+	current, desired, managed := largeListSync(100_000)
+	opts := ListSyncOptions{PreserveUnmanaged: true, Managed: managed}
+
+	plan := planDomainListSync(current, desired, opts)
+	require.Len(t, plan.Add, 10_000)
+	require.Len(t, plan.Delete, 10_000)
+
+	perfbudget.Enforce(t, perfbudget.Budget{Time: 200 * time.Millisecond, Allocs: 1200}, func() {
+		planDomainListSync(current, desired, opts)
+	})
+}
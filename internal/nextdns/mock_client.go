@@ -3,7 +3,10 @@ package nextdns
 import (
 	"context"
 	"fmt"
+	"net/netip"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/jacaudi/nextdns-go/nextdns"
 )
@@ -57,14 +60,24 @@ type MockClient struct {
 	// Rewrites stores mock rewrites per profile
 	Rewrites map[string][]*nextdns.Rewrites
 
+	// RejectedRewrites maps rewrite names to the reason SyncRewrites reports
+	// for rejecting them
+	RejectedRewrites map[string]string
+
 	// SetupData stores mock setup data per profile
 	SetupData map[string]*nextdns.Setup
 
+	// BlockedDomains stores the analytics of blocked domains per profile,
+	// most blocked first
+	BlockedDomains map[string][]*nextdns.AnalyticsEntry
+
 	// Error injection for testing error paths
 	CreateProfileError                error
 	GetProfileError                   error
 	UpdateProfileError                error
 	DeleteProfileError                error
+	ListProfilesError                 error
+	ValidateKeyError                  error
 	UpdateSecurityError               error
 	GetSecurityError                  error
 	UpdatePrivacyError                error
@@ -88,6 +101,7 @@ type MockClient struct {
 	GetParentalControlServicesError   error
 	GetRewritesError                  error
 	GetSetupError                     error
+	GetTopBlockedDomainsError         error
 
 	// Call tracking
 	Calls []MockCall
@@ -121,6 +135,7 @@ func NewMockClient() *MockClient {
 		ParentalControlServices:   make(map[string][]*nextdns.ParentalControlServices),
 		Rewrites:                  make(map[string][]*nextdns.Rewrites),
 		SetupData:                 make(map[string]*nextdns.Setup),
+		BlockedDomains:            make(map[string][]*nextdns.AnalyticsEntry),
 		Calls:                     make([]MockCall, 0),
 		NextProfileID:             1,
 	}
@@ -170,6 +185,35 @@ func (m *MockClient) GetProfile(ctx context.Context, profileID string) (*nextdns
 	return profile, nil
 }
 
+// ValidateKey returns ValidateKeyError
+func (m *MockClient) ValidateKey(ctx context.Context) error {
+	m.recordCall("ValidateKey")
+	return m.ValidateKeyError
+}
+
+// ListProfiles returns a summary of every mock profile, sorted by ID
+func (m *MockClient) ListProfiles(ctx context.Context) ([]*nextdns.ProfileSummary, error) {
+	m.recordCall("ListProfiles")
+	if m.ListProfilesError != nil {
+		return nil, m.ListProfilesError
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	profiles := make([]*nextdns.ProfileSummary, 0, len(m.Profiles))
+	for id, profile := range m.Profiles {
+		profiles = append(profiles, &nextdns.ProfileSummary{
+			ID:          id,
+			Name:        profile.Name,
+			Fingerprint: profile.Fingerprint,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+
+	return profiles, nil
+}
+
 // UpdateProfile updates a mock profile
 func (m *MockClient) UpdateProfile(ctx context.Context, profileID, name string) error {
 	m.recordCall("UpdateProfile", profileID, name)
@@ -384,9 +428,9 @@ func (m *MockClient) GetParentalControl(ctx context.Context, profileID string) (
 	return pc, nil
 }
 
-// SyncDenylist syncs mock denylist
-func (m *MockClient) SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry) error {
-	m.recordCall("SyncDenylist", profileID, entries)
+// SyncDenylist syncs mock denylist, applying the same diff as the real client
+func (m *MockClient) SyncDenylist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error {
+	m.recordCall("SyncDenylist", profileID, entries, opts)
 	if m.SyncDenylistError != nil {
 		return m.SyncDenylistError
 	}
@@ -394,18 +438,43 @@ func (m *MockClient) SyncDenylist(ctx context.Context, profileID string, entries
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	current := make(map[string]bool, len(m.Denylists[profileID]))
+	for _, e := range m.Denylists[profileID] {
+		current[e.ID] = e.Active
+	}
+	plan := planDomainListSync(current, entries, opts)
+
+	deleted := make(map[string]bool, len(plan.Delete))
+	for _, domain := range plan.Delete {
+		deleted[domain] = true
+	}
+	updated := make(map[string]bool, len(plan.Update))
+	for _, e := range plan.Update {
+		updated[e.Domain] = e.Active
+	}
+
 	var denylist []*nextdns.Denylist
-	for _, entry := range entries {
-		denylist = append(denylist, &nextdns.Denylist{ID: entry.Domain, Active: entry.Active})
+	for _, e := range m.Denylists[profileID] {
+		if deleted[e.ID] {
+			continue
+		}
+		active := e.Active
+		if v, ok := updated[e.ID]; ok {
+			active = v
+		}
+		denylist = append(denylist, &nextdns.Denylist{ID: e.ID, Active: active})
+	}
+	for _, e := range plan.Add {
+		denylist = append(denylist, &nextdns.Denylist{ID: e.Domain, Active: e.Active})
 	}
 	m.Denylists[profileID] = denylist
 
 	return nil
 }
 
-// SyncAllowlist syncs mock allowlist
-func (m *MockClient) SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry) error {
-	m.recordCall("SyncAllowlist", profileID, entries)
+// SyncAllowlist syncs mock allowlist, applying the same diff as the real client
+func (m *MockClient) SyncAllowlist(ctx context.Context, profileID string, entries []DomainEntry, opts ListSyncOptions) error {
+	m.recordCall("SyncAllowlist", profileID, entries, opts)
 	if m.SyncAllowlistError != nil {
 		return m.SyncAllowlistError
 	}
@@ -413,9 +482,34 @@ func (m *MockClient) SyncAllowlist(ctx context.Context, profileID string, entrie
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	current := make(map[string]bool, len(m.Allowlists[profileID]))
+	for _, e := range m.Allowlists[profileID] {
+		current[e.ID] = e.Active
+	}
+	plan := planDomainListSync(current, entries, opts)
+
+	deleted := make(map[string]bool, len(plan.Delete))
+	for _, domain := range plan.Delete {
+		deleted[domain] = true
+	}
+	updated := make(map[string]bool, len(plan.Update))
+	for _, e := range plan.Update {
+		updated[e.Domain] = e.Active
+	}
+
 	var allowlist []*nextdns.Allowlist
-	for _, entry := range entries {
-		allowlist = append(allowlist, &nextdns.Allowlist{ID: entry.Domain, Active: entry.Active})
+	for _, e := range m.Allowlists[profileID] {
+		if deleted[e.ID] {
+			continue
+		}
+		active := e.Active
+		if v, ok := updated[e.ID]; ok {
+			active = v
+		}
+		allowlist = append(allowlist, &nextdns.Allowlist{ID: e.ID, Active: active})
+	}
+	for _, e := range plan.Add {
+		allowlist = append(allowlist, &nextdns.Allowlist{ID: e.Domain, Active: e.Active})
 	}
 	m.Allowlists[profileID] = allowlist
 
@@ -706,10 +800,10 @@ func (m *MockClient) GetParentalControlServices(ctx context.Context, profileID s
 }
 
 // SyncRewrites syncs mock rewrites using diff-based create/delete
-func (m *MockClient) SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) error {
+func (m *MockClient) SyncRewrites(ctx context.Context, profileID string, entries []RewriteEntry) ([]RewriteResult, error) {
 	m.recordCall("SyncRewrites", profileID, entries)
 	if m.SyncRewritesError != nil {
-		return m.SyncRewritesError
+		return nil, m.SyncRewritesError
 	}
 
 	m.mu.Lock()
@@ -721,29 +815,52 @@ func (m *MockClient) SyncRewrites(ctx context.Context, profileID string, entries
 		desired[rewriteKey(e)] = true
 	}
 
-	currentSet := make(map[rewriteKey]bool)
+	currentTypes := make(map[rewriteKey]string)
 	var kept []*nextdns.Rewrites
 	for _, rw := range m.Rewrites[profileID] {
 		key := rewriteKey{rw.Name, rw.Content}
 		if desired[key] {
 			kept = append(kept, rw)
-			currentSet[key] = true
+			currentTypes[key] = rw.Type
 		}
 	}
 
+	results := make([]RewriteResult, 0, len(entries))
 	for _, e := range entries {
+		result := RewriteResult{Name: e.Name, Content: e.Content}
 		key := rewriteKey(e)
-		if !currentSet[key] {
+		if recordType, ok := currentTypes[key]; ok {
+			result.Type = recordType
+		} else if reason, rejected := m.RejectedRewrites[e.Name]; rejected {
+			result.Error = reason
+		} else {
+			result.Type = mockRewriteType(e.Content)
+			currentTypes[key] = result.Type
 			kept = append(kept, &nextdns.Rewrites{
 				ID:      fmt.Sprintf("rw-%s", e.Name),
 				Name:    e.Name,
+				Type:    result.Type,
 				Content: e.Content,
 			})
 		}
+		results = append(results, result)
 	}
 
 	m.Rewrites[profileID] = kept
-	return nil
+	return results, nil
+}
+
+// mockRewriteType returns the record type NextDNS assigns to rewrite content
+func mockRewriteType(content string) string {
+	addr, err := netip.ParseAddr(content)
+	switch {
+	case err != nil:
+		return "CNAME"
+	case addr.Is4():
+		return "A"
+	default:
+		return "AAAA"
+	}
 }
 
 // GetRewrites retrieves mock rewrites
@@ -777,6 +894,24 @@ func (m *MockClient) GetSetup(ctx context.Context, profileID string) (*nextdns.S
 	return setup, nil
 }
 
+// GetTopBlockedDomains retrieves the mock blocked domain analytics, up to
+// limit entries
+func (m *MockClient) GetTopBlockedDomains(ctx context.Context, profileID string, from time.Time, limit int) ([]*nextdns.AnalyticsEntry, error) {
+	m.recordCall("GetTopBlockedDomains", profileID, from, limit)
+	if m.GetTopBlockedDomainsError != nil {
+		return nil, m.GetTopBlockedDomainsError
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	domains := m.BlockedDomains[profileID]
+	if limit > 0 && len(domains) > limit {
+		domains = domains[:limit]
+	}
+	return domains, nil
+}
+
 // GetCallCount returns the number of calls to a specific method
 func (m *MockClient) GetCallCount(method string) int {
 	m.mu.RLock()
@@ -0,0 +1,394 @@
+package nextdns
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+	"golang.org/x/time/rate"
+)
+
+// ClientConfig controls how clients pace and retry NextDNS API requests
+type ClientConfig struct {
+	// RequestsPerSecond is the sustained request rate allowed per API key
+	RequestsPerSecond float64
+
+	// Burst is the number of requests per API key that may exceed the rate
+	Burst int
+
+	// MaxRetries is how often a rate-limited or failed request is retried
+	MaxRetries int
+
+	// MinBackoff is the backoff before the first retry; it doubles with each
+	// further retry
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the backoff and any Retry-After the API asks for
+	MaxBackoff time.Duration
+
+	// AccountLimits overrides RequestsPerSecond and Burst for the accounts
+	// it holds, keyed by account fingerprint
+	AccountLimits map[string]AccountLimit
+}
+
+// AccountLimit is the request budget of a single NextDNS account
+type AccountLimit struct {
+	// RequestsPerSecond is the sustained request rate allowed
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that may exceed the rate; 0 keeps the
+	// default burst
+	Burst int
+}
+
+// DefaultClientConfig is used unless SetClientConfig is called
+var DefaultClientConfig = ClientConfig{
+	RequestsPerSecond: 5,
+	Burst:             10,
+	MaxRetries:        3,
+	MinBackoff:        500 * time.Millisecond,
+	MaxBackoff:        30 * time.Second,
+}
+
+// Validate checks that the configuration is usable
+func (c ClientConfig) Validate() error {
+	if c.RequestsPerSecond <= 0 {
+		return errors.New("API requests per second must be positive")
+	}
+	if c.Burst < 1 {
+		return errors.New("API burst must be at least 1")
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("API max retries must not be negative")
+	}
+	if c.MinBackoff <= 0 || c.MaxBackoff < c.MinBackoff {
+		return errors.New("API backoff must be positive with a maximum of at least the minimum")
+	}
+	for account, limit := range c.AccountLimits {
+		if limit.RequestsPerSecond <= 0 {
+			return fmt.Errorf("API requests per second of account %s must be positive", account)
+		}
+		if limit.Burst < 0 {
+			return fmt.Errorf("API burst of account %s must not be negative", account)
+		}
+	}
+	return nil
+}
+
+// ParseAccountLimits parses per-account rate limits given as a
+// comma-separated list of FINGERPRINT=RATE or FINGERPRINT=RATE/BURST
+func ParseAccountLimits(value string) (map[string]AccountLimit, error) {
+	limits := make(map[string]AccountLimit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		account, budget, ok := strings.Cut(item, "=")
+		if !ok || account == "" {
+			return nil, fmt.Errorf("invalid account rate limit %q: expected FINGERPRINT=RATE[/BURST]", item)
+		}
+		rateValue, burstValue, hasBurst := strings.Cut(budget, "/")
+		var limit AccountLimit
+		var err error
+		if limit.RequestsPerSecond, err = strconv.ParseFloat(rateValue, 64); err != nil {
+			return nil, fmt.Errorf("invalid rate in account rate limit %q: %w", item, err)
+		}
+		if hasBurst {
+			if limit.Burst, err = strconv.Atoi(burstValue); err != nil {
+				return nil, fmt.Errorf("invalid burst in account rate limit %q: %w", item, err)
+			}
+		}
+		limits[account] = limit
+	}
+	return limits, nil
+}
+
+// AccountFingerprint returns a short, stable identifier of the NextDNS
+// account apiKey belongs to. It is derived from a digest of the key, so it
+// can be shown in status and metrics without revealing the key.
+func AccountFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+var (
+	// clientConfig applies to clients created after it is set
+	clientConfig = DefaultClientConfig
+
+	// apiMetrics records the requests of clients created after it is set;
+	// nil means metrics.Default()
+	apiMetrics *metrics.Metrics
+
+	// limiters holds the token bucket of each API key, keyed by the key's
+	// digest, so every client for the same account shares one budget
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*rate.Limiter)
+
+	// accountLimits holds the limits set with SetAccountLimit, keyed by
+	// account fingerprint; they take precedence over ClientConfig.AccountLimits
+	accountLimits = make(map[string]AccountLimit)
+)
+
+// SetClientConfig sets the configuration of clients created afterwards and
+// resets the per-key rate limiters. It must be called before the
+// controllers start.
+func SetClientConfig(c ClientConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	clientConfig = c
+	limiters = make(map[string]*rate.Limiter)
+	return nil
+}
+
+// SetMetrics sets the metrics that clients created afterwards record their
+// requests and retries to. It must be called before the controllers start.
+func SetMetrics(m *metrics.Metrics) {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	apiMetrics = m
+}
+
+// clientMetrics returns the metrics new clients record to
+func clientMetrics() *metrics.Metrics {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if apiMetrics == nil {
+		return metrics.Default()
+	}
+	return apiMetrics
+}
+
+// limiterFor returns the shared rate limiter of apiKey and the configuration
+// it was created with. The limiter uses the account's entry in
+// AccountLimits when there is one.
+func limiterFor(apiKey string) (*rate.Limiter, ClientConfig) {
+	sum := sha256.Sum256([]byte(apiKey))
+	key := hex.EncodeToString(sum[:])
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	limiter, ok := limiters[key]
+	if !ok {
+		rps, burst := accountBudget(key[:12])
+		limiter = rate.NewLimiter(rate.Limit(rps), burst)
+		limiters[key] = limiter
+	}
+	return limiter, clientConfig
+}
+
+// accountBudget returns the rate and burst of the account with fingerprint:
+// its SetAccountLimit limit, its entry in ClientConfig.AccountLimits or the
+// configured default. limitersMu must be held.
+func accountBudget(fingerprint string) (float64, int) {
+	rps, burst := clientConfig.RequestsPerSecond, clientConfig.Burst
+	limit, ok := accountLimits[fingerprint]
+	if !ok {
+		limit, ok = clientConfig.AccountLimits[fingerprint]
+	}
+	if ok {
+		rps = limit.RequestsPerSecond
+		if limit.Burst > 0 {
+			burst = limit.Burst
+		}
+	}
+	return rps, burst
+}
+
+// SetAccountLimit overrides the rate limit of the account apiKey belongs to,
+// including for clients created before; a nil limit removes the override.
+// It is how NextDNSAccount resources set the budget of their account.
+func SetAccountLimit(apiKey string, limit *AccountLimit) error {
+	if limit != nil && limit.RequestsPerSecond <= 0 {
+		return errors.New("API requests per second must be positive")
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	key := hex.EncodeToString(sum[:])
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if limit == nil {
+		delete(accountLimits, key[:12])
+	} else {
+		accountLimits[key[:12]] = *limit
+	}
+	if limiter, ok := limiters[key]; ok {
+		rps, burst := accountBudget(key[:12])
+		limiter.SetLimit(rate.Limit(rps))
+		limiter.SetBurst(burst)
+	}
+	return nil
+}
+
+// requestTimeout bounds a request including its retries
+const requestTimeout = 3 * time.Minute
+
+// newHTTPClient returns the HTTP client for apiKey. It mirrors the SDK's
+// default client, with the timeout widened to cover retries.
+func newHTTPClient(apiKey string) *http.Client {
+	limiter, config := limiterFor(apiKey)
+	base := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS13},
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &http.Client{
+		Timeout:       requestTimeout,
+		CheckRedirect: stripAPIKeyOnCrossHost,
+		Transport: &retryTransport{
+			next:    base,
+			limiter: limiter,
+			config:  config,
+			metrics: clientMetrics(),
+			account: AccountFingerprint(apiKey),
+		},
+	}
+}
+
+// stripAPIKeyOnCrossHost keeps the API key from following redirects to other hosts
+func stripAPIKeyOnCrossHost(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del("X-Api-Key")
+	}
+	return nil
+}
+
+// retryTransport paces requests through a rate limiter and retries those
+// the API rejected as rate limited or failed with a server error
+type retryTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	config  ClientConfig
+	metrics *metrics.Metrics
+
+	// account is the fingerprint of the API key's account, used as the
+	// metrics label
+	account string
+
+	// sleep waits for d or until the request is canceled; replaced in tests
+	sleep func(req *http.Request, d time.Duration) error
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("failed to wait for NextDNS API rate limit: %w", err)
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("cannot retry NextDNS API request without a replayable body")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		reason := retryReason(req.Method, resp.StatusCode)
+		if reason == "" || attempt >= t.config.MaxRetries {
+			return resp, nil
+		}
+
+		wait := t.backoff(attempt, resp.Header.Get("Retry-After"))
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+		t.metrics.RecordAPIRetry(t.account, reason)
+
+		if err := t.wait(req, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryReason returns why a response should be retried, or "" if it should
+// not. Server errors are only retried for idempotent methods so a request
+// the API may have applied, such as creating a profile, is not repeated.
+func retryReason(method string, status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate_limited"
+	case status >= http.StatusInternalServerError && method != http.MethodPost:
+		return "server_error"
+	default:
+		return ""
+	}
+}
+
+// backoff returns the wait before the retry following attempt: the
+// Retry-After the API asked for, or exponential backoff with full jitter.
+// Both are capped at MaxBackoff.
+func (t *retryTransport) backoff(attempt int, retryAfter string) time.Duration {
+	if d, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+		return min(d, t.config.MaxBackoff)
+	}
+
+	ceiling := t.config.MinBackoff << min(attempt, 30)
+	if ceiling <= 0 || ceiling > t.config.MaxBackoff {
+		ceiling = t.config.MaxBackoff
+	}
+	return t.config.MinBackoff/2 + time.Duration(rand.Int64N(int64(ceiling-t.config.MinBackoff/2)+1))
+}
+
+// wait sleeps for d unless the request is canceled first
+func (t *retryTransport) wait(req *http.Request, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(req, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
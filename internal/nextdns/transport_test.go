@@ -0,0 +1,305 @@
+package nextdns
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/jacaudi/nextdns-operator/internal/metrics"
+)
+
+// newTestTransport returns a retryTransport that records its waits instead of sleeping
+func newTestTransport(config ClientConfig, waits *[]time.Duration) *retryTransport {
+	return &retryTransport{
+		next:    http.DefaultTransport,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+		config:  config,
+		metrics: metrics.Default(),
+		sleep: func(_ *http.Request, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+}
+
+func TestRetryTransport_RetriesRateLimitedWithRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestTransport(DefaultClientConfig, &waits)}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"name":"home"}`))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, waits)
+	assert.Equal(t, []string{`{"name":"home"}`, `{"name":"home"}`, `{"name":"home"}`}, bodies,
+		"the request body is replayed on every attempt")
+}
+
+func TestRetryTransport_ServerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		wantCalls int32
+	}{
+		{name: "idempotent request is retried", method: http.MethodPatch, wantCalls: 4},
+		{name: "POST is not retried", method: http.MethodPost, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &http.Client{Transport: newTestTransport(DefaultClientConfig, &waits)}
+
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader("{}"))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, http.StatusBadGateway, resp.StatusCode, "the last response is returned once retries are exhausted")
+			assert.Equal(t, tt.wantCalls, calls.Load())
+			for _, wait := range waits {
+				assert.GreaterOrEqual(t, wait, DefaultClientConfig.MinBackoff/2)
+				assert.LessOrEqual(t, wait, DefaultClientConfig.MaxBackoff)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_StopsWhenCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport := &retryTransport{
+		next:    http.DefaultTransport,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+		config:  DefaultClientConfig,
+		metrics: metrics.Default(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryTransport_Backoff(t *testing.T) {
+	transport := &retryTransport{config: ClientConfig{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}}
+
+	for attempt := range 5 {
+		ceiling := min(time.Second<<attempt, 4*time.Second)
+		for range 20 {
+			d := transport.backoff(attempt, "")
+			assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+			assert.LessOrEqual(t, d, ceiling)
+		}
+	}
+
+	assert.Equal(t, 3*time.Second, transport.backoff(0, "3"))
+	assert.Equal(t, 4*time.Second, transport.backoff(0, "120"), "Retry-After is capped at the maximum backoff")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "5", want: 5 * time.Second, wantOK: true},
+		{name: "HTTP date", value: now.Add(10 * time.Second).Format(http.TimeFormat), want: 10 * time.Second, wantOK: true},
+		{name: "past HTTP date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "empty", value: ""},
+		{name: "negative", value: "-1"},
+		{name: "invalid", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientConfigValidate(t *testing.T) {
+	assert.NoError(t, DefaultClientConfig.Validate())
+
+	invalid := map[string]func(*ClientConfig){
+		"zero rate":         func(c *ClientConfig) { c.RequestsPerSecond = 0 },
+		"zero burst":        func(c *ClientConfig) { c.Burst = 0 },
+		"negative retries":  func(c *ClientConfig) { c.MaxRetries = -1 },
+		"zero backoff":      func(c *ClientConfig) { c.MinBackoff = 0 },
+		"max below minimum": func(c *ClientConfig) { c.MaxBackoff = c.MinBackoff / 2 },
+		"zero account rate": func(c *ClientConfig) {
+			c.AccountLimits = map[string]AccountLimit{"0123456789ab": {Burst: 5}}
+		},
+		"negative account burst": func(c *ClientConfig) {
+			c.AccountLimits = map[string]AccountLimit{"0123456789ab": {RequestsPerSecond: 1, Burst: -1}}
+		},
+	}
+	for name, mutate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultClientConfig
+			mutate(&c)
+			assert.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestLimiterFor_SharedPerAPIKey(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetClientConfig(DefaultClientConfig)) })
+	require.NoError(t, SetClientConfig(ClientConfig{
+		RequestsPerSecond: 2, Burst: 3, MaxRetries: 1, MinBackoff: time.Second, MaxBackoff: time.Second,
+	}))
+
+	first, config := limiterFor("key-a")
+	second, _ := limiterFor("key-a")
+	other, _ := limiterFor("key-b")
+
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, rate.Limit(2), first.Limit())
+	assert.Equal(t, 3, first.Burst())
+	assert.Equal(t, 1, config.MaxRetries)
+
+	assert.Error(t, SetClientConfig(ClientConfig{}))
+}
+
+func TestLimiterFor_AccountLimits(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetClientConfig(DefaultClientConfig)) })
+	config := DefaultClientConfig
+	config.AccountLimits = map[string]AccountLimit{
+		AccountFingerprint("key-a"): {RequestsPerSecond: 20, Burst: 40},
+		AccountFingerprint("key-b"): {RequestsPerSecond: 1},
+	}
+	require.NoError(t, SetClientConfig(config))
+
+	a, _ := limiterFor("key-a")
+	assert.Equal(t, rate.Limit(20), a.Limit())
+	assert.Equal(t, 40, a.Burst())
+
+	// A zero burst keeps the default burst
+	b, _ := limiterFor("key-b")
+	assert.Equal(t, rate.Limit(1), b.Limit())
+	assert.Equal(t, DefaultClientConfig.Burst, b.Burst())
+
+	c, _ := limiterFor("key-c")
+	assert.Equal(t, rate.Limit(DefaultClientConfig.RequestsPerSecond), c.Limit())
+}
+
+func TestSetAccountLimit(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetAccountLimit("key-a", nil))
+		require.NoError(t, SetClientConfig(DefaultClientConfig))
+	})
+	config := DefaultClientConfig
+	config.AccountLimits = map[string]AccountLimit{
+		AccountFingerprint("key-a"): {RequestsPerSecond: 20, Burst: 40},
+	}
+	require.NoError(t, SetClientConfig(config))
+
+	// Limiters created before the override are updated in place
+	a, _ := limiterFor("key-a")
+	require.NoError(t, SetAccountLimit("key-a", &AccountLimit{RequestsPerSecond: 2}))
+	assert.Equal(t, rate.Limit(2), a.Limit())
+	assert.Equal(t, DefaultClientConfig.Burst, a.Burst(), "a zero burst keeps the default burst")
+
+	// Removing the override restores the configured account limit
+	require.NoError(t, SetAccountLimit("key-a", nil))
+	assert.Equal(t, rate.Limit(20), a.Limit())
+	assert.Equal(t, 40, a.Burst())
+
+	// New limiters start with the override
+	require.NoError(t, SetAccountLimit("key-a", &AccountLimit{RequestsPerSecond: 3, Burst: 6}))
+	require.NoError(t, SetClientConfig(config))
+	a, _ = limiterFor("key-a")
+	assert.Equal(t, rate.Limit(3), a.Limit())
+	assert.Equal(t, 6, a.Burst())
+
+	assert.Error(t, SetAccountLimit("key-a", &AccountLimit{}))
+}
+
+func TestAccountFingerprint(t *testing.T) {
+	fingerprint := AccountFingerprint("test-api-key")
+	assert.Len(t, fingerprint, 12)
+	assert.Equal(t, fingerprint, AccountFingerprint("test-api-key"))
+	assert.NotEqual(t, fingerprint, AccountFingerprint("other-api-key"))
+	assert.NotContains(t, fingerprint, "test")
+}
+
+func TestParseAccountLimits(t *testing.T) {
+	limits, err := ParseAccountLimits("0123456789ab=20/40, ba9876543210=0.5,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]AccountLimit{
+		"0123456789ab": {RequestsPerSecond: 20, Burst: 40},
+		"ba9876543210": {RequestsPerSecond: 0.5},
+	}, limits)
+
+	limits, err = ParseAccountLimits("")
+	require.NoError(t, err)
+	assert.Empty(t, limits)
+
+	for _, value := range []string{"0123456789ab", "=5", "0123456789ab=fast", "0123456789ab=5/many"} {
+		_, err := ParseAccountLimits(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSetMetrics(t *testing.T) {
+	t.Cleanup(func() { SetMetrics(nil) })
+	assert.Same(t, metrics.Default(), clientMetrics())
+
+	m, err := metrics.New(prometheus.NewRegistry())
+	require.NoError(t, err)
+	SetMetrics(m)
+
+	client, err := NewClient("test-api-key")
+	require.NoError(t, err)
+	assert.Same(t, m, client.metrics)
+	assert.Equal(t, AccountFingerprint("test-api-key"), client.account)
+	transport := newHTTPClient("test-api-key").Transport.(*retryTransport)
+	assert.Same(t, m, transport.metrics)
+	assert.Equal(t, AccountFingerprint("test-api-key"), transport.account)
+}
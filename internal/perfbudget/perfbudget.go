@@ -0,0 +1,95 @@
+// Package perfbudget enforces performance budgets from ordinary tests, so a
+// change that slows down large installs fails go test instead of surfacing
+// as reconcile latency in production. Allocation budgets are always checked;
+// time budgets depend on the machine and are only checked when TimeEnv is
+// set. The budgets are listed in docs/performance.md.
+package perfbudget
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TimeEnv names the environment variable that enables the time budgets,
+// which would flake on shared or loaded runners if checked by default
+const TimeEnv = "PERF_BUDGET_TIME"
+
+// ScaleEnv names the environment variable that multiplies every time
+// budget, for runners slower than the ones the budgets were measured on
+const ScaleEnv = "PERF_BUDGET_SCALE"
+
+// runs is how often an operation is timed; the fastest run is compared with
+// the budget, which filters out scheduling and garbage collection noise
+const runs = 3
+
+// Budget bounds a single run of an operation
+type Budget struct {
+	// Time is the longest a run may take
+	Time time.Duration
+	// Allocs is the most heap allocations a run may make
+	Allocs float64
+}
+
+// Enforce runs op and fails t when it exceeds budget. The time budget is
+// only checked when TimeEnv is true. It is skipped in -short mode and under
+// the race detector, which slows code down by an order of magnitude and
+// changes allocation counts.
+func Enforce(t *testing.T, budget Budget, op func()) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("performance budgets are not enforced in -short mode")
+	}
+	if raceEnabled {
+		t.Skip("performance budgets are not enforced under the race detector")
+	}
+
+	// AllocsPerRun warms op up before counting
+	allocs := testing.AllocsPerRun(runs, op)
+	t.Logf("%.0f allocs (budget %.0f)", allocs, budget.Allocs)
+	if allocs > budget.Allocs {
+		t.Errorf("made %.0f allocations, over the budget of %.0f", allocs, budget.Allocs)
+	}
+
+	if !timeBudgets(t) {
+		return
+	}
+	scale := 1.0
+	if v := os.Getenv(ScaleEnv); v != "" {
+		s, err := strconv.ParseFloat(v, 64)
+		if err != nil || s <= 0 {
+			t.Fatalf("invalid %s %q: must be a positive number", ScaleEnv, v)
+		}
+		scale = s
+	}
+
+	fastest := time.Duration(-1)
+	for range runs {
+		start := time.Now()
+		op()
+		if elapsed := time.Since(start); fastest < 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+
+	limit := time.Duration(float64(budget.Time) * scale)
+	t.Logf("fastest of %d runs: %s (budget %s)", runs, fastest, limit)
+	if fastest > limit {
+		t.Errorf("took %s, over the budget of %s", fastest, limit)
+	}
+}
+
+// timeBudgets reports whether TimeEnv enables the time budgets
+func timeBudgets(t *testing.T) bool {
+	t.Helper()
+	v := os.Getenv(TimeEnv)
+	if v == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		t.Fatalf("invalid %s %q: must be a boolean", TimeEnv, v)
+	}
+	return enabled
+}
@@ -0,0 +1,102 @@
+// Package profileresolver looks up the NextDNSProfile a resource references
+// and whether it is ready. Controllers share it so every consumer of a
+// profile resolves references, judges readiness and maps profile events back
+// to the referencing resources the same way.
+package profileresolver
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+const (
+	// IndexField is the field index key for looking up resources by their
+	// profile reference; index values are built with Key
+	IndexField = ".spec.profileRef"
+
+	// conditionTypeReady is the condition a profile sets once it is synced
+	conditionTypeReady = "Ready"
+)
+
+// Key returns the index key (namespace/name) of a profile reference made
+// from namespace
+func Key(ref nextdnsv1alpha1.ResourceReference, namespace string) string {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return namespace + "/" + ref.Name
+}
+
+// Resolve fetches the profile ref points to, defaulting its namespace to
+// namespace. Reading through the manager's client serves the profile from
+// the shared informer cache. A missing profile returns an error wrapping
+// the NotFound API error.
+func Resolve(ctx context.Context, c client.Reader, ref nextdnsv1alpha1.ResourceReference, namespace string) (*nextdnsv1alpha1.NextDNSProfile, error) {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{}
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, profile); err != nil {
+		return nil, fmt.Errorf("failed to get NextDNSProfile %s/%s: %w", namespace, ref.Name, err)
+	}
+	return profile, nil
+}
+
+// IsReady reports whether the profile has a Ready condition set to True
+func IsReady(profile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return meta.IsStatusConditionTrue(profile.Status.Conditions, conditionTypeReady)
+}
+
+// ReadinessChanged reports whether a profile update changes what consumers
+// wait for: its readiness or its NextDNS profile ID
+func ReadinessChanged(oldProfile, newProfile *nextdnsv1alpha1.NextDNSProfile) bool {
+	return oldProfile.Status.ProfileID != newProfile.Status.ProfileID ||
+		IsReady(oldProfile) != IsReady(newProfile)
+}
+
+// EnqueueReferencing returns a handler mapping profile events to the
+// resources of list type referencing the profile, looked up through the
+// IndexField index
+func EnqueueReferencing(c client.Reader, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return Referencing(ctx, c, list, obj)
+	})
+}
+
+// Referencing returns reconcile requests for the resources of list type
+// referencing profile. Objects other than profiles have no references.
+func Referencing(ctx context.Context, c client.Reader, list client.ObjectList, profile client.Object) []reconcile.Request {
+	if _, ok := profile.(*nextdnsv1alpha1.NextDNSProfile); !ok {
+		return nil
+	}
+
+	items := list.DeepCopyObject().(client.ObjectList)
+	key := Key(nextdnsv1alpha1.ResourceReference{Name: profile.GetName()}, profile.GetNamespace())
+	if err := c.List(ctx, items, client.MatchingFields{IndexField: key}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list resources referencing profile", "profile", key)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	_ = meta.EachListItem(items, func(item runtime.Object) error {
+		if o, ok := item.(metav1.Object); ok {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()},
+			})
+		}
+		return nil
+	})
+	return requests
+}
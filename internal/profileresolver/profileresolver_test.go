@@ -0,0 +1,185 @@
+package profileresolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+)
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = nextdnsv1alpha1.AddToScheme(scheme)
+	return scheme
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "default/home", Key(nextdnsv1alpha1.ResourceReference{Name: "home"}, "default"))
+	assert.Equal(t, "shared/home", Key(nextdnsv1alpha1.ResourceReference{Name: "home", Namespace: "shared"}, "default"))
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "shared"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "abc123"},
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(profile).Build()
+
+	resolved, err := Resolve(ctx, c, nextdnsv1alpha1.ResourceReference{Name: "home", Namespace: "shared"}, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", resolved.Status.ProfileID)
+
+	_, err = Resolve(ctx, c, nextdnsv1alpha1.ResourceReference{Name: "home"}, "default")
+	require.Error(t, err)
+	assert.True(t, apierrors.IsNotFound(err), "the NotFound error is wrapped")
+	assert.Contains(t, err.Error(), "failed to get NextDNSProfile default/home")
+}
+
+func TestReadinessChanged(t *testing.T) {
+	ready := &nextdnsv1alpha1.NextDNSProfile{Status: nextdnsv1alpha1.NextDNSProfileStatus{
+		ProfileID:  "abc123",
+		Conditions: []metav1.Condition{{Type: conditionTypeReady, Status: metav1.ConditionTrue}},
+	}}
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = metav1.ConditionFalse
+	otherID := ready.DeepCopy()
+	otherID.Status.ProfileID = "def456"
+	otherMessage := ready.DeepCopy()
+	otherMessage.Status.Conditions[0].Message = "Synced again"
+
+	assert.True(t, ReadinessChanged(ready, notReady))
+	assert.True(t, ReadinessChanged(ready, otherID))
+	assert.False(t, ReadinessChanged(ready, otherMessage))
+}
+
+func TestReferencing(t *testing.T) {
+	indexFunc := func(obj client.Object) []string {
+		device := obj.(*nextdnsv1alpha1.NextDNSDevice)
+		return []string{Key(device.Spec.ProfileRef, device.Namespace)}
+	}
+	sameNamespace := &nextdnsv1alpha1.NextDNSDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "tv", Namespace: "default"},
+		Spec:       nextdnsv1alpha1.NextDNSDeviceSpec{ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"}},
+	}
+	crossNamespace := &nextdnsv1alpha1.NextDNSDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "laptop", Namespace: "edge"},
+		Spec: nextdnsv1alpha1.NextDNSDeviceSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home", Namespace: "default"},
+		},
+	}
+	otherProfile := &nextdnsv1alpha1.NextDNSDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: "phone", Namespace: "edge"},
+		Spec:       nextdnsv1alpha1.NextDNSDeviceSpec{ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"}},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme()).
+		WithObjects(sameNamespace, crossNamespace, otherProfile).
+		WithIndex(&nextdnsv1alpha1.NextDNSDevice{}, IndexField, indexFunc).
+		Build()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"}}
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "tv", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "laptop", Namespace: "edge"}},
+	}, Referencing(context.Background(), c, &nextdnsv1alpha1.NextDNSDeviceList{}, profile))
+	assert.Nil(t, Referencing(context.Background(), c, &nextdnsv1alpha1.NextDNSDeviceList{}, sameNamespace))
+}
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   *nextdnsv1alpha1.NextDNSProfile
+		wantReady bool
+	}{
+		{
+			name: "profile with Ready=True",
+			profile: &nextdnsv1alpha1.NextDNSProfile{
+				Status: nextdnsv1alpha1.NextDNSProfileStatus{
+					ProfileID: "abc123",
+					Conditions: []metav1.Condition{
+						{
+							Type:   conditionTypeReady,
+							Status: metav1.ConditionTrue,
+							Reason: "Ready",
+						},
+					},
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "profile with Ready=False",
+			profile: &nextdnsv1alpha1.NextDNSProfile{
+				Status: nextdnsv1alpha1.NextDNSProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   conditionTypeReady,
+							Status: metav1.ConditionFalse,
+							Reason: "Syncing",
+						},
+					},
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "profile with Ready=Unknown",
+			profile: &nextdnsv1alpha1.NextDNSProfile{
+				Status: nextdnsv1alpha1.NextDNSProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   conditionTypeReady,
+							Status: metav1.ConditionUnknown,
+							Reason: "Initializing",
+						},
+					},
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "profile without Ready condition",
+			profile: &nextdnsv1alpha1.NextDNSProfile{
+				Status: nextdnsv1alpha1.NextDNSProfileStatus{
+					ProfileID: "abc123",
+					Conditions: []metav1.Condition{
+						{
+							Type:   "Synced",
+							Status: metav1.ConditionTrue,
+							Reason: "Synced",
+						},
+					},
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "profile with no conditions",
+			profile: &nextdnsv1alpha1.NextDNSProfile{
+				Status: nextdnsv1alpha1.NextDNSProfileStatus{
+					ProfileID:  "abc123",
+					Conditions: []metav1.Condition{},
+				},
+			},
+			wantReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isReady := IsReady(tt.profile)
+			assert.Equal(t, tt.wantReady, isReady)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// Options configures a scan run
+type Options struct {
+	// APIKey authenticates against the NextDNS account to scan
+	APIKey string
+	// Adopt prints skeleton NextDNSProfile manifests for unmanaged profiles
+	Adopt bool
+	// Resources controls the generated manifests
+	Resources AdoptOptions
+}
+
+// ParseFlags parses the arguments of the scan subcommand
+func ParseFlags(args []string, output io.Writer) (*Options, error) {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	opts := &Options{}
+	fs.StringVar(&opts.APIKey, "api-key", os.Getenv("NEXTDNS_API_KEY"),
+		"NextDNS API key of the account to scan. Defaults to $NEXTDNS_API_KEY.")
+	fs.BoolVar(&opts.Adopt, "adopt", false,
+		"Print skeleton observe-mode NextDNSProfile manifests for unmanaged profiles.")
+	fs.StringVar(&opts.Resources.Namespace, "namespace", "default", "Namespace of the generated NextDNSProfiles.")
+	fs.StringVar(&opts.Resources.CredentialsSecret, "credentials-secret", "nextdns-credentials",
+		"Secret referenced by spec.credentialsRef of the generated NextDNSProfiles.")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if opts.APIKey == "" {
+		return nil, errors.New("--api-key or NEXTDNS_API_KEY is required")
+	}
+	return opts, nil
+}
+
+// WriteYAML writes objs as a multi-document YAML stream
+func WriteYAML(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run executes the scan subcommand. The report is written to stdout, or to
+// stderr with --adopt so stdout only carries the skeleton manifests.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer,
+	newClient func() (client.Client, error),
+	newNextDNSClient func(apiKey string) (nextdns.ClientInterface, error)) error {
+	opts, err := ParseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	nds, err := newNextDNSClient(opts.APIKey)
+	if err != nil {
+		return fmt.Errorf("failed to create NextDNS client: %w", err)
+	}
+
+	res, err := Scan(ctx, c, nds)
+	if err != nil {
+		return err
+	}
+	if !opts.Adopt {
+		return res.WriteReport(stdout)
+	}
+	if err := res.WriteReport(stderr); err != nil {
+		return err
+	}
+
+	var objs []client.Object
+	for _, p := range res.Skeletons(opts.Resources) {
+		objs = append(objs, p)
+	}
+	return WriteYAML(stdout, objs)
+}
@@ -0,0 +1,148 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	sdknextdns "github.com/jacaudi/nextdns-go/nextdns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+// maxNameLength leaves room for the profile ID suffix within the 63
+// character label limit used for generated resource names
+const maxNameLength = 40
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// UnmanagedProfile is a remote profile with no corresponding NextDNSProfile
+type UnmanagedProfile struct {
+	ID          string
+	Name        string
+	Fingerprint string
+}
+
+// Result is the outcome of an account scan
+type Result struct {
+	// Remote is the number of profiles in the NextDNS account
+	Remote int
+	// Unmanaged lists the remote profiles not referenced by any NextDNSProfile
+	Unmanaged []UnmanagedProfile
+}
+
+// Scan lists every profile in the NextDNS account and returns those whose
+// ID is neither set in spec.profileID nor recorded in status.profileID of a
+// NextDNSProfile in the cluster
+func Scan(ctx context.Context, c client.Client, nds nextdns.ClientInterface) (*Result, error) {
+	var crs nextdnsv1alpha1.NextDNSProfileList
+	if err := c.List(ctx, &crs); err != nil {
+		return nil, fmt.Errorf("failed to list NextDNSProfiles: %w", err)
+	}
+
+	represented := make(map[string]bool, len(crs.Items))
+	for _, cr := range crs.Items {
+		if cr.Spec.ProfileID != "" {
+			represented[cr.Spec.ProfileID] = true
+		}
+		if cr.Status.ProfileID != "" {
+			represented[cr.Status.ProfileID] = true
+		}
+	}
+
+	remote, err := nds.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Remote:    len(remote),
+		Unmanaged: unmanaged(remote, represented),
+	}, nil
+}
+
+// unmanaged returns the remote profiles whose ID is not in represented
+func unmanaged(remote []*sdknextdns.ProfileSummary, represented map[string]bool) []UnmanagedProfile {
+	var profiles []UnmanagedProfile
+	for _, p := range remote {
+		if represented[p.ID] {
+			continue
+		}
+		profiles = append(profiles, UnmanagedProfile{
+			ID:          p.ID,
+			Name:        p.Name,
+			Fingerprint: p.Fingerprint,
+		})
+	}
+	return profiles
+}
+
+// WriteReport writes a summary line and one line per unmanaged profile to w
+func (r *Result) WriteReport(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "found %d remote profiles, %d without a NextDNSProfile\n", r.Remote, len(r.Unmanaged)); err != nil {
+		return err
+	}
+	for _, p := range r.Unmanaged {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", p.ID, p.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdoptOptions controls the skeleton NextDNSProfile manifests generated for
+// unmanaged profiles
+type AdoptOptions struct {
+	// Namespace of the generated resources
+	Namespace string
+	// CredentialsSecret is the Secret referenced by spec.credentialsRef
+	CredentialsSecret string
+}
+
+// Skeletons builds an observe-mode NextDNSProfile for each unmanaged profile.
+// Observe mode reads the remote configuration into status.suggestedSpec
+// without changing it, so the manifests are safe to apply as-is.
+func (r *Result) Skeletons(opts AdoptOptions) []*nextdnsv1alpha1.NextDNSProfile {
+	var profiles []*nextdnsv1alpha1.NextDNSProfile
+	for _, p := range r.Unmanaged {
+		profiles = append(profiles, &nextdnsv1alpha1.NextDNSProfile{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: nextdnsv1alpha1.GroupVersion.String(),
+				Kind:       "NextDNSProfile",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resourceName(p),
+				Namespace: opts.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/created-by": "nextdns-operator-scan",
+				},
+			},
+			Spec: nextdnsv1alpha1.NextDNSProfileSpec{
+				Mode:      nextdnsv1alpha1.ProfileModeObserve,
+				ProfileID: p.ID,
+				CredentialsRef: nextdnsv1alpha1.SecretKeySelector{
+					Name: opts.CredentialsSecret,
+				},
+			},
+		})
+	}
+	return profiles
+}
+
+// resourceName derives a DNS-1123 name from the profile name, suffixed with
+// the profile ID so profiles sharing a name do not collide
+func resourceName(p UnmanagedProfile) string {
+	slug := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(p.Name), "-"), "-")
+	if len(slug) > maxNameLength {
+		slug = strings.TrimRight(slug[:maxNameLength], "-")
+	}
+	if slug == "" {
+		slug = "nextdns"
+	}
+	return slug + "-" + strings.ToLower(p.ID)
+}
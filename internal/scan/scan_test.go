@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/nextdns"
+)
+
+func newFakeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(nextdnsv1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newAccount() *nextdns.MockClient {
+	nds := nextdns.NewMockClient()
+	nds.SetProfile("abc123", "Home", "fp1")
+	nds.SetProfile("def456", "Kids Devices", "fp2")
+	nds.SetProfile("ghi789", "Office", "fp3")
+	return nds
+}
+
+func TestScan(t *testing.T) {
+	adopted := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "dns"},
+		Spec:       nextdnsv1alpha1.NextDNSProfileSpec{ProfileID: "abc123"},
+	}
+	created := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "office", Namespace: "other"},
+		Spec:       nextdnsv1alpha1.NextDNSProfileSpec{Name: "Office"},
+		Status:     nextdnsv1alpha1.NextDNSProfileStatus{ProfileID: "ghi789"},
+	}
+
+	res, err := Scan(context.Background(), newFakeClient(adopted, created), newAccount())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, res.Remote)
+	assert.Equal(t, []UnmanagedProfile{{ID: "def456", Name: "Kids Devices", Fingerprint: "fp2"}}, res.Unmanaged)
+}
+
+func TestScan_ListError(t *testing.T) {
+	nds := newAccount()
+	nds.ListProfilesError = errors.New("unauthorized")
+
+	_, err := Scan(context.Background(), newFakeClient(), nds)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unauthorized")
+}
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		profile UnmanagedProfile
+		want    string
+	}{
+		{UnmanagedProfile{ID: "def456", Name: "Kids Devices"}, "kids-devices-def456"},
+		{UnmanagedProfile{ID: "abc123", Name: "  Home / Wi-Fi!! "}, "home-wi-fi-abc123"},
+		{UnmanagedProfile{ID: "abc123", Name: "🙂"}, "nextdns-abc123"},
+		{
+			UnmanagedProfile{ID: "abc123", Name: "a very long profile name that keeps going past the limit"},
+			"a-very-long-profile-name-that-keeps-goin-abc123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile.Name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resourceName(tt.profile))
+		})
+	}
+}
+
+func TestRun_Report(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), []string{"--api-key", "key"}, &stdout, &stderr,
+		func() (client.Client, error) { return newFakeClient(), nil },
+		func(string) (nextdns.ClientInterface, error) { return newAccount(), nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, "found 3 remote profiles, 3 without a NextDNSProfile\n"+
+		"abc123\tHome\ndef456\tKids Devices\nghi789\tOffice\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_Adopt(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), []string{
+		"--api-key", "key", "--adopt", "--namespace", "dns", "--credentials-secret", "creds",
+	}, &stdout, &stderr,
+		func() (client.Client, error) { return newFakeClient(), nil },
+		func(string) (nextdns.ClientInterface, error) { return newAccount(), nil })
+	require.NoError(t, err)
+
+	assert.Contains(t, stderr.String(), "3 without a NextDNSProfile")
+
+	docs := bytes.Split(stdout.Bytes(), []byte("---\n"))
+	require.Len(t, docs, 4)
+	var profile nextdnsv1alpha1.NextDNSProfile
+	require.NoError(t, yaml.Unmarshal(docs[2], &profile))
+	assert.Equal(t, "NextDNSProfile", profile.Kind)
+	assert.Equal(t, "kids-devices-def456", profile.Name)
+	assert.Equal(t, "dns", profile.Namespace)
+	assert.Equal(t, nextdnsv1alpha1.ProfileModeObserve, profile.Spec.Mode)
+	assert.Equal(t, "def456", profile.Spec.ProfileID)
+	assert.Equal(t, "creds", profile.Spec.CredentialsRef.Name)
+}
+
+func TestParseFlags_RequiresAPIKey(t *testing.T) {
+	t.Setenv("NEXTDNS_API_KEY", "")
+
+	_, err := ParseFlags(nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NEXTDNS_API_KEY")
+}
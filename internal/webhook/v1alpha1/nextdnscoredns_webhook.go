@@ -0,0 +1,139 @@
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+var nextdnscorednslog = logf.Log.WithName("nextdnscoredns-webhook")
+
+// SetupNextDNSCoreDNSWebhookWithManager registers the defaulting webhook for NextDNSCoreDNS
+func SetupNextDNSCoreDNSWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &nextdnsv1alpha1.NextDNSCoreDNS{}).
+		WithDefaulter(&NextDNSCoreDNSDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-nextdns-io-v1alpha1-nextdnscoredns,mutating=true,failurePolicy=fail,sideEffects=None,groups=nextdns.io,resources=nextdnscorednses,verbs=create;update,versions=v1alpha1,name=mnextdnscoredns-v1alpha1.nextdns.io,admissionReviewVersions=v1
+
+// NextDNSCoreDNSDefaulter writes the effective defaults into NextDNSCoreDNS
+// objects so they are visible with kubectl and stay pinned when the
+// operator's built-in defaults change
+type NextDNSCoreDNSDefaulter struct{}
+
+// Default implements admission.Defaulter
+func (d *NextDNSCoreDNSDefaulter) Default(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) error {
+	nextdnscorednslog.V(1).Info("Defaulting NextDNSCoreDNS", "name", coreDNS.Name, "namespace", coreDNS.Namespace)
+	SetNextDNSCoreDNSDefaults(coreDNS)
+	return nil
+}
+
+// SetNextDNSCoreDNSDefaults fills unset fields with the defaults the
+// controller applies. Fields that are already set are never changed.
+func SetNextDNSCoreDNSDefaults(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	spec := &coreDNS.Spec
+
+	if spec.Deployment == nil {
+		spec.Deployment = &nextdnsv1alpha1.CoreDNSDeploymentConfig{}
+	}
+	if spec.Deployment.Mode == "" {
+		spec.Deployment.Mode = nextdnsv1alpha1.DeploymentModeDeployment
+	}
+	if spec.Deployment.Image == "" {
+		spec.Deployment.Image = coredns.DefaultCoreDNSImage
+	}
+	// Replicas only apply to Deployment mode; DaemonSets run one pod per node
+	if spec.Deployment.Mode == nextdnsv1alpha1.DeploymentModeDeployment && spec.Deployment.Replicas == nil {
+		spec.Deployment.Replicas = ptr.To[int32](coredns.DefaultReplicas)
+	}
+
+	if spec.Corefile == nil {
+		spec.Corefile = &nextdnsv1alpha1.CorefileSpec{}
+	}
+	cf := spec.Corefile
+
+	if cf.Upstream == nil {
+		cf.Upstream = &nextdnsv1alpha1.UpstreamConfig{}
+	}
+	if cf.Upstream.Primary == "" {
+		cf.Upstream.Primary = nextdnsv1alpha1.DNSProtocol(coredns.ProtocolDoT)
+	}
+
+	if cf.Cache == nil {
+		cf.Cache = &nextdnsv1alpha1.CoreDNSCacheConfig{}
+	}
+	if cf.Cache.Enabled == nil {
+		cf.Cache.Enabled = ptr.To(true)
+	}
+	if cf.Cache.SuccessTTL == nil {
+		cf.Cache.SuccessTTL = ptr.To[int32](coredns.DefaultCacheTTL)
+	}
+
+	if cf.Metrics == nil {
+		cf.Metrics = &nextdnsv1alpha1.CoreDNSMetricsConfig{}
+	}
+	if cf.Metrics.Enabled == nil {
+		cf.Metrics.Enabled = ptr.To(true)
+	}
+	if cf.Metrics.Port == nil {
+		cf.Metrics.Port = ptr.To[int32](coredns.DefaultMetricsPort)
+	}
+
+	if cf.Logging == nil {
+		cf.Logging = &nextdnsv1alpha1.CoreDNSLoggingConfig{}
+	}
+	if cf.Logging.Enabled == nil {
+		cf.Logging.Enabled = ptr.To(false)
+	}
+
+	if cf.Health == nil {
+		cf.Health = &nextdnsv1alpha1.CoreDNSHealthConfig{}
+	}
+	if cf.Health.Enabled == nil {
+		cf.Health.Enabled = ptr.To(true)
+	}
+	if cf.Health.Port == nil {
+		cf.Health.Port = ptr.To[int32](coredns.DefaultHealthPort)
+	}
+
+	if cf.Ready == nil {
+		cf.Ready = &nextdnsv1alpha1.CoreDNSReadyConfig{}
+	}
+	if cf.Ready.Enabled == nil {
+		cf.Ready.Enabled = ptr.To(true)
+	}
+	if cf.Ready.Port == nil {
+		cf.Ready.Port = ptr.To[int32](coredns.DefaultReadyPort)
+	}
+
+	if cf.Errors == nil {
+		cf.Errors = &nextdnsv1alpha1.CoreDNSErrorsConfig{}
+	}
+	if cf.Errors.Enabled == nil {
+		cf.Errors.Enabled = ptr.To(true)
+	}
+
+	// Listeners are opt-in; only fill in the ones that are configured
+	if spec.Listeners != nil && spec.Listeners.DoH != nil {
+		if spec.Listeners.DoH.Enabled == nil {
+			spec.Listeners.DoH.Enabled = ptr.To(true)
+		}
+		if spec.Listeners.DoH.Port == nil {
+			spec.Listeners.DoH.Port = ptr.To[int32](coredns.DefaultDoHListenerPort)
+		}
+	}
+	if spec.Listeners != nil && spec.Listeners.DoT != nil {
+		if spec.Listeners.DoT.Enabled == nil {
+			spec.Listeners.DoT.Enabled = ptr.To(true)
+		}
+		if spec.Listeners.DoT.Port == nil {
+			spec.Listeners.DoT.Port = ptr.To[int32](coredns.DefaultDoTListenerPort)
+		}
+	}
+}
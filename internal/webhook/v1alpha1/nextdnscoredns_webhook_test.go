@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/coredns"
+)
+
+func TestNextDNSCoreDNSDefaulter_MaterializesDefaults(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-dns", Namespace: "default"},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "home"},
+		},
+	}
+
+	require.NoError(t, (&NextDNSCoreDNSDefaulter{}).Default(context.Background(), coreDNS))
+
+	deploy := coreDNS.Spec.Deployment
+	require.NotNil(t, deploy)
+	assert.Equal(t, nextdnsv1alpha1.DeploymentModeDeployment, deploy.Mode)
+	assert.Equal(t, coredns.DefaultCoreDNSImage, deploy.Image)
+	assert.Equal(t, int32(2), *deploy.Replicas)
+
+	cf := coreDNS.Spec.Corefile
+	require.NotNil(t, cf)
+	assert.Equal(t, nextdnsv1alpha1.DNSProtocol("DoT"), cf.Upstream.Primary)
+	assert.True(t, *cf.Cache.Enabled)
+	assert.Equal(t, int32(3600), *cf.Cache.SuccessTTL)
+	assert.True(t, *cf.Metrics.Enabled)
+	assert.Equal(t, int32(9153), *cf.Metrics.Port)
+	assert.False(t, *cf.Logging.Enabled)
+	assert.Equal(t, int32(8080), *cf.Health.Port)
+	assert.Equal(t, int32(8181), *cf.Ready.Port)
+	assert.True(t, *cf.Errors.Enabled)
+	assert.Nil(t, coreDNS.Spec.Listeners, "listeners are opt-in")
+}
+
+func TestNextDNSCoreDNSDefaulter_Listeners(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Listeners: &nextdnsv1alpha1.CoreDNSListenersConfig{
+				DoH: &nextdnsv1alpha1.CoreDNSDoHListenerConfig{TLSSecretName: "dns-tls"},
+				DoT: &nextdnsv1alpha1.CoreDNSDoTListenerConfig{CertificateName: "dns", Port: ptr.To[int32](8853)},
+			},
+		},
+	}
+
+	SetNextDNSCoreDNSDefaults(coreDNS)
+
+	doh := coreDNS.Spec.Listeners.DoH
+	assert.True(t, *doh.Enabled)
+	assert.Equal(t, int32(443), *doh.Port)
+	assert.Equal(t, "dns-tls", doh.TLSSecretName)
+
+	dot := coreDNS.Spec.Listeners.DoT
+	assert.True(t, *dot.Enabled)
+	assert.Equal(t, int32(8853), *dot.Port)
+}
+
+func TestNextDNSCoreDNSDefaulter_KeepsUserValues(t *testing.T) {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{
+				Mode:  nextdnsv1alpha1.DeploymentModeDaemonSet,
+				Image: "registry.example.com/coredns:custom",
+			},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				Upstream: &nextdnsv1alpha1.UpstreamConfig{Primary: "DoH"},
+				Cache:    &nextdnsv1alpha1.CoreDNSCacheConfig{Enabled: ptr.To(false)},
+				Metrics:  &nextdnsv1alpha1.CoreDNSMetricsConfig{Port: ptr.To[int32](9253)},
+			},
+		},
+	}
+
+	SetNextDNSCoreDNSDefaults(coreDNS)
+
+	assert.Equal(t, "registry.example.com/coredns:custom", coreDNS.Spec.Deployment.Image)
+	assert.Nil(t, coreDNS.Spec.Deployment.Replicas, "replicas are not defaulted in DaemonSet mode")
+	assert.Equal(t, nextdnsv1alpha1.DNSProtocol("DoH"), coreDNS.Spec.Corefile.Upstream.Primary)
+	assert.False(t, *coreDNS.Spec.Corefile.Cache.Enabled)
+	assert.Equal(t, int32(9253), *coreDNS.Spec.Corefile.Metrics.Port)
+	assert.True(t, *coreDNS.Spec.Corefile.Metrics.Enabled)
+
+	// Defaulting is idempotent
+	again := coreDNS.DeepCopy()
+	SetNextDNSCoreDNSDefaults(again)
+	assert.Equal(t, coreDNS, again)
+}
@@ -0,0 +1,424 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/controller"
+)
+
+// exposure is how a matrix case publishes the CoreDNS pods
+type exposure string
+
+const (
+	exposureClusterIP    exposure = "ClusterIP"
+	exposureLoadBalancer exposure = "LoadBalancer"
+
+	// exposureHostPort binds port 53 on the nodes; the node-level
+	// counterpart of a NodePort Service, which the CRD does not offer
+	exposureHostPort exposure = "HostPort"
+)
+
+const (
+	// e2eProfileID is the NextDNS profile ID written to the test profiles
+	e2eProfileID = "e2e123"
+
+	// probeName is a local record every case resolves through its Service
+	probeName = "probe.e2e.test"
+
+	// probeIP is the answer to probeName
+	probeIP = "192.0.2.10"
+)
+
+// TestCoreDNSMatrix deploys a NextDNSCoreDNS for every supported
+// combination of workload mode and exposure, in parallel, and checks its
+// endpoints, Corefile, rollout of a Corefile change and status. Cases
+// running a Deployment or DaemonSet then switch to the other mode.
+func TestCoreDNSMatrix(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     nextdnsv1alpha1.DeploymentMode
+		exposure exposure
+	}{
+		{"deployment-clusterip", nextdnsv1alpha1.DeploymentModeDeployment, exposureClusterIP},
+		{"deployment-loadbalancer", nextdnsv1alpha1.DeploymentModeDeployment, exposureLoadBalancer},
+		{"daemonset-clusterip", nextdnsv1alpha1.DeploymentModeDaemonSet, exposureClusterIP},
+		{"daemonset-loadbalancer", nextdnsv1alpha1.DeploymentModeDaemonSet, exposureLoadBalancer},
+		// hostPort is only supported in DaemonSet mode, so this case does
+		// not switch modes
+		{"daemonset-hostport", nextdnsv1alpha1.DeploymentModeDaemonSet, exposureHostPort},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			namespace := createNamespace(t, "e2e-"+caseName(t))
+			createReadyProfile(t, namespace, e2eProfileID)
+
+			coreDNS := newCoreDNS(namespace, tc.mode, tc.exposure)
+			require.NoError(t, k8sClient.Create(context.Background(), coreDNS))
+
+			waitReady(t, coreDNS)
+			checkWorkload(t, coreDNS, tc.mode)
+			checkEndpoints(t, coreDNS, tc.exposure)
+			checkCorefile(t, coreDNS)
+			checkTestQuery(t, coreDNS)
+			checkCorefileRollout(t, coreDNS, tc.mode)
+
+			if tc.exposure == exposureHostPort {
+				return
+			}
+			other := nextdnsv1alpha1.DeploymentModeDaemonSet
+			if tc.mode == nextdnsv1alpha1.DeploymentModeDaemonSet {
+				other = nextdnsv1alpha1.DeploymentModeDeployment
+			}
+			update(t, coreDNS, func() { coreDNS.Spec.Deployment.Mode = other })
+			waitReady(t, coreDNS)
+			checkWorkload(t, coreDNS, other)
+			checkEndpoints(t, coreDNS, tc.exposure)
+			checkTestQuery(t, coreDNS)
+		})
+	}
+}
+
+// newCoreDNS returns a NextDNSCoreDNS named dns for the e2e profile
+func newCoreDNS(namespace string, mode nextdnsv1alpha1.DeploymentMode, exp exposure) *nextdnsv1alpha1.NextDNSCoreDNS {
+	coreDNS := &nextdnsv1alpha1.NextDNSCoreDNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: namespace},
+		Spec: nextdnsv1alpha1.NextDNSCoreDNSSpec{
+			ProfileRef: nextdnsv1alpha1.ResourceReference{Name: "e2e"},
+			Deployment: &nextdnsv1alpha1.CoreDNSDeploymentConfig{Mode: mode},
+			Service:    &nextdnsv1alpha1.CoreDNSServiceConfig{Type: nextdnsv1alpha1.ServiceTypeClusterIP},
+			Corefile: &nextdnsv1alpha1.CorefileSpec{
+				LocalRecords: []nextdnsv1alpha1.LocalRecord{{Name: probeName, Type: "A", Value: probeIP}},
+			},
+		},
+	}
+	switch exp {
+	case exposureLoadBalancer:
+		coreDNS.Spec.Service.Type = nextdnsv1alpha1.ServiceTypeLoadBalancer
+	case exposureHostPort:
+		coreDNS.Spec.Deployment.HostPort = &nextdnsv1alpha1.CoreDNSHostPortConfig{Enabled: true}
+	}
+	if mode == nextdnsv1alpha1.DeploymentModeDeployment {
+		coreDNS.Spec.Deployment.Replicas = ptr.To[int32](2)
+	}
+	return coreDNS
+}
+
+// resourceName is the name of the workload, Service and ConfigMap of coreDNS
+func resourceName(coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) string {
+	return fmt.Sprintf("%s-%s-coredns", coreDNS.Name, e2eProfileID)
+}
+
+// waitReady waits until the status of coreDNS reports its current
+// generation ready
+func waitReady(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	t.Helper()
+	eventually(t, readyTimeout, "NextDNSCoreDNS to be ready", func(ctx context.Context) error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(coreDNS), coreDNS); err != nil {
+			return err
+		}
+		status := coreDNS.Status
+		if status.ObservedGeneration != coreDNS.Generation {
+			return fmt.Errorf("observed generation %d, want %d", status.ObservedGeneration, coreDNS.Generation)
+		}
+		if cond := meta.FindStatusCondition(status.Conditions, controller.ConditionTypeReady); cond == nil || cond.Status != metav1.ConditionTrue {
+			return fmt.Errorf("Ready condition is %+v", cond)
+		}
+		if !status.Ready || status.Replicas == nil || status.Replicas.Ready != status.Replicas.Desired {
+			return fmt.Errorf("replicas %+v", status.Replicas)
+		}
+		if status.ProfileID != e2eProfileID {
+			return fmt.Errorf("status.profileID %q, want %q", status.ProfileID, e2eProfileID)
+		}
+		return nil
+	})
+}
+
+// checkWorkload checks the workload of mode is rolled out and reported in
+// status, and the workload of the other mode is gone
+func checkWorkload(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, mode nextdnsv1alpha1.DeploymentMode) {
+	t.Helper()
+	key := client.ObjectKey{Namespace: coreDNS.Namespace, Name: resourceName(coreDNS)}
+
+	eventually(t, readyTimeout, "workload of mode "+string(mode), func(ctx context.Context) error {
+		deployment := &appsv1.Deployment{}
+		deploymentErr := k8sClient.Get(ctx, key, deployment)
+		daemonSet := &appsv1.DaemonSet{}
+		daemonSetErr := k8sClient.Get(ctx, key, daemonSet)
+
+		var desired, ready int32
+		switch mode {
+		case nextdnsv1alpha1.DeploymentModeDaemonSet:
+			if daemonSetErr != nil {
+				return daemonSetErr
+			}
+			if !apierrors.IsNotFound(deploymentErr) {
+				return fmt.Errorf("the Deployment is still present: %v", deploymentErr)
+			}
+			desired, ready = daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberReady
+			if desired == 0 {
+				return fmt.Errorf("the DaemonSet schedules no pods")
+			}
+		default:
+			if deploymentErr != nil {
+				return deploymentErr
+			}
+			if !apierrors.IsNotFound(daemonSetErr) {
+				return fmt.Errorf("the DaemonSet is still present: %v", daemonSetErr)
+			}
+			desired, ready = *deployment.Spec.Replicas, deployment.Status.ReadyReplicas
+		}
+
+		if got := coreDNS.Status.Replicas; got.Desired != desired || got.Ready != ready {
+			return fmt.Errorf("status.replicas %+v, workload has %d desired and %d ready", *got, desired, ready)
+		}
+		return nil
+	})
+}
+
+// checkEndpoints checks the Service, its EndpointSlices and the endpoints
+// published in status for exp
+func checkEndpoints(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, exp exposure) {
+	t.Helper()
+	key := client.ObjectKey{Namespace: coreDNS.Namespace, Name: resourceName(coreDNS)}
+
+	eventually(t, readyTimeout, "endpoints of "+string(exp), func(ctx context.Context) error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(coreDNS), coreDNS); err != nil {
+			return err
+		}
+		service := &corev1.Service{}
+		if err := k8sClient.Get(ctx, key, service); err != nil {
+			return err
+		}
+
+		var want []string
+		switch exp {
+		case exposureLoadBalancer:
+			if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+				return fmt.Errorf("Service type %s", service.Spec.Type)
+			}
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				want = append(want, ingress.IP)
+			}
+			if len(want) == 0 {
+				return fmt.Errorf("no LoadBalancer address assigned")
+			}
+		case exposureHostPort:
+			ips, err := hostPortNodeIPs(ctx, coreDNS)
+			if err != nil {
+				return err
+			}
+			if !slices.Equal(ips, coreDNS.Status.NodeIPs) {
+				return fmt.Errorf("status.nodeIPs %v, want %v", coreDNS.Status.NodeIPs, ips)
+			}
+			want = append([]string{service.Spec.ClusterIP}, ips...)
+		default:
+			if service.Spec.Type != corev1.ServiceTypeClusterIP {
+				return fmt.Errorf("Service type %s", service.Spec.Type)
+			}
+			want = []string{service.Spec.ClusterIP}
+		}
+
+		if coreDNS.Status.DNSIP != want[0] {
+			return fmt.Errorf("status.dnsIP %q, want %q", coreDNS.Status.DNSIP, want[0])
+		}
+		for _, ip := range want {
+			for _, protocol := range []string{"UDP", "TCP"} {
+				if !slices.ContainsFunc(coreDNS.Status.Endpoints, func(e nextdnsv1alpha1.DNSEndpoint) bool {
+					return e.IP == ip && e.Port == 53 && e.Protocol == protocol
+				}) {
+					return fmt.Errorf("status.endpoints %+v lacks %s %s:53", coreDNS.Status.Endpoints, protocol, ip)
+				}
+			}
+		}
+
+		ready, err := readyEndpoints(ctx, service)
+		if err != nil {
+			return err
+		}
+		if int32(ready) != coreDNS.Status.Replicas.Ready {
+			return fmt.Errorf("the Service has %d ready endpoints, status.replicas.ready is %d", ready, coreDNS.Status.Replicas.Ready)
+		}
+		return nil
+	})
+}
+
+// readyEndpoints counts the ready pod addresses behind service
+func readyEndpoints(ctx context.Context, service *corev1.Service) (int, error) {
+	var endpointSlices discoveryv1.EndpointSliceList
+	if err := k8sClient.List(ctx, &endpointSlices, client.InNamespace(service.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return 0, err
+	}
+	addresses := map[string]bool{}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				addresses[address] = true
+			}
+		}
+	}
+	return len(addresses), nil
+}
+
+// hostPortNodeIPs returns the sorted InternalIPs of the nodes running a
+// ready CoreDNS pod of coreDNS
+func hostPortNodeIPs(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]string, error) {
+	pods, err := corednsPods(ctx, coreDNS)
+	if err != nil {
+		return nil, err
+	}
+	var ips []string
+	for _, pod := range pods {
+		if !podReady(&pod) {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+			return nil, err
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				ips = append(ips, address.Address)
+				break
+			}
+		}
+	}
+	slices.Sort(ips)
+	return slices.Compact(ips), nil
+}
+
+// corednsPods returns the pods of the workload of coreDNS
+func corednsPods(ctx context.Context, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := k8sClient.List(ctx, &pods, client.InNamespace(coreDNS.Namespace),
+		client.MatchingLabels{"app.kubernetes.io/instance": coreDNS.Name}); err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// podReady reports whether pod is running and ready
+func podReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// checkCorefile checks the generated Corefile forwards to the profile and
+// serves the local record
+func checkCorefile(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, k8sClient.Get(context.Background(),
+		client.ObjectKey{Namespace: coreDNS.Namespace, Name: resourceName(coreDNS)}, configMap))
+
+	corefile := configMap.Data["Corefile"]
+	require.Contains(t, corefile, "forward .")
+	require.Contains(t, corefile, e2eProfileID, "the Corefile forwards to the profile")
+	require.Contains(t, corefile, probeName)
+	require.Contains(t, corefile, probeIP)
+}
+
+// checkTestQuery resolves the local record through the Service with the
+// test-query annotation
+func checkTestQuery(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS) {
+	t.Helper()
+	requested := metav1.Now().Rfc3339Copy()
+	update(t, coreDNS, func() {
+		if coreDNS.Annotations == nil {
+			coreDNS.Annotations = map[string]string{}
+		}
+		coreDNS.Annotations[controller.AnnotationTestQuery] = probeName
+	})
+
+	eventually(t, readyTimeout, "test query of "+probeName, func(ctx context.Context) error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(coreDNS), coreDNS); err != nil {
+			return err
+		}
+		query := coreDNS.Status.LastTestQuery
+		if query == nil || query.Name != probeName || query.Time.Before(&requested) {
+			return fmt.Errorf("no test query recorded since %s: %+v", requested, query)
+		}
+		if query.Result != controller.TestQueryResolved || !slices.Contains(query.Answers, probeIP) {
+			return fmt.Errorf("test query %+v, want %s resolved to %s", query, probeName, probeIP)
+		}
+		return nil
+	})
+}
+
+// checkCorefileRollout changes the Corefile and waits for every pod to run
+// it and for status to record it
+func checkCorefileRollout(t *testing.T, coreDNS *nextdnsv1alpha1.NextDNSCoreDNS, mode nextdnsv1alpha1.DeploymentMode) {
+	t.Helper()
+	previousHash := coreDNS.Status.CorefileHash
+	require.NotEmpty(t, previousHash, "status.corefileHash is recorded once the pods run the Corefile")
+
+	update(t, coreDNS, func() {
+		coreDNS.Spec.Corefile.Cache = &nextdnsv1alpha1.CoreDNSCacheConfig{SuccessTTL: ptr.To[int32](120)}
+	})
+
+	eventually(t, readyTimeout, "the changed Corefile to roll out", func(ctx context.Context) error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(coreDNS), coreDNS); err != nil {
+			return err
+		}
+		status := coreDNS.Status
+		if status.CorefileGeneration != coreDNS.Generation || status.CorefileHash == previousHash {
+			return fmt.Errorf("status records Corefile %.12s of generation %d, want a new one for generation %d",
+				status.CorefileHash, status.CorefileGeneration, coreDNS.Generation)
+		}
+		if status.CorefileAppliedAt == nil {
+			return fmt.Errorf("status.corefileAppliedAt is not set")
+		}
+
+		pods, err := corednsPods(ctx, coreDNS)
+		if err != nil {
+			return err
+		}
+		current := 0
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			if hash := pod.Annotations[controller.AnnotationCorefileHash]; hash != status.CorefileHash {
+				return fmt.Errorf("pod %s still runs Corefile %.12s", pod.Name, hash)
+			}
+			if podReady(&pod) {
+				current++
+			}
+		}
+		if int32(current) != status.Replicas.Desired {
+			return fmt.Errorf("%d of %d %s pods ready with the new Corefile", current, status.Replicas.Desired,
+				strings.ToLower(string(mode)))
+		}
+		return nil
+	})
+	waitReady(t, coreDNS)
+}
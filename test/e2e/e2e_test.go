@@ -0,0 +1,152 @@
+//go:build e2e
+
+// Package e2e runs the operator against a live cluster. It expects the
+// operator to be installed in the cluster of the current kubeconfig, with a
+// LoadBalancer implementation; hack/e2e-kind.sh sets up kind and MetalLB.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nextdnsv1alpha1 "github.com/jacaudi/nextdns-operator/api/v1alpha1"
+	"github.com/jacaudi/nextdns-operator/internal/controller"
+)
+
+const (
+	// pollInterval is how often a condition is checked while waiting
+	pollInterval = 2 * time.Second
+
+	// readyTimeout bounds the wait for CoreDNS pods to roll out
+	readyTimeout = 3 * time.Minute
+)
+
+// k8sClient is the client of the cluster under test
+var k8sClient client.Client
+
+func TestMain(m *testing.M) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(nextdnsv1alpha1.AddToScheme(scheme))
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
+		os.Exit(1)
+	}
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create client: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// eventually polls check until it returns nil, failing the test with its
+// last error after timeout
+func eventually(t *testing.T, timeout time.Duration, what string, check func(ctx context.Context) error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var err error
+	for {
+		if err = check(ctx); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out after %s waiting for %s: %v", timeout, what, err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// createNamespace creates a namespace for a test and deletes it when the
+// test ends
+func createNamespace(t *testing.T, name string) string {
+	t.Helper()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	require.NoError(t, k8sClient.Create(context.Background(), ns))
+	t.Cleanup(func() {
+		if os.Getenv("E2E_KEEP_NAMESPACES") != "" {
+			return
+		}
+		_ = k8sClient.Delete(context.Background(), ns)
+	})
+	return name
+}
+
+// createReadyProfile creates a NextDNSProfile that looks synced to the
+// CoreDNS controller without a NextDNS account: it is paused, so the
+// profile controller leaves alone the status written here.
+func createReadyProfile(t *testing.T, namespace, profileID string) *nextdnsv1alpha1.NextDNSProfile {
+	t.Helper()
+	ctx := context.Background()
+
+	profile := &nextdnsv1alpha1.NextDNSProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "e2e",
+			Namespace:   namespace,
+			Annotations: map[string]string{controller.AnnotationPaused: "true"},
+		},
+		Spec: nextdnsv1alpha1.NextDNSProfileSpec{Name: "e2e"},
+	}
+	require.NoError(t, k8sClient.Create(ctx, profile))
+
+	// The operator records the Paused condition concurrently
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(profile), profile); err != nil {
+			return err
+		}
+		profile.Status.ProfileID = profileID
+		profile.Status.Fingerprint = "fp" + profileID
+		meta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+			Type:    controller.ConditionTypeReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Synced",
+			Message: "Set by the e2e suite",
+		})
+		return k8sClient.Status().Update(ctx, profile)
+	})
+	require.NoError(t, err)
+	return profile
+}
+
+// update applies mutate to the latest version of obj, retrying on conflicts
+func update(t *testing.T, obj client.Object, mutate func()) {
+	t.Helper()
+	ctx := context.Background()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return err
+		}
+		mutate()
+		return k8sClient.Update(ctx, obj)
+	})
+	require.NoError(t, err)
+}
+
+// caseName turns a subtest name into a namespace-safe suffix
+func caseName(t *testing.T) string {
+	name := t.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(name)
+}